@@ -0,0 +1,124 @@
+package rtcp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ApplicationDefined (APP) is a packet intended for experimental use, as new
+// applications and features are developed. See RFC 3550, 6.7
+//
+// Unmarshal returns an ApplicationDefined for APP packets whose name has no
+// decoder registered with RegisterApplicationDefined, or whose registered
+// decoder fails.
+type ApplicationDefined struct {
+	// The application dependent subtype, at most 31
+	SubType uint8
+	// SSRC or CSRC of the sender
+	SSRC uint32
+	// The name of the application, four ASCII characters
+	Name string
+	// Application dependent data. Marshal pads it to a multiple of 32 bits.
+	Data []byte
+}
+
+var _ Packet = (*ApplicationDefined)(nil) // assert is a Packet
+
+const (
+	appNameLength = 4
+	appNameOffset = headerLength + ssrcLength
+	appDataOffset = appNameOffset + appNameLength
+)
+
+// Marshal encodes the ApplicationDefined packet in binary
+func (a ApplicationDefined) Marshal() ([]byte, error) {
+	/*
+	 *  0                   1                   2                   3
+	 *  0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |V=2|P| subtype |   PT=APP=204  |             length            |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |                           SSRC/CSRC                           |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |                          name (ASCII)                         |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |                   application-dependent data                ...
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 */
+	if len(a.Name) != appNameLength {
+		return nil, errAppNameLength
+	}
+
+	hData, err := a.Header().Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	rawPacket := make([]byte, a.len())
+	copy(rawPacket, hData)
+	binary.BigEndian.PutUint32(rawPacket[headerLength:], a.SSRC)
+	copy(rawPacket[appNameOffset:], a.Name)
+	copy(rawPacket[appDataOffset:], a.Data)
+	if padding := getPadding(len(a.Data)); padding != 0 {
+		rawPacket[len(rawPacket)-1] = uint8(padding)
+	}
+
+	return rawPacket, nil
+}
+
+// Unmarshal decodes the ApplicationDefined packet from binary
+func (a *ApplicationDefined) Unmarshal(rawPacket []byte) error {
+	var h Header
+	if err := h.Unmarshal(rawPacket); err != nil {
+		return err
+	}
+
+	if h.Type != TypeApplicationDefined {
+		return errWrongType
+	}
+
+	end := h.size()
+	if end < appDataOffset || end > len(rawPacket) {
+		return errPacketTooShort
+	}
+
+	if h.Padding {
+		padding := int(rawPacket[end-1])
+		if padding == 0 || appDataOffset+padding > end {
+			return errBadPadding
+		}
+		end -= padding
+	}
+
+	a.SubType = h.Count
+	a.SSRC = binary.BigEndian.Uint32(rawPacket[headerLength:])
+	a.Name = string(rawPacket[appNameOffset:appDataOffset])
+	a.Data = rawPacket[appDataOffset:end]
+	return nil
+}
+
+// Header returns the Header associated with this packet.
+func (a *ApplicationDefined) Header() Header {
+	return Header{
+		Padding: getPadding(len(a.Data)) != 0,
+		Count:   a.SubType,
+		Type:    TypeApplicationDefined,
+		Length:  uint16(a.len()/4 - 1),
+	}
+}
+
+func (a *ApplicationDefined) len() int {
+	l := appDataOffset + len(a.Data)
+	return l + getPadding(l)
+}
+
+// DestinationSSRC returns an array of SSRC values that this packet refers to.
+// The SSRC of an APP packet is that of its sender, and its Data is opaque,
+// so it refers to no media source.
+func (a *ApplicationDefined) DestinationSSRC() []uint32 {
+	return []uint32{}
+}
+
+func (a ApplicationDefined) String() string {
+	return fmt.Sprintf("ApplicationDefined %q subtype %d from %x, %d bytes", a.Name, a.SubType, a.SSRC, len(a.Data))
+}
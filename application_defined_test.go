@@ -0,0 +1,106 @@
+package rtcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplicationDefinedRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		Name      string
+		Packet    ApplicationDefined
+		WantBytes []byte
+		WantError error
+	}{
+		{
+			Name:   "aligned",
+			Packet: ApplicationDefined{SubType: 3, SSRC: 0x902f9e2e, Name: "test", Data: []byte{1, 2, 3, 4}},
+			WantBytes: []byte{
+				0x83, 0xcc, 0x0, 0x3,
+				0x90, 0x2f, 0x9e, 0x2e,
+				't', 'e', 's', 't',
+				0x1, 0x2, 0x3, 0x4,
+			},
+		},
+		{
+			Name:   "padded",
+			Packet: ApplicationDefined{SSRC: 1, Name: "abcd", Data: []byte{9}},
+			WantBytes: []byte{
+				0xa0, 0xcc, 0x0, 0x3,
+				0x0, 0x0, 0x0, 0x1,
+				'a', 'b', 'c', 'd',
+				0x9, 0x0, 0x0, 0x3,
+			},
+		},
+		{
+			Name:   "no data",
+			Packet: ApplicationDefined{SSRC: 1, Name: "abcd"},
+			WantBytes: []byte{
+				0x80, 0xcc, 0x0, 0x2,
+				0x0, 0x0, 0x0, 0x1,
+				'a', 'b', 'c', 'd',
+			},
+		},
+		{
+			Name:      "short name",
+			Packet:    ApplicationDefined{Name: "abc"},
+			WantError: errAppNameLength,
+		},
+		{
+			Name:      "subtype overflow",
+			Packet:    ApplicationDefined{SubType: 32, Name: "abcd"},
+			WantError: errInvalidHeader,
+		},
+	} {
+		data, err := test.Packet.Marshal()
+		if got, want := err, test.WantError; got != want {
+			t.Fatalf("Marshal %q: err = %v, want %v", test.Name, got, want)
+		}
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(data, test.WantBytes) {
+			t.Fatalf("Marshal %q: got %#v, want %#v", test.Name, data, test.WantBytes)
+		}
+
+		var decoded ApplicationDefined
+		if err := decoded.Unmarshal(data); err != nil {
+			t.Fatalf("Unmarshal %q: %v", test.Name, err)
+		}
+		if len(decoded.Data) == 0 {
+			decoded.Data = test.Packet.Data
+		}
+		if !reflect.DeepEqual(decoded, test.Packet) {
+			t.Fatalf("Unmarshal %q: got %v, want %v", test.Name, decoded, test.Packet)
+		}
+	}
+}
+
+func TestApplicationDefinedUnmarshalErrors(t *testing.T) {
+	for _, test := range []struct {
+		Name      string
+		Data      []byte
+		WantError error
+	}{
+		{
+			Name:      "too short",
+			Data:      []byte{0x80, 0xcc, 0x0, 0x1, 0x0, 0x0, 0x0, 0x1},
+			WantError: errPacketTooShort,
+		},
+		{
+			Name:      "wrong type",
+			Data:      []byte{0x80, 0xcb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x1, 'a', 'b', 'c', 'd'},
+			WantError: errWrongType,
+		},
+		{
+			Name:      "padding into name",
+			Data:      []byte{0xa0, 0xcc, 0x0, 0x2, 0x0, 0x0, 0x0, 0x1, 'a', 'b', 'c', 0x4},
+			WantError: errBadPadding,
+		},
+	} {
+		var a ApplicationDefined
+		if got, want := a.Unmarshal(test.Data), test.WantError; got != want {
+			t.Fatalf("Unmarshal %q: err = %v, want %v", test.Name, got, want)
+		}
+	}
+}
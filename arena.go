@@ -0,0 +1,262 @@
+package rtcp
+
+import "encoding/binary"
+
+// decodeArena holds the slabs UnmarshalArena carves packets and their
+// slices from
+type decodeArena struct {
+	senderReports   []SenderReport
+	receiverReports []ReceiverReport
+	descriptions    []SourceDescription
+	nacks           []TransportLayerNack
+	tccs            []TransportLayerCC
+	plis            []PictureLossIndication
+
+	reports   []ReceptionReport
+	chunks    []SourceDescriptionChunk
+	nackPairs []NackPair
+}
+
+// tccSlabs holds the chunks and receive deltas of the TransportLayerCCs of
+// an arena
+type tccSlabs struct {
+	chunks    []PacketStatusChunk
+	runs      []RunLengthChunk
+	vectors   []StatusVectorChunk
+	symbols   []PacketStatusSymbol
+	deltas    []RecvDelta
+	deltaPtrs []*RecvDelta
+}
+
+// UnmarshalArena is like Unmarshal, but allocates the packets of the
+// datagram, and the report blocks, chunks, NACK pairs, and the packet status
+// chunks and receive deltas of transport wide feedback they hold, from a
+// few slabs sized by a scan of the headers instead of one at a time. The
+// slabs are freed together once none of the packets is referenced, which
+// reduces the work of the garbage collector on servers that decode reports
+// and discard them right away.
+//
+// As the packets share their storage, appending to a slice of one of them
+// may allocate, but never overwrites another packet.
+func UnmarshalArena(rawData []byte) ([]Packet, error) {
+	a, n := newDecodeArena(rawData)
+	packets := make([]Packet, 0, n)
+	for len(rawData) != 0 {
+		p, processed, err := unmarshalWith(rawData, a.alloc)
+		if err != nil {
+			return nil, err
+		}
+
+		packets = append(packets, p)
+		rawData = rawData[processed:]
+	}
+
+	if len(packets) == 0 {
+		return nil, errInvalidHeader
+	}
+	return packets, nil
+}
+
+// newDecodeArena sizes the slabs for the packets in raw, and returns the
+// number of packets found. Malformed packets end the scan; decoding reports
+// them.
+func newDecodeArena(raw []byte) (*decodeArena, int) {
+	var senderReports, receiverReports, descriptions, nacks, tccs, plis int
+	var reports, chunks, nackPairs int
+	var tccCounts packetStatusChunkCounts
+	n := 0
+	datagram := raw
+	for len(raw) >= headerLength {
+		var h Header
+		if err := h.Unmarshal(raw); err != nil || h.size() > len(raw) {
+			break
+		}
+		switch h.Type {
+		// reports too short for their fixed part fail to decode, and don't
+		// take room in the slabs
+		case TypeSenderReport:
+			if h.size() >= headerLength+srHeaderLength {
+				senderReports++
+				reports += fitCount(int(h.Count), h.size()-headerLength-srHeaderLength, receptionReportLength)
+			}
+		case TypeReceiverReport:
+			if h.size() >= headerLength+ssrcLength {
+				receiverReports++
+				reports += fitCount(int(h.Count), h.size()-headerLength-ssrcLength, receptionReportLength)
+			}
+		case TypeSourceDescription:
+			descriptions++
+			// a chunk is an SSRC and at least a word of terminating nulls
+			chunks += fitCount(int(h.Count), h.size()-headerLength, 2*ssrcLength)
+		case TypeTransportSpecificFeedback:
+			if h.Count == FormatTLN && h.size() >= nackOffset+headerLength {
+				nacks++
+				nackPairs += (h.size() - headerLength - nackOffset) / 4
+			}
+			if counts, ok := tccChunkCounts(h, raw); ok {
+				tccs++
+				tccCounts.runs += counts.runs
+				tccCounts.vectors += counts.vectors
+				tccCounts.deltas += counts.deltas
+			}
+		case TypePayloadSpecificFeedback:
+			if h.Count == FormatPLI {
+				plis++
+			}
+		}
+		n++
+		raw = raw[h.size():]
+	}
+
+	a := &decodeArena{
+		senderReports:   make([]SenderReport, senderReports),
+		receiverReports: make([]ReceiverReport, receiverReports),
+		descriptions:    make([]SourceDescription, descriptions),
+		nacks:           make([]TransportLayerNack, nacks),
+		tccs:            make([]TransportLayerCC, tccs),
+		plis:            make([]PictureLossIndication, plis),
+		reports:         make([]ReceptionReport, reports),
+		chunks:          make([]SourceDescriptionChunk, chunks),
+		nackPairs:       make([]NackPair, nackPairs),
+	}
+	if tccs > 0 {
+		a.prepareTCCs(datagram, tccCounts)
+	}
+	return a, n
+}
+
+// fitCount returns count, announced by a header, limited to the elements of
+// size bytes that fit in room, so a crafted count can't size the slabs
+// beyond what the datagram holds
+func fitCount(count, room, size int) int {
+	if n := room / size; count > n {
+		return n
+	}
+	return count
+}
+
+// tccChunkCounts returns the chunks and receive deltas of the transport wide
+// feedback at the start of raw, and false if it isn't one, or its deltas
+// don't fit in it
+func tccChunkCounts(h Header, raw []byte) (packetStatusChunkCounts, bool) {
+	if h.Count != FormatTCC || h.size() < headerLength+packetChunkOffset {
+		return packetStatusChunkCounts{}, false
+	}
+	count := binary.BigEndian.Uint16(raw[headerLength+packetStatusCountOffset:])
+	counts, err := countPacketStatusChunks(raw[:h.size()], count)
+	return counts, err == nil && counts.deltasFit(raw[:h.size()])
+}
+
+// prepareTCCs leaves the chunks and receive deltas of each transport wide
+// feedback in raw in the capacity of the PacketChunks and RecvDeltas of its
+// packet in the slab, in the order of the feedback, for
+// TransportLayerCC.Unmarshal to decode into
+func (a *decodeArena) prepareTCCs(raw []byte, counts packetStatusChunkCounts) {
+	s := tccSlabs{
+		chunks:    make([]PacketStatusChunk, counts.runs+counts.vectors),
+		runs:      make([]RunLengthChunk, counts.runs),
+		vectors:   make([]StatusVectorChunk, counts.vectors),
+		symbols:   make([]PacketStatusSymbol, counts.vectors*oneBitVectorSymbols),
+		deltas:    make([]RecvDelta, counts.deltas),
+		deltaPtrs: make([]*RecvDelta, counts.deltas),
+	}
+	for i := range s.deltas {
+		s.deltaPtrs[i] = &s.deltas[i]
+	}
+
+	tccs := a.tccs
+	for len(raw) >= headerLength && len(tccs) > 0 {
+		var h Header
+		if err := h.Unmarshal(raw); err != nil || h.size() > len(raw) {
+			return
+		}
+		if counts, ok := tccChunkCounts(h, raw); ok && h.Type == TypeTransportSpecificFeedback {
+			p := &tccs[0]
+			tccs = tccs[1:]
+			chunks := s.chunks[: 0 : counts.runs+counts.vectors]
+			count := binary.BigEndian.Uint16(raw[headerLength+packetStatusCountOffset:])
+			_, _ = scanPacketStatusChunks(raw[:h.size()], count, func(run bool) {
+				if run {
+					chunks = append(chunks, &s.runs[0])
+					s.runs = s.runs[1:]
+					return
+				}
+				v := &s.vectors[0]
+				s.vectors = s.vectors[1:]
+				v.SymbolList = s.symbols[:0:oneBitVectorSymbols]
+				s.symbols = s.symbols[oneBitVectorSymbols:]
+				chunks = append(chunks, v)
+			})
+			p.PacketChunks = chunks[:0]
+			s.chunks = s.chunks[len(chunks):]
+			p.RecvDeltas = s.deltaPtrs[:0:counts.deltas]
+			s.deltaPtrs = s.deltaPtrs[counts.deltas:]
+		}
+		raw = raw[h.size():]
+	}
+}
+
+// alloc returns a packet for h from the slabs, with room for the elements
+// its header announces, or a new packet if the slabs are used up
+func (a *decodeArena) alloc(h Header) Packet {
+	switch {
+	case h.Type == TypeSenderReport && len(a.senderReports) > 0:
+		p := &a.senderReports[0]
+		a.senderReports = a.senderReports[1:]
+		p.Reports = a.takeReports(int(h.Count))
+		return p
+	case h.Type == TypeReceiverReport && len(a.receiverReports) > 0:
+		p := &a.receiverReports[0]
+		a.receiverReports = a.receiverReports[1:]
+		p.Reports = a.takeReports(int(h.Count))
+		return p
+	case h.Type == TypeSourceDescription && len(a.descriptions) > 0:
+		p := &a.descriptions[0]
+		a.descriptions = a.descriptions[1:]
+		n := int(h.Count)
+		if n > len(a.chunks) {
+			n = len(a.chunks)
+		}
+		if n > 0 {
+			p.Chunks = a.chunks[:0:n]
+			a.chunks = a.chunks[n:]
+		}
+		return p
+	case h.Type == TypeTransportSpecificFeedback && h.Count == FormatTLN && len(a.nacks) > 0:
+		p := &a.nacks[0]
+		a.nacks = a.nacks[1:]
+		n := (h.size() - headerLength - nackOffset) / 4
+		if n > len(a.nackPairs) {
+			n = len(a.nackPairs)
+		}
+		if n > 0 {
+			p.Nacks = a.nackPairs[:0:n]
+			a.nackPairs = a.nackPairs[n:]
+		}
+		return p
+	case h.Type == TypeTransportSpecificFeedback && h.Count == FormatTCC && len(a.tccs) > 0:
+		p := &a.tccs[0]
+		a.tccs = a.tccs[1:]
+		return p
+	case h.Type == TypePayloadSpecificFeedback && h.Count == FormatPLI && len(a.plis) > 0:
+		p := &a.plis[0]
+		a.plis = a.plis[1:]
+		return p
+	default:
+		return newPacket(h)
+	}
+}
+
+// takeReports returns an empty slice with room for n reports from the slab,
+// or nil as Unmarshal leaves it for no reports
+func (a *decodeArena) takeReports(n int) []ReceptionReport {
+	if n > len(a.reports) {
+		n = len(a.reports)
+	}
+	if n == 0 {
+		return nil
+	}
+	out := a.reports[:0:n]
+	a.reports = a.reports[n:]
+	return out
+}
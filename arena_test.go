@@ -0,0 +1,159 @@
+package rtcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalArena(t *testing.T) {
+	vectors, err := TestVectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	datagrams := [][]byte{realPacket}
+	for _, v := range vectors {
+		datagrams = append(datagrams, v.Data)
+	}
+
+	for _, data := range datagrams {
+		want, wantErr := Unmarshal(data)
+		got, err := UnmarshalArena(data)
+		if !reflect.DeepEqual(err, wantErr) {
+			t.Fatalf("UnmarshalArena(%x) error = %v, want %v", data, err, wantErr)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("UnmarshalArena(%x) = %#v, want %#v", data, got, want)
+		}
+	}
+
+	for _, data := range [][]byte{nil, realPacket[:10]} {
+		_, wantErr := Unmarshal(data)
+		if _, err := UnmarshalArena(data); !reflect.DeepEqual(err, wantErr) {
+			t.Fatalf("UnmarshalArena(%x) error = %v, want %v", data, err, wantErr)
+		}
+	}
+}
+
+func TestUnmarshalArenaShared(t *testing.T) {
+	rr := &ReceiverReport{SSRC: 1, Reports: []ReceptionReport{{SSRC: 2}}}
+	sr := &SenderReport{SSRC: 3, Reports: []ReceptionReport{{SSRC: 4}, {SSRC: 5}}}
+	data, err := Marshal([]Packet{rr, sr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packets, err := UnmarshalArena(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// growing the reports of one packet must not overwrite the next
+	first := packets[0].(*ReceiverReport)
+	first.Reports = append(first.Reports, ReceptionReport{SSRC: 6})
+	if got := packets[1].(*SenderReport).Reports[0].SSRC; got != 4 {
+		t.Fatalf("SenderReport report SSRC = %d, want 4", got)
+	}
+
+	tcc := benchmarkTransportLayerCC()
+	data, err = Marshal([]Packet{tcc, tcc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packets, err = UnmarshalArena(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstCC := packets[0].(*TransportLayerCC)
+	firstCC.PacketChunks = append(firstCC.PacketChunks, &RunLengthChunk{})
+	firstCC.RecvDeltas = append(firstCC.RecvDeltas, &RecvDelta{})
+	if !reflect.DeepEqual(packets[1], want[1]) {
+		t.Fatalf("TransportLayerCC = %#v, want %#v", packets[1], want[1])
+	}
+}
+
+func TestUnmarshalArenaAllocs(t *testing.T) {
+	sr := &SenderReport{SSRC: 1}
+	rr := &ReceiverReport{SSRC: 1}
+	for i := uint32(0); i < 16; i++ {
+		sr.Reports = append(sr.Reports, ReceptionReport{SSRC: 100 + i})
+		rr.Reports = append(rr.Reports, ReceptionReport{SSRC: 200 + i})
+	}
+	nack := &TransportLayerNack{SenderSSRC: 1, MediaSSRC: 100}
+	for i := uint16(0); i < 16; i++ {
+		nack.Nacks = append(nack.Nacks, NackPair{PacketID: 17 * i})
+	}
+	data, err := Marshal([]Packet{sr, rr, &SourceDescription{Chunks: []SourceDescriptionChunk{{Source: 1, Items: []SourceDescriptionItem{{Type: SDESCNAME, Text: "cname"}}}}}, nack, benchmarkTransportLayerCC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unmarshalAllocs := testing.AllocsPerRun(100, func() {
+		if _, err := Unmarshal(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+	arenaAllocs := testing.AllocsPerRun(100, func() {
+		if _, err := UnmarshalArena(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if arenaAllocs >= unmarshalAllocs {
+		t.Fatalf("UnmarshalArena allocs %v, want fewer than Unmarshal allocs %v", arenaAllocs, unmarshalAllocs)
+	}
+
+	// the chunks and deltas of transport wide feedback come from slabs
+	// shared by every feedback of the datagram, so a second one costs no
+	// more allocations
+	one, err := Marshal([]Packet{benchmarkTransportLayerCC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	two, err := Marshal([]Packet{benchmarkTransportLayerCC(), benchmarkTransportLayerCC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oneAllocs := testing.AllocsPerRun(100, func() {
+		if _, err := UnmarshalArena(one); err != nil {
+			t.Fatal(err)
+		}
+	})
+	twoAllocs := testing.AllocsPerRun(100, func() {
+		if _, err := UnmarshalArena(two); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if twoAllocs != oneAllocs {
+		t.Fatalf("UnmarshalArena of two TransportLayerCCs allocs %v, want %v as for one", twoAllocs, oneAllocs)
+	}
+}
+
+func TestUnmarshalArenaCraftedCounts(t *testing.T) {
+	// the slabs are sized by what the datagram can hold, not by the counts
+	// it announces: transport wide feedback announcing 65535 deltas without
+	// any, and reports of 31 blocks without their blocks
+	var datagram []byte
+	for i := 0; i < 30; i++ {
+		datagram = append(datagram, craftedTransportLayerCC...)
+	}
+	var reports []byte
+	for i := 0; i < 300; i++ {
+		reports = append(reports, 0x9f, 0xc9, 0x00, 0x00)
+	}
+
+	for _, data := range [][]byte{datagram, reports} {
+		_, wantErr := Unmarshal(data)
+		if _, err := UnmarshalArena(data); !reflect.DeepEqual(err, wantErr) {
+			t.Fatalf("UnmarshalArena error = %v, want %v", err, wantErr)
+		}
+		if n := allocatedBytes(10, func() {
+			_, _ = UnmarshalArena(data)
+		}); n > 16*uint64(len(data)) {
+			t.Fatalf("UnmarshalArena of %d crafted bytes allocates %d bytes", len(data), n)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package rtcp
+
+import "time"
+
+const (
+	// DefaultBandwidthFraction is the fraction of the session bandwidth that
+	// RTCP traffic is limited to. See RFC 3550, 6.2
+	DefaultBandwidthFraction = 0.05
+)
+
+type bandwidthSample struct {
+	at    time.Time
+	bytes int
+}
+
+// bandwidthWindow sums byte counts over a sliding time window
+type bandwidthWindow struct {
+	samples []bandwidthSample
+	total   int
+}
+
+func (w *bandwidthWindow) add(now time.Time, n int) {
+	w.samples = append(w.samples, bandwidthSample{at: now, bytes: n})
+	w.total += n
+}
+
+func (w *bandwidthWindow) expire(cutoff time.Time) {
+	i := 0
+	for ; i < len(w.samples) && !w.samples[i].at.After(cutoff); i++ {
+		w.total -= w.samples[i].bytes
+	}
+	w.samples = append(w.samples[:0], w.samples[i:]...)
+}
+
+// A BandwidthAccountant tracks the RTCP bytes sent and received over a
+// sliding window, and decides if more RTCP may be sent without exceeding the
+// configured fraction of the session bandwidth.
+//
+// Byte counts passed to the accountant should include lower layer headers
+// (e.g. 28 bytes for UDP over IPv4), as required by RFC 3550, 6.2
+type BandwidthAccountant struct {
+	// The session bandwidth in bits per second
+	SessionBandwidth uint64
+	// The fraction of SessionBandwidth available to RTCP. If zero,
+	// DefaultBandwidthFraction is used.
+	Fraction float64
+	// The length of the sliding window
+	Window time.Duration
+	// The source of the current time. If nil, SystemClock is used.
+	Clock Clock
+
+	sent     bandwidthWindow
+	received bandwidthWindow
+}
+
+// NewBandwidthAccountant creates a BandwidthAccountant allowing fraction of
+// sessionBandwidth (in bits per second), measured over the given window.
+func NewBandwidthAccountant(sessionBandwidth uint64, fraction float64, window time.Duration) *BandwidthAccountant {
+	return &BandwidthAccountant{
+		SessionBandwidth: sessionBandwidth,
+		Fraction:         fraction,
+		Window:           window,
+	}
+}
+
+func (b *BandwidthAccountant) expire() time.Time {
+	now := clockNow(b.Clock)
+	cutoff := now.Add(-b.Window)
+	b.sent.expire(cutoff)
+	b.received.expire(cutoff)
+	return now
+}
+
+// Budget returns the number of bytes that may be sent per window.
+func (b *BandwidthAccountant) Budget() int {
+	fraction := b.Fraction
+	if fraction == 0 {
+		fraction = DefaultBandwidthFraction
+	}
+	return int(float64(b.SessionBandwidth) / 8 * fraction * b.Window.Seconds())
+}
+
+// OnSent records that n bytes of RTCP were sent.
+func (b *BandwidthAccountant) OnSent(n int) {
+	b.sent.add(b.expire(), n)
+}
+
+// OnReceived records that n bytes of RTCP were received.
+func (b *BandwidthAccountant) OnReceived(n int) {
+	b.received.add(b.expire(), n)
+}
+
+// Allow reports whether n more bytes may be sent now without exceeding the
+// budget over the current window. It doesn't record the bytes; call OnSent
+// once they have actually been sent.
+func (b *BandwidthAccountant) Allow(n int) bool {
+	b.expire()
+	return b.sent.total+n <= b.Budget()
+}
+
+// Sent returns the number of bytes sent within the current window.
+func (b *BandwidthAccountant) Sent() int {
+	b.expire()
+	return b.sent.total
+}
+
+// Received returns the number of bytes received within the current window.
+func (b *BandwidthAccountant) Received() int {
+	b.expire()
+	return b.received.total
+}
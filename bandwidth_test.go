@@ -0,0 +1,48 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBandwidthAccountant(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(0, 0)
+	// 5% of 160kbit/s over 1s is 1000 bytes
+	b := NewBandwidthAccountant(160000, 0, time.Second)
+	b.Clock = ClockFunc(func() time.Time { return now })
+
+	assert.Equal(1000, b.Budget())
+	assert.True(b.Allow(1000))
+	assert.False(b.Allow(1001))
+
+	b.OnSent(600)
+	now = now.Add(500 * time.Millisecond)
+	b.OnSent(300)
+	b.OnReceived(5000)
+
+	assert.Equal(900, b.Sent())
+	assert.Equal(5000, b.Received())
+	assert.True(b.Allow(100))
+	assert.False(b.Allow(101))
+
+	// the first sample leaves the window
+	now = now.Add(500 * time.Millisecond)
+	assert.Equal(300, b.Sent())
+	assert.True(b.Allow(700))
+
+	now = now.Add(time.Second)
+	assert.Equal(0, b.Sent())
+	assert.Equal(0, b.Received())
+}
+
+func TestBandwidthAccountantFraction(t *testing.T) {
+	b := NewBandwidthAccountant(1000000, 0.01, 2*time.Second)
+	assert.Equal(t, 2500, b.Budget())
+
+	b.OnSent(2500)
+	assert.False(t, b.Allow(1))
+}
@@ -0,0 +1,118 @@
+package rtcp
+
+import (
+	"io"
+	"net"
+)
+
+// A BatchMessage is a datagram read or written by a BatchConn.
+type BatchMessage struct {
+	// The datagram, or the buffer it is read into
+	Buffer []byte
+	// The number of bytes read into Buffer
+	N int
+	// The address the datagram came from, or is sent to
+	Addr net.Addr
+}
+
+// A BatchConn reads and writes several datagrams per call, as the recvmmsg
+// and sendmmsg system calls do. The ipv4.PacketConn and ipv6.PacketConn of
+// golang.org/x/net take their own Messages and flags, so they don't satisfy
+// BatchConn themselves; package batchconn wraps them in one that does.
+type BatchConn interface {
+	// ReadBatch blocks until at least one datagram is received, reads up
+	// to len(msgs) datagrams into the Buffers of msgs, setting N and Addr,
+	// and returns the number read.
+	ReadBatch(msgs []BatchMessage) (int, error)
+	// WriteBatch sends the Buffers of msgs to their Addrs, and returns the
+	// number sent, which may be less than len(msgs).
+	WriteBatch(msgs []BatchMessage) (int, error)
+}
+
+// ReadBatch reads up to len(bufs) datagrams from conn, each into its own
+// buffer, and returns their packets, a CompoundPacket per datagram. If conn
+// is a BatchConn the datagrams are read with a single call, so a busy
+// session is serviced with fewer system calls; otherwise a single datagram
+// is read with ReadFrom. The packets refer to bufs, which must not be
+// reused while they are in use.
+//
+// Datagrams that fail to decode are skipped, and the error of the first is
+// returned along with the packets of the others.
+func ReadBatch(conn net.PacketConn, bufs [][]byte) ([]CompoundPacket, error) {
+	if len(bufs) == 0 {
+		return nil, nil
+	}
+
+	var datagrams [][]byte
+	if bc, ok := conn.(BatchConn); ok {
+		msgs := make([]BatchMessage, len(bufs))
+		for i, buf := range bufs {
+			msgs[i].Buffer = buf
+		}
+		n, err := bc.ReadBatch(msgs)
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range msgs[:n] {
+			datagrams = append(datagrams, msg.Buffer[:msg.N])
+		}
+	} else {
+		n, _, err := conn.ReadFrom(bufs[0])
+		if err != nil {
+			return nil, err
+		}
+		datagrams = append(datagrams, bufs[0][:n])
+	}
+
+	out := make([]CompoundPacket, 0, len(datagrams))
+	var firstErr error
+	for _, datagram := range datagrams {
+		packets, err := Unmarshal(datagram)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		out = append(out, CompoundPacket(packets))
+	}
+	return out, firstErr
+}
+
+// WriteBatch marshals each of packets into a datagram and sends them to
+// addr, with as few calls as conn allows: a BatchConn sends them all at
+// once, other connections one by one with WriteTo. It returns the number of
+// datagrams sent. Like PacketConnTransport.WriteRTCP, it doesn't require the
+// packets to be valid compound packets, so reduced-size RTCP (RFC 5506) can
+// be sent.
+func WriteBatch(conn net.PacketConn, addr net.Addr, packets []CompoundPacket) (int, error) {
+	msgs := make([]BatchMessage, len(packets))
+	for i, c := range packets {
+		data, err := Marshal(c)
+		if err != nil {
+			return 0, err
+		}
+		msgs[i] = BatchMessage{Buffer: data, Addr: addr}
+	}
+
+	bc, ok := conn.(BatchConn)
+	sent := 0
+	for sent < len(msgs) {
+		if ok {
+			n, err := bc.WriteBatch(msgs[sent:])
+			sent += n
+			if err != nil {
+				return sent, err
+			}
+			if n == 0 {
+				return sent, io.ErrShortWrite
+			}
+			continue
+		}
+		if _, err := conn.WriteTo(msgs[sent].Buffer, addr); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
@@ -0,0 +1,103 @@
+package rtcp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryBatchConn is a BatchConn whose writes are queued for its reads. It
+// writes at most two datagrams per call, as sendmmsg may.
+type memoryBatchConn struct {
+	net.PacketConn
+	queue  []BatchMessage
+	writes int
+}
+
+func (c *memoryBatchConn) ReadBatch(msgs []BatchMessage) (int, error) {
+	n := 0
+	for ; n < len(msgs) && len(c.queue) > 0; n++ {
+		msgs[n].N = copy(msgs[n].Buffer, c.queue[0].Buffer)
+		msgs[n].Addr = c.queue[0].Addr
+		c.queue = c.queue[1:]
+	}
+	return n, nil
+}
+
+func (c *memoryBatchConn) WriteBatch(msgs []BatchMessage) (int, error) {
+	c.writes++
+	if len(msgs) > 2 {
+		msgs = msgs[:2]
+	}
+	c.queue = append(c.queue, msgs...)
+	return len(msgs), nil
+}
+
+func TestBatch(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 5005}
+	packets := []CompoundPacket{
+		{&ReceiverReport{SSRC: 1, ProfileExtensions: []byte{}}},
+		{&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}},
+		{&ReceiverReport{SSRC: 3, ProfileExtensions: []byte{}}, &Goodbye{Sources: []uint32{3}}},
+	}
+
+	conn := &memoryBatchConn{}
+	n, err := WriteBatch(conn, addr, packets)
+	assert.NoError(err)
+	assert.Equal(3, n)
+	assert.Equal(2, conn.writes)
+	assert.Equal(addr, conn.queue[0].Addr)
+
+	// a malformed datagram is skipped
+	conn.queue = append(conn.queue[:1], append([]BatchMessage{{Buffer: []byte{0x80}}}, conn.queue[1:]...)...)
+
+	bufs := make([][]byte, 3)
+	for i := range bufs {
+		bufs[i] = make([]byte, receiveMTU)
+	}
+	got, err := ReadBatch(conn, bufs)
+	assert.Equal(errPacketTooShort, err)
+	assert.Equal(packets[:2], got)
+	got, err = ReadBatch(conn, bufs)
+	assert.NoError(err)
+	assert.Equal(packets[2:], got)
+
+	got, err = ReadBatch(conn, nil)
+	assert.NoError(err)
+	assert.Empty(got)
+}
+
+func TestBatchPacketConn(t *testing.T) {
+	a, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on loopback: %v", err)
+	}
+	b, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on loopback: %v", err)
+	}
+	defer func() {
+		assert.NoError(t, a.Close())
+		assert.NoError(t, b.Close())
+	}()
+
+	// connections without batch support send and read one datagram at a
+	// time
+	packets := []CompoundPacket{
+		{&ReceiverReport{SSRC: 1, ProfileExtensions: []byte{}}},
+		{&ReceiverReport{SSRC: 2, ProfileExtensions: []byte{}}},
+	}
+	n, err := WriteBatch(a, b.LocalAddr(), packets)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	bufs := [][]byte{make([]byte, receiveMTU), make([]byte, receiveMTU)}
+	for _, want := range packets {
+		got, err := ReadBatch(b, bufs)
+		assert.NoError(t, err)
+		assert.Equal(t, []CompoundPacket{want}, got)
+	}
+}
@@ -0,0 +1,66 @@
+// Package batchconn adapts the ipv4.PacketConn and ipv6.PacketConn of
+// golang.org/x/net to rtcp.BatchConn, so rtcp.ReadBatch and rtcp.WriteBatch
+// read and write several datagrams per system call, with recvmmsg and
+// sendmmsg on Linux. On other platforms x/net moves a single datagram per
+// call, and the adapter behaves like the connection it wraps.
+package batchconn
+
+import (
+	"net"
+
+	"github.com/pion/rtcp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// batchReadWriter is implemented by both ipv4.PacketConn and
+// ipv6.PacketConn, whose Messages are the same type
+type batchReadWriter interface {
+	ReadBatch(ms []ipv4.Message, flags int) (int, error)
+	WriteBatch(ms []ipv4.Message, flags int) (int, error)
+}
+
+// A Conn is a net.PacketConn that is also an rtcp.BatchConn. It is safe for
+// concurrent use if the connection it wraps is.
+type Conn struct {
+	net.PacketConn
+	batch batchReadWriter
+}
+
+var _ rtcp.BatchConn = (*Conn)(nil) // assert is a BatchConn
+
+// NewIPv4 returns a Conn that batches the reads and writes of conn, a UDP
+// connection of an IPv4 socket.
+func NewIPv4(conn net.PacketConn) *Conn {
+	return &Conn{PacketConn: conn, batch: ipv4.NewPacketConn(conn)}
+}
+
+// NewIPv6 returns a Conn that batches the reads and writes of conn, a UDP
+// connection of an IPv6 socket.
+func NewIPv6(conn net.PacketConn) *Conn {
+	return &Conn{PacketConn: conn, batch: ipv6.NewPacketConn(conn)}
+}
+
+// ReadBatch implements rtcp.BatchConn.
+func (c *Conn) ReadBatch(msgs []rtcp.BatchMessage) (int, error) {
+	ms := make([]ipv4.Message, len(msgs))
+	for i := range msgs {
+		ms[i].Buffers = [][]byte{msgs[i].Buffer}
+	}
+	n, err := c.batch.ReadBatch(ms, 0)
+	for i := 0; i < n; i++ {
+		msgs[i].N = ms[i].N
+		msgs[i].Addr = ms[i].Addr
+	}
+	return n, err
+}
+
+// WriteBatch implements rtcp.BatchConn.
+func (c *Conn) WriteBatch(msgs []rtcp.BatchMessage) (int, error) {
+	ms := make([]ipv4.Message, len(msgs))
+	for i := range msgs {
+		ms[i].Buffers = [][]byte{msgs[i].Buffer}
+		ms[i].Addr = msgs[i].Addr
+	}
+	return c.batch.WriteBatch(ms, 0)
+}
@@ -0,0 +1,74 @@
+package batchconn
+
+import (
+	"net"
+	"testing"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConn(t *testing.T) {
+	for _, test := range []struct {
+		Name    string
+		Network string
+		Address string
+		New     func(net.PacketConn) *Conn
+	}{
+		{"ipv4", "udp4", "127.0.0.1:0", NewIPv4},
+		{"ipv6", "udp6", "[::1]:0", NewIPv6},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			a, err := net.ListenPacket(test.Network, test.Address)
+			if err != nil {
+				t.Skipf("cannot listen on loopback: %v", err)
+			}
+			b, err := net.ListenPacket(test.Network, test.Address)
+			if err != nil {
+				assert.NoError(a.Close())
+				t.Skipf("cannot listen on loopback: %v", err)
+			}
+			defer func() {
+				assert.NoError(a.Close())
+				assert.NoError(b.Close())
+			}()
+
+			packets := []rtcp.CompoundPacket{
+				{&rtcp.ReceiverReport{SSRC: 1, ProfileExtensions: []byte{}}},
+				{&rtcp.PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}},
+				{&rtcp.ReceiverReport{SSRC: 3, ProfileExtensions: []byte{}}, &rtcp.Goodbye{Sources: []uint32{3}}},
+			}
+			n, err := rtcp.WriteBatch(test.New(a), b.LocalAddr(), packets)
+			assert.NoError(err)
+			assert.Equal(len(packets), n)
+
+			// recvmmsg returns the datagrams that have arrived, which may
+			// be fewer than were sent
+			conn := test.New(b)
+			bufs := make([][]byte, len(packets))
+			for i := range bufs {
+				bufs[i] = make([]byte, 1500)
+			}
+			var got []rtcp.CompoundPacket
+			for len(got) < len(packets) {
+				read, err := rtcp.ReadBatch(conn, bufs[len(got):])
+				if !assert.NoError(err) {
+					return
+				}
+				got = append(got, read...)
+			}
+			assert.Equal(packets, got)
+
+			msgs := []rtcp.BatchMessage{{Buffer: make([]byte, 1500)}}
+			_, err = test.New(a).WriteBatch([]rtcp.BatchMessage{{Buffer: []byte{1, 2, 3}, Addr: b.LocalAddr()}})
+			assert.NoError(err)
+			n, err = conn.ReadBatch(msgs)
+			assert.NoError(err)
+			assert.Equal(1, n)
+			assert.Equal(3, msgs[0].N)
+			assert.Equal(a.LocalAddr().String(), msgs[0].Addr.String())
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package rtcp
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// benchmarkTransportLayerCC returns feedback for 500 packets with a mix of
+// losses, small and large deltas, so every chunk kind is exercised
+func benchmarkTransportLayerCC() *TransportLayerCC {
+	r := NewRecorder(1)
+	r.MediaSSRC = 2
+
+	at := time.Unix(0, 0)
+	for i := 0; i < 500; i++ {
+		switch {
+		case i%50 == 0:
+			at = at.Add(100 * time.Millisecond)
+		case i%7 == 3:
+			continue
+		default:
+			at = at.Add(time.Millisecond)
+		}
+		// the middle is a long run of received packets
+		if i%7 == 3 && (i < 200 || i > 300) {
+			continue
+		}
+		r.Record(uint16(i), at)
+	}
+	return r.BuildFeedback()[0]
+}
+
+func benchmarkPackets() []struct {
+	Name   string
+	Packet Packet
+} {
+	reports := []ReceptionReport{
+		{SSRC: 2, FractionLost: 10, TotalLost: 100, LastSequenceNumber: 0x1234, Jitter: 30, LastSenderReport: 0x1, Delay: 0x2},
+		{SSRC: 3, FractionLost: 20, TotalLost: 200, LastSequenceNumber: 0x5678, Jitter: 40, LastSenderReport: 0x3, Delay: 0x4},
+	}
+
+	return []struct {
+		Name   string
+		Packet Packet
+	}{
+		{Name: "SenderReport", Packet: &SenderReport{SSRC: 1, NTPTime: 0x1234, RTPTime: 0x5678, PacketCount: 100, OctetCount: 10000, Reports: reports}},
+		{Name: "ReceiverReport", Packet: &ReceiverReport{SSRC: 1, Reports: reports}},
+		{Name: "SourceDescription", Packet: &SourceDescription{Chunks: []SourceDescriptionChunk{
+			{Source: 1, Items: []SourceDescriptionItem{{Type: SDESCNAME, Text: "{9c00eb92-1afb-9d49-a47d-91f64eee69f5}"}}},
+		}}},
+		{Name: "Goodbye", Packet: &Goodbye{Sources: []uint32{1, 2}, Reason: "bye"}},
+		{Name: "TransportLayerNack", Packet: &TransportLayerNack{SenderSSRC: 1, MediaSSRC: 2, Nacks: []NackPair{{PacketID: 10, LostPackets: 0xff}, {PacketID: 40}}}},
+		{Name: "RapidResynchronizationRequest", Packet: &RapidResynchronizationRequest{SenderSSRC: 1, MediaSSRC: 2}},
+		{Name: "TransportLayerCC", Packet: benchmarkTransportLayerCC()},
+		{Name: "PictureLossIndication", Packet: &PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}},
+		{Name: "SliceLossIndication", Packet: &SliceLossIndication{SenderSSRC: 1, MediaSSRC: 2, SLI: []SLIEntry{{First: 1, Number: 2, Picture: 3}}}},
+		{Name: "ReceiverEstimatedMaximumBitrate", Packet: &ReceiverEstimatedMaximumBitrate{SenderSSRC: 1, Bitrate: 1500000, SSRCs: []uint32{2, 3}}},
+		{Name: "RawPacket", Packet: &RawPacket{0x81, 0xcc, 0x0, 0x1, 0x0, 0x0, 0x0, 0x1}},
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	for _, test := range benchmarkPackets() {
+		p := test.Packet
+		b.Run(test.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := p.Marshal(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	for _, test := range benchmarkPackets() {
+		data, err := test.Packet.Marshal()
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(test.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := Unmarshal(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+
+	b.Run("Compound", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := Unmarshal(realPacket); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkDecoder(b *testing.B) {
+	d := NewDecoder()
+	var dst []Packet
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if dst, err = d.Decode(realPacket, dst); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestTransportLayerCC_UnmarshalAllocs(t *testing.T) {
+	// chunks, receive deltas and their backing arrays are each allocated
+	// once, however many statuses the feedback holds
+	const budget = 6
+
+	fb := benchmarkTransportLayerCC()
+	if fb.PacketStatusCount != 500 {
+		t.Fatalf("PacketStatusCount = %d, want 500", fb.PacketStatusCount)
+	}
+	data, err := fb.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got TransportLayerCC
+	allocs := testing.AllocsPerRun(100, func() {
+		got = TransportLayerCC{}
+		if err := got.Unmarshal(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > budget {
+		t.Fatalf("Unmarshal of %d statuses allocates %v times, want at most %d", fb.PacketStatusCount, allocs, budget)
+	}
+	if len(got.RecvDeltas) != len(fb.RecvDeltas) || len(got.PacketChunks) != len(fb.PacketChunks) {
+		t.Fatalf("Unmarshal: got %d chunks and %d deltas, want %d and %d", len(got.PacketChunks), len(got.RecvDeltas), len(fb.PacketChunks), len(fb.RecvDeltas))
+	}
+}
+
+// allocatedBytes returns the bytes allocated per call of f, over runs calls
+func allocatedBytes(runs int, f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < runs; i++ {
+		f()
+	}
+	runtime.ReadMemStats(&after)
+	return (after.TotalAlloc - before.TotalAlloc) / uint64(runs)
+}
+
+// craftedTransportLayerCC announces 65535 received packets in 9 run length
+// chunks of 40 bytes, without any of their receive deltas
+var craftedTransportLayerCC = []byte{
+	0x8f, 0xcd, 0x00, 0x09,
+	0x00, 0x00, 0x00, 0x01,
+	0x00, 0x00, 0x00, 0x02,
+	0x00, 0x00, 0xff, 0xff,
+	0x00, 0x00, 0x00, 0x00,
+	0x3f, 0xff, 0x3f, 0xff,
+	0x3f, 0xff, 0x3f, 0xff,
+	0x3f, 0xff, 0x3f, 0xff,
+	0x3f, 0xff, 0x3f, 0xff,
+	0x20, 0x07, 0x00, 0x00,
+}
+
+func TestTransportLayerCC_UnmarshalCraftedCount(t *testing.T) {
+	// the announced deltas are rejected before storage is allocated for
+	// them, so the packet costs little more than its own size
+	const budget = 1024
+
+	var got TransportLayerCC
+	if err := got.Unmarshal(craftedTransportLayerCC); err != errPacketTooShort {
+		t.Fatalf("Unmarshal err = %v, want %v", err, errPacketTooShort)
+	}
+	if n := allocatedBytes(100, func() {
+		got = TransportLayerCC{}
+		_ = got.Unmarshal(craftedTransportLayerCC)
+	}); n > budget {
+		t.Fatalf("Unmarshal of a crafted packet allocates %d bytes, want at most %d", n, budget)
+	}
+
+	// the pool of a Decoder doesn't keep storage sized for it either
+	d := NewDecoder()
+	if _, err := d.Decode(craftedTransportLayerCC, nil); err != errPacketTooShort {
+		t.Fatalf("Decode err = %v, want %v", err, errPacketTooShort)
+	}
+	for _, p := range d.tccs {
+		if cap(p.RecvDeltas) > 0 || cap(p.PacketChunks) > 0 {
+			t.Fatalf("pooled TransportLayerCC holds %d chunks and %d deltas, want none", cap(p.PacketChunks), cap(p.RecvDeltas))
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package rtcp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCaptureSize is the number of datagrams a Capture holds when
+	// created with a size of zero.
+	DefaultCaptureSize = 256
+)
+
+// CaptureDirection tells whether a captured datagram was received or sent.
+type CaptureDirection int
+
+const (
+	// CaptureReceived marks a received datagram.
+	CaptureReceived CaptureDirection = iota
+	// CaptureSent marks a sent datagram.
+	CaptureSent
+)
+
+func (d CaptureDirection) String() string {
+	switch d {
+	case CaptureReceived:
+		return "received"
+	case CaptureSent:
+		return "sent"
+	default:
+		return fmt.Sprintf("direction(%d)", int(d))
+	}
+}
+
+// CaptureFormat selects the output format of Capture.DumpSince.
+type CaptureFormat int
+
+const (
+	// CaptureText writes each datagram as a line with its time, direction
+	// and size, followed by its indented packets.
+	CaptureText CaptureFormat = iota
+	// CaptureJSON writes each datagram as a JSON object on its own line.
+	CaptureJSON
+)
+
+// A CapturedDatagram is an RTCP datagram held by a Capture.
+type CapturedDatagram struct {
+	Time      time.Time
+	Direction CaptureDirection
+	Data      []byte
+}
+
+// A Capture remembers the most recent RTCP datagrams received and sent, so
+// the feedback leading up to an incident can be reconstructed afterwards.
+// Datagrams are copied when recorded and only decoded when dumped, so
+// capturing is cheap. A Capture is safe for concurrent use.
+type Capture struct {
+	// The source of capture times. If nil, SystemClock is used.
+	Clock Clock
+
+	mu   sync.Mutex
+	ring []CapturedDatagram
+	next int
+	full bool
+}
+
+// NewCapture creates a Capture holding the last size datagrams. A size of
+// zero means DefaultCaptureSize.
+func NewCapture(size int) *Capture {
+	if size <= 0 {
+		size = DefaultCaptureSize
+	}
+	return &Capture{ring: make([]CapturedDatagram, size)}
+}
+
+// Record captures a datagram at the current time of Clock, replacing the
+// oldest one once the capture is full.
+func (c *Capture) Record(direction CaptureDirection, raw []byte) {
+	now := clockNow(c.Clock)
+	data := append([]byte{}, raw...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ring[c.next] = CapturedDatagram{Time: now, Direction: direction, Data: data}
+	c.next++
+	if c.next == len(c.ring) {
+		c.next = 0
+		c.full = true
+	}
+}
+
+// RecordPackets marshals packets and captures them as one datagram.
+func (c *Capture) RecordPackets(direction CaptureDirection, packets []Packet) error {
+	data, err := Marshal(packets)
+	if err != nil {
+		return err
+	}
+	c.Record(direction, data)
+	return nil
+}
+
+// Since returns the captured datagrams recorded at or after t, oldest
+// first.
+func (c *Capture) Since(t time.Time) []CapturedDatagram {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var out []CapturedDatagram
+	start, n := 0, c.next
+	if c.full {
+		start, n = c.next, len(c.ring)
+	}
+	for i := 0; i < n; i++ {
+		d := c.ring[(start+i)%len(c.ring)]
+		if !d.Time.Before(t) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+type capturedDatagramJSON struct {
+	Time      time.Time            `json:"time"`
+	Direction string               `json:"direction"`
+	Data      []byte               `json:"data"`
+	Packets   []capturedPacketJSON `json:"packets,omitempty"`
+	Error     string               `json:"error,omitempty"`
+}
+
+type capturedPacketJSON struct {
+	Type   string `json:"type"`
+	Packet Packet `json:"packet"`
+}
+
+// DumpSince writes the datagrams recorded at or after t to w in the given
+// format. Datagrams that fail to decode are dumped in hex along with the
+// error.
+func (c *Capture) DumpSince(w io.Writer, t time.Time, format CaptureFormat) error {
+	return c.DumpMatching(w, t, format, nil)
+}
+
+// DumpMatching is like DumpSince, but only writes the datagrams with a
+// packet matching f. Datagrams that fail to decode are always written.
+func (c *Capture) DumpMatching(w io.Writer, t time.Time, format CaptureFormat, f *Filter) error {
+	enc := json.NewEncoder(w)
+	for _, d := range c.Since(t) {
+		packets, decodeErr := Unmarshal(d.Data)
+		if decodeErr == nil && !f.MatchAny(packets) {
+			continue
+		}
+
+		if format == CaptureJSON {
+			out := capturedDatagramJSON{Time: d.Time, Direction: d.Direction.String(), Data: d.Data}
+			if decodeErr != nil {
+				out.Error = decodeErr.Error()
+			}
+			for _, p := range packets {
+				out.Packets = append(out.Packets, capturedPacketJSON{Type: packetTypeName(p), Packet: p})
+			}
+			if err := enc.Encode(out); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s %s %d bytes\n", d.Time.Format(time.RFC3339Nano), d.Direction, len(d.Data))
+		if decodeErr != nil {
+			fmt.Fprintf(&b, "  error: %v\n  %s\n", decodeErr, hex.EncodeToString(d.Data))
+		}
+		for _, p := range packets {
+			var text string
+			if s, ok := p.(fmt.Stringer); ok {
+				text = s.String()
+			} else {
+				text = fmt.Sprintf("%s %+v", packetTypeName(p), p)
+			}
+			for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+				fmt.Fprintf(&b, "  %s\n", line)
+			}
+		}
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// packetTypeName returns the name of the Go type of p, e.g. "SenderReport"
+func packetTypeName(p Packet) string {
+	name := fmt.Sprintf("%T", p)
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -0,0 +1,109 @@
+package rtcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapture(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1000, 0).UTC()
+	c := NewCapture(3)
+	c.Clock = ClockFunc(func() time.Time { return now })
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(c.RecordPackets(CaptureSent, []Packet{&PictureLossIndication{SenderSSRC: 1, MediaSSRC: uint32(i)}}))
+		now = now.Add(time.Second)
+	}
+
+	// only the last three are kept
+	captured := c.Since(time.Time{})
+	assert.Len(captured, 3)
+	assert.Equal(time.Unix(1002, 0).UTC(), captured[0].Time)
+	assert.Equal(CaptureSent, captured[0].Direction)
+	assert.Len(c.Since(time.Unix(1003, 0)), 2)
+
+	// the recorded bytes are copied
+	raw := []byte{0x81, 0xce, 0x00, 0x02, 0, 0, 0, 1, 0, 0, 0, 9}
+	c.Record(CaptureReceived, raw)
+	raw[11] = 0
+	captured = c.Since(now)
+	assert.Len(captured, 1)
+	assert.Equal(byte(9), captured[0].Data[11])
+}
+
+func TestCaptureDumpSince(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1000, 0).UTC()
+	c := NewCapture(0)
+	c.Clock = ClockFunc(func() time.Time { return now })
+	assert.NoError(c.RecordPackets(CaptureReceived, []Packet{
+		&ReceiverReport{SSRC: 1},
+		&Goodbye{Sources: []uint32{1}},
+	}))
+	c.Record(CaptureSent, []byte{0x81, 0xce})
+
+	var text bytes.Buffer
+	assert.NoError(c.DumpSince(&text, time.Time{}, CaptureText))
+	assert.Equal(strings.Join([]string{
+		"1970-01-01T00:16:40Z received 20 bytes",
+		"  ReceiverReport from 1",
+		"  \tSSRC    \tLost\tLastSequence",
+		"  \tProfile Extension Data: []",
+		"  Goodbye &{Sources:[1] Reason:}",
+		"1970-01-01T00:16:40Z sent 2 bytes",
+		"  error: " + errPacketTooShort.Error(),
+		"  81ce",
+		"",
+	}, "\n"), text.String())
+
+	var out bytes.Buffer
+	assert.NoError(c.DumpSince(&out, time.Time{}, CaptureJSON))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(lines, 2)
+
+	var first struct {
+		Direction string
+		Packets   []struct {
+			Type   string
+			Packet map[string]interface{}
+		}
+	}
+	assert.NoError(json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal("received", first.Direction)
+	assert.Len(first.Packets, 2)
+	assert.Equal("ReceiverReport", first.Packets[0].Type)
+	assert.Equal(1.0, first.Packets[0].Packet["SSRC"])
+	assert.Equal("Goodbye", first.Packets[1].Type)
+
+	var second map[string]interface{}
+	assert.NoError(json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal("sent", second["direction"])
+	assert.Equal(errPacketTooShort.Error(), second["error"])
+}
+
+func TestCaptureDumpMatching(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewCapture(0)
+	assert.NoError(c.RecordPackets(CaptureReceived, []Packet{&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}}))
+	assert.NoError(c.RecordPackets(CaptureReceived, []Packet{&RapidResynchronizationRequest{SenderSSRC: 1, MediaSSRC: 3}}))
+	c.Record(CaptureReceived, []byte{0x80})
+
+	f, err := ParseFilter("ssrc==3")
+	assert.NoError(err)
+	var out bytes.Buffer
+	assert.NoError(c.DumpMatching(&out, time.Time{}, CaptureText, f))
+	text := out.String()
+	assert.NotContains(text, "PictureLossIndication")
+	assert.Contains(text, "RapidResynchronizationRequest")
+	// undecodable datagrams are kept
+	assert.Contains(text, "error:")
+}
@@ -0,0 +1,270 @@
+package rtcp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ECN is the Explicit Congestion Notification codepoint of a packet, the
+// two least significant bits of its IPv4 TOS or IPv6 traffic class. See
+// RFC 3168, 5.
+type ECN uint8
+
+// ECN codepoints
+const (
+	ECNNonECT ECN = 0 // Not-ECT, the transport isn't ECN capable
+	ECNECT1   ECN = 1 // ECT(1), used by L4S
+	ECNECT0   ECN = 2 // ECT(0)
+	ECNCE     ECN = 3 // CE, congestion experienced
+)
+
+// ECNFromTrafficClass returns the ECN codepoint of the IPv4 TOS or IPv6
+// traffic class byte tc, as received in the IP_TOS or IPV6_TCLASS socket
+// control message.
+func ECNFromTrafficClass(tc byte) ECN {
+	return ECN(tc & 0x3)
+}
+
+func (e ECN) String() string {
+	switch e {
+	case ECNNonECT:
+		return "Not-ECT"
+	case ECNECT1:
+		return "ECT(1)"
+	case ECNECT0:
+		return "ECT(0)"
+	case ECNCE:
+		return "CE"
+	default:
+		return fmt.Sprintf("ECN(%d)", uint8(e))
+	}
+}
+
+const (
+	// ArrivalTimeOffsetOverRange and ArrivalTimeOffsetUnavailable are the
+	// arrival time offsets of packets that arrived more than 8189/1024
+	// seconds before the report timestamp, or at an unknown time. See RFC
+	// 8888, 3.1
+	ArrivalTimeOffsetOverRange   = 0x1ffe
+	ArrivalTimeOffsetUnavailable = 0x1fff
+
+	ccfbHeaderLength      = headerLength + ssrcLength
+	ccfbBlockHeaderLength = ssrcLength + 4
+	ccfbMetricLength      = 2
+	ccfbTimestampLength   = 4
+	// begin_seq and num_reports cover at most 16384 packets
+	ccfbMaxMetricBlocks = 1 << 14
+)
+
+// A CCFeedbackMetricBlock reports on a single RTP packet.
+type CCFeedbackMetricBlock struct {
+	Received bool
+	// The ECN codepoint the packet arrived with, if received
+	ECN ECN
+	// How long before the report timestamp the packet arrived, in 1/1024
+	// seconds, or one of ArrivalTimeOffsetOverRange and
+	// ArrivalTimeOffsetUnavailable. At most 13 bits.
+	ArrivalTimeOffset uint16
+}
+
+// A CCFeedbackReportBlock reports on consecutive RTP packets of one source.
+type CCFeedbackReportBlock struct {
+	// SSRC of the RTP stream reported on
+	MediaSSRC uint32
+	// RTP sequence number of the packet the first MetricBlock is for
+	BeginSequence uint16
+	// MetricBlocks of packets BeginSequence, BeginSequence+1...
+	MetricBlocks []CCFeedbackMetricBlock
+}
+
+// The CCFeedbackReport packet carries the RTP congestion control feedback
+// of RFC 8888: the arrival time and ECN codepoint of each packet the sender
+// of the feedback received. Unlike TransportLayerCC, it reports on RTP
+// sequence numbers, per source.
+type CCFeedbackReport struct {
+	// SSRC of sender
+	SenderSSRC uint32
+	// Report blocks, one for each source reported on
+	ReportBlocks []CCFeedbackReportBlock
+	// The middle 32 bits of the NTP time the report was built at, which the
+	// arrival time offsets are counted back from
+	ReportTimestamp uint32
+}
+
+// CCFB is a short name for CCFeedbackReport.
+type CCFB = CCFeedbackReport
+
+var _ Packet = (*CCFeedbackReport)(nil) // assert is a Packet
+
+func (b CCFeedbackReportBlock) len() int {
+	n := ccfbBlockHeaderLength + len(b.MetricBlocks)*ccfbMetricLength
+	return (n + 3) &^ 3
+}
+
+func (p CCFeedbackReport) len() int {
+	n := ccfbHeaderLength + ccfbTimestampLength
+	for _, b := range p.ReportBlocks {
+		n += b.len()
+	}
+	return n
+}
+
+// Marshal encodes the CCFeedbackReport packet in binary
+func (p CCFeedbackReport) Marshal() ([]byte, error) {
+	/*
+	 *  0                   1                   2                   3
+	 *  0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |V=2|P| FMT=11  |   PT = 205    |          length               |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |                 SSRC of RTCP packet sender                    |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |                   SSRC of 1st RTP Stream                      |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |          begin_seq            |          num_reports          |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |R|ECN|  Arrival time offset    | ...                           .
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * .                                                               .
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |                   SSRC of nth RTP Stream                      |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * .                                                               .
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |                 Report Timestamp (32bits)                     |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 */
+	size := p.len()
+	if size/4-1 > 0xffff {
+		return nil, errPacketTooLong
+	}
+
+	hData, err := p.Header().Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	rawPacket := make([]byte, size)
+	copy(rawPacket, hData)
+	binary.BigEndian.PutUint32(rawPacket[headerLength:], p.SenderSSRC)
+
+	offset := ccfbHeaderLength
+	for _, b := range p.ReportBlocks {
+		if len(b.MetricBlocks) > ccfbMaxMetricBlocks {
+			return nil, errTooManyStatuses
+		}
+		binary.BigEndian.PutUint32(rawPacket[offset:], b.MediaSSRC)
+		binary.BigEndian.PutUint16(rawPacket[offset+4:], b.BeginSequence)
+		binary.BigEndian.PutUint16(rawPacket[offset+6:], uint16(len(b.MetricBlocks)))
+		for i, m := range b.MetricBlocks {
+			if m.ArrivalTimeOffset > ArrivalTimeOffsetUnavailable {
+				return nil, errBadArrivalOffset
+			}
+			v := uint16(m.ECN&0x3)<<13 | m.ArrivalTimeOffset
+			if m.Received {
+				v |= 1 << 15
+			}
+			binary.BigEndian.PutUint16(rawPacket[offset+ccfbBlockHeaderLength+i*ccfbMetricLength:], v)
+		}
+		offset += b.len()
+	}
+	binary.BigEndian.PutUint32(rawPacket[offset:], p.ReportTimestamp)
+	return rawPacket, nil
+}
+
+// Unmarshal decodes the CCFeedbackReport packet from binary
+func (p *CCFeedbackReport) Unmarshal(rawPacket []byte) error {
+	var h Header
+	if err := h.Unmarshal(rawPacket); err != nil {
+		return err
+	}
+	if h.Type != TypeTransportSpecificFeedback || h.Count != FormatCCFB {
+		return errWrongType
+	}
+
+	end := h.size()
+	if end < ccfbHeaderLength+ccfbTimestampLength || end > len(rawPacket) {
+		return errPacketTooShort
+	}
+	if h.Padding {
+		padding := int(rawPacket[end-1])
+		if padding == 0 || padding%4 != 0 || ccfbHeaderLength+ccfbTimestampLength+padding > end {
+			return errBadPadding
+		}
+		end -= padding
+	}
+	end -= ccfbTimestampLength
+
+	p.SenderSSRC = binary.BigEndian.Uint32(rawPacket[headerLength:])
+	p.ReportTimestamp = binary.BigEndian.Uint32(rawPacket[end:])
+	p.ReportBlocks = nil
+	for offset := ccfbHeaderLength; offset < end; {
+		if offset+ccfbBlockHeaderLength > end {
+			return errPacketTooShort
+		}
+		b := CCFeedbackReportBlock{
+			MediaSSRC:     binary.BigEndian.Uint32(rawPacket[offset:]),
+			BeginSequence: binary.BigEndian.Uint16(rawPacket[offset+4:]),
+		}
+		n := int(binary.BigEndian.Uint16(rawPacket[offset+6:]))
+		if n > ccfbMaxMetricBlocks {
+			return errTooManyStatuses
+		}
+		blockEnd := offset + (ccfbBlockHeaderLength+n*ccfbMetricLength+3)&^3
+		if blockEnd > end {
+			return errPacketTooShort
+		}
+		metrics := rawPacket[offset+ccfbBlockHeaderLength : blockEnd]
+		if n > 0 {
+			b.MetricBlocks = make([]CCFeedbackMetricBlock, n)
+		}
+		for i := range b.MetricBlocks {
+			v := binary.BigEndian.Uint16(metrics[i*ccfbMetricLength:])
+			b.MetricBlocks[i] = CCFeedbackMetricBlock{
+				Received:          v&(1<<15) != 0,
+				ECN:               ECN(v>>13) & 0x3,
+				ArrivalTimeOffset: v & ArrivalTimeOffsetUnavailable,
+			}
+		}
+		offset = blockEnd
+		p.ReportBlocks = append(p.ReportBlocks, b)
+	}
+	return nil
+}
+
+// Header returns the Header associated with this packet.
+func (p *CCFeedbackReport) Header() Header {
+	return Header{
+		Count:  FormatCCFB,
+		Type:   TypeTransportSpecificFeedback,
+		Length: uint16(p.len()/4 - 1),
+	}
+}
+
+// DestinationSSRC returns an array of SSRC values that this packet refers to.
+func (p *CCFeedbackReport) DestinationSSRC() []uint32 {
+	ssrcs := make([]uint32, 0, len(p.ReportBlocks))
+	for _, b := range p.ReportBlocks {
+		ssrcs = append(ssrcs, b.MediaSSRC)
+	}
+	return ssrcs
+}
+
+// MarshalSize returns the size of the packet once marshaled.
+func (p CCFeedbackReport) MarshalSize() int {
+	return p.len()
+}
+
+func (p CCFeedbackReport) String() string {
+	out := fmt.Sprintf("CCFeedbackReport from %x at %08x\n", p.SenderSSRC, p.ReportTimestamp)
+	for _, b := range p.ReportBlocks {
+		received := 0
+		for _, m := range b.MetricBlocks {
+			if m.Received {
+				received++
+			}
+		}
+		out += fmt.Sprintf("\t%x: %d packets from %d, %d received\n", b.MediaSSRC, len(b.MetricBlocks), b.BeginSequence, received)
+	}
+	return out
+}
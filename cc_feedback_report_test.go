@@ -0,0 +1,137 @@
+package rtcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCCFeedbackReportUnmarshal(t *testing.T) {
+	for _, test := range []struct {
+		Name      string
+		Data      []byte
+		Want      CCFeedbackReport
+		WantError error
+	}{
+		{
+			Name: "valid",
+			Data: []byte{
+				// v=2, p=0, FMT=11, TSFB, len=6
+				0x8b, 0xcd, 0x00, 0x06,
+				// sender=0x902f9e2e
+				0x90, 0x2f, 0x9e, 0x2e,
+				// media=0xbc5e9a40
+				0xbc, 0x5e, 0x9a, 0x40,
+				// begin_seq=16, num_reports=3
+				0x00, 0x10, 0x00, 0x03,
+				// received ECT(1) 32/1024s before, lost
+				0xa0, 0x20, 0x00, 0x00,
+				// received CE at an unknown time, padding
+				0xff, 0xff, 0x00, 0x00,
+				// report timestamp
+				0x12, 0x34, 0x56, 0x78,
+			},
+			Want: CCFeedbackReport{
+				SenderSSRC: 0x902f9e2e,
+				ReportBlocks: []CCFeedbackReportBlock{{
+					MediaSSRC:     0xbc5e9a40,
+					BeginSequence: 16,
+					MetricBlocks: []CCFeedbackMetricBlock{
+						{Received: true, ECN: ECNECT1, ArrivalTimeOffset: 0x20},
+						{},
+						{Received: true, ECN: ECNCE, ArrivalTimeOffset: ArrivalTimeOffsetUnavailable},
+					},
+				}},
+				ReportTimestamp: 0x12345678,
+			},
+		},
+		{
+			Name: "no blocks",
+			Data: []byte{
+				0x8b, 0xcd, 0x00, 0x02,
+				0x90, 0x2f, 0x9e, 0x2e,
+				0x12, 0x34, 0x56, 0x78,
+			},
+			Want: CCFeedbackReport{SenderSSRC: 0x902f9e2e, ReportTimestamp: 0x12345678},
+		},
+		{
+			Name: "truncated metric blocks",
+			Data: []byte{
+				0x8b, 0xcd, 0x00, 0x04,
+				0x90, 0x2f, 0x9e, 0x2e,
+				0xbc, 0x5e, 0x9a, 0x40,
+				0x00, 0x10, 0x00, 0x03,
+				0x12, 0x34, 0x56, 0x78,
+			},
+			WantError: errPacketTooShort,
+		},
+		{
+			Name: "missing timestamp",
+			Data: []byte{
+				0x8b, 0xcd, 0x00, 0x01,
+				0x90, 0x2f, 0x9e, 0x2e,
+			},
+			WantError: errPacketTooShort,
+		},
+		{
+			Name: "wrong format",
+			Data: []byte{
+				0x8a, 0xcd, 0x00, 0x02,
+				0x90, 0x2f, 0x9e, 0x2e,
+				0x12, 0x34, 0x56, 0x78,
+			},
+			WantError: errWrongType,
+		},
+	} {
+		var p CCFeedbackReport
+		err := p.Unmarshal(test.Data)
+		if got, want := err, test.WantError; got != want {
+			t.Fatalf("Unmarshal %q: err = %v, want %v", test.Name, got, want)
+		}
+		if err != nil {
+			continue
+		}
+		if got, want := p, test.Want; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Unmarshal %q: got %#v, want %#v", test.Name, got, want)
+		}
+
+		data, err := p.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal %q: %v", test.Name, err)
+		}
+		if !reflect.DeepEqual(data, test.Data) {
+			t.Fatalf("Marshal %q: got %x, want %x", test.Name, data, test.Data)
+		}
+
+		packets, err := Unmarshal(test.Data)
+		if err != nil {
+			t.Fatalf("Unmarshal %q packets: %v", test.Name, err)
+		}
+		if _, ok := packets[0].(*CCFeedbackReport); !ok {
+			t.Fatalf("Unmarshal %q packets: got %T, want *CCFeedbackReport", test.Name, packets[0])
+		}
+	}
+}
+
+func TestCCFeedbackReportMarshalErrors(t *testing.T) {
+	p := CCFeedbackReport{ReportBlocks: []CCFeedbackReportBlock{{
+		MetricBlocks: []CCFeedbackMetricBlock{{Received: true, ArrivalTimeOffset: 0x2000}},
+	}}}
+	if _, err := p.Marshal(); err != errBadArrivalOffset {
+		t.Fatalf("Marshal: err = %v, want %v", err, errBadArrivalOffset)
+	}
+
+	p.ReportBlocks[0].MetricBlocks = make([]CCFeedbackMetricBlock, ccfbMaxMetricBlocks+1)
+	if _, err := p.Marshal(); err != errTooManyStatuses {
+		t.Fatalf("Marshal: err = %v, want %v", err, errTooManyStatuses)
+	}
+}
+
+func TestECNFromTrafficClass(t *testing.T) {
+	// DSCP EF with CE
+	if got := ECNFromTrafficClass(0xb8 | 0x3); got != ECNCE {
+		t.Fatalf("ECNFromTrafficClass = %v, want %v", got, ECNCE)
+	}
+	if got := ECNFromTrafficClass(0x1).String(); got != "ECT(1)" {
+		t.Fatalf("String = %q, want ECT(1)", got)
+	}
+}
@@ -0,0 +1,109 @@
+package rtcp
+
+import "time"
+
+// ToCCFB converts TransportLayerCC feedback to RFC 8888 feedback, as a
+// gateway between transport-cc and RFC 8888 endpoints does. The transport
+// wide sequence numbers become the RTP sequence numbers of MediaSSRC, in
+// report blocks of up to 16384 packets.
+//
+// The conversion loses precision: arrival time offsets have a resolution of
+// 1/1024 seconds rather than 250us, and packets that arrived more than 8189
+// milliseconds before the last one are reported as over range. The report
+// timestamp is the arrival of the last packet on the receiver's clock, which
+// isn't the wallclock, so only differences between arrival times remain
+// meaningful. The feedback packet count is dropped.
+func ToCCFB(t *TransportLayerCC) (*CCFB, error) {
+	if t.PacketStatusCoverage() < int(t.PacketStatusCount) {
+		return nil, errShortStatusList
+	}
+
+	reference := t.reference(false)
+	arrivals := t.ArrivalTimes()
+	deltas := 0
+	t.forEachStatus(func(seq uint16, symbol PacketStatusSymbol) {
+		if symbol == TypePacketReceivedSmallDelta || symbol == TypePacketReceivedLargeDelta {
+			deltas++
+		}
+	})
+	if deltas != len(t.RecvDeltas) {
+		return nil, errDeltaMismatch
+	}
+
+	latest := reference
+	for _, offset := range arrivals {
+		if reference+offset > latest {
+			latest = reference + offset
+		}
+	}
+	// the report timestamp, rounded up to the 1/65536s of compact NTP
+	frac := uint32((latest%time.Second*65536 + time.Second - 1) / time.Second)
+	timestamp := uint32(latest/time.Second)<<16 + frac
+	reportAt := latest.Truncate(time.Second) + compactNTPDuration(frac)
+
+	out := &CCFB{SenderSSRC: t.SenderSSRC, ReportTimestamp: timestamp}
+	var block *CCFeedbackReportBlock
+	t.forEachStatus(func(seq uint16, symbol PacketStatusSymbol) {
+		if block == nil || len(block.MetricBlocks) == ccfbMaxMetricBlocks {
+			out.ReportBlocks = append(out.ReportBlocks, CCFeedbackReportBlock{MediaSSRC: t.MediaSSRC, BeginSequence: seq})
+			block = &out.ReportBlocks[len(out.ReportBlocks)-1]
+		}
+
+		m := CCFeedbackMetricBlock{Received: symbol != TypePacketNotReceived}
+		if m.Received {
+			m.ArrivalTimeOffset = ArrivalTimeOffsetUnavailable
+			if offset, ok := arrivals[seq]; ok {
+				m.ArrivalTimeOffset = arrivalTimeOffset(reportAt - (reference + offset))
+			}
+		}
+		block.MetricBlocks = append(block.MetricBlocks, m)
+	})
+	return out, nil
+}
+
+// FromCCFB converts RFC 8888 feedback on a single RTP stream to
+// TransportLayerCC feedback, the inverse of ToCCFB. The RTP sequence numbers
+// become transport wide sequence numbers.
+//
+// The conversion loses precision: arrivals are quantized to the 250us of
+// receive deltas, packets arriving more than 8 seconds apart, or reported as
+// over range or at an unknown time, are reported as received without a
+// delta, and the ECN codepoints are dropped. The feedback packet count is
+// zero.
+func FromCCFB(c *CCFB) (*TransportLayerCC, error) {
+	if len(c.ReportBlocks) == 0 {
+		return nil, errNoReportBlocks
+	}
+
+	first := c.ReportBlocks[0]
+	b := NewTransportLayerCCBuilder(first.BeginSequence)
+	b.SenderSSRC = c.SenderSSRC
+	b.MediaSSRC = first.MediaSSRC
+	b.Rounding = RoundingNearest
+
+	reportAt := compactNTPDuration(c.ReportTimestamp)
+	for _, block := range c.ReportBlocks {
+		if block.MediaSSRC != first.MediaSSRC {
+			return nil, errMixedSSRCs
+		}
+		for i, m := range block.MetricBlocks {
+			seq := block.BeginSequence + uint16(i)
+			var err error
+			switch {
+			case !m.Received:
+				err = b.AddLost(seq)
+			case m.ArrivalTimeOffset >= ArrivalTimeOffsetOverRange:
+				err = b.AddReceivedWithoutDelta(seq)
+			default:
+				arrival := reportAt - time.Duration(m.ArrivalTimeOffset)*time.Second/1024
+				if err = b.AddReceived(seq, arrival); err == errDeltaExceedLimit {
+					err = b.AddReceivedWithoutDelta(seq)
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b.Build(), nil
+}
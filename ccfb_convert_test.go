@@ -0,0 +1,131 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCCFBConversion(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewTransportLayerCCBuilder(65534)
+	b.SenderSSRC, b.MediaSSRC, b.FbPktCount = 1, 2, 7
+	base := 1024 * time.Millisecond
+	assert.NoError(b.AddReceived(65534, base+250*time.Microsecond))
+	assert.NoError(b.AddReceived(0, base+3*time.Millisecond))
+	assert.NoError(b.AddReceivedWithoutDelta(1))
+	assert.NoError(b.AddReceived(2, base+40*time.Millisecond))
+	assert.NoError(b.AddLost(3))
+	tcc := b.Build()
+
+	ccfb, err := ToCCFB(tcc)
+	assert.NoError(err)
+	assert.Equal(uint32(1), ccfb.SenderSSRC)
+	assert.Len(ccfb.ReportBlocks, 1)
+	block := ccfb.ReportBlocks[0]
+	assert.Equal(uint32(2), block.MediaSSRC)
+	assert.Equal(uint16(65534), block.BeginSequence)
+	assert.Len(block.MetricBlocks, 6)
+	assert.False(block.MetricBlocks[1].Received)
+	assert.Equal(ArrivalTimeOffsetUnavailable, int(block.MetricBlocks[3].ArrivalTimeOffset))
+	assert.False(block.MetricBlocks[5].Received)
+	// the last arrival is the report timestamp, rounded up
+	assert.Equal(uint16(0), block.MetricBlocks[4].ArrivalTimeOffset)
+	// 39.75ms
+	assert.Equal(uint16(41), block.MetricBlocks[0].ArrivalTimeOffset)
+
+	// and back, within the precision of the arrival time offsets
+	back, err := FromCCFB(ccfb)
+	assert.NoError(err)
+	assert.Equal(tcc.MediaSSRC, back.MediaSSRC)
+	assert.Equal(tcc.BaseSequenceNumber, back.BaseSequenceNumber)
+	assert.Equal(tcc.PacketStatusCount, back.PacketStatusCount)
+	assert.Equal(tcc.Lost(), back.Lost())
+	want, got := tcc.ArrivalTimes(), back.ArrivalTimes()
+	assert.Len(got, len(want))
+	for _, seq := range []uint16{0, 2} {
+		wantDelta := want[seq] - want[65534]
+		gotDelta := got[seq] - got[65534]
+		assert.InDelta(wantDelta, gotDelta, float64(time.Second/1024), "seq %d", seq)
+	}
+	_, err = back.Marshal()
+	assert.NoError(err)
+}
+
+func TestCCFBConversionLargeFeedback(t *testing.T) {
+	assert := assert.New(t)
+
+	// 20000 packets need two report blocks, and arrivals 13s apart are over
+	// range
+	b := NewTransportLayerCCBuilder(0)
+	assert.NoError(b.AddReceived(0, 0))
+	for seq := uint16(1); seq < 20000; seq++ {
+		assert.NoError(b.AddReceived(seq, 8*time.Second+time.Duration(seq)*time.Millisecond/4))
+	}
+	tcc := b.Build()
+	ccfb, err := ToCCFB(tcc)
+	assert.NoError(err)
+	assert.Len(ccfb.ReportBlocks, 2)
+	assert.Len(ccfb.ReportBlocks[0].MetricBlocks, ccfbMaxMetricBlocks)
+	assert.Equal(uint16(ccfbMaxMetricBlocks), ccfb.ReportBlocks[1].BeginSequence)
+	assert.Equal(uint16(ArrivalTimeOffsetOverRange), ccfb.ReportBlocks[0].MetricBlocks[0].ArrivalTimeOffset)
+
+	back, err := FromCCFB(ccfb)
+	assert.NoError(err)
+	assert.Equal(uint16(20000), back.PacketStatusCount)
+	_, ok := back.ArrivalTimes()[0]
+	assert.False(ok)
+	assert.Len(back.ArrivalTimes(), 19999)
+}
+
+func TestCCFBConversionErrors(t *testing.T) {
+	_, err := ToCCFB(&TransportLayerCC{PacketStatusCount: 2, PacketChunks: []PacketStatusChunk{
+		&RunLengthChunk{PacketStatusSymbol: TypePacketReceivedSmallDelta, RunLength: 1},
+	}})
+	assert.Equal(t, errShortStatusList, err)
+
+	_, err = ToCCFB(&TransportLayerCC{PacketStatusCount: 1, PacketChunks: []PacketStatusChunk{
+		&RunLengthChunk{PacketStatusSymbol: TypePacketReceivedSmallDelta, RunLength: 1},
+	}})
+	assert.Equal(t, errDeltaMismatch, err)
+
+	_, err = FromCCFB(&CCFB{})
+	assert.Equal(t, errNoReportBlocks, err)
+
+	_, err = FromCCFB(&CCFB{ReportBlocks: []CCFeedbackReportBlock{{MediaSSRC: 1}, {MediaSSRC: 2}}})
+	assert.Equal(t, errMixedSSRCs, err)
+
+	_, err = FromCCFB(&CCFB{ReportBlocks: []CCFeedbackReportBlock{
+		{MetricBlocks: make([]CCFeedbackMetricBlock, 2)},
+		{MetricBlocks: make([]CCFeedbackMetricBlock, 2)},
+	}})
+	assert.Equal(t, errStatusOutOfOrder, err)
+}
+
+// Feedback built by a Recorder reports the same packets either way.
+func TestCCFBRecorderParity(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	twcc, ccfb := NewRecorder(1), NewRecorder(1)
+	for _, r := range []*Recorder{twcc, ccfb} {
+		r.MediaSSRC = 2
+		for i, seq := range []uint16{100, 101, 103, 104, 107} {
+			r.Record(seq, start.Add(time.Duration(i)*7*time.Millisecond))
+		}
+	}
+	fromTWCC, err := ToCCFB(twcc.BuildFeedback()[0])
+	assert.NoError(err)
+	direct := ccfb.BuildCCFeedbackReport(start.Add(28 * time.Millisecond))
+
+	assert.Len(direct.ReportBlocks, 1)
+	assert.Equal(direct.ReportBlocks[0].BeginSequence, fromTWCC.ReportBlocks[0].BeginSequence)
+	assert.Len(fromTWCC.ReportBlocks[0].MetricBlocks, len(direct.ReportBlocks[0].MetricBlocks))
+	for i, m := range direct.ReportBlocks[0].MetricBlocks {
+		converted := fromTWCC.ReportBlocks[0].MetricBlocks[i]
+		assert.Equal(m.Received, converted.Received, "packet %d", i)
+		assert.InDelta(m.ArrivalTimeOffset, converted.ArrivalTimeOffset, 1, "packet %d", i)
+	}
+}
@@ -0,0 +1,30 @@
+package rtcp
+
+import "time"
+
+// A Clock provides the current time to the time-based components of this
+// package, such as the BandwidthAccountant, MemberTable, Recorder and
+// statistics. Embedders can supply a monotonic source, and tests a fake one.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts an ordinary function to a Clock.
+type ClockFunc func() time.Time
+
+// Now calls f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock used when none is configured. It returns
+// time.Now, which carries a monotonic reading.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// clockNow returns the time from c, falling back to SystemClock if c is nil
+func clockNow(c Clock) time.Time {
+	if c == nil {
+		return SystemClock.Now()
+	}
+	return c.Now()
+}
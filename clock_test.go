@@ -0,0 +1,18 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	fixed := time.Unix(42, 0)
+	if got := clockNow(ClockFunc(func() time.Time { return fixed })); !got.Equal(fixed) {
+		t.Fatalf("clockNow(ClockFunc) = %v, want %v", got, fixed)
+	}
+
+	before := time.Now()
+	if got := clockNow(nil); got.Before(before) {
+		t.Fatalf("clockNow(nil) = %v, want at least %v", got, before)
+	}
+}
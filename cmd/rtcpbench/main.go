@@ -0,0 +1,174 @@
+// Command rtcpbench generates RTCP load and measures how fast it parses, for
+// capacity planning of SFUs. It synthesizes the compound packets a receiver
+// sends for a number of media streams, with configurable loss, and either
+// sends them to a UDP target, receives and decodes them, or decodes them in
+// process without any network I/O.
+//
+//	rtcpbench -listen :5005
+//	rtcpbench -send 127.0.0.1:5005 -rate 20000 -streams 4 -loss 0.02
+//	rtcpbench -local -duration 5s
+//
+// Each compound packet carries an RR with a report block per stream, an
+// SDES CNAME, transport wide feedback, NACKs for lost packets and a REMB.
+// Loss follows a Gilbert-Elliott model set by -loss, -burst-start and
+// -burst-end.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// distinctDatagrams is how many different compound packets are synthesized
+// up front and cycled through, so generation doesn't limit the send rate
+const distinctDatagrams = 1024
+
+var errMode = errors.New("exactly one of -send, -listen and -local is required")
+
+type options struct {
+	send     string
+	listen   string
+	local    bool
+	rate     int
+	duration time.Duration
+	workload workloadConfig
+}
+
+func main() {
+	var o options
+	flag.StringVar(&o.send, "send", "", "send compound RTCP to this UDP address")
+	flag.StringVar(&o.listen, "listen", "", "receive and decode RTCP on this UDP address")
+	flag.BoolVar(&o.local, "local", false, "decode synthesized RTCP in process, without network I/O")
+	flag.IntVar(&o.rate, "rate", 10000, "compound packets per second to send, 0 for as fast as possible")
+	flag.DurationVar(&o.duration, "duration", 10*time.Second, "how long to run; with -listen, 0 runs until interrupted")
+	flag.IntVar(&o.workload.Streams, "streams", 2, "media streams reported on")
+	flag.IntVar(&o.workload.PacketsPerReport, "packets", 50, "media packets per stream covered by each compound packet")
+	flag.Float64Var(&o.workload.Loss, "loss", 0.01, "probability of losing a media packet")
+	flag.Float64Var(&o.workload.BurstStart, "burst-start", 0, "probability of entering a loss burst at each media packet")
+	flag.Float64Var(&o.workload.BurstEnd, "burst-end", 0.5, "probability of leaving a loss burst at each media packet")
+	flag.Int64Var(&o.workload.Seed, "seed", 1, "seed of the loss model")
+	flag.Parse()
+
+	if err := run(o, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "rtcpbench:", err)
+		os.Exit(1) // nolint
+	}
+}
+
+func run(o options, w io.Writer) error {
+	modes := 0
+	for _, set := range []bool{o.send != "", o.listen != "", o.local} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
+		return errMode
+	}
+
+	if o.listen != "" {
+		return listen(o.listen, o.duration, w)
+	}
+
+	datagrams, err := generate(o.workload, distinctDatagrams)
+	if err != nil {
+		return err
+	}
+	if o.local {
+		return local(datagrams, o.duration, w)
+	}
+	return send(o.send, datagrams, o.rate, o.duration, w)
+}
+
+// local decodes datagrams in a loop for the given duration
+func local(datagrams [][]byte, duration time.Duration, w io.Writer) error {
+	r := newReceiver()
+	start := time.Now()
+	for i := 0; time.Since(start) < duration; i++ {
+		r.receive(datagrams[i%len(datagrams)])
+	}
+	return r.stats.report(w, time.Since(start))
+}
+
+// send writes datagrams to addr at rate per second for the given duration
+func send(addr string, datagrams [][]byte, rate int, duration time.Duration, w io.Writer) error {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close() // nolint:errcheck
+
+	var interval time.Duration
+	if rate > 0 {
+		interval = time.Second / time.Duration(rate)
+	}
+
+	var stats parseStats
+	start := time.Now()
+	for i := 0; ; i++ {
+		elapsed := time.Since(start)
+		if elapsed >= duration {
+			break
+		}
+		// sleep in coarse steps; at high rates several datagrams go out
+		// back to back to catch up
+		if ahead := time.Duration(i)*interval - elapsed; ahead > time.Millisecond {
+			time.Sleep(ahead)
+		}
+
+		b := datagrams[i%len(datagrams)]
+		if _, err := conn.Write(b); err != nil {
+			stats.Errors++
+			continue
+		}
+		stats.Datagrams++
+		stats.Bytes += len(b)
+	}
+
+	_, err = fmt.Fprintf(w, "sent %d datagrams (%.0f/s), %.2f MB/s, %d write errors\n",
+		stats.Datagrams, float64(stats.Datagrams)/duration.Seconds(),
+		float64(stats.Bytes)/duration.Seconds()/1e6, stats.Errors)
+	return err
+}
+
+// listen decodes datagrams received on addr, reporting once per second
+func listen(addr string, duration time.Duration, w io.Writer) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close() // nolint:errcheck
+
+	r := newReceiver()
+	buf := make([]byte, 65536)
+	start := time.Now()
+	lastReport, last := start, parseStats{}
+	for duration == 0 || time.Since(start) < duration {
+		if err := conn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+			return err
+		}
+		n, _, err := conn.ReadFrom(buf)
+		if err == nil {
+			r.receive(buf[:n])
+		} else if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+			return err
+		}
+
+		if now := time.Now(); now.Sub(lastReport) >= time.Second {
+			if err := r.stats.sub(last).report(w, now.Sub(lastReport)); err != nil {
+				return err
+			}
+			lastReport, last = now, r.stats
+		}
+	}
+
+	if _, err := fmt.Fprint(w, "total: "); err != nil {
+		return err
+	}
+	return r.stats.report(w, time.Since(start))
+}
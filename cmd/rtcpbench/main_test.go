@@ -0,0 +1,19 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunModes(t *testing.T) {
+	assert.Equal(t, errMode, run(options{}, &bytes.Buffer{}))
+	assert.Equal(t, errMode, run(options{local: true, send: "127.0.0.1:1"}, &bytes.Buffer{}))
+
+	var out bytes.Buffer
+	assert.NoError(t, run(options{local: true, duration: 10 * time.Millisecond, workload: workloadConfig{Streams: 1}}, &out))
+	assert.True(t, strings.Contains(out.String(), " 0 errors"), out.String())
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+// parseStats counts what a receiver decoded
+type parseStats struct {
+	Datagrams int
+	Packets   int
+	Bytes     int
+	Errors    int
+	// time spent decoding, excluding I/O
+	Elapsed time.Duration
+}
+
+// receiver decodes datagrams with a reused Decoder, as an SFU would
+type receiver struct {
+	decoder *rtcp.Decoder
+	packets []rtcp.Packet
+	stats   parseStats
+}
+
+func newReceiver() *receiver {
+	return &receiver{decoder: rtcp.NewDecoder()}
+}
+
+func (r *receiver) receive(b []byte) {
+	start := time.Now()
+	packets, err := r.decoder.Decode(b, r.packets)
+	r.stats.Elapsed += time.Since(start)
+
+	r.stats.Datagrams++
+	r.stats.Bytes += len(b)
+	if err != nil {
+		r.stats.Errors++
+		return
+	}
+	r.packets = packets
+	r.stats.Packets += len(packets)
+}
+
+// report writes the throughput of s over the wall clock interval
+func (s parseStats) report(w io.Writer, interval time.Duration) error {
+	seconds := interval.Seconds()
+	if seconds <= 0 {
+		seconds = 1
+	}
+	parse := 0.0
+	if s.Elapsed > 0 {
+		parse = float64(s.Packets) / s.Elapsed.Seconds()
+	}
+	_, err := fmt.Fprintf(w, "%d datagrams (%.0f/s), %d packets (%.0f/s), %.2f MB/s, %d errors, parse %.0f packets/s\n",
+		s.Datagrams, float64(s.Datagrams)/seconds,
+		s.Packets, float64(s.Packets)/seconds,
+		float64(s.Bytes)/seconds/1e6, s.Errors, parse)
+	return err
+}
+
+func (s parseStats) sub(prev parseStats) parseStats {
+	return parseStats{
+		Datagrams: s.Datagrams - prev.Datagrams,
+		Packets:   s.Packets - prev.Packets,
+		Bytes:     s.Bytes - prev.Bytes,
+		Errors:    s.Errors - prev.Errors,
+		Elapsed:   s.Elapsed - prev.Elapsed,
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtcp/seqnum"
+	"github.com/pion/rtcp/sim"
+)
+
+const (
+	senderSSRC   = 0x5ee0
+	firstSSRC    = 0x1000
+	mediaSize    = 1200
+	rembBitrate  = 2500000
+	benchCNAME   = "rtcpbench"
+	mediaSpacing = time.Millisecond
+)
+
+// workloadConfig describes the media traffic the synthesized RTCP reports on
+type workloadConfig struct {
+	// Number of media streams
+	Streams int
+	// Media packets per stream covered by each compound packet
+	PacketsPerReport int
+	// Random loss probability, and Gilbert-Elliott burst probabilities
+	Loss       float64
+	BurstStart float64
+	BurstEnd   float64
+	Seed       int64
+}
+
+// workload synthesizes the compound RTCP a receiver would send for a set of
+// media streams sharing a transport: an RR with a report block per stream,
+// an SDES CNAME, transport wide feedback, NACKs for the lost packets and a
+// REMB.
+type workload struct {
+	config   workloadConfig
+	link     *sim.Link
+	recorder *rtcp.Recorder
+	epoch    time.Time
+	now      time.Duration
+
+	transportSeq uint16
+	streams      []stream
+}
+
+type stream struct {
+	ssrc    uint32
+	seq     uint16
+	tracker seqnum.Tracker
+	lost    []uint16
+}
+
+func newWorkload(config workloadConfig) *workload {
+	if config.Streams < 1 {
+		config.Streams = 1
+	}
+	if config.PacketsPerReport < 1 {
+		config.PacketsPerReport = 1
+	}
+
+	link := sim.NewLink(0, config.Seed)
+	link.Loss = config.Loss
+	link.BurstStart = config.BurstStart
+	link.BurstEnd = config.BurstEnd
+
+	w := &workload{
+		config:   config,
+		link:     link,
+		recorder: rtcp.NewRecorder(senderSSRC),
+		epoch:    time.Unix(0, 0),
+		streams:  make([]stream, config.Streams),
+	}
+	w.recorder.UseLatestMediaSSRC = true
+	for i := range w.streams {
+		w.streams[i] = stream{
+			ssrc:    firstSSRC + uint32(i),
+			tracker: seqnum.Tracker{MinSequential: 1},
+		}
+	}
+	return w
+}
+
+// next sends the media of one reporting interval over the link, and returns
+// the marshaled compound packet reporting on it
+func (w *workload) next() ([]byte, error) {
+	for i := 0; i < w.config.PacketsPerReport*len(w.streams); i++ {
+		s := &w.streams[i%len(w.streams)]
+		s.seq++
+		w.transportSeq++
+		w.now += mediaSpacing
+
+		arrival, ok := w.link.Transmit(sim.SendRecord{Seq: w.transportSeq, SendTime: w.now, Size: mediaSize})
+		if !ok {
+			s.lost = append(s.lost, s.seq)
+			continue
+		}
+		s.tracker.Update(s.seq)
+		w.recorder.RecordSSRC(w.transportSeq, s.ssrc, w.epoch.Add(arrival))
+	}
+
+	rr := &rtcp.ReceiverReport{SSRC: senderSSRC}
+	ssrcs := make([]uint32, 0, len(w.streams))
+	for i := range w.streams {
+		s := &w.streams[i]
+		expected, received := s.tracker.Interval()
+		rr.Reports = append(rr.Reports, rtcp.ReceptionReport{
+			SSRC:               s.ssrc,
+			FractionLost:       rtcp.FractionLost(expected, received),
+			TotalLost:          rtcp.CumulativeLost(s.tracker.Expected(), s.tracker.Received()),
+			LastSequenceNumber: s.tracker.ExtendedHighest(),
+		})
+		ssrcs = append(ssrcs, s.ssrc)
+	}
+
+	sdes := rtcp.NewSourceDescriptionBuilder()
+	if err := sdes.AddCNAME(benchCNAME, senderSSRC); err != nil {
+		return nil, err
+	}
+	descriptions, err := sdes.Build(0)
+	if err != nil {
+		return nil, err
+	}
+
+	pkts := []rtcp.Packet{rr}
+	for _, d := range descriptions {
+		pkts = append(pkts, d)
+	}
+	for _, fb := range w.recorder.BuildFeedback() {
+		pkts = append(pkts, fb)
+	}
+	for i := range w.streams {
+		s := &w.streams[i]
+		if len(s.lost) == 0 {
+			continue
+		}
+		pkts = append(pkts, &rtcp.TransportLayerNack{
+			SenderSSRC: senderSSRC,
+			MediaSSRC:  s.ssrc,
+			Nacks:      rtcp.NackPairsFromSequenceNumbers(s.lost),
+		})
+		s.lost = s.lost[:0]
+	}
+	pkts = append(pkts, &rtcp.ReceiverEstimatedMaximumBitrate{
+		SenderSSRC: senderSSRC,
+		Bitrate:    rembBitrate,
+		SSRCs:      ssrcs,
+	})
+
+	return rtcp.Marshal(pkts)
+}
+
+// generate returns n datagrams of the workload
+func generate(config workloadConfig, n int) ([][]byte, error) {
+	w := newWorkload(config)
+	out := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		b, err := w.next()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkload(t *testing.T) {
+	assert := assert.New(t)
+
+	datagrams, err := generate(workloadConfig{Streams: 3, PacketsPerReport: 40, Loss: 0.1, Seed: 7}, 20)
+	assert.NoError(err)
+	assert.Len(datagrams, 20)
+
+	lost, nacked := 0, 0
+	for _, b := range datagrams {
+		pkts, err := rtcp.Unmarshal(b)
+		assert.NoError(err)
+
+		if assert.IsType(&rtcp.ReceiverReport{}, pkts[0]) {
+			rr := pkts[0].(*rtcp.ReceiverReport)
+			assert.Len(rr.Reports, 3)
+		}
+		assert.IsType(&rtcp.SourceDescription{}, pkts[1])
+		assert.IsType(&rtcp.ReceiverEstimatedMaximumBitrate{}, pkts[len(pkts)-1])
+
+		for _, p := range pkts {
+			switch p := p.(type) {
+			case *rtcp.TransportLayerCC:
+				lost += len(p.Lost())
+			case *rtcp.TransportLayerNack:
+				for _, pair := range p.Nacks {
+					nacked += len(pair.PacketList())
+				}
+			}
+		}
+	}
+
+	// about 10% of 2400 media packets are lost; TWCC can't report a loss
+	// before the first packet of a feedback, so it may see a few less
+	assert.InDelta(240, nacked, 80)
+	assert.InDelta(nacked, lost, 20)
+}
+
+func TestWorkloadLossless(t *testing.T) {
+	datagrams, err := generate(workloadConfig{Streams: 1, PacketsPerReport: 10}, 5)
+	assert.NoError(t, err)
+	for _, b := range datagrams {
+		pkts, err := rtcp.Unmarshal(b)
+		assert.NoError(t, err)
+		for _, p := range pkts {
+			_, isNack := p.(*rtcp.TransportLayerNack)
+			assert.False(t, isNack)
+		}
+		rr := pkts[0].(*rtcp.ReceiverReport)
+		assert.Equal(t, uint8(0), rr.Reports[0].FractionLost)
+	}
+}
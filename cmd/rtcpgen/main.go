@@ -0,0 +1,178 @@
+// Command rtcpgen constructs RTCP packets from a JSON description and
+// writes the raw bytes, or sends them over UDP. It is useful for fuzzing
+// peers and for interop testing.
+//
+//	rtcpgen -in packets.json -hex
+//	rtcpgen -in packets.json -udp 127.0.0.1:5005
+//
+// A description lists packets of type twcc, remb, nack, pli, rrr or bye:
+//
+//	{"packets": [
+//	  {"type": "twcc", "sender_ssrc": 1, "media_ssrc": 2,
+//	   "base_seq": 100, "count": 20, "lost": ["103", "110-112"]},
+//	  {"type": "remb", "sender_ssrc": 1, "bitrate": 1500000, "ssrcs": [2]}
+//	]}
+//
+// All packets are written as a single datagram unless -split is given.
+//
+// With -vectors go or -vectors hex, rtcpgen instead writes annotated test
+// vectors of every packet type the library implements, for checking other
+// implementations against it.
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+
+	"github.com/pion/rtcp"
+)
+
+func main() {
+	in := flag.String("in", "-", "description file, or - for stdin")
+	out := flag.String("out", "-", "output file, or - for stdout")
+	hexOut := flag.Bool("hex", false, "write hex instead of raw bytes, one datagram per line")
+	udp := flag.String("udp", "", "send to this UDP address instead of writing to -out")
+	split := flag.Bool("split", false, "write every packet as its own datagram")
+	vectors := flag.String("vectors", "", "write annotated test vectors as go or hex and exit")
+	flag.Parse()
+
+	if *vectors != "" {
+		if err := writeVectors(os.Stdout, *vectors); err != nil {
+			fmt.Fprintln(os.Stderr, "rtcpgen:", err)
+			os.Exit(1) // nolint
+		}
+		return
+	}
+
+	if err := run(*in, *out, *udp, *hexOut, *split); err != nil {
+		fmt.Fprintln(os.Stderr, "rtcpgen:", err)
+		os.Exit(1) // nolint
+	}
+}
+
+func run(in, out, udp string, hexOut, split bool) error {
+	data, err := readInput(in)
+	if err != nil {
+		return err
+	}
+
+	datagrams, err := generate(data, split)
+	if err != nil {
+		return err
+	}
+
+	if udp != "" {
+		return send(udp, datagrams)
+	}
+
+	w := io.Writer(os.Stdout)
+	if out != "-" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close() // nolint:errcheck
+		w = f
+	}
+	return write(w, datagrams, hexOut)
+}
+
+func readInput(in string) ([]byte, error) {
+	if in == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(in) // nolint:gosec
+}
+
+// generate parses a description and marshals its packets into datagrams
+func generate(data []byte, split bool) ([][]byte, error) {
+	d, err := parseDescription(data)
+	if err != nil {
+		return nil, err
+	}
+	pkts, err := d.build()
+	if err != nil {
+		return nil, err
+	}
+
+	if !split {
+		b, err := rtcp.Marshal(pkts)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{b}, nil
+	}
+
+	out := make([][]byte, 0, len(pkts))
+	for _, p := range pkts {
+		b, err := p.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+func write(w io.Writer, datagrams [][]byte, hexOut bool) error {
+	for _, b := range datagrams {
+		if hexOut {
+			if _, err := fmt.Fprintln(w, hex.EncodeToString(b)); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeVectors writes the test vectors of the library in the given style
+func writeVectors(w io.Writer, style string) error {
+	var s rtcp.VectorStyle
+	switch style {
+	case "go":
+		s = rtcp.VectorGo
+	case "hex":
+		s = rtcp.VectorHex
+	default:
+		return fmt.Errorf("%w: %q", errVectorStyle, style)
+	}
+	vectors, err := rtcp.TestVectors()
+	if err != nil {
+		return err
+	}
+	for i, v := range vectors {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, v.Annotated(s)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func send(addr string, datagrams [][]byte) error {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close() // nolint:errcheck
+
+	for _, b := range datagrams {
+		if _, err := conn.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+var (
+	errUnknownType = errors.New("unknown packet type")
+	errBadRange    = errors.New("sequence ranges must look like 10 or 10-20")
+	errNoPackets   = errors.New("description contains no packets")
+	errVectorStyle = errors.New("test vectors must be written as go or hex")
+)
+
+// defaultInterval is the arrival spacing of TWCC packets if none is given
+const defaultInterval = time.Millisecond
+
+// A description lists the packets to generate. It is read from JSON.
+type description struct {
+	Packets []packetDescription `json:"packets"`
+}
+
+// A packetDescription describes a single packet. Which fields are used
+// depends on Type:
+//
+//	twcc: sender_ssrc, media_ssrc, base_seq, count, lost, interval_us
+//	remb: sender_ssrc, bitrate, ssrcs
+//	nack: sender_ssrc, media_ssrc, lost
+//	pli, rrr: sender_ssrc, media_ssrc
+//	bye: sources, reason
+type packetDescription struct {
+	Type       string `json:"type"`
+	SenderSSRC uint32 `json:"sender_ssrc"`
+	MediaSSRC  uint32 `json:"media_ssrc"`
+
+	// The first sequence number and number of packets reported by twcc
+	BaseSeq uint16 `json:"base_seq"`
+	Count   int    `json:"count"`
+	// Lost sequence numbers, as single numbers or inclusive ranges such as
+	// "100-110"
+	Lost []string `json:"lost"`
+	// Spacing of arrivals reported by twcc in microseconds
+	IntervalUS int `json:"interval_us"`
+
+	// Estimated bitrate in bits per second and the SSRCs it applies to
+	Bitrate uint64   `json:"bitrate"`
+	SSRCs   []uint32 `json:"ssrcs"`
+
+	Sources []uint32 `json:"sources"`
+	Reason  string   `json:"reason"`
+}
+
+// parseDescription parses a JSON description, rejecting unknown fields
+func parseDescription(data []byte) (*description, error) {
+	d := &description{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(d); err != nil {
+		return nil, err
+	}
+	if len(d.Packets) == 0 {
+		return nil, errNoPackets
+	}
+	return d, nil
+}
+
+// build constructs the packets of the description
+func (d *description) build() ([]rtcp.Packet, error) {
+	var out []rtcp.Packet
+	for i, p := range d.Packets {
+		pkts, err := p.build()
+		if err != nil {
+			return nil, fmt.Errorf("packet %d (%s): %v", i, p.Type, err)
+		}
+		out = append(out, pkts...)
+	}
+	return out, nil
+}
+
+func (p packetDescription) build() ([]rtcp.Packet, error) {
+	switch strings.ToLower(p.Type) {
+	case "twcc":
+		return p.buildTWCC()
+	case "remb":
+		return []rtcp.Packet{&rtcp.ReceiverEstimatedMaximumBitrate{
+			SenderSSRC: p.SenderSSRC,
+			Bitrate:    p.Bitrate,
+			SSRCs:      p.SSRCs,
+		}}, nil
+	case "nack":
+		lost, err := parseRanges(p.Lost)
+		if err != nil {
+			return nil, err
+		}
+		return []rtcp.Packet{&rtcp.TransportLayerNack{
+			SenderSSRC: p.SenderSSRC,
+			MediaSSRC:  p.MediaSSRC,
+			Nacks:      nackPairs(lost),
+		}}, nil
+	case "pli":
+		return []rtcp.Packet{&rtcp.PictureLossIndication{SenderSSRC: p.SenderSSRC, MediaSSRC: p.MediaSSRC}}, nil
+	case "rrr":
+		return []rtcp.Packet{&rtcp.RapidResynchronizationRequest{SenderSSRC: p.SenderSSRC, MediaSSRC: p.MediaSSRC}}, nil
+	case "bye":
+		return []rtcp.Packet{&rtcp.Goodbye{Sources: p.Sources, Reason: p.Reason}}, nil
+	default:
+		return nil, errUnknownType
+	}
+}
+
+// buildTWCC reports count packets from base_seq, of which the ones listed in
+// lost are missing. Feedback can't start with a lost packet, so losses at
+// the start of the range are not reported.
+func (p packetDescription) buildTWCC() ([]rtcp.Packet, error) {
+	lost, err := parseRanges(p.Lost)
+	if err != nil {
+		return nil, err
+	}
+	isLost := map[uint16]bool{}
+	for _, seq := range lost {
+		isLost[seq] = true
+	}
+
+	interval := defaultInterval
+	if p.IntervalUS != 0 {
+		interval = time.Duration(p.IntervalUS) * time.Microsecond
+	}
+
+	r := rtcp.NewRecorder(p.SenderSSRC)
+	r.MediaSSRC = p.MediaSSRC
+	start := time.Unix(0, 0)
+	for i := 0; i < p.Count; i++ {
+		seq := p.BaseSeq + uint16(i)
+		if !isLost[seq] {
+			r.Record(seq, start.Add(time.Duration(i)*interval))
+		}
+	}
+
+	var out []rtcp.Packet
+	for _, fb := range r.BuildFeedback() {
+		out = append(out, fb)
+	}
+	return out, nil
+}
+
+// parseRanges parses sequence numbers and inclusive ranges of them
+func parseRanges(ranges []string) ([]uint16, error) {
+	var out []uint16
+	for _, r := range ranges {
+		parts := strings.SplitN(r, "-", 2)
+		first, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 16)
+		if err != nil {
+			return nil, errBadRange
+		}
+		last := first
+		if len(parts) == 2 {
+			if last, err = strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 16); err != nil || last < first {
+				return nil, errBadRange
+			}
+		}
+		for seq := first; seq <= last; seq++ {
+			out = append(out, uint16(seq))
+		}
+	}
+	return out, nil
+}
+
+// nackPairs packs sequence numbers into NackPairs
+func nackPairs(seqs []uint16) []rtcp.NackPair {
+	sorted := append([]uint16{}, seqs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return rtcp.NackPairsFromSequenceNumbers(sorted)
+}
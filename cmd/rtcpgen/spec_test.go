@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDescription(t *testing.T) {
+	d, err := parseDescription([]byte(`{"packets": [
+  {"type": "twcc", "sender_ssrc": 1, "media_ssrc": 2, "base_seq": 100, "count": 10,
+   "lost": ["103", "105-106"], "interval_us": 2000},
+  {"type": "remb", "sender_ssrc": 1, "bitrate": 1500000, "ssrcs": [2, 3]},
+  {"type": "nack", "sender_ssrc": 1, "media_ssrc": 2, "lost": ["10-12", "30"]},
+  {"type": "pli", "sender_ssrc": 1, "media_ssrc": 2},
+  {"type": "bye", "sources": [1], "reason": "done"}
+]}`))
+	assert.NoError(t, err)
+
+	pkts, err := d.build()
+	assert.NoError(t, err)
+	assert.Len(t, pkts, 5)
+
+	twcc := pkts[0].(*rtcp.TransportLayerCC)
+	assert.Equal(t, uint16(100), twcc.BaseSequenceNumber)
+	assert.Equal(t, uint16(10), twcc.PacketStatusCount)
+	assert.Equal(t, []uint16{103, 105, 106}, twcc.Lost())
+	assert.Len(t, twcc.ArrivalTimes(), 7)
+
+	assert.Equal(t, &rtcp.ReceiverEstimatedMaximumBitrate{SenderSSRC: 1, Bitrate: 1500000, SSRCs: []uint32{2, 3}}, pkts[1])
+	assert.Equal(t, &rtcp.TransportLayerNack{
+		SenderSSRC: 1,
+		MediaSSRC:  2,
+		Nacks:      []rtcp.NackPair{{PacketID: 10, LostPackets: 0x3}, {PacketID: 30}},
+	}, pkts[2])
+	assert.Equal(t, &rtcp.PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}, pkts[3])
+	assert.Equal(t, &rtcp.Goodbye{Sources: []uint32{1}, Reason: "done"}, pkts[4])
+}
+
+func TestBuildJSON(t *testing.T) {
+	datagrams, err := generate([]byte(`{"packets": [{"type": "rrr", "sender_ssrc": 1, "media_ssrc": 2}, {"type": "pli", "media_ssrc": 3}]}`), true)
+	assert.NoError(t, err)
+	assert.Len(t, datagrams, 2)
+
+	pkts, err := rtcp.Unmarshal(datagrams[0])
+	assert.NoError(t, err)
+	assert.Equal(t, []rtcp.Packet{&rtcp.RapidResynchronizationRequest{SenderSSRC: 1, MediaSSRC: 2}}, pkts)
+
+	datagrams, err = generate([]byte(`{"packets": [{"type": "rrr"}, {"type": "pli"}]}`), false)
+	assert.NoError(t, err)
+	assert.Len(t, datagrams, 1)
+	assert.Len(t, datagrams[0], 24)
+}
+
+func TestBuildErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"packets": []}`,
+		`{"packets": [{"type": "fir"}]}`,
+		`{"packets": [{"type": "pli", "unknown": 1}]}`,
+		`{"packets": [{"type": "nack", "lost": ["20-10"]}]}`,
+		`{"packets": [{"type": "nack", "lost": ["x"]}]}`,
+		`packets: [{type: pli}]`,
+	} {
+		if _, err := generate([]byte(data), false); err == nil {
+			t.Fatalf("generate(%q) succeeded, want error", data)
+		}
+	}
+}
+
+func TestNackPairs(t *testing.T) {
+	assert.Equal(t, []rtcp.NackPair{
+		{PacketID: 1, LostPackets: 1 << 15},
+		{PacketID: 18},
+	}, nackPairs([]uint16{18, 17, 1, 1}))
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+var errCompareFiles = errors.New("-compare needs two pcap files")
+
+// compareWindow is how many packets ahead of its expected position a
+// packet is looked for in the other capture, bounding the reordering
+// compare tolerates
+const compareWindow = 64
+
+// A record is one RTCP packet of a capture.
+type record struct {
+	at     time.Time
+	header rtcp.Header
+	// the packet including its header
+	raw  []byte
+	ssrc uint32
+	// an identifier unique within the stream of the packet, for packet
+	// types that carry one, which tells a modified packet from a lost one
+	id    uint64
+	hasID bool
+}
+
+// A streamKey identifies the packets of one kind sent by one source.
+type streamKey struct {
+	typ    rtcp.PacketType
+	format uint8
+	ssrc   uint32
+}
+
+func (k streamKey) String() string {
+	if _, ok := (rtcp.Header{Type: k.typ}).FeedbackFormat(); ok {
+		return fmt.Sprintf("%v fmt %d ssrc %x", k.typ, k.format, k.ssrc)
+	}
+	return fmt.Sprintf("%v ssrc %x", k.typ, k.ssrc)
+}
+
+type diffKind int
+
+const (
+	diffLost diffKind = iota
+	diffModified
+	diffAdded
+)
+
+func (k diffKind) String() string {
+	switch k {
+	case diffLost:
+		return "lost"
+	case diffModified:
+		return "modified"
+	default:
+		return "added"
+	}
+}
+
+// A diffEntry is a packet of the first capture missing from or modified in
+// the second, or a packet only the second has.
+type diffEntry struct {
+	kind diffKind
+	a, b *record
+}
+
+// A streamDiff compares the packets of one stream in two captures.
+type streamDiff struct {
+	key                                   streamKey
+	sent, received, lost, modified, added int
+	entries                               []diffEntry
+}
+
+// records splits the datagrams of a capture into their packets, and
+// returns the number of datagrams that aren't well-formed
+func records(datagrams []datagram) ([]record, int) {
+	var out []record
+	malformed := 0
+	for _, d := range datagrams {
+		raw := d.payload
+		err := rtcp.ScanCompound(raw, func(h rtcp.Header, body []byte) bool {
+			size := len(body) + 4
+			r := record{at: d.at, header: h, raw: raw[:size]}
+			raw = raw[size:]
+			if len(body) >= 4 {
+				r.ssrc = binary.BigEndian.Uint32(body)
+			}
+			r.id, r.hasID = packetID(h, body)
+			out = append(out, r)
+			return true
+		})
+		if err != nil {
+			malformed++
+		}
+	}
+	return out, malformed
+}
+
+// packetID returns the identifier of packets that carry one: the NTP
+// timestamp of SenderReports and the base sequence number and feedback
+// packet count of transport wide feedback
+func packetID(h rtcp.Header, body []byte) (uint64, bool) {
+	switch {
+	case h.Type == rtcp.TypeSenderReport && len(body) >= 12:
+		return binary.BigEndian.Uint64(body[4:]), true
+	case h.Type == rtcp.TypeTransportSpecificFeedback && h.Count == rtcp.FormatTCC && len(body) >= 16:
+		return uint64(binary.BigEndian.Uint16(body[8:]))<<8 | uint64(body[15]), true
+	}
+	return 0, false
+}
+
+func (r *record) key() streamKey {
+	k := streamKey{typ: r.header.Type, ssrc: r.ssrc}
+	if format, ok := r.header.FeedbackFormat(); ok {
+		k.format = format
+	}
+	return k
+}
+
+// same reports whether a and b are the same packet, possibly modified
+func same(a, b *record) bool {
+	if a.hasID && b.hasID {
+		return a.id == b.id
+	}
+	return bytes.Equal(a.raw, b.raw)
+}
+
+// compareCaptures aligns the packets of two captures of the same session,
+// e.g. taken on the sender and receiver side, stream by stream, and returns
+// the differences of each stream in stream order.
+func compareCaptures(a, b []record) []streamDiff {
+	streams := map[streamKey]*[2][]*record{}
+	var keys []streamKey
+	add := func(side int, records []record) {
+		for i := range records {
+			k := records[i].key()
+			s, ok := streams[k]
+			if !ok {
+				s = &[2][]*record{}
+				streams[k] = s
+				keys = append(keys, k)
+			}
+			s[side] = append(s[side], &records[i])
+		}
+	}
+	add(0, a)
+	add(1, b)
+	sort.Slice(keys, func(i, j int) bool {
+		ki, kj := keys[i], keys[j]
+		if ki.typ != kj.typ {
+			return ki.typ < kj.typ
+		}
+		if ki.format != kj.format {
+			return ki.format < kj.format
+		}
+		return ki.ssrc < kj.ssrc
+	})
+
+	out := make([]streamDiff, 0, len(keys))
+	for _, k := range keys {
+		s := streams[k]
+		d := compareStream(s[0], s[1])
+		d.key = k
+		out = append(out, d)
+	}
+	return out
+}
+
+// compareStream matches the packets of b with those of a in order. The
+// packets between two matches are paired up as modified, and those left
+// over are lost or added.
+func compareStream(a, b []*record) streamDiff {
+	d := streamDiff{sent: len(a), received: len(b)}
+	gap := func(lost, added []*record) {
+		for len(lost) > 0 && len(added) > 0 {
+			d.entries = append(d.entries, diffEntry{kind: diffModified, a: lost[0], b: added[0]})
+			lost, added = lost[1:], added[1:]
+		}
+		for _, r := range lost {
+			d.entries = append(d.entries, diffEntry{kind: diffLost, a: r})
+		}
+		for _, r := range added {
+			d.entries = append(d.entries, diffEntry{kind: diffAdded, b: r})
+		}
+	}
+
+	next := 0
+	var unmatched []*record
+	for _, rb := range b {
+		match := -1
+		for i := next; i < len(a) && i < next+compareWindow; i++ {
+			if same(a[i], rb) {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			unmatched = append(unmatched, rb)
+			continue
+		}
+		gap(a[next:match], unmatched)
+		unmatched = nil
+		if !bytes.Equal(a[match].raw, rb.raw) {
+			d.entries = append(d.entries, diffEntry{kind: diffModified, a: a[match], b: rb})
+		}
+		next = match + 1
+	}
+	gap(a[next:], unmatched)
+
+	for _, e := range d.entries {
+		switch e.kind {
+		case diffLost:
+			d.lost++
+		case diffModified:
+			d.modified++
+		case diffAdded:
+			d.added++
+		}
+	}
+	return d
+}
+
+// writeDiff prints the differences of the streams, with the times of the
+// packets relative to the start of their capture
+func writeDiff(w io.Writer, diffs []streamDiff, startA, startB time.Time) error {
+	for _, d := range diffs {
+		if _, err := fmt.Fprintf(w, "%v: %d sent, %d received, %d lost, %d modified, %d added\n",
+			d.key, d.sent, d.received, d.lost, d.modified, d.added); err != nil {
+			return err
+		}
+		for _, e := range d.entries {
+			var line string
+			switch e.kind {
+			case diffLost:
+				line = fmt.Sprintf("  lost     %9.3fs", e.a.at.Sub(startA).Seconds())
+			case diffAdded:
+				line = fmt.Sprintf("  added    %9.3fs", e.b.at.Sub(startB).Seconds())
+			case diffModified:
+				line = fmt.Sprintf("  modified %9.3fs -> %.3fs, %s", e.a.at.Sub(startA).Seconds(), e.b.at.Sub(startB).Seconds(), describeChange(e.a.raw, e.b.raw))
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// describeChange summarizes how b differs from a
+func describeChange(a, b []byte) string {
+	if len(a) != len(b) {
+		return fmt.Sprintf("%d bytes became %d", len(a), len(b))
+	}
+	first, n := -1, 0
+	for i := range a {
+		if a[i] != b[i] {
+			if first < 0 {
+				first = i
+			}
+			n++
+		}
+	}
+	return fmt.Sprintf("%d of %d bytes differ from offset %d", n, len(a), first)
+}
+
+// runCompare compares the RTCP of two pcap files
+func runCompare(files []string, w io.Writer) error {
+	if len(files) != 2 {
+		return errCompareFiles
+	}
+
+	var captures [2][]record
+	var starts [2]time.Time
+	for i, name := range files {
+		f, err := os.Open(name) // nolint:gosec
+		if err != nil {
+			return err
+		}
+		datagrams, err := readPcap(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if len(datagrams) > 0 {
+			starts[i] = datagrams[0].at
+		}
+
+		var malformed int
+		captures[i], malformed = records(datagrams)
+		if _, err := fmt.Fprintf(w, "%s: %d datagrams, %d packets, %d malformed\n", name, len(datagrams), len(captures[i]), malformed); err != nil {
+			return err
+		}
+	}
+	return writeDiff(w, compareCaptures(captures[0], captures[1]), starts[0], starts[1])
+}
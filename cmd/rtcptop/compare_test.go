@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func marshal(t *testing.T, packets ...rtcp.Packet) []byte {
+	data, err := rtcp.Marshal(packets)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestCompareCaptures(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	sr := func(ntp uint64, rtpTime uint32) *rtcp.SenderReport {
+		return &rtcp.SenderReport{SSRC: 1, NTPTime: ntp, RTPTime: rtpTime}
+	}
+	rr := func(lost uint32) *rtcp.ReceiverReport {
+		return &rtcp.ReceiverReport{SSRC: 2, Reports: []rtcp.ReceptionReport{{SSRC: 1, TotalLost: lost}}}
+	}
+	sdes := rtcp.NewCNAMESourceDescription(1, "cname")
+	twcc := &rtcp.TransportLayerCC{
+		SenderSSRC: 2, BaseSequenceNumber: 10, PacketStatusCount: 1,
+		PacketChunks: []rtcp.PacketStatusChunk{&rtcp.RunLengthChunk{PacketStatusSymbol: rtcp.TypePacketNotReceived, RunLength: 1}},
+	}
+
+	sent, malformed := records([]datagram{
+		{start, marshal(t, sr(1, 100), sdes)},
+		{start.Add(time.Second), marshal(t, rr(0))},
+		{start.Add(2 * time.Second), marshal(t, sr(2, 200))},
+		{start.Add(3 * time.Second), marshal(t, rr(1))},
+		{start.Add(4 * time.Second), marshal(t, rr(2))},
+		{start.Add(5 * time.Second), marshal(t, twcc)},
+	})
+	assert.Equal(7, len(sent))
+	assert.Equal(0, malformed)
+
+	received, malformed := records([]datagram{
+		{start, marshal(t, sr(1, 100), sdes)},
+		// the first RR is lost, and the SR rewritten
+		{start.Add(2 * time.Second), marshal(t, sr(2, 201))},
+		{start.Add(3 * time.Second), marshal(t, rr(5))},
+		{start.Add(4 * time.Second), marshal(t, rr(2))},
+		{start.Add(4 * time.Second), marshal(t, &rtcp.PictureLossIndication{SenderSSRC: 3, MediaSSRC: 1})},
+		{start.Add(5 * time.Second), marshal(t, twcc)},
+		{start.Add(6 * time.Second), []byte{0x80, 0xc9, 0x00, 0x05}},
+	})
+	assert.Equal(7, len(received))
+	assert.Equal(1, malformed)
+
+	diffs := compareCaptures(sent, received)
+	var summary []string
+	for _, d := range diffs {
+		summary = append(summary, d.key.String())
+	}
+	assert.Equal([]string{"SR ssrc 1", "RR ssrc 2", "SDES ssrc 1", "TSFB fmt 15 ssrc 2", "PSFB fmt 1 ssrc 3"}, summary)
+
+	// the SR is told apart by its NTP timestamp
+	assert.Equal(streamDiff{key: diffs[0].key, sent: 2, received: 2, modified: 1, entries: []diffEntry{
+		{kind: diffModified, a: &sent[3], b: &received[2]},
+	}}, diffs[0])
+	// the RRs by their position between matches: the first RR is paired
+	// with the modified one, as it can't be told from it
+	assert.Equal(3, diffs[1].sent)
+	assert.Equal(2, diffs[1].received)
+	assert.Equal(1, diffs[1].lost)
+	assert.Equal(1, diffs[1].modified)
+	assert.Empty(diffs[2].entries)
+	assert.Empty(diffs[3].entries)
+	assert.Equal(1, diffs[4].added)
+
+	var out bytes.Buffer
+	assert.NoError(writeDiff(&out, diffs, start, start))
+	text := out.String()
+	assert.True(strings.Contains(text, "SR ssrc 1: 2 sent, 2 received, 0 lost, 1 modified, 0 added\n"), text)
+	assert.True(strings.Contains(text, "  modified     2.000s -> 2.000s, 1 of 28 bytes differ from offset 19\n"), text)
+	assert.True(strings.Contains(text, "  lost         3.000s\n"), text)
+	assert.True(strings.Contains(text, "  added        4.000s\n"), text)
+}
+
+func TestDescribeChange(t *testing.T) {
+	assert.Equal(t, "2 of 4 bytes differ from offset 1", describeChange([]byte{1, 2, 3, 4}, []byte{1, 0, 3, 0}))
+	assert.Equal(t, "4 bytes became 8", describeChange(make([]byte, 4), make([]byte, 8)))
+}
+
+func TestRunCompare(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "rtcptop")
+	assert.NoError(err)
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	start := time.Unix(1000, 0)
+	rr := marshal(t, &rtcp.ReceiverReport{SSRC: 2})
+	a := filepath.Join(dir, "a.pcap")
+	b := filepath.Join(dir, "b.pcap")
+	assert.NoError(ioutil.WriteFile(a, pcapFile(linkTypeEthernet, start, ethernet(ipv4UDP(rr)), ethernet(ipv4UDP(rr))), 0600))
+	assert.NoError(ioutil.WriteFile(b, pcapFile(linkTypeEthernet, start, ethernet(ipv4UDP(rr))), 0600))
+
+	var out bytes.Buffer
+	assert.NoError(run(options{compare: true, files: []string{a, b}}, &out))
+	text := out.String()
+	assert.True(strings.Contains(text, "a.pcap: 2 datagrams, 2 packets, 0 malformed\n"), text)
+	assert.True(strings.Contains(text, "RR ssrc 2: 2 sent, 1 received, 1 lost, 0 modified, 0 added\n"), text)
+
+	assert.Equal(errCompareFiles, run(options{compare: true, files: []string{a}}, &out))
+	assert.Error(run(options{compare: true, files: []string{a, filepath.Join(dir, "missing.pcap")}}, &out))
+	assert.NoError(ioutil.WriteFile(b, []byte("not a capture"), 0600))
+	err = run(options{compare: true, files: []string{a, b}}, &out)
+	assert.True(strings.Contains(err.Error(), "b.pcap: not a pcap file"), err)
+}
@@ -0,0 +1,157 @@
+// Command rtcptop listens for RTCP on a UDP port and prints rolling per-SSRC
+// statistics, like top for RTCP: the loss and jitter receivers report, the
+// round trip time derived from sender and receiver reports, the latest REMB
+// estimate and the packet rate and loss seen in transport wide feedback.
+//
+//	rtcptop -listen :5005
+//	rtcptop -listen :5005 -key <base64 master key and salt> -filter 'pt==205'
+//
+// With -key, datagrams are SRTCP protected with AES_CM_128_HMAC_SHA1_80 and
+// the key is the base64 encoded 30 byte master key and salt, as found in the
+// inline parameter of an SDP crypto attribute.
+//
+// The round trip time is measured between the observer and the reporting
+// receivers, so it comes out right when rtcptop runs next to the media
+// sender.
+//
+// With -compare, rtcptop instead compares two pcap captures of the same
+// session, e.g. one taken at the sender and one at the receiver, and
+// reports which RTCP packets were lost or modified in transit, or added on
+// the way, such as by an SFU:
+//
+//	rtcptop -compare sender.pcap receiver.pcap
+//
+// The packets are aligned by packet type, feedback format and sender SSRC,
+// then by the NTP timestamp of sender reports and the feedback packet count
+// of transport wide feedback. Other packets are matched by content, so a
+// modified one is told from a lost one by its position between matches.
+// SRTCP protected captures can't be compared.
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+type options struct {
+	compare  bool
+	files    []string
+	listen   string
+	key      string
+	filter   string
+	interval time.Duration
+	duration time.Duration
+}
+
+func main() {
+	var o options
+	flag.StringVar(&o.listen, "listen", "", "receive RTCP on this UDP address")
+	flag.StringVar(&o.key, "key", "", "base64 SRTCP master key and salt, if the RTCP is protected")
+	flag.StringVar(&o.filter, "filter", "", "only account packets matching this filter, e.g. 'pt==205 && fmt==15'")
+	flag.DurationVar(&o.interval, "interval", time.Second, "how often to print statistics")
+	flag.DurationVar(&o.duration, "duration", 0, "how long to run, 0 runs until interrupted")
+	flag.BoolVar(&o.compare, "compare", false, "compare the RTCP of the two pcap files given as arguments")
+	flag.Parse()
+	o.files = flag.Args()
+
+	if err := run(o, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "rtcptop:", err)
+		os.Exit(1) // nolint
+	}
+}
+
+func run(o options, w io.Writer) error {
+	if o.compare {
+		return runCompare(o.files, w)
+	}
+	if o.listen == "" {
+		return errNoListen
+	}
+
+	filter, err := parseFilter(o.filter)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.ListenPacket("udp", o.listen)
+	if err != nil {
+		return err
+	}
+	defer conn.Close() // nolint:errcheck
+
+	transport := rtcp.NewPacketConnTransport(conn, nil)
+	if o.key != "" {
+		key, err := base64.StdEncoding.DecodeString(o.key)
+		if err != nil {
+			return err
+		}
+		dec, err := newSRTCPDecryptor(key)
+		if err != nil {
+			return err
+		}
+		transport.Decryptor = dec
+		transport.AuthTagLength = srtcpAuthTagLength
+	}
+
+	return analyze(conn, transport, filter, o.interval, o.duration, w)
+}
+
+func parseFilter(expr string) (*rtcp.Filter, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return rtcp.ParseFilter(expr)
+}
+
+// analyze reads from transport until duration has passed, printing the
+// statistics every interval. conn is the connection transport reads from.
+func analyze(conn net.PacketConn, transport rtcp.Transport, filter *rtcp.Filter, interval, duration time.Duration, w io.Writer) error {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	t := newTable()
+	start := time.Now()
+	lastReport := start
+	for duration == 0 || time.Since(start) < duration {
+		deadline := lastReport.Add(interval)
+		if end := start.Add(duration); duration != 0 && end.Before(deadline) {
+			deadline = end
+		}
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			return err
+		}
+
+		packets, err := transport.ReadRTCP()
+		now := time.Now()
+		switch ne, ok := err.(net.Error); {
+		case err == nil:
+			for _, p := range packets {
+				if filter.Match(p) {
+					t.observe(now, p)
+				}
+			}
+		case ok && ne.Timeout():
+		case ok:
+			return err
+		default:
+			// a datagram that doesn't decode or authenticate
+			t.errors++
+		}
+
+		if now.Sub(lastReport) >= interval {
+			if err := t.write(w, now.Sub(lastReport)); err != nil {
+				return err
+			}
+			lastReport = now
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunErrors(t *testing.T) {
+	assert.Equal(t, errNoListen, run(options{}, &bytes.Buffer{}))
+	assert.Error(t, run(options{listen: "127.0.0.1:0", filter: "pt=="}, &bytes.Buffer{}))
+	assert.Equal(t, errKeyLength, run(options{listen: "127.0.0.1:0", key: "AAAA"}, &bytes.Buffer{}))
+}
+
+func TestAnalyze(t *testing.T) {
+	assert := assert.New(t)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(err)
+	defer conn.Close() // nolint:errcheck
+
+	sender, err := net.Dial("udp", conn.LocalAddr().String())
+	assert.NoError(err)
+	defer sender.Close() // nolint:errcheck
+
+	rr, err := rtcp.Marshal([]rtcp.Packet{&rtcp.ReceiverReport{SSRC: 1, Reports: []rtcp.ReceptionReport{{SSRC: 0xbeef, TotalLost: 3}}}})
+	assert.NoError(err)
+	remb, err := rtcp.Marshal([]rtcp.Packet{&rtcp.ReceiverEstimatedMaximumBitrate{SenderSSRC: 1, Bitrate: 1000, SSRCs: []uint32{0xcafe}}})
+	assert.NoError(err)
+	for _, b := range [][]byte{rr, remb, {0x80}} {
+		_, err = sender.Write(b)
+		assert.NoError(err)
+	}
+
+	filter, err := rtcp.ParseFilter("pt==201")
+	assert.NoError(err)
+	var out bytes.Buffer
+	transport := rtcp.NewPacketConnTransport(conn, nil)
+	assert.NoError(analyze(conn, transport, filter, 50*time.Millisecond, 120*time.Millisecond, &out))
+
+	text := out.String()
+	assert.True(strings.Contains(text, "beef"), text)
+	assert.False(strings.Contains(text, "cafe"), text)
+	assert.True(strings.Contains(text, "1 packets, 1 errors"), text)
+}
+
+func TestAnalyzeClosed(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	assert.NoError(t, conn.Close())
+
+	err = analyze(conn, rtcp.NewPacketConnTransport(conn, nil), nil, time.Second, 0, &bytes.Buffer{})
+	var ne net.Error
+	assert.True(t, errors.As(err, &ne), "%v", err)
+}
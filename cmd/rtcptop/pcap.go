@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	errNotPcap   = errors.New("not a pcap file")
+	errPcapng    = errors.New("pcapng files aren't supported, convert them with editcap -F pcap")
+	errTruncated = errors.New("truncated pcap file")
+	errLinkType  = errors.New("unsupported pcap link type")
+)
+
+// Link layer types of the pcap header, see
+// https://www.tcpdump.org/linktypes.html
+const (
+	linkTypeNull     = 0
+	linkTypeEthernet = 1
+	linkTypeRaw      = 101
+	linkTypeLinuxSLL = 113
+	linkTypeRawAlt   = 12
+	linkTypeSLL2     = 276
+)
+
+const (
+	pcapHeaderLength       = 24
+	pcapRecordHeaderLength = 16
+	// larger records are taken for corruption, see the snaplen of tcpdump
+	pcapMaxRecordLength = 262144
+	pcapngMagic         = 0x0a0d0d0a
+
+	etherTypeIPv4 = 0x0800
+	etherTypeIPv6 = 0x86dd
+	etherTypeVLAN = 0x8100
+	etherTypeQinQ = 0x88a8
+
+	ipProtocolUDP = 17
+)
+
+// A datagram is a UDP payload read from a capture.
+type datagram struct {
+	at      time.Time
+	payload []byte
+}
+
+// readPcap returns the UDP payloads of the IPv4 and IPv6 packets in the
+// classic pcap file read from r that look like RTCP, i.e. RTP version 2
+// with a packet type in the range RFC 5761 reserves for RTCP. Fragments and
+// other packets are skipped.
+func readPcap(r io.Reader) ([]datagram, error) {
+	var header [pcapHeaderLength]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, errNotPcap
+	}
+
+	var order binary.ByteOrder
+	var nanos bool
+	switch binary.LittleEndian.Uint32(header[:]) {
+	case 0xa1b2c3d4:
+		order = binary.LittleEndian
+	case 0xa1b23c4d:
+		order, nanos = binary.LittleEndian, true
+	case 0xd4c3b2a1:
+		order = binary.BigEndian
+	case 0x4d3cb2a1:
+		order, nanos = binary.BigEndian, true
+	case pcapngMagic:
+		return nil, errPcapng
+	default:
+		return nil, errNotPcap
+	}
+	linkType := order.Uint32(header[20:]) & 0xffff
+	switch linkType {
+	case linkTypeNull, linkTypeEthernet, linkTypeRaw, linkTypeRawAlt, linkTypeLinuxSLL, linkTypeSLL2:
+	default:
+		return nil, fmt.Errorf("%w: %d", errLinkType, linkType)
+	}
+
+	var out []datagram
+	var record [pcapRecordHeaderLength]byte
+	for {
+		if _, err := io.ReadFull(r, record[:]); err == io.EOF {
+			return out, nil
+		} else if err != nil {
+			return nil, errTruncated
+		}
+		sec, frac := order.Uint32(record[:]), order.Uint32(record[4:])
+		if !nanos {
+			frac *= 1000
+		}
+		length := order.Uint32(record[8:])
+		if length > pcapMaxRecordLength {
+			return nil, errTruncated
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, errTruncated
+		}
+
+		payload, ok := udpPayload(linkType, data)
+		if !ok || !looksLikeRTCP(payload) {
+			continue
+		}
+		out = append(out, datagram{at: time.Unix(int64(sec), int64(frac)), payload: payload})
+	}
+}
+
+// udpPayload strips the link, IP and UDP headers from a captured frame
+func udpPayload(linkType uint32, frame []byte) ([]byte, bool) {
+	ip, ok := ipPacket(linkType, frame)
+	if !ok || len(ip) < 1 {
+		return nil, false
+	}
+
+	var udp []byte
+	switch ip[0] >> 4 {
+	case 4:
+		if len(ip) < 20 {
+			return nil, false
+		}
+		ihl := int(ip[0]&0x0f) * 4
+		total := int(binary.BigEndian.Uint16(ip[2:]))
+		// later fragments have no UDP header, and first ones only part of
+		// the payload
+		fragment := binary.BigEndian.Uint16(ip[6:])&0x3fff != 0
+		if ip[9] != ipProtocolUDP || fragment || ihl < 20 || total < ihl || total > len(ip) {
+			return nil, false
+		}
+		udp = ip[ihl:total]
+	case 6:
+		if len(ip) < 40 || ip[6] != ipProtocolUDP {
+			return nil, false
+		}
+		total := 40 + int(binary.BigEndian.Uint16(ip[4:]))
+		if total > len(ip) {
+			return nil, false
+		}
+		udp = ip[40:total]
+	default:
+		return nil, false
+	}
+
+	if len(udp) < 8 {
+		return nil, false
+	}
+	length := int(binary.BigEndian.Uint16(udp[4:]))
+	if length < 8 || length > len(udp) {
+		return nil, false
+	}
+	return udp[8:length], true
+}
+
+// ipPacket strips the link layer header from a captured frame
+func ipPacket(linkType uint32, frame []byte) ([]byte, bool) {
+	switch linkType {
+	case linkTypeEthernet:
+		if len(frame) < 14 {
+			return nil, false
+		}
+		etherType, rest := binary.BigEndian.Uint16(frame[12:]), frame[14:]
+		for etherType == etherTypeVLAN || etherType == etherTypeQinQ {
+			if len(rest) < 4 {
+				return nil, false
+			}
+			etherType, rest = binary.BigEndian.Uint16(rest[2:]), rest[4:]
+		}
+		return rest, etherType == etherTypeIPv4 || etherType == etherTypeIPv6
+	case linkTypeLinuxSLL:
+		if len(frame) < 16 {
+			return nil, false
+		}
+		etherType := binary.BigEndian.Uint16(frame[14:])
+		return frame[16:], etherType == etherTypeIPv4 || etherType == etherTypeIPv6
+	case linkTypeSLL2:
+		if len(frame) < 20 {
+			return nil, false
+		}
+		etherType := binary.BigEndian.Uint16(frame)
+		return frame[20:], etherType == etherTypeIPv4 || etherType == etherTypeIPv6
+	case linkTypeNull:
+		// the address family is in the byte order of the capturing host,
+		// so rely on the IP version instead
+		if len(frame) < 4 {
+			return nil, false
+		}
+		return frame[4:], true
+	case linkTypeRaw, linkTypeRawAlt:
+		return frame, true
+	default:
+		return nil, false
+	}
+}
+
+// looksLikeRTCP tells RTCP from RTP and other protocols sharing a port, see
+// RFC 5761, 4
+func looksLikeRTCP(payload []byte) bool {
+	return len(payload) >= 8 && payload[0]>>6 == 2 && payload[1] >= 192 && payload[1] <= 223
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ipv4UDP returns an IPv4 packet carrying payload in a UDP datagram
+func ipv4UDP(payload []byte) []byte {
+	p := make([]byte, 28+len(payload))
+	p[0] = 0x45
+	binary.BigEndian.PutUint16(p[2:], uint16(len(p)))
+	p[8] = 64
+	p[9] = ipProtocolUDP
+	copy(p[12:], []byte{127, 0, 0, 1, 127, 0, 0, 1})
+	binary.BigEndian.PutUint16(p[20:], 5005)
+	binary.BigEndian.PutUint16(p[22:], 5006)
+	binary.BigEndian.PutUint16(p[24:], uint16(8+len(payload)))
+	copy(p[28:], payload)
+	return p
+}
+
+// ipv6UDP returns an IPv6 packet carrying payload in a UDP datagram
+func ipv6UDP(payload []byte) []byte {
+	p := make([]byte, 48+len(payload))
+	p[0] = 0x60
+	binary.BigEndian.PutUint16(p[4:], uint16(8+len(payload)))
+	p[6] = ipProtocolUDP
+	binary.BigEndian.PutUint16(p[44:], uint16(8+len(payload)))
+	copy(p[48:], payload)
+	return p
+}
+
+// ethernet returns an Ethernet frame carrying ip
+func ethernet(ip []byte) []byte {
+	etherType := uint16(etherTypeIPv4)
+	if ip[0]>>4 == 6 {
+		etherType = etherTypeIPv6
+	}
+	f := make([]byte, 14, 14+len(ip))
+	binary.BigEndian.PutUint16(f[12:], etherType)
+	return append(f, ip...)
+}
+
+// pcapFile returns a little endian microsecond pcap file of frames, a
+// millisecond apart from start
+func pcapFile(linkType uint32, start time.Time, frames ...[]byte) []byte {
+	var b bytes.Buffer
+	header := make([]byte, pcapHeaderLength)
+	binary.LittleEndian.PutUint32(header, 0xa1b2c3d4)
+	binary.LittleEndian.PutUint16(header[4:], 2)
+	binary.LittleEndian.PutUint16(header[6:], 4)
+	binary.LittleEndian.PutUint32(header[16:], 65535)
+	binary.LittleEndian.PutUint32(header[20:], linkType)
+	b.Write(header)
+	for i, f := range frames {
+		at := start.Add(time.Duration(i) * time.Millisecond)
+		record := make([]byte, pcapRecordHeaderLength)
+		binary.LittleEndian.PutUint32(record, uint32(at.Unix()))
+		binary.LittleEndian.PutUint32(record[4:], uint32(at.Nanosecond()/1000))
+		binary.LittleEndian.PutUint32(record[8:], uint32(len(f)))
+		binary.LittleEndian.PutUint32(record[12:], uint32(len(f)))
+		b.Write(record)
+		b.Write(f)
+	}
+	return b.Bytes()
+}
+
+func TestReadPcap(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	rr := []byte{0x80, 0xc9, 0x00, 0x01, 0, 0, 0, 1}
+	rtp := []byte{0x80, 0x60, 0x00, 0x01, 0, 0, 0, 1, 0, 0, 0, 2}
+
+	fragment := ipv4UDP(rr)
+	fragment[6] = 0x20 // more fragments
+	vlan := ethernet(ipv4UDP(rr))
+	vlan = append(append(append([]byte{}, vlan[:12]...), 0x81, 0x00, 0x00, 0x05), vlan[12:]...)
+
+	data := pcapFile(linkTypeEthernet, start,
+		ethernet(ipv4UDP(rr)),
+		ethernet(ipv4UDP(rtp)),
+		ethernet(fragment),
+		ethernet(ipv6UDP(rr)),
+		vlan,
+		ethernet(ipv4UDP(rr))[:30],
+	)
+	datagrams, err := readPcap(bytes.NewReader(data))
+	assert.NoError(err)
+	if assert.Len(datagrams, 3) {
+		assert.Equal(start, datagrams[0].at)
+		assert.Equal(rr, datagrams[0].payload)
+		assert.Equal(start.Add(3*time.Millisecond), datagrams[1].at)
+		assert.Equal(rr, datagrams[1].payload)
+		assert.Equal(start.Add(4*time.Millisecond), datagrams[2].at)
+	}
+
+	for _, linkType := range []uint32{linkTypeRaw, linkTypeNull, linkTypeLinuxSLL, linkTypeSLL2} {
+		frame := ipv6UDP(rr)
+		switch linkType {
+		case linkTypeNull:
+			frame = append([]byte{30, 0, 0, 0}, frame...)
+		case linkTypeLinuxSLL:
+			frame = append(make([]byte, 16), frame...)
+			binary.BigEndian.PutUint16(frame[14:], etherTypeIPv6)
+		case linkTypeSLL2:
+			frame = append(make([]byte, 20), frame...)
+			binary.BigEndian.PutUint16(frame, etherTypeIPv6)
+		}
+		datagrams, err = readPcap(bytes.NewReader(pcapFile(linkType, start, frame)))
+		assert.NoError(err)
+		if assert.Len(datagrams, 1, "link type %d", linkType) {
+			assert.Equal(rr, datagrams[0].payload)
+		}
+	}
+
+	_, err = readPcap(bytes.NewReader(nil))
+	assert.Equal(errNotPcap, err)
+	_, err = readPcap(bytes.NewReader([]byte{0x0a, 0x0d, 0x0d, 0x0a, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}))
+	assert.Equal(errPcapng, err)
+	_, err = readPcap(bytes.NewReader(pcapFile(147, start)))
+	assert.True(errors.Is(err, errLinkType), err)
+	_, err = readPcap(bytes.NewReader(data[:len(data)-1]))
+	assert.Equal(errTruncated, err)
+}
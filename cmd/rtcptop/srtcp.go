@@ -0,0 +1,131 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1" // nolint:gosec
+	"encoding/binary"
+	"errors"
+	"hash"
+
+	"github.com/pion/rtcp"
+)
+
+// SRTCP with AES_CM_128_HMAC_SHA1_80, see RFC 3711
+const (
+	srtcpMasterKeyLength  = 16
+	srtcpMasterSaltLength = 14
+	srtcpAuthKeyLength    = 20
+	srtcpAuthTagLength    = 10
+	srtcpIndexLength      = 4
+	// the first header and the sender SSRC are sent in the clear
+	srtcpClearLength = 8
+
+	labelSRTCPEncryption = 0x03
+	labelSRTCPAuth       = 0x04
+	labelSRTCPSalt       = 0x05
+)
+
+var (
+	errKeyLength    = errors.New("srtcp master key and salt must be 30 bytes")
+	errSRTCPShort   = errors.New("srtcp packet too short")
+	errSRTCPAuthTag = errors.New("srtcp authentication failed")
+)
+
+// srtcpDecryptor removes the protection of SRTCP packets of a single
+// session. Replayed packets aren't detected.
+type srtcpDecryptor struct {
+	block cipher.Block
+	salt  []byte
+	mac   hash.Hash
+}
+
+var _ rtcp.Decryptor = (*srtcpDecryptor)(nil)
+
+// newSRTCPDecryptor creates a decryptor from the concatenated master key and
+// salt
+func newSRTCPDecryptor(keyAndSalt []byte) (*srtcpDecryptor, error) {
+	if len(keyAndSalt) != srtcpMasterKeyLength+srtcpMasterSaltLength {
+		return nil, errKeyLength
+	}
+	masterKey, masterSalt := keyAndSalt[:srtcpMasterKeyLength], keyAndSalt[srtcpMasterKeyLength:]
+
+	encKey, err := deriveSessionKey(masterKey, masterSalt, labelSRTCPEncryption, srtcpMasterKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	authKey, err := deriveSessionKey(masterKey, masterSalt, labelSRTCPAuth, srtcpAuthKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := deriveSessionKey(masterKey, masterSalt, labelSRTCPSalt, srtcpMasterSaltLength)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return &srtcpDecryptor{block: block, salt: salt, mac: hmac.New(sha1.New, authKey)}, nil
+}
+
+// deriveSessionKey derives n bytes of session key material for label with
+// a key derivation rate of zero, see RFC 3711, 4.3
+func deriveSessionKey(masterKey, masterSalt []byte, label byte, n int) ([]byte, error) {
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	copy(iv, masterSalt)
+	iv[7] ^= label
+
+	out := make([]byte, n)
+	cipher.NewCTR(block, iv).XORKeyStream(out, out)
+	return out, nil
+}
+
+// DecryptRTCP authenticates encrypted and returns it decrypted, without the
+// SRTCP index and authentication tag.
+func (d *srtcpDecryptor) DecryptRTCP(dst, encrypted []byte, _ *rtcp.Header) ([]byte, error) {
+	n := len(encrypted)
+	if n < srtcpClearLength+srtcpIndexLength+srtcpAuthTagLength {
+		return nil, errSRTCPShort
+	}
+	authenticated, tag := encrypted[:n-srtcpAuthTagLength], encrypted[n-srtcpAuthTagLength:]
+
+	d.mac.Reset()
+	d.mac.Write(authenticated) // nolint:errcheck,gosec
+	if !hmac.Equal(d.mac.Sum(nil)[:srtcpAuthTagLength], tag) {
+		return nil, errSRTCPAuthTag
+	}
+
+	indexOffset := len(authenticated) - srtcpIndexLength
+	index := binary.BigEndian.Uint32(authenticated[indexOffset:])
+	out := append(dst[:0], authenticated[:indexOffset]...)
+	if index>>31 == 0 {
+		// the E flag is clear, so the packet isn't encrypted
+		return out, nil
+	}
+
+	cipher.NewCTR(d.block, d.iv(out[4:8], index&0x7fffffff)).XORKeyStream(out[srtcpClearLength:], out[srtcpClearLength:])
+	return out, nil
+}
+
+// iv returns the counter mode IV of the packet with the given sender SSRC
+// and index, see RFC 3711, 4.1.1
+func (d *srtcpDecryptor) iv(ssrc []byte, index uint32) []byte {
+	iv := make([]byte, aes.BlockSize)
+	copy(iv, d.salt)
+	for i := 0; i < 4; i++ {
+		iv[4+i] ^= ssrc[i]
+	}
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], index)
+	for i := 0; i < 4; i++ {
+		iv[10+i] ^= b[i]
+	}
+	return iv
+}
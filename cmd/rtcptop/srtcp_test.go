@@ -0,0 +1,83 @@
+package main
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1" // nolint:gosec
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeriveSessionKey(t *testing.T) {
+	// RFC 3711, B.3
+	masterKey, _ := hex.DecodeString("E1F97A0D3E018BE0D64FA32C06DE4139")
+	masterSalt, _ := hex.DecodeString("0EC675AD498AFEEBB6960B3AABE6")
+
+	for _, test := range []struct {
+		label byte
+		n     int
+		want  string
+	}{
+		{0x00, 16, "c61e7a93744f39ee10734afe3ff7a087"},
+		{0x01, 20, "cebe321f6ff7716b6fd4ab49af256a156d38baa4"},
+		{0x02, 14, "30cbbc08863d8c85d49db34a9ae1"},
+	} {
+		key, err := deriveSessionKey(masterKey, masterSalt, test.label, test.n)
+		assert.NoError(t, err)
+		assert.Equal(t, test.want, hex.EncodeToString(key), "label %d", test.label)
+	}
+}
+
+// encrypt protects a marshaled packet as an SRTCP sender would
+func encrypt(t *testing.T, keyAndSalt, plain []byte, index uint32) []byte {
+	d, err := newSRTCPDecryptor(keyAndSalt)
+	assert.NoError(t, err)
+	authKey, err := deriveSessionKey(keyAndSalt[:16], keyAndSalt[16:], labelSRTCPAuth, srtcpAuthKeyLength)
+	assert.NoError(t, err)
+
+	out := append([]byte{}, plain...)
+	cipher.NewCTR(d.block, d.iv(out[4:8], index)).XORKeyStream(out[8:], out[8:])
+	out = append(out, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(out[len(out)-4:], 1<<31|index)
+
+	mac := hmac.New(sha1.New, authKey)
+	mac.Write(out) // nolint:errcheck,gosec
+	return append(out, mac.Sum(nil)[:srtcpAuthTagLength]...)
+}
+
+func TestSRTCPDecryptor(t *testing.T) {
+	assert := assert.New(t)
+
+	keyAndSalt := make([]byte, 30)
+	for i := range keyAndSalt {
+		keyAndSalt[i] = byte(i)
+	}
+	plain, err := rtcp.Marshal([]rtcp.Packet{&rtcp.ReceiverReport{
+		SSRC:    0x902f9e2e,
+		Reports: []rtcp.ReceptionReport{{SSRC: 1, FractionLost: 64, TotalLost: 10}},
+	}})
+	assert.NoError(err)
+
+	encrypted := encrypt(t, keyAndSalt, plain, 7)
+	assert.NotEqual(plain, encrypted[:len(plain)])
+
+	d, err := newSRTCPDecryptor(keyAndSalt)
+	assert.NoError(err)
+	got, err := d.DecryptRTCP(nil, encrypted, nil)
+	assert.NoError(err)
+	assert.Equal(plain, got)
+
+	encrypted[9] ^= 1
+	_, err = d.DecryptRTCP(nil, encrypted, nil)
+	assert.Equal(errSRTCPAuthTag, err)
+
+	_, err = d.DecryptRTCP(nil, plain[:8], nil)
+	assert.Equal(errSRTCPShort, err)
+
+	_, err = newSRTCPDecryptor(keyAndSalt[:16])
+	assert.Equal(errKeyLength, err)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+var errNoListen = errors.New("-listen is required")
+
+// maxSenderReports is how many recent sender reports per source are kept to
+// match the LSR of reception reports against
+const maxSenderReports = 8
+
+// sourceStats are the statistics of one media source
+type sourceStats struct {
+	// from the latest reception report about the source
+	reported     bool
+	fractionLost uint8
+	totalLost    uint32
+	jitter       uint32
+
+	rtt  time.Duration
+	remb uint64
+
+	// arrival times of recent sender reports of the source, by the middle
+	// 32 bits of their NTP time
+	senderReports map[uint32]time.Time
+
+	// transport wide feedback since the last write
+	twccReceived int
+	twccLost     int
+}
+
+// table accumulates statistics by SSRC
+type table struct {
+	sources map[uint32]*sourceStats
+	packets int
+	errors  int
+}
+
+func newTable() *table {
+	return &table{sources: make(map[uint32]*sourceStats)}
+}
+
+func (t *table) source(ssrc uint32) *sourceStats {
+	s, ok := t.sources[ssrc]
+	if !ok {
+		s = &sourceStats{senderReports: make(map[uint32]time.Time)}
+		t.sources[ssrc] = s
+	}
+	return s
+}
+
+// observe accounts a packet received at now
+func (t *table) observe(now time.Time, p rtcp.Packet) {
+	t.packets++
+	switch p := p.(type) {
+	case *rtcp.SenderReport:
+		s := t.source(p.SSRC)
+		if len(s.senderReports) >= maxSenderReports {
+			var oldest uint32
+			var oldestTime time.Time
+			for ntp, at := range s.senderReports {
+				if oldestTime.IsZero() || at.Before(oldestTime) {
+					oldest, oldestTime = ntp, at
+				}
+			}
+			delete(s.senderReports, oldest)
+		}
+		s.senderReports[uint32(p.NTPTime>>16)] = now
+		t.observeReports(now, p.Reports)
+
+	case *rtcp.ReceiverReport:
+		t.observeReports(now, p.Reports)
+
+	case *rtcp.ReceiverEstimatedMaximumBitrate:
+		for _, ssrc := range p.SSRCs {
+			t.source(ssrc).remb = p.Bitrate
+		}
+
+	case *rtcp.TransportLayerCC:
+		s := t.source(p.MediaSSRC)
+		lost := len(p.Lost())
+		s.twccLost += lost
+		s.twccReceived += int(p.PacketStatusCount) - lost
+	}
+}
+
+func (t *table) observeReports(now time.Time, reports []rtcp.ReceptionReport) {
+	for _, r := range reports {
+		s := t.source(r.SSRC)
+		s.reported = true
+		s.fractionLost = r.FractionLost
+		s.totalLost = r.TotalLost
+		s.jitter = r.Jitter
+
+		if r.LastSenderReport == 0 {
+			continue
+		}
+		sent, ok := s.senderReports[r.LastSenderReport]
+		if !ok {
+			continue
+		}
+		delay := time.Duration(r.Delay) * time.Second / 65536
+		if rtt := now.Sub(sent) - delay; rtt >= 0 {
+			s.rtt = rtt
+		}
+	}
+}
+
+// write prints the statistics of every source and resets the per interval
+// counters
+func (t *table) write(w io.Writer, interval time.Duration) error {
+	seconds := interval.Seconds()
+	if seconds <= 0 {
+		seconds = 1
+	}
+
+	ssrcs := make([]uint32, 0, len(t.sources))
+	for ssrc := range t.sources {
+		ssrcs = append(ssrcs, ssrc)
+	}
+	sort.Slice(ssrcs, func(i, j int) bool { return ssrcs[i] < ssrcs[j] })
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s  %d packets, %d errors\n", time.Now().Format("15:04:05"), t.packets, t.errors)
+	fmt.Fprintln(tw, "SSRC\tLOSS\tLOST\tJITTER\tRTT\tREMB\tTWCC RECV/s\tTWCC LOSS")
+	for _, ssrc := range ssrcs {
+		s := t.sources[ssrc]
+		loss, lost, jitter := "-", "-", "-"
+		if s.reported {
+			loss = fmt.Sprintf("%.1f%%", float64(s.fractionLost)*100/256)
+			lost = fmt.Sprint(s.totalLost)
+			jitter = fmt.Sprint(s.jitter)
+		}
+		rtt := "-"
+		if s.rtt > 0 {
+			rtt = s.rtt.Round(100 * time.Microsecond).String()
+		}
+		remb := "-"
+		if s.remb > 0 {
+			remb = fmt.Sprintf("%.0f kbps", float64(s.remb)/1000)
+		}
+		twccRate, twccLoss := "-", "-"
+		if total := s.twccReceived + s.twccLost; total > 0 {
+			twccRate = fmt.Sprintf("%.0f", float64(s.twccReceived)/seconds)
+			twccLoss = fmt.Sprintf("%.1f%%", float64(s.twccLost)*100/float64(total))
+		}
+		fmt.Fprintf(tw, "%x\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", ssrc, loss, lost, jitter, rtt, remb, twccRate, twccLoss)
+
+		s.twccReceived, s.twccLost = 0, 0
+	}
+	t.packets, t.errors = 0, 0
+	return tw.Flush()
+}
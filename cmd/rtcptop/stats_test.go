@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1000, 0)
+	tbl := newTable()
+	tbl.observe(now, &rtcp.SenderReport{SSRC: 0xa, NTPTime: 0x1234567800000000})
+	// received 50ms later, after the receiver held the SR for 10ms
+	tbl.observe(now.Add(50*time.Millisecond), &rtcp.ReceiverReport{
+		SSRC: 0xb,
+		Reports: []rtcp.ReceptionReport{{
+			SSRC:             0xa,
+			FractionLost:     128,
+			TotalLost:        42,
+			Jitter:           90,
+			LastSenderReport: 0x56780000,
+			Delay:            655,
+		}},
+	})
+	tbl.observe(now, &rtcp.ReceiverEstimatedMaximumBitrate{SenderSSRC: 0xb, Bitrate: 1500000, SSRCs: []uint32{0xa}})
+
+	b := rtcp.NewTransportLayerCCBuilder(0)
+	b.MediaSSRC = 0xa
+	assert.NoError(b.AddReceived(0, 0))
+	assert.NoError(b.AddLost(1))
+	assert.NoError(b.AddReceived(2, time.Millisecond))
+	assert.NoError(b.AddReceived(3, 2*time.Millisecond))
+	tbl.observe(now, b.Build())
+
+	var out bytes.Buffer
+	assert.NoError(tbl.write(&out, time.Second))
+	lines := strings.Split(out.String(), "\n")
+	assert.Contains(lines[0], "4 packets, 0 errors")
+	assert.Equal([]string{"a", "50.0%", "42", "90", "40ms", "1500", "kbps", "3", "25.0%"}, strings.Fields(lines[2]))
+
+	// transport wide counters restart every interval
+	out.Reset()
+	assert.NoError(tbl.write(&out, time.Second))
+	assert.Equal([]string{"a", "50.0%", "42", "90", "40ms", "1500", "kbps", "-", "-"}, strings.Fields(strings.Split(out.String(), "\n")[2]))
+}
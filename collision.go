@@ -0,0 +1,263 @@
+package rtcp
+
+import (
+	"math/rand"
+	"net"
+	"time"
+)
+
+const (
+	// DefaultConflictTimeout is how long a conflicting transport address is
+	// remembered: ten times the minimum RTCP interval. See RFC 3550, 8.2
+	DefaultConflictTimeout = 10 * DefaultMinInterval
+
+	collisionReason = "SSRC collision"
+)
+
+// CollisionType classifies the result of observing an SSRC
+type CollisionType int
+
+// Results of the collision and loop detection algorithm in RFC 3550, 8.2
+const (
+	// CollisionNone means the packet can be processed normally
+	CollisionNone CollisionType = iota
+	// CollisionThirdParty means two other participants use the same SSRC.
+	// The packet should be discarded.
+	CollisionThirdParty
+	// LoopThirdParty means packets of another participant are looped back.
+	// The packet should be discarded.
+	LoopThirdParty
+	// LoopOwn means the local participant's packets are looped back. The
+	// packet should be discarded.
+	LoopOwn
+	// CollisionOwn means another participant uses the local SSRC. The
+	// local participant must send a BYE for its SSRC and choose a new one.
+	CollisionOwn
+)
+
+func (c CollisionType) String() string {
+	switch c {
+	case CollisionNone:
+		return "none"
+	case CollisionThirdParty:
+		return "third party collision"
+	case LoopThirdParty:
+		return "third party loop"
+	case LoopOwn:
+		return "own loop"
+	case CollisionOwn:
+		return "own collision"
+	default:
+		return "unknown"
+	}
+}
+
+// A CollisionResult describes the outcome of observing an SSRC.
+type CollisionResult struct {
+	Type CollisionType
+	// The SSRC that was observed
+	SSRC uint32
+	// For CollisionOwn, the BYE to send for the old SSRC
+	Goodbye *Goodbye
+	// For CollisionOwn, an unused SSRC to switch to
+	NewSSRC uint32
+}
+
+type collisionSource struct {
+	addr  string
+	cname string
+}
+
+// A CollisionDetector detects SSRC collisions and forwarding loops from the
+// transport addresses that SSRCs are received from, as described in
+// RFC 3550, 8.2
+type CollisionDetector struct {
+	// The SSRC and CNAME of the local participant
+	LocalSSRC  uint32
+	LocalCNAME string
+	// How long conflicting transport addresses are remembered. If zero,
+	// DefaultConflictTimeout is used.
+	ConflictTimeout time.Duration
+	// The source of the current time. If nil, SystemClock is used.
+	Clock Clock
+	// The maximum number of remote SSRCs tracked. Once reached, the SSRC
+	// observed least recently is forgotten to make room for a new one. If
+	// zero, DefaultMaxMembers is used.
+	MaxSources int
+
+	sources   map[uint32]*collisionSource
+	lastSeen  lastSeenQueue
+	conflicts map[string]time.Time
+}
+
+// NewCollisionDetector creates a CollisionDetector for the local participant.
+func NewCollisionDetector(localSSRC uint32, localCNAME string) *CollisionDetector {
+	return &CollisionDetector{
+		LocalSSRC:  localSSRC,
+		LocalCNAME: localCNAME,
+		sources:    map[uint32]*collisionSource{},
+		conflicts:  map[string]time.Time{},
+	}
+}
+
+func (d *CollisionDetector) init() {
+	if d.sources == nil {
+		d.sources = map[uint32]*collisionSource{}
+	}
+	if d.conflicts == nil {
+		d.conflicts = map[string]time.Time{}
+	}
+}
+
+// Observe checks an SSRC received from addr. cname is the CNAME sent for
+// the SSRC in an SDES chunk, or empty if the SSRC wasn't seen in an SDES
+// chunk with a CNAME.
+func (d *CollisionDetector) Observe(ssrc uint32, addr net.Addr, cname string) CollisionResult {
+	d.init()
+	now := clockNow(d.Clock)
+	d.expireConflicts(now)
+
+	result := CollisionResult{Type: CollisionNone, SSRC: ssrc}
+	key := addr.String()
+
+	if ssrc == d.LocalSSRC {
+		if _, ok := d.conflicts[key]; ok {
+			d.conflicts[key] = now
+			result.Type = LoopOwn
+			return result
+		}
+
+		// A CNAME matching our own means our own packets came back
+		if cname != "" && cname == d.LocalCNAME {
+			d.conflicts[key] = now
+			result.Type = LoopOwn
+			return result
+		}
+
+		d.conflicts[key] = now
+		result.Type = CollisionOwn
+		result.Goodbye = &Goodbye{Sources: []uint32{ssrc}, Reason: collisionReason}
+		result.NewSSRC = d.unusedSSRC()
+		return result
+	}
+
+	src, ok := d.sources[ssrc]
+	if !ok {
+		d.makeRoom()
+		d.sources[ssrc] = &collisionSource{addr: key, cname: cname}
+		d.lastSeen.touch(ssrc, now)
+		return result
+	}
+	d.lastSeen.touch(ssrc, now)
+	if src.cname == "" {
+		src.cname = cname
+	}
+	if src.addr == key {
+		return result
+	}
+
+	if cname != "" && src.cname != "" && cname != src.cname {
+		result.Type = CollisionThirdParty
+	} else {
+		result.Type = LoopThirdParty
+	}
+	return result
+}
+
+// ObservePackets runs Observe for every SSRC sent in a list of packets that
+// were received from addr, and returns the results that aren't CollisionNone.
+func (d *CollisionDetector) ObservePackets(packets []Packet, addr net.Addr) []CollisionResult {
+	var out []CollisionResult
+	observe := func(ssrc uint32, cname string) {
+		if r := d.Observe(ssrc, addr, cname); r.Type != CollisionNone {
+			out = append(out, r)
+		}
+	}
+
+	var visit func(Packet)
+	visit = func(packet Packet) {
+		switch p := packet.(type) {
+		case *CompoundPacket:
+			for _, inner := range *p {
+				visit(inner)
+			}
+		case *SenderReport:
+			observe(p.SSRC, "")
+		case *ReceiverReport:
+			observe(p.SSRC, "")
+		case *SourceDescription:
+			for _, chunk := range p.Chunks {
+				cname := ""
+				for _, item := range chunk.Items {
+					if item.Type == SDESCNAME {
+						cname = item.Text
+					}
+				}
+				observe(chunk.Source, cname)
+			}
+		case *Goodbye:
+			for _, ssrc := range p.Sources {
+				observe(ssrc, "")
+			}
+		}
+	}
+	for _, p := range packets {
+		visit(p)
+	}
+	return out
+}
+
+// ChangeSSRC switches the local participant to newSSRC after a collision.
+// The old SSRC is kept in the table with the colliding participant's
+// address, so its packets are treated as a regular remote source.
+func (d *CollisionDetector) ChangeSSRC(newSSRC uint32, collidingAddr net.Addr) {
+	d.init()
+	if _, ok := d.sources[d.LocalSSRC]; !ok {
+		d.makeRoom()
+	}
+	d.sources[d.LocalSSRC] = &collisionSource{addr: collidingAddr.String()}
+	d.lastSeen.touch(d.LocalSSRC, clockNow(d.Clock))
+	d.LocalSSRC = newSSRC
+}
+
+// Forget removes an SSRC from the table, e.g. after it sent a BYE or timed out.
+func (d *CollisionDetector) Forget(ssrc uint32) {
+	delete(d.sources, ssrc)
+	d.lastSeen.remove(ssrc)
+}
+
+// makeRoom forgets the least recently observed SSRCs until another fits
+func (d *CollisionDetector) makeRoom() {
+	maxSources := d.MaxSources
+	if maxSources <= 0 {
+		maxSources = DefaultMaxMembers
+	}
+	for len(d.sources) >= maxSources {
+		oldest, ok := d.lastSeen.oldest()
+		if !ok {
+			return
+		}
+		d.Forget(oldest)
+	}
+}
+
+func (d *CollisionDetector) expireConflicts(now time.Time) {
+	timeout := d.ConflictTimeout
+	if timeout == 0 {
+		timeout = DefaultConflictTimeout
+	}
+	for addr, seen := range d.conflicts {
+		if now.Sub(seen) > timeout {
+			delete(d.conflicts, addr)
+		}
+	}
+}
+
+func (d *CollisionDetector) unusedSSRC() uint32 {
+	for {
+		ssrc := rand.Uint32() // nolint:gosec
+		if _, ok := d.sources[ssrc]; !ok && ssrc != d.LocalSSRC {
+			return ssrc
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package rtcp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollisionDetectorThirdParty(t *testing.T) {
+	assert := assert.New(t)
+
+	a := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}
+	b := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 5000}
+
+	d := NewCollisionDetector(1, "local")
+	assert.Equal(CollisionNone, d.Observe(2, a, "two").Type)
+	assert.Equal(CollisionNone, d.Observe(2, a, "").Type)
+
+	// same CNAME from another address is a loop
+	assert.Equal(LoopThirdParty, d.Observe(2, b, "two").Type)
+	assert.Equal(LoopThirdParty, d.Observe(2, b, "").Type)
+	// different CNAME is a collision
+	assert.Equal(CollisionThirdParty, d.Observe(2, b, "other").Type)
+
+	d.Forget(2)
+	assert.Equal(CollisionNone, d.Observe(2, b, "other").Type)
+}
+
+func TestCollisionDetectorOwn(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(100, 0)
+	a := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}
+	b := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 5000}
+
+	d := NewCollisionDetector(1, "local")
+	d.Clock = ClockFunc(func() time.Time { return now })
+
+	r := d.Observe(1, a, "")
+	assert.Equal(CollisionOwn, r.Type)
+	assert.Equal(&Goodbye{Sources: []uint32{1}, Reason: collisionReason}, r.Goodbye)
+	assert.NotEqual(uint32(1), r.NewSSRC)
+
+	// the address is now known to conflict
+	assert.Equal(LoopOwn, d.Observe(1, a, "").Type)
+	// our own CNAME coming back is a loop
+	assert.Equal(LoopOwn, d.Observe(1, b, "local").Type)
+
+	d.ChangeSSRC(r.NewSSRC, a)
+	assert.Equal(r.NewSSRC, d.LocalSSRC)
+	assert.Equal(CollisionNone, d.Observe(1, a, "").Type)
+
+	// conflicting addresses expire
+	now = now.Add(DefaultConflictTimeout + time.Second)
+	assert.Equal(CollisionOwn, d.Observe(r.NewSSRC, a, "").Type)
+}
+
+func TestCollisionDetectorObservePackets(t *testing.T) {
+	a := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}
+	b := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 5000}
+
+	d := NewCollisionDetector(1, "local")
+	results := d.ObservePackets([]Packet{
+		&CompoundPacket{
+			&ReceiverReport{SSRC: 2},
+			&SourceDescription{Chunks: []SourceDescriptionChunk{{
+				Source: 2,
+				Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: "two"}},
+			}}},
+		},
+	}, a)
+	assert.Empty(t, results)
+
+	results = d.ObservePackets([]Packet{
+		&SenderReport{SSRC: 1},
+		&SourceDescription{Chunks: []SourceDescriptionChunk{{
+			Source: 2,
+			Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: "not two"}},
+		}}},
+	}, b)
+	assert.Len(t, results, 2)
+	assert.Equal(t, CollisionOwn, results[0].Type)
+	assert.Equal(t, CollisionThirdParty, results[1].Type)
+	assert.Equal(t, uint32(2), results[1].SSRC)
+}
+
+func TestCollisionDetectorMaxSources(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1000, 0)
+	a := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}
+	b := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 5000}
+
+	d := NewCollisionDetector(1, "local")
+	d.Clock = ClockFunc(func() time.Time { return now })
+	d.MaxSources = 2
+	d.Observe(2, a, "")
+	now = now.Add(time.Second)
+	d.Observe(3, a, "")
+	now = now.Add(time.Second)
+	d.Observe(2, a, "")
+
+	// 3 was observed least recently, so it's forgotten and its SSRC is
+	// accepted from another address
+	now = now.Add(time.Second)
+	d.Observe(4, a, "")
+	assert.Len(d.sources, 2)
+	assert.Equal(CollisionNone, d.Observe(3, b, "").Type)
+	assert.Equal(LoopThirdParty, d.Observe(3, a, "").Type)
+
+	// a flood of new SSRCs stays within the limit
+	d.MaxSources = 100
+	for i := uint32(0); i < 10000; i++ {
+		now = now.Add(time.Millisecond)
+		d.Observe(1000+i, a, "")
+	}
+	assert.Len(d.sources, 100)
+	assert.Equal(100, d.lastSeen.Len())
+	_, ok := d.sources[1000+9900]
+	assert.True(ok)
+}
@@ -0,0 +1,132 @@
+package rtcp
+
+// This file provides the names later releases of upstream pion/rtcp export
+// for the packet types both packages have, so code written against either
+// compiles against this package without rewriting call sites. Names that
+// match upstream already, such as the packet types, their fields and
+// Unmarshal, need no shim. The extended report blocks of the two packages
+// differ and have no equivalents here.
+
+// Status chunk and symbol values of TransportLayerCC under their upstream
+// names. They are untyped, so they can be assigned to the uint16 fields of
+// upstream code and to PacketStatusSymbol fields alike.
+const (
+	TypeTCCRunLengthChunk    = typeRunLengthChunk
+	TypeTCCStatusVectorChunk = typeStatusVectorChunk
+
+	TypeTCCPacketNotReceived          = 0 // TypePacketNotReceived
+	TypeTCCPacketReceivedSmallDelta   = 1 // TypePacketReceivedSmallDelta
+	TypeTCCPacketReceivedLargeDelta   = 2 // TypePacketReceivedLargeDelta
+	TypeTCCPacketReceivedWithoutDelta = 3 // TypePacketReceivedWithoutDelta
+
+	TypeTCCSymbolSizeOneBit = typeSymbolSizeOneBit
+	TypeTCCSymbolSizeTwoBit = typeSymbolSizeTwoBit
+
+	// RecvDelta.Delta is in microseconds, in multiples of this
+	TypeTCCDeltaScaleFactor = delta250us
+)
+
+// NewCNAMESourceDescription creates a SourceDescription with a single chunk
+// carrying the CNAME of ssrc.
+func NewCNAMESourceDescription(ssrc uint32, cname string) *SourceDescription {
+	return &SourceDescription{
+		Chunks: []SourceDescriptionChunk{{
+			Source: ssrc,
+			Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: cname}},
+		}},
+	}
+}
+
+// Range calls f with each sequence number the NackPair reports lost, in
+// order, until f returns false.
+func (n *NackPair) Range(f func(seqno uint16) bool) {
+	if !f(n.PacketID) {
+		return
+	}
+	for i := uint16(0); i < 16; i++ {
+		if n.LostPackets&(1<<i) != 0 && !f(n.PacketID+i+1) {
+			return
+		}
+	}
+}
+
+// MarshalSize returns the size of the packet once marshaled.
+func (r SenderReport) MarshalSize() int {
+	return r.len()
+}
+
+// MarshalSize returns the size of the packet once marshaled.
+func (r ReceiverReport) MarshalSize() int {
+	return r.len()
+}
+
+// MarshalSize returns the size of the packet once marshaled.
+func (g Goodbye) MarshalSize() int {
+	return g.len()
+}
+
+// MarshalSize returns the size of the packet once marshaled.
+func (a ApplicationDefined) MarshalSize() int {
+	return a.len()
+}
+
+// MarshalSize returns the size of the packet once marshaled.
+func (x ExtendedReport) MarshalSize() int {
+	return int(x.Header().Length+1) * 4
+}
+
+// MarshalSize returns the size of the packet once marshaled.
+func (p TransportLayerNack) MarshalSize() int {
+	return p.len()
+}
+
+// MarshalSize returns the size of the packet once marshaled.
+func (p RapidResynchronizationRequest) MarshalSize() int {
+	return p.len()
+}
+
+// MarshalSize returns the size of the packet once marshaled.
+func (t TransportLayerCC) MarshalSize() int {
+	return t.len()
+}
+
+// MarshalSize returns the size of the packet once marshaled.
+func (p PictureLossIndication) MarshalSize() int {
+	return p.len()
+}
+
+// MarshalSize returns the size of the packet once marshaled.
+func (p FullIntraRequest) MarshalSize() int {
+	return p.len()
+}
+
+// MarshalSize returns the size of the packet once marshaled.
+func (p SliceLossIndication) MarshalSize() int {
+	return p.len()
+}
+
+// MarshalSize returns the size of the packet once marshaled.
+func (r RawPacket) MarshalSize() int {
+	return len(r)
+}
+
+// MarshalSize returns the size of the packets once marshaled.
+func (c CompoundPacket) MarshalSize() int {
+	n := 0
+	for _, p := range c {
+		n += marshalSize(p)
+	}
+	return n
+}
+
+// marshalSize returns the size of p once marshaled, from its MarshalSize
+// method if it has one, or zero if it fails to marshal
+func marshalSize(p Packet) int {
+	if s, ok := p.(interface{ MarshalSize() int }); ok {
+		return s.MarshalSize()
+	}
+	if data, err := p.Marshal(); err == nil {
+		return len(data)
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalSize(t *testing.T) {
+	vectors, err := TestVectors()
+	assert.NoError(t, err)
+
+	packets := []Packet{
+		&SenderReport{SSRC: 1, Reports: []ReceptionReport{{SSRC: 2}}, ProfileExtensions: []byte{1, 2, 3}},
+		&Goodbye{Sources: []uint32{1}, Reason: "bye"},
+		&ApplicationDefined{SSRC: 1, Name: "NAME", Data: []byte{1}},
+		(*RawPacket)(&realPacket),
+	}
+	for _, v := range vectors {
+		packets = append(packets, v.Packets...)
+	}
+	c := CompoundPacket{packets[0], NewCNAMESourceDescription(1, "cname")}
+	packets = append(packets, &c)
+
+	for _, p := range packets {
+		data, err := p.Marshal()
+		assert.NoError(t, err, "%T", p)
+		s, ok := p.(interface{ MarshalSize() int })
+		if !ok {
+			// a packet type upstream doesn't have
+			continue
+		}
+		assert.Equal(t, len(data), s.MarshalSize(), "%T", p)
+	}
+}
+
+func TestNewCNAMESourceDescription(t *testing.T) {
+	assert.Equal(t, &SourceDescription{
+		Chunks: []SourceDescriptionChunk{{
+			Source: 1,
+			Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: "cname"}},
+		}},
+	}, NewCNAMESourceDescription(1, "cname"))
+}
+
+func TestNackPairRange(t *testing.T) {
+	n := &NackPair{PacketID: 65534, LostPackets: 0x8005}
+
+	var seqs []uint16
+	n.Range(func(seq uint16) bool {
+		seqs = append(seqs, seq)
+		return true
+	})
+	assert.Equal(t, n.PacketList(), seqs)
+
+	seqs = nil
+	n.Range(func(seq uint16) bool {
+		seqs = append(seqs, seq)
+		return len(seqs) < 2
+	})
+	assert.Equal(t, []uint16{65534, 65535}, seqs)
+}
+
+func TestTCCConstants(t *testing.T) {
+	// the untyped constants fit upstream's uint16 fields and the
+	// PacketStatusSymbol fields here
+	var upstream uint16 = TypeTCCPacketReceivedLargeDelta
+	chunk := RunLengthChunk{Type: TypeTCCRunLengthChunk, PacketStatusSymbol: TypeTCCPacketReceivedLargeDelta}
+	assert.Equal(t, TypePacketReceivedLargeDelta, chunk.PacketStatusSymbol)
+	assert.Equal(t, uint16(2), upstream)
+	assert.Equal(t, PacketStatusSymbol(TypeTCCPacketNotReceived), TypePacketNotReceived)
+	assert.Equal(t, PacketStatusSymbol(TypeTCCPacketReceivedSmallDelta), TypePacketReceivedSmallDelta)
+	assert.Equal(t, PacketStatusSymbol(TypeTCCPacketReceivedWithoutDelta), TypePacketReceivedWithoutDelta)
+	assert.Equal(t, 1, TypeTCCSymbolSizeTwoBit)
+	assert.Equal(t, int64(250), int64(TypeTCCDeltaScaleFactor))
+}
@@ -0,0 +1,157 @@
+package rtcp
+
+import "sort"
+
+// A CompoundBuilder assembles a CompoundPacket from packets added in any
+// order. By default Build puts them in a canonical order, so the same set of
+// packets always marshals to the same bytes:
+//
+//	SenderReports, then ReceiverReports
+//	SourceDescriptions
+//	all other packets, ordered by packet type and then format, e.g. NACK
+//	  (RTPFB) before PLI (PSFB)
+//	Goodbyes last
+//
+// Packets of the same kind keep the order they were added in.
+type CompoundBuilder struct {
+	// If set, Build keeps the packets in the order they were added.
+	PreserveOrder bool
+
+	packets []Packet
+}
+
+// NewCompoundBuilder creates an empty CompoundBuilder.
+func NewCompoundBuilder() *CompoundBuilder {
+	return &CompoundBuilder{}
+}
+
+// Add appends packets to the compound. The packets of a CompoundPacket are
+// added individually.
+func (b *CompoundBuilder) Add(packets ...Packet) {
+	for _, p := range packets {
+		if c, ok := p.(*CompoundPacket); ok {
+			b.Add(*c...)
+			continue
+		}
+		b.packets = append(b.packets, p)
+	}
+}
+
+// Len returns the number of packets added.
+func (b *CompoundBuilder) Len() int {
+	return len(b.packets)
+}
+
+// Build returns the packets as a CompoundPacket, in canonical order unless
+// PreserveOrder is set. It doesn't validate the result; CompoundPacket.Marshal
+// does.
+func (b *CompoundBuilder) Build() CompoundPacket {
+	out := make(CompoundPacket, len(b.packets))
+	copy(out, b.packets)
+	if b.PreserveOrder {
+		return out
+	}
+
+	sorted := make([]orderedPacket, len(out))
+	for i, p := range out {
+		sorted[i] = orderedPacket{key: compoundOrder(p), packet: p}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].key.less(sorted[j].key)
+	})
+	for i := range sorted {
+		out[i] = sorted[i].packet
+	}
+	return out
+}
+
+// Fit builds the CompoundPacket like Build, but keeps it within maxSize
+// bytes, e.g. an MTUBudget's Size: packets are taken in order while they
+// fit, and those that don't are skipped, so smaller ones after them may
+// still be taken. The skipped packets are returned in order, for the next
+// datagram. If the leading report or a SourceDescription carrying a CNAME
+// doesn't fit, no packets are taken, as a compound packet needs both.
+func (b *CompoundBuilder) Fit(maxSize int) (CompoundPacket, []Packet) {
+	var fit CompoundPacket
+	var rest []Packet
+	size := 0
+	built := b.Build()
+	for i, p := range built {
+		n := marshalSize(p)
+		if size+n > maxSize {
+			if requiredInCompound(p, i) {
+				return nil, built
+			}
+			rest = append(rest, p)
+			continue
+		}
+		fit = append(fit, p)
+		size += n
+	}
+	return fit, rest
+}
+
+// requiredInCompound reports whether p, at index i of a built compound
+// packet, can't be left out of it: the leading report, and SourceDescriptions
+// carrying a CNAME
+func requiredInCompound(p Packet, i int) bool {
+	switch p := p.(type) {
+	case *SenderReport, *ReceiverReport:
+		return i == 0
+	case *SourceDescription:
+		for _, chunk := range p.Chunks {
+			if hasCNAME(chunk) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Marshal builds the CompoundPacket, then validates and encodes it.
+func (b *CompoundBuilder) Marshal() ([]byte, error) {
+	return b.Build().Marshal()
+}
+
+// Reset removes all packets from the builder.
+func (b *CompoundBuilder) Reset() {
+	b.packets = nil
+}
+
+type orderedPacket struct {
+	key    compoundOrderKey
+	packet Packet
+}
+
+type compoundOrderKey struct {
+	rank   int
+	typ    PacketType
+	format uint8
+}
+
+func (k compoundOrderKey) less(o compoundOrderKey) bool {
+	if k.rank != o.rank {
+		return k.rank < o.rank
+	}
+	if k.typ != o.typ {
+		return k.typ < o.typ
+	}
+	return k.format < o.format
+}
+
+// compoundOrder returns the canonical position of p in a compound packet
+func compoundOrder(p Packet) compoundOrderKey {
+	h := headerOf(p)
+	switch h.Type {
+	case TypeSenderReport:
+		return compoundOrderKey{rank: 0}
+	case TypeReceiverReport:
+		return compoundOrderKey{rank: 1}
+	case TypeSourceDescription:
+		return compoundOrderKey{rank: 2}
+	case TypeGoodbye:
+		return compoundOrderKey{rank: 4}
+	default:
+		return compoundOrderKey{rank: 3, typ: h.Type, format: h.Count}
+	}
+}
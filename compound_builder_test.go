@@ -0,0 +1,94 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompoundBuilderCanonicalOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	sr := &SenderReport{SSRC: 1}
+	rr := &ReceiverReport{SSRC: 1}
+	sdes := &SourceDescription{Chunks: []SourceDescriptionChunk{{
+		Source: 1,
+		Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: "cname"}},
+	}}}
+	nack := &TransportLayerNack{MediaSSRC: 2, Nacks: []NackPair{{PacketID: 1}}}
+	tcc := &TransportLayerCC{MediaSSRC: 2}
+	pli := &PictureLossIndication{MediaSSRC: 2}
+	remb := &ReceiverEstimatedMaximumBitrate{Bitrate: 1000, SSRCs: []uint32{2}}
+	pli2 := &PictureLossIndication{MediaSSRC: 3}
+	bye := &Goodbye{Sources: []uint32{1}}
+
+	b := NewCompoundBuilder()
+	b.Add(bye, pli, remb, tcc, sdes, &CompoundPacket{rr, nack}, pli2, sr)
+	assert.Equal(9, b.Len())
+	assert.Equal(CompoundPacket{sr, rr, sdes, nack, tcc, pli, pli2, remb, bye}, b.Build())
+
+	// the bytes don't depend on the order packets were added in
+	data, err := b.Marshal()
+	assert.NoError(err)
+	other := NewCompoundBuilder()
+	other.Add(sr, nack, remb, bye, pli, sdes, tcc, pli2, rr)
+	otherData, err := other.Marshal()
+	assert.NoError(err)
+	assert.Equal(data, otherData)
+
+	b.Reset()
+	assert.Equal(0, b.Len())
+}
+
+func TestCompoundBuilderPreserveOrder(t *testing.T) {
+	rr := &ReceiverReport{SSRC: 1}
+	bye := &Goodbye{Sources: []uint32{1}}
+	pli := &PictureLossIndication{MediaSSRC: 2}
+
+	b := NewCompoundBuilder()
+	b.PreserveOrder = true
+	b.Add(rr, bye, pli)
+	assert.Equal(t, CompoundPacket{rr, bye, pli}, b.Build())
+
+	// still validated when marshaling
+	_, err := b.Marshal()
+	assert.Equal(t, errPacketBeforeCNAME, err)
+}
+
+func TestCompoundBuilderFit(t *testing.T) {
+	assert := assert.New(t)
+
+	rr := &ReceiverReport{SSRC: 1, Reports: make([]ReceptionReport, 2)} // 56 bytes
+	sdes := NewCNAMESourceDescription(1, "cname")                       // 20 bytes
+	remb := &ReceiverEstimatedMaximumBitrate{Bitrate: 1000, SSRCs: make([]uint32, 10)}
+	pli := &PictureLossIndication{MediaSSRC: 2} // 12 bytes
+	bye := &Goodbye{Sources: []uint32{1}}       // 8 bytes
+
+	b := NewCompoundBuilder()
+	b.Add(bye, remb, pli, sdes, rr)
+
+	fit, rest := b.Fit(1200)
+	assert.Equal(b.Build(), fit)
+	assert.Empty(rest)
+
+	// the REMB doesn't fit, but the packets after it do
+	fit, rest = b.Fit(100)
+	assert.Equal(CompoundPacket{rr, sdes, pli, bye}, fit)
+	assert.Equal([]Packet{remb}, rest)
+	data, err := fit.Marshal()
+	assert.NoError(err)
+	assert.True(len(data) <= 100)
+
+	// nothing fits without the leading report
+	fit, rest = b.Fit(50)
+	assert.Empty(fit)
+	assert.Equal([]Packet{rr, sdes, pli, remb, bye}, rest)
+
+	// nor without the CNAME, though the smaller packets after it would fit
+	fit, rest = b.Fit(70)
+	assert.Empty(fit)
+	assert.Equal([]Packet{rr, sdes, pli, remb, bye}, rest)
+
+	fit, _ = b.Fit(MTUBudget{MTU: 28 + 100}.Size())
+	assert.Equal(CompoundPacket{rr, sdes, pli, bye}, fit)
+}
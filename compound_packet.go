@@ -41,16 +41,14 @@ func (c CompoundPacket) Validate() error {
 		// A SourceDescription containing a CNAME must be included in every
 		// CompoundPacket.
 		case *SourceDescription:
-			var hasCNAME bool
-			for _, c := range p.Chunks {
-				for _, it := range c.Items {
-					if it.Type == SDESCNAME {
-						hasCNAME = true
-					}
+			var cname bool
+			for _, chunk := range p.Chunks {
+				if hasCNAME(chunk) {
+					cname = true
 				}
 			}
 
-			if !hasCNAME {
+			if !cname {
 				return errMissingCNAME
 			}
 
@@ -66,32 +64,84 @@ func (c CompoundPacket) Validate() error {
 	return errMissingCNAME
 }
 
-//CNAME returns the CNAME that *must* be present in every CompoundPacket
+// CNAME returns the CNAME that *must* be present in every CompoundPacket.
+// If c breaks the rules checked by Validate, the error Validate returns is
+// reported along with the CNAME, if there is one.
 func (c CompoundPacket) CNAME() (string, error) {
-	var err error
+	sdes, err := c.cnameDescription()
+	if sdes == nil {
+		return "", err
+	}
+	for _, chunk := range sdes.Chunks {
+		for _, it := range chunk.Items {
+			if it.Type == SDESCNAME {
+				return it.Text, err
+			}
+		}
+	}
+	return "", errMissingCNAME
+}
 
-	if len(c) < 1 {
-		return "", errEmptyCompound
+// SSRCs returns the sources the CNAME SourceDescription assigns a CNAME to,
+// usually all the streams of a single participant. Errors are reported as
+// by CNAME.
+func (c CompoundPacket) SSRCs() ([]uint32, error) {
+	sdes, err := c.cnameDescription()
+	if sdes == nil {
+		return nil, err
+	}
+	var out []uint32
+	for _, chunk := range sdes.Chunks {
+		if hasCNAME(chunk) {
+			out = append(out, chunk.Source)
+		}
+	}
+	return out, err
+}
+
+// cnameDescription returns the first SourceDescription of c carrying a
+// CNAME, and the error Validate returns for c
+func (c CompoundPacket) cnameDescription() (*SourceDescription, error) {
+	if len(c) == 0 {
+		return nil, errEmptyCompound
+	}
+
+	var err error
+	switch c[0].(type) {
+	case *SenderReport, *ReceiverReport:
+	default:
+		err = errBadFirstPacket
 	}
 
 	for _, pkt := range c[1:] {
-		sdes, ok := pkt.(*SourceDescription)
-		if ok {
-			for _, c := range sdes.Chunks {
-				for _, it := range c.Items {
-					if it.Type == SDESCNAME {
-						return it.Text, err
-					}
+		switch p := pkt.(type) {
+		case *ReceiverReport:
+		case *SourceDescription:
+			for _, chunk := range p.Chunks {
+				if hasCNAME(chunk) {
+					return p, err
 				}
 			}
-		} else {
-			_, ok := pkt.(*ReceiverReport)
-			if !ok {
+			if err == nil {
+				err = errMissingCNAME
+			}
+		default:
+			if err == nil {
 				err = errPacketBeforeCNAME
 			}
 		}
 	}
-	return "", errMissingCNAME
+	return nil, errMissingCNAME
+}
+
+// hasCNAME reports whether chunk has a CNAME item
+func hasCNAME(chunk SourceDescriptionChunk) bool {
+	for _, it := range chunk.Items {
+		if it.Type == SDESCNAME {
+			return true
+		}
+	}
+	return false
 }
 
 // Marshal encodes the CompoundPacket as binary.
@@ -126,12 +176,24 @@ func (c *CompoundPacket) Unmarshal(rawData []byte) error {
 	return nil
 }
 
-// DestinationSSRC returns the synchronization sources associated with this
-// CompoundPacket's reception report.
+// DestinationSSRC returns the synchronization sources referred to by any of
+// the packets in this CompoundPacket, in order of first appearance and
+// without duplicates.
 func (c CompoundPacket) DestinationSSRC() []uint32 {
 	if len(c) == 0 {
 		return nil
 	}
 
-	return c[0].DestinationSSRC()
+	out := []uint32{}
+	seen := make(map[uint32]struct{})
+	for _, p := range c {
+		for _, ssrc := range p.DestinationSSRC() {
+			if _, ok := seen[ssrc]; ok {
+				continue
+			}
+			seen[ssrc] = struct{}{}
+			out = append(out, ssrc)
+		}
+	}
+	return out
 }
@@ -294,3 +294,112 @@ func TestCompoundPacketRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestCompoundPacketSSRCs(t *testing.T) {
+	sdes := &SourceDescription{
+		Chunks: []SourceDescriptionChunk{
+			{Source: 1, Items: []SourceDescriptionItem{{Type: SDESCNAME, Text: "cname"}}},
+			{Source: 2, Items: []SourceDescriptionItem{{Type: SDESName, Text: "name"}}},
+			{Source: 3, Items: []SourceDescriptionItem{{Type: SDESCNAME, Text: "cname"}}},
+		},
+	}
+
+	for _, test := range []struct {
+		Name   string
+		Packet CompoundPacket
+		Err    error
+		SSRCs  []uint32
+	}{
+		{
+			Name: "empty",
+			Err:  errEmptyCompound,
+		},
+		{
+			Name:   "valid",
+			Packet: CompoundPacket{&ReceiverReport{}, sdes},
+			SSRCs:  []uint32{1, 3},
+		},
+		{
+			Name:   "bad first packet",
+			Packet: CompoundPacket{&Goodbye{}, sdes},
+			Err:    errBadFirstPacket,
+			SSRCs:  []uint32{1, 3},
+		},
+		{
+			Name:   "no cname",
+			Packet: CompoundPacket{&ReceiverReport{}, &SourceDescription{}},
+			Err:    errMissingCNAME,
+		},
+	} {
+		ssrcs, err := test.Packet.SSRCs()
+		if got, want := err, test.Err; got != want {
+			t.Fatalf("SSRCs(%s) err = %v, want %v", test.Name, got, want)
+		}
+		if got, want := ssrcs, test.SSRCs; !reflect.DeepEqual(got, want) {
+			t.Fatalf("SSRCs(%s) = %v, want %v", test.Name, got, want)
+		}
+		if test.Packet != nil {
+			if got, want := test.Packet.Validate(), test.Err; got != want {
+				t.Fatalf("Validate(%s) = %v, want %v", test.Name, got, want)
+			}
+		}
+	}
+}
+
+// withPadding returns the packet raw padded to a multiple of blockSize
+// octets, as SRTCP senders do before encrypting. If counted is false, the
+// length field isn't updated to include the padding.
+func withPadding(raw []byte, blockSize int, counted bool) []byte {
+	padding := blockSize - len(raw)%blockSize
+	out := append([]byte{}, raw...)
+	out[0] |= 1 << paddingShift
+	out = append(out, make([]byte, padding)...)
+	out[len(out)-1] = byte(padding)
+	if counted {
+		out[3] += byte(padding / 4)
+	}
+	return out
+}
+
+func TestUnmarshalTrailingPadding(t *testing.T) {
+	rr := realPacket[:32]
+	sdes := realPacket[32:84]
+	want, err := Unmarshal(realPacket[:84])
+	assert.NoError(t, err)
+
+	for _, test := range []struct {
+		Name string
+		Data []byte
+	}{
+		{"counted", append(append([]byte{}, rr...), withPadding(sdes, 16, true)...)},
+		{"uncounted", append(append([]byte{}, rr...), withPadding(sdes, 16, false)...)},
+		{"block sized", append(append([]byte{}, rr...), withPadding(sdes, 4, true)...)},
+	} {
+		packets, err := Unmarshal(test.Data)
+		if err != nil {
+			t.Fatalf("Unmarshal %q: %v", test.Name, err)
+		}
+		assert.Equal(t, want, packets, test.Name)
+
+		var c CompoundPacket
+		assert.NoError(t, c.Unmarshal(test.Data), test.Name)
+		assert.Equal(t, CompoundPacket(want), c, test.Name)
+
+		decoded, err := NewDecoder().Decode(test.Data, nil)
+		assert.NoError(t, err, test.Name)
+		assert.Equal(t, want, decoded, test.Name)
+	}
+
+	// the pad count must be within the packet
+	bad := withPadding(sdes, 16, true)
+	bad[len(bad)-1] = 0
+	_, err = Unmarshal(bad)
+	assert.Equal(t, errBadPadding, err)
+	bad[len(bad)-1] = 200
+	_, err = Unmarshal(bad)
+	assert.Equal(t, errBadPadding, err)
+
+	// trailing bytes after an unpadded packet are still an error
+	_, err = Unmarshal(append(append([]byte{}, rr...), 0, 0, 0, 4))
+	assert.Error(t, err)
+}
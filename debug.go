@@ -0,0 +1,10 @@
+//go:build !rtcp_nodebug
+// +build !rtcp_nodebug
+
+package rtcp
+
+// debugChecks enables the consistency checks and debug logging of the hot
+// paths. Builds with the rtcp_nodebug tag compile them out, for production
+// servers where every nanosecond per packet counts; default builds and
+// tests keep them.
+const debugChecks = true
@@ -0,0 +1,58 @@
+package rtcp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// shortPacket is a Packet whose length field claims more than it marshals
+type shortPacket struct {
+	RawPacket
+}
+
+func (p shortPacket) Marshal() ([]byte, error) {
+	return []byte{0x80, 0xc9, 0x00, 0x02, 0, 0, 0, 1}, nil
+}
+
+// overcountedPacket is a Packet whose count field announces more reception
+// reports than it marshals
+type overcountedPacket struct {
+	RawPacket
+}
+
+func (p overcountedPacket) Marshal() ([]byte, error) {
+	return []byte{0x82, 0xc9, 0x00, 0x07, 0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, nil
+}
+
+func TestCheckMarshaled(t *testing.T) {
+	data, err := Marshal([]Packet{&ReceiverReport{SSRC: 1}, NewCNAMESourceDescription(1, "cname")})
+	assert.NoError(t, err)
+	assert.NoError(t, checkMarshaled(data))
+	assert.Equal(t, errBadMarshaledLen, checkMarshaled(data[:len(data)-4]))
+
+	_, err = Marshal([]Packet{&shortPacket{}})
+	if debugChecks {
+		assert.True(t, errors.Is(err, errBadMarshaledLen), err)
+	} else {
+		assert.NoError(t, err)
+	}
+
+	// the count fields of packets from this package agree with their bodies
+	data, err = Marshal([]Packet{
+		&SenderReport{SSRC: 1, Reports: []ReceptionReport{{SSRC: 2}, {SSRC: 3}}},
+		&ReceiverReport{SSRC: 1, Reports: []ReceptionReport{{SSRC: 2}}},
+		&SourceDescription{Chunks: []SourceDescriptionChunk{{Source: 1}, {Source: 2}}},
+		&Goodbye{Sources: []uint32{1, 2, 3}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, checkMarshaled(data))
+
+	_, err = Marshal([]Packet{&overcountedPacket{}})
+	if debugChecks {
+		assert.True(t, errors.Is(err, errBadMarshaledCount), err)
+	} else {
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,274 @@
+package rtcp
+
+// A Decoder unmarshals RTCP datagrams like Unmarshal, but reuses the packets
+// and their slices across calls to reduce allocations on busy servers.
+//
+// Packets returned by Decode are only valid until the next call to Decode on
+// the same Decoder. A Decoder must not be used concurrently.
+type Decoder struct {
+	senderReports   []*SenderReport
+	receiverReports []*ReceiverReport
+	descriptions    []*SourceDescription
+	goodbyes        []*Goodbye
+	applications    []*ApplicationDefined
+	extendedReports []*ExtendedReport
+	nacks           []*TransportLayerNack
+	rrrs            []*RapidResynchronizationRequest
+	tccs            []*TransportLayerCC
+	plis            []*PictureLossIndication
+	firs            []*FullIntraRequest
+	slis            []*SliceLossIndication
+	rembs           []*ReceiverEstimatedMaximumBitrate
+	raws            []*RawPacket
+
+	used decoderUsage
+
+	lenient     bool
+	warnings    []error
+	observer    Observer
+	stats       *DecoderStats
+	maxElements int
+	logger      Logger
+
+	// packets scratch of DecodeReceived
+	received []Packet
+}
+
+// A DecoderOption configures a Decoder.
+type DecoderOption func(*Decoder)
+
+// decoderUsage counts how many pooled packets of each type are handed out
+type decoderUsage struct {
+	senderReports   int
+	receiverReports int
+	descriptions    int
+	goodbyes        int
+	applications    int
+	extendedReports int
+	nacks           int
+	rrrs            int
+	tccs            int
+	plis            int
+	firs            int
+	slis            int
+	rembs           int
+	raws            int
+}
+
+// NewDecoder creates a Decoder with the given options.
+func NewDecoder(opts ...DecoderOption) *Decoder {
+	d := &Decoder{}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Decode unmarshals every RTCP packet in raw into dst, which is truncated
+// first, and returns the resulting slice. The packets refer to raw where
+// Unmarshal would, so raw must not be modified while they are in use.
+func (d *Decoder) Decode(raw []byte, dst []Packet) ([]Packet, error) {
+	dst, err := d.decode(raw, dst)
+	if debugChecks && err != nil && d.logger != nil {
+		d.logger.Debug("rtcp: datagram failed to decode", "error", err, "size", len(raw))
+	}
+	if d.stats != nil {
+		d.stats.Datagrams++
+		if err != nil {
+			d.stats.Errors++
+		}
+	}
+	return dst, err
+}
+
+func (d *Decoder) decode(raw []byte, dst []Packet) ([]Packet, error) {
+	d.used = decoderUsage{}
+	dst = dst[:0]
+	d.warnings = d.warnings[:0]
+	if d.lenient {
+		raw, d.warnings = lenientRepair(raw, d.warnings)
+	}
+	defer d.logWarnings()
+
+	for len(raw) != 0 {
+		p, processed, err := d.unmarshal(raw)
+		if d.stats != nil {
+			d.stats.countPacket(raw, processed, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		dst = append(dst, p)
+		raw = raw[processed:]
+	}
+
+	if len(dst) == 0 {
+		return nil, errInvalidHeader
+	}
+	if d.lenient && !hasCNAMEPacket(dst) {
+		d.warnings = append(d.warnings, errMissingCNAME)
+	}
+	if d.observer != nil {
+		Dispatch(d.observer, dst)
+	}
+	return dst, nil
+}
+
+// unmarshal decodes the packet at the start of raw into a pooled packet
+func (d *Decoder) unmarshal(raw []byte) (Packet, int, error) {
+	if d.maxElements > 0 {
+		if err := checkElementCount(raw, d.maxElements); err != nil {
+			return nil, 0, err
+		}
+	}
+	return unmarshalWith(raw, d.alloc)
+}
+
+// logWarnings passes the warnings of the current datagram to the logger
+func (d *Decoder) logWarnings() {
+	if d.logger == nil {
+		return
+	}
+	for _, w := range d.warnings {
+		d.logger.Warn("rtcp: tolerated malformed datagram", "warning", w)
+	}
+}
+
+// Warnings returns the deviations from RFC 3550 tolerated by the previous
+// call to Decode, see WithLenient. It is only valid until the next call to
+// Decode.
+func (d *Decoder) Warnings() []error {
+	return d.warnings
+}
+
+// alloc returns a reset packet for h from the pools, keeping the capacity of
+// its slices
+func (d *Decoder) alloc(h Header) Packet {
+	switch h.Type {
+	case TypeSenderReport:
+		if d.used.senderReports == len(d.senderReports) {
+			d.senderReports = append(d.senderReports, new(SenderReport))
+		}
+		p := d.senderReports[d.used.senderReports]
+		d.used.senderReports++
+		*p = SenderReport{Reports: p.Reports[:0]}
+		return p
+
+	case TypeReceiverReport:
+		if d.used.receiverReports == len(d.receiverReports) {
+			d.receiverReports = append(d.receiverReports, new(ReceiverReport))
+		}
+		p := d.receiverReports[d.used.receiverReports]
+		d.used.receiverReports++
+		*p = ReceiverReport{Reports: p.Reports[:0]}
+		return p
+
+	case TypeSourceDescription:
+		if d.used.descriptions == len(d.descriptions) {
+			d.descriptions = append(d.descriptions, new(SourceDescription))
+		}
+		p := d.descriptions[d.used.descriptions]
+		d.used.descriptions++
+		*p = SourceDescription{Chunks: p.Chunks[:0]}
+		return p
+
+	case TypeGoodbye:
+		if d.used.goodbyes == len(d.goodbyes) {
+			d.goodbyes = append(d.goodbyes, new(Goodbye))
+		}
+		p := d.goodbyes[d.used.goodbyes]
+		d.used.goodbyes++
+		*p = Goodbye{Sources: p.Sources[:0]}
+		return p
+
+	case TypeApplicationDefined:
+		if d.used.applications == len(d.applications) {
+			d.applications = append(d.applications, new(ApplicationDefined))
+		}
+		p := d.applications[d.used.applications]
+		d.used.applications++
+		*p = ApplicationDefined{}
+		return p
+
+	case TypeExtendedReport:
+		if d.used.extendedReports == len(d.extendedReports) {
+			d.extendedReports = append(d.extendedReports, new(ExtendedReport))
+		}
+		p := d.extendedReports[d.used.extendedReports]
+		d.used.extendedReports++
+		*p = ExtendedReport{Reports: p.Reports[:0]}
+		return p
+
+	case TypeTransportSpecificFeedback:
+		switch h.Count {
+		case FormatTLN:
+			if d.used.nacks == len(d.nacks) {
+				d.nacks = append(d.nacks, new(TransportLayerNack))
+			}
+			p := d.nacks[d.used.nacks]
+			d.used.nacks++
+			*p = TransportLayerNack{Nacks: p.Nacks[:0]}
+			return p
+		case FormatRRR:
+			if d.used.rrrs == len(d.rrrs) {
+				d.rrrs = append(d.rrrs, new(RapidResynchronizationRequest))
+			}
+			p := d.rrrs[d.used.rrrs]
+			d.used.rrrs++
+			*p = RapidResynchronizationRequest{}
+			return p
+		case FormatTCC:
+			if d.used.tccs == len(d.tccs) {
+				d.tccs = append(d.tccs, new(TransportLayerCC))
+			}
+			p := d.tccs[d.used.tccs]
+			d.used.tccs++
+			*p = TransportLayerCC{PacketChunks: p.PacketChunks[:0], RecvDeltas: p.RecvDeltas[:0]}
+			return p
+		}
+
+	case TypePayloadSpecificFeedback:
+		switch h.Count {
+		case FormatPLI:
+			if d.used.plis == len(d.plis) {
+				d.plis = append(d.plis, new(PictureLossIndication))
+			}
+			p := d.plis[d.used.plis]
+			d.used.plis++
+			*p = PictureLossIndication{}
+			return p
+		case FormatFIR:
+			if d.used.firs == len(d.firs) {
+				d.firs = append(d.firs, new(FullIntraRequest))
+			}
+			p := d.firs[d.used.firs]
+			d.used.firs++
+			*p = FullIntraRequest{FIR: p.FIR[:0]}
+			return p
+		case FormatSLI:
+			if d.used.slis == len(d.slis) {
+				d.slis = append(d.slis, new(SliceLossIndication))
+			}
+			p := d.slis[d.used.slis]
+			d.used.slis++
+			*p = SliceLossIndication{SLI: p.SLI[:0]}
+			return p
+		case FormatREMB:
+			if d.used.rembs == len(d.rembs) {
+				d.rembs = append(d.rembs, new(ReceiverEstimatedMaximumBitrate))
+			}
+			p := d.rembs[d.used.rembs]
+			d.used.rembs++
+			*p = ReceiverEstimatedMaximumBitrate{SSRCs: p.SSRCs[:0]}
+			return p
+		}
+	}
+
+	if d.used.raws == len(d.raws) {
+		d.raws = append(d.raws, new(RawPacket))
+	}
+	p := d.raws[d.used.raws]
+	d.used.raws++
+	return p
+}
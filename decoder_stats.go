@@ -0,0 +1,87 @@
+package rtcp
+
+// A PacketKind identifies a kind of RTCP packet by its type and, for
+// feedback messages, its format.
+type PacketKind struct {
+	Type PacketType
+	// The FMT of transport layer and payload specific feedback, zero for
+	// other types
+	Format uint8
+}
+
+// packetKindOf returns the kind of the packet with header h
+func packetKindOf(h Header) PacketKind {
+	k := PacketKind{Type: h.Type}
+	if h.Type == TypeTransportSpecificFeedback || h.Type == TypePayloadSpecificFeedback {
+		k.Format = h.Count
+	}
+	return k
+}
+
+// PacketKindStats counts the packets of one kind a Decoder saw.
+type PacketKindStats struct {
+	// Packets decoded, and their size in bytes including the header and
+	// padding
+	Packets uint64
+	Bytes   uint64
+	// Packets of the kind that failed to decode
+	Errors uint64
+}
+
+// DecoderStats is a snapshot of the counters of a Decoder, see WithStats.
+type DecoderStats struct {
+	// Datagrams passed to Decode, and those that failed to decode
+	Datagrams uint64
+	Errors    uint64
+	// Counters by packet kind. Errors of packets whose header couldn't be
+	// read are only counted in Errors.
+	Kinds map[PacketKind]PacketKindStats
+}
+
+// WithStats makes a Decoder count the packets, bytes and errors of every
+// kind of packet it decodes, giving visibility into what a peer actually
+// sends. See Decoder.Stats.
+func WithStats() DecoderOption {
+	return func(d *Decoder) {
+		d.stats = &DecoderStats{Kinds: map[PacketKind]PacketKindStats{}}
+	}
+}
+
+// Stats returns a snapshot of the counters, or the zero DecoderStats if the
+// Decoder wasn't created WithStats.
+func (d *Decoder) Stats() DecoderStats {
+	if d.stats == nil {
+		return DecoderStats{}
+	}
+	out := *d.stats
+	out.Kinds = make(map[PacketKind]PacketKindStats, len(d.stats.Kinds))
+	for k, s := range d.stats.Kinds {
+		out.Kinds[k] = s
+	}
+	return out
+}
+
+// ResetStats sets the counters back to zero.
+func (d *Decoder) ResetStats() {
+	if d.stats != nil {
+		*d.stats = DecoderStats{Kinds: map[PacketKind]PacketKindStats{}}
+	}
+}
+
+// countPacket counts the packet at the start of raw, which decoded to
+// processed bytes or failed with err
+func (s *DecoderStats) countPacket(raw []byte, processed int, err error) {
+	var h Header
+	if h.Unmarshal(raw) != nil {
+		return
+	}
+	k := packetKindOf(h)
+	c := s.Kinds[k]
+	if err != nil {
+		c.Errors++
+	} else {
+		c.Packets++
+		c.Bytes += uint64(processed)
+	}
+	s.Kinds[k] = c
+}
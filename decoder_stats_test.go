@@ -0,0 +1,49 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoderStats(t *testing.T) {
+	assert := assert.New(t)
+
+	raw, err := Marshal([]Packet{
+		&ReceiverReport{SSRC: 1},
+		&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2},
+		&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 3},
+	})
+	assert.NoError(err)
+
+	d := NewDecoder(WithStats())
+	_, err = d.Decode(raw, nil)
+	assert.NoError(err)
+	// a truncated NACK
+	_, err = d.Decode([]byte{0x81, 0xcd, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01}, nil)
+	assert.Error(err)
+	_, err = d.Decode(nil, nil)
+	assert.Error(err)
+
+	assert.Equal(DecoderStats{
+		Datagrams: 3,
+		Errors:    2,
+		Kinds: map[PacketKind]PacketKindStats{
+			{Type: TypeReceiverReport}:                               {Packets: 1, Bytes: 8},
+			{Type: TypePayloadSpecificFeedback, Format: FormatPLI}:   {Packets: 2, Bytes: 24},
+			{Type: TypeTransportSpecificFeedback, Format: FormatTLN}: {Errors: 1},
+		},
+	}, d.Stats())
+
+	// the snapshot isn't affected by later packets
+	stats := d.Stats()
+	_, err = d.Decode(raw, nil)
+	assert.NoError(err)
+	assert.Equal(uint64(3), stats.Datagrams)
+	assert.Equal(uint64(4), d.Stats().Datagrams)
+
+	d.ResetStats()
+	assert.Equal(DecoderStats{Kinds: map[PacketKind]PacketKindStats{}}, d.Stats())
+
+	assert.Equal(DecoderStats{}, NewDecoder().Stats())
+}
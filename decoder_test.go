@@ -0,0 +1,116 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoder(t *testing.T) {
+	want, err := Unmarshal(realPacket)
+	assert.NoError(t, err)
+
+	d := NewDecoder()
+	var dst []Packet
+	for i := 0; i < 3; i++ {
+		dst, err = d.Decode(realPacket, dst)
+		assert.NoError(t, err)
+		assert.Equal(t, want, dst, "decode %d", i)
+	}
+
+	tcc := &TransportLayerCC{
+		SenderSSRC:         1,
+		MediaSSRC:          2,
+		BaseSequenceNumber: 10,
+		PacketStatusCount:  1,
+		PacketChunks: []PacketStatusChunk{
+			&RunLengthChunk{PacketStatusSymbol: TypePacketReceivedSmallDelta, RunLength: 1},
+		},
+		RecvDeltas: []*RecvDelta{
+			{Type: TypePacketReceivedSmallDelta, Delta: 250},
+		},
+	}
+	raw, err := Marshal([]Packet{tcc, &PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}})
+	assert.NoError(t, err)
+
+	want, err = Unmarshal(raw)
+	assert.NoError(t, err)
+	dst, err = d.Decode(raw, dst)
+	assert.NoError(t, err)
+	assert.Equal(t, want, dst)
+
+	_, err = d.Decode(nil, dst)
+	assert.Equal(t, errInvalidHeader, err)
+
+	_, err = d.Decode(realPacket[:10], dst)
+	assert.Error(t, err)
+}
+
+func TestDecoderAllocs(t *testing.T) {
+	unmarshalAllocs := testing.AllocsPerRun(100, func() {
+		if _, err := Unmarshal(realPacket); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	d := NewDecoder()
+	dst := make([]Packet, 0, 8)
+	decodeAllocs := testing.AllocsPerRun(100, func() {
+		var err error
+		if dst, err = d.Decode(realPacket, dst); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if decodeAllocs >= unmarshalAllocs {
+		t.Fatalf("Decode allocs %v, want fewer than Unmarshal allocs %v", decodeAllocs, unmarshalAllocs)
+	}
+}
+
+func TestDecoderTransportLayerCCAllocs(t *testing.T) {
+	data, err := Marshal([]Packet{benchmarkTransportLayerCC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the chunks and deltas of the pooled packet are decoded into again
+	d := NewDecoder()
+	dst := make([]Packet, 0, 8)
+	allocs := testing.AllocsPerRun(100, func() {
+		if dst, err = d.Decode(data, dst); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("Decode of a TransportLayerCC allocates %v times, want 0", allocs)
+	}
+	assert.Equal(t, want, dst)
+
+	// feedback of another shape only allocates what the pooled packet lacks
+	larger := benchmarkTransportLayerCC()
+	larger.PacketChunks = append(larger.PacketChunks, &RunLengthChunk{PacketStatusSymbol: TypePacketReceivedSmallDelta, RunLength: 20})
+	for i := 0; i < 20; i++ {
+		larger.RecvDeltas = append(larger.RecvDeltas, &RecvDelta{Type: TypePacketReceivedSmallDelta, Delta: 1000})
+	}
+	larger.PacketStatusCount += 20
+	other, err := Marshal([]Packet{larger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOther, err := Unmarshal(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst, err = d.Decode(other, dst); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, wantOther, dst)
+	if dst, err = d.Decode(data, dst); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, want, dst)
+}
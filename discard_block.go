@@ -0,0 +1,286 @@
+package rtcp
+
+import "encoding/binary"
+
+// DiscardType tells why the packets of a DiscardCountReportBlock were
+// discarded. See RFC 7002, 3.1
+type DiscardType uint8
+
+// DiscardType values
+const (
+	// Packets discarded for arriving too early or too late to be played out
+	DiscardTypeLateOrEarly DiscardType = 0
+	// Packets discarded because the jitter buffer was full
+	DiscardTypeOverflow DiscardType = 1
+	// Packets discarded for either reason
+	DiscardTypeAny DiscardType = 2
+)
+
+// A DiscardCountReportBlock reports the number of packets that arrived but
+// were discarded rather than played out, which RR loss doesn't include. See
+// RFC 7002
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|     BT=24     | I |DT |resvd. |       Block length=2          |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|                        SSRC of Source                         |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|                 Number of packets discarded                   |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+type DiscardCountReportBlock struct {
+	// The period the count covers
+	IntervalMetric IntervalMetric
+	DiscardType    DiscardType
+	// SSRC of the media source the packets were discarded from
+	SSRC      uint32
+	Discarded uint32
+}
+
+var _ ReportBlock = (*DiscardCountReportBlock)(nil) // assert is a ReportBlock
+
+const (
+	discardCountBlockLength = 12
+
+	discardTypeShift = 4
+	discardTypeMask  = 0x3
+)
+
+// BlockType returns BlockTypeDiscardCount.
+func (b *DiscardCountReportBlock) BlockType() BlockType {
+	return BlockTypeDiscardCount
+}
+
+// DestinationSSRC returns the SSRC of the media source.
+func (b *DiscardCountReportBlock) DestinationSSRC() []uint32 {
+	return []uint32{b.SSRC}
+}
+
+// Marshal encodes the block in binary
+func (b DiscardCountReportBlock) Marshal() ([]byte, error) {
+	if b.IntervalMetric > intervalMetricMask {
+		return nil, errBadIntervalMetric
+	}
+	if b.DiscardType > discardTypeMask {
+		return nil, errBadDiscardType
+	}
+
+	rawBlock := make([]byte, discardCountBlockLength)
+	XRHeader{
+		BlockType:    BlockTypeDiscardCount,
+		TypeSpecific: uint8(b.IntervalMetric)<<intervalMetricShift | uint8(b.DiscardType)<<discardTypeShift,
+		BlockLength:  discardCountBlockLength/4 - 1,
+	}.marshalTo(rawBlock)
+	binary.BigEndian.PutUint32(rawBlock[4:], b.SSRC)
+	binary.BigEndian.PutUint32(rawBlock[8:], b.Discarded)
+	return rawBlock, nil
+}
+
+// Unmarshal decodes the block from binary
+func (b *DiscardCountReportBlock) Unmarshal(rawBlock []byte) error {
+	if len(rawBlock) != discardCountBlockLength {
+		return errBadBlockLength
+	}
+	h, err := unmarshalXRHeader(rawBlock, BlockTypeDiscardCount)
+	if err != nil {
+		return err
+	}
+	b.IntervalMetric = IntervalMetric(h.TypeSpecific >> intervalMetricShift & intervalMetricMask)
+	b.DiscardType = DiscardType(h.TypeSpecific >> discardTypeShift & discardTypeMask)
+	b.SSRC = binary.BigEndian.Uint32(rawBlock[4:])
+	b.Discarded = binary.BigEndian.Uint32(rawBlock[8:])
+	return nil
+}
+
+// An RLEChunk is a chunk of the run length encoding used by the RLE report
+// blocks of RFC 3611, 4.1. A chunk is either a run of packets that all
+// were or all weren't reported on, or a vector of 15 flags, one per packet.
+// The null chunk, zero, terminates the list.
+type RLEChunk uint16
+
+const (
+	rleVectorBit    = 1 << 15
+	rleRunTypeBit   = 1 << 14
+	rleMaxRunLength = 1<<14 - 1
+	rleVectorLength = 15
+)
+
+// Len returns the number of packets the chunk describes.
+func (c RLEChunk) Len() int {
+	if c&rleVectorBit != 0 {
+		return rleVectorLength
+	}
+	return int(c & rleMaxRunLength)
+}
+
+// At reports whether the chunk flags its i-th packet, for i below Len.
+func (c RLEChunk) At(i int) bool {
+	if c&rleVectorBit != 0 {
+		return c>>(rleVectorLength-1-uint(i))&1 == 1
+	}
+	return c&rleRunTypeBit != 0
+}
+
+// EncodeRLEChunks run length encodes a list of per packet flags. Runs of 15
+// or more packets become run length chunks, others bit vectors, with the
+// last one padded with unset flags.
+func EncodeRLEChunks(flags []bool) []RLEChunk {
+	var out []RLEChunk
+	for i := 0; i < len(flags); {
+		run := 1
+		for i+run < len(flags) && flags[i+run] == flags[i] && run < rleMaxRunLength {
+			run++
+		}
+		if run >= rleVectorLength {
+			c := RLEChunk(run)
+			if flags[i] {
+				c |= rleRunTypeBit
+			}
+			out = append(out, c)
+			i += run
+			continue
+		}
+
+		c := RLEChunk(rleVectorBit)
+		for j := 0; j < rleVectorLength && i < len(flags); j++ {
+			if flags[i] {
+				c |= 1 << (rleVectorLength - 1 - uint(j))
+			}
+			i++
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// A DiscardRLEReportBlock reports which packets of a sequence number range
+// were discarded, either for arriving too late or, if Early is set, too
+// early. See RFC 7097
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|     BT=25     |rsvd |E|   T   |         block length          |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|                        SSRC of source                         |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|          begin_seq            |             end_seq           |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|          chunk 1              |             chunk 2           |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	:                              ...                              :
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+type DiscardRLEReportBlock struct {
+	// Whether the packets were discarded for arriving early rather than
+	// late
+	Early bool
+	// Only every 2^Thinning-th packet is reported on, at most 15
+	Thinning uint8
+	// SSRC of the media source the packets were discarded from
+	SSRC uint32
+	// The range of sequence numbers reported on, end exclusive
+	BeginSeq uint16
+	EndSeq   uint16
+	// The chunks flagging discarded packets, without the terminating null
+	// chunk
+	Chunks []RLEChunk
+}
+
+var _ ReportBlock = (*DiscardRLEReportBlock)(nil) // assert is a ReportBlock
+
+const (
+	discardRLEHeaderLength = 12
+	rleEarlyBit            = 1 << 4
+	rleThinningMask        = 0xf
+)
+
+// BlockType returns BlockTypeDiscardRLE.
+func (b *DiscardRLEReportBlock) BlockType() BlockType {
+	return BlockTypeDiscardRLE
+}
+
+// DestinationSSRC returns the SSRC of the media source.
+func (b *DiscardRLEReportBlock) DestinationSSRC() []uint32 {
+	return []uint32{b.SSRC}
+}
+
+// Discarded returns the sequence numbers of the packets flagged as
+// discarded.
+func (b *DiscardRLEReportBlock) Discarded() []uint16 {
+	var out []uint16
+	step := uint16(1) << b.Thinning
+	seq := b.BeginSeq
+	for _, c := range b.Chunks {
+		for i := 0; i < c.Len(); i++ {
+			if seq == b.EndSeq {
+				return out
+			}
+			if c.At(i) {
+				out = append(out, seq)
+			}
+			seq += step
+		}
+	}
+	return out
+}
+
+// Marshal encodes the block in binary
+func (b DiscardRLEReportBlock) Marshal() ([]byte, error) {
+	if b.Thinning > rleThinningMask {
+		return nil, errBadThinning
+	}
+
+	n := len(b.Chunks)
+	// terminated by a null chunk if an odd number of chunks leaves room
+	if n%2 == 1 {
+		n++
+	}
+	rawBlock := make([]byte, discardRLEHeaderLength+2*n)
+	if len(rawBlock)/4-1 > 0xffff {
+		return nil, errPacketTooLong
+	}
+
+	typeSpecific := b.Thinning
+	if b.Early {
+		typeSpecific |= rleEarlyBit
+	}
+	XRHeader{
+		BlockType:    BlockTypeDiscardRLE,
+		TypeSpecific: typeSpecific,
+		BlockLength:  uint16(len(rawBlock)/4 - 1),
+	}.marshalTo(rawBlock)
+	binary.BigEndian.PutUint32(rawBlock[4:], b.SSRC)
+	binary.BigEndian.PutUint16(rawBlock[8:], b.BeginSeq)
+	binary.BigEndian.PutUint16(rawBlock[10:], b.EndSeq)
+	for i, c := range b.Chunks {
+		binary.BigEndian.PutUint16(rawBlock[discardRLEHeaderLength+2*i:], uint16(c))
+	}
+	return rawBlock, nil
+}
+
+// Unmarshal decodes the block from binary
+func (b *DiscardRLEReportBlock) Unmarshal(rawBlock []byte) error {
+	if len(rawBlock) < discardRLEHeaderLength {
+		return errBadBlockLength
+	}
+	h, err := unmarshalXRHeader(rawBlock, BlockTypeDiscardRLE)
+	if err != nil {
+		return err
+	}
+	b.Early = h.TypeSpecific&rleEarlyBit != 0
+	b.Thinning = h.TypeSpecific & rleThinningMask
+	b.SSRC = binary.BigEndian.Uint32(rawBlock[4:])
+	b.BeginSeq = binary.BigEndian.Uint16(rawBlock[8:])
+	b.EndSeq = binary.BigEndian.Uint16(rawBlock[10:])
+
+	b.Chunks = b.Chunks[:0]
+	for pos := discardRLEHeaderLength; pos < len(rawBlock); pos += 2 {
+		c := RLEChunk(binary.BigEndian.Uint16(rawBlock[pos:]))
+		if c == 0 {
+			break
+		}
+		b.Chunks = append(b.Chunks, c)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package rtcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiscardCountReportBlock(t *testing.T) {
+	data := []byte{
+		// BT=24, I=interval, DT=overflow, block length=2
+		0x18, 0x50, 0x00, 0x02,
+		// ssrc=0x902f9e2e
+		0x90, 0x2f, 0x9e, 0x2e,
+		// 1234 packets discarded
+		0x00, 0x00, 0x04, 0xd2,
+	}
+	want := DiscardCountReportBlock{
+		IntervalMetric: IntervalMetricInterval,
+		DiscardType:    DiscardTypeOverflow,
+		SSRC:           0x902f9e2e,
+		Discarded:      1234,
+	}
+
+	var b DiscardCountReportBlock
+	if err := b.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(b, want) {
+		t.Fatalf("Unmarshal: got %#v, want %#v", b, want)
+	}
+
+	got, err := b.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !reflect.DeepEqual(got, data) {
+		t.Fatalf("Marshal: got %x, want %x", got, data)
+	}
+
+	if err := b.Unmarshal(data[:8]); err != errBadBlockLength {
+		t.Fatalf("Unmarshal short: err = %v, want %v", err, errBadBlockLength)
+	}
+	b.DiscardType = 4
+	if _, err := b.Marshal(); err != errBadDiscardType {
+		t.Fatalf("Marshal bad discard type: err = %v, want %v", err, errBadDiscardType)
+	}
+}
+
+func TestEncodeRLEChunks(t *testing.T) {
+	for _, test := range []struct {
+		Name  string
+		Flags []bool
+		Want  []RLEChunk
+	}{
+		{
+			Name: "empty",
+		},
+		{
+			Name:  "short vector",
+			Flags: []bool{true, false, true},
+			Want:  []RLEChunk{0xd000},
+		},
+		{
+			Name:  "run then vector",
+			Flags: append(make([]bool, 20), true, true),
+			Want:  []RLEChunk{0x0014, 0xe000},
+		},
+		{
+			Name: "run of discards",
+			Flags: []bool{
+				true, true, true, true, true, true, true, true,
+				true, true, true, true, true, true, true, true,
+			},
+			Want: []RLEChunk{0x4010},
+		},
+	} {
+		got := EncodeRLEChunks(test.Flags)
+		if !reflect.DeepEqual(got, test.Want) {
+			t.Fatalf("EncodeRLEChunks %q: got %x, want %x", test.Name, got, test.Want)
+		}
+
+		var decoded []bool
+		for _, c := range got {
+			for i := 0; i < c.Len(); i++ {
+				decoded = append(decoded, c.At(i))
+			}
+		}
+		if len(decoded) < len(test.Flags) || !reflect.DeepEqual(decoded[:len(test.Flags)], test.Flags) {
+			t.Fatalf("EncodeRLEChunks %q: decodes to %v, want %v", test.Name, decoded, test.Flags)
+		}
+	}
+}
+
+func TestDiscardRLEReportBlock(t *testing.T) {
+	data := []byte{
+		// BT=25, E=1, T=1, block length=3
+		0x19, 0x11, 0x00, 0x03,
+		// ssrc=0x902f9e2e
+		0x90, 0x2f, 0x9e, 0x2e,
+		// begin_seq=100, end_seq=140
+		0x00, 0x64, 0x00, 0x8c,
+		// vector with the first and third flags set, null chunk
+		0xd0, 0x00, 0x00, 0x00,
+	}
+	want := DiscardRLEReportBlock{
+		Early:    true,
+		Thinning: 1,
+		SSRC:     0x902f9e2e,
+		BeginSeq: 100,
+		EndSeq:   140,
+		Chunks:   []RLEChunk{0xd000},
+	}
+
+	var b DiscardRLEReportBlock
+	if err := b.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(b, want) {
+		t.Fatalf("Unmarshal: got %#v, want %#v", b, want)
+	}
+	if got, want := b.Discarded(), []uint16{100, 104}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Discarded: got %v, want %v", got, want)
+	}
+
+	got, err := b.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !reflect.DeepEqual(got, data) {
+		t.Fatalf("Marshal: got %x, want %x", got, data)
+	}
+
+	b.Thinning = 16
+	if _, err := b.Marshal(); err != errBadThinning {
+		t.Fatalf("Marshal bad thinning: err = %v, want %v", err, errBadThinning)
+	}
+}
+
+func TestDiscardRLEReportBlockEndSeq(t *testing.T) {
+	b := DiscardRLEReportBlock{
+		BeginSeq: 0xfffe,
+		EndSeq:   2,
+		Chunks:   []RLEChunk{0x4020},
+	}
+	if got, want := b.Discarded(), []uint16{0xfffe, 0xffff, 0, 1}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Discarded: got %v, want %v", got, want)
+	}
+}
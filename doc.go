@@ -35,5 +35,11 @@ Encoding RTCP packets:
 	pliData, err := pkt.Marshal()
 	// ...
 
+Building with the rtcp_nodebug tag compiles out the consistency checks of
+Marshal and the debug level logging of the stateful types, for production
+servers where the cost per packet matters:
+
+	go build -tags rtcp_nodebug
+
 */
 package rtcp
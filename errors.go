@@ -16,6 +16,38 @@ var (
 	errWrongType         = errors.New("rtcp: wrong packet type")
 	errSDESTextTooLong   = errors.New("rtcp: sdes must be < 255 octets long")
 	errSDESMissingType   = errors.New("rtcp: sdes item missing type")
+	errSDESChunkTooLarge = errors.New("rtcp: sdes chunk exceeds the packet size limit")
+	errSDESBadPrivate    = errors.New("rtcp: sdes item isn't a well-formed priv item")
 	errReasonTooLong     = errors.New("rtcp: reason must be < 255 octets long")
+	errReasonNotUTF8     = errors.New("rtcp: reason is not valid UTF-8")
+	errReasonTruncated   = errors.New("rtcp: reason truncated to 255 octets")
 	errBadVersion        = errors.New("rtcp: invalid packet version")
+	errBadPacketType     = errors.New("rtcp: packet type outside of the RTCP range")
+	errBadPadding        = errors.New("rtcp: invalid padding")
+	errAppNameLength     = errors.New("rtcp: application defined name must be 4 octets")
+	errStatusOutOfOrder  = errors.New("rtcp: packet status must follow the previous one")
+	errTooManyStatuses   = errors.New("rtcp: too many packet statuses")
+	errPacketTooLong     = errors.New("rtcp: packet exceeds the maximum length")
+	errBadBlockLength    = errors.New("rtcp: invalid report block length")
+	errBadBlockType      = errors.New("rtcp: xr block types 0 and 255 are reserved")
+	errBadIntervalMetric = errors.New("rtcp: interval metric must be at most 3")
+	errBadDiscardType    = errors.New("rtcp: discard type must be at most 3")
+	errBadThinning       = errors.New("rtcp: thinning must be at most 15")
+	errBadPayloadLength  = errors.New("rtcp: payload must be a multiple of 4 octets")
+	errBadFormat         = errors.New("rtcp: format must be at most 31")
+	errBadFilter         = errors.New("rtcp: invalid filter")
+	errBadRTCPFeedback   = errors.New("rtcp: invalid rtcp-fb attribute")
+	errNoReportSources   = errors.New("rtcp: no report sources")
+	errBadExtension      = errors.New("rtcp: invalid RTP header extension length")
+	errLengthOffByOne    = errors.New("rtcp: packet length off by one word")
+	errTransportClosed   = errors.New("rtcp: transport closed")
+	errTooManyElements   = errors.New("rtcp: packet announces too many elements")
+	errRoundTrip         = errors.New("rtcp: packet changed marshaling it again")
+	errBadArrivalOffset  = errors.New("rtcp: arrival time offset must be at most 0x1fff")
+	errBadMarshaledLen   = errors.New("rtcp: marshaled packet disagrees with its length field")
+	errBadMarshaledCount = errors.New("rtcp: marshaled packet is too short for its count field")
+	errShortStatusList   = errors.New("rtcp: packet status chunks don't cover the packet status count")
+	errDeltaMismatch     = errors.New("rtcp: receive deltas don't match the packet statuses")
+	errNoReportBlocks    = errors.New("rtcp: feedback has no report blocks")
+	errMixedSSRCs        = errors.New("rtcp: report blocks are for more than one SSRC")
 )
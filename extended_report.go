@@ -0,0 +1,301 @@
+package rtcp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// The ExtendedReport (XR) packet carries report blocks with metrics beyond
+// those of sender and receiver reports. See RFC 3611
+type ExtendedReport struct {
+	// SSRC of the sender of this packet
+	SenderSSRC uint32
+	// The report blocks, in the order they appear in the packet
+	Reports []ReportBlock
+}
+
+var _ Packet = (*ExtendedReport)(nil) // assert is a Packet
+
+// BlockType identifies the type of an XR report block. See
+// https://www.iana.org/assignments/rtcp-xr-block-types/rtcp-xr-block-types.xhtml
+type BlockType uint8
+
+// XR report block types implemented by this package
+const (
+	BlockTypeMeasurementInformation BlockType = 14 // RFC 6776
+	BlockTypeJitterBuffer           BlockType = 23 // RFC 7005
+	BlockTypeDiscardCount           BlockType = 24 // RFC 7002
+	BlockTypeDiscardRLE             BlockType = 25 // RFC 7097
+)
+
+func (t BlockType) String() string {
+	switch t {
+	case BlockTypeMeasurementInformation:
+		return "MeasurementInformation"
+	case BlockTypeJitterBuffer:
+		return "JitterBuffer"
+	case BlockTypeDiscardCount:
+		return "DiscardCount"
+	case BlockTypeDiscardRLE:
+		return "DiscardRLE"
+	default:
+		return fmt.Sprintf("BlockType(%d)", uint8(t))
+	}
+}
+
+// IntervalMetric tells which period a metric block reports on. See RFC
+// 6792, 6.2
+type IntervalMetric uint8
+
+// IntervalMetric values
+const (
+	// Since the previous report
+	IntervalMetricInterval IntervalMetric = 1
+	// Since the start of the measurement, as described by a
+	// MeasurementInformationReportBlock
+	IntervalMetricCumulative IntervalMetric = 2
+	// A sample taken when the report was generated
+	IntervalMetricSampled IntervalMetric = 3
+)
+
+// A ReportBlock is a block of an ExtendedReport. Marshal and Unmarshal
+// include the four byte block header.
+type ReportBlock interface {
+	// BlockType returns the type of the block.
+	BlockType() BlockType
+	// DestinationSSRC returns the SSRCs the block reports on.
+	DestinationSSRC() []uint32
+	Marshal() ([]byte, error)
+	Unmarshal(rawBlock []byte) error
+}
+
+const (
+	xrHeaderLength      = headerLength + ssrcLength
+	xrBlockHeaderLength = 4
+)
+
+// An XRHeader is the header shared by all XR report blocks. See RFC 3611, 3
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|      BT       | type-specific |         block length          |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+type XRHeader struct {
+	BlockType    BlockType
+	TypeSpecific uint8
+	// The length of the block in 32-bit words minus one, including the
+	// header
+	BlockLength uint16
+}
+
+// marshalTo encodes the header into the start of b
+func (h XRHeader) marshalTo(b []byte) {
+	b[0] = byte(h.BlockType)
+	b[1] = h.TypeSpecific
+	binary.BigEndian.PutUint16(b[2:], h.BlockLength)
+}
+
+// unmarshalXRHeader decodes the block header of rawBlock and checks that it
+// has the given type and covers all of rawBlock
+func unmarshalXRHeader(rawBlock []byte, typ BlockType) (XRHeader, error) {
+	if len(rawBlock) < xrBlockHeaderLength {
+		return XRHeader{}, errPacketTooShort
+	}
+	h := XRHeader{
+		BlockType:    BlockType(rawBlock[0]),
+		TypeSpecific: rawBlock[1],
+		BlockLength:  binary.BigEndian.Uint16(rawBlock[2:]),
+	}
+	if h.BlockType != typ {
+		return h, errWrongType
+	}
+	if (int(h.BlockLength)+1)*4 != len(rawBlock) {
+		return h, errBadBlockLength
+	}
+	return h, nil
+}
+
+// An UnknownReportBlock holds an XR report block of a type this package
+// doesn't decode.
+type UnknownReportBlock struct {
+	Type         BlockType
+	TypeSpecific uint8
+	// The block contents after the header, a multiple of 4 bytes
+	Data []byte
+}
+
+var _ ReportBlock = (*UnknownReportBlock)(nil) // assert is a ReportBlock
+
+// BlockType returns the type of the block.
+func (b *UnknownReportBlock) BlockType() BlockType {
+	return b.Type
+}
+
+// DestinationSSRC returns nil, as the contents of the block are unknown.
+func (b *UnknownReportBlock) DestinationSSRC() []uint32 {
+	return nil
+}
+
+// Marshal encodes the block in binary
+func (b UnknownReportBlock) Marshal() ([]byte, error) {
+	if len(b.Data)%4 != 0 {
+		return nil, errBadBlockLength
+	}
+	rawBlock := make([]byte, xrBlockHeaderLength+len(b.Data))
+	XRHeader{
+		BlockType:    b.Type,
+		TypeSpecific: b.TypeSpecific,
+		BlockLength:  uint16(len(rawBlock)/4 - 1),
+	}.marshalTo(rawBlock)
+	copy(rawBlock[xrBlockHeaderLength:], b.Data)
+	return rawBlock, nil
+}
+
+// Unmarshal decodes the block from binary
+func (b *UnknownReportBlock) Unmarshal(rawBlock []byte) error {
+	if len(rawBlock) < xrBlockHeaderLength {
+		return errPacketTooShort
+	}
+	h, err := unmarshalXRHeader(rawBlock, BlockType(rawBlock[0]))
+	if err != nil {
+		return err
+	}
+	b.Type = h.BlockType
+	b.TypeSpecific = h.TypeSpecific
+	b.Data = rawBlock[xrBlockHeaderLength:]
+	return nil
+}
+
+// newReportBlock returns an empty report block of the given type
+func newReportBlock(typ BlockType) ReportBlock {
+	switch typ {
+	case BlockTypeMeasurementInformation:
+		return new(MeasurementInformationReportBlock)
+	case BlockTypeJitterBuffer:
+		return new(JitterBufferReportBlock)
+	case BlockTypeDiscardCount:
+		return new(DiscardCountReportBlock)
+	case BlockTypeDiscardRLE:
+		return new(DiscardRLEReportBlock)
+	default:
+		return new(UnknownReportBlock)
+	}
+}
+
+// Marshal encodes the ExtendedReport in binary
+func (x ExtendedReport) Marshal() ([]byte, error) {
+	/*
+	 *  0                   1                   2                   3
+	 *  0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |V=2|P|reserved |   PT=XR=207   |             length            |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |                              SSRC                             |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * :                         report blocks                         :
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 */
+	rawPacket := make([]byte, xrHeaderLength)
+	binary.BigEndian.PutUint32(rawPacket[headerLength:], x.SenderSSRC)
+	for _, block := range x.Reports {
+		data, err := block.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		rawPacket = append(rawPacket, data...)
+	}
+
+	if len(rawPacket)/4-1 > 0xffff {
+		return nil, errPacketTooLong
+	}
+	h := Header{
+		Type:   TypeExtendedReport,
+		Length: uint16(len(rawPacket)/4 - 1),
+	}
+	hData, err := h.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	copy(rawPacket, hData)
+	return rawPacket, nil
+}
+
+// Unmarshal decodes the ExtendedReport from binary. Blocks of types this
+// package doesn't decode, and no decoder was registered for with
+// RegisterReportBlock, are returned as UnknownReportBlocks.
+func (x *ExtendedReport) Unmarshal(rawPacket []byte) error {
+	var h Header
+	if err := h.Unmarshal(rawPacket); err != nil {
+		return err
+	}
+	if h.Type != TypeExtendedReport {
+		return errWrongType
+	}
+
+	end := h.size()
+	if end < xrHeaderLength || end > len(rawPacket) {
+		return errPacketTooShort
+	}
+	if h.Padding {
+		padding := int(rawPacket[end-1])
+		if padding == 0 || padding%4 != 0 || xrHeaderLength+padding > end {
+			return errBadPadding
+		}
+		end -= padding
+	}
+
+	x.SenderSSRC = binary.BigEndian.Uint32(rawPacket[headerLength:])
+	x.Reports = x.Reports[:0]
+	for pos := xrHeaderLength; pos < end; {
+		if pos+xrBlockHeaderLength > end {
+			return errPacketTooShort
+		}
+		blockEnd := pos + (int(binary.BigEndian.Uint16(rawPacket[pos+2:]))+1)*4
+		if blockEnd > end {
+			return errPacketTooShort
+		}
+
+		block, ok := unmarshalRegisteredReportBlock(rawPacket[pos:blockEnd])
+		if !ok {
+			block = newReportBlock(BlockType(rawPacket[pos]))
+			if err := block.Unmarshal(rawPacket[pos:blockEnd]); err != nil {
+				return err
+			}
+		}
+		x.Reports = append(x.Reports, block)
+		pos = blockEnd
+	}
+	return nil
+}
+
+// Header returns the Header associated with this packet.
+func (x *ExtendedReport) Header() Header {
+	n := xrHeaderLength
+	for _, block := range x.Reports {
+		if data, err := block.Marshal(); err == nil {
+			n += len(data)
+		}
+	}
+	return Header{
+		Type:   TypeExtendedReport,
+		Length: uint16(n/4 - 1),
+	}
+}
+
+// DestinationSSRC returns the SSRCs the report blocks report on.
+func (x *ExtendedReport) DestinationSSRC() []uint32 {
+	var out []uint32
+	for _, block := range x.Reports {
+		out = append(out, block.DestinationSSRC()...)
+	}
+	return out
+}
+
+func (x ExtendedReport) String() string {
+	out := fmt.Sprintf("ExtendedReport from %x\n", x.SenderSSRC)
+	for _, block := range x.Reports {
+		out += fmt.Sprintf("\t%v %+v\n", block.BlockType(), block)
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+package rtcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtendedReportUnmarshal(t *testing.T) {
+	for _, test := range []struct {
+		Name      string
+		Data      []byte
+		Want      ExtendedReport
+		WantError error
+	}{
+		{
+			Name: "unknown block",
+			Data: []byte{
+				// v=2, p=0, XR, len=3
+				0x80, 0xcf, 0x00, 0x03,
+				// ssrc=0x902f9e2e
+				0x90, 0x2f, 0x9e, 0x2e,
+				// BT=99, type specific=7, block length=1
+				0x63, 0x07, 0x00, 0x01,
+				0x01, 0x02, 0x03, 0x04,
+			},
+			Want: ExtendedReport{
+				SenderSSRC: 0x902f9e2e,
+				Reports: []ReportBlock{&UnknownReportBlock{
+					Type:         99,
+					TypeSpecific: 7,
+					Data:         []byte{0x01, 0x02, 0x03, 0x04},
+				}},
+			},
+		},
+		{
+			Name: "no blocks",
+			Data: []byte{
+				0x80, 0xcf, 0x00, 0x01,
+				0x90, 0x2f, 0x9e, 0x2e,
+			},
+			Want: ExtendedReport{SenderSSRC: 0x902f9e2e},
+		},
+		{
+			Name: "block overruns packet",
+			Data: []byte{
+				0x80, 0xcf, 0x00, 0x02,
+				0x90, 0x2f, 0x9e, 0x2e,
+				0x63, 0x07, 0x00, 0x01,
+			},
+			WantError: errPacketTooShort,
+		},
+		{
+			Name: "measurement information block too short",
+			Data: []byte{
+				0x80, 0xcf, 0x00, 0x02,
+				0x90, 0x2f, 0x9e, 0x2e,
+				0x0e, 0x00, 0x00, 0x00,
+			},
+			WantError: errBadBlockLength,
+		},
+		{
+			Name: "wrong type",
+			Data: []byte{
+				0x80, 0xc9, 0x00, 0x01,
+				0x90, 0x2f, 0x9e, 0x2e,
+			},
+			WantError: errWrongType,
+		},
+		{
+			Name:      "packet too short",
+			Data:      []byte{0x80, 0xcf, 0x00, 0x00},
+			WantError: errPacketTooShort,
+		},
+	} {
+		var xr ExtendedReport
+		err := xr.Unmarshal(test.Data)
+		if got, want := err, test.WantError; got != want {
+			t.Fatalf("Unmarshal %q: err = %v, want %v", test.Name, got, want)
+		}
+		if err != nil {
+			continue
+		}
+		if got, want := xr, test.Want; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Unmarshal %q: got %#v, want %#v", test.Name, got, want)
+		}
+	}
+}
+
+func TestExtendedReportRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		Name   string
+		Report ExtendedReport
+	}{
+		{
+			Name: "measurement information",
+			Report: ExtendedReport{
+				SenderSSRC: 1,
+				Reports: []ReportBlock{
+					&MeasurementInformationReportBlock{
+						SSRC:                  2,
+						FirstSequenceNumber:   100,
+						IntervalFirstSequence: 0x10064,
+						IntervalLastSequence:  0x10200,
+						Interval:              5 << 16,
+						Cumulative:            60 << 32,
+					},
+					&UnknownReportBlock{Type: 99, Data: []byte{1, 2, 3, 4}},
+				},
+			},
+		},
+	} {
+		data, err := test.Report.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal %q: %v", test.Name, err)
+		}
+
+		var decoded ExtendedReport
+		if err := decoded.Unmarshal(data); err != nil {
+			t.Fatalf("Unmarshal %q: %v", test.Name, err)
+		}
+		if got, want := decoded, test.Report; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%q round trip: got %#v, want %#v", test.Name, got, want)
+		}
+		if got, want := int(decoded.Header().Length+1)*4, len(data); got != want {
+			t.Fatalf("%q Header length: got %d, want %d", test.Name, got, want)
+		}
+
+		packets, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Unmarshal %q packets: %v", test.Name, err)
+		}
+		if _, ok := packets[0].(*ExtendedReport); !ok {
+			t.Fatalf("Unmarshal %q: got %T, want *ExtendedReport", test.Name, packets[0])
+		}
+	}
+}
+
+func TestExtendedReportMarshalBadBlock(t *testing.T) {
+	xr := ExtendedReport{Reports: []ReportBlock{&UnknownReportBlock{Data: []byte{1}}}}
+	if _, err := xr.Marshal(); err != errBadBlockLength {
+		t.Fatalf("Marshal: err = %v, want %v", err, errBadBlockLength)
+	}
+}
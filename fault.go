@@ -0,0 +1,163 @@
+package rtcp
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// A FaultInjector impairs marshaled RTCP datagrams the way unreliable
+// networks and buggy middleboxes do, for testing the robustness of
+// receivers. Every decision is drawn from a random source seeded by the
+// seed, so a failure can be reproduced.
+//
+// A FaultInjector isn't safe for concurrent use.
+type FaultInjector struct {
+	// Probability of flipping a random bit of a datagram
+	Corrupt float64
+	// Probability of cutting a datagram short at a random length
+	Truncate float64
+	// Probability of delivering a datagram twice
+	Duplicate float64
+	// Probability of holding a datagram back until after the next one
+	Reorder float64
+
+	rand *rand.Rand
+	held []byte
+}
+
+// NewFaultInjector creates a FaultInjector that doesn't impair anything
+// yet, whose random decisions are seeded by seed.
+func NewFaultInjector(seed int64) *FaultInjector {
+	return &FaultInjector{rand: rand.New(rand.NewSource(seed))} // nolint:gosec
+}
+
+// Impair returns the datagrams to deliver in place of datagram: none if it
+// is held back to be reordered, two if it is duplicated or a held datagram
+// follows it. datagram itself isn't modified.
+func (f *FaultInjector) Impair(datagram []byte) [][]byte {
+	if f.rand == nil {
+		f.rand = rand.New(rand.NewSource(0)) // nolint:gosec
+	}
+
+	// draw every random number for every datagram, so changing one
+	// probability doesn't shift the decisions of the others
+	corrupt, truncate, duplicate, reorder := f.rand.Float64(), f.rand.Float64(), f.rand.Float64(), f.rand.Float64()
+	bit, length := f.rand.Intn(8*len(datagram)+1), f.rand.Intn(len(datagram)+1)
+
+	out := append([]byte(nil), datagram...)
+	if corrupt < f.Corrupt && len(out) > 0 {
+		out[bit/8%len(out)] ^= 1 << uint(bit%8)
+	}
+	if truncate < f.Truncate {
+		out = out[:length]
+	}
+
+	if reorder < f.Reorder && f.held == nil {
+		f.held = out
+		return nil
+	}
+	datagrams := [][]byte{out}
+	if duplicate < f.Duplicate {
+		datagrams = append(datagrams, append([]byte(nil), out...))
+	}
+	if f.held != nil {
+		datagrams = append(datagrams, f.held)
+		f.held = nil
+	}
+	return datagrams
+}
+
+// Flush returns the datagram held back for reordering, if any.
+func (f *FaultInjector) Flush() [][]byte {
+	if f.held == nil {
+		return nil
+	}
+	held := f.held
+	f.held = nil
+	return [][]byte{held}
+}
+
+// A FaultyTransport is an in-memory Transport delivering what is written to
+// it back to its reader through a FaultInjector. ReadRTCP returns the error
+// of unmarshaling an impaired datagram, so tests can check how components
+// fed by a Transport cope with them.
+//
+// A FaultyTransport is safe for concurrent use.
+type FaultyTransport struct {
+	mu       sync.Mutex
+	faults   *FaultInjector
+	queue    [][]byte
+	ready    chan struct{}
+	closed   bool
+	closedCh chan struct{}
+}
+
+var _ Transport = (*FaultyTransport)(nil) // assert is a Transport
+
+// NewFaultyTransport creates a FaultyTransport impairing datagrams with
+// faults.
+func NewFaultyTransport(faults *FaultInjector) *FaultyTransport {
+	return &FaultyTransport{
+		faults:   faults,
+		ready:    make(chan struct{}, 1),
+		closedCh: make(chan struct{}),
+	}
+}
+
+// WriteRTCP marshals pkts into a datagram and queues what the FaultInjector
+// makes of it.
+func (t *FaultyTransport) WriteRTCP(pkts []Packet) error {
+	data, err := Marshal(pkts)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return errTransportClosed
+	}
+	t.queue = append(t.queue, t.faults.Impair(data)...)
+	if len(t.queue) > 0 {
+		select {
+		case t.ready <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// ReadRTCP blocks until a datagram is queued and returns its packets, or the
+// error unmarshaling it.
+func (t *FaultyTransport) ReadRTCP() ([]Packet, error) {
+	for {
+		t.mu.Lock()
+		if t.closed {
+			t.mu.Unlock()
+			return nil, errTransportClosed
+		}
+		if len(t.queue) > 0 {
+			data := t.queue[0]
+			t.queue = t.queue[1:]
+			t.mu.Unlock()
+			return Unmarshal(data)
+		}
+		t.mu.Unlock()
+
+		select {
+		case <-t.ready:
+		case <-t.closedCh:
+		}
+	}
+}
+
+// Close closes the transport, unblocking a pending ReadRTCP.
+func (t *FaultyTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.closed {
+		t.closed = true
+		close(t.closedCh)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package rtcp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFaultInjector(t *testing.T) {
+	assert := assert.New(t)
+
+	datagram := []byte{0x80, 0xc9, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01}
+
+	f := NewFaultInjector(1)
+	assert.Equal([][]byte{datagram}, f.Impair(datagram))
+
+	f.Duplicate = 1
+	assert.Equal([][]byte{datagram, datagram}, f.Impair(datagram))
+
+	// a reordered datagram follows the next one
+	f.Duplicate, f.Reorder = 0, 1
+	first := []byte{0x80, 0xc9, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02}
+	assert.Empty(f.Impair(first))
+	assert.Equal([][]byte{datagram, first}, f.Impair(datagram))
+	assert.Empty(f.Impair(first))
+	assert.Equal([][]byte{first}, f.Flush())
+	assert.Empty(f.Flush())
+
+	f.Reorder, f.Corrupt = 0, 1
+	corrupted := f.Impair(datagram)
+	assert.Len(corrupted, 1)
+	assert.NotEqual(datagram, corrupted[0])
+	assert.Len(corrupted[0], len(datagram))
+	assert.Equal([]byte{0x80, 0xc9, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01}, datagram)
+
+	f.Corrupt, f.Truncate = 0, 1
+	for i := 0; i < 10; i++ {
+		assert.True(len(f.Impair(datagram)[0]) <= len(datagram))
+	}
+
+	// the same seed makes the same decisions
+	a, b := NewFaultInjector(7), NewFaultInjector(7)
+	a.Corrupt, b.Corrupt = 0.5, 0.5
+	for i := 0; i < 100; i++ {
+		assert.Equal(a.Impair(datagram), b.Impair(datagram))
+	}
+}
+
+func TestFaultyTransport(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFaultInjector(1)
+	tr := NewFaultyTransport(f)
+	pkts := []Packet{&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}}
+	assert.NoError(tr.WriteRTCP(pkts))
+	got, err := tr.ReadRTCP()
+	assert.NoError(err)
+	assert.Equal(pkts, got)
+
+	f.Truncate = 1
+	for {
+		assert.NoError(tr.WriteRTCP(pkts))
+		if _, err = tr.ReadRTCP(); err != nil {
+			break
+		}
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := tr.ReadRTCP()
+		done <- err
+	}()
+	assert.NoError(tr.Close())
+	assert.Equal(errTransportClosed, <-done)
+	assert.Equal(errTransportClosed, tr.WriteRTCP(pkts))
+}
+
+// TestUnmarshalImpaired checks that impaired datagrams either decode or fail
+// with an error, and that a Decoder agrees with Unmarshal on them.
+func TestUnmarshalImpaired(t *testing.T) {
+	vectors, err := TestVectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFaultInjector(1)
+	f.Corrupt, f.Truncate = 0.5, 0.5
+	d := NewDecoder()
+	for i := 0; i < 200; i++ {
+		for _, v := range vectors {
+			for _, data := range f.Impair(v.Data) {
+				want, wantErr := Unmarshal(data)
+				got, err := d.Decode(data, nil)
+				if (err != nil) != (wantErr != nil) {
+					t.Fatalf("%s %x: Decode error %v, Unmarshal error %v", v.Name, data, err, wantErr)
+				}
+				if err == nil && !reflect.DeepEqual(typesOf(got), typesOf(want)) {
+					t.Fatalf("%s %x: Decode %v, Unmarshal %v", v.Name, data, typesOf(got), typesOf(want))
+				}
+			}
+		}
+	}
+}
+
+func typesOf(packets []Packet) []reflect.Type {
+	out := make([]reflect.Type, len(packets))
+	for i, p := range packets {
+		out[i] = reflect.TypeOf(p)
+	}
+	return out
+}
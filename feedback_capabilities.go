@@ -0,0 +1,167 @@
+package rtcp
+
+import "fmt"
+
+// FeedbackCapabilities lists the feedback messages an endpoint negotiated,
+// usually through the a=rtcp-fb attributes of SDP, so the same code can
+// serve endpoints that understand different sets of feedback. The zero
+// value allows no feedback messages at all.
+type FeedbackCapabilities struct {
+	// Generic NACK, "nack"
+	NACK bool
+	// Picture loss indications, "nack pli"
+	PLI bool
+	// Full intra requests, "ccm fir"
+	FIR bool
+	// Transport wide congestion control feedback, "transport-cc"
+	TransportCC bool
+	// RFC 8888 congestion control feedback, "ack ccfb"
+	CCFB bool
+	// Receiver estimated maximum bitrate, "goog-remb"
+	REMB bool
+}
+
+// AllFeedbackCapabilities allows every feedback message FeedbackCapabilities
+// knows about.
+var AllFeedbackCapabilities = FeedbackCapabilities{
+	NACK:        true,
+	PLI:         true,
+	FIR:         true,
+	TransportCC: true,
+	CCFB:        true,
+	REMB:        true,
+}
+
+// CongestionControlFeedback is a kind of feedback a receiver sends for the
+// sender's bandwidth estimation.
+type CongestionControlFeedback int
+
+const (
+	// CongestionControlNone means no congestion control feedback was
+	// negotiated.
+	CongestionControlNone CongestionControlFeedback = iota
+	// CongestionControlTransportCC is TransportLayerCC feedback, as built by
+	// Recorder.
+	CongestionControlTransportCC
+	// CongestionControlCCFB is RFC 8888 congestion control feedback.
+	CongestionControlCCFB
+	// CongestionControlREMB is a ReceiverEstimatedMaximumBitrate computed by
+	// the receiver.
+	CongestionControlREMB
+)
+
+func (c CongestionControlFeedback) String() string {
+	switch c {
+	case CongestionControlNone:
+		return "none"
+	case CongestionControlTransportCC:
+		return "transport-cc"
+	case CongestionControlCCFB:
+		return "ccfb"
+	case CongestionControlREMB:
+		return "goog-remb"
+	default:
+		return fmt.Sprintf("CongestionControlFeedback(%d)", int(c))
+	}
+}
+
+// CongestionControl returns the congestion control feedback to send. When
+// several were negotiated, transport wide feedback is preferred, as it is
+// what Recorder builds, then RFC 8888 feedback, then REMB, which leaves
+// the estimation to the receiver.
+func (c FeedbackCapabilities) CongestionControl() CongestionControlFeedback {
+	switch {
+	case c.TransportCC:
+		return CongestionControlTransportCC
+	case c.CCFB:
+		return CongestionControlCCFB
+	case c.REMB:
+		return CongestionControlREMB
+	default:
+		return CongestionControlNone
+	}
+}
+
+// Allows reports whether the endpoint negotiated the feedback message p.
+// Reports, source descriptions and other packets that aren't feedback
+// messages FeedbackCapabilities knows about are always allowed.
+func (c FeedbackCapabilities) Allows(p Packet) bool {
+	if compound, ok := p.(*CompoundPacket); ok {
+		for _, p := range *compound {
+			if !c.Allows(p) {
+				return false
+			}
+		}
+		return true
+	}
+
+	h := headerOf(p)
+	switch h.Type {
+	case TypeTransportSpecificFeedback:
+		switch h.Count {
+		case FormatTLN:
+			return c.NACK
+		case FormatTCC:
+			return c.TransportCC
+		case FormatCCFB:
+			return c.CCFB
+		}
+	case TypePayloadSpecificFeedback:
+		switch h.Count {
+		case FormatPLI:
+			return c.PLI
+		case FormatFIR:
+			return c.FIR
+		case FormatREMB:
+			return c.REMB
+		}
+	}
+	return true
+}
+
+// Filter returns the packets the endpoint negotiated, see Allows. The
+// result shares the storage of packets.
+func (c FeedbackCapabilities) Filter(packets []Packet) []Packet {
+	out := packets[:0:0]
+	for _, p := range packets {
+		if c.Allows(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// A KeyframeRequester builds requests for a keyframe in the form the
+// endpoint negotiated: a PictureLossIndication if it supports them, or else
+// a FullIntraRequest, with the sequence number RFC 5104 requires to be
+// incremented for every new request.
+//
+// A KeyframeRequester isn't safe for concurrent use.
+type KeyframeRequester struct {
+	// SSRC of the requests' sender
+	SenderSSRC   uint32
+	Capabilities FeedbackCapabilities
+
+	sequenceNumbers map[uint32]uint8
+}
+
+// Request returns a request for a keyframe from mediaSSRC, or nil if the
+// endpoint negotiated neither PLI nor FIR.
+func (k *KeyframeRequester) Request(mediaSSRC uint32) Packet {
+	switch {
+	case k.Capabilities.PLI:
+		return &PictureLossIndication{SenderSSRC: k.SenderSSRC, MediaSSRC: mediaSSRC}
+	case k.Capabilities.FIR:
+		if k.sequenceNumbers == nil {
+			k.sequenceNumbers = make(map[uint32]uint8)
+		}
+		seq := k.sequenceNumbers[mediaSSRC]
+		k.sequenceNumbers[mediaSSRC] = seq + 1
+		return &FullIntraRequest{
+			SenderSSRC: k.SenderSSRC,
+			FIR:        []FIREntry{{SSRC: mediaSSRC, SequenceNumber: seq}},
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,62 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedbackCapabilitiesCongestionControl(t *testing.T) {
+	for _, test := range []struct {
+		Capabilities FeedbackCapabilities
+		Want         CongestionControlFeedback
+	}{
+		{FeedbackCapabilities{}, CongestionControlNone},
+		{FeedbackCapabilities{REMB: true}, CongestionControlREMB},
+		{FeedbackCapabilities{REMB: true, CCFB: true}, CongestionControlCCFB},
+		{AllFeedbackCapabilities, CongestionControlTransportCC},
+	} {
+		assert.Equal(t, test.Want, test.Capabilities.CongestionControl(), "%+v", test.Capabilities)
+	}
+	assert.Equal(t, "transport-cc", CongestionControlTransportCC.String())
+	assert.Equal(t, "CongestionControlFeedback(9)", CongestionControlFeedback(9).String())
+}
+
+func TestFeedbackCapabilitiesAllows(t *testing.T) {
+	nack := &TransportLayerNack{Nacks: []NackPair{{PacketID: 1}}}
+	twcc := &TransportLayerCC{}
+	ccfb := &RawPacket{0x8b, 0xcd, 0x00, 0x02, 0, 0, 0, 1, 0, 0, 0, 2}
+	pli := &PictureLossIndication{}
+	fir := &FullIntraRequest{}
+	remb := &ReceiverEstimatedMaximumBitrate{}
+	rr := &ReceiverReport{}
+	rrr := &RapidResynchronizationRequest{}
+
+	none := FeedbackCapabilities{}
+	for _, p := range []Packet{nack, twcc, ccfb, pli, fir, remb} {
+		assert.False(t, none.Allows(p), "%T", p)
+		assert.True(t, AllFeedbackCapabilities.Allows(p), "%T", p)
+	}
+	// packets that aren't negotiated through capabilities
+	assert.True(t, none.Allows(rr))
+	assert.True(t, none.Allows(rrr))
+
+	c := FeedbackCapabilities{NACK: true, PLI: true, TransportCC: true}
+	assert.Equal(t, []Packet{rr, nack, twcc, pli}, c.Filter([]Packet{rr, nack, twcc, ccfb, pli, fir, remb}))
+	assert.True(t, c.Allows(&CompoundPacket{rr, nack}))
+	assert.False(t, c.Allows(&CompoundPacket{rr, remb}))
+}
+
+func TestKeyframeRequester(t *testing.T) {
+	k := &KeyframeRequester{SenderSSRC: 1}
+	assert.Nil(t, k.Request(2))
+
+	k.Capabilities.FIR = true
+	assert.Equal(t, &FullIntraRequest{SenderSSRC: 1, FIR: []FIREntry{{SSRC: 2, SequenceNumber: 0}}}, k.Request(2))
+	assert.Equal(t, &FullIntraRequest{SenderSSRC: 1, FIR: []FIREntry{{SSRC: 2, SequenceNumber: 1}}}, k.Request(2))
+	assert.Equal(t, &FullIntraRequest{SenderSSRC: 1, FIR: []FIREntry{{SSRC: 3, SequenceNumber: 0}}}, k.Request(3))
+
+	// PLI is preferred, as it doesn't need state
+	k.Capabilities.PLI = true
+	assert.Equal(t, &PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}, k.Request(2))
+}
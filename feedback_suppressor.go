@@ -0,0 +1,273 @@
+package rtcp
+
+import (
+	"math/rand"
+	"time"
+)
+
+// DefaultDitherFactor is the factor l of the maximum dithering interval
+// l*T_rr, see RFC 4585, 3.4
+const DefaultDitherFactor = 0.5
+
+// A feedbackEvent identifies the loss a piece of feedback reports: a lost
+// packet of a source for NACKs, or a picture loss of a source
+type feedbackEvent struct {
+	mediaSSRC uint32
+	pli       bool
+	seq       uint16
+}
+
+type pendingFeedback struct {
+	packet Packet
+	due    time.Time
+	events []feedbackEvent
+	// events reported by other members while the feedback waited
+	suppressed map[feedbackEvent]bool
+}
+
+// A FeedbackSuppressor delays early feedback by a random dithering interval
+// and drops it when another member of the session sends equivalent feedback
+// first, as receivers in multicast and multi-unicast topologies do according
+// to RFC 4585, 3.5. Feedback is equivalent when it reports the loss of the
+// same packets of the same source (TransportLayerNack) or a picture loss of
+// the same source (PictureLossIndication). Other feedback is dithered but
+// never suppressed.
+//
+// When the session has only two members the point-to-point rules apply:
+// feedback isn't dithered, and since nobody else could report it, isn't
+// suppressed either.
+//
+// A FeedbackSuppressor isn't safe for concurrent use; see
+// SyncFeedbackSuppressor.
+type FeedbackSuppressor struct {
+	// SSRC of the local participant. Feedback observed from it isn't taken
+	// for feedback of another member.
+	LocalSSRC uint32
+	// ReportInterval is the regular RTCP interval T_rr, usually
+	// Scheduler.DeterministicInterval. Feedback of other members is
+	// remembered for that long. If zero, DefaultMinInterval is used.
+	ReportInterval time.Duration
+	// DitherFactor is the factor l of the maximum dithering interval. If
+	// zero, DefaultDitherFactor is used.
+	DitherFactor float64
+	// Membership supplies the number of members. If nil the session is
+	// assumed to be point-to-point.
+	Membership MemberCounter
+	// Clock supplies the current time. If nil, SystemClock is used.
+	Clock Clock
+	// Source supplies the random numbers of the dithering intervals, see
+	// Scheduler.Source. If nil, the global source of math/rand is used.
+	Source rand.Source
+
+	pending []*pendingFeedback
+	// events reported by other members, and when
+	seen map[feedbackEvent]time.Time
+}
+
+// NewFeedbackSuppressor creates a FeedbackSuppressor for the local
+// participant ssrc whose dithering intervals are drawn from a random source
+// seeded by seed.
+func NewFeedbackSuppressor(ssrc uint32, seed int64) *FeedbackSuppressor {
+	return &FeedbackSuppressor{
+		LocalSSRC: ssrc,
+		Source:    rand.NewSource(seed),
+	}
+}
+
+func (f *FeedbackSuppressor) reportInterval() time.Duration {
+	if f.ReportInterval > 0 {
+		return f.ReportInterval
+	}
+	return DefaultMinInterval
+}
+
+// MaxDither returns the maximum dithering interval T_dither_max: zero in
+// point-to-point sessions, and DitherFactor times the report interval
+// otherwise.
+func (f *FeedbackSuppressor) MaxDither() time.Duration {
+	if f.Membership == nil || f.Membership.Members() <= 2 {
+		return 0
+	}
+	l := f.DitherFactor
+	if l <= 0 {
+		l = DefaultDitherFactor
+	}
+	return time.Duration(l * float64(f.reportInterval()))
+}
+
+// Schedule queues the feedback p of the local participant, and returns when
+// it is due to be sent. It returns false, and drops p, if another member
+// already sent equivalent feedback within the report interval. NACKs
+// reporting some packets already reported by others are queued without
+// them.
+func (f *FeedbackSuppressor) Schedule(p Packet) (time.Time, bool) {
+	now := clockNow(f.Clock)
+	f.expire(now)
+
+	events := feedbackEvents(p)
+	pf := &pendingFeedback{packet: p, events: events, suppressed: make(map[feedbackEvent]bool)}
+	for _, e := range events {
+		if _, ok := f.seen[e]; ok {
+			pf.suppressed[e] = true
+		}
+	}
+	if len(events) > 0 && len(pf.suppressed) == len(events) {
+		return time.Time{}, false
+	}
+
+	pf.due = now.Add(time.Duration(randFloat64(f.Source) * float64(f.MaxDither())))
+	f.pending = append(f.pending, pf)
+	return pf.due, true
+}
+
+// Observe records the feedback in p, which may be a CompoundPacket, received
+// from other members, and suppresses the queued feedback it's equivalent
+// to.
+func (f *FeedbackSuppressor) Observe(p Packet) {
+	if c, ok := p.(*CompoundPacket); ok {
+		for _, sub := range *c {
+			f.Observe(sub)
+		}
+		return
+	}
+	if ssrc, ok := packetSenderSSRC(p); ok && ssrc == f.LocalSSRC {
+		return
+	}
+	events := feedbackEvents(p)
+	if len(events) == 0 {
+		return
+	}
+
+	now := clockNow(f.Clock)
+	if f.seen == nil {
+		f.seen = make(map[feedbackEvent]time.Time)
+	}
+	for _, e := range events {
+		f.seen[e] = now
+	}
+	f.suppress(events)
+}
+
+// OnRepaired records that the packet sequenceNumber of the source ssrc was
+// recovered by FEC. It is dropped from the queued NACKs, and from those
+// scheduled within the report interval, as if another member had reported
+// its loss.
+func (f *FeedbackSuppressor) OnRepaired(ssrc uint32, sequenceNumber uint16) {
+	e := feedbackEvent{mediaSSRC: ssrc, seq: sequenceNumber}
+	if f.seen == nil {
+		f.seen = make(map[feedbackEvent]time.Time)
+	}
+	f.seen[e] = clockNow(f.Clock)
+	f.suppress([]feedbackEvent{e})
+}
+
+// suppress marks events in the queued feedback, and drops the feedback
+// left without events
+func (f *FeedbackSuppressor) suppress(events []feedbackEvent) {
+	kept := f.pending[:0]
+	for _, pf := range f.pending {
+		for _, e := range events {
+			for _, pe := range pf.events {
+				if e == pe {
+					pf.suppressed[e] = true
+				}
+			}
+		}
+		if len(pf.suppressed) < len(pf.events) {
+			kept = append(kept, pf)
+		}
+	}
+	for i := len(kept); i < len(f.pending); i++ {
+		f.pending[i] = nil
+	}
+	f.pending = kept
+}
+
+// Next returns when the earliest queued feedback is due, and false if none
+// is queued.
+func (f *FeedbackSuppressor) Next() (time.Time, bool) {
+	var next time.Time
+	for _, pf := range f.pending {
+		if next.IsZero() || pf.due.Before(next) {
+			next = pf.due
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// Due removes and returns the queued feedback that is due, in the order it
+// was scheduled. NACKs are returned without the packets other members
+// reported, or that were repaired, while they waited.
+func (f *FeedbackSuppressor) Due() []Packet {
+	now := clockNow(f.Clock)
+	var due []Packet
+	kept := f.pending[:0]
+	for _, pf := range f.pending {
+		if pf.due.After(now) {
+			kept = append(kept, pf)
+			continue
+		}
+		due = append(due, pf.remaining())
+	}
+	for i := len(kept); i < len(f.pending); i++ {
+		f.pending[i] = nil
+	}
+	f.pending = kept
+	return due
+}
+
+// Pending returns the number of queued feedback packets.
+func (f *FeedbackSuppressor) Pending() int {
+	return len(f.pending)
+}
+
+// expire forgets the feedback of other members older than the report
+// interval
+func (f *FeedbackSuppressor) expire(now time.Time) {
+	for e, at := range f.seen {
+		if now.Sub(at) >= f.reportInterval() {
+			delete(f.seen, e)
+		}
+	}
+}
+
+// remaining returns the packet of pf without the suppressed events
+func (pf *pendingFeedback) remaining() Packet {
+	nack, ok := pf.packet.(*TransportLayerNack)
+	if !ok || len(pf.suppressed) == 0 {
+		return pf.packet
+	}
+	var seqs []uint16
+	for _, e := range pf.events {
+		if !pf.suppressed[e] {
+			seqs = append(seqs, e.seq)
+		}
+	}
+	return &TransportLayerNack{
+		SenderSSRC: nack.SenderSSRC,
+		MediaSSRC:  nack.MediaSSRC,
+		Nacks:      NackPairsFromSequenceNumbers(seqs),
+	}
+}
+
+// feedbackEvents returns the distinct losses p reports, or nil if p isn't
+// feedback that can be suppressed
+func feedbackEvents(p Packet) []feedbackEvent {
+	switch p := p.(type) {
+	case *TransportLayerNack:
+		var events []feedbackEvent
+		seen := make(map[uint16]bool)
+		for i := range p.Nacks {
+			for _, seq := range p.Nacks[i].PacketList() {
+				if !seen[seq] {
+					seen[seq] = true
+					events = append(events, feedbackEvent{mediaSSRC: p.MediaSSRC, seq: seq})
+				}
+			}
+		}
+		return events
+	case *PictureLossIndication:
+		return []feedbackEvent{{mediaSSRC: p.MediaSSRC, pli: true}}
+	}
+	return nil
+}
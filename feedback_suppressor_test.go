@@ -0,0 +1,174 @@
+package rtcp
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedbackSuppressorPointToPoint(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1000, 0)
+	f := NewFeedbackSuppressor(1, 0)
+	f.Clock = ClockFunc(func() time.Time { return now })
+	f.Membership = staticMembers{members: 2}
+	assert.Equal(time.Duration(0), f.MaxDither())
+
+	// without dithering the feedback is due at once, and feedback seen
+	// later can't suppress it
+	pli := &PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}
+	due, ok := f.Schedule(pli)
+	assert.True(ok)
+	assert.Equal(now, due)
+	assert.Equal([]Packet{pli}, f.Due())
+	assert.Equal(0, f.Pending())
+}
+
+func TestFeedbackSuppressorDither(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1000, 0)
+	f := NewFeedbackSuppressor(1, 0)
+	f.Clock = ClockFunc(func() time.Time { return now })
+	f.Membership = staticMembers{members: 10}
+	f.ReportInterval = 2 * time.Second
+	assert.Equal(time.Second, f.MaxDither())
+	f.DitherFactor = 0.25
+	assert.Equal(500*time.Millisecond, f.MaxDither())
+
+	for i := 0; i < 100; i++ {
+		due, ok := f.Schedule(&PictureLossIndication{SenderSSRC: 1, MediaSSRC: uint32(i)})
+		assert.True(ok)
+		assert.False(due.Before(now))
+		assert.True(due.Before(now.Add(500 * time.Millisecond)))
+	}
+	assert.Equal(100, f.Pending())
+
+	next, ok := f.Next()
+	assert.True(ok)
+	now = next
+	first := f.Due()
+	assert.NotEmpty(first)
+	assert.Equal(100-len(first), f.Pending())
+	now = now.Add(500 * time.Millisecond)
+	assert.Len(f.Due(), 100-len(first))
+	assert.Equal(0, f.Pending())
+	_, ok = f.Next()
+	assert.False(ok)
+}
+
+func TestFeedbackSuppressorSuppression(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1000, 0)
+	f := NewFeedbackSuppressor(1, 0)
+	f.Clock = ClockFunc(func() time.Time { return now })
+	f.Membership = staticMembers{members: 3}
+
+	nack := func(sender uint32, seqs ...uint16) *TransportLayerNack {
+		return &TransportLayerNack{SenderSSRC: sender, MediaSSRC: 5, Nacks: NackPairsFromSequenceNumbers(seqs)}
+	}
+
+	// feedback of another member observed while the local one waits
+	_, ok := f.Schedule(nack(1, 10, 11, 12))
+	assert.True(ok)
+	_, ok = f.Schedule(&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 5})
+	assert.True(ok)
+	f.Observe(&CompoundPacket{
+		&ReceiverReport{SSRC: 2},
+		nack(2, 11),
+		&PictureLossIndication{SenderSSRC: 2, MediaSSRC: 5},
+	})
+	// local feedback doesn't suppress itself
+	f.Observe(nack(1, 10))
+	assert.Equal(1, f.Pending())
+
+	now = now.Add(f.MaxDither())
+	assert.Equal([]Packet{nack(1, 10, 12)}, f.Due())
+
+	// feedback already sent by another member
+	_, ok = f.Schedule(nack(1, 11))
+	assert.False(ok)
+	_, ok = f.Schedule(&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 5})
+	assert.False(ok)
+	_, ok = f.Schedule(&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 6})
+	assert.True(ok)
+	// other feedback is never suppressed
+	_, ok = f.Schedule(&FullIntraRequest{SenderSSRC: 1, MediaSSRC: 5})
+	assert.True(ok)
+	assert.Equal(2, f.Pending())
+
+	// and forgotten after the report interval
+	now = now.Add(DefaultMinInterval)
+	_, ok = f.Schedule(nack(1, 11))
+	assert.True(ok)
+}
+
+func TestFeedbackSuppressorSeed(t *testing.T) {
+	now := time.Unix(1000, 0)
+	dues := func(seed int64) []time.Time {
+		f := NewFeedbackSuppressor(1, seed)
+		f.Clock = ClockFunc(func() time.Time { return now })
+		f.Membership = staticMembers{members: 3}
+		var dues []time.Time
+		for i := 0; i < 10; i++ {
+			due, _ := f.Schedule(&PictureLossIndication{SenderSSRC: 1, MediaSSRC: uint32(i)})
+			dues = append(dues, due)
+		}
+		return dues
+	}
+	assert.Equal(t, dues(1), dues(1))
+	assert.NotEqual(t, dues(1), dues(2))
+
+	// a recorded trace reproduces the dithering
+	trace := NewTraceSource(rand.NewSource(3))
+	f := NewFeedbackSuppressor(1, 0)
+	f.Source = trace
+	f.Clock = ClockFunc(func() time.Time { return now })
+	f.Membership = staticMembers{members: 3}
+	due, _ := f.Schedule(&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 1})
+
+	f = NewFeedbackSuppressor(1, 0)
+	f.Source = NewReplaySource(trace.Trace())
+	f.Clock = ClockFunc(func() time.Time { return now })
+	f.Membership = staticMembers{members: 3}
+	replayed, _ := f.Schedule(&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 1})
+	assert.Equal(t, due, replayed)
+}
+
+func TestFeedbackSuppressorRepaired(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1000, 0)
+	f := NewFeedbackSuppressor(1, 0)
+	f.Clock = ClockFunc(func() time.Time { return now })
+	f.Membership = staticMembers{members: 3}
+
+	// packets recovered while the NACK waits aren't asked for
+	_, ok := f.Schedule(&TransportLayerNack{SenderSSRC: 1, MediaSSRC: 2, Nacks: NackPairsFromSequenceNumbers([]uint16{10, 11, 12})})
+	assert.True(ok)
+	f.OnRepaired(2, 11)
+	// of another source
+	f.OnRepaired(3, 12)
+	now = now.Add(f.MaxDither())
+	assert.Equal([]Packet{&TransportLayerNack{SenderSSRC: 1, MediaSSRC: 2, Nacks: NackPairsFromSequenceNumbers([]uint16{10, 12})}}, f.Due())
+
+	// neither are packets recovered before their loss is noticed, and a
+	// NACK left empty is dropped
+	f.OnRepaired(2, 20)
+	_, ok = f.Schedule(&TransportLayerNack{SenderSSRC: 1, MediaSSRC: 2, Nacks: NackPairsFromSequenceNumbers([]uint16{20})})
+	assert.False(ok)
+	_, ok = f.Schedule(&TransportLayerNack{SenderSSRC: 1, MediaSSRC: 2, Nacks: NackPairsFromSequenceNumbers([]uint16{21})})
+	assert.True(ok)
+	f.OnRepaired(2, 21)
+	assert.Equal(0, f.Pending())
+
+	// picture losses aren't repaired
+	_, ok = f.Schedule(&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2})
+	assert.True(ok)
+	f.OnRepaired(2, 0)
+	assert.Equal(1, f.Pending())
+}
@@ -0,0 +1,331 @@
+package rtcp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A Filter selects RTCP packets with an expression such as
+//
+//	pt==205 && fmt==15
+//	ssrc==0x1234 || (pt==200 && loss>0)
+//
+// An expression compares fields to numbers with ==, !=, <, <=, > and >=,
+// and combines comparisons with &&, || and !. Numbers are decimal or, with
+// a 0x prefix, hexadecimal. The fields are
+//
+//	pt    the packet type
+//	fmt   the count field of the header, the FMT of feedback messages
+//	len   the length of the packet in bytes
+//	ssrc  the SSRC of the sender of the packet or a source it reports on
+//	loss  the fraction lost of a reception report, out of 256
+//
+// As a packet can have several SSRCs and reception reports, a comparison of
+// ssrc or loss holds if it holds for any of them, and never holds for a
+// packet without any.
+type Filter struct {
+	text string
+	root filterNode
+}
+
+// ParseFilter parses a filter expression.
+func ParseFilter(expr string) (*Filter, error) {
+	p := filterParser{tokens: tokenizeFilter(expr)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q", errBadFilter, p.tokens[p.pos])
+	}
+	return &Filter{text: expr, root: root}, nil
+}
+
+// Match reports whether p satisfies the filter. A nil Filter matches all
+// packets. A CompoundPacket matches if any of its packets does.
+func (f *Filter) Match(p Packet) bool {
+	if f == nil {
+		return true
+	}
+	if c, ok := p.(*CompoundPacket); ok {
+		return f.MatchAny(*c)
+	}
+	return f.root.eval(p)
+}
+
+// MatchAny reports whether any of packets satisfies the filter.
+func (f *Filter) MatchAny(packets []Packet) bool {
+	for _, p := range packets {
+		if f.Match(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the expression the filter was parsed from.
+func (f *Filter) String() string {
+	return f.text
+}
+
+type filterNode interface {
+	eval(p Packet) bool
+}
+
+type filterAnd struct{ left, right filterNode }
+
+func (n filterAnd) eval(p Packet) bool { return n.left.eval(p) && n.right.eval(p) }
+
+type filterOr struct{ left, right filterNode }
+
+func (n filterOr) eval(p Packet) bool { return n.left.eval(p) || n.right.eval(p) }
+
+type filterNot struct{ operand filterNode }
+
+func (n filterNot) eval(p Packet) bool { return !n.operand.eval(p) }
+
+type filterComparison struct {
+	field string
+	op    string
+	value uint64
+}
+
+func (n filterComparison) eval(p Packet) bool {
+	for _, v := range filterFieldValues(n.field, p) {
+		if compareFilterValues(v, n.op, n.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterFields lists the fields a comparison can use
+var filterFields = map[string]bool{"pt": true, "fmt": true, "len": true, "ssrc": true, "loss": true}
+
+// filterFieldValues returns the values of field for p
+func filterFieldValues(field string, p Packet) []uint64 {
+	switch field {
+	case "pt":
+		return []uint64{uint64(headerOf(p).Type)}
+	case "fmt":
+		return []uint64{uint64(headerOf(p).Count)}
+	case "len":
+		return []uint64{(uint64(headerOf(p).Length) + 1) * 4}
+	case "ssrc":
+		var out []uint64
+		if ssrc, ok := packetSenderSSRC(p); ok {
+			out = append(out, uint64(ssrc))
+		}
+		for _, ssrc := range p.DestinationSSRC() {
+			out = append(out, uint64(ssrc))
+		}
+		return out
+	case "loss":
+		var reports []ReceptionReport
+		switch p := p.(type) {
+		case *SenderReport:
+			reports = p.Reports
+		case *ReceiverReport:
+			reports = p.Reports
+		}
+		out := make([]uint64, len(reports))
+		for i, r := range reports {
+			out[i] = uint64(r.FractionLost)
+		}
+		return out
+	}
+	return nil
+}
+
+func compareFilterValues(a uint64, op string, b uint64) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+// headerOf returns the header p marshals with
+func headerOf(p Packet) Header {
+	switch p := p.(type) {
+	case *TransportLayerCC:
+		return p.packetHeader()
+	case interface{ Header() Header }:
+		return p.Header()
+	}
+
+	var h Header
+	if data, err := p.Marshal(); err == nil {
+		_ = h.Unmarshal(data)
+	}
+	return h
+}
+
+// packetSenderSSRC returns the SSRC of the sender of p, if p carries one
+func packetSenderSSRC(p Packet) (uint32, bool) {
+	switch p := p.(type) {
+	case *SenderReport:
+		return p.SSRC, true
+	case *ReceiverReport:
+		return p.SSRC, true
+	case *ApplicationDefined:
+		return p.SSRC, true
+	case *TransportLayerNack:
+		return p.SenderSSRC, true
+	case *TransportLayerCC:
+		return p.SenderSSRC, true
+	case *RapidResynchronizationRequest:
+		return p.SenderSSRC, true
+	case *RapidAcquisition:
+		return p.SenderSSRC, true
+	case *CCFeedbackReport:
+		return p.SenderSSRC, true
+	case *PictureLossIndication:
+		return p.SenderSSRC, true
+	case *FullIntraRequest:
+		return p.SenderSSRC, true
+	case *SliceLossIndication:
+		return p.SenderSSRC, true
+	case *ReceiverEstimatedMaximumBitrate:
+		return p.SenderSSRC, true
+	case *ExtendedReport:
+		return p.SenderSSRC, true
+	case *PortMapping:
+		return p.SSRC, true
+	}
+	return 0, false
+}
+
+// tokenizeFilter splits a filter expression into identifiers, numbers,
+// operators and parentheses
+func tokenizeFilter(expr string) []string {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, expr[i:i+1])
+			i++
+		case strings.IndexByte("=!<>&|", c) >= 0:
+			j := i + 1
+			for j < len(expr) && strings.IndexByte("=&|", expr[j]) >= 0 && j-i < 2 {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		default:
+			j := i + 1
+			for j < len(expr) && strings.IndexByte(" \t()=!<>&|", expr[j]) < 0 {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
+
+// filterParser is a recursive descent parser of filter expressions
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *filterParser) peek() string {
+	if p.pos == len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() string {
+	t := p.peek()
+	if t != "" {
+		p.pos++
+	}
+	return t
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = filterAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	switch t := p.next(); t {
+	case "!":
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{operand}, nil
+	case "(":
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("%w: missing )", errBadFilter)
+		}
+		return n, nil
+	case "":
+		return nil, fmt.Errorf("%w: unexpected end of expression", errBadFilter)
+	default:
+		field := strings.ToLower(t)
+		if !filterFields[field] {
+			return nil, fmt.Errorf("%w: unknown field %q", errBadFilter, t)
+		}
+		op := p.next()
+		switch op {
+		case "==", "!=", "<", "<=", ">", ">=":
+		default:
+			return nil, fmt.Errorf("%w: expected comparison after %q", errBadFilter, t)
+		}
+		number := p.next()
+		value, err := strconv.ParseUint(number, 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad number %q", errBadFilter, number)
+		}
+		return filterComparison{field: field, op: op, value: value}, nil
+	}
+}
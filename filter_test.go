@@ -0,0 +1,78 @@
+package rtcp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	sr := &SenderReport{
+		SSRC:    0x1234,
+		Reports: []ReceptionReport{{SSRC: 5, FractionLost: 0}, {SSRC: 6, FractionLost: 12}},
+	}
+	rr := &ReceiverReport{SSRC: 7, Reports: []ReceptionReport{{SSRC: 0x1234}}}
+	tcc := &TransportLayerCC{SenderSSRC: 7, MediaSSRC: 0x1234}
+	remb := &ReceiverEstimatedMaximumBitrate{SenderSSRC: 7, Bitrate: 1e6, SSRCs: []uint32{0x1234}}
+	pli := &PictureLossIndication{SenderSSRC: 8, MediaSSRC: 9}
+
+	for _, test := range []struct {
+		Expr   string
+		Packet Packet
+		Want   bool
+	}{
+		{"pt==205 && fmt==15", tcc, true},
+		{"pt==205 && fmt==15", remb, false},
+		{"pt==206&&fmt==15", remb, true},
+		{"ssrc==0x1234", sr, true},
+		{"ssrc==0x1234", rr, true},
+		{"ssrc==0x1234", pli, false},
+		{"ssrc==8", pli, true},
+		{"loss>0", sr, true},
+		{"loss>0", rr, false},
+		{"loss>0", pli, false},
+		{"!(loss>0)", pli, true},
+		{"pt==201 || pt==200 && loss>=12", sr, true},
+		{"(pt==201 || pt==200) && loss>12", sr, false},
+		{"len==12", pli, true},
+		{"len<=8", pli, false},
+		{"pt!=206", pli, false},
+		{"PT==206", pli, true},
+		{"fmt==1", &CompoundPacket{rr, pli}, true},
+		{"fmt==2", &CompoundPacket{rr, pli}, false},
+	} {
+		f, err := ParseFilter(test.Expr)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q): %v", test.Expr, err)
+		}
+		if got := f.Match(test.Packet); got != test.Want {
+			t.Fatalf("%q Match(%T) = %v, want %v", test.Expr, test.Packet, got, test.Want)
+		}
+		if got := f.String(); got != test.Expr {
+			t.Fatalf("String() = %q, want %q", got, test.Expr)
+		}
+	}
+
+	var f *Filter
+	if !f.Match(pli) {
+		t.Fatal("nil filter doesn't match")
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"pt",
+		"pt==",
+		"pt==abc",
+		"pt=205",
+		"foo==1",
+		"(pt==205",
+		"pt==205)",
+		"pt==205 &&",
+		"ssrc==0x100000000",
+	} {
+		if _, err := ParseFilter(expr); !errors.Is(err, errBadFilter) {
+			t.Fatalf("ParseFilter(%q): err = %v, want %v", expr, err, errBadFilter)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package rtcp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// A FIREntry is a (ssrc, seqno) pair, as carried by FullIntraRequest.
+type FIREntry struct {
+	SSRC uint32
+	// Incremented for every new request to the same media source, so
+	// retransmitted requests can be told apart
+	SequenceNumber uint8
+}
+
+// The FullIntraRequest packet is used to reliably request an intra frame
+// from one or more media senders. See RFC 5104, 4.3.1
+type FullIntraRequest struct {
+	SenderSSRC uint32
+	MediaSSRC  uint32
+
+	FIR []FIREntry
+}
+
+var _ Packet = (*FullIntraRequest)(nil) // assert is a Packet
+
+const (
+	firOffset      = 8
+	firEntryLength = 8
+)
+
+// Marshal encodes the FullIntraRequest
+func (p FullIntraRequest) Marshal() ([]byte, error) {
+	rawPacket := make([]byte, firOffset+(len(p.FIR)*firEntryLength))
+	binary.BigEndian.PutUint32(rawPacket, p.SenderSSRC)
+	binary.BigEndian.PutUint32(rawPacket[4:], p.MediaSSRC)
+	for i, fir := range p.FIR {
+		binary.BigEndian.PutUint32(rawPacket[firOffset+firEntryLength*i:], fir.SSRC)
+		rawPacket[firOffset+firEntryLength*i+4] = fir.SequenceNumber
+	}
+	h := p.Header()
+	hData, err := h.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(hData, rawPacket...), nil
+}
+
+// Unmarshal decodes the FullIntraRequest
+func (p *FullIntraRequest) Unmarshal(rawPacket []byte) error {
+	if len(rawPacket) < (headerLength + ssrcLength) {
+		return errPacketTooShort
+	}
+
+	var h Header
+	if err := h.Unmarshal(rawPacket); err != nil {
+		return err
+	}
+
+	if h.size() < headerLength+firOffset || len(rawPacket) < h.size() {
+		return errPacketTooShort
+	}
+
+	if h.Type != TypePayloadSpecificFeedback || h.Count != FormatFIR {
+		return errWrongType
+	}
+
+	p.SenderSSRC = binary.BigEndian.Uint32(rawPacket[headerLength:])
+	p.MediaSSRC = binary.BigEndian.Uint32(rawPacket[headerLength+ssrcLength:])
+	p.FIR = p.FIR[:0]
+	for i := headerLength + firOffset; i+firEntryLength <= h.size(); i += firEntryLength {
+		p.FIR = append(p.FIR, FIREntry{
+			binary.BigEndian.Uint32(rawPacket[i:]),
+			rawPacket[i+4],
+		})
+	}
+	return nil
+}
+
+// Header returns the Header associated with this packet.
+func (p *FullIntraRequest) Header() Header {
+	return Header{
+		Count:  FormatFIR,
+		Type:   TypePayloadSpecificFeedback,
+		Length: uint16((p.len() / 4) - 1),
+	}
+}
+
+func (p *FullIntraRequest) len() int {
+	return headerLength + firOffset + len(p.FIR)*firEntryLength
+}
+
+func (p *FullIntraRequest) String() string {
+	out := fmt.Sprintf("FullIntraRequest %x %x", p.SenderSSRC, p.MediaSSRC)
+	for _, fir := range p.FIR {
+		out += fmt.Sprintf(" (%x %d)", fir.SSRC, fir.SequenceNumber)
+	}
+	return out
+}
+
+// DestinationSSRC returns the media sources an intra frame is requested
+// from.
+func (p *FullIntraRequest) DestinationSSRC() []uint32 {
+	ssrcs := make([]uint32, 0, len(p.FIR))
+	for _, fir := range p.FIR {
+		ssrcs = append(ssrcs, fir.SSRC)
+	}
+	return ssrcs
+}
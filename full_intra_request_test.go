@@ -0,0 +1,113 @@
+package rtcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFullIntraRequestUnmarshal(t *testing.T) {
+	for _, test := range []struct {
+		Name      string
+		Data      []byte
+		Want      FullIntraRequest
+		WantError error
+	}{
+		{
+			Name: "valid",
+			Data: []byte{
+				// v=2, p=0, FMT=4, PSFB, len=4
+				0x84, 0xce, 0x00, 0x04,
+				// sender=0x0
+				0x00, 0x00, 0x00, 0x00,
+				// media=0x0
+				0x00, 0x00, 0x00, 0x00,
+				// ssrc=0x4bc4fcb4
+				0x4b, 0xc4, 0xfc, 0xb4,
+				// seqno=0x2a, reserved
+				0x2a, 0x00, 0x00, 0x00,
+			},
+			Want: FullIntraRequest{
+				FIR: []FIREntry{{SSRC: 0x4bc4fcb4, SequenceNumber: 0x2a}},
+			},
+		},
+		{
+			Name: "packet too short",
+			Data: []byte{
+				0x00, 0x00, 0x00, 0x00,
+			},
+			WantError: errPacketTooShort,
+		},
+		{
+			Name: "length beyond the packet",
+			Data: []byte{
+				// v=2, p=0, FMT=4, PSFB, len=4
+				0x84, 0xce, 0x00, 0x04,
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+			},
+			WantError: errPacketTooShort,
+		},
+		{
+			Name: "wrong fmt",
+			Data: []byte{
+				// v=2, p=0, FMT=1, PSFB, len=2
+				0x81, 0xce, 0x00, 0x02,
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+			},
+			WantError: errWrongType,
+		},
+	} {
+		var fir FullIntraRequest
+		err := fir.Unmarshal(test.Data)
+		if got, want := err, test.WantError; got != want {
+			t.Fatalf("Unmarshal %q: err = %v, want %v", test.Name, got, want)
+		}
+		if err != nil {
+			continue
+		}
+
+		if got, want := fir, test.Want; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Unmarshal %q: got %v, want %v", test.Name, got, want)
+		}
+	}
+}
+
+func TestFullIntraRequestRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		Name   string
+		Packet FullIntraRequest
+	}{
+		{
+			Name: "one entry",
+			Packet: FullIntraRequest{
+				SenderSSRC: 1,
+				FIR:        []FIREntry{{SSRC: 2, SequenceNumber: 3}},
+			},
+		},
+		{
+			Name: "two entries",
+			Packet: FullIntraRequest{
+				SenderSSRC: 1,
+				FIR:        []FIREntry{{SSRC: 2, SequenceNumber: 3}, {SSRC: 4, SequenceNumber: 255}},
+			},
+		},
+	} {
+		data, err := test.Packet.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal %q: %v", test.Name, err)
+		}
+
+		var decoded FullIntraRequest
+		if err := decoded.Unmarshal(data); err != nil {
+			t.Fatalf("Unmarshal %q: %v", test.Name, err)
+		}
+
+		if got, want := decoded, test.Packet; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%q round trip: got %#v, want %#v", test.Name, got, want)
+		}
+		if got, want := decoded.DestinationSSRC(), test.Packet.DestinationSSRC(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%q DestinationSSRC: got %v, want %v", test.Name, got, want)
+		}
+	}
+}
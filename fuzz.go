@@ -1,51 +1,100 @@
+//go:build gofuzz
 // +build gofuzz
 
 package rtcp
 
-import (
-	"bytes"
-	"io"
-)
-
-// Fuzz implements a randomized fuzz test of the rtcp
-// parser using go-fuzz.
+// The Fuzz functions are entry points for go-fuzz and OSS-Fuzz. Each returns
+// 1 for input that decodes, which go-fuzz should prioritize, and 0 otherwise,
+// and panics if a decoded packet doesn't survive marshaling and decoding
+// again unchanged.
 //
 // To run the fuzzer, first download go-fuzz:
 // `go get github.com/dvyukov/go-fuzz/...`
 //
-// Then build the testing package:
-// `go-fuzz-build github.com/pion/webrtc`
+// Then build the testing package, choosing an entry point:
+// `go-fuzz-build -func FuzzTransportLayerCC github.com/pion/rtcp`
 //
 // And run the fuzzer on the corpus:
 // ```
 // mkdir workdir
 //
-// # optionally add a starter corpus of valid rtcp packets.
-// # the corpus should be as compact and diverse as possible.
+// # optionally add a starter corpus of valid rtcp packets, e.g. the
+// # Data of TestVectors. The corpus should be as compact and diverse as
+// # possible, see MinimizeCorpus.
 // cp -r ~/my-rtcp-packets workdir/corpus
 //
-// go-fuzz -bin=ase-fuzz.zip -workdir=workdir
-// ````
-func Fuzz(data []byte) int {
-	r := NewReader(bytes.NewReader(data))
-	for {
-		_, data, err := r.ReadPacket()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return 0
-		}
-
-		packet, err := Unmarshal(data)
-		if err != nil {
-			return 0
-		}
-
-		if _, err := packet.Marshal(); err != nil {
-			return 0
-		}
+// go-fuzz -bin=rtcp-fuzz.zip -workdir=workdir
+// ```
+
+func fuzzResult(n int, err error) int {
+	if err != nil {
+		panic(err) // nolint
 	}
+	return n
+}
+
+// Fuzz decodes data as a datagram of RTCP packets.
+func Fuzz(data []byte) int {
+	return fuzzResult(fuzzDatagram(data))
+}
+
+// FuzzSenderReport decodes data as a SenderReport.
+func FuzzSenderReport(data []byte) int {
+	return fuzzResult(fuzzPacket(data, func() Packet { return new(SenderReport) }))
+}
+
+// FuzzReceiverReport decodes data as a ReceiverReport.
+func FuzzReceiverReport(data []byte) int {
+	return fuzzResult(fuzzPacket(data, func() Packet { return new(ReceiverReport) }))
+}
+
+// FuzzSourceDescription decodes data as a SourceDescription.
+func FuzzSourceDescription(data []byte) int {
+	return fuzzResult(fuzzPacket(data, func() Packet { return new(SourceDescription) }))
+}
+
+// FuzzGoodbye decodes data as a Goodbye.
+func FuzzGoodbye(data []byte) int {
+	return fuzzResult(fuzzPacket(data, func() Packet { return new(Goodbye) }))
+}
+
+// FuzzApplicationDefined decodes data as an ApplicationDefined.
+func FuzzApplicationDefined(data []byte) int {
+	return fuzzResult(fuzzPacket(data, func() Packet { return new(ApplicationDefined) }))
+}
+
+// FuzzExtendedReport decodes data as an ExtendedReport.
+func FuzzExtendedReport(data []byte) int {
+	return fuzzResult(fuzzPacket(data, func() Packet { return new(ExtendedReport) }))
+}
+
+// FuzzTransportLayerNack decodes data as a TransportLayerNack.
+func FuzzTransportLayerNack(data []byte) int {
+	return fuzzResult(fuzzPacket(data, func() Packet { return new(TransportLayerNack) }))
+}
+
+// FuzzTransportLayerCC decodes data as a TransportLayerCC.
+func FuzzTransportLayerCC(data []byte) int {
+	return fuzzResult(fuzzPacket(data, func() Packet { return new(TransportLayerCC) }))
+}
+
+// FuzzCCFeedbackReport decodes data as a CCFeedbackReport.
+func FuzzCCFeedbackReport(data []byte) int {
+	return fuzzResult(fuzzPacket(data, func() Packet { return new(CCFeedbackReport) }))
+}
+
+// FuzzPictureLossIndication decodes data as a PictureLossIndication.
+func FuzzPictureLossIndication(data []byte) int {
+	return fuzzResult(fuzzPacket(data, func() Packet { return new(PictureLossIndication) }))
+}
+
+// FuzzFullIntraRequest decodes data as a FullIntraRequest.
+func FuzzFullIntraRequest(data []byte) int {
+	return fuzzResult(fuzzPacket(data, func() Packet { return new(FullIntraRequest) }))
+}
 
-	return 1
+// FuzzReceiverEstimatedMaximumBitrate decodes data as a
+// ReceiverEstimatedMaximumBitrate.
+func FuzzReceiverEstimatedMaximumBitrate(data []byte) int {
+	return fuzzResult(fuzzPacket(data, func() Packet { return new(ReceiverEstimatedMaximumBitrate) }))
 }
@@ -0,0 +1,104 @@
+package rtcp
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// fuzzPacket decodes data into a packet created by newPacket, and checks that
+// it marshals and decodes again to the same bytes. It returns 1 if data
+// decodes and 0 if it doesn't, or errRoundTrip if the check fails. Packets
+// that decode but don't marshal are accepted, as Unmarshal tolerates some
+// values Marshal refuses to emit.
+func fuzzPacket(data []byte, newPacket func() Packet) (int, error) {
+	p := newPacket()
+	if err := p.Unmarshal(data); err != nil {
+		return 0, nil
+	}
+	first, err := p.Marshal()
+	if err != nil {
+		return 1, nil
+	}
+
+	again := newPacket()
+	if err = again.Unmarshal(first); err != nil {
+		return 1, fmt.Errorf("%w: %T %x decodes from %x: %v", errRoundTrip, p, first, data, err)
+	}
+	second, err := again.Marshal()
+	if err != nil || !bytes.Equal(first, second) {
+		return 1, fmt.Errorf("%w: %T %x marshals to %x: %v", errRoundTrip, p, first, second, err)
+	}
+	return 1, nil
+}
+
+// fuzzDatagram is fuzzPacket for a datagram decoded by Unmarshal
+func fuzzDatagram(data []byte) (int, error) {
+	packets, err := Unmarshal(data)
+	if err != nil {
+		return 0, nil
+	}
+	first, err := Marshal(packets)
+	if err != nil {
+		return 1, nil
+	}
+
+	if packets, err = Unmarshal(first); err != nil {
+		return 1, fmt.Errorf("%w: %x decodes from %x: %v", errRoundTrip, first, data, err)
+	}
+	second, err := Marshal(packets)
+	if err != nil || !bytes.Equal(first, second) {
+		return 1, fmt.Errorf("%w: %x marshals to %x: %v", errRoundTrip, first, second, err)
+	}
+	return 1, nil
+}
+
+// MinimizeCorpus returns the smallest input of corpus for every distinct way
+// inputs decode, in the order they first appear: the types of the packets
+// found walking the headers, whether the last is truncated, and the error of
+// Unmarshal, if any. It shrinks
+// corpora collected from captures, where most datagrams are alike, before
+// they seed a fuzzer.
+func MinimizeCorpus(corpus [][]byte) [][]byte {
+	var order []string
+	smallest := make(map[string][]byte)
+	for _, input := range corpus {
+		sig := corpusSignature(input)
+		kept, ok := smallest[sig]
+		if !ok {
+			order = append(order, sig)
+		}
+		if !ok || len(input) < len(kept) {
+			smallest[sig] = input
+		}
+	}
+
+	out := make([][]byte, len(order))
+	for i, sig := range order {
+		out[i] = smallest[sig]
+	}
+	return out
+}
+
+// corpusSignature describes how data decodes, see MinimizeCorpus
+func corpusSignature(data []byte) string {
+	var b strings.Builder
+	for raw := data; len(raw) > 0; {
+		var h Header
+		if h.Unmarshal(raw) != nil {
+			b.WriteString("invalid ")
+			break
+		}
+		k := packetKindOf(h)
+		fmt.Fprintf(&b, "%d/%d ", k.Type, k.Format)
+		if h.size() > len(raw) {
+			b.WriteString("truncated ")
+			break
+		}
+		raw = raw[h.size():]
+	}
+	if _, err := Unmarshal(data); err != nil {
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	vectors, err := TestVectors()
+	assert.NoError(err)
+	corpus := [][]byte{realPacket}
+	for _, v := range vectors {
+		corpus = append(corpus, v.Data)
+	}
+	f := NewFaultInjector(1)
+	f.Corrupt, f.Truncate = 0.5, 0.2
+	for i := 0; i < 50; i++ {
+		for _, data := range corpus[:len(vectors)+1] {
+			corpus = append(corpus, f.Impair(data)...)
+		}
+	}
+
+	newPackets := []func() Packet{
+		func() Packet { return new(SenderReport) },
+		func() Packet { return new(ReceiverReport) },
+		func() Packet { return new(SourceDescription) },
+		func() Packet { return new(Goodbye) },
+		func() Packet { return new(ApplicationDefined) },
+		func() Packet { return new(ExtendedReport) },
+		func() Packet { return new(TransportLayerNack) },
+		func() Packet { return new(TransportLayerCC) },
+		func() Packet { return new(PictureLossIndication) },
+		func() Packet { return new(FullIntraRequest) },
+		func() Packet { return new(ReceiverEstimatedMaximumBitrate) },
+	}
+	decoded := 0
+	for _, data := range corpus {
+		n, err := fuzzDatagram(data)
+		assert.NoError(err)
+		decoded += n
+		for _, newPacket := range newPackets {
+			_, err := fuzzPacket(data, newPacket)
+			assert.NoError(err)
+		}
+	}
+	assert.True(decoded > len(vectors), "only %d inputs decoded", decoded)
+
+	n, err := fuzzDatagram(realPacket[:10])
+	assert.Equal(0, n)
+	assert.NoError(err)
+}
+
+func TestMinimizeCorpus(t *testing.T) {
+	rr := []byte{0x80, 0xc9, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01}
+	rrWithReport, err := (&ReceiverReport{SSRC: 1, Reports: []ReceptionReport{{SSRC: 2}}}).Marshal()
+	assert.NoError(t, err)
+	pli, err := (&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}).Marshal()
+	assert.NoError(t, err)
+	rrAndPLI := append(append([]byte(nil), rr...), pli...)
+
+	assert.Equal(t, [][]byte{rr, pli, rr[:6], rrAndPLI[:10], {0x00}}, MinimizeCorpus([][]byte{
+		rrWithReport, pli, rr, rrWithReport[:20], rr[:6], rrAndPLI[:10], rrAndPLI[:11], {0x00}, {0x00, 0x00},
+	}))
+	assert.Empty(t, MinimizeCorpus(nil))
+}
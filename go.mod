@@ -2,4 +2,7 @@ module github.com/pion/rtcp
 
 go 1.13
 
-require github.com/stretchr/testify v1.5.1
+require (
+	github.com/stretchr/testify v1.5.1
+	golang.org/x/net v0.0.0-20201021035429-f5854403a974
+)
@@ -2,6 +2,8 @@ package rtcp
 
 import (
 	"encoding/binary"
+	"strings"
+	"unicode/utf8"
 )
 
 // The Goodbye packet indicates that one or more sources are no longer active.
@@ -87,17 +89,22 @@ func (g *Goodbye) Unmarshal(rawPacket []byte) error {
 		return errWrongType
 	}
 
-	if getPadding(len(rawPacket)) != 0 {
+	if header.size() > len(rawPacket) {
 		return errPacketTooShort
 	}
+	rawPacket = rawPacket[:header.size()]
 
-	g.Sources = make([]uint32, header.Count)
+	if getPadding(len(rawPacket)) != 0 {
+		return errPacketTooShort
+	}
 
-	reasonOffset := int(headerLength + header.Count*ssrcLength)
+	reasonOffset := headerLength + int(header.Count)*ssrcLength
 	if reasonOffset > len(rawPacket) {
 		return errPacketTooShort
 	}
 
+	g.Sources = make([]uint32, header.Count)
+
 	for i := 0; i < int(header.Count); i++ {
 		offset := headerLength + i*ssrcLength
 
@@ -118,6 +125,39 @@ func (g *Goodbye) Unmarshal(rawPacket []byte) error {
 	return nil
 }
 
+// ValidateReason checks that Reason can be sent: it must fit in 255 octets,
+// and be UTF-8 as required for RTCP text by RFC 3550, 6.5. Marshal only
+// enforces the length.
+func (g Goodbye) ValidateReason() error {
+	if len(g.Reason) > sdesMaxOctetCount {
+		return errReasonTooLong
+	}
+	if !utf8.ValidString(g.Reason) {
+		return errReasonNotUTF8
+	}
+	return nil
+}
+
+// SetReason sets Reason to a version of reason that passes ValidateReason.
+// Invalid UTF-8 sequences are replaced with U+FFFD, and a reason too long
+// for the packet is cut at the last whole character within 255 octets. The
+// reason is set in either case; errReasonTruncated reports that text was
+// lost, so callers concatenating user input can notice.
+func (g *Goodbye) SetReason(reason string) error {
+	reason = strings.ToValidUTF8(reason, string(utf8.RuneError))
+	if len(reason) <= sdesMaxOctetCount {
+		g.Reason = reason
+		return nil
+	}
+
+	n := sdesMaxOctetCount
+	for n > 0 && !utf8.RuneStart(reason[n]) {
+		n--
+	}
+	g.Reason = reason[:n]
+	return errReasonTruncated
+}
+
 // Header returns the Header associated with this packet.
 func (g *Goodbye) Header() Header {
 	return Header{
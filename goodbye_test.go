@@ -2,6 +2,7 @@ package rtcp
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -15,8 +16,8 @@ func TestGoodbyeUnmarshal(t *testing.T) {
 		{
 			Name: "valid",
 			Data: []byte{
-				// v=2, p=0, count=1, BYE, len=12
-				0x81, 0xcb, 0x00, 0x0c,
+				// v=2, p=0, count=1, BYE, len=2
+				0x81, 0xcb, 0x00, 0x02,
 				// ssrc=0x902f9e2e
 				0x90, 0x2f, 0x9e, 0x2e,
 				// len=3, text=FOO
@@ -30,8 +31,8 @@ func TestGoodbyeUnmarshal(t *testing.T) {
 		{
 			Name: "invalid octet count",
 			Data: []byte{
-				// v=2, p=0, count=1, BYE, len=12
-				0x81, 0xcb, 0x00, 0x0c,
+				// v=2, p=0, count=1, BYE, len=2
+				0x81, 0xcb, 0x00, 0x02,
 				// ssrc=0x902f9e2e
 				0x90, 0x2f, 0x9e, 0x2e,
 				// len=4, text=FOO
@@ -54,11 +55,11 @@ func TestGoodbyeUnmarshal(t *testing.T) {
 		{
 			Name: "short reason",
 			Data: []byte{
-				// v=2, p=0, count=1, BYE, len=12
-				0x81, 0xcb, 0x00, 0x0c,
+				// v=2, p=0, count=1, BYE, len=2
+				0x81, 0xcb, 0x00, 0x02,
 				// ssrc=0x902f9e2e
 				0x90, 0x2f, 0x9e, 0x2e,
-				// len=3, text=F + padding
+				// len=1, text=F + padding
 				0x01, 0x46, 0x00, 0x00,
 			},
 			Want: Goodbye{
@@ -81,8 +82,8 @@ func TestGoodbyeUnmarshal(t *testing.T) {
 		{
 			Name: "bad count in header",
 			Data: []byte{
-				// v=2, p=0, count=2, BYE, len=8
-				0x82, 0xcb, 0x00, 0x0c,
+				// v=2, p=0, count=2, BYE, len=1
+				0x82, 0xcb, 0x00, 0x01,
 				// ssrc=0x902f9e2e
 				0x90, 0x2f, 0x9e, 0x2e,
 			},
@@ -91,8 +92,8 @@ func TestGoodbyeUnmarshal(t *testing.T) {
 		{
 			Name: "empty packet",
 			Data: []byte{
-				// v=2, p=0, count=0, BYE, len=4
-				0x80, 0xcb, 0x00, 0x04,
+				// v=2, p=0, count=0, BYE, len=0
+				0x80, 0xcb, 0x00, 0x00,
 			},
 			Want: Goodbye{
 				Sources: []uint32{},
@@ -205,3 +206,57 @@ func init() {
 		tooManySources = append(tooManySources, 0x00)
 	}
 }
+
+func TestGoodbyeReason(t *testing.T) {
+	for _, test := range []struct {
+		Name          string
+		Reason        string
+		Want          string
+		ValidateError error
+		SetError      error
+	}{
+		{
+			Name:   "valid",
+			Reason: "camera malfunction",
+			Want:   "camera malfunction",
+		},
+		{
+			Name:          "invalid utf-8",
+			Reason:        "bad \xff\xfe input",
+			Want:          "bad � input",
+			ValidateError: errReasonNotUTF8,
+		},
+		{
+			Name:          "too long",
+			Reason:        strings.Repeat("a", 300),
+			Want:          strings.Repeat("a", 255),
+			ValidateError: errReasonTooLong,
+			SetError:      errReasonTruncated,
+		},
+		{
+			// 127 two octet characters; the 128th would end at octet 256
+			Name:          "too long multibyte",
+			Reason:        strings.Repeat("é", 200),
+			Want:          strings.Repeat("é", 127),
+			ValidateError: errReasonTooLong,
+			SetError:      errReasonTruncated,
+		},
+	} {
+		g := Goodbye{Sources: []uint32{1}, Reason: test.Reason}
+		if got, want := g.ValidateReason(), test.ValidateError; got != want {
+			t.Fatalf("ValidateReason %q: got %v, want %v", test.Name, got, want)
+		}
+		if got, want := g.SetReason(test.Reason), test.SetError; got != want {
+			t.Fatalf("SetReason %q: got %v, want %v", test.Name, got, want)
+		}
+		if g.Reason != test.Want {
+			t.Fatalf("SetReason %q: reason %q, want %q", test.Name, g.Reason, test.Want)
+		}
+		if err := g.ValidateReason(); err != nil {
+			t.Fatalf("ValidateReason %q after SetReason: %v", test.Name, err)
+		}
+		if _, err := g.Marshal(); err != nil {
+			t.Fatalf("Marshal %q after SetReason: %v", test.Name, err)
+		}
+	}
+}
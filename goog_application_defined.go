@@ -0,0 +1,30 @@
+package rtcp
+
+import "fmt"
+
+const googApplicationName = "goog"
+
+// GoogApplicationDefined is an APP packet named "goog", as sent by older
+// Chrome endpoints. The formats of its subtypes were never documented, so
+// the payload is kept as Data; decoding them separately lets captures from
+// such endpoints be told apart from arbitrary APP packets.
+type GoogApplicationDefined struct {
+	ApplicationDefined
+}
+
+var _ Packet = (*GoogApplicationDefined)(nil) // assert is a Packet
+
+// Unmarshal decodes the GoogApplicationDefined packet from binary
+func (g *GoogApplicationDefined) Unmarshal(rawPacket []byte) error {
+	if err := g.ApplicationDefined.Unmarshal(rawPacket); err != nil {
+		return err
+	}
+	if g.Name != googApplicationName {
+		return errWrongType
+	}
+	return nil
+}
+
+func (g GoogApplicationDefined) String() string {
+	return fmt.Sprintf("GoogApplicationDefined subtype %d from %x, %d bytes", g.SubType, g.SSRC, len(g.Data))
+}
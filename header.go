@@ -13,22 +13,27 @@ const (
 	TypeReceiverReport            PacketType = 201 // RFC 3550, 6.4.2
 	TypeSourceDescription         PacketType = 202 // RFC 3550, 6.5
 	TypeGoodbye                   PacketType = 203 // RFC 3550, 6.6
-	TypeApplicationDefined        PacketType = 204 // RFC 3550, 6.7 (unimplemented)
+	TypeApplicationDefined        PacketType = 204 // RFC 3550, 6.7
 	TypeTransportSpecificFeedback PacketType = 205 // RFC 4585, 6051
 	TypePayloadSpecificFeedback   PacketType = 206 // RFC 4585, 6.3
-
+	TypeExtendedReport            PacketType = 207 // RFC 3611
+	TypeToken                     PacketType = 210 // RFC 6284
 )
 
 // Transport and Payload specific feedback messages overload the count field to act as a message type. those are listed here
 const (
 	FormatSLI  uint8 = 2
 	FormatPLI  uint8 = 1
+	FormatFIR  uint8 = 4
 	FormatTLN  uint8 = 1
 	FormatRRR  uint8 = 5
+	FormatRAMS uint8 = 6
 	FormatREMB uint8 = 15
 
 	//https://tools.ietf.org/html/draft-holmer-rmcat-transport-wide-cc-extensions-01#page-5
 	FormatTCC uint8 = 15
+	// https://tools.ietf.org/html/rfc8888#section-3.1
+	FormatCCFB uint8 = 11
 )
 
 func (p PacketType) String() string {
@@ -47,6 +52,10 @@ func (p PacketType) String() string {
 		return "TSFB"
 	case TypePayloadSpecificFeedback:
 		return "PSFB"
+	case TypeExtendedReport:
+		return "XR"
+	case TypeToken:
+		return "TOKEN"
 	default:
 		return string(p)
 	}
@@ -60,7 +69,8 @@ type Header struct {
 	// some additional padding octets at the end which are not part of
 	// the control information but are included in the length field.
 	Padding bool
-	// The number of reception reports, sources contained or FMT in this packet (depending on the Type)
+	// The number of reception reports, sources contained or FMT in this packet (depending on the Type),
+	// see ReportCount and FeedbackFormat
 	Count uint8
 	// The RTCP packet type for this packet
 	Type PacketType
@@ -69,6 +79,55 @@ type Header struct {
 	Length uint16
 }
 
+// size returns the length of the packet in octets, as given by the length
+// field. It is computed as an int, as the length field times 4 overflows a
+// uint16.
+func (h Header) size() int {
+	return (int(h.Length) + 1) * 4
+}
+
+// ReportCount returns the number of items in the body of packets whose
+// Count field is a count: the reception reports of SenderReports and
+// ReceiverReports, the chunks of SourceDescriptions and the sources of
+// Goodbyes. It returns false for other packet types, whose Count field is
+// a subtype or FMT, or reserved.
+func (h Header) ReportCount() (int, bool) {
+	switch h.Type {
+	case TypeSenderReport, TypeReceiverReport, TypeSourceDescription, TypeGoodbye:
+		return int(h.Count), true
+	}
+	return 0, false
+}
+
+// FeedbackFormat returns the feedback message type (FMT) of transport and
+// payload specific feedback, which is carried in the Count field, such as
+// FormatTLN or FormatPLI. It returns false for other packet types.
+func (h Header) FeedbackFormat() (uint8, bool) {
+	switch h.Type {
+	case TypeTransportSpecificFeedback, TypePayloadSpecificFeedback:
+		return h.Count, true
+	}
+	return 0, false
+}
+
+// minBodySize returns the smallest size of a packet with this header that
+// holds ReportCount items, or zero if the Count field isn't a count
+func (h Header) minBodySize() int {
+	switch h.Type {
+	case TypeSenderReport:
+		return headerLength + srHeaderLength + int(h.Count)*receptionReportLength
+	case TypeReceiverReport:
+		return headerLength + ssrcLength + int(h.Count)*receptionReportLength
+	case TypeSourceDescription:
+		// a chunk holds at least its source and a terminating null octet,
+		// padded to 32 bits
+		return headerLength + int(h.Count)*(sdesSourceLen+4)
+	case TypeGoodbye:
+		return headerLength + int(h.Count)*ssrcLength
+	}
+	return 0
+}
+
 const (
 	headerLength = 4
 	versionShift = 6
@@ -111,3 +111,35 @@ func TestHeaderRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestHeaderCountAccessors(t *testing.T) {
+	for _, test := range []struct {
+		Type       PacketType
+		WantReport bool
+		WantFormat bool
+	}{
+		{TypeSenderReport, true, false},
+		{TypeReceiverReport, true, false},
+		{TypeSourceDescription, true, false},
+		{TypeGoodbye, true, false},
+		{TypeApplicationDefined, false, false},
+		{TypeTransportSpecificFeedback, false, true},
+		{TypePayloadSpecificFeedback, false, true},
+		{TypeExtendedReport, false, false},
+	} {
+		h := Header{Type: test.Type, Count: 3}
+		count, ok := h.ReportCount()
+		if ok != test.WantReport || (ok && count != 3) {
+			t.Fatalf("ReportCount %v: got %d, %v", test.Type, count, ok)
+		}
+		format, ok := h.FeedbackFormat()
+		if ok != test.WantFormat || (ok && format != 3) {
+			t.Fatalf("FeedbackFormat %v: got %d, %v", test.Type, format, ok)
+		}
+	}
+
+	pli := PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}
+	if format, ok := pli.Header().FeedbackFormat(); !ok || format != FormatPLI {
+		t.Fatalf("FeedbackFormat of PLI: got %d, %v", format, ok)
+	}
+}
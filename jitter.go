@@ -0,0 +1,87 @@
+package rtcp
+
+import "time"
+
+// An InterarrivalJitter accumulates the interarrival jitter of an RTP
+// stream as described in RFC 3550, 6.4.1 and A.8. The estimate is kept in
+// timestamp units, so the result can be placed in ReceptionReport.Jitter
+// directly, or converted to a duration for metrics.
+//
+// One InterarrivalJitter tracks a single SSRC; retransmissions, whose RTP
+// timestamps don't reflect their send time, shouldn't be passed to it.
+type InterarrivalJitter struct {
+	// The RTP clock rate of the stream in Hz, e.g. 90000 for video
+	ClockRate uint32
+
+	// the jitter estimate scaled by 16, as in RFC 3550 A.8, to keep the
+	// fractional part without floating point
+	jitter      uint32
+	transit     uint32
+	base        time.Time
+	initialized bool
+}
+
+// NewInterarrivalJitter creates an InterarrivalJitter for a stream with the
+// given RTP clock rate in Hz.
+func NewInterarrivalJitter(clockRate uint32) *InterarrivalJitter {
+	return &InterarrivalJitter{ClockRate: clockRate}
+}
+
+// Update records the arrival of a packet carrying the RTP timestamp
+// rtpTimestamp at the local time arrival.
+func (j *InterarrivalJitter) Update(rtpTimestamp uint32, arrival time.Time) {
+	if !j.initialized {
+		j.base = arrival
+	}
+	j.UpdateTimestamp(rtpTimestamp, j.timestampUnits(arrival.Sub(j.base)))
+}
+
+// UpdateTimestamp records the arrival of a packet carrying the RTP timestamp
+// rtpTimestamp, with the arrival time already expressed in timestamp units
+// of the same clock rate. The arrival clock may have any origin, and both
+// values may wrap.
+func (j *InterarrivalJitter) UpdateTimestamp(rtpTimestamp, arrival uint32) {
+	transit := arrival - rtpTimestamp
+	if !j.initialized {
+		j.transit = transit
+		j.initialized = true
+		return
+	}
+
+	d := int32(transit - j.transit)
+	j.transit = transit
+	if d < 0 {
+		d = -d
+	}
+	j.jitter += uint32(d) - ((j.jitter + 8) >> 4)
+}
+
+// Jitter returns the current estimate in timestamp units, as carried in
+// ReceptionReport.Jitter.
+func (j *InterarrivalJitter) Jitter() uint32 {
+	return j.jitter >> 4
+}
+
+// Duration returns the current estimate converted to a duration using
+// ClockRate. It returns 0 if ClockRate is 0.
+func (j *InterarrivalJitter) Duration() time.Duration {
+	if j.ClockRate == 0 {
+		return 0
+	}
+	// keep the fractional part of the scaled estimate
+	return time.Duration(uint64(j.jitter) * uint64(time.Second) / (16 * uint64(j.ClockRate)))
+}
+
+// Reset discards the estimate, e.g. after the source changed its SSRC or
+// timestamp base.
+func (j *InterarrivalJitter) Reset() {
+	*j = InterarrivalJitter{ClockRate: j.ClockRate}
+}
+
+// timestampUnits converts d to units of ClockRate, without overflowing for
+// long running streams
+func (j *InterarrivalJitter) timestampUnits(d time.Duration) uint32 {
+	rate := int64(j.ClockRate)
+	sec, frac := int64(d/time.Second), int64(d%time.Second)
+	return uint32(sec*rate + frac*rate/int64(time.Second))
+}
@@ -0,0 +1,102 @@
+package rtcp
+
+import "encoding/binary"
+
+// Special values of the delays of a JitterBufferReportBlock
+const (
+	// The delay is larger than can be represented
+	JitterBufferOverRange = 0xfffe
+	// The delay isn't known
+	JitterBufferUnavailable = 0xffff
+)
+
+// A JitterBufferReportBlock reports the state of the de-jitter buffer of a
+// receiver. See RFC 7005
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|     BT=23     | I |C|  resv.  |      block length=3           |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|                        SSRC of source                         |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|          JB nominal           |          JB maximum           |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|          JB abs max           |           reserved            |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+type JitterBufferReportBlock struct {
+	// The period the Maximum delay covers
+	IntervalMetric IntervalMetric
+	// Whether the buffer adapts its delay, rather than having a fixed one
+	Adaptive bool
+	// SSRC of the media source the buffer plays out
+	SSRC uint32
+	// The current nominal delay, the maximum delay of packets during the
+	// reported period, and the upper limit of the buffer, in milliseconds
+	// or JitterBufferOverRange or JitterBufferUnavailable
+	Nominal         uint16
+	Maximum         uint16
+	AbsoluteMaximum uint16
+}
+
+var _ ReportBlock = (*JitterBufferReportBlock)(nil) // assert is a ReportBlock
+
+const (
+	jitterBufferBlockLength = 16
+
+	intervalMetricShift = 6
+	intervalMetricMask  = 0x3
+	jbAdaptiveShift     = 5
+)
+
+// BlockType returns BlockTypeJitterBuffer.
+func (b *JitterBufferReportBlock) BlockType() BlockType {
+	return BlockTypeJitterBuffer
+}
+
+// DestinationSSRC returns the SSRC of the media source.
+func (b *JitterBufferReportBlock) DestinationSSRC() []uint32 {
+	return []uint32{b.SSRC}
+}
+
+// Marshal encodes the block in binary
+func (b JitterBufferReportBlock) Marshal() ([]byte, error) {
+	if b.IntervalMetric > intervalMetricMask {
+		return nil, errBadIntervalMetric
+	}
+
+	typeSpecific := uint8(b.IntervalMetric) << intervalMetricShift
+	if b.Adaptive {
+		typeSpecific |= 1 << jbAdaptiveShift
+	}
+
+	rawBlock := make([]byte, jitterBufferBlockLength)
+	XRHeader{
+		BlockType:    BlockTypeJitterBuffer,
+		TypeSpecific: typeSpecific,
+		BlockLength:  jitterBufferBlockLength/4 - 1,
+	}.marshalTo(rawBlock)
+	binary.BigEndian.PutUint32(rawBlock[4:], b.SSRC)
+	binary.BigEndian.PutUint16(rawBlock[8:], b.Nominal)
+	binary.BigEndian.PutUint16(rawBlock[10:], b.Maximum)
+	binary.BigEndian.PutUint16(rawBlock[12:], b.AbsoluteMaximum)
+	return rawBlock, nil
+}
+
+// Unmarshal decodes the block from binary
+func (b *JitterBufferReportBlock) Unmarshal(rawBlock []byte) error {
+	if len(rawBlock) != jitterBufferBlockLength {
+		return errBadBlockLength
+	}
+	h, err := unmarshalXRHeader(rawBlock, BlockTypeJitterBuffer)
+	if err != nil {
+		return err
+	}
+	b.IntervalMetric = IntervalMetric(h.TypeSpecific >> intervalMetricShift & intervalMetricMask)
+	b.Adaptive = h.TypeSpecific>>jbAdaptiveShift&1 == 1
+	b.SSRC = binary.BigEndian.Uint32(rawBlock[4:])
+	b.Nominal = binary.BigEndian.Uint16(rawBlock[8:])
+	b.Maximum = binary.BigEndian.Uint16(rawBlock[10:])
+	b.AbsoluteMaximum = binary.BigEndian.Uint16(rawBlock[12:])
+	return nil
+}
@@ -0,0 +1,65 @@
+package rtcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJitterBufferReportBlock(t *testing.T) {
+	data := []byte{
+		// BT=23, I=sampled, C=adaptive, block length=3
+		0x17, 0xe0, 0x00, 0x03,
+		// ssrc=0x902f9e2e
+		0x90, 0x2f, 0x9e, 0x2e,
+		// nominal 40ms, maximum 120ms
+		0x00, 0x28, 0x00, 0x78,
+		// abs max unavailable
+		0xff, 0xff, 0x00, 0x00,
+	}
+	want := JitterBufferReportBlock{
+		IntervalMetric:  IntervalMetricSampled,
+		Adaptive:        true,
+		SSRC:            0x902f9e2e,
+		Nominal:         40,
+		Maximum:         120,
+		AbsoluteMaximum: JitterBufferUnavailable,
+	}
+
+	var b JitterBufferReportBlock
+	if err := b.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(b, want) {
+		t.Fatalf("Unmarshal: got %#v, want %#v", b, want)
+	}
+	got, err := b.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !reflect.DeepEqual(got, data) {
+		t.Fatalf("Marshal: got %x, want %x", got, data)
+	}
+
+	// a fixed buffer reporting on the interval
+	b = JitterBufferReportBlock{IntervalMetric: IntervalMetricInterval, SSRC: 1}
+	got, err = b.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if got[1] != 0x40 {
+		t.Fatalf("Marshal: got type specific %x, want 40", got[1])
+	}
+
+	b.IntervalMetric = 4
+	if _, err := b.Marshal(); err != errBadIntervalMetric {
+		t.Fatalf("Marshal: err = %v, want %v", err, errBadIntervalMetric)
+	}
+
+	var xr ExtendedReport
+	if err := xr.Unmarshal(append([]byte{0x80, 0xcf, 0x00, 0x05, 0, 0, 0, 1}, data...)); err != nil {
+		t.Fatalf("Unmarshal XR: %v", err)
+	}
+	if got, want := xr.Reports, []ReportBlock{&want}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Unmarshal XR: got %#v, want %#v", got, want)
+	}
+}
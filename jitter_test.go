@@ -0,0 +1,65 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterarrivalJitter(t *testing.T) {
+	assert := assert.New(t)
+
+	// packets sent every 20ms with an 8kHz clock, arriving alternately
+	// early and late by 1ms (8 timestamp units)
+	j := NewInterarrivalJitter(8000)
+	start := time.Unix(1000, 0)
+	for i := 0; i < 1000; i++ {
+		arrival := start.Add(time.Duration(i) * 20 * time.Millisecond)
+		if i%2 == 1 {
+			arrival = arrival.Add(time.Millisecond)
+		}
+		j.Update(uint32(i*160), arrival)
+	}
+
+	// each transit difference is 8 units, so the estimate converges on 8
+	assert.InDelta(8, float64(j.Jitter()), 1)
+	assert.InDelta(float64(time.Millisecond), float64(j.Duration()), float64(125*time.Microsecond))
+
+	j.Reset()
+	assert.Equal(uint32(0), j.Jitter())
+	assert.Equal(uint32(8000), j.ClockRate)
+}
+
+func TestInterarrivalJitterUpdateTimestamp(t *testing.T) {
+	assert := assert.New(t)
+
+	j := NewInterarrivalJitter(90000)
+	// the first packet only sets the transit time
+	j.UpdateTimestamp(1000, 5000)
+	assert.Equal(uint32(0), j.Jitter())
+
+	// transit difference of 160: J = 0 + (160 - 0)/16
+	j.UpdateTimestamp(4000, 8160)
+	assert.Equal(uint32(10), j.Jitter())
+
+	// no change in transit decays the estimate by 1/16
+	j.UpdateTimestamp(7000, 11160)
+	assert.Equal(uint32(9), j.Jitter())
+
+	// both clocks wrapping doesn't disturb the estimate
+	w := NewInterarrivalJitter(90000)
+	w.UpdateTimestamp(0xFFFFFF00, 0xFFFFFFF0)
+	w.UpdateTimestamp(0x00000100, 0x000001F0)
+	assert.Equal(uint32(0), w.Jitter())
+}
+
+func TestInterarrivalJitterLongRunning(t *testing.T) {
+	// a day of 90kHz timestamps would overflow a naive nanosecond product
+	j := NewInterarrivalJitter(90000)
+	start := time.Unix(0, 0)
+	j.Update(0, start)
+	day := 24 * time.Hour
+	j.Update(uint32(uint64(day/time.Second)*90000), start.Add(day))
+	assert.Equal(t, uint32(0), j.Jitter())
+}
@@ -0,0 +1,82 @@
+package rtcp
+
+import (
+	"container/heap"
+	"time"
+)
+
+// A lastSeenQueue orders SSRCs by when they were last seen, so tables
+// bounded in size find the one to evict in O(log n) rather than scanning
+// every entry, which a flood of spoofed SSRCs would make costly. The zero
+// value is an empty queue.
+type lastSeenQueue struct {
+	entries []lastSeenEntry
+	// index of each SSRC in entries
+	index map[uint32]int
+}
+
+type lastSeenEntry struct {
+	ssrc uint32
+	seen time.Time
+}
+
+// touch records that ssrc was seen at seen, adding it if it's new
+func (q *lastSeenQueue) touch(ssrc uint32, seen time.Time) {
+	if i, ok := q.index[ssrc]; ok {
+		q.entries[i].seen = seen
+		heap.Fix(q, i)
+		return
+	}
+	if q.index == nil {
+		q.index = make(map[uint32]int)
+	}
+	heap.Push(q, lastSeenEntry{ssrc: ssrc, seen: seen})
+}
+
+// remove drops ssrc from the queue, if it's in it
+func (q *lastSeenQueue) remove(ssrc uint32) {
+	if i, ok := q.index[ssrc]; ok {
+		heap.Remove(q, i)
+	}
+}
+
+// oldest returns the SSRC seen least recently, and false if the queue is
+// empty
+func (q *lastSeenQueue) oldest() (uint32, bool) {
+	if len(q.entries) == 0 {
+		return 0, false
+	}
+	return q.entries[0].ssrc, true
+}
+
+// Len implements heap.Interface.
+func (q *lastSeenQueue) Len() int {
+	return len(q.entries)
+}
+
+// Less implements heap.Interface.
+func (q *lastSeenQueue) Less(i, j int) bool {
+	return q.entries[i].seen.Before(q.entries[j].seen)
+}
+
+// Swap implements heap.Interface.
+func (q *lastSeenQueue) Swap(i, j int) {
+	q.entries[i], q.entries[j] = q.entries[j], q.entries[i]
+	q.index[q.entries[i].ssrc] = i
+	q.index[q.entries[j].ssrc] = j
+}
+
+// Push implements heap.Interface.
+func (q *lastSeenQueue) Push(x interface{}) {
+	e := x.(lastSeenEntry)
+	q.index[e.ssrc] = len(q.entries)
+	q.entries = append(q.entries, e)
+}
+
+// Pop implements heap.Interface.
+func (q *lastSeenQueue) Pop() interface{} {
+	e := q.entries[len(q.entries)-1]
+	q.entries = q.entries[:len(q.entries)-1]
+	delete(q.index, e.ssrc)
+	return e
+}
@@ -0,0 +1,41 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastSeenQueue(t *testing.T) {
+	assert := assert.New(t)
+
+	var q lastSeenQueue
+	_, ok := q.oldest()
+	assert.False(ok)
+
+	start := time.Unix(1000, 0)
+	for i := uint32(0); i < 100; i++ {
+		// seen in an order other than that of the SSRCs
+		q.touch(i, start.Add(time.Duration((i*37)%100)*time.Second))
+	}
+	oldest, _ := q.oldest()
+	assert.Equal(uint32(0), oldest)
+
+	// seeing an SSRC again moves it to the back
+	q.touch(0, start.Add(time.Hour))
+	oldest, _ = q.oldest()
+	assert.Equal(uint32(73), oldest) // 73*37 % 100 == 1
+
+	q.remove(73)
+	q.remove(1000)
+	oldest, _ = q.oldest()
+	assert.Equal(uint32(46), oldest) // 46*37 % 100 == 2
+	assert.Equal(99, q.Len())
+
+	for q.Len() > 0 {
+		ssrc, _ := q.oldest()
+		q.remove(ssrc)
+	}
+	assert.Empty(q.index)
+}
@@ -0,0 +1,98 @@
+package rtcp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// WithLenient makes a Decoder tolerate deviations from RFC 3550 that some
+// embedded devices, such as IP cameras, are known to send, which Unmarshal
+// rejects or CompoundPacket.Validate reports:
+//
+//	a length field one word more or less than the packet's actual length,
+//	  if the length of the datagram makes the intent unambiguous
+//	version 1 in the header
+//	a compound packet starting with a report but without a
+//	  SourceDescription carrying a CNAME
+//
+// Each deviation is reported by Decoder.Warnings instead of making Decode
+// fail, as an error wrapping errors such as the one Unmarshal would have
+// returned. Datagrams with other problems still fail to decode.
+func WithLenient() DecoderOption {
+	return func(d *Decoder) {
+		d.lenient = true
+	}
+}
+
+// lenientRepair corrects the headers of the packets in raw for the
+// deviations WithLenient tolerates, appending a warning to warnings for
+// each. raw isn't modified; a copy is returned if anything was repaired.
+func lenientRepair(raw []byte, warnings []error) ([]byte, []error) {
+	repaired := raw
+	copied := false
+	write := func() {
+		if !copied {
+			repaired = append([]byte(nil), raw...)
+			copied = true
+		}
+	}
+
+	for offset := 0; offset+headerLength <= len(repaired); {
+		if repaired[offset]>>versionShift&versionMask == 1 {
+			write()
+			repaired[offset] = repaired[offset]&^(versionMask<<versionShift) | rtpVersion<<versionShift
+			warnings = append(warnings, fmt.Errorf("%w: version 1 at offset %d", errBadVersion, offset))
+		}
+
+		var h Header
+		if err := h.Unmarshal(repaired[offset:]); err != nil {
+			break
+		}
+		size, rest := h.size(), len(repaired)-offset
+		switch {
+		case size == rest+4:
+			write()
+			binary.BigEndian.PutUint16(repaired[offset+2:], h.Length-1)
+			size = rest
+			warnings = append(warnings, fmt.Errorf("%w: one word too long at offset %d", errLengthOffByOne, offset))
+		case size+4 == rest && !h.Padding && !isHeaderOnlyPacket(repaired[offset+size:]):
+			write()
+			binary.BigEndian.PutUint16(repaired[offset+2:], h.Length+1)
+			size = rest
+			warnings = append(warnings, fmt.Errorf("%w: one word too short at offset %d", errLengthOffByOne, offset))
+		}
+		if size > rest {
+			break
+		}
+		offset += size
+	}
+	return repaired, warnings
+}
+
+// isHeaderOnlyPacket reports whether the 4 octets b are a valid packet of
+// just a header
+func isHeaderOnlyPacket(b []byte) bool {
+	var h Header
+	return h.Unmarshal(b) == nil && h.Length == 0
+}
+
+// hasCNAMEPacket reports whether packets start with a report and include a
+// SourceDescription with a CNAME, or don't start with a report at all, as
+// reduced-size RTCP doesn't
+func hasCNAMEPacket(packets []Packet) bool {
+	switch packets[0].(type) {
+	case *SenderReport, *ReceiverReport:
+	default:
+		return true
+	}
+	for _, p := range packets {
+		if sd, ok := p.(*SourceDescription); ok {
+			for _, c := range sd.Chunks {
+				if hasCNAME(c) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package rtcp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoderLenient(t *testing.T) {
+	assert := assert.New(t)
+
+	rr := &ReceiverReport{SSRC: 0x902f9e2e}
+	sdes := &SourceDescription{Chunks: []SourceDescriptionChunk{{
+		Source: 0x902f9e2e,
+		Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: "cname"}},
+	}}}
+	compound, err := Marshal([]Packet{rr, sdes})
+	assert.NoError(err)
+	pli, err := (&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}).Marshal()
+	assert.NoError(err)
+	reportOnly, err := Unmarshal(compound[:8])
+	assert.NoError(err)
+
+	for _, test := range []struct {
+		Name    string
+		Data    []byte
+		Want    []Packet
+		Warning error
+	}{
+		{
+			Name:    "version 1",
+			Data:    append([]byte{0x41}, pli[1:]...),
+			Want:    []Packet{&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}},
+			Warning: errBadVersion,
+		},
+		{
+			Name:    "length one word too long",
+			Data:    append([]byte{0x81, 0xce, 0x00, 0x03}, pli[4:]...),
+			Want:    []Packet{&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}},
+			Warning: errLengthOffByOne,
+		},
+		{
+			Name:    "length one word too short",
+			Data:    append([]byte{0x81, 0xce, 0x00, 0x01}, pli[4:]...),
+			Want:    []Packet{&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}},
+			Warning: errLengthOffByOne,
+		},
+		{
+			Name:    "missing cname",
+			Data:    compound[:8],
+			Want:    reportOnly,
+			Warning: errMissingCNAME,
+		},
+	} {
+		_, err := Unmarshal(test.Data)
+		if test.Warning != errMissingCNAME {
+			assert.Error(err, test.Name)
+		}
+
+		d := NewDecoder(WithLenient())
+		got, err := d.Decode(test.Data, nil)
+		assert.NoError(err, test.Name)
+		assert.Equal(test.Want, got, test.Name)
+		assert.Len(d.Warnings(), 1, test.Name)
+		assert.True(errors.Is(d.Warnings()[0], test.Warning), test.Name)
+	}
+
+	// a compliant datagram has no warnings
+	d := NewDecoder(WithLenient())
+	got, err := d.Decode(compound, nil)
+	assert.NoError(err)
+	assert.Len(got, 2)
+	assert.Empty(d.Warnings())
+
+	// without the option nothing is tolerated
+	d = NewDecoder()
+	_, err = d.Decode(append([]byte{0x41}, pli[1:]...), nil)
+	assert.Equal(errBadVersion, err)
+}
@@ -0,0 +1,114 @@
+package rtcp
+
+import (
+	"sync"
+	"time"
+)
+
+// A Logger receives the diagnostics of the stateful types of this package,
+// such as the Recorder, TransportLayerCCHistory, MemberTable and Decoder.
+// keyvals alternate keys and values, as in most structured loggers:
+//
+//	l.Warn("rtcp: SSRC collision", "ssrc", ssrc)
+//
+// Loggers are shared by these types, so they must be safe for concurrent
+// use.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+}
+
+// NopLogger is the Logger used when none is configured. It discards
+// everything.
+var NopLogger Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Warn(string, ...interface{})  {}
+
+// loggerOr returns l, falling back to NopLogger if l is nil
+func loggerOr(l Logger) Logger {
+	if l == nil {
+		return NopLogger
+	}
+	return l
+}
+
+// WithLogger makes a Decoder log the datagrams it fails to decode, at debug
+// level, and the deviations tolerated WithLenient, as warnings. Peers
+// control both, so l is usually a RateLimitedLogger.
+func WithLogger(l Logger) DecoderOption {
+	return func(d *Decoder) {
+		d.logger = l
+	}
+}
+
+// A RateLimitedLogger is a Logger that passes each message to Logger at most
+// once per Interval, so a misbehaving peer can't flood the logs. Messages
+// are told apart by msg alone. The first message after a quiet period is
+// logged with a "suppressed" count of the ones dropped since the previous.
+type RateLimitedLogger struct {
+	Logger Logger
+	// The minimum time between two messages with the same msg
+	Interval time.Duration
+	// The source of the current time. If nil, SystemClock is used.
+	Clock Clock
+
+	mu   sync.Mutex
+	msgs map[string]*rateLimitedMessage
+}
+
+type rateLimitedMessage struct {
+	last       time.Time
+	suppressed int
+}
+
+var _ Logger = (*RateLimitedLogger)(nil) // assert is a Logger
+
+// NewRateLimitedLogger wraps l, passing each message at most once per
+// interval.
+func NewRateLimitedLogger(l Logger, interval time.Duration) *RateLimitedLogger {
+	return &RateLimitedLogger{Logger: l, Interval: interval}
+}
+
+// Debug passes msg to Logger.Debug unless it was logged within Interval.
+func (r *RateLimitedLogger) Debug(msg string, keyvals ...interface{}) {
+	if keyvals, ok := r.allow(msg, keyvals); ok {
+		loggerOr(r.Logger).Debug(msg, keyvals...)
+	}
+}
+
+// Warn passes msg to Logger.Warn unless it was logged within Interval.
+func (r *RateLimitedLogger) Warn(msg string, keyvals ...interface{}) {
+	if keyvals, ok := r.allow(msg, keyvals); ok {
+		loggerOr(r.Logger).Warn(msg, keyvals...)
+	}
+}
+
+// allow reports whether msg may be logged now, adding the suppressed count to
+// keyvals
+func (r *RateLimitedLogger) allow(msg string, keyvals []interface{}) ([]interface{}, bool) {
+	now := clockNow(r.Clock)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.msgs == nil {
+		r.msgs = map[string]*rateLimitedMessage{}
+	}
+	m, ok := r.msgs[msg]
+	if !ok {
+		r.msgs[msg] = &rateLimitedMessage{last: now}
+		return keyvals, true
+	}
+	if now.Sub(m.last) < r.Interval {
+		m.suppressed++
+		return nil, false
+	}
+
+	if m.suppressed > 0 {
+		keyvals = append(keyvals[:len(keyvals):len(keyvals)], "suppressed", m.suppressed)
+	}
+	m.last, m.suppressed = now, 0
+	return keyvals, true
+}
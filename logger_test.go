@@ -0,0 +1,114 @@
+package rtcp
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *recordingLogger) log(level, msg string, keyvals []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, strings.TrimSpace(fmt.Sprintln(append([]interface{}{level, msg}, keyvals...)...)))
+}
+
+func (l *recordingLogger) Debug(msg string, keyvals ...interface{}) { l.log("debug", msg, keyvals) }
+func (l *recordingLogger) Warn(msg string, keyvals ...interface{})  { l.log("warn", msg, keyvals) }
+
+func TestRateLimitedLogger(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(100, 0)
+	l := &recordingLogger{}
+	r := NewRateLimitedLogger(l, time.Second)
+	r.Clock = ClockFunc(func() time.Time { return now })
+
+	r.Warn("a", "n", 1)
+	r.Warn("a", "n", 2)
+	r.Debug("b")
+	r.Debug("a", "n", 3)
+	now = now.Add(time.Second)
+	r.Warn("a", "n", 4)
+	r.Warn("a", "n", 5)
+	now = now.Add(time.Second)
+	r.Debug("b")
+	assert.Equal([]string{
+		"warn a n 1",
+		"debug b",
+		"warn a n 4 suppressed 2",
+		"debug b",
+	}, l.lines)
+
+	// without a Logger, messages are dropped
+	r.Logger = nil
+	now = now.Add(time.Second)
+	r.Warn("a")
+	assert.Len(l.lines, 4)
+}
+
+func TestLoggerSubsystems(t *testing.T) {
+	if !debugChecks {
+		t.Skip("debug logging is compiled out by rtcp_nodebug")
+	}
+
+	assert := assert.New(t)
+
+	l := &recordingLogger{}
+	m := NewMemberTable(1)
+	m.Logger = l
+	m.MaxMembers = 1
+	m.Update([]Packet{&ReceiverReport{SSRC: 1}, &ReceiverReport{SSRC: 2}, &ReceiverReport{SSRC: 3}})
+	assert.Equal([]string{
+		"warn rtcp: SSRC collision ssrc 1",
+		"debug rtcp: member table full, evicting member ssrc 2",
+	}, l.lines)
+
+	l.lines = nil
+	h := NewTransportLayerCCHistory()
+	h.Logger = l
+	h.MaxPackets = 1
+	h.OnSent(1, 100, time.Unix(0, 0))
+	h.OnSent(2, 100, time.Unix(0, 0))
+	h.OnFeedback(&TransportLayerCC{FbPktCount: 0})
+	h.OnFeedback(&TransportLayerCC{FbPktCount: 2})
+	assert.Equal([]string{
+		"debug rtcp: history evicting packet without feedback seq 1 held 2",
+		"debug rtcp: feedback lost missing 1 fbPktCount 2",
+	}, l.lines)
+
+	// nil Loggers are fine
+	m.Logger, h.Logger = nil, nil
+	m.Update([]Packet{&ReceiverReport{SSRC: 1}})
+	h.OnSent(3, 100, time.Unix(0, 0))
+	assert.Len(l.lines, 2)
+}
+
+func TestDecoderLogger(t *testing.T) {
+	if !debugChecks {
+		t.Skip("debug logging is compiled out by rtcp_nodebug")
+	}
+
+	assert := assert.New(t)
+
+	pli, err := (&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}).Marshal()
+	assert.NoError(err)
+
+	l := &recordingLogger{}
+	d := NewDecoder(WithLenient(), WithLogger(l))
+	_, err = d.Decode(append([]byte{0x41}, pli[1:]...), nil)
+	assert.NoError(err)
+	_, err = d.Decode(pli[:8], nil)
+	assert.Error(err)
+	assert.Len(l.lines, 3)
+	assert.Contains(l.lines[0], "warn rtcp: tolerated malformed datagram warning "+errBadVersion.Error())
+	assert.Equal("debug rtcp: datagram failed to decode error "+errPacketTooShort.Error()+" size 8", l.lines[2])
+}
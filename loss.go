@@ -0,0 +1,47 @@
+package rtcp
+
+const (
+	maxCumulativeLost = 1<<23 - 1
+	minCumulativeLost = -1 << 23
+)
+
+// FractionLost returns the fraction of packets lost over a reporting
+// interval, as carried in ReceptionReport.FractionLost: an 8 bit fixed point
+// number with the binary point at the left edge. expected and received are
+// the packets expected and received during the interval; when duplicates
+// make received exceed expected, the fraction is 0, and when every packet
+// was lost it is 255. See RFC 3550, A.3
+func FractionLost(expected, received uint32) uint8 {
+	if expected == 0 || received >= expected {
+		return 0
+	}
+	lost := uint64(expected - received)
+	fraction := lost << 8 / uint64(expected)
+	// losing every packet would be 256/256, which doesn't fit
+	if fraction > 0xFF {
+		return 0xFF
+	}
+	return uint8(fraction)
+}
+
+// CumulativeLost returns the number of packets lost since the beginning of
+// reception, in the 24 bit two's complement encoding of
+// ReceptionReport.TotalLost. expected and received are counted since the
+// beginning of reception. Duplicates can make the count negative; it is
+// clamped to the range of 24 bits. See RFC 3550, A.3
+func CumulativeLost(expected, received uint32) uint32 {
+	lost := int64(expected) - int64(received)
+	if lost > maxCumulativeLost {
+		lost = maxCumulativeLost
+	} else if lost < minCumulativeLost {
+		lost = minCumulativeLost
+	}
+	return uint32(lost) & 0xFFFFFF
+}
+
+// DecodeCumulativeLost returns the signed number of packets lost carried in
+// ReceptionReport.TotalLost.
+func DecodeCumulativeLost(totalLost uint32) int32 {
+	// sign extend from 24 bits
+	return int32(totalLost<<8) >> 8
+}
@@ -0,0 +1,57 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFractionLost(t *testing.T) {
+	assert := assert.New(t)
+	for _, test := range []struct {
+		Expected, Received uint32
+		Want               uint8
+	}{
+		{0, 0, 0},
+		{100, 100, 0},
+		{100, 75, 64},
+		{100, 0, 255},
+		{4, 3, 64},
+		{3, 2, 85},
+		// duplicates
+		{100, 110, 0},
+		{0xFFFFFFFF, 0, 255},
+	} {
+		assert.Equalf(test.Want, FractionLost(test.Expected, test.Received), "expected %d received %d", test.Expected, test.Received)
+	}
+}
+
+func TestCumulativeLost(t *testing.T) {
+	assert := assert.New(t)
+	for _, test := range []struct {
+		Expected, Received uint32
+		Want               uint32
+		Decoded            int32
+	}{
+		{0, 0, 0, 0},
+		{100, 90, 10, 10},
+		// duplicates make the count negative
+		{100, 101, 0xFFFFFF, -1},
+		{100, 200, 0xFFFF9C, -100},
+		// clamped to 24 bits
+		{1 << 24, 0, 0x7FFFFF, 0x7FFFFF},
+		{0, 1 << 24, 0x800000, -0x800000},
+	} {
+		got := CumulativeLost(test.Expected, test.Received)
+		assert.Equalf(test.Want, got, "expected %d received %d", test.Expected, test.Received)
+		assert.Equalf(test.Decoded, DecodeCumulativeLost(got), "decode %#x", got)
+	}
+
+	// the encoding fits ReceptionReport.TotalLost
+	r := ReceptionReport{TotalLost: CumulativeLost(0, 1<<24)}
+	data, err := r.Marshal()
+	assert.NoError(err)
+	var got ReceptionReport
+	assert.NoError(got.Unmarshal(data))
+	assert.Equal(int32(minCumulativeLost), DecodeCumulativeLost(got.TotalLost))
+}
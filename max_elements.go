@@ -0,0 +1,65 @@
+package rtcp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// WithMaxElements makes a Decoder reject packets announcing more than max
+// elements, with an error wrapping errTooManyElements, before allocating
+// storage for them. The elements are the packet statuses of
+// TransportLayerCC feedback, the reports of SenderReports and
+// ReceiverReports, the chunks of SourceDescriptions, the sources of Goodbyes
+// and ReceiverEstimatedMaximumBitrate feedback, the NackPairs of
+// TransportLayerNacks and the entries of FullIntraRequests.
+//
+// Unmarshal checks every count against the length of the packet before
+// allocating for it, so a crafted count can't make a packet cost much more
+// than its size; the 16 bit PacketStatusCount of a TransportLayerCC is only
+// accepted when the packet holds a byte for each receive delta it announces.
+// A limit further bounds the work of servers that expect small packets. A
+// max of zero, the default, doesn't limit anything.
+func WithMaxElements(max int) DecoderOption {
+	return func(d *Decoder) {
+		d.maxElements = max
+	}
+}
+
+// checkElementCount fails if the packet at the start of raw announces more
+// than max elements, see WithMaxElements. Malformed packets are left for
+// Unmarshal to report.
+func checkElementCount(raw []byte, max int) error {
+	var h Header
+	if h.Unmarshal(raw) != nil || len(raw) < h.size() {
+		return nil
+	}
+
+	var n int
+	switch h.Type {
+	case TypeSenderReport, TypeReceiverReport, TypeSourceDescription, TypeGoodbye:
+		n = int(h.Count)
+	case TypeTransportSpecificFeedback:
+		switch h.Count {
+		case FormatTCC:
+			if h.size() >= headerLength+packetChunkOffset {
+				n = int(binary.BigEndian.Uint16(raw[headerLength+packetStatusCountOffset:]))
+			}
+		case FormatTLN:
+			n = (h.size() - headerLength - nackOffset) / 4
+		}
+	case TypePayloadSpecificFeedback:
+		switch h.Count {
+		case FormatFIR:
+			n = (h.size() - headerLength - firOffset) / firEntryLength
+		case FormatREMB:
+			if h.size() > 16 && string(raw[12:16]) == "REMB" {
+				n = int(raw[16])
+			}
+		}
+	}
+
+	if n > max {
+		return fmt.Errorf("%w: %d in %s packet, at most %d", errTooManyElements, n, h.Type, max)
+	}
+	return nil
+}
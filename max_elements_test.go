@@ -0,0 +1,53 @@
+package rtcp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoderMaxElements(t *testing.T) {
+	assert := assert.New(t)
+
+	hugeTCC := craftedTransportLayerCC
+	nack := &TransportLayerNack{SenderSSRC: 1, MediaSSRC: 2, Nacks: make([]NackPair, 5)}
+	remb := &ReceiverEstimatedMaximumBitrate{SenderSSRC: 1, SSRCs: make([]uint32, 5)}
+	rr := &ReceiverReport{SSRC: 1, Reports: make([]ReceptionReport, 5)}
+	fir := &FullIntraRequest{SenderSSRC: 1, FIR: make([]FIREntry, 5)}
+	bye := &Goodbye{Sources: make([]uint32, 5)}
+
+	d := NewDecoder(WithMaxElements(4))
+	for _, p := range []Packet{nack, remb, rr, fir, bye} {
+		raw, err := p.Marshal()
+		assert.NoError(err)
+		_, err = d.Decode(raw, nil)
+		assert.True(errors.Is(err, errTooManyElements), "%T: %v", p, err)
+
+		// within the limit
+		_, err = NewDecoder(WithMaxElements(5)).Decode(raw, nil)
+		assert.NoError(err, "%T", p)
+	}
+
+	_, err := d.Decode(hugeTCC, nil)
+	assert.True(errors.Is(err, errTooManyElements), err)
+
+	// without a limit, the packet is rejected as too short before
+	// storage for its deltas is allocated
+	_, err = NewDecoder().Decode(hugeTCC, nil)
+	assert.Equal(errPacketTooShort, err)
+	assert.True(allocatedBytes(100, func() {
+		_, _ = NewDecoder().Decode(hugeTCC, nil)
+	}) < 4096)
+
+	// packets without elements aren't limited
+	raw, err := (&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}).Marshal()
+	assert.NoError(err)
+	_, err = NewDecoder(WithMaxElements(1)).Decode(raw, nil)
+	assert.NoError(err)
+
+	// malformed packets fail as they would without a limit
+	_, err = d.Decode(realPacket[:10], nil)
+	_, wantErr := Unmarshal(realPacket[:10])
+	assert.Equal(wantErr, err)
+}
@@ -0,0 +1,120 @@
+package rtcp
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// A MeasurementInformationReportBlock describes the measurement period the
+// other metric blocks of an ExtendedReport cover. See RFC 6776
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|     BT=14     |    Reserved   |      block length = 7         |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|                       SSRC of source                          |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|            Reserved           |    first sequence number      |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|           extended first sequence number of interval          |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|                 extended last sequence number                 |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|              Measurement Duration (Interval)                  |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|           Measurement Duration (Cumulative) - Seconds         |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|           Measurement Duration (Cumulative) - Fraction        |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+type MeasurementInformationReportBlock struct {
+	// SSRC of the media source the measurement is for
+	SSRC uint32
+	// The sequence number of the first packet of the cumulative
+	// measurement period
+	FirstSequenceNumber uint16
+	// The extended sequence numbers of the first and last packet of the
+	// current interval
+	IntervalFirstSequence uint32
+	IntervalLastSequence  uint32
+	// The duration of the interval, in units of 1/65536 seconds
+	Interval uint32
+	// The duration of the cumulative measurement period, in the 64 bit NTP
+	// timestamp format
+	Cumulative uint64
+}
+
+var _ ReportBlock = (*MeasurementInformationReportBlock)(nil) // assert is a ReportBlock
+
+const (
+	measurementInformationBlockLength = 32
+
+	// one second in the units of the interval duration
+	intervalUnitsPerSecond = 1 << 16
+)
+
+// BlockType returns BlockTypeMeasurementInformation.
+func (b *MeasurementInformationReportBlock) BlockType() BlockType {
+	return BlockTypeMeasurementInformation
+}
+
+// DestinationSSRC returns the SSRC of the media source.
+func (b *MeasurementInformationReportBlock) DestinationSSRC() []uint32 {
+	return []uint32{b.SSRC}
+}
+
+// IntervalDuration returns Interval as a time.Duration.
+func (b MeasurementInformationReportBlock) IntervalDuration() time.Duration {
+	return time.Duration(uint64(b.Interval) * uint64(time.Second) / intervalUnitsPerSecond)
+}
+
+// SetIntervalDuration sets Interval from d, rounded down. d must be shorter
+// than 65536 seconds.
+func (b *MeasurementInformationReportBlock) SetIntervalDuration(d time.Duration) {
+	b.Interval = uint32(uint64(d) * intervalUnitsPerSecond / uint64(time.Second))
+}
+
+// CumulativeDuration returns Cumulative as a time.Duration.
+func (b MeasurementInformationReportBlock) CumulativeDuration() time.Duration {
+	seconds, fraction := b.Cumulative>>32, b.Cumulative&0xffffffff
+	return time.Duration(seconds)*time.Second + time.Duration(fraction*uint64(time.Second)>>32)
+}
+
+// SetCumulativeDuration sets Cumulative from d, rounded down.
+func (b *MeasurementInformationReportBlock) SetCumulativeDuration(d time.Duration) {
+	seconds, rest := d/time.Second, d%time.Second
+	b.Cumulative = uint64(seconds)<<32 | uint64(rest)<<32/uint64(time.Second)
+}
+
+// Marshal encodes the block in binary
+func (b MeasurementInformationReportBlock) Marshal() ([]byte, error) {
+	rawBlock := make([]byte, measurementInformationBlockLength)
+	XRHeader{
+		BlockType:   BlockTypeMeasurementInformation,
+		BlockLength: measurementInformationBlockLength/4 - 1,
+	}.marshalTo(rawBlock)
+	binary.BigEndian.PutUint32(rawBlock[4:], b.SSRC)
+	binary.BigEndian.PutUint16(rawBlock[10:], b.FirstSequenceNumber)
+	binary.BigEndian.PutUint32(rawBlock[12:], b.IntervalFirstSequence)
+	binary.BigEndian.PutUint32(rawBlock[16:], b.IntervalLastSequence)
+	binary.BigEndian.PutUint32(rawBlock[20:], b.Interval)
+	binary.BigEndian.PutUint64(rawBlock[24:], b.Cumulative)
+	return rawBlock, nil
+}
+
+// Unmarshal decodes the block from binary
+func (b *MeasurementInformationReportBlock) Unmarshal(rawBlock []byte) error {
+	if len(rawBlock) != measurementInformationBlockLength {
+		return errBadBlockLength
+	}
+	if _, err := unmarshalXRHeader(rawBlock, BlockTypeMeasurementInformation); err != nil {
+		return err
+	}
+	b.SSRC = binary.BigEndian.Uint32(rawBlock[4:])
+	b.FirstSequenceNumber = binary.BigEndian.Uint16(rawBlock[10:])
+	b.IntervalFirstSequence = binary.BigEndian.Uint32(rawBlock[12:])
+	b.IntervalLastSequence = binary.BigEndian.Uint32(rawBlock[16:])
+	b.Interval = binary.BigEndian.Uint32(rawBlock[20:])
+	b.Cumulative = binary.BigEndian.Uint64(rawBlock[24:])
+	return nil
+}
@@ -0,0 +1,68 @@
+package rtcp
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMeasurementInformationReportBlock(t *testing.T) {
+	data := []byte{
+		// BT=14, block length=7
+		0x0e, 0x00, 0x00, 0x07,
+		// ssrc=0x902f9e2e
+		0x90, 0x2f, 0x9e, 0x2e,
+		// first sequence number=0x1234
+		0x00, 0x00, 0x12, 0x34,
+		// interval from 0x11240 to 0x11300
+		0x00, 0x01, 0x12, 0x40,
+		0x00, 0x01, 0x13, 0x00,
+		// interval of 1.5s
+		0x00, 0x01, 0x80, 0x00,
+		// cumulative duration of 90.25s
+		0x00, 0x00, 0x00, 0x5a,
+		0x40, 0x00, 0x00, 0x00,
+	}
+	want := MeasurementInformationReportBlock{
+		SSRC:                  0x902f9e2e,
+		FirstSequenceNumber:   0x1234,
+		IntervalFirstSequence: 0x11240,
+		IntervalLastSequence:  0x11300,
+		Interval:              0x18000,
+		Cumulative:            0x5a40000000,
+	}
+
+	var b MeasurementInformationReportBlock
+	if err := b.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(b, want) {
+		t.Fatalf("Unmarshal: got %#v, want %#v", b, want)
+	}
+	if got, want := b.IntervalDuration(), 1500*time.Millisecond; got != want {
+		t.Fatalf("IntervalDuration: got %v, want %v", got, want)
+	}
+	if got, want := b.CumulativeDuration(), 90250*time.Millisecond; got != want {
+		t.Fatalf("CumulativeDuration: got %v, want %v", got, want)
+	}
+
+	got, err := b.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !reflect.DeepEqual(got, data) {
+		t.Fatalf("Marshal: got %x, want %x", got, data)
+	}
+
+	var set MeasurementInformationReportBlock
+	set.SetIntervalDuration(1500 * time.Millisecond)
+	set.SetCumulativeDuration(90250 * time.Millisecond)
+	if set.Interval != want.Interval || set.Cumulative != want.Cumulative {
+		t.Fatalf("SetIntervalDuration, SetCumulativeDuration: got %x, %x, want %x, %x", set.Interval, set.Cumulative, want.Interval, want.Cumulative)
+	}
+
+	data[0] = 0x0f
+	if err := b.Unmarshal(data); err != errWrongType {
+		t.Fatalf("Unmarshal wrong type: err = %v, want %v", err, errWrongType)
+	}
+}
@@ -0,0 +1,324 @@
+package rtcp
+
+import "time"
+
+const (
+	// DefaultMemberTimeout is the time after which a silent member is
+	// removed: five times the minimum RTCP interval. See RFC 3550, 6.3.5
+	DefaultMemberTimeout = 5 * DefaultMinInterval
+
+	// DefaultMaxMembers is the number of remote members a MemberTable tracks
+	// when its MaxMembers is zero.
+	DefaultMaxMembers = 1 << 16
+
+	// DefaultSenderTimeout is the time after which a member that stopped
+	// sending RTP is a receiver again: two minimum RTCP intervals. See RFC
+	// 3550, 6.3.8
+	DefaultSenderTimeout = 2 * DefaultMinInterval
+)
+
+// A Member is a participant in an RTP session, identified by its SSRC.
+type Member struct {
+	SSRC uint32
+	// The CNAME announced by the member, if any
+	CNAME string
+	// Whether the member is an active sender: it sent RTP or a
+	// SenderReport within the SenderTimeout of the MemberTable, and its last
+	// report wasn't a ReceiverReport
+	Sender bool
+	// When a packet from this member was last seen
+	LastSeen time.Time
+	// When RTP or a SenderReport from this member was last seen
+	LastSent time.Time
+}
+
+// A MemberTable tracks the participants of an RTP session from the RTCP
+// packets they send, as described in RFC 3550, 6.3
+//
+// A MemberTable isn't safe for concurrent use; see SyncMemberTable.
+type MemberTable struct {
+	// The SSRC of the local participant
+	LocalSSRC uint32
+	// How long a member may be silent before it's removed. If zero,
+	// DefaultMemberTimeout is used.
+	Timeout time.Duration
+	// The source of the current time. If nil, SystemClock is used.
+	Clock Clock
+	// The maximum number of remote members tracked. Once reached, the
+	// member silent for the longest time is evicted to make room for a new
+	// one. If zero, DefaultMaxMembers is used.
+	MaxMembers int
+	// How long a member, or the local participant, is an active sender
+	// after it last sent RTP or a SenderReport. RFC 3550 defines it as two
+	// deterministic report intervals, see Scheduler.DeterministicInterval.
+	// If zero, DefaultSenderTimeout is used.
+	SenderTimeout time.Duration
+	// Logger receives SSRC collisions and evictions. If nil, NopLogger is
+	// used.
+	Logger Logger
+
+	members   map[uint32]*Member
+	lastSeen  lastSeenQueue
+	senders   int
+	evictions uint64
+	localSent time.Time
+}
+
+var _ MemberCounter = (*MemberTable)(nil) // assert is a MemberCounter
+
+// NewMemberTable creates an empty MemberTable for the local participant.
+func NewMemberTable(localSSRC uint32) *MemberTable {
+	return &MemberTable{
+		LocalSSRC: localSSRC,
+		members:   map[uint32]*Member{},
+	}
+}
+
+// Update records the members referenced by a list of received packets. It
+// reports whether any packet claims to be sent by LocalSSRC, which means
+// another participant collided with the local SSRC.
+func (m *MemberTable) Update(packets []Packet) (collision bool) {
+	if m.members == nil {
+		m.members = map[uint32]*Member{}
+	}
+	now := clockNow(m.Clock)
+
+	for _, p := range packets {
+		if m.update(p, now) {
+			collision = true
+		}
+	}
+	return collision
+}
+
+// UpdateReceived records the members referenced by received packets like
+// Update, but as seen at their arrival times rather than the time of Clock.
+func (m *MemberTable) UpdateReceived(packets []ReceivedPacket) (collision bool) {
+	if m.members == nil {
+		m.members = map[uint32]*Member{}
+	}
+
+	for _, rp := range packets {
+		if m.update(rp.Packet, rp.ArrivalTime) {
+			collision = true
+		}
+	}
+	return collision
+}
+
+func (m *MemberTable) update(packet Packet, now time.Time) (collision bool) {
+	switch p := packet.(type) {
+	case *CompoundPacket:
+		for _, inner := range *p {
+			if m.update(inner, now) {
+				collision = true
+			}
+		}
+		return collision
+	case *SenderReport:
+		return m.touch(p.SSRC, now, func(mb *Member) {
+			mb.LastSent = now
+			m.setSender(mb, true)
+		})
+	case *ReceiverReport:
+		return m.touch(p.SSRC, now, func(mb *Member) { m.setSender(mb, false) })
+	case *SourceDescription:
+		for _, chunk := range p.Chunks {
+			if m.touch(chunk.Source, now, func(mb *Member) {
+				for _, item := range chunk.Items {
+					if item.Type == SDESCNAME {
+						mb.CNAME = item.Text
+					}
+				}
+			}) {
+				collision = true
+			}
+		}
+		return collision
+	case *Goodbye:
+		for _, ssrc := range p.Sources {
+			if ssrc == m.LocalSSRC {
+				loggerOr(m.Logger).Warn("rtcp: goodbye for the local SSRC", "ssrc", ssrc)
+				collision = true
+				continue
+			}
+			m.remove(ssrc)
+		}
+		return collision
+	case *TransportLayerNack:
+		return m.touch(p.SenderSSRC, now, nil)
+	case *TransportLayerCC:
+		return m.touch(p.SenderSSRC, now, nil)
+	case *CCFeedbackReport:
+		return m.touch(p.SenderSSRC, now, nil)
+	case *RapidResynchronizationRequest:
+		return m.touch(p.SenderSSRC, now, nil)
+	case *PictureLossIndication:
+		return m.touch(p.SenderSSRC, now, nil)
+	case *FullIntraRequest:
+		return m.touch(p.SenderSSRC, now, nil)
+	case *SliceLossIndication:
+		return m.touch(p.SenderSSRC, now, nil)
+	case *ReceiverEstimatedMaximumBitrate:
+		return m.touch(p.SenderSSRC, now, nil)
+	case *ExtendedReport:
+		return m.touch(p.SenderSSRC, now, nil)
+	}
+	return false
+}
+
+func (m *MemberTable) touch(ssrc uint32, now time.Time, fn func(*Member)) (collision bool) {
+	if ssrc == m.LocalSSRC {
+		loggerOr(m.Logger).Warn("rtcp: SSRC collision", "ssrc", ssrc)
+		return true
+	}
+
+	mb, ok := m.members[ssrc]
+	if !ok {
+		m.makeRoom()
+		mb = &Member{SSRC: ssrc}
+		m.members[ssrc] = mb
+	}
+	mb.LastSeen = now
+	m.lastSeen.touch(ssrc, now)
+	if fn != nil {
+		fn(mb)
+	}
+	return false
+}
+
+// OnRTP records an RTP packet received from ssrc, which makes it an active
+// sender. Like Update, it reports whether ssrc is LocalSSRC.
+func (m *MemberTable) OnRTP(ssrc uint32) (collision bool) {
+	if m.members == nil {
+		m.members = map[uint32]*Member{}
+	}
+	now := clockNow(m.Clock)
+	return m.touch(ssrc, now, func(mb *Member) {
+		mb.LastSent = now
+		m.setSender(mb, true)
+	})
+}
+
+// OnLocalRTP records that the local participant sent an RTP packet.
+func (m *MemberTable) OnLocalRTP() {
+	m.localSent = clockNow(m.Clock)
+}
+
+// LocalSender reports whether the local participant sent RTP within the
+// SenderTimeout. A Scheduler whose Membership is the MemberTable counts the
+// local participant as a sender then, as if its WeSent was set.
+func (m *MemberTable) LocalSender() bool {
+	return !m.localSent.IsZero() && !m.localSent.Before(clockNow(m.Clock).Add(-m.senderTimeout()))
+}
+
+func (m *MemberTable) senderTimeout() time.Duration {
+	if m.SenderTimeout == 0 {
+		return DefaultSenderTimeout
+	}
+	return m.SenderTimeout
+}
+
+// ExpireSenders makes the active senders that didn't send RTP or a
+// SenderReport within the SenderTimeout receivers, and returns their SSRCs.
+// It should be called at every report interval, with Expire.
+func (m *MemberTable) ExpireSenders() []uint32 {
+	cutoff := clockNow(m.Clock).Add(-m.senderTimeout())
+
+	var out []uint32
+	for ssrc, mb := range m.members {
+		if mb.Sender && mb.LastSent.Before(cutoff) {
+			m.setSender(mb, false)
+			out = append(out, ssrc)
+		}
+	}
+	return out
+}
+
+// makeRoom evicts the least recently seen members until another fits
+func (m *MemberTable) makeRoom() {
+	maxMembers := m.MaxMembers
+	if maxMembers <= 0 {
+		maxMembers = DefaultMaxMembers
+	}
+	for len(m.members) >= maxMembers {
+		oldest, ok := m.lastSeen.oldest()
+		if !ok {
+			return
+		}
+		if debugChecks {
+			loggerOr(m.Logger).Debug("rtcp: member table full, evicting member", "ssrc", oldest)
+		}
+		m.remove(oldest)
+		m.evictions++
+	}
+}
+
+// Evictions returns the number of members removed to stay within MaxMembers.
+func (m *MemberTable) Evictions() uint64 {
+	return m.evictions
+}
+
+func (m *MemberTable) setSender(mb *Member, sender bool) {
+	if mb.Sender == sender {
+		return
+	}
+	mb.Sender = sender
+	if sender {
+		m.senders++
+	} else {
+		m.senders--
+	}
+}
+
+func (m *MemberTable) remove(ssrc uint32) {
+	mb, ok := m.members[ssrc]
+	if !ok {
+		return
+	}
+	if mb.Sender {
+		m.senders--
+	}
+	delete(m.members, ssrc)
+	m.lastSeen.remove(ssrc)
+}
+
+// Expire removes the members that have been silent for longer than Timeout
+// and returns their SSRCs.
+func (m *MemberTable) Expire() []uint32 {
+	timeout := m.Timeout
+	if timeout == 0 {
+		timeout = DefaultMemberTimeout
+	}
+	cutoff := clockNow(m.Clock).Add(-timeout)
+
+	var out []uint32
+	for ssrc, mb := range m.members {
+		if mb.LastSeen.Before(cutoff) {
+			out = append(out, ssrc)
+		}
+	}
+	for _, ssrc := range out {
+		m.remove(ssrc)
+	}
+	return out
+}
+
+// Member returns the member with the given SSRC.
+func (m *MemberTable) Member(ssrc uint32) (Member, bool) {
+	mb, ok := m.members[ssrc]
+	if !ok {
+		return Member{}, false
+	}
+	return *mb, true
+}
+
+// Members returns the number of members, including the local participant.
+func (m *MemberTable) Members() int {
+	return len(m.members) + 1
+}
+
+// Senders returns the number of remote members that are active senders.
+func (m *MemberTable) Senders() int {
+	return m.senders
+}
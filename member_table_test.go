@@ -0,0 +1,174 @@
+package rtcp
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemberTable(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(100, 0)
+	m := NewMemberTable(1)
+	m.Clock = ClockFunc(func() time.Time { return now })
+
+	assert.Equal(1, m.Members())
+	assert.Equal(0, m.Senders())
+
+	collision := m.Update([]Packet{
+		&CompoundPacket{
+			&SenderReport{SSRC: 2},
+			&SourceDescription{Chunks: []SourceDescriptionChunk{{
+				Source: 2,
+				Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: "two"}},
+			}}},
+		},
+		&ReceiverReport{SSRC: 3},
+		&PictureLossIndication{SenderSSRC: 4, MediaSSRC: 1},
+	})
+	assert.False(collision)
+	assert.Equal(4, m.Members())
+	assert.Equal(1, m.Senders())
+
+	mb, ok := m.Member(2)
+	assert.True(ok)
+	assert.Equal(Member{SSRC: 2, CNAME: "two", Sender: true, LastSeen: now, LastSent: now}, mb)
+
+	// 2 stops sending
+	m.Update([]Packet{&ReceiverReport{SSRC: 2}})
+	assert.Equal(0, m.Senders())
+
+	// 3 leaves
+	m.Update([]Packet{&SenderReport{SSRC: 3}, &Goodbye{Sources: []uint32{3}}})
+	assert.Equal(3, m.Members())
+	assert.Equal(0, m.Senders())
+	_, ok = m.Member(3)
+	assert.False(ok)
+
+	// 4 keeps talking, 2 goes silent
+	now = now.Add(DefaultMemberTimeout)
+	m.Update([]Packet{&SenderReport{SSRC: 4}})
+	now = now.Add(time.Second)
+	assert.Equal([]uint32{2}, m.Expire())
+	assert.Equal(2, m.Members())
+	assert.Equal(1, m.Senders())
+
+	now = now.Add(DefaultMemberTimeout)
+	assert.Equal([]uint32{4}, m.Expire())
+	assert.Equal(0, m.Senders())
+}
+
+func TestMemberTableCollision(t *testing.T) {
+	now := time.Unix(100, 0)
+	m := NewMemberTable(1)
+	m.Clock = ClockFunc(func() time.Time { return now })
+
+	assert.True(t, m.Update([]Packet{&ReceiverReport{SSRC: 2}, &SenderReport{SSRC: 1}}))
+	assert.True(t, m.Update([]Packet{&Goodbye{Sources: []uint32{1}}}))
+	assert.False(t, m.Update([]Packet{&TransportLayerNack{SenderSSRC: 3, MediaSSRC: 1}}))
+
+	m.Timeout = time.Second
+	now = now.Add(2 * time.Second)
+	expired := m.Expire()
+	sort.Slice(expired, func(i, j int) bool { return expired[i] < expired[j] })
+	assert.Equal(t, []uint32{2, 3}, expired)
+}
+
+func TestMemberTableMaxMembers(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(100, 0)
+	m := NewMemberTable(1)
+	m.Clock = ClockFunc(func() time.Time { return now })
+	m.MaxMembers = 2
+
+	m.Update([]Packet{&SenderReport{SSRC: 2}})
+	now = now.Add(time.Second)
+	m.Update([]Packet{&ReceiverReport{SSRC: 3}})
+	now = now.Add(time.Second)
+	m.Update([]Packet{&ReceiverReport{SSRC: 4}})
+
+	// 2 was silent the longest
+	assert.Equal(3, m.Members())
+	assert.Equal(0, m.Senders())
+	assert.Equal(uint64(1), m.Evictions())
+	_, ok := m.Member(2)
+	assert.False(ok)
+
+	// known members don't evict anyone
+	m.Update([]Packet{&ReceiverReport{SSRC: 3}})
+	assert.Equal(uint64(1), m.Evictions())
+
+	// 3 was seen again, so 4 is evicted next
+	now = now.Add(time.Second)
+	m.Update([]Packet{&ReceiverReport{SSRC: 3}})
+	m.Update([]Packet{&ReceiverReport{SSRC: 5}})
+	_, ok = m.Member(3)
+	assert.True(ok)
+	_, ok = m.Member(4)
+	assert.False(ok)
+
+	// a flood of new SSRCs leaves the most recent ones
+	m.MaxMembers = 100
+	for i := uint32(0); i < 10000; i++ {
+		now = now.Add(time.Millisecond)
+		m.Update([]Packet{&ReceiverReport{SSRC: 1000 + i}})
+	}
+	assert.Equal(101, m.Members())
+	assert.Equal(uint64(2+9902), m.Evictions())
+	_, ok = m.Member(1000 + 9899)
+	assert.False(ok)
+	_, ok = m.Member(1000 + 9900)
+	assert.True(ok)
+
+	// expired and removed members leave the queue as well
+	now = now.Add(DefaultMemberTimeout + time.Second)
+	assert.Len(m.Expire(), 100)
+	assert.Equal(0, m.lastSeen.Len())
+}
+
+func TestMemberTableSenders(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(100, 0)
+	m := NewMemberTable(1)
+	m.Clock = ClockFunc(func() time.Time { return now })
+
+	assert.False(m.OnRTP(2))
+	m.Update([]Packet{&SenderReport{SSRC: 3}, &ReceiverReport{SSRC: 4}})
+	assert.Equal(4, m.Members())
+	assert.Equal(2, m.Senders())
+	assert.False(m.LocalSender())
+	assert.True(m.OnRTP(1))
+
+	m.OnLocalRTP()
+	assert.True(m.LocalSender())
+
+	// 2 keeps sending RTP, and 4 starts; 3 only sends ReceiverReports
+	now = now.Add(DefaultSenderTimeout / 2)
+	m.OnRTP(2)
+	m.OnRTP(4)
+	m.Update([]Packet{&ReceiverReport{SSRC: 3}})
+	assert.Equal(2, m.Senders())
+	assert.Empty(m.ExpireSenders())
+
+	// a ReceiverReport from 4 means it stopped sending; 2 and the local
+	// participant didn't send for two intervals
+	m.Update([]Packet{&ReceiverReport{SSRC: 4}})
+	assert.Equal(1, m.Senders())
+	now = now.Add(DefaultSenderTimeout + time.Second)
+	assert.Equal([]uint32{2}, m.ExpireSenders())
+	assert.Equal(0, m.Senders())
+	assert.False(m.LocalSender())
+	assert.Equal(4, m.Members())
+
+	m.SenderTimeout = time.Minute
+	m.OnRTP(2)
+	now = now.Add(30 * time.Second)
+	assert.Empty(m.ExpireSenders())
+	now = now.Add(31 * time.Second)
+	assert.Equal([]uint32{2}, m.ExpireSenders())
+}
@@ -0,0 +1,72 @@
+package rtcp
+
+const (
+	// DefaultMTU is the datagram size MTUBudget assumes when its MTU is
+	// zero. It's the conservative size WebRTC stacks use, which passes
+	// tunnels and VPNs that lower the 1500 byte Ethernet MTU.
+	DefaultMTU = 1200
+
+	// IPv6PacketOverhead is the size of the IPv6 and UDP headers, see
+	// DefaultPacketOverhead for IPv4.
+	IPv6PacketOverhead = 40 + 8
+)
+
+// An MTUBudget computes how many bytes of RTCP fit one datagram on a path:
+// the MTU, less the IP and UDP headers, and less the SRTCP index and
+// authentication tag if the session is protected. Use Size as the maxSize
+// of CompoundBuilder.Fit and SourceDescriptionBuilder.Build, and
+// ReceptionReports to decide how many report blocks a report carries.
+type MTUBudget struct {
+	// MTU of the path in bytes, including the IP header. If zero,
+	// DefaultMTU is used.
+	MTU int
+	// The size of the IP and UDP headers. If zero, DefaultPacketOverhead is
+	// used, which is right for IPv4; IPv6 needs IPv6PacketOverhead.
+	Overhead int
+	// Whether the packets are protected with SRTCP, and the length of the
+	// negotiated authentication tag, see SRTCPOverhead.
+	SRTCP         bool
+	AuthTagLength int
+}
+
+// Size returns the number of bytes of RTCP that fit one datagram, which is
+// zero or less if the headers alone exceed the MTU.
+func (b MTUBudget) Size() int {
+	mtu, overhead := b.MTU, b.Overhead
+	if mtu == 0 {
+		mtu = DefaultMTU
+	}
+	if overhead == 0 {
+		overhead = DefaultPacketOverhead
+	}
+	size := mtu - overhead
+	if b.SRTCP {
+		size -= SRTCPOverhead(b.AuthTagLength)
+	}
+	return size
+}
+
+// Remaining returns the number of bytes still available in a datagram
+// once packets are in it, which is negative if they don't fit.
+func (b MTUBudget) Remaining(packets ...Packet) int {
+	return b.Size() - CompoundPacket(packets).MarshalSize()
+}
+
+// ReceptionReports returns how many reception reports a SenderReport, or a
+// ReceiverReport if sender is false, can carry in a datagram that also
+// holds other packets, at most the 31 a report can carry. Reports beyond
+// that go into later reports, as described in RFC 3550, 6.4.
+func (b MTUBudget) ReceptionReports(sender bool, other ...Packet) int {
+	fixed := headerLength + ssrcLength
+	if sender {
+		fixed = headerLength + srHeaderLength
+	}
+	n := (b.Remaining(other...) - fixed) / receptionReportLength
+	switch {
+	case n < 0:
+		return 0
+	case n > countMax:
+		return countMax
+	}
+	return n
+}
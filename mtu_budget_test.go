@@ -0,0 +1,36 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMTUBudget(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(1200-28, MTUBudget{}.Size())
+	assert.Equal(1500-48, MTUBudget{MTU: 1500, Overhead: IPv6PacketOverhead}.Size())
+	// AES_CM_128_HMAC_SHA1_80 adds the index and a 10 byte tag
+	b := MTUBudget{SRTCP: true, AuthTagLength: 10}
+	assert.Equal(1200-28-14, b.Size())
+
+	sdes := NewCNAMESourceDescription(1, "cname")
+	assert.Equal(b.Size()-sdes.MarshalSize(), b.Remaining(sdes))
+
+	// (1158 - 12 - 8) / 24 reports would fit, but a report carries at
+	// most 31
+	assert.Equal(31, b.ReceptionReports(false, sdes))
+
+	small := MTUBudget{MTU: 200}
+	// (172 - 12 - 8) / 24 and (172 - 12 - 28) / 24
+	assert.Equal(6, small.ReceptionReports(false, sdes))
+	assert.Equal(5, small.ReceptionReports(true, sdes))
+	assert.Equal(0, MTUBudget{MTU: 40}.ReceptionReports(true))
+
+	// the computed number of reports fits
+	rr := &ReceiverReport{SSRC: 1, Reports: make([]ReceptionReport, small.ReceptionReports(false, sdes))}
+	assert.True(small.Remaining(rr, sdes) >= 0)
+	rr.Reports = append(rr.Reports, ReceptionReport{})
+	assert.True(small.Remaining(rr, sdes) < 0)
+}
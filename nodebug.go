@@ -0,0 +1,7 @@
+//go:build rtcp_nodebug
+// +build rtcp_nodebug
+
+package rtcp
+
+// debugChecks is disabled by the rtcp_nodebug tag, see debug.go
+const debugChecks = false
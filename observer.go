@@ -0,0 +1,96 @@
+package rtcp
+
+// An Observer is notified of decoded packets by type, so applications can
+// subscribe to the packets they handle instead of type switching over the
+// result of Unmarshal. Embed BaseObserver to implement only some methods.
+//
+// Packets passed to a Decoder's Observer are only valid until the next call
+// to Decode, like the Decoder's results.
+type Observer interface {
+	OnSenderReport(*SenderReport)
+	OnReceiverReport(*ReceiverReport)
+	OnSourceDescription(*SourceDescription)
+	OnGoodbye(*Goodbye)
+	OnNack(*TransportLayerNack)
+	OnTWCC(*TransportLayerCC)
+	OnPictureLossIndication(*PictureLossIndication)
+	OnFullIntraRequest(*FullIntraRequest)
+	OnREMB(*ReceiverEstimatedMaximumBitrate)
+	// OnUnknown is called for every other packet, e.g. ApplicationDefined,
+	// ExtendedReport, packets of registered types and RawPacket.
+	OnUnknown(Packet)
+}
+
+// BaseObserver implements Observer by ignoring every packet.
+type BaseObserver struct{}
+
+var _ Observer = BaseObserver{} // assert is an Observer
+
+// OnSenderReport implements Observer.
+func (BaseObserver) OnSenderReport(*SenderReport) {}
+
+// OnReceiverReport implements Observer.
+func (BaseObserver) OnReceiverReport(*ReceiverReport) {}
+
+// OnSourceDescription implements Observer.
+func (BaseObserver) OnSourceDescription(*SourceDescription) {}
+
+// OnGoodbye implements Observer.
+func (BaseObserver) OnGoodbye(*Goodbye) {}
+
+// OnNack implements Observer.
+func (BaseObserver) OnNack(*TransportLayerNack) {}
+
+// OnTWCC implements Observer.
+func (BaseObserver) OnTWCC(*TransportLayerCC) {}
+
+// OnPictureLossIndication implements Observer.
+func (BaseObserver) OnPictureLossIndication(*PictureLossIndication) {}
+
+// OnFullIntraRequest implements Observer.
+func (BaseObserver) OnFullIntraRequest(*FullIntraRequest) {}
+
+// OnREMB implements Observer.
+func (BaseObserver) OnREMB(*ReceiverEstimatedMaximumBitrate) {}
+
+// OnUnknown implements Observer.
+func (BaseObserver) OnUnknown(Packet) {}
+
+// Dispatch calls the method of o for each of packets, in order. The packets
+// of a CompoundPacket are dispatched individually.
+func Dispatch(o Observer, packets []Packet) {
+	for _, p := range packets {
+		switch p := p.(type) {
+		case *CompoundPacket:
+			Dispatch(o, *p)
+		case *SenderReport:
+			o.OnSenderReport(p)
+		case *ReceiverReport:
+			o.OnReceiverReport(p)
+		case *SourceDescription:
+			o.OnSourceDescription(p)
+		case *Goodbye:
+			o.OnGoodbye(p)
+		case *TransportLayerNack:
+			o.OnNack(p)
+		case *TransportLayerCC:
+			o.OnTWCC(p)
+		case *PictureLossIndication:
+			o.OnPictureLossIndication(p)
+		case *FullIntraRequest:
+			o.OnFullIntraRequest(p)
+		case *ReceiverEstimatedMaximumBitrate:
+			o.OnREMB(p)
+		default:
+			o.OnUnknown(p)
+		}
+	}
+}
+
+// WithObserver makes a Decoder dispatch the packets of every datagram it
+// decodes successfully to o, see Dispatch.
+func WithObserver(o Observer) DecoderOption {
+	return func(d *Decoder) {
+		d.observer = o
+	}
+}
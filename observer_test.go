@@ -0,0 +1,66 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingObserver struct {
+	BaseObserver
+	senderReports []*SenderReport
+	nacks         []*TransportLayerNack
+	unknown       []Packet
+}
+
+func (o *recordingObserver) OnSenderReport(p *SenderReport) {
+	o.senderReports = append(o.senderReports, p)
+}
+
+func (o *recordingObserver) OnNack(p *TransportLayerNack) {
+	o.nacks = append(o.nacks, p)
+}
+
+func (o *recordingObserver) OnUnknown(p Packet) {
+	o.unknown = append(o.unknown, p)
+}
+
+func TestDispatch(t *testing.T) {
+	assert := assert.New(t)
+
+	sr := &SenderReport{SSRC: 1}
+	nack := &TransportLayerNack{MediaSSRC: 2}
+	app := &ApplicationDefined{Name: "test"}
+	pli := &PictureLossIndication{MediaSSRC: 2}
+
+	o := &recordingObserver{}
+	Dispatch(o, []Packet{&CompoundPacket{sr, nack}, app, pli})
+	assert.Equal([]*SenderReport{sr}, o.senderReports)
+	assert.Equal([]*TransportLayerNack{nack}, o.nacks)
+	// methods that aren't implemented are ignored
+	assert.Equal([]Packet{app}, o.unknown)
+}
+
+func TestDecoderObserver(t *testing.T) {
+	assert := assert.New(t)
+
+	o := &recordingObserver{}
+	d := NewDecoder(WithObserver(o))
+	raw, err := Marshal([]Packet{
+		&SenderReport{SSRC: 1},
+		&RapidResynchronizationRequest{SenderSSRC: 1, MediaSSRC: 2},
+		&TransportLayerNack{SenderSSRC: 1, MediaSSRC: 2, Nacks: []NackPair{{PacketID: 5}}},
+	})
+	assert.NoError(err)
+
+	packets, err := d.Decode(raw, nil)
+	assert.NoError(err)
+	assert.Equal([]*SenderReport{packets[0].(*SenderReport)}, o.senderReports)
+	assert.Equal([]Packet{packets[1]}, o.unknown)
+	assert.Equal([]*TransportLayerNack{packets[2].(*TransportLayerNack)}, o.nacks)
+
+	// nothing is dispatched for datagrams that fail to decode
+	_, err = d.Decode(raw[:10], nil)
+	assert.Error(err)
+	assert.Len(o.senderReports, 1)
+}
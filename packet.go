@@ -1,10 +1,23 @@
 package rtcp
 
+import "fmt"
+
 // Packet represents an RTCP packet, a protocol used for out-of-band statistics and control information for an RTP session
 type Packet interface {
 	// DestinationSSRC returns an array of SSRC values that this packet refers to.
+	//
+	// This is every media source the packet carries information about: the
+	// report blocks of a SenderReport or ReceiverReport, the chunks of a
+	// SourceDescription, the sources leaving in a Goodbye, the SSRC list of a
+	// ReceiverEstimatedMaximumBitrate, and the media SSRC of other feedback.
+	// The SSRC of the packet's sender is not included, so the result can be
+	// used to route a packet to the streams it concerns. The returned slice
+	// is owned by the caller.
 	DestinationSSRC() []uint32
 
+	// Marshal encodes the packet. The length and count fields of the header
+	// are always computed from the contents, so a decoded packet can be
+	// modified, e.g. by appending report blocks, and marshalled again.
 	Marshal() ([]byte, error)
 	Unmarshal(rawPacket []byte) error
 }
@@ -15,6 +28,10 @@ type Packet interface {
 // If this is a reduced-size RTCP packet a feedback packet (Goodbye, SliceLossIndication, etc)
 // will be returned. Otherwise, the underlying type of the returned packet will be
 // CompoundPacket.
+//
+// The padding of a packet with the P bit set is removed before it is decoded.
+// If it is the last packet of the datagram, its padding is also accepted
+// after the end given by its length, as some senders leave it uncounted.
 func Unmarshal(rawData []byte) ([]Packet, error) {
 	var packets []Packet
 	for len(rawData) != 0 {
@@ -46,14 +63,45 @@ func Marshal(packets []Packet) ([]byte, error) {
 		if err != nil {
 			return nil, err
 		}
+		if debugChecks {
+			if err := checkMarshaled(data); err != nil {
+				return nil, fmt.Errorf("%w: %T", err, p)
+			}
+		}
 		out = append(out, data...)
 	}
 	return out, nil
 }
 
+// checkMarshaled verifies that the length fields of the packets in data
+// cover it exactly, and that their bodies have room for the items their
+// count fields announce, catching Packet implementations that compute them
+// wrong
+func checkMarshaled(data []byte) error {
+	for len(data) != 0 {
+		var h Header
+		if err := h.Unmarshal(data); err != nil {
+			return err
+		}
+		if h.size() > len(data) {
+			return errBadMarshaledLen
+		}
+		if h.minBodySize() > h.size() {
+			return errBadMarshaledCount
+		}
+		data = data[h.size():]
+	}
+	return nil
+}
+
 // unmarshal is a factory which pulls the first RTCP packet from a bytestream,
 // and returns it's parsed representation, and the amount of data that was processed.
 func unmarshal(rawData []byte) (packet Packet, bytesprocessed int, err error) {
+	return unmarshalWith(rawData, newPacket)
+}
+
+// unmarshalWith is like unmarshal, but obtains the packet to decode into from alloc
+func unmarshalWith(rawData []byte, alloc func(h Header) Packet) (packet Packet, bytesprocessed int, err error) {
 	var h Header
 
 	err = h.Unmarshal(rawData)
@@ -61,54 +109,128 @@ func unmarshal(rawData []byte) (packet Packet, bytesprocessed int, err error) {
 		return nil, 0, err
 	}
 
-	bytesprocessed = int(h.Length+1) * 4
+	bytesprocessed = h.size()
 	if bytesprocessed > len(rawData) {
 		return nil, 0, errPacketTooShort
 	}
 	inPacket := rawData[:bytesprocessed]
 
+	if h.Padding {
+		if isTrailingPadding(rawData[bytesprocessed:]) {
+			// the padding isn't counted in the length of the packet, so
+			// it ends the datagram
+			inPacket, err = unpad(&h, inPacket, 0)
+			bytesprocessed = len(rawData)
+		} else if padding := int(inPacket[len(inPacket)-1]); padding == 0 {
+			err = errBadPadding
+		} else {
+			inPacket, err = unpad(&h, inPacket, padding)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if h.Type == TypeApplicationDefined {
+		if p, ok := unmarshalApplicationDefined(inPacket); ok {
+			return p, bytesprocessed, nil
+		}
+	}
+	if p, ok, err := unmarshalRegistered(h, inPacket); ok {
+		return p, bytesprocessed, err
+	}
+
+	packet = alloc(h)
+	err = packet.Unmarshal(inPacket)
+
+	return packet, bytesprocessed, err
+}
+
+// isTrailingPadding reports whether rest, what follows a padded packet, is
+// its padding: RFC 3550 puts the pad count in the last octet, and padding
+// can't be told apart from a further packet otherwise.
+func isTrailingPadding(rest []byte) bool {
+	if len(rest) == 0 || int(rest[len(rest)-1]) != len(rest) {
+		return false
+	}
+	var h Header
+	return h.Unmarshal(rest) != nil || (int(h.Length)+1)*4 > len(rest)
+}
+
+// unpad removes padding octets from the end of inPacket and clears the P
+// bit, adjusting h and the header of the result to match, so packet types
+// don't mistake the pad octets for content. The P bit may only be set on the
+// last packet of a compound packet, so copying it is rare. Padding that
+// isn't a multiple of 4 octets leaves the packet unaligned, so it's left for
+// the packet types that allow it to remove.
+func unpad(h *Header, inPacket []byte, padding int) ([]byte, error) {
+	if padding > len(inPacket)-headerLength {
+		return nil, errBadPadding
+	}
+	if padding%4 != 0 {
+		return inPacket, nil
+	}
+
+	h.Padding = false
+	h.Length -= uint16(padding / 4)
+	header, err := h.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(inPacket)-padding)
+	copy(out, header)
+	copy(out[headerLength:], inPacket[headerLength:])
+	return out, nil
+}
+
+// newPacket returns a new, empty packet of the type described by h
+func newPacket(h Header) Packet {
 	switch h.Type {
 	case TypeSenderReport:
-		packet = new(SenderReport)
+		return new(SenderReport)
 
 	case TypeReceiverReport:
-		packet = new(ReceiverReport)
+		return new(ReceiverReport)
 
 	case TypeSourceDescription:
-		packet = new(SourceDescription)
+		return new(SourceDescription)
 
 	case TypeGoodbye:
-		packet = new(Goodbye)
+		return new(Goodbye)
+
+	case TypeApplicationDefined:
+		return new(ApplicationDefined)
+
+	case TypeExtendedReport:
+		return new(ExtendedReport)
 
 	case TypeTransportSpecificFeedback:
 		switch h.Count {
 		case FormatTLN:
-			packet = new(TransportLayerNack)
+			return new(TransportLayerNack)
 		case FormatRRR:
-			packet = new(RapidResynchronizationRequest)
+			return new(RapidResynchronizationRequest)
 		case FormatTCC:
-			packet = new(TransportLayerCC)
+			return new(TransportLayerCC)
 		default:
-			packet = new(RawPacket)
+			return new(RawPacket)
 		}
 
 	case TypePayloadSpecificFeedback:
 		switch h.Count {
 		case FormatPLI:
-			packet = new(PictureLossIndication)
+			return new(PictureLossIndication)
+		case FormatFIR:
+			return new(FullIntraRequest)
 		case FormatSLI:
-			packet = new(SliceLossIndication)
+			return new(SliceLossIndication)
 		case FormatREMB:
-			packet = new(ReceiverEstimatedMaximumBitrate)
+			return new(ReceiverEstimatedMaximumBitrate)
 		default:
-			packet = new(RawPacket)
+			return new(RawPacket)
 		}
 
 	default:
-		packet = new(RawPacket)
+		return new(RawPacket)
 	}
-
-	err = packet.Unmarshal(inPacket)
-
-	return packet, bytesprocessed, err
 }
@@ -0,0 +1,584 @@
+package rtcp
+
+import (
+	"time"
+)
+
+// The builders in this file assemble packets of each type with chained
+// calls, for tests and tools that need many valid packets differing in a few
+// parameters:
+//
+//	sr := rtcp.NewSR().WithSSRC(1).WithNTPNow().
+//		AddReport(rtcp.ReceptionReport{SSRC: 2, FractionLost: 64}).
+//		Build()
+//
+// Build returns a new packet on every call, so a builder can be modified and
+// built again. The builders don't validate the values set; Marshal does.
+
+// seconds between the NTP epoch, 1900, and the Unix epoch
+const ntpEpochOffset = 2208988800
+
+// ntpTime converts t to a 64 bit NTP timestamp, see RFC 3550, 4
+func ntpTime(t time.Time) uint64 {
+	seconds := uint64(t.Unix() + ntpEpochOffset)
+	fraction := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+	return seconds<<32 | fraction
+}
+
+// An SRBuilder builds a SenderReport, see NewSR.
+type SRBuilder struct {
+	p SenderReport
+}
+
+// NewSR starts building a SenderReport.
+func NewSR() *SRBuilder {
+	return &SRBuilder{}
+}
+
+// WithSSRC sets the SSRC of the sender.
+func (b *SRBuilder) WithSSRC(ssrc uint32) *SRBuilder {
+	b.p.SSRC = ssrc
+	return b
+}
+
+// WithNTPTime sets the wallclock time of the report.
+func (b *SRBuilder) WithNTPTime(t time.Time) *SRBuilder {
+	b.p.NTPTime = ntpTime(t)
+	return b
+}
+
+// WithNTPNow sets the wallclock time of the report to the current time.
+func (b *SRBuilder) WithNTPNow() *SRBuilder {
+	return b.WithNTPTime(time.Now())
+}
+
+// WithRTPTime sets the RTP timestamp matching the wallclock time.
+func (b *SRBuilder) WithRTPTime(rtpTime uint32) *SRBuilder {
+	b.p.RTPTime = rtpTime
+	return b
+}
+
+// WithCounts sets the sender's packet and octet counts.
+func (b *SRBuilder) WithCounts(packets, octets uint32) *SRBuilder {
+	b.p.PacketCount, b.p.OctetCount = packets, octets
+	return b
+}
+
+// AddReport appends reception reports.
+func (b *SRBuilder) AddReport(reports ...ReceptionReport) *SRBuilder {
+	b.p.Reports = append(b.p.Reports, reports...)
+	return b
+}
+
+// Build returns the SenderReport.
+func (b *SRBuilder) Build() *SenderReport {
+	p := b.p
+	p.Reports = append([]ReceptionReport(nil), b.p.Reports...)
+	return &p
+}
+
+// An RRBuilder builds a ReceiverReport, see NewRR.
+type RRBuilder struct {
+	p ReceiverReport
+}
+
+// NewRR starts building a ReceiverReport.
+func NewRR() *RRBuilder {
+	return &RRBuilder{}
+}
+
+// WithSSRC sets the SSRC of the sender.
+func (b *RRBuilder) WithSSRC(ssrc uint32) *RRBuilder {
+	b.p.SSRC = ssrc
+	return b
+}
+
+// AddReport appends reception reports.
+func (b *RRBuilder) AddReport(reports ...ReceptionReport) *RRBuilder {
+	b.p.Reports = append(b.p.Reports, reports...)
+	return b
+}
+
+// Build returns the ReceiverReport.
+func (b *RRBuilder) Build() *ReceiverReport {
+	p := b.p
+	p.Reports = append([]ReceptionReport(nil), b.p.Reports...)
+	return &p
+}
+
+// An SDESBuilder builds a SourceDescription, see NewSDES.
+type SDESBuilder struct {
+	chunks []SourceDescriptionChunk
+	index  map[uint32]int
+}
+
+// NewSDES starts building a SourceDescription.
+func NewSDES() *SDESBuilder {
+	return &SDESBuilder{index: make(map[uint32]int)}
+}
+
+// AddItem appends an item to the chunk of source. Items for the same source
+// share a chunk, in the order the sources were first added.
+func (b *SDESBuilder) AddItem(source uint32, typ SDESType, text string) *SDESBuilder {
+	i, ok := b.index[source]
+	if !ok {
+		i = len(b.chunks)
+		b.index[source] = i
+		b.chunks = append(b.chunks, SourceDescriptionChunk{Source: source})
+	}
+	b.chunks[i].Items = append(b.chunks[i].Items, SourceDescriptionItem{Type: typ, Text: text})
+	return b
+}
+
+// AddCNAME appends a CNAME item to the chunk of source.
+func (b *SDESBuilder) AddCNAME(source uint32, cname string) *SDESBuilder {
+	return b.AddItem(source, SDESCNAME, cname)
+}
+
+// Build returns the SourceDescription.
+func (b *SDESBuilder) Build() *SourceDescription {
+	chunks := make([]SourceDescriptionChunk, len(b.chunks))
+	for i, c := range b.chunks {
+		chunks[i] = SourceDescriptionChunk{Source: c.Source, Items: append([]SourceDescriptionItem(nil), c.Items...)}
+	}
+	return &SourceDescription{Chunks: chunks}
+}
+
+// A ByeBuilder builds a Goodbye, see NewBye.
+type ByeBuilder struct {
+	p Goodbye
+}
+
+// NewBye starts building a Goodbye.
+func NewBye() *ByeBuilder {
+	return &ByeBuilder{}
+}
+
+// AddSource appends the sources leaving.
+func (b *ByeBuilder) AddSource(sources ...uint32) *ByeBuilder {
+	b.p.Sources = append(b.p.Sources, sources...)
+	return b
+}
+
+// WithReason sets the reason for leaving.
+func (b *ByeBuilder) WithReason(reason string) *ByeBuilder {
+	b.p.Reason = reason
+	return b
+}
+
+// Build returns the Goodbye.
+func (b *ByeBuilder) Build() *Goodbye {
+	p := b.p
+	p.Sources = append([]uint32(nil), b.p.Sources...)
+	return &p
+}
+
+// An AppBuilder builds an ApplicationDefined, see NewApp.
+type AppBuilder struct {
+	p ApplicationDefined
+}
+
+// NewApp starts building an ApplicationDefined packet of the application
+// name.
+func NewApp(name string) *AppBuilder {
+	return &AppBuilder{p: ApplicationDefined{Name: name}}
+}
+
+// WithSSRC sets the SSRC of the sender.
+func (b *AppBuilder) WithSSRC(ssrc uint32) *AppBuilder {
+	b.p.SSRC = ssrc
+	return b
+}
+
+// WithSubType sets the application dependent subtype.
+func (b *AppBuilder) WithSubType(subType uint8) *AppBuilder {
+	b.p.SubType = subType
+	return b
+}
+
+// WithData sets the application dependent data.
+func (b *AppBuilder) WithData(data []byte) *AppBuilder {
+	b.p.Data = data
+	return b
+}
+
+// Build returns the ApplicationDefined packet.
+func (b *AppBuilder) Build() *ApplicationDefined {
+	p := b.p
+	p.Data = append([]byte(nil), b.p.Data...)
+	return &p
+}
+
+// An XRBuilder builds an ExtendedReport, see NewXR.
+type XRBuilder struct {
+	p ExtendedReport
+}
+
+// NewXR starts building an ExtendedReport.
+func NewXR() *XRBuilder {
+	return &XRBuilder{}
+}
+
+// WithSenderSSRC sets the SSRC of the sender.
+func (b *XRBuilder) WithSenderSSRC(ssrc uint32) *XRBuilder {
+	b.p.SenderSSRC = ssrc
+	return b
+}
+
+// AddBlock appends report blocks.
+func (b *XRBuilder) AddBlock(blocks ...ReportBlock) *XRBuilder {
+	b.p.Reports = append(b.p.Reports, blocks...)
+	return b
+}
+
+// Build returns the ExtendedReport. The blocks are shared with the builder.
+func (b *XRBuilder) Build() *ExtendedReport {
+	p := b.p
+	p.Reports = append([]ReportBlock(nil), b.p.Reports...)
+	return &p
+}
+
+// A NACKBuilder builds a TransportLayerNack, see NewNACK.
+type NACKBuilder struct {
+	p    TransportLayerNack
+	lost []uint16
+}
+
+// NewNACK starts building a TransportLayerNack.
+func NewNACK() *NACKBuilder {
+	return &NACKBuilder{}
+}
+
+// WithSenderSSRC sets the SSRC of the feedback sender.
+func (b *NACKBuilder) WithSenderSSRC(ssrc uint32) *NACKBuilder {
+	b.p.SenderSSRC = ssrc
+	return b
+}
+
+// WithMediaSSRC sets the SSRC of the media source.
+func (b *NACKBuilder) WithMediaSSRC(ssrc uint32) *NACKBuilder {
+	b.p.MediaSSRC = ssrc
+	return b
+}
+
+// AddLost appends sequence numbers of lost packets. Build packs them into
+// NackPairs with NackPairsFromSequenceNumbers.
+func (b *NACKBuilder) AddLost(seqs ...uint16) *NACKBuilder {
+	b.lost = append(b.lost, seqs...)
+	return b
+}
+
+// Build returns the TransportLayerNack.
+func (b *NACKBuilder) Build() *TransportLayerNack {
+	p := b.p
+	p.Nacks = NackPairsFromSequenceNumbers(b.lost)
+	return &p
+}
+
+// A PLIBuilder builds a PictureLossIndication, see NewPLI.
+type PLIBuilder struct {
+	p PictureLossIndication
+}
+
+// NewPLI starts building a PictureLossIndication.
+func NewPLI() *PLIBuilder {
+	return &PLIBuilder{}
+}
+
+// WithSenderSSRC sets the SSRC of the feedback sender.
+func (b *PLIBuilder) WithSenderSSRC(ssrc uint32) *PLIBuilder {
+	b.p.SenderSSRC = ssrc
+	return b
+}
+
+// WithMediaSSRC sets the SSRC of the media source.
+func (b *PLIBuilder) WithMediaSSRC(ssrc uint32) *PLIBuilder {
+	b.p.MediaSSRC = ssrc
+	return b
+}
+
+// Build returns the PictureLossIndication.
+func (b *PLIBuilder) Build() *PictureLossIndication {
+	p := b.p
+	return &p
+}
+
+// An SLIBuilder builds a SliceLossIndication, see NewSLI.
+type SLIBuilder struct {
+	p SliceLossIndication
+}
+
+// NewSLI starts building a SliceLossIndication.
+func NewSLI() *SLIBuilder {
+	return &SLIBuilder{}
+}
+
+// WithSenderSSRC sets the SSRC of the feedback sender.
+func (b *SLIBuilder) WithSenderSSRC(ssrc uint32) *SLIBuilder {
+	b.p.SenderSSRC = ssrc
+	return b
+}
+
+// WithMediaSSRC sets the SSRC of the media source.
+func (b *SLIBuilder) WithMediaSSRC(ssrc uint32) *SLIBuilder {
+	b.p.MediaSSRC = ssrc
+	return b
+}
+
+// AddLoss appends the loss of number slices from first of picture.
+func (b *SLIBuilder) AddLoss(first, number uint16, picture uint8) *SLIBuilder {
+	b.p.SLI = append(b.p.SLI, SLIEntry{First: first, Number: number, Picture: picture})
+	return b
+}
+
+// Build returns the SliceLossIndication.
+func (b *SLIBuilder) Build() *SliceLossIndication {
+	p := b.p
+	p.SLI = append([]SLIEntry(nil), b.p.SLI...)
+	return &p
+}
+
+// A FIRBuilder builds a FullIntraRequest, see NewFIR.
+type FIRBuilder struct {
+	p FullIntraRequest
+}
+
+// NewFIR starts building a FullIntraRequest.
+func NewFIR() *FIRBuilder {
+	return &FIRBuilder{}
+}
+
+// WithSenderSSRC sets the SSRC of the feedback sender.
+func (b *FIRBuilder) WithSenderSSRC(ssrc uint32) *FIRBuilder {
+	b.p.SenderSSRC = ssrc
+	return b
+}
+
+// WithMediaSSRC sets the media SSRC field, which is unused by FIR.
+func (b *FIRBuilder) WithMediaSSRC(ssrc uint32) *FIRBuilder {
+	b.p.MediaSSRC = ssrc
+	return b
+}
+
+// AddRequest appends a request for a keyframe of ssrc, with the command
+// sequence number seq.
+func (b *FIRBuilder) AddRequest(ssrc uint32, seq uint8) *FIRBuilder {
+	b.p.FIR = append(b.p.FIR, FIREntry{SSRC: ssrc, SequenceNumber: seq})
+	return b
+}
+
+// Build returns the FullIntraRequest.
+func (b *FIRBuilder) Build() *FullIntraRequest {
+	p := b.p
+	p.FIR = append([]FIREntry(nil), b.p.FIR...)
+	return &p
+}
+
+// An RRRBuilder builds a RapidResynchronizationRequest, see NewRRR.
+type RRRBuilder struct {
+	p RapidResynchronizationRequest
+}
+
+// NewRRR starts building a RapidResynchronizationRequest.
+func NewRRR() *RRRBuilder {
+	return &RRRBuilder{}
+}
+
+// WithSenderSSRC sets the SSRC of the feedback sender.
+func (b *RRRBuilder) WithSenderSSRC(ssrc uint32) *RRRBuilder {
+	b.p.SenderSSRC = ssrc
+	return b
+}
+
+// WithMediaSSRC sets the SSRC of the media source.
+func (b *RRRBuilder) WithMediaSSRC(ssrc uint32) *RRRBuilder {
+	b.p.MediaSSRC = ssrc
+	return b
+}
+
+// Build returns the RapidResynchronizationRequest.
+func (b *RRRBuilder) Build() *RapidResynchronizationRequest {
+	p := b.p
+	return &p
+}
+
+// A REMBBuilder builds a ReceiverEstimatedMaximumBitrate, see NewREMB.
+type REMBBuilder struct {
+	p ReceiverEstimatedMaximumBitrate
+}
+
+// NewREMB starts building a ReceiverEstimatedMaximumBitrate.
+func NewREMB() *REMBBuilder {
+	return &REMBBuilder{}
+}
+
+// WithSenderSSRC sets the SSRC of the feedback sender.
+func (b *REMBBuilder) WithSenderSSRC(ssrc uint32) *REMBBuilder {
+	b.p.SenderSSRC = ssrc
+	return b
+}
+
+// WithBitrate sets the estimate in bits per second.
+func (b *REMBBuilder) WithBitrate(bitrate uint64) *REMBBuilder {
+	b.p.Bitrate = bitrate
+	return b
+}
+
+// AddSSRC appends the SSRCs the estimate applies to.
+func (b *REMBBuilder) AddSSRC(ssrcs ...uint32) *REMBBuilder {
+	b.p.SSRCs = append(b.p.SSRCs, ssrcs...)
+	return b
+}
+
+// Build returns the ReceiverEstimatedMaximumBitrate.
+func (b *REMBBuilder) Build() *ReceiverEstimatedMaximumBitrate {
+	p := b.p
+	p.SSRCs = append([]uint32(nil), b.p.SSRCs...)
+	return &p
+}
+
+// A TWCCBuilder builds a TransportLayerCC with a TransportLayerCCBuilder,
+// see NewTWCC.
+type TWCCBuilder struct {
+	senderSSRC uint32
+	mediaSSRC  uint32
+	fbPktCount uint8
+	base       uint16
+	statuses   []twccStatus
+	err        error
+}
+
+type twccStatus struct {
+	seq      uint16
+	arrival  time.Duration
+	received bool
+}
+
+// NewTWCC starts building a TransportLayerCC starting at the transport wide
+// sequence number base.
+func NewTWCC(base uint16) *TWCCBuilder {
+	return &TWCCBuilder{base: base}
+}
+
+// WithSenderSSRC sets the SSRC of the feedback sender.
+func (b *TWCCBuilder) WithSenderSSRC(ssrc uint32) *TWCCBuilder {
+	b.senderSSRC = ssrc
+	return b
+}
+
+// WithMediaSSRC sets the SSRC of the media source.
+func (b *TWCCBuilder) WithMediaSSRC(ssrc uint32) *TWCCBuilder {
+	b.mediaSSRC = ssrc
+	return b
+}
+
+// WithFbPktCount sets the feedback packet count.
+func (b *TWCCBuilder) WithFbPktCount(count uint8) *TWCCBuilder {
+	b.fbPktCount = count
+	return b
+}
+
+// Received reports the packet seq as received at arrival, see
+// TransportLayerCCBuilder.AddReceived.
+func (b *TWCCBuilder) Received(seq uint16, arrival time.Duration) *TWCCBuilder {
+	b.statuses = append(b.statuses, twccStatus{seq: seq, arrival: arrival, received: true})
+	return b
+}
+
+// Lost reports the packet seq as lost.
+func (b *TWCCBuilder) Lost(seq uint16) *TWCCBuilder {
+	b.statuses = append(b.statuses, twccStatus{seq: seq})
+	return b
+}
+
+// Err returns the first error of the TransportLayerCCBuilder in the latest
+// call to Build, for a status out of order or a delta out of range.
+func (b *TWCCBuilder) Err() error {
+	return b.err
+}
+
+// Build returns the TransportLayerCC. Statuses the TransportLayerCCBuilder
+// refused are left out, see Err.
+func (b *TWCCBuilder) Build() *TransportLayerCC {
+	cc := NewTransportLayerCCBuilder(b.base)
+	b.err = nil
+	for _, s := range b.statuses {
+		var err error
+		if s.received {
+			err = cc.AddReceived(s.seq, s.arrival)
+		} else {
+			err = cc.AddLost(s.seq)
+		}
+		if err != nil && b.err == nil {
+			b.err = err
+		}
+	}
+
+	p := cc.Build()
+	p.SenderSSRC, p.MediaSSRC, p.FbPktCount = b.senderSSRC, b.mediaSSRC, b.fbPktCount
+	return p
+}
+
+// A RAMSBuilder builds a RapidAcquisition, see NewRAMS.
+type RAMSBuilder struct {
+	p RapidAcquisition
+}
+
+// NewRAMS starts building a RapidAcquisition message of type typ.
+func NewRAMS(typ RAMSMessageType) *RAMSBuilder {
+	return &RAMSBuilder{p: RapidAcquisition{MessageType: typ}}
+}
+
+// WithSenderSSRC sets the SSRC of the feedback sender.
+func (b *RAMSBuilder) WithSenderSSRC(ssrc uint32) *RAMSBuilder {
+	b.p.SenderSSRC = ssrc
+	return b
+}
+
+// WithMediaSSRC sets the SSRC of the media source.
+func (b *RAMSBuilder) WithMediaSSRC(ssrc uint32) *RAMSBuilder {
+	b.p.MediaSSRC = ssrc
+	return b
+}
+
+// WithData sets the TLV elements following the message type.
+func (b *RAMSBuilder) WithData(data []byte) *RAMSBuilder {
+	b.p.Data = data
+	return b
+}
+
+// Build returns the RapidAcquisition.
+func (b *RAMSBuilder) Build() *RapidAcquisition {
+	p := b.p
+	p.Data = append([]byte(nil), b.p.Data...)
+	return &p
+}
+
+// A PortMappingBuilder builds a PortMapping, see NewPortMapping.
+type PortMappingBuilder struct {
+	p PortMapping
+}
+
+// NewPortMapping starts building a PortMapping message of type typ.
+func NewPortMapping(typ PortMappingType) *PortMappingBuilder {
+	return &PortMappingBuilder{p: PortMapping{MessageType: typ}}
+}
+
+// WithSSRC sets the SSRC of the sender.
+func (b *PortMappingBuilder) WithSSRC(ssrc uint32) *PortMappingBuilder {
+	b.p.SSRC = ssrc
+	return b
+}
+
+// WithData sets the message contents after the SSRC.
+func (b *PortMappingBuilder) WithData(data []byte) *PortMappingBuilder {
+	b.p.Data = data
+	return b
+}
+
+// Build returns the PortMapping.
+func (b *PortMappingBuilder) Build() *PortMapping {
+	p := b.p
+	p.Data = append([]byte(nil), b.p.Data...)
+	return &p
+}
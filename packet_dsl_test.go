@@ -0,0 +1,111 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPacketDSL(t *testing.T) {
+	assert := assert.New(t)
+
+	at := time.Unix(1, int64(time.Second/4))
+	report := ReceptionReport{SSRC: 2, FractionLost: 64}
+	twcc := NewTWCC(10).WithSenderSSRC(1).WithMediaSSRC(2).WithFbPktCount(3).
+		Received(10, 1024*time.Millisecond).Lost(11).Received(12, 1025*time.Millisecond)
+
+	for _, test := range []struct {
+		Got  Packet
+		Want Packet
+	}{
+		{
+			NewSR().WithSSRC(1).WithNTPTime(at).WithRTPTime(5).WithCounts(6, 7).AddReport(report).Build(),
+			&SenderReport{SSRC: 1, NTPTime: (ntpEpochOffset+1)<<32 | 1<<30, RTPTime: 5, PacketCount: 6, OctetCount: 7, Reports: []ReceptionReport{report}},
+		},
+		{
+			NewRR().WithSSRC(1).AddReport(report, report).Build(),
+			&ReceiverReport{SSRC: 1, Reports: []ReceptionReport{report, report}},
+		},
+		{
+			NewSDES().AddCNAME(1, "a").AddCNAME(2, "b").AddItem(1, SDESTool, "t").Build(),
+			&SourceDescription{Chunks: []SourceDescriptionChunk{
+				{Source: 1, Items: []SourceDescriptionItem{{Type: SDESCNAME, Text: "a"}, {Type: SDESTool, Text: "t"}}},
+				{Source: 2, Items: []SourceDescriptionItem{{Type: SDESCNAME, Text: "b"}}},
+			}},
+		},
+		{
+			NewBye().AddSource(1, 2).WithReason("done").Build(),
+			&Goodbye{Sources: []uint32{1, 2}, Reason: "done"},
+		},
+		{
+			NewApp("NAME").WithSSRC(1).WithSubType(2).WithData([]byte{1, 2, 3, 4}).Build(),
+			&ApplicationDefined{SSRC: 1, SubType: 2, Name: "NAME", Data: []byte{1, 2, 3, 4}},
+		},
+		{
+			NewXR().WithSenderSSRC(1).AddBlock(&UnknownReportBlock{Type: 200, Data: []byte{1, 2, 3, 4}}).Build(),
+			&ExtendedReport{SenderSSRC: 1, Reports: []ReportBlock{&UnknownReportBlock{Type: 200, Data: []byte{1, 2, 3, 4}}}},
+		},
+		{
+			NewNACK().WithSenderSSRC(1).WithMediaSSRC(2).AddLost(10, 12, 40).Build(),
+			&TransportLayerNack{SenderSSRC: 1, MediaSSRC: 2, Nacks: []NackPair{{PacketID: 10, LostPackets: 2}, {PacketID: 40}}},
+		},
+		{
+			NewPLI().WithSenderSSRC(1).WithMediaSSRC(2).Build(),
+			&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2},
+		},
+		{
+			NewSLI().WithSenderSSRC(1).WithMediaSSRC(2).AddLoss(3, 4, 5).Build(),
+			&SliceLossIndication{SenderSSRC: 1, MediaSSRC: 2, SLI: []SLIEntry{{First: 3, Number: 4, Picture: 5}}},
+		},
+		{
+			NewFIR().WithSenderSSRC(1).WithMediaSSRC(0).AddRequest(2, 3).Build(),
+			&FullIntraRequest{SenderSSRC: 1, FIR: []FIREntry{{SSRC: 2, SequenceNumber: 3}}},
+		},
+		{
+			NewRRR().WithSenderSSRC(1).WithMediaSSRC(2).Build(),
+			&RapidResynchronizationRequest{SenderSSRC: 1, MediaSSRC: 2},
+		},
+		{
+			NewREMB().WithSenderSSRC(1).WithBitrate(1000).AddSSRC(2, 3).Build(),
+			&ReceiverEstimatedMaximumBitrate{SenderSSRC: 1, Bitrate: 1000, SSRCs: []uint32{2, 3}},
+		},
+		{
+			NewRAMS(RAMSRequest).WithSenderSSRC(1).WithMediaSSRC(2).WithData([]byte{1, 2, 3, 4}).Build(),
+			&RapidAcquisition{SenderSSRC: 1, MediaSSRC: 2, MessageType: RAMSRequest, Data: []byte{1, 2, 3, 4}},
+		},
+		{
+			NewPortMapping(PortMappingRequest).WithSSRC(1).WithData([]byte{1, 2, 3, 4}).Build(),
+			&PortMapping{MessageType: PortMappingRequest, SSRC: 1, Data: []byte{1, 2, 3, 4}},
+		},
+	} {
+		assert.Equal(test.Want, test.Got)
+		data, err := test.Got.Marshal()
+		assert.NoError(err, "%T", test.Got)
+		_, err = Unmarshal(data)
+		assert.NoError(err, "%T", test.Got)
+	}
+
+	fb := twcc.Build()
+	assert.NoError(twcc.Err())
+	assert.Equal(uint32(1), fb.SenderSSRC)
+	assert.Equal(uint32(2), fb.MediaSSRC)
+	assert.Equal(uint8(3), fb.FbPktCount)
+	assert.Equal([]uint16{11}, fb.Lost())
+	assert.Equal(map[uint16]time.Duration{10: 0, 12: time.Millisecond}, fb.ArrivalTimes())
+
+	// a status out of order is left out and reported
+	fb = twcc.Received(11, 2*time.Second).Build()
+	assert.Error(twcc.Err())
+	assert.Equal(uint16(3), fb.PacketStatusCount)
+}
+
+func TestPacketDSLBuildCopies(t *testing.T) {
+	b := NewRR().WithSSRC(1).AddReport(ReceptionReport{SSRC: 2})
+	first := b.Build()
+	second := b.AddReport(ReceptionReport{SSRC: 3}).Build()
+	first.Reports[0].SSRC = 4
+
+	assert.Len(t, first.Reports, 1)
+	assert.Equal(t, []ReceptionReport{{SSRC: 2}, {SSRC: 3}}, second.Reports)
+}
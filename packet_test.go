@@ -1,6 +1,7 @@
 package rtcp
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -136,3 +137,220 @@ func TestInvalidHeaderLength(t *testing.T) {
 		t.Fatalf("Unmarshal(nil) err = %v, want %v", got, want)
 	}
 }
+
+func TestDestinationSSRC(t *testing.T) {
+	for _, test := range []struct {
+		Name   string
+		Packet Packet
+		Want   []uint32
+	}{
+		{
+			Name:   "sender report",
+			Packet: &SenderReport{SSRC: 1, Reports: []ReceptionReport{{SSRC: 2}, {SSRC: 3}}},
+			Want:   []uint32{2, 3},
+		},
+		{
+			Name:   "receiver report",
+			Packet: &ReceiverReport{SSRC: 1, Reports: []ReceptionReport{{SSRC: 2}, {SSRC: 3}}},
+			Want:   []uint32{2, 3},
+		},
+		{
+			Name:   "source description",
+			Packet: &SourceDescription{Chunks: []SourceDescriptionChunk{{Source: 4}, {Source: 5}}},
+			Want:   []uint32{4, 5},
+		},
+		{
+			Name:   "goodbye",
+			Packet: &Goodbye{Sources: []uint32{6, 7}},
+			Want:   []uint32{6, 7},
+		},
+		{
+			Name:   "remb",
+			Packet: &ReceiverEstimatedMaximumBitrate{SenderSSRC: 1, SSRCs: []uint32{8, 9}},
+			Want:   []uint32{8, 9},
+		},
+		{
+			Name:   "nack",
+			Packet: &TransportLayerNack{SenderSSRC: 1, MediaSSRC: 10},
+			Want:   []uint32{10},
+		},
+		{
+			Name:   "pli",
+			Packet: &PictureLossIndication{SenderSSRC: 1, MediaSSRC: 11},
+			Want:   []uint32{11},
+		},
+		{
+			Name:   "sli",
+			Packet: &SliceLossIndication{SenderSSRC: 1, MediaSSRC: 12},
+			Want:   []uint32{12},
+		},
+		{
+			Name:   "rrr",
+			Packet: &RapidResynchronizationRequest{SenderSSRC: 1, MediaSSRC: 13},
+			Want:   []uint32{13},
+		},
+		{
+			Name:   "tcc",
+			Packet: &TransportLayerCC{SenderSSRC: 1, MediaSSRC: 14},
+			Want:   []uint32{14},
+		},
+		{
+			Name:   "application defined",
+			Packet: &ApplicationDefined{SSRC: 1, Name: "TEST"},
+			Want:   []uint32{},
+		},
+		{
+			Name:   "port mapping",
+			Packet: &PortMapping{SSRC: 1},
+			Want:   []uint32{},
+		},
+		{
+			Name:   "raw",
+			Packet: &RawPacket{},
+			Want:   []uint32{},
+		},
+		{
+			Name: "compound",
+			Packet: &CompoundPacket{
+				&ReceiverReport{SSRC: 1, Reports: []ReceptionReport{{SSRC: 2}}},
+				&SourceDescription{Chunks: []SourceDescriptionChunk{{Source: 1}}},
+				&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2},
+				&Goodbye{Sources: []uint32{1}},
+			},
+			Want: []uint32{2, 1},
+		},
+		{
+			Name:   "empty compound",
+			Packet: &CompoundPacket{},
+			Want:   nil,
+		},
+	} {
+		assert.Equal(t, test.Want, test.Packet.DestinationSSRC(), test.Name)
+	}
+
+	remb := &ReceiverEstimatedMaximumBitrate{SSRCs: []uint32{1}}
+	remb.DestinationSSRC()[0] = 2
+	assert.Equal(t, []uint32{1}, remb.SSRCs, "DestinationSSRC must not alias the packet")
+}
+
+func TestMarshalAfterMutation(t *testing.T) {
+	vectors, err := TestVectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range vectors {
+		packets, err := Unmarshal(v.Data)
+		if err != nil {
+			t.Fatalf("%s: unmarshal: %v", v.Name, err)
+		}
+		p := packets[0]
+
+		// grow the packet the way a user relaying or rewriting it would
+		switch p := p.(type) {
+		case *SenderReport:
+			p.Reports = append(p.Reports, ReceptionReport{SSRC: 0x1})
+		case *ReceiverReport:
+			p.Reports = append(p.Reports, ReceptionReport{SSRC: 0x1})
+		case *SourceDescription:
+			p.Chunks[0].Items = append(p.Chunks[0].Items, SourceDescriptionItem{Type: SDESTool, Text: "pion"})
+			p.Chunks = append(p.Chunks, SourceDescriptionChunk{Source: 0x1, Items: []SourceDescriptionItem{{Type: SDESCNAME, Text: "other"}}})
+		case *Goodbye:
+			p.Sources = append(p.Sources, 0x1)
+			p.Reason += " now"
+		case *ApplicationDefined:
+			p.Data = append(p.Data, 5, 6, 7, 8)
+		case *TransportLayerNack:
+			p.Nacks = append(p.Nacks, NackPair{PacketID: 0xbbb})
+		case *TransportLayerCC:
+			n := p.PacketStatusCount + 1
+			p.PacketStatusCount = n
+			p.PacketChunks = []PacketStatusChunk{&RunLengthChunk{PacketStatusSymbol: TypePacketReceivedSmallDelta, RunLength: n}}
+			p.RecvDeltas = nil
+			for i := uint16(0); i < n; i++ {
+				p.RecvDeltas = append(p.RecvDeltas, &RecvDelta{Type: TypePacketReceivedSmallDelta, Delta: 250})
+			}
+		case *ReceiverEstimatedMaximumBitrate:
+			p.SSRCs = append(p.SSRCs, 0x1)
+		case *ExtendedReport:
+			p.Reports = append(p.Reports, &UnknownReportBlock{Type: 255, Data: []byte{1, 2, 3, 4}})
+		case *CCFeedbackReport:
+			p.ReportBlocks[0].MetricBlocks = append(p.ReportBlocks[0].MetricBlocks, CCFeedbackMetricBlock{Received: true})
+			p.ReportBlocks = append(p.ReportBlocks, CCFeedbackReportBlock{MediaSSRC: 0x1})
+		case *RapidAcquisition:
+			p.Data = append(p.Data, 0, 1, 0, 0)
+		case *PortMapping:
+			p.Data = append(p.Data, 0, 0, 0, 2)
+		case *PictureLossIndication:
+			p.MediaSSRC = 0x1
+		case *FullIntraRequest:
+			p.FIR = append(p.FIR, FIREntry{SSRC: 0x1, SequenceNumber: 1})
+		case *RapidResynchronizationRequest:
+			p.MediaSSRC = 0x1
+		default:
+			t.Fatalf("%s: no mutation for %T", v.Name, p)
+		}
+
+		data, err := p.Marshal()
+		if err != nil {
+			t.Fatalf("%s: marshal: %v", v.Name, err)
+		}
+		var h Header
+		if err := h.Unmarshal(data); err != nil {
+			t.Fatalf("%s: header: %v", v.Name, err)
+		}
+		if got, want := (int(h.Length)+1)*4, len(data); got != want {
+			t.Fatalf("%s: header length covers %d bytes, packet has %d", v.Name, got, want)
+		}
+		if want := headerOf(p); h != want {
+			t.Fatalf("%s: header %+v, want %+v", v.Name, h, want)
+		}
+
+		decoded, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("%s: unmarshal mutated: %v", v.Name, err)
+		}
+		again, err := Marshal(decoded)
+		if err != nil {
+			t.Fatalf("%s: marshal decoded: %v", v.Name, err)
+		}
+		assert.Equal(t, data, again, v.Name)
+	}
+}
+
+func TestUnmarshalCraftedLength(t *testing.T) {
+	packets := []Packet{
+		&SenderReport{}, &ReceiverReport{}, &SourceDescription{}, &Goodbye{},
+		&ApplicationDefined{}, &ExtendedReport{}, &TransportLayerNack{},
+		&RapidResynchronizationRequest{}, &TransportLayerCC{}, &PictureLossIndication{}, &FullIntraRequest{},
+		&SliceLossIndication{}, &ReceiverEstimatedMaximumBitrate{}, &RapidAcquisition{},
+		&PortMapping{}, &CCFeedbackReport{}, &RawPacket{},
+	}
+
+	for _, p := range packets {
+		h := headerOf(p)
+		// lengths beyond the buffer, including ones whose size in octets
+		// overflows a uint16
+		for _, length := range []uint16{0xffff, 0x4000, 0x4004, 0x3fff, 64} {
+			h.Length = length
+			header, err := h.Marshal()
+			assert.NoError(t, err)
+			raw := append(header, make([]byte, 60)...)
+			if h.Type == TypeApplicationDefined {
+				copy(raw[8:], "name")
+			}
+			if _, ok := p.(*ReceiverEstimatedMaximumBitrate); ok {
+				copy(raw[12:], "REMB")
+			}
+
+			target := reflect.New(reflect.TypeOf(p).Elem()).Interface().(Packet)
+			assert.Equal(t, errPacketTooShort, target.Unmarshal(raw), "%T with length %#x", p, length)
+
+			_, err = Unmarshal(raw)
+			assert.Equal(t, errPacketTooShort, err, "Unmarshal %T with length %#x", p, length)
+
+			err = ScanCompound(raw, func(Header, []byte) bool { return true })
+			assert.Equal(t, errPacketTooShort, err, "ScanCompound %T with length %#x", p, length)
+		}
+	}
+}
@@ -63,6 +63,10 @@ func (p *PictureLossIndication) Unmarshal(rawPacket []byte) error {
 		return errWrongType
 	}
 
+	if h.size() < headerLength+ssrcLength*2 || h.size() > len(rawPacket) {
+		return errPacketTooShort
+	}
+
 	p.SenderSSRC = binary.BigEndian.Uint32(rawPacket[headerLength:])
 	p.MediaSSRC = binary.BigEndian.Uint32(rawPacket[headerLength+ssrcLength:])
 	return nil
@@ -0,0 +1,128 @@
+package rtcp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// PortMappingType is the type of a PortMapping message, its SMT field.
+type PortMappingType uint8
+
+// PortMappingType values, see RFC 6284, 6
+const (
+	PortMappingRequest       PortMappingType = 1
+	PortMappingResponse      PortMappingType = 2
+	TokenVerificationRequest PortMappingType = 3
+	TokenVerificationFailure PortMappingType = 4
+)
+
+func (t PortMappingType) String() string {
+	switch t {
+	case PortMappingRequest:
+		return "PortMappingRequest"
+	case PortMappingResponse:
+		return "PortMappingResponse"
+	case TokenVerificationRequest:
+		return "TokenVerificationRequest"
+	case TokenVerificationFailure:
+		return "TokenVerificationFailure"
+	default:
+		return fmt.Sprintf("PortMappingType(%d)", uint8(t))
+	}
+}
+
+// The PortMapping packet carries the TOKEN messages of RFC 6284, with which
+// a unicast receiver of a multicast session obtains and proves ownership of
+// a port mapping. The message specific fields after the sender SSRC aren't
+// decoded.
+type PortMapping struct {
+	// The message type, at most 31
+	MessageType PortMappingType
+	// SSRC of sender
+	SSRC uint32
+	// The message contents after the SSRC, a multiple of 4 bytes
+	Data []byte
+}
+
+var _ Packet = (*PortMapping)(nil) // assert is a Packet
+
+const portMappingHeaderLength = headerLength + ssrcLength
+
+// Marshal encodes the PortMapping packet in binary
+func (p PortMapping) Marshal() ([]byte, error) {
+	/*
+	 *  0                   1                   2                   3
+	 *  0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |V=2|P|   SMT   |   PT=TOKEN    |             length            |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |                 SSRC of packet sender                         |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * :                   message specific fields                     :
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 */
+	if len(p.Data)%4 != 0 {
+		return nil, errBadPayloadLength
+	}
+	if (portMappingHeaderLength+len(p.Data))/4-1 > 0xffff {
+		return nil, errPacketTooLong
+	}
+
+	hData, err := p.Header().Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	rawPacket := make([]byte, portMappingHeaderLength+len(p.Data))
+	copy(rawPacket, hData)
+	binary.BigEndian.PutUint32(rawPacket[headerLength:], p.SSRC)
+	copy(rawPacket[portMappingHeaderLength:], p.Data)
+	return rawPacket, nil
+}
+
+// Unmarshal decodes the PortMapping packet from binary
+func (p *PortMapping) Unmarshal(rawPacket []byte) error {
+	var h Header
+	if err := h.Unmarshal(rawPacket); err != nil {
+		return err
+	}
+	if h.Type != TypeToken {
+		return errWrongType
+	}
+
+	end := h.size()
+	if end < portMappingHeaderLength || end > len(rawPacket) {
+		return errPacketTooShort
+	}
+	if h.Padding {
+		padding := int(rawPacket[end-1])
+		if padding == 0 || padding%4 != 0 || portMappingHeaderLength+padding > end {
+			return errBadPadding
+		}
+		end -= padding
+	}
+
+	p.MessageType = PortMappingType(h.Count)
+	p.SSRC = binary.BigEndian.Uint32(rawPacket[headerLength:])
+	p.Data = rawPacket[portMappingHeaderLength:end]
+	return nil
+}
+
+// Header returns the Header associated with this packet.
+func (p *PortMapping) Header() Header {
+	return Header{
+		Count:  uint8(p.MessageType),
+		Type:   TypeToken,
+		Length: uint16((portMappingHeaderLength+len(p.Data))/4 - 1),
+	}
+}
+
+// DestinationSSRC returns an array of SSRC values that this packet refers to.
+// A PortMapping refers to no media source; its SSRC is that of its sender.
+func (p *PortMapping) DestinationSSRC() []uint32 {
+	return []uint32{}
+}
+
+func (p PortMapping) String() string {
+	return fmt.Sprintf("PortMapping %v from %x, %d bytes", p.MessageType, p.SSRC, len(p.Data))
+}
@@ -0,0 +1,98 @@
+package rtcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPortMappingUnmarshal(t *testing.T) {
+	for _, test := range []struct {
+		Name      string
+		Data      []byte
+		Want      PortMapping
+		WantError error
+	}{
+		{
+			Name: "valid",
+			Data: []byte{
+				// v=2, p=0, SMT=2, TOKEN, len=2
+				0x82, 0xd2, 0x00, 0x02,
+				// ssrc=0x902f9e2e
+				0x90, 0x2f, 0x9e, 0x2e,
+				// message specific fields
+				0x01, 0x02, 0x03, 0x04,
+			},
+			Want: PortMapping{
+				MessageType: PortMappingResponse,
+				SSRC:        0x902f9e2e,
+				Data:        []byte{0x01, 0x02, 0x03, 0x04},
+			},
+		},
+		{
+			Name: "padding",
+			Data: []byte{
+				// v=2, p=1, SMT=1, TOKEN, len=2
+				0xa1, 0xd2, 0x00, 0x02,
+				0x90, 0x2f, 0x9e, 0x2e,
+				0x00, 0x00, 0x00, 0x04,
+			},
+			Want: PortMapping{
+				MessageType: PortMappingRequest,
+				SSRC:        0x902f9e2e,
+				Data:        []byte{},
+			},
+		},
+		{
+			Name:      "short",
+			Data:      []byte{0x81, 0xd2, 0x00, 0x00},
+			WantError: errPacketTooShort,
+		},
+		{
+			Name: "wrong type",
+			Data: []byte{
+				0x81, 0xcc, 0x00, 0x01,
+				0x90, 0x2f, 0x9e, 0x2e,
+			},
+			WantError: errWrongType,
+		},
+	} {
+		var p PortMapping
+		err := p.Unmarshal(test.Data)
+		if got, want := err, test.WantError; got != want {
+			t.Fatalf("Unmarshal %q: err = %v, want %v", test.Name, got, want)
+		}
+		if err != nil {
+			continue
+		}
+		if got, want := p, test.Want; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Unmarshal %q: got %#v, want %#v", test.Name, got, want)
+		}
+
+		packets, err := Unmarshal(test.Data)
+		if err != nil {
+			t.Fatalf("Unmarshal %q packets: %v", test.Name, err)
+		}
+		if _, ok := packets[0].(*PortMapping); !ok {
+			t.Fatalf("Unmarshal %q: got %T, want *PortMapping", test.Name, packets[0])
+		}
+	}
+}
+
+func TestPortMappingRoundTrip(t *testing.T) {
+	want := PortMapping{
+		MessageType: TokenVerificationRequest,
+		SSRC:        0x902f9e2e,
+		Data:        []byte{0, 0, 0, 1, 0, 0, 0, 2},
+	}
+	data, err := want.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var got PortMapping
+	if err := got.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("round trip: got %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package rtcp
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// randFloat64 returns a pseudo-random number in [0, 1) from src as
+// rand.Float64 does, or from the global source of math/rand if src is nil
+func randFloat64(src rand.Source) float64 {
+	if src == nil {
+		return rand.Float64() // nolint:gosec
+	}
+	for {
+		// drop the low bits rather than round, so 1 can't come out
+		if f := float64(src.Int63()>>10) / (1 << 53); f < 1 {
+			return f
+		}
+	}
+}
+
+// A TraceSource is a rand.Source that records the numbers it returns, so
+// the timing decisions of a Scheduler or FeedbackSuppressor using it can be
+// audited afterwards, and reproduced from the trace with a ReplaySource.
+// It's safe for concurrent use.
+type TraceSource struct {
+	mu     sync.Mutex
+	source rand.Source
+	trace  []int64
+}
+
+var _ rand.Source = (*TraceSource)(nil) // assert is a rand.Source
+
+// NewTraceSource creates a TraceSource recording the numbers of source.
+func NewTraceSource(source rand.Source) *TraceSource {
+	return &TraceSource{source: source}
+}
+
+// Int63 returns the next number of the underlying source and records it.
+func (t *TraceSource) Int63() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := t.source.Int63()
+	t.trace = append(t.trace, n)
+	return n
+}
+
+// Seed seeds the underlying source. The trace is kept.
+func (t *TraceSource) Seed(seed int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.source.Seed(seed)
+}
+
+// Trace returns the numbers returned so far, in order.
+func (t *TraceSource) Trace() []int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]int64(nil), t.trace...)
+}
+
+// Reset forgets the numbers returned so far.
+func (t *TraceSource) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.trace = nil
+}
+
+// A ReplaySource is a rand.Source returning the numbers of a trace, as
+// recorded by a TraceSource, in order. Once they are used up it starts
+// over. It isn't safe for concurrent use.
+type ReplaySource struct {
+	trace []int64
+	next  int
+}
+
+var _ rand.Source = (*ReplaySource)(nil) // assert is a rand.Source
+
+// NewReplaySource creates a ReplaySource returning the numbers of trace.
+func NewReplaySource(trace []int64) *ReplaySource {
+	return &ReplaySource{trace: trace}
+}
+
+// Int63 returns the next number of the trace, or zero if it's empty.
+func (r *ReplaySource) Int63() int64 {
+	if len(r.trace) == 0 {
+		return 0
+	}
+	n := r.trace[r.next%len(r.trace)]
+	r.next++
+	return n
+}
+
+// Seed restarts the trace. The seed is ignored.
+func (r *ReplaySource) Seed(int64) {
+	r.next = 0
+}
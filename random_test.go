@@ -0,0 +1,62 @@
+package rtcp
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandFloat64(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(0.0, randFloat64(NewReplaySource([]int64{0})))
+	assert.Equal(0.5, randFloat64(NewReplaySource([]int64{1 << 62})))
+	assert.True(randFloat64(NewReplaySource([]int64{1<<63 - 1})) < 1)
+
+	src := rand.NewSource(1)
+	for i := 0; i < 1000; i++ {
+		f := randFloat64(src)
+		assert.True(f >= 0 && f < 1, f)
+	}
+	f := randFloat64(nil)
+	assert.True(f >= 0 && f < 1, f)
+}
+
+func TestTraceSource(t *testing.T) {
+	assert := assert.New(t)
+
+	trace := NewTraceSource(rand.NewSource(1))
+	want := rand.New(rand.NewSource(1))
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			trace.Int63()
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		trace.Int63()
+	}
+	wg.Wait()
+
+	recorded := trace.Trace()
+	assert.Len(recorded, 100)
+	for _, n := range recorded {
+		assert.Equal(want.Int63(), n)
+	}
+
+	trace.Reset()
+	assert.Empty(trace.Trace())
+	trace.Seed(1)
+	assert.Equal(recorded[0], trace.Int63())
+
+	// a replay starts over once the trace is used up
+	replay := NewReplaySource([]int64{1, 2})
+	assert.Equal([]int64{1, 2, 1}, []int64{replay.Int63(), replay.Int63(), replay.Int63()})
+	replay.Seed(0)
+	assert.Equal(int64(1), replay.Int63())
+	assert.Equal(int64(0), NewReplaySource(nil).Int63())
+}
@@ -0,0 +1,136 @@
+package rtcp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// RAMSMessageType is the type of a RapidAcquisition message, its SFMT field.
+type RAMSMessageType uint8
+
+// RAMSMessageType values, see RFC 6285, 7.1
+const (
+	RAMSRequest     RAMSMessageType = 1 // RAMS-R
+	RAMSInformation RAMSMessageType = 2 // RAMS-I
+	RAMSTermination RAMSMessageType = 3 // RAMS-T
+)
+
+func (t RAMSMessageType) String() string {
+	switch t {
+	case RAMSRequest:
+		return "RAMS-R"
+	case RAMSInformation:
+		return "RAMS-I"
+	case RAMSTermination:
+		return "RAMS-T"
+	default:
+		return fmt.Sprintf("RAMSMessageType(%d)", uint8(t))
+	}
+}
+
+// The RapidAcquisition packet carries the Rapid Acquisition of Multicast RTP
+// Sessions messages of RFC 6285, with which a receiver joining a multicast
+// session asks a retransmission server for a unicast burst. The TLV
+// elements of the message aren't decoded.
+type RapidAcquisition struct {
+	// SSRC of sender
+	SenderSSRC uint32
+	// SSRC of the media source
+	MediaSSRC uint32
+	// The message type
+	MessageType RAMSMessageType
+	// The TLV elements following the message type, a multiple of 4 bytes
+	Data []byte
+}
+
+var _ Packet = (*RapidAcquisition)(nil) // assert is a Packet
+
+const (
+	ramsHeaderLength = headerLength + 2*ssrcLength + 4
+	ramsTypeOffset   = headerLength + 2*ssrcLength
+)
+
+// Marshal encodes the RapidAcquisition packet in binary
+func (p RapidAcquisition) Marshal() ([]byte, error) {
+	/*
+	 *  0                   1                   2                   3
+	 *  0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |V=2|P|  FMT=6  |   PT=205      |             length            |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |                  SSRC of packet sender                        |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |                  SSRC of media source                         |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * |     SFMT      |                   Reserved                    |
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 * :            Requests/responses/notifications (TLVs)            :
+	 * +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 */
+	if len(p.Data)%4 != 0 {
+		return nil, errBadPayloadLength
+	}
+	if (ramsHeaderLength+len(p.Data))/4-1 > 0xffff {
+		return nil, errPacketTooLong
+	}
+
+	hData, err := p.Header().Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	rawPacket := make([]byte, ramsHeaderLength+len(p.Data))
+	copy(rawPacket, hData)
+	binary.BigEndian.PutUint32(rawPacket[headerLength:], p.SenderSSRC)
+	binary.BigEndian.PutUint32(rawPacket[headerLength+ssrcLength:], p.MediaSSRC)
+	rawPacket[ramsTypeOffset] = uint8(p.MessageType)
+	copy(rawPacket[ramsHeaderLength:], p.Data)
+	return rawPacket, nil
+}
+
+// Unmarshal decodes the RapidAcquisition packet from binary
+func (p *RapidAcquisition) Unmarshal(rawPacket []byte) error {
+	var h Header
+	if err := h.Unmarshal(rawPacket); err != nil {
+		return err
+	}
+	if h.Type != TypeTransportSpecificFeedback || h.Count != FormatRAMS {
+		return errWrongType
+	}
+
+	end := h.size()
+	if end < ramsHeaderLength || end > len(rawPacket) {
+		return errPacketTooShort
+	}
+	if h.Padding {
+		padding := int(rawPacket[end-1])
+		if padding == 0 || padding%4 != 0 || ramsHeaderLength+padding > end {
+			return errBadPadding
+		}
+		end -= padding
+	}
+
+	p.SenderSSRC = binary.BigEndian.Uint32(rawPacket[headerLength:])
+	p.MediaSSRC = binary.BigEndian.Uint32(rawPacket[headerLength+ssrcLength:])
+	p.MessageType = RAMSMessageType(rawPacket[ramsTypeOffset])
+	p.Data = rawPacket[ramsHeaderLength:end]
+	return nil
+}
+
+// Header returns the Header associated with this packet.
+func (p *RapidAcquisition) Header() Header {
+	return Header{
+		Count:  FormatRAMS,
+		Type:   TypeTransportSpecificFeedback,
+		Length: uint16((ramsHeaderLength+len(p.Data))/4 - 1),
+	}
+}
+
+// DestinationSSRC returns an array of SSRC values that this packet refers to.
+func (p *RapidAcquisition) DestinationSSRC() []uint32 {
+	return []uint32{p.MediaSSRC}
+}
+
+func (p RapidAcquisition) String() string {
+	return fmt.Sprintf("RapidAcquisition %v from %x to %x, %d bytes", p.MessageType, p.SenderSSRC, p.MediaSSRC, len(p.Data))
+}
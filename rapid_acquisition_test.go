@@ -0,0 +1,91 @@
+package rtcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRapidAcquisitionUnmarshal(t *testing.T) {
+	for _, test := range []struct {
+		Name      string
+		Data      []byte
+		Want      RapidAcquisition
+		WantError error
+	}{
+		{
+			Name: "valid",
+			Data: []byte{
+				// v=2, p=0, FMT=6, TSFB, len=4
+				0x86, 0xcd, 0x00, 0x04,
+				// sender=0x902f9e2e
+				0x90, 0x2f, 0x9e, 0x2e,
+				// media=0xbc5e9a40
+				0xbc, 0x5e, 0x9a, 0x40,
+				// SFMT=RAMS-R
+				0x01, 0x00, 0x00, 0x00,
+				// TLVs
+				0x01, 0x02, 0x03, 0x04,
+			},
+			Want: RapidAcquisition{
+				SenderSSRC:  0x902f9e2e,
+				MediaSSRC:   0xbc5e9a40,
+				MessageType: RAMSRequest,
+				Data:        []byte{0x01, 0x02, 0x03, 0x04},
+			},
+		},
+		{
+			Name: "missing message type",
+			Data: []byte{
+				0x86, 0xcd, 0x00, 0x02,
+				0x90, 0x2f, 0x9e, 0x2e,
+				0xbc, 0x5e, 0x9a, 0x40,
+			},
+			WantError: errPacketTooShort,
+		},
+		{
+			Name: "wrong format",
+			Data: []byte{
+				0x85, 0xcd, 0x00, 0x03,
+				0x90, 0x2f, 0x9e, 0x2e,
+				0xbc, 0x5e, 0x9a, 0x40,
+				0x01, 0x00, 0x00, 0x00,
+			},
+			WantError: errWrongType,
+		},
+	} {
+		var p RapidAcquisition
+		err := p.Unmarshal(test.Data)
+		if got, want := err, test.WantError; got != want {
+			t.Fatalf("Unmarshal %q: err = %v, want %v", test.Name, got, want)
+		}
+		if err != nil {
+			continue
+		}
+		if got, want := p, test.Want; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Unmarshal %q: got %#v, want %#v", test.Name, got, want)
+		}
+
+		data, err := p.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal %q: %v", test.Name, err)
+		}
+		if !reflect.DeepEqual(data, test.Data) {
+			t.Fatalf("Marshal %q: got %x, want %x", test.Name, data, test.Data)
+		}
+
+		packets, err := Unmarshal(test.Data)
+		if err != nil {
+			t.Fatalf("Unmarshal %q packets: %v", test.Name, err)
+		}
+		if _, ok := packets[0].(*RapidAcquisition); !ok {
+			t.Fatalf("Unmarshal %q: got %T, want *RapidAcquisition", test.Name, packets[0])
+		}
+	}
+}
+
+func TestRapidAcquisitionMarshalBadData(t *testing.T) {
+	p := RapidAcquisition{MessageType: RAMSTermination, Data: []byte{1, 2}}
+	if _, err := p.Marshal(); err != errBadPayloadLength {
+		t.Fatalf("Marshal: err = %v, want %v", err, errBadPayloadLength)
+	}
+}
@@ -60,6 +60,10 @@ func (p *RapidResynchronizationRequest) Unmarshal(rawPacket []byte) error {
 		return errWrongType
 	}
 
+	if h.size() < headerLength+ssrcLength*2 || h.size() > len(rawPacket) {
+		return errPacketTooShort
+	}
+
 	p.SenderSSRC = binary.BigEndian.Uint32(rawPacket[headerLength:])
 	p.MediaSSRC = binary.BigEndian.Uint32(rawPacket[headerLength+ssrcLength:])
 	return nil
@@ -21,7 +21,13 @@ func (r *RawPacket) Unmarshal(b []byte) error {
 	*r = b
 
 	var h Header
-	return h.Unmarshal(b)
+	if err := h.Unmarshal(b); err != nil {
+		return err
+	}
+	if h.size() > len(b) {
+		return errPacketTooShort
+	}
+	return nil
 }
 
 // Header returns the Header associated with this packet.
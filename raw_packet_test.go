@@ -15,8 +15,8 @@ func TestRawPacketRoundTrip(t *testing.T) {
 		{
 			Name: "valid",
 			Packet: RawPacket([]byte{
-				// v=2, p=0, count=1, BYE, len=12
-				0x81, 0xcb, 0x00, 0x0c,
+				// v=2, p=0, count=1, BYE, len=2
+				0x81, 0xcb, 0x00, 0x02,
 				// ssrc=0x902f9e2e
 				0x90, 0x2f, 0x9e, 0x2e,
 				// len=3, text=FOO
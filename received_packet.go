@@ -0,0 +1,43 @@
+package rtcp
+
+import (
+	"net"
+	"time"
+)
+
+// A ReceivedPacket is a received packet along with the context it arrived
+// in, so consumers such as the RTTEstimator and MemberTable get the arrival
+// time without a side channel. It embeds the Packet, which type switches
+// must be applied to rather than the ReceivedPacket itself.
+type ReceivedPacket struct {
+	Packet
+	// When the datagram carrying the packet was read
+	ArrivalTime time.Time
+	// The address the datagram came from, if known
+	RemoteAddr net.Addr
+}
+
+// NewReceivedPackets wraps each packet of a datagram that arrived at the
+// given time from addr.
+func NewReceivedPackets(packets []Packet, arrival time.Time, addr net.Addr) []ReceivedPacket {
+	return appendReceived(nil, packets, arrival, addr)
+}
+
+func appendReceived(dst []ReceivedPacket, packets []Packet, arrival time.Time, addr net.Addr) []ReceivedPacket {
+	for _, p := range packets {
+		dst = append(dst, ReceivedPacket{Packet: p, ArrivalTime: arrival, RemoteAddr: addr})
+	}
+	return dst
+}
+
+// DecodeReceived decodes raw like Decode, and returns its packets in dst,
+// which is truncated first, tagged with the time raw arrived and the address
+// it came from. The same lifetime rules as for Decode apply.
+func (d *Decoder) DecodeReceived(raw []byte, arrival time.Time, addr net.Addr, dst []ReceivedPacket) ([]ReceivedPacket, error) {
+	packets, err := d.Decode(raw, d.received)
+	d.received = packets
+	if err != nil {
+		return nil, err
+	}
+	return appendReceived(dst[:0], packets, arrival, addr), nil
+}
@@ -0,0 +1,66 @@
+package rtcp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoderDecodeReceived(t *testing.T) {
+	assert := assert.New(t)
+
+	want, err := Unmarshal(realPacket)
+	assert.NoError(err)
+
+	arrival := time.Unix(1000, 0)
+	addr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 5000}
+	d := NewDecoder()
+	var dst []ReceivedPacket
+	for i := 0; i < 2; i++ {
+		dst, err = d.DecodeReceived(realPacket, arrival, addr, dst)
+		assert.NoError(err)
+		assert.Equal(NewReceivedPackets(want, arrival, addr), dst)
+	}
+
+	_, err = d.DecodeReceived(nil, arrival, addr, dst)
+	assert.Equal(errInvalidHeader, err)
+}
+
+func TestReceivedPacketConsumers(t *testing.T) {
+	assert := assert.New(t)
+
+	sent := time.Unix(1000, 0)
+	sr := &SenderReport{SSRC: 1, NTPTime: ntpTime(sent)}
+	e := NewRTTEstimator()
+	e.SentSenderReport(sr, sent)
+
+	rr := &ReceiverReport{SSRC: 2, Reports: []ReceptionReport{
+		{SSRC: 3, LastSenderReport: 1},
+		{SSRC: 1, LastSenderReport: uint32(sr.NTPTime >> 16), Delay: 65536 / 16},
+	}}
+	arrival := sent.Add(150 * time.Millisecond)
+	packets := NewReceivedPackets([]Packet{&CompoundPacket{rr, NewCNAMESourceDescription(2, "two")}}, arrival, nil)
+
+	rtt, ok := e.OnReceivedPacket(packets[0], 1)
+	assert.True(ok)
+	assert.Equal(87500*time.Microsecond, rtt)
+	_, ok = e.OnReceivedPacket(packets[0], 4)
+	assert.False(ok)
+
+	// members are seen at the arrival time, not the clock's
+	m := NewMemberTable(1)
+	m.Clock = ClockFunc(func() time.Time { return time.Unix(5000, 0) })
+	assert.False(m.UpdateReceived(packets))
+	mb, ok := m.Member(2)
+	assert.True(ok)
+	assert.Equal(arrival, mb.LastSeen)
+	assert.Equal("two", mb.CNAME)
+
+	r := NewSimulcastRouter()
+	r.SetLayer(10, 20)
+	routed, ok := r.RouteReceived(ReceivedPacket{Packet: &PictureLossIndication{MediaSSRC: 10}, ArrivalTime: arrival})
+	assert.True(ok)
+	assert.Equal(ReceivedPacket{Packet: &PictureLossIndication{MediaSSRC: 20}, ArrivalTime: arrival}, routed)
+}
@@ -158,7 +158,7 @@ func (p *ReceiverEstimatedMaximumBitrate) Unmarshal(buf []byte) (err error) {
 
 	// 20 bytes is the size of the packet with no SSRCs
 	if len(buf) < 20 {
-		return errors.New("buffer too short")
+		return errPacketTooShort
 	}
 
 	// version  must be 2
@@ -186,16 +186,16 @@ func (p *ReceiverEstimatedMaximumBitrate) Unmarshal(buf []byte) (err error) {
 
 	// length is the number of 32-bit words, minus 1
 	length := binary.BigEndian.Uint16(buf[2:4])
-	size := int((length + 1) * 4)
+	size := (int(length) + 1) * 4
 
 	// There's not way this could be legit
 	if size < 20 {
-		return errors.New("header length is too small")
+		return errPacketTooShort
 	}
 
 	// Make sure the buffer is large enough.
 	if len(buf) < size {
-		return errors.New("buffer too short")
+		return errPacketTooShort
 	}
 
 	// The sender SSRC is 32-bits
@@ -281,5 +281,7 @@ func (p *ReceiverEstimatedMaximumBitrate) String() string {
 
 // DestinationSSRC returns an array of SSRC values that this packet refers to.
 func (p *ReceiverEstimatedMaximumBitrate) DestinationSSRC() []uint32 {
-	return p.SSRCs
+	out := make([]uint32, len(p.SSRCs))
+	copy(out, p.SSRCs)
+	return out
 }
@@ -75,16 +75,10 @@ func (r ReceiverReport) Marshal() ([]byte, error) {
 		return nil, errTooManyReports
 	}
 
-	pe := make([]byte, len(r.ProfileExtensions))
-	copy(pe, r.ProfileExtensions)
-
 	//if the length of the profile extensions isn't devisible
-	//by 4, we need to pad the end.
-	for (len(pe) & 0x3) != 0 {
-		pe = append(pe, 0)
-	}
-
-	rawPacket = append(rawPacket, pe...)
+	//by 4, we need to pad the end. rawPacket is already sized
+	//for the padding, so it is left zeroed.
+	copy(packetBody[ssrcLength+receptionReportLength*len(r.Reports):], r.ProfileExtensions)
 
 	hData, err := r.Header().Marshal()
 
@@ -139,6 +133,11 @@ func (r *ReceiverReport) Unmarshal(rawPacket []byte) error {
 		return errWrongType
 	}
 
+	if h.size() > len(rawPacket) || h.size() < headerLength+ssrcLength {
+		return errPacketTooShort
+	}
+	rawPacket = rawPacket[:h.size()]
+
 	r.SSRC = binary.BigEndian.Uint32(rawPacket[rrSSRCOffset:])
 
 	for i := rrReportOffset; i < len(rawPacket) && len(r.Reports) < int(h.Count); i += receptionReportLength {
@@ -162,7 +161,8 @@ func (r *ReceiverReport) len() int {
 	for _, rep := range r.Reports {
 		repsLength += rep.len()
 	}
-	return headerLength + ssrcLength + repsLength
+	peLength := len(r.ProfileExtensions) + getPadding(len(r.ProfileExtensions))
+	return headerLength + ssrcLength + repsLength + peLength
 }
 
 // Header returns the Header associated with this packet.
@@ -170,7 +170,7 @@ func (r *ReceiverReport) Header() Header {
 	return Header{
 		Count:  uint8(len(r.Reports)),
 		Type:   TypeReceiverReport,
-		Length: uint16((r.len()/4)-1) + uint16(getPadding(len(r.ProfileExtensions))),
+		Length: uint16((r.len() / 4) - 1),
 	}
 }
 
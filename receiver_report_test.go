@@ -142,6 +142,16 @@ func TestReceiverReportUnmarshal(t *testing.T) {
 			},
 			WantError: errInvalidHeader,
 		},
+		{
+			Name: "length too short for the ssrc",
+			Data: []byte{
+				// v=2, p=0, count=0, RR, len=0
+				0x80, 0xc9, 0x00, 0x00,
+				// bytes of a following packet
+				0x01, 0x02, 0x03, 0x04,
+			},
+			WantError: errPacketTooShort,
+		},
 		{
 			Name:      "nil",
 			Data:      nil,
@@ -218,6 +228,16 @@ func TestReceiverReportRoundTrip(t *testing.T) {
 			},
 			WantError: errInvalidTotalLost,
 		},
+		{
+			Name: "totallost wider than 24 bits",
+			Report: ReceiverReport{
+				SSRC: 1,
+				Reports: []ReceptionReport{{
+					TotalLost: 1 << 24,
+				}},
+			},
+			WantError: errInvalidTotalLost,
+		},
 		{
 			Name: "count overflow",
 			Report: ReceiverReport{
@@ -262,3 +282,43 @@ func init() {
 		})
 	}
 }
+
+func TestReceiverReportHeaderLength(t *testing.T) {
+	for _, test := range []struct {
+		Name       string
+		Report     ReceiverReport
+		WantLength uint16
+	}{
+		{
+			Name:       "no extensions",
+			Report:     ReceiverReport{SSRC: 1, Reports: []ReceptionReport{{SSRC: 2}}},
+			WantLength: 7,
+		},
+		{
+			Name:       "aligned extensions",
+			Report:     ReceiverReport{SSRC: 1, ProfileExtensions: []byte{1, 2, 3, 4}},
+			WantLength: 2,
+		},
+		{
+			Name:       "unaligned extensions",
+			Report:     ReceiverReport{SSRC: 1, ProfileExtensions: []byte{1, 2, 3, 4, 5}},
+			WantLength: 3,
+		},
+	} {
+		data, err := test.Report.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal %q: %v", test.Name, err)
+		}
+
+		var h Header
+		if err := h.Unmarshal(data); err != nil {
+			t.Fatalf("Unmarshal %q header: %v", test.Name, err)
+		}
+		if got, want := h.Length, test.WantLength; got != want {
+			t.Fatalf("%q rr header length: got %d, want %d", test.Name, got, want)
+		}
+		if got, want := len(data), int(h.Length+1)*4; got != want {
+			t.Fatalf("%q rr marshaled size: got %d, want %d", test.Name, got, want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package rtcp
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pion/rtcp/seqnum"
+)
+
+// RepairStreamPolicy selects how ReceiverStats reports on repair streams.
+type RepairStreamPolicy int
+
+const (
+	// RepairStreamsReported reports repair streams like media streams, as
+	// RFC 3550 has every source reported on.
+	RepairStreamsReported RepairStreamPolicy = iota
+	// RepairStreamsExcluded keeps no statistics on repair streams.
+	RepairStreamsExcluded
+	// RepairStreamsSeparate leaves repair streams out of ReceptionReports
+	// and returns their reports from RepairReceptionReports instead.
+	RepairStreamsSeparate
+)
+
+// ReceiverStats keeps the reception statistics of the RTP sources heard by a
+// local participant and builds the reception reports for its SenderReports
+// and ReceiverReports, as in RFC 3550, 6.4.
+//
+// Jitter is kept in the timestamp units of each stream, so ReceiverStats
+// needs the clock rates of the payload types the session uses, e.g. 48000
+// for Opus and 90000 for video. When a source switches to a payload type of
+// a different clock rate, its jitter estimate starts over.
+//
+// Repair streams, retransmissions (RTX, RFC 4588) and forward error
+// correction (FlexFEC, RFC 8627) sent with SSRCs of their own, are told
+// apart by their SSRC or payload type. Their sequence numbers only advance
+// when there is something to repair, so their loss says little about the
+// path and reports on them would mislead senders and monitoring alike;
+// RepairPolicy excludes them, or keeps their reports separate.
+//
+// A ReceiverStats isn't safe for concurrent use; see SyncReceiverStats.
+type ReceiverStats struct {
+	// ClockRates maps RTP payload types to their clock rate in Hz.
+	ClockRates map[uint8]uint32
+	// ClockRate optionally returns the clock rate of payload types missing
+	// from ClockRates. The jitter of payload types of unknown clock rate
+	// isn't computed.
+	ClockRate func(payloadType uint8) (uint32, bool)
+	// The source of the current time for the delay since the last
+	// SenderReport. If nil, SystemClock is used.
+	Clock Clock
+	// The SSRCs and payload types of repair streams, and how they are
+	// reported. A source sending a packet of a repair payload type is a
+	// repair stream from then on.
+	RepairSSRCs        map[uint32]bool
+	RepairPayloadTypes map[uint8]bool
+	RepairPolicy       RepairStreamPolicy
+
+	sources map[uint32]*receiverSource
+}
+
+type receiverSource struct {
+	sequence seqnum.Tracker
+	jitter   InterarrivalJitter
+	// only valid if hasSenderReport is set
+	lastSenderReport uint32
+	senderReportAt   time.Time
+	hasSenderReport  bool
+	repair           bool
+}
+
+// NewReceiverStats creates a ReceiverStats for a session with the given
+// payload type to clock rate mapping.
+func NewReceiverStats(clockRates map[uint8]uint32) *ReceiverStats {
+	return &ReceiverStats{ClockRates: clockRates}
+}
+
+// clockRate returns the clock rate of payloadType, or 0 if it isn't known
+func (s *ReceiverStats) clockRate(payloadType uint8) uint32 {
+	if rate, ok := s.ClockRates[payloadType]; ok {
+		return rate
+	}
+	if s.ClockRate != nil {
+		if rate, ok := s.ClockRate(payloadType); ok {
+			return rate
+		}
+	}
+	return 0
+}
+
+func (s *ReceiverStats) source(ssrc uint32) *receiverSource {
+	if s.sources == nil {
+		s.sources = make(map[uint32]*receiverSource)
+	}
+	src, ok := s.sources[ssrc]
+	if !ok {
+		src = &receiverSource{}
+		s.sources[ssrc] = src
+	}
+	return src
+}
+
+// Update records the arrival of an RTP packet of the source ssrc.
+func (s *ReceiverStats) Update(ssrc uint32, payloadType uint8, sequenceNumber uint16, rtpTimestamp uint32, arrival time.Time) {
+	repair := s.RepairSSRCs[ssrc] || s.RepairPayloadTypes[payloadType]
+	if repair && s.RepairPolicy == RepairStreamsExcluded {
+		return
+	}
+	src := s.source(ssrc)
+	src.repair = src.repair || repair
+	if !src.sequence.Update(sequenceNumber) {
+		return
+	}
+
+	rate := s.clockRate(payloadType)
+	if rate != src.jitter.ClockRate {
+		src.jitter = InterarrivalJitter{ClockRate: rate}
+	}
+	if rate != 0 {
+		src.jitter.Update(rtpTimestamp, arrival)
+	}
+}
+
+// OnRepaired records that the packet sequenceNumber of the source ssrc was
+// recovered by FEC, so it counts as received rather than lost. It doesn't
+// affect the jitter, which is about arrivals. Only valid sources are
+// counted; a source doesn't leave probation with recovered packets. Like
+// duplicates, a packet that arrives after it was recovered is counted
+// twice.
+func (s *ReceiverStats) OnRepaired(ssrc uint32, sequenceNumber uint16) {
+	src, ok := s.sources[ssrc]
+	if !ok || !src.sequence.Valid() {
+		return
+	}
+	src.sequence.Update(sequenceNumber)
+}
+
+// OnSenderReport records the arrival of a SenderReport, so the reports on
+// its source carry the LastSenderReport and Delay fields the sender computes
+// the round trip time from.
+func (s *ReceiverStats) OnSenderReport(sr *SenderReport, arrival time.Time) {
+	src := s.source(sr.SSRC)
+	src.lastSenderReport = uint32(sr.NTPTime >> 16)
+	src.senderReportAt = arrival
+	src.hasSenderReport = true
+}
+
+// Remove forgets the source ssrc, e.g. after it sent a Goodbye.
+func (s *ReceiverStats) Remove(ssrc uint32) {
+	delete(s.sources, ssrc)
+}
+
+// ReceptionReports returns a report for every valid source that sent RTP
+// packets since the previous call, ordered by SSRC, and starts a new
+// reporting interval. Unless RepairPolicy is RepairStreamsReported, repair
+// streams are left out. It can be used as Runner.ReceptionReports through a
+// SyncReceiverStats.
+func (s *ReceiverStats) ReceptionReports() []ReceptionReport {
+	return s.reports(func(src *receiverSource) bool {
+		return !src.repair || s.RepairPolicy == RepairStreamsReported
+	})
+}
+
+// RepairReceptionReports is like ReceptionReports, but returns the reports
+// on the repair streams left out of it when RepairPolicy is
+// RepairStreamsSeparate, and starts their new reporting interval. They can
+// be sent in a ReceiverReport of their own, or passed to monitoring only.
+func (s *ReceiverStats) RepairReceptionReports() []ReceptionReport {
+	if s.RepairPolicy != RepairStreamsSeparate {
+		return nil
+	}
+	return s.reports(func(src *receiverSource) bool {
+		return src.repair
+	})
+}
+
+// reports returns the reports on the sources include selects
+func (s *ReceiverStats) reports(include func(src *receiverSource) bool) []ReceptionReport {
+	now := clockNow(s.Clock)
+
+	var reports []ReceptionReport
+	for ssrc, src := range s.sources {
+		if !src.sequence.Valid() || !include(src) {
+			continue
+		}
+		expected, received := src.sequence.Interval()
+		if received == 0 {
+			continue
+		}
+
+		r := ReceptionReport{
+			SSRC:               ssrc,
+			FractionLost:       FractionLost(expected, received),
+			TotalLost:          CumulativeLost(src.sequence.Expected(), src.sequence.Received()),
+			LastSequenceNumber: src.sequence.ExtendedHighest(),
+			Jitter:             src.jitter.Jitter(),
+		}
+		if src.hasSenderReport {
+			r.LastSenderReport = src.lastSenderReport
+			if d := now.Sub(src.senderReportAt); d > 0 {
+				r.Delay = uint32(d * (1 << 16) / time.Second)
+			}
+		}
+		reports = append(reports, r)
+	}
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].SSRC < reports[j].SSRC })
+	return reports
+}
@@ -0,0 +1,185 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReceiverStats(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	now := start
+	s := NewReceiverStats(map[uint8]uint32{111: 48000, 96: 90000})
+	s.Clock = ClockFunc(func() time.Time { return now })
+
+	// 20ms audio frames arriving 1ms late every other packet, and video at
+	// 30 frames per second doing the same: the jitter is the same duration
+	// in units of each clock rate
+	for i := 0; i < 100; i++ {
+		late := time.Duration(i%2) * time.Millisecond
+		s.Update(1, 111, uint16(i), uint32(i*960), start.Add(time.Duration(i)*20*time.Millisecond+late))
+		s.Update(2, 96, uint16(i), uint32(i*3000), start.Add(time.Duration(i)*time.Second/30+late))
+	}
+	// packet 100 was lost
+	s.Update(1, 111, 101, 101*960, start.Add(101*20*time.Millisecond))
+
+	reports := s.ReceptionReports()
+	assert.Len(reports, 2)
+	assert.Equal(uint32(1), reports[0].SSRC)
+	assert.Equal(uint32(101), reports[0].LastSequenceNumber)
+	assert.Equal(int32(1), DecodeCumulativeLost(reports[0].TotalLost))
+	assert.Equal(FractionLost(101, 100), reports[0].FractionLost)
+	assert.InDelta(48, reports[0].Jitter, 4)
+
+	assert.Equal(uint32(2), reports[1].SSRC)
+	assert.Equal(uint8(0), reports[1].FractionLost)
+	assert.InDelta(90, reports[1].Jitter, 8)
+
+	// only sources heard since the previous report are reported
+	s.Update(2, 96, 100, 100*3000, start.Add(100*time.Second/30))
+	reports = s.ReceptionReports()
+	assert.Len(reports, 1)
+	assert.Equal(uint32(2), reports[0].SSRC)
+	assert.Empty(s.ReceptionReports())
+
+	// the last SR and the delay since it was received
+	s.OnSenderReport(&SenderReport{SSRC: 2, NTPTime: 0x1122334455667788}, now)
+	now = now.Add(time.Second / 2)
+	s.Update(2, 96, 101, 101*3000, start.Add(101*time.Second/30))
+	reports = s.ReceptionReports()
+	assert.Len(reports, 1)
+	assert.Equal(uint32(0x33445566), reports[0].LastSenderReport)
+	assert.Equal(uint32(1<<15), reports[0].Delay)
+
+	s.Remove(2)
+	s.Update(1, 111, 102, 102*960, start.Add(102*20*time.Millisecond))
+	reports = s.ReceptionReports()
+	assert.Len(reports, 1)
+	assert.Equal(uint32(1), reports[0].SSRC)
+}
+
+func TestReceiverStatsClockRate(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	s := &ReceiverStats{
+		ClockRate: func(payloadType uint8) (uint32, bool) {
+			if payloadType == 8 {
+				return 8000, true
+			}
+			return 0, false
+		},
+	}
+
+	// 20ms frames of PCMA, two thirds of them arriving 2ms late
+	for i := 0; i < 60; i++ {
+		late := time.Duration(i%3/2*2) * time.Millisecond
+		s.Update(1, 8, uint16(i), uint32(i*160), start.Add(time.Duration(i)*20*time.Millisecond+late))
+		// the clock rate of payload type 100 isn't known, so its jitter
+		// isn't computed
+		s.Update(2, 100, uint16(i), uint32(i*160), start.Add(time.Duration(i)*20*time.Millisecond+late))
+	}
+
+	reports := s.ReceptionReports()
+	assert.Len(reports, 2)
+	assert.NotZero(reports[0].Jitter)
+	assert.Zero(reports[1].Jitter)
+
+	// switching to a payload type of another clock rate restarts the
+	// estimate
+	s.ClockRates = map[uint8]uint32{9: 16000}
+	s.Update(1, 9, 60, 60*320, start.Add(60*20*time.Millisecond))
+	assert.Zero(s.ReceptionReports()[0].Jitter)
+}
+
+func TestReceiverStatsRepairStreams(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	update := func(s *ReceiverStats) {
+		for i := 0; i < 10; i++ {
+			at := start.Add(time.Duration(i) * 20 * time.Millisecond)
+			s.Update(1, 96, uint16(i), uint32(i*1800), at)
+			// RTX of 1 by payload type, one retransmission lost
+			if i != 7 {
+				s.Update(2, 97, uint16(i), uint32(i*1800), at)
+			}
+			// FlexFEC by SSRC
+			s.Update(3, 98, uint16(i), uint32(i*1800), at)
+		}
+	}
+	ssrcs := func(reports []ReceptionReport) []uint32 {
+		var out []uint32
+		for _, r := range reports {
+			out = append(out, r.SSRC)
+		}
+		return out
+	}
+	newStats := func(policy RepairStreamPolicy) *ReceiverStats {
+		s := NewReceiverStats(map[uint8]uint32{96: 90000, 97: 90000, 98: 90000})
+		s.RepairPayloadTypes = map[uint8]bool{97: true}
+		s.RepairSSRCs = map[uint32]bool{3: true}
+		s.RepairPolicy = policy
+		return s
+	}
+
+	// by default repair streams are reported like media
+	s := newStats(RepairStreamsReported)
+	update(s)
+	assert.Equal([]uint32{1, 2, 3}, ssrcs(s.ReceptionReports()))
+	assert.Empty(s.RepairReceptionReports())
+
+	s = newStats(RepairStreamsExcluded)
+	update(s)
+	assert.Equal([]uint32{1}, ssrcs(s.ReceptionReports()))
+	assert.Empty(s.RepairReceptionReports())
+	assert.Len(s.sources, 1)
+
+	s = newStats(RepairStreamsSeparate)
+	update(s)
+	reports := s.RepairReceptionReports()
+	assert.Equal([]uint32{2, 3}, ssrcs(reports))
+	assert.Equal(int32(1), DecodeCumulativeLost(reports[0].TotalLost))
+	assert.Equal([]uint32{1}, ssrcs(s.ReceptionReports()))
+	reports = s.ReceptionReports()
+	assert.Empty(reports)
+	assert.Empty(s.RepairReceptionReports())
+
+	// a source stays a repair stream when it sends another payload type
+	s.Update(2, 96, 10, 10*1800, start.Add(200*time.Millisecond))
+	assert.Empty(s.ReceptionReports())
+	assert.Equal([]uint32{2}, ssrcs(s.RepairReceptionReports()))
+}
+
+func TestReceiverStatsRepaired(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	s := NewReceiverStats(map[uint8]uint32{96: 90000})
+
+	// recovered packets of unknown sources are ignored
+	s.OnRepaired(1, 0)
+	assert.Empty(s.sources)
+
+	// 5 of 20 packets were lost, 3 of them recovered by FEC
+	for i := 0; i < 20; i++ {
+		if i%4 == 3 {
+			continue
+		}
+		s.Update(1, 96, uint16(i), uint32(i*3000), start.Add(time.Duration(i)*time.Second/30))
+	}
+	s.Update(1, 96, 20, 20*3000, start.Add(20*time.Second/30))
+	for _, seq := range []uint16{3, 11, 19} {
+		s.OnRepaired(1, seq)
+	}
+
+	reports := s.ReceptionReports()
+	assert.Len(reports, 1)
+	assert.Equal(int32(2), DecodeCumulativeLost(reports[0].TotalLost))
+	// the first packet was on probation
+	assert.Equal(FractionLost(20, 18), reports[0].FractionLost)
+	assert.Equal(uint32(20), reports[0].LastSequenceNumber)
+}
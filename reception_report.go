@@ -13,7 +13,8 @@ type ReceptionReport struct {
 	// number with the binary point at the left edge of the field.
 	FractionLost uint8
 	// The total number of RTP data packets from source SSRC that have
-	// been lost since the beginning of reception.
+	// been lost since the beginning of reception, as a 24 bit signed
+	// integer. See CumulativeLost and DecodeCumulativeLost.
 	TotalLost uint32
 	// The low 16 bits contain the highest sequence number received in an
 	// RTP data packet from source SSRC, and the most significant 16
@@ -22,7 +23,7 @@ type ReceptionReport struct {
 	LastSequenceNumber uint32
 	// An estimate of the statistical variance of the RTP data packet
 	// interarrival time, measured in timestamp units and expressed as an
-	// unsigned integer.
+	// unsigned integer. See InterarrivalJitter.
 	Jitter uint32
 	// The middle 32 bits out of 64 in the NTP timestamp received as part of
 	// the most recent RTCP sender report (SR) packet from source SSRC. If no
@@ -71,7 +72,7 @@ func (r ReceptionReport) Marshal() ([]byte, error) {
 	rawPacket[fractionLostOffset] = r.FractionLost
 
 	// pack TotalLost into 24 bits
-	if r.TotalLost >= (1 << 25) {
+	if r.TotalLost >= (1 << 24) {
 		return nil, errInvalidTotalLost
 	}
 	tlBytes := rawPacket[totalLostOffset:]
@@ -128,3 +129,56 @@ func (r *ReceptionReport) Unmarshal(rawPacket []byte) error {
 func (r *ReceptionReport) len() int {
 	return receptionReportLength
 }
+
+// SplitReceptionReports splits reports into runs of at most 31, the most a
+// single SenderReport or ReceiverReport carries. The runs share the storage
+// of reports.
+func SplitReceptionReports(reports []ReceptionReport) [][]ReceptionReport {
+	var out [][]ReceptionReport
+	for len(reports) > 0 {
+		n := len(reports)
+		if n > countMax {
+			n = countMax
+		}
+		out = append(out, reports[:n:n])
+		reports = reports[n:]
+	}
+	return out
+}
+
+// MergeReceptionReports concatenates the reports of several packets, e.g.
+// the SenderReport and the overflow ReceiverReports of one compound packet,
+// or the reports a mixer collected from several receivers, and removes the
+// duplicates, see DedupReceptionReports.
+func MergeReceptionReports(reports ...[]ReceptionReport) []ReceptionReport {
+	var merged []ReceptionReport
+	for _, r := range reports {
+		merged = append(merged, r...)
+	}
+	return DedupReceptionReports(merged)
+}
+
+// DedupReceptionReports returns one report for each SSRC in reports, in the
+// order the SSRCs first appear. Of several reports on the same SSRC the most
+// recent one is kept: the one with the highest extended sequence number,
+// allowing for wrap around, or the last one if they are equal. The result
+// doesn't share the storage of reports.
+func DedupReceptionReports(reports []ReceptionReport) []ReceptionReport {
+	if len(reports) == 0 {
+		return nil
+	}
+	out := make([]ReceptionReport, 0, len(reports))
+	index := make(map[uint32]int, len(reports))
+	for _, r := range reports {
+		i, ok := index[r.SSRC]
+		if !ok {
+			index[r.SSRC] = len(out)
+			out = append(out, r)
+			continue
+		}
+		if int32(r.LastSequenceNumber-out[i].LastSequenceNumber) >= 0 {
+			out[i] = r
+		}
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitReceptionReports(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(SplitReceptionReports(nil))
+
+	reports := make([]ReceptionReport, 63)
+	for i := range reports {
+		reports[i].SSRC = uint32(i)
+	}
+	split := SplitReceptionReports(reports)
+	assert.Equal([][]ReceptionReport{reports[:31], reports[31:62], reports[62:]}, split)
+
+	// appending to a run doesn't overwrite the next one
+	split[0] = append(split[0], ReceptionReport{SSRC: 1000})
+	assert.Equal(uint32(31), reports[31].SSRC)
+}
+
+func TestDedupReceptionReports(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(DedupReceptionReports(nil))
+
+	reports := []ReceptionReport{
+		{SSRC: 1, LastSequenceNumber: 10, Jitter: 1},
+		{SSRC: 2, LastSequenceNumber: 0xfffffff0},
+		{SSRC: 1, LastSequenceNumber: 12, Jitter: 2},
+		// older than the previous report on 1
+		{SSRC: 1, LastSequenceNumber: 11, Jitter: 3},
+		// the extended sequence number wrapped around
+		{SSRC: 2, LastSequenceNumber: 5, Jitter: 4},
+	}
+	assert.Equal([]ReceptionReport{
+		{SSRC: 1, LastSequenceNumber: 12, Jitter: 2},
+		{SSRC: 2, LastSequenceNumber: 5, Jitter: 4},
+	}, DedupReceptionReports(reports))
+
+	// equal sequence numbers keep the last report
+	assert.Equal([]ReceptionReport{{SSRC: 3, Jitter: 2}}, DedupReceptionReports([]ReceptionReport{
+		{SSRC: 3, Jitter: 1}, {SSRC: 3, Jitter: 2},
+	}))
+}
+
+func TestMergeReceptionReports(t *testing.T) {
+	merged := MergeReceptionReports(
+		[]ReceptionReport{{SSRC: 1, LastSequenceNumber: 4}, {SSRC: 2}},
+		nil,
+		[]ReceptionReport{{SSRC: 3}, {SSRC: 1, LastSequenceNumber: 5}},
+	)
+	assert.Equal(t, []ReceptionReport{
+		{SSRC: 1, LastSequenceNumber: 5},
+		{SSRC: 2},
+		{SSRC: 3},
+	}, merged)
+}
@@ -0,0 +1,507 @@
+package rtcp
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pion/rtcp/seqnum"
+)
+
+const (
+	// reference time is expressed in multiples of 64ms
+	referenceTimeResolution = 64 * time.Millisecond
+	referenceTimeMask       = (1 << 24) - 1
+
+	oneBitVectorSymbols = 14
+	twoBitVectorSymbols = 7
+	minRunLengthSymbols = twoBitVectorSymbols
+
+	// DefaultRecorderMaxPackets is the number of arrivals a Recorder holds
+	// between feedback when its MaxPackets is zero.
+	DefaultRecorderMaxPackets = 1 << 15
+	// DefaultRecorderRateWindow is the window a Recorder computes the
+	// receive bitrate over when its RateWindow is zero.
+	DefaultRecorderRateWindow = time.Second
+)
+
+// ReferenceTimeAnchor selects the origin of the reference time in the
+// feedback built by a Recorder.
+type ReferenceTimeAnchor int
+
+const (
+	// AnchorFirstArrival counts reference time from the first recorded
+	// arrival.
+	AnchorFirstArrival ReferenceTimeAnchor = iota
+	// AnchorEpoch counts reference time from Recorder.Epoch, so several
+	// recorders in a session share a time base.
+	AnchorEpoch
+	// AnchorWallClock counts reference time from the Unix epoch, as libwebrtc
+	// does. Arrival times must then carry a wall clock reading.
+	AnchorWallClock
+)
+
+// A Recorder records the arrival times of packets carrying a transport wide
+// sequence number, and builds TransportLayerCC feedback from them.
+//
+// Packets may be recorded in any order. Duplicates are ignored, as are
+// packets older than what previous feedback already covered.
+//
+// Transport wide sequence numbers are shared by every media source on the
+// transport, so one Recorder covers all of them when streams are bundled.
+// Packets can be tagged with their media SSRC using RecordSSRC.
+//
+// The reference time of each feedback is the arrival of its first packet
+// rounded down to the 64ms resolution of the field; the remainder is carried
+// into the first receive delta, as libwebrtc does, so arrival times aren't
+// biased by the coarse reference time.
+//
+// If feedback isn't built often enough, the oldest arrivals are evicted once
+// more than MaxPackets are held or they are older than MaxAge, and are then
+// treated as never having arrived.
+//
+// Packets recorded with their size, using RecordSize or RecordSSRCSize, are
+// also accounted for in the receive bitrate and byte counts, which a
+// receiver computing REMB can use alongside the feedback.
+//
+// Packets recorded with their ECN codepoint, using RecordECN, can be
+// reported with BuildCCFeedbackReport instead, as TransportLayerCC has no
+// room for it. Support for ECN is experimental.
+//
+// A Recorder isn't safe for concurrent use; see SyncRecorder.
+type Recorder struct {
+	// SSRC of the feedback sender
+	SenderSSRC uint32
+	// SSRC of the media source the feedback is sent for
+	MediaSSRC uint32
+	// If true, feedback is sent for the media SSRC of the most recently
+	// recorded packet that was tagged with one, as libwebrtc does when
+	// streams are bundled, and MediaSSRC is only used until a tagged packet
+	// arrived.
+	UseLatestMediaSSRC bool
+	// The source of arrival times for RecordNow. If nil, SystemClock is
+	// used.
+	Clock Clock
+	// The origin of reference times, AnchorFirstArrival by default. Anchor
+	// and Epoch must be set before the first packet is recorded.
+	Anchor ReferenceTimeAnchor
+	// The origin of reference times with AnchorEpoch. Arrivals must be
+	// within about 290 years of it.
+	Epoch time.Time
+	// The maximum number of arrivals held until the next feedback. If zero,
+	// DefaultRecorderMaxPackets is used.
+	MaxPackets int
+	// How much older than the newest arrival others may be before they're
+	// evicted. If zero, arrivals don't expire.
+	MaxAge time.Duration
+	// The window Bitrate is computed over. If zero,
+	// DefaultRecorderRateWindow is used.
+	RateWindow time.Duration
+	// Logger receives evictions. If nil, NopLogger is used.
+	Logger Logger
+
+	arrivals  map[int64]recordedPacket
+	evictions uint64
+	// range of the recorded sequence numbers, and the latest arrival
+	oldest        int64
+	newest        int64
+	latestArrival time.Time
+
+	unwrapper seqnum.Unwrapper
+	startTime time.Time
+	anchored  bool
+	// media SSRC of the most recently recorded tagged packet
+	latestSSRC uint32
+	hasLatest  bool
+
+	// first sequence number not covered by feedback yet
+	nextSeq    int64
+	reported   bool
+	fbPktCount uint8
+
+	// sized arrivals within the rate window, and the bytes recorded since
+	// the previous call to IntervalBytes
+	rate          bandwidthWindow
+	intervalBytes int
+}
+
+type recordedPacket struct {
+	arrival time.Time
+	ssrc    uint32
+	tagged  bool
+	ecn     ECN
+}
+
+// NewRecorder creates a Recorder that sends feedback as senderSSRC.
+func NewRecorder(senderSSRC uint32) *Recorder {
+	return &Recorder{
+		SenderSSRC: senderSSRC,
+		arrivals:   map[int64]recordedPacket{},
+	}
+}
+
+// Record records that the packet with transport wide sequence number seq
+// arrived at the given time.
+func (r *Recorder) Record(seq uint16, arrival time.Time) {
+	r.record(seq, recordedPacket{arrival: arrival})
+}
+
+// RecordSSRC records that the packet with transport wide sequence number
+// seq, sent by the media source ssrc, arrived at the given time.
+func (r *Recorder) RecordSSRC(seq uint16, ssrc uint32, arrival time.Time) {
+	r.record(seq, recordedPacket{arrival: arrival, ssrc: ssrc, tagged: true})
+}
+
+// RecordSize records that the packet with transport wide sequence number
+// seq and size bytes arrived at the given time.
+func (r *Recorder) RecordSize(seq uint16, size int, arrival time.Time) {
+	if r.record(seq, recordedPacket{arrival: arrival}) {
+		r.recordSize(size, arrival)
+	}
+}
+
+// RecordSSRCSize records that the packet with transport wide sequence number
+// seq and size bytes, sent by the media source ssrc, arrived at the given
+// time.
+func (r *Recorder) RecordSSRCSize(seq uint16, ssrc uint32, size int, arrival time.Time) {
+	if r.record(seq, recordedPacket{arrival: arrival, ssrc: ssrc, tagged: true}) {
+		r.recordSize(size, arrival)
+	}
+}
+
+// RecordECN records that the packet with transport wide sequence number seq
+// arrived at the given time with the ECN codepoint ecn, as read from the
+// socket control messages, see ECNFromTrafficClass.
+func (r *Recorder) RecordECN(seq uint16, ecn ECN, arrival time.Time) {
+	r.record(seq, recordedPacket{arrival: arrival, ecn: ecn})
+}
+
+// record adds p, and reports whether it was recorded rather than ignored
+func (r *Recorder) record(seq uint16, p recordedPacket) bool {
+	if r.arrivals == nil {
+		r.arrivals = map[int64]recordedPacket{}
+	}
+
+	unwrapped := r.unwrapper.Unwrap(seq)
+	if r.reported && unwrapped < r.nextSeq {
+		return false
+	}
+	if _, ok := r.arrivals[unwrapped]; ok {
+		return false
+	}
+	if !r.anchored {
+		r.startTime = r.anchorTime(p.arrival)
+		r.anchored = true
+	}
+	if p.tagged {
+		r.latestSSRC, r.hasLatest = p.ssrc, true
+	}
+	if len(r.arrivals) == 0 {
+		r.oldest, r.newest, r.latestArrival = unwrapped, unwrapped, p.arrival
+	}
+	r.arrivals[unwrapped] = p
+	if unwrapped < r.oldest {
+		r.oldest = unwrapped
+	}
+	if unwrapped > r.newest {
+		r.newest = unwrapped
+	}
+	if p.arrival.After(r.latestArrival) {
+		r.latestArrival = p.arrival
+	}
+	r.evict()
+	return true
+}
+
+func (r *Recorder) recordSize(size int, arrival time.Time) {
+	r.rate.add(arrival, size)
+	r.rate.expire(r.latestArrival.Add(-r.rateWindow()))
+	r.intervalBytes += size
+}
+
+func (r *Recorder) rateWindow() time.Duration {
+	if r.RateWindow <= 0 {
+		return DefaultRecorderRateWindow
+	}
+	return r.RateWindow
+}
+
+// Bitrate returns the receive bitrate in bits per second of the packets
+// recorded with their size, averaged over the RateWindow ending at the
+// latest arrival.
+func (r *Recorder) Bitrate() float64 {
+	return float64(r.rate.total) * 8 / r.rateWindow().Seconds()
+}
+
+// IntervalBytes returns the bytes of the packets recorded with their size
+// since the previous call, and starts a new interval.
+func (r *Recorder) IntervalBytes() int {
+	n := r.intervalBytes
+	r.intervalBytes = 0
+	return n
+}
+
+// evict drops the oldest arrivals until the recorder is within its limits
+func (r *Recorder) evict() {
+	maxPackets := r.MaxPackets
+	if maxPackets <= 0 {
+		maxPackets = DefaultRecorderMaxPackets
+	}
+	for ; r.oldest <= r.newest; r.oldest++ {
+		p, ok := r.arrivals[r.oldest]
+		if !ok {
+			continue
+		}
+		if len(r.arrivals) <= maxPackets &&
+			(r.MaxAge <= 0 || r.latestArrival.Sub(p.arrival) <= r.MaxAge) {
+			return
+		}
+		if debugChecks {
+			loggerOr(r.Logger).Debug("rtcp: recorder evicting arrival", "seq", uint16(r.oldest), "held", len(r.arrivals))
+		}
+		delete(r.arrivals, r.oldest)
+		r.evictions++
+	}
+}
+
+// Evictions returns the number of arrivals dropped because feedback wasn't
+// built before the recorder reached its limits.
+func (r *Recorder) Evictions() uint64 {
+	return r.evictions
+}
+
+// SSRCs returns the media SSRCs of the tagged packets recorded since the
+// previous feedback, in transport wide sequence number order of their first
+// packet.
+func (r *Recorder) SSRCs() []uint32 {
+	seqs := r.sortedSeqs()
+	var out []uint32
+	seen := map[uint32]bool{}
+	for _, seq := range seqs {
+		p := r.arrivals[seq]
+		if p.tagged && !seen[p.ssrc] {
+			seen[p.ssrc] = true
+			out = append(out, p.ssrc)
+		}
+	}
+	return out
+}
+
+// anchorTime returns the origin of reference times given the first arrival
+func (r *Recorder) anchorTime(first time.Time) time.Time {
+	switch r.Anchor {
+	case AnchorEpoch:
+		return r.Epoch
+	case AnchorWallClock:
+		return time.Unix(0, 0)
+	default:
+		return first
+	}
+}
+
+// mediaSSRC returns the media SSRC to send feedback for
+func (r *Recorder) mediaSSRC() uint32 {
+	if r.UseLatestMediaSSRC && r.hasLatest {
+		return r.latestSSRC
+	}
+	return r.MediaSSRC
+}
+
+// sortedSeqs returns the unwrapped sequence numbers recorded, in order
+func (r *Recorder) sortedSeqs() []int64 {
+	seqs := make([]int64, 0, len(r.arrivals))
+	for seq := range r.arrivals {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs
+}
+
+// RecordNow records that the packet with transport wide sequence number seq
+// arrived at the current time of Clock.
+func (r *Recorder) RecordNow(seq uint16) {
+	r.Record(seq, clockNow(r.Clock))
+}
+
+// BuildFeedback returns TransportLayerCC packets covering every packet
+// recorded since the previous call, and resets the recorded arrivals.
+// Packets missing between recorded ones are reported as lost. It returns
+// nil if nothing was recorded.
+func (r *Recorder) BuildFeedback() []*TransportLayerCC {
+	if len(r.arrivals) == 0 {
+		return nil
+	}
+
+	seqs, next := r.pending()
+	var out []*TransportLayerCC
+	for i := 0; i < len(seqs); {
+		var fb *TransportLayerCC
+		fb, i, next = r.buildPacket(seqs, i, next)
+		out = append(out, fb)
+	}
+
+	r.reset(next)
+	return out
+}
+
+// BuildCCFeedbackReport returns RFC 8888 feedback, with the arrival time
+// and ECN codepoint of every packet recorded since the previous feedback,
+// and resets the recorded arrivals like BuildFeedback. The recorded
+// sequence numbers are reported as the RTP sequence numbers of the media
+// SSRC, so a recorder building them should track a single RTP stream. The
+// arrival time offsets count back from reportTime, the time the feedback is
+// sent, on the clock of the arrivals. It returns nil if nothing was
+// recorded.
+func (r *Recorder) BuildCCFeedbackReport(reportTime time.Time) *CCFeedbackReport {
+	if len(r.arrivals) == 0 {
+		return nil
+	}
+
+	seqs, next := r.pending()
+	fb := &CCFeedbackReport{
+		SenderSSRC:      r.SenderSSRC,
+		ReportTimestamp: uint32(ntpTime(reportTime) >> 16),
+	}
+	for i := 0; i < len(seqs); {
+		// a gap a block can't span isn't reported
+		if seqs[i]-next >= ccfbMaxMetricBlocks {
+			next = seqs[i]
+		}
+		block := CCFeedbackReportBlock{MediaSSRC: r.mediaSSRC(), BeginSequence: uint16(next)}
+		for ; i < len(seqs) && seqs[i]-next < ccfbMaxMetricBlocks; i++ {
+			for len(block.MetricBlocks) < int(seqs[i]-next) {
+				block.MetricBlocks = append(block.MetricBlocks, CCFeedbackMetricBlock{})
+			}
+			p := r.arrivals[seqs[i]]
+			block.MetricBlocks = append(block.MetricBlocks, CCFeedbackMetricBlock{
+				Received:          true,
+				ECN:               p.ecn,
+				ArrivalTimeOffset: arrivalTimeOffset(reportTime.Sub(p.arrival)),
+			})
+		}
+		next += int64(len(block.MetricBlocks))
+		fb.ReportBlocks = append(fb.ReportBlocks, block)
+	}
+
+	r.reset(next)
+	return fb
+}
+
+// arrivalTimeOffset converts d to the nearest 1/1024 seconds, saturating at
+// ArrivalTimeOffsetOverRange
+func arrivalTimeOffset(d time.Duration) uint16 {
+	if d < 0 {
+		return 0
+	}
+	ato := (d*1024 + time.Second/2) / time.Second
+	if ato >= ArrivalTimeOffsetOverRange {
+		return ArrivalTimeOffsetOverRange
+	}
+	return uint16(ato)
+}
+
+// pending returns the unwrapped sequence numbers recorded, in order, and
+// the first sequence number the next feedback covers
+func (r *Recorder) pending() ([]int64, int64) {
+	seqs := r.sortedSeqs()
+	if r.reported {
+		return seqs, r.nextSeq
+	}
+	return seqs, seqs[0]
+}
+
+// reset forgets the recorded arrivals once feedback covered them up to next
+func (r *Recorder) reset(next int64) {
+	r.nextSeq = next
+	r.reported = true
+	r.arrivals = map[int64]recordedPacket{}
+}
+
+// buildPacket builds a single feedback packet starting at seqs[i], with next
+// being the first sequence number it covers. It returns the index and
+// sequence number where the following packet has to start.
+func (r *Recorder) buildPacket(seqs []int64, i int, next int64) (*TransportLayerCC, int, int64) {
+	b := NewTransportLayerCCBuilder(uint16(next))
+	b.SenderSSRC = r.SenderSSRC
+	b.MediaSSRC = r.mediaSSRC()
+	b.FbPktCount = r.fbPktCount
+	b.Rounding = RoundingNearest
+	r.fbPktCount++
+
+	for ; i < len(seqs); i++ {
+		seq := seqs[i]
+		if seq-next >= math.MaxUint16 {
+			break
+		}
+		if err := b.AddReceived(uint16(seq), r.arrivals[seq].arrival.Sub(r.startTime)); err != nil {
+			break
+		}
+	}
+
+	return b.Build(), i, next + int64(b.Len())
+}
+
+// encodeStatusChunks packs status symbols into run length and status vector
+// chunks.
+func encodeStatusChunks(symbols []PacketStatusSymbol) []PacketStatusChunk {
+	var chunks []PacketStatusChunk
+	for i := 0; i < len(symbols); {
+		run := 1
+		for i+run < len(symbols) && symbols[i+run] == symbols[i] && run < maxRunLength {
+			run++
+		}
+		if run >= minRunLengthSymbols || i+run == len(symbols) {
+			chunks = append(chunks, &RunLengthChunk{
+				Type:               typeRunLengthChunk,
+				PacketStatusSymbol: symbols[i],
+				RunLength:          uint16(run),
+			})
+			i += run
+			continue
+		}
+
+		n := oneBitVectorSymbols
+		if n > len(symbols)-i {
+			n = len(symbols) - i
+		}
+		oneBit := true
+		for _, s := range symbols[i : i+n] {
+			if s != TypePacketNotReceived && s != TypePacketReceivedSmallDelta {
+				oneBit = false
+				break
+			}
+		}
+
+		if oneBit {
+			list := append([]PacketStatusSymbol{}, symbols[i:i+n]...)
+			chunks = append(chunks, &StatusVectorChunk{
+				Type:       typeStatusVectorChunk,
+				SymbolSize: typeSymbolSizeOneBit,
+				SymbolList: list,
+			})
+			i += n
+			continue
+		}
+
+		n = twoBitVectorSymbols
+		if n > len(symbols)-i {
+			n = len(symbols) - i
+		}
+		list := append([]PacketStatusSymbol{}, symbols[i:i+n]...)
+		chunks = append(chunks, &StatusVectorChunk{
+			Type:       typeStatusVectorChunk,
+			SymbolSize: typeSymbolSizeTwoBit,
+			SymbolList: list,
+		})
+		i += n
+	}
+	return chunks
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,335 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderOutOfOrderAndDuplicates(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	r := NewRecorder(1)
+	r.MediaSSRC = 2
+
+	r.Record(2, start)
+	r.Record(1, start.Add(5*time.Millisecond))
+	r.Record(2, start.Add(6*time.Millisecond)) // duplicate
+	r.Record(5, start.Add(7*time.Millisecond))
+	r.Record(4, start.Add(20*time.Millisecond))
+
+	feedback := r.BuildFeedback()
+	assert.Len(feedback, 1)
+
+	fb := feedback[0]
+	assert.Equal(uint32(1), fb.SenderSSRC)
+	assert.Equal(uint32(2), fb.MediaSSRC)
+	assert.Equal(uint16(1), fb.BaseSequenceNumber)
+	assert.Equal(uint16(5), fb.PacketStatusCount)
+	assert.Equal(uint32(0), fb.ReferenceTime)
+	assert.Equal(uint8(0), fb.FbPktCount)
+
+	assert.Equal(map[uint16]time.Duration{
+		1: 5 * time.Millisecond,
+		2: 0,
+		4: 20 * time.Millisecond,
+		5: 7 * time.Millisecond,
+	}, fb.ArrivalTimes())
+	assert.Equal([]uint16{3}, fb.Lost())
+
+	// arrived earlier than the previous packet in sequence order
+	assert.Equal(TypePacketReceivedLargeDelta, fb.RecvDeltas[1].Type)
+	assert.Equal(int64(-5000), fb.RecvDeltas[1].Delta)
+
+	// late and duplicate packets are dropped, gaps since the last
+	// feedback are reported as lost
+	r.Record(3, start.Add(30*time.Millisecond))
+	r.Record(8, start.Add(130*time.Millisecond))
+	r.Record(8, start.Add(131*time.Millisecond))
+
+	feedback = r.BuildFeedback()
+	assert.Len(feedback, 1)
+	fb = feedback[0]
+	assert.Equal(uint16(6), fb.BaseSequenceNumber)
+	assert.Equal(uint16(3), fb.PacketStatusCount)
+	assert.Equal(uint32(2), fb.ReferenceTime)
+	assert.Equal(uint8(1), fb.FbPktCount)
+	assert.Equal(map[uint16]time.Duration{8: 2 * time.Millisecond}, fb.ArrivalTimes())
+	assert.Equal([]uint16{6, 7}, fb.Lost())
+
+	assert.Nil(r.BuildFeedback())
+}
+
+func TestRecorderWraparound(t *testing.T) {
+	start := time.Unix(1000, 0)
+	r := NewRecorder(1)
+	r.Record(65535, start)
+	r.Record(1, start.Add(time.Millisecond))
+	r.Record(0, start.Add(2*time.Millisecond))
+
+	feedback := r.BuildFeedback()
+	assert.Len(t, feedback, 1)
+	assert.Equal(t, uint16(65535), feedback[0].BaseSequenceNumber)
+	assert.Equal(t, map[uint16]time.Duration{
+		65535: 0,
+		0:     2 * time.Millisecond,
+		1:     time.Millisecond,
+	}, feedback[0].ArrivalTimes())
+}
+
+func TestRecorderSplitsLargeGaps(t *testing.T) {
+	start := time.Unix(1000, 0)
+	r := NewRecorder(1)
+	r.Record(1, start)
+	r.Record(2, start.Add(10*time.Second))
+
+	feedback := r.BuildFeedback()
+	assert.Len(t, feedback, 2)
+	assert.Equal(t, uint16(1), feedback[0].PacketStatusCount)
+	assert.Equal(t, uint16(2), feedback[1].BaseSequenceNumber)
+	assert.Equal(t, uint32(10*time.Second/referenceTimeResolution), feedback[1].ReferenceTime)
+	assert.Equal(t, uint8(1), feedback[1].FbPktCount)
+}
+
+func TestEncodeStatusChunks(t *testing.T) {
+	assert := assert.New(t)
+
+	// long runs use run length chunks
+	symbols := make([]PacketStatusSymbol, 20)
+	for i := range symbols {
+		symbols[i] = TypePacketReceivedSmallDelta
+	}
+	assert.Equal([]PacketStatusChunk{
+		&RunLengthChunk{Type: typeRunLengthChunk, PacketStatusSymbol: TypePacketReceivedSmallDelta, RunLength: 20},
+	}, encodeStatusChunks(symbols))
+
+	// runs are split at the largest run length a chunk can hold
+	for _, test := range []struct {
+		n    int
+		want []uint16
+	}{
+		{n: maxRunLength, want: []uint16{maxRunLength}},
+		{n: maxRunLength + 1, want: []uint16{maxRunLength, 1}},
+		{n: 2*maxRunLength + 7, want: []uint16{maxRunLength, maxRunLength, 7}},
+	} {
+		symbols := make([]PacketStatusSymbol, test.n)
+		var got []uint16
+		for _, chunk := range encodeStatusChunks(symbols) {
+			run, ok := chunk.(*RunLengthChunk)
+			assert.True(ok)
+			_, err := run.Marshal()
+			assert.NoError(err)
+			got = append(got, run.RunLength)
+		}
+		assert.Equal(test.want, got, "run of %d", test.n)
+	}
+
+	// mixed small deltas and losses use one bit vectors, which are only
+	// partially filled at the end
+	assert.Equal([]PacketStatusChunk{
+		&StatusVectorChunk{Type: typeStatusVectorChunk, SymbolSize: typeSymbolSizeOneBit, SymbolList: []PacketStatusSymbol{1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0}},
+		&StatusVectorChunk{Type: typeStatusVectorChunk, SymbolSize: typeSymbolSizeOneBit, SymbolList: []PacketStatusSymbol{1, 0, 1}},
+	}, encodeStatusChunks([]PacketStatusSymbol{1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1}))
+
+	// large deltas need two bit vectors
+	assert.Equal([]PacketStatusChunk{
+		&StatusVectorChunk{Type: typeStatusVectorChunk, SymbolSize: typeSymbolSizeTwoBit, SymbolList: []PacketStatusSymbol{1, 2}},
+	}, encodeStatusChunks([]PacketStatusSymbol{1, 2}))
+}
+
+func TestRecorderRecordNow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := NewRecorder(1)
+	r.Clock = ClockFunc(func() time.Time { return now })
+
+	r.RecordNow(7)
+	now = now.Add(10 * time.Millisecond)
+	r.RecordNow(8)
+
+	feedback := r.BuildFeedback()
+	assert.Len(t, feedback, 1)
+	assert.Equal(t, map[uint16]time.Duration{
+		7: 0,
+		8: 10 * time.Millisecond,
+	}, feedback[0].ArrivalTimes())
+}
+
+func TestRecorderMultipleMediaSSRCs(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	r := NewRecorder(1)
+	r.MediaSSRC = 9
+
+	// audio and video share the transport wide sequence
+	r.RecordSSRC(11, 200, start.Add(time.Millisecond))
+	r.RecordSSRC(10, 100, start)
+	r.RecordSSRC(12, 100, start.Add(2*time.Millisecond))
+	r.Record(13, start.Add(3*time.Millisecond))
+	assert.Equal([]uint32{100, 200}, r.SSRCs())
+
+	feedback := r.BuildFeedback()
+	assert.Len(feedback, 1)
+	assert.Equal(uint32(9), feedback[0].MediaSSRC)
+	assert.Equal(uint16(10), feedback[0].BaseSequenceNumber)
+	assert.Equal(uint16(4), feedback[0].PacketStatusCount)
+	assert.Empty(r.SSRCs())
+
+	r.UseLatestMediaSSRC = true
+	r.RecordSSRC(14, 100, start.Add(4*time.Millisecond))
+	r.RecordSSRC(15, 200, start.Add(5*time.Millisecond))
+	r.Record(16, start.Add(6*time.Millisecond))
+	feedback = r.BuildFeedback()
+	assert.Len(feedback, 1)
+	assert.Equal(uint32(200), feedback[0].MediaSSRC)
+
+	// MediaSSRC is used until a tagged packet arrived
+	untagged := NewRecorder(1)
+	untagged.MediaSSRC = 9
+	untagged.UseLatestMediaSSRC = true
+	untagged.Record(1, start)
+	assert.Equal(uint32(9), untagged.BuildFeedback()[0].MediaSSRC)
+}
+
+func TestRecorderReferenceTimeAnchor(t *testing.T) {
+	assert := assert.New(t)
+
+	epoch := time.Unix(2000, 0)
+	// 1s and 10.25ms after the epoch
+	first := epoch.Add(time.Second + 10250*time.Microsecond)
+
+	for _, test := range []struct {
+		Name      string
+		Anchor    ReferenceTimeAnchor
+		Reference uint32
+	}{
+		{"first arrival", AnchorFirstArrival, 0},
+		// 1010.25ms is 15 * 64ms + 50.25ms
+		{"epoch", AnchorEpoch, 15},
+		// 2001010.25ms is 31265 * 64ms + 50.25ms
+		{"wall clock", AnchorWallClock, 31265},
+	} {
+		r := NewRecorder(1)
+		r.Anchor = test.Anchor
+		r.Epoch = epoch
+		r.Record(1, first)
+		r.Record(2, first.Add(time.Millisecond))
+
+		feedback := r.BuildFeedback()
+		assert.Len(feedback, 1, test.Name)
+		fb := feedback[0]
+		assert.Equal(test.Reference, fb.ReferenceTime, test.Name)
+
+		// the remainder below the reference time resolution is carried in
+		// the first delta, so arrivals are exact relative to the anchor
+		reference := time.Duration(fb.ReferenceTime) * referenceTimeResolution
+		arrivals := fb.ArrivalTimes()
+		if test.Anchor != AnchorFirstArrival {
+			assert.Equal(int64(50250), fb.RecvDeltas[0].Delta, test.Name)
+		}
+		assert.Equal(time.Millisecond, arrivals[2]-arrivals[1], test.Name)
+		switch test.Anchor {
+		case AnchorEpoch:
+			assert.Equal(first.Sub(epoch), reference+arrivals[1], test.Name)
+		case AnchorWallClock:
+			assert.Equal(first.Sub(time.Unix(0, 0)), reference+arrivals[1], test.Name)
+		}
+	}
+}
+
+func TestRecorderLimits(t *testing.T) {
+	assert := assert.New(t)
+	start := time.Unix(1000, 0)
+
+	r := NewRecorder(1)
+	r.MaxPackets = 4
+	for i := 0; i < 10; i++ {
+		r.Record(uint16(i), start.Add(time.Duration(i)*time.Millisecond))
+	}
+	assert.Equal(uint64(6), r.Evictions())
+	feedback := r.BuildFeedback()
+	assert.Len(feedback, 1)
+	assert.Equal(uint16(6), feedback[0].BaseSequenceNumber)
+	assert.Equal(uint16(4), feedback[0].PacketStatusCount)
+
+	r = NewRecorder(1)
+	r.MaxAge = 100 * time.Millisecond
+	r.Record(0, start)
+	r.Record(1, start.Add(50*time.Millisecond))
+	r.Record(2, start.Add(120*time.Millisecond))
+	assert.Equal(uint64(1), r.Evictions())
+	feedback = r.BuildFeedback()
+	assert.Len(feedback, 1)
+	assert.Equal(uint16(1), feedback[0].BaseSequenceNumber)
+	assert.Equal(uint16(2), feedback[0].PacketStatusCount)
+}
+
+func TestRecorderSizes(t *testing.T) {
+	assert := assert.New(t)
+	start := time.Unix(1000, 0)
+
+	r := NewRecorder(1)
+	r.RateWindow = 100 * time.Millisecond
+	// 1000 bytes every 10ms is 800kbps
+	for i := 0; i < 20; i++ {
+		r.RecordSize(uint16(i), 1000, start.Add(time.Duration(i)*10*time.Millisecond))
+	}
+	assert.InDelta(800000, r.Bitrate(), 1)
+	assert.Equal(20000, r.IntervalBytes())
+	assert.Equal(0, r.IntervalBytes())
+
+	// duplicates and packets recorded without a size aren't counted
+	r.RecordSize(19, 1000, start.Add(200*time.Millisecond))
+	r.Record(20, start.Add(200*time.Millisecond))
+	assert.Equal(0, r.IntervalBytes())
+
+	// the sizes don't affect the feedback, and survive building it
+	feedback := r.BuildFeedback()
+	assert.Len(feedback, 1)
+	assert.Equal(uint16(21), feedback[0].PacketStatusCount)
+	r.RecordSSRCSize(21, 5, 500, start.Add(250*time.Millisecond))
+	assert.Equal([]uint32{5}, r.SSRCs())
+	assert.Equal(500, r.IntervalBytes())
+	// only 500 bytes at 250ms and 1000 bytes at 160ms-190ms are in the window
+	assert.InDelta(float64(500+4*1000)*8*10, r.Bitrate(), 1)
+}
+
+func TestRecorderCCFeedbackReport(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	r := NewRecorder(1)
+	r.MediaSSRC = 2
+	r.RecordECN(10, ECNECT0, start)
+	r.Record(12, start.Add(10*time.Second))
+	r.Record(13, start.Add(11*time.Second))
+
+	fb := r.BuildCCFeedbackReport(start.Add(11 * time.Second))
+	assert.Equal(&CCFeedbackReport{
+		SenderSSRC: 1,
+		ReportBlocks: []CCFeedbackReportBlock{{
+			MediaSSRC:     2,
+			BeginSequence: 10,
+			MetricBlocks: []CCFeedbackMetricBlock{
+				{Received: true, ECN: ECNECT0, ArrivalTimeOffset: ArrivalTimeOffsetOverRange},
+				{},
+				{Received: true, ArrivalTimeOffset: 1024},
+				{Received: true},
+			},
+		}},
+		ReportTimestamp: uint32(ntpTime(start.Add(11*time.Second)) >> 16),
+	}, fb)
+
+	// the next feedback continues where this one stopped, and gaps too
+	// large for a block aren't reported
+	r.Record(15, start)
+	r.Record(20000, start)
+	fb = r.BuildCCFeedbackReport(start)
+	assert.Len(fb.ReportBlocks, 2)
+	assert.Equal(uint16(14), fb.ReportBlocks[0].BeginSequence)
+	assert.Len(fb.ReportBlocks[0].MetricBlocks, 2)
+	assert.Equal(uint16(20000), fb.ReportBlocks[1].BeginSequence)
+	assert.Len(fb.ReportBlocks[1].MetricBlocks, 1)
+}
@@ -0,0 +1,182 @@
+package rtcp
+
+import "sync"
+
+var applicationDecoders = struct {
+	sync.RWMutex
+	byName map[string]func() Packet
+}{
+	byName: map[string]func() Packet{
+		googApplicationName: func() Packet { return new(GoogApplicationDefined) },
+	},
+}
+
+// RegisterApplicationDefined registers a decoder for APP packets with the
+// given four character name. Unmarshal and Decoder pass matching APP packets,
+// including their header, to the Unmarshal method of a packet returned by
+// newPacket. If that fails, the packet is returned as an ApplicationDefined,
+// so an unexpected payload doesn't fail the whole compound packet.
+//
+// Registering a name again replaces its decoder; a nil newPacket removes it.
+// APP packets named "goog" decode to GoogApplicationDefined by default.
+func RegisterApplicationDefined(name string, newPacket func() Packet) error {
+	if len(name) != appNameLength {
+		return errAppNameLength
+	}
+
+	applicationDecoders.Lock()
+	defer applicationDecoders.Unlock()
+	if newPacket == nil {
+		delete(applicationDecoders.byName, name)
+		return nil
+	}
+	applicationDecoders.byName[name] = newPacket
+	return nil
+}
+
+// unmarshalApplicationDefined decodes an APP packet with its registered
+// decoder, if any, and reports whether it succeeded
+func unmarshalApplicationDefined(rawPacket []byte) (Packet, bool) {
+	if len(rawPacket) < appDataOffset {
+		return nil, false
+	}
+
+	applicationDecoders.RLock()
+	newPacket, ok := applicationDecoders.byName[string(rawPacket[appNameOffset:appDataOffset])]
+	applicationDecoders.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	p := newPacket()
+	if err := p.Unmarshal(rawPacket); err != nil {
+		return nil, false
+	}
+	return p, true
+}
+
+// packetKey identifies the packets a decoder registered with RegisterPacket
+// handles
+type packetKey struct {
+	typ    PacketType
+	format uint8
+}
+
+// packetDecoder creates the packets for a packetKey. The errors of builtin
+// decoders are returned, not hidden behind the RawPacket fallback, since
+// the package would have decoded these packets itself.
+type packetDecoder struct {
+	newPacket func() Packet
+	builtin   bool
+}
+
+var packetDecoders = struct {
+	sync.RWMutex
+	byKey map[packetKey]packetDecoder
+}{
+	byKey: map[packetKey]packetDecoder{
+		{TypeTransportSpecificFeedback, FormatRAMS}:  {func() Packet { return new(RapidAcquisition) }, true},
+		{TypeTransportSpecificFeedback, FormatCCFB}:  {func() Packet { return new(CCFeedbackReport) }, true},
+		{TypeToken, uint8(PortMappingRequest)}:       {func() Packet { return new(PortMapping) }, true},
+		{TypeToken, uint8(PortMappingResponse)}:      {func() Packet { return new(PortMapping) }, true},
+		{TypeToken, uint8(TokenVerificationRequest)}: {func() Packet { return new(PortMapping) }, true},
+		{TypeToken, uint8(TokenVerificationFailure)}: {func() Packet { return new(PortMapping) }, true},
+	},
+}
+
+// RegisterPacket registers a decoder for packets of the given type whose
+// header count field, the FMT of feedback messages, equals format. Unmarshal
+// and Decoder pass matching packets to the Unmarshal method of a packet
+// returned by newPacket. If that fails, the packet is decoded as it would
+// have been without the registration, usually as a RawPacket.
+//
+// Registering a type and format again replaces its decoder; a nil newPacket
+// removes it. APP packets go to the decoders of RegisterApplicationDefined
+// first. RAMS feedback decodes to RapidAcquisition, RFC 8888 feedback to
+// CCFeedbackReport and TOKEN packets to PortMapping by default; unlike
+// registered decoders, these fail the packet when it is malformed.
+func RegisterPacket(typ PacketType, format uint8, newPacket func() Packet) error {
+	if format > countMax {
+		return errBadFormat
+	}
+
+	packetDecoders.Lock()
+	defer packetDecoders.Unlock()
+	key := packetKey{typ, format}
+	if newPacket == nil {
+		delete(packetDecoders.byKey, key)
+		return nil
+	}
+	packetDecoders.byKey[key] = packetDecoder{newPacket: newPacket}
+	return nil
+}
+
+// unmarshalRegistered decodes a packet with its registered decoder, if any,
+// and reports whether it handled the packet: the error of a builtin decoder
+// is returned, that of one registered with RegisterPacket is not
+func unmarshalRegistered(h Header, rawPacket []byte) (Packet, bool, error) {
+	packetDecoders.RLock()
+	d, ok := packetDecoders.byKey[packetKey{h.Type, h.Count}]
+	packetDecoders.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+
+	p := d.newPacket()
+	if err := p.Unmarshal(rawPacket); err != nil {
+		if d.builtin {
+			return nil, true, err
+		}
+		return nil, false, nil
+	}
+	return p, true, nil
+}
+
+var reportBlockDecoders = struct {
+	sync.RWMutex
+	byType map[BlockType]func() ReportBlock
+}{
+	byType: map[BlockType]func() ReportBlock{},
+}
+
+// RegisterReportBlock registers a decoder for XR report blocks of the given
+// type. ExtendedReport.Unmarshal, and so Unmarshal and Decoder, pass matching
+// blocks, including their block header, to the Unmarshal method of a block
+// returned by newBlock. If that fails, the block is decoded as it would have
+// been without the registration, as an UnknownReportBlock for types this
+// package doesn't decode.
+//
+// Registering a type again replaces its decoder; a nil newBlock removes it.
+// Registered decoders take precedence over the block types of this package.
+// The types 0 and 255 are reserved by RFC 3611 and can't be registered.
+func RegisterReportBlock(typ BlockType, newBlock func() ReportBlock) error {
+	if typ == 0 || typ == 255 {
+		return errBadBlockType
+	}
+
+	reportBlockDecoders.Lock()
+	defer reportBlockDecoders.Unlock()
+	if newBlock == nil {
+		delete(reportBlockDecoders.byType, typ)
+		return nil
+	}
+	reportBlockDecoders.byType[typ] = newBlock
+	return nil
+}
+
+// unmarshalRegisteredReportBlock decodes an XR report block with its
+// registered decoder, if any, and reports whether it succeeded
+func unmarshalRegisteredReportBlock(rawBlock []byte) (ReportBlock, bool) {
+	reportBlockDecoders.RLock()
+	newBlock, ok := reportBlockDecoders.byType[BlockType(rawBlock[0])]
+	reportBlockDecoders.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	b := newBlock()
+	if err := b.Unmarshal(rawBlock); err != nil {
+		return nil, false
+	}
+	return b, true
+}
@@ -0,0 +1,232 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exampleApplication decodes APP packets named "exmp" that carry a single
+// 32 bit value
+type exampleApplication struct {
+	ApplicationDefined
+	Value uint32
+}
+
+func (e *exampleApplication) Unmarshal(rawPacket []byte) error {
+	if err := e.ApplicationDefined.Unmarshal(rawPacket); err != nil {
+		return err
+	}
+	if len(e.Data) != 4 {
+		return errPacketTooShort
+	}
+	e.Value = uint32(e.Data[0])<<24 | uint32(e.Data[1])<<16 | uint32(e.Data[2])<<8 | uint32(e.Data[3])
+	return nil
+}
+
+func TestRegisterApplicationDefined(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(errAppNameLength, RegisterApplicationDefined("toolong", nil))
+	assert.NoError(RegisterApplicationDefined("exmp", func() Packet { return new(exampleApplication) }))
+	defer func() {
+		assert.NoError(RegisterApplicationDefined("exmp", nil))
+	}()
+
+	valid, err := ApplicationDefined{SSRC: 1, Name: "exmp", Data: []byte{0, 0, 1, 0}}.Marshal()
+	assert.NoError(err)
+	// a payload the decoder rejects falls back to ApplicationDefined
+	invalid, err := ApplicationDefined{SSRC: 1, Name: "exmp", Data: []byte{0, 0, 1, 0, 0, 0, 0, 0}}.Marshal()
+	assert.NoError(err)
+	goog, err := ApplicationDefined{SubType: 1, SSRC: 2, Name: "goog", Data: []byte{1, 2, 3, 4}}.Marshal()
+	assert.NoError(err)
+	other, err := ApplicationDefined{SSRC: 3, Name: "othr"}.Marshal()
+	assert.NoError(err)
+
+	raw := append(append(append(append([]byte{}, valid...), invalid...), goog...), other...)
+	for _, decode := range []func([]byte) ([]Packet, error){
+		Unmarshal,
+		func(raw []byte) ([]Packet, error) { return NewDecoder().Decode(raw, nil) },
+	} {
+		packets, err := decode(raw)
+		assert.NoError(err)
+		assert.Len(packets, 4)
+
+		if assert.IsType(&exampleApplication{}, packets[0]) {
+			assert.Equal(uint32(256), packets[0].(*exampleApplication).Value)
+		}
+		assert.IsType(&ApplicationDefined{}, packets[1])
+		if assert.IsType(&GoogApplicationDefined{}, packets[2]) {
+			g := packets[2].(*GoogApplicationDefined)
+			assert.Equal(uint8(1), g.SubType)
+			assert.Equal([]byte{1, 2, 3, 4}, g.Data)
+		}
+		if assert.IsType(&ApplicationDefined{}, packets[3]) {
+			assert.Equal("othr", packets[3].(*ApplicationDefined).Name)
+		}
+	}
+
+	// once removed, the name decodes to ApplicationDefined
+	assert.NoError(RegisterApplicationDefined("exmp", nil))
+	packets, err := Unmarshal(valid)
+	assert.NoError(err)
+	assert.IsType(&ApplicationDefined{}, packets[0])
+}
+
+// exampleFeedback decodes transport layer feedback with FMT 20
+type exampleFeedback struct {
+	RawPacket
+}
+
+func (e *exampleFeedback) Unmarshal(rawPacket []byte) error {
+	if len(rawPacket) != headerLength+2*ssrcLength {
+		return errPacketTooShort
+	}
+	return e.RawPacket.Unmarshal(rawPacket)
+}
+
+func TestRegisterPacket(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(errBadFormat, RegisterPacket(TypeTransportSpecificFeedback, 32, nil))
+	assert.NoError(RegisterPacket(TypeTransportSpecificFeedback, 20, func() Packet { return new(exampleFeedback) }))
+	defer func() {
+		assert.NoError(RegisterPacket(TypeTransportSpecificFeedback, 20, nil))
+	}()
+
+	valid := []byte{0x94, 0xcd, 0x00, 0x02, 0, 0, 0, 1, 0, 0, 0, 2}
+	// a payload the decoder rejects falls back to RawPacket
+	invalid := []byte{0x94, 0xcd, 0x00, 0x01, 0, 0, 0, 1}
+	rams, err := RapidAcquisition{SenderSSRC: 1, MediaSSRC: 2, MessageType: RAMSRequest}.Marshal()
+	assert.NoError(err)
+
+	raw := append(append(append([]byte{}, valid...), invalid...), rams...)
+	for _, decode := range []func([]byte) ([]Packet, error){
+		Unmarshal,
+		func(raw []byte) ([]Packet, error) { return NewDecoder().Decode(raw, nil) },
+	} {
+		packets, err := decode(raw)
+		assert.NoError(err)
+		if assert.Len(packets, 3) {
+			assert.IsType(&exampleFeedback{}, packets[0])
+			assert.IsType(&RawPacket{}, packets[1])
+			assert.IsType(&RapidAcquisition{}, packets[2])
+		}
+	}
+
+	// once removed, the format decodes to RawPacket
+	assert.NoError(RegisterPacket(TypeTransportSpecificFeedback, 20, nil))
+	packets, err := Unmarshal(valid)
+	assert.NoError(err)
+	assert.IsType(&RawPacket{}, packets[0])
+}
+
+func TestRegisterPacketBuiltinErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, test := range []struct {
+		Name string
+		Data []byte
+	}{
+		{
+			Name: "ccfb missing metrics",
+			Data: []byte{
+				// v=2, p=0, FMT=11, TSFB, len=4
+				0x8b, 0xcd, 0x00, 0x04,
+				// sender, media
+				0, 0, 0, 1, 0, 0, 0, 2,
+				// begin_seq=0, num_reports=100
+				0x00, 0x00, 0x00, 0x64,
+				// report timestamp
+				0x12, 0x34, 0x56, 0x78,
+			},
+		},
+		{
+			Name: "rams without message type",
+			Data: []byte{0x86, 0xcd, 0x00, 0x02, 0, 0, 0, 1, 0, 0, 0, 2},
+		},
+		{
+			Name: "port mapping without ssrc",
+			Data: []byte{0x81, 0xd2, 0x00, 0x00},
+		},
+	} {
+		for _, decode := range []func([]byte) ([]Packet, error){
+			Unmarshal,
+			UnmarshalArena,
+			func(raw []byte) ([]Packet, error) { return NewDecoder().Decode(raw, nil) },
+		} {
+			packets, err := decode(test.Data)
+			assert.Equal(errPacketTooShort, err, test.Name)
+			assert.Nil(packets, test.Name)
+		}
+	}
+}
+
+// exampleReportBlock decodes XR blocks of type 200 that carry a single SSRC
+type exampleReportBlock struct {
+	UnknownReportBlock
+	SSRC uint32
+}
+
+func (e *exampleReportBlock) Unmarshal(rawBlock []byte) error {
+	if err := e.UnknownReportBlock.Unmarshal(rawBlock); err != nil {
+		return err
+	}
+	if len(e.Data) != 4 {
+		return errBadBlockLength
+	}
+	e.SSRC = uint32(e.Data[0])<<24 | uint32(e.Data[1])<<16 | uint32(e.Data[2])<<8 | uint32(e.Data[3])
+	return nil
+}
+
+func (e *exampleReportBlock) DestinationSSRC() []uint32 {
+	return []uint32{e.SSRC}
+}
+
+func TestRegisterReportBlock(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(errBadBlockType, RegisterReportBlock(0, nil))
+	assert.Equal(errBadBlockType, RegisterReportBlock(255, nil))
+	assert.NoError(RegisterReportBlock(200, func() ReportBlock { return new(exampleReportBlock) }))
+	defer func() {
+		assert.NoError(RegisterReportBlock(200, nil))
+	}()
+
+	raw, err := ExtendedReport{
+		SenderSSRC: 1,
+		Reports: []ReportBlock{
+			&UnknownReportBlock{Type: 200, Data: []byte{0, 0, 0, 2}},
+			// a payload the decoder rejects falls back to UnknownReportBlock
+			&UnknownReportBlock{Type: 200, Data: []byte{0, 0, 0, 2, 0, 0, 0, 3}},
+			&JitterBufferReportBlock{SSRC: 4},
+		},
+	}.Marshal()
+	assert.NoError(err)
+
+	for _, decode := range []func([]byte) ([]Packet, error){
+		Unmarshal,
+		func(raw []byte) ([]Packet, error) { return NewDecoder().Decode(raw, nil) },
+	} {
+		packets, err := decode(raw)
+		assert.NoError(err)
+		if !assert.Len(packets, 1) || !assert.IsType(&ExtendedReport{}, packets[0]) {
+			continue
+		}
+		xr := packets[0].(*ExtendedReport)
+		if assert.Len(xr.Reports, 3) {
+			if assert.IsType(&exampleReportBlock{}, xr.Reports[0]) {
+				assert.Equal(uint32(2), xr.Reports[0].(*exampleReportBlock).SSRC)
+			}
+			assert.IsType(&UnknownReportBlock{}, xr.Reports[1])
+			assert.IsType(&JitterBufferReportBlock{}, xr.Reports[2])
+		}
+		assert.Equal([]uint32{2, 4}, xr.DestinationSSRC())
+	}
+
+	// once removed, the type decodes to UnknownReportBlock
+	assert.NoError(RegisterReportBlock(200, nil))
+	var xr ExtendedReport
+	assert.NoError(xr.Unmarshal(raw))
+	assert.IsType(&UnknownReportBlock{}, xr.Reports[0])
+}
@@ -0,0 +1,335 @@
+package rtcp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultREMBEstimatorStartBitrate is the estimate of a REMBEstimator
+	// before it received any packets, when its StartBitrate is zero.
+	DefaultREMBEstimatorStartBitrate = 300000
+
+	// packets sent within this time of the first packet of a group are part
+	// of the group, as the pacer sends them in bursts
+	rembGroupLength = 5 * time.Millisecond
+	// number of delay variation samples the trend is computed over
+	rembTrendWindow    = 20
+	rembTrendSmoothing = 0.9
+	rembTrendGain      = 4.0
+	// the trend is scaled by the number of samples, up to this many
+	rembTrendMaxSamples = 60
+
+	// adaptive threshold of the overuse detector in ms, see
+	// draft-ietf-rmcat-gcc-02, 5.4
+	rembInitialThreshold = 12.5
+	rembMinThreshold     = 6
+	rembMaxThreshold     = 600
+	rembThresholdUp      = 0.0087
+	rembThresholdDown    = 0.039
+	// trends further above the threshold than this are outliers that
+	// don't adapt it
+	rembThresholdOutlier = 15
+
+	// rate control, see draft-ietf-rmcat-gcc-02, 5.5
+	rembDecreaseFactor    = 0.85
+	rembIncreasePerSecond = 1.08
+	rembRateWindow        = time.Second
+)
+
+// BandwidthUsage is the state of the network path detected by the delay
+// based estimation of a REMBEstimator.
+type BandwidthUsage int
+
+const (
+	// BandwidthNormal means the queuing delay is stable.
+	BandwidthNormal BandwidthUsage = iota
+	// BandwidthOverusing means the queuing delay grows: the sender exceeds
+	// the capacity of the path.
+	BandwidthOverusing
+	// BandwidthUnderusing means the queuing delay shrinks, e.g. as a queue
+	// drains after overuse.
+	BandwidthUnderusing
+)
+
+func (u BandwidthUsage) String() string {
+	switch u {
+	case BandwidthNormal:
+		return "normal"
+	case BandwidthOverusing:
+		return "overusing"
+	case BandwidthUnderusing:
+		return "underusing"
+	default:
+		return fmt.Sprintf("BandwidthUsage(%d)", int(u))
+	}
+}
+
+type rembPacketGroup struct {
+	firstSend   time.Duration
+	lastSend    time.Duration
+	lastArrival time.Time
+}
+
+type rembTrendSample struct {
+	arrival  float64
+	smoothed float64
+}
+
+// A REMBEstimator estimates the available bandwidth on the receive side from
+// the one-way delay variation of the incoming packets, as the delay based
+// controller of Google Congestion Control does (draft-ietf-rmcat-gcc-02),
+// and builds ReceiverEstimatedMaximumBitrate feedback from the estimate. It
+// serves peers that negotiated goog-remb rather than transport-cc, for which
+// the sender runs the estimation itself.
+//
+// Packets are grouped into the bursts they were sent in. The variation of
+// the delay between consecutive groups is smoothed, and the trend of the
+// queuing delay is the slope of a linear fit over the latest samples. A
+// trend above an adaptive threshold is overuse, upon which the estimate
+// drops to 85% of the incoming bitrate; while the delay is stable it grows
+// by 8% per second, up to 1.5 times the incoming bitrate.
+//
+// The send time of each packet usually comes from the abs-send-time RTP
+// header extension, unwrapped with an AbsSendTimeUnwrapper; any clock of the
+// sender works, as only differences are used, but it must not wrap around.
+//
+// A REMBEstimator isn't safe for concurrent use.
+type REMBEstimator struct {
+	// SSRC of the feedback sender
+	SenderSSRC uint32
+	// The estimate in bits per second before any packets were received. If
+	// zero, DefaultREMBEstimatorStartBitrate is used.
+	StartBitrate uint64
+	// Bounds of the estimate in bits per second. A zero MaxBitrate is
+	// unbounded.
+	MinBitrate uint64
+	MaxBitrate uint64
+
+	started bool
+	bitrate float64
+	usage   BandwidthUsage
+
+	group     rembPacketGroup
+	haveGroup bool
+	prevGroup rembPacketGroup
+	havePrev  bool
+
+	firstArrival time.Time
+	accumulated  float64
+	smoothed     float64
+	samples      []rembTrendSample
+	numDeltas    int
+	threshold    float64
+	lastUpdate   time.Time
+
+	incoming    bandwidthWindow
+	lastArrival time.Time
+	lastRate    time.Time
+	ssrcs       map[uint32]bool
+}
+
+// NewREMBEstimator creates a REMBEstimator that sends feedback as
+// senderSSRC, starting at startBitrate bits per second.
+func NewREMBEstimator(senderSSRC uint32, startBitrate uint64) *REMBEstimator {
+	return &REMBEstimator{SenderSSRC: senderSSRC, StartBitrate: startBitrate}
+}
+
+func (e *REMBEstimator) start() {
+	if e.started {
+		return
+	}
+	e.started = true
+	e.bitrate = float64(e.StartBitrate)
+	if e.bitrate == 0 {
+		e.bitrate = DefaultREMBEstimatorStartBitrate
+	}
+	e.threshold = rembInitialThreshold
+	e.ssrcs = make(map[uint32]bool)
+}
+
+// OnPacket records the arrival of an RTP packet of the media source ssrc,
+// sent at sendTime on the sender's clock, with size bytes.
+func (e *REMBEstimator) OnPacket(ssrc uint32, sendTime time.Duration, arrival time.Time, size int) {
+	e.start()
+	e.ssrcs[ssrc] = true
+	e.incoming.add(arrival, size)
+	e.incoming.expire(arrival.Add(-rembRateWindow))
+	if arrival.After(e.lastArrival) {
+		e.lastArrival = arrival
+	}
+
+	switch {
+	case !e.haveGroup:
+		e.group = rembPacketGroup{firstSend: sendTime, lastSend: sendTime, lastArrival: arrival}
+		e.haveGroup = true
+		e.firstArrival = arrival
+		return
+	case sendTime < e.group.firstSend:
+		// reordered from an earlier group
+		return
+	case sendTime-e.group.firstSend <= rembGroupLength:
+		if sendTime > e.group.lastSend {
+			e.group.lastSend = sendTime
+		}
+		if arrival.After(e.group.lastArrival) {
+			e.group.lastArrival = arrival
+		}
+		return
+	}
+
+	// the packet starts a new group, which completes the current one
+	if e.havePrev {
+		sendDelta := e.group.lastSend - e.prevGroup.lastSend
+		arrivalDelta := e.group.lastArrival.Sub(e.prevGroup.lastArrival)
+		e.onDelayVariation(arrivalDelta-sendDelta, e.group.lastArrival)
+	}
+	e.prevGroup, e.havePrev = e.group, true
+	e.group = rembPacketGroup{firstSend: sendTime, lastSend: sendTime, lastArrival: arrival}
+}
+
+// onDelayVariation updates the trend with the delay variation between two
+// groups, the latter completed at arrival, and runs the detector and rate
+// control
+func (e *REMBEstimator) onDelayVariation(variation time.Duration, arrival time.Time) {
+	e.accumulated += durationMillis(variation)
+	e.smoothed = rembTrendSmoothing*e.smoothed + (1-rembTrendSmoothing)*e.accumulated
+	e.samples = append(e.samples, rembTrendSample{
+		arrival:  durationMillis(arrival.Sub(e.firstArrival)),
+		smoothed: e.smoothed,
+	})
+	if len(e.samples) > rembTrendWindow {
+		e.samples = append(e.samples[:0], e.samples[1:]...)
+	}
+	if e.numDeltas < rembTrendMaxSamples {
+		e.numDeltas++
+	}
+	if len(e.samples) < rembTrendWindow {
+		return
+	}
+
+	trend := float64(e.numDeltas) * linearSlope(e.samples) * rembTrendGain
+	e.detect(trend, arrival)
+	e.control(arrival)
+}
+
+// detect compares trend with the adaptive threshold
+func (e *REMBEstimator) detect(trend float64, now time.Time) {
+	switch {
+	case trend > e.threshold:
+		e.usage = BandwidthOverusing
+	case trend < -e.threshold:
+		e.usage = BandwidthUnderusing
+	default:
+		e.usage = BandwidthNormal
+	}
+
+	if !e.lastUpdate.IsZero() && math.Abs(trend) <= e.threshold+rembThresholdOutlier {
+		k := rembThresholdUp
+		if math.Abs(trend) < e.threshold {
+			k = rembThresholdDown
+		}
+		dt := durationMillis(now.Sub(e.lastUpdate))
+		if dt > 100 {
+			dt = 100
+		}
+		e.threshold += k * (math.Abs(trend) - e.threshold) * dt
+		e.threshold = math.Max(rembMinThreshold, math.Min(rembMaxThreshold, e.threshold))
+	}
+	e.lastUpdate = now
+}
+
+// control adjusts the estimate to the detected usage
+func (e *REMBEstimator) control(now time.Time) {
+	incoming := e.incomingBitrate()
+	switch e.usage {
+	case BandwidthOverusing:
+		if decreased := rembDecreaseFactor * incoming; decreased < e.bitrate {
+			e.bitrate = decreased
+		}
+	case BandwidthNormal:
+		if !e.lastRate.IsZero() {
+			e.bitrate *= math.Pow(rembIncreasePerSecond, now.Sub(e.lastRate).Seconds())
+		}
+		if limit := 1.5*incoming + 10000; e.bitrate > limit {
+			e.bitrate = math.Max(limit, float64(e.MinBitrate))
+		}
+	case BandwidthUnderusing:
+		// hold while the queues drain
+	}
+	e.lastRate = now
+
+	if e.bitrate < float64(e.MinBitrate) {
+		e.bitrate = float64(e.MinBitrate)
+	}
+	if e.MaxBitrate != 0 && e.bitrate > float64(e.MaxBitrate) {
+		e.bitrate = float64(e.MaxBitrate)
+	}
+}
+
+// incomingBitrate returns the bitrate received over the rate window, or
+// over the time since the first arrival until the window is full
+func (e *REMBEstimator) incomingBitrate() float64 {
+	window := e.lastArrival.Sub(e.firstArrival)
+	if window > rembRateWindow {
+		window = rembRateWindow
+	}
+	if window <= 0 {
+		return 0
+	}
+	return float64(e.incoming.total) * 8 / window.Seconds()
+}
+
+// Usage returns the state of the path detected by the latest packets.
+func (e *REMBEstimator) Usage() BandwidthUsage {
+	return e.usage
+}
+
+// Bitrate returns the current estimate in bits per second.
+func (e *REMBEstimator) Bitrate() uint64 {
+	e.start()
+	return uint64(e.bitrate)
+}
+
+// REMB returns feedback carrying the current estimate for every media SSRC
+// received, in SSRC order.
+func (e *REMBEstimator) REMB() *ReceiverEstimatedMaximumBitrate {
+	e.start()
+	ssrcs := make([]uint32, 0, len(e.ssrcs))
+	for ssrc := range e.ssrcs {
+		ssrcs = append(ssrcs, ssrc)
+	}
+	sort.Slice(ssrcs, func(i, j int) bool { return ssrcs[i] < ssrcs[j] })
+	return &ReceiverEstimatedMaximumBitrate{
+		SenderSSRC: e.SenderSSRC,
+		Bitrate:    e.Bitrate(),
+		SSRCs:      ssrcs,
+	}
+}
+
+// linearSlope returns the slope of the least squares fit of the smoothed
+// delays over the arrival times
+func linearSlope(samples []rembTrendSample) float64 {
+	var sumX, sumY float64
+	for _, s := range samples {
+		sumX += s.arrival
+		sumY += s.smoothed
+	}
+	meanX, meanY := sumX/float64(len(samples)), sumY/float64(len(samples))
+
+	var num, den float64
+	for _, s := range samples {
+		num += (s.arrival - meanX) * (s.smoothed - meanY)
+		den += (s.arrival - meanX) * (s.arrival - meanX)
+	}
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
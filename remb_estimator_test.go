@@ -0,0 +1,74 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sendOverLink feeds e with packets of size bytes sent at sendRate bits per
+// second for d, through a link of the given capacity in bits per second
+func sendOverLink(e *REMBEstimator, start time.Time, sendRate, capacity float64, size int, d time.Duration) {
+	interval := time.Duration(float64(size*8) / sendRate * float64(time.Second))
+	transmit := time.Duration(float64(size*8) / capacity * float64(time.Second))
+	var linkFree time.Duration
+	for send := time.Duration(0); send < d; send += interval {
+		arrival := send
+		if linkFree > arrival {
+			arrival = linkFree
+		}
+		arrival += transmit
+		linkFree = arrival
+		e.OnPacket(1, send, start.Add(arrival+20*time.Millisecond), size)
+	}
+}
+
+func TestREMBEstimatorStable(t *testing.T) {
+	assert := assert.New(t)
+
+	e := NewREMBEstimator(5, 0)
+	assert.Equal(uint64(DefaultREMBEstimatorStartBitrate), e.Bitrate())
+
+	// 500kbps over a 2Mbps link: the delay is stable, and the estimate
+	// grows up to 1.5 times the incoming bitrate
+	sendOverLink(e, time.Unix(1000, 0), 500000, 2000000, 1000, 20*time.Second)
+	assert.Equal(BandwidthNormal, e.Usage())
+	assert.InDelta(1.5*500000+10000, float64(e.Bitrate()), 20000)
+
+	remb := e.REMB()
+	assert.Equal(uint32(5), remb.SenderSSRC)
+	assert.Equal([]uint32{1}, remb.SSRCs)
+	assert.Equal(e.Bitrate(), remb.Bitrate)
+}
+
+func TestREMBEstimatorOveruse(t *testing.T) {
+	assert := assert.New(t)
+
+	// 2Mbps over a 1Mbps link: the queue grows, and the estimate drops
+	// below the capacity
+	e := NewREMBEstimator(5, 2000000)
+	sendOverLink(e, time.Unix(1000, 0), 2000000, 1000000, 1200, 3*time.Second)
+	assert.Equal(BandwidthOverusing, e.Usage())
+	assert.True(e.Bitrate() < 1000000, "estimate %d", e.Bitrate())
+	assert.True(e.Bitrate() > 700000, "estimate %d", e.Bitrate())
+}
+
+func TestREMBEstimatorBounds(t *testing.T) {
+	assert := assert.New(t)
+
+	e := NewREMBEstimator(5, 2000000)
+	e.MinBitrate = 1500000
+	sendOverLink(e, time.Unix(1000, 0), 2000000, 1000000, 1200, 3*time.Second)
+	assert.Equal(uint64(1500000), e.Bitrate())
+
+	e = NewREMBEstimator(5, 0)
+	e.MaxBitrate = 400000
+	sendOverLink(e, time.Unix(1000, 0), 500000, 2000000, 1000, 5*time.Second)
+	assert.Equal(uint64(400000), e.Bitrate())
+}
+
+func TestBandwidthUsageString(t *testing.T) {
+	assert.Equal(t, "overusing", BandwidthOverusing.String())
+	assert.Equal(t, "BandwidthUsage(7)", BandwidthUsage(7).String())
+}
@@ -0,0 +1,40 @@
+package rtcp
+
+// A RepairObserver is told about media packets that weren't received but
+// recovered, by FlexFEC (RFC 8627) or ULPFEC (RFC 5109) decoding, so it
+// neither reports them as lost nor asks for their retransmission.
+// ReceiverStats and FeedbackSuppressor are RepairObservers; pass every
+// recovered packet to each of them through RepairObservers.
+type RepairObserver interface {
+	// OnRepaired is called with the SSRC and sequence number of the media
+	// packet recovered, not those of the FEC packets it was recovered from.
+	OnRepaired(ssrc uint32, sequenceNumber uint16)
+}
+
+var (
+	_ RepairObserver = (*ReceiverStats)(nil)          // assert is a RepairObserver
+	_ RepairObserver = (*FeedbackSuppressor)(nil)     // assert is a RepairObserver
+	_ RepairObserver = (*SyncReceiverStats)(nil)      // assert is a RepairObserver
+	_ RepairObserver = (*SyncFeedbackSuppressor)(nil) // assert is a RepairObserver
+	_ RepairObserver = RepairObservers(nil)           // assert is a RepairObserver
+)
+
+// RepairObservers is a RepairObserver notifying each of its elements, in
+// order.
+type RepairObservers []RepairObserver
+
+// OnRepaired implements RepairObserver.
+func (o RepairObservers) OnRepaired(ssrc uint32, sequenceNumber uint16) {
+	for _, observer := range o {
+		observer.OnRepaired(ssrc, sequenceNumber)
+	}
+}
+
+// RepairObserverFunc is a function implementing RepairObserver, e.g. to
+// call RTXRewriter.OnRepaired for the packets of its media stream.
+type RepairObserverFunc func(ssrc uint32, sequenceNumber uint16)
+
+// OnRepaired calls f.
+func (f RepairObserverFunc) OnRepaired(ssrc uint32, sequenceNumber uint16) {
+	f(ssrc, sequenceNumber)
+}
@@ -0,0 +1,35 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairObservers(t *testing.T) {
+	assert := assert.New(t)
+
+	var got []string
+	observer := func(name string) RepairObserver {
+		return RepairObserverFunc(func(ssrc uint32, sequenceNumber uint16) {
+			got = append(got, name)
+			assert.Equal(uint32(1), ssrc)
+			assert.Equal(uint16(2), sequenceNumber)
+		})
+	}
+	RepairObservers{observer("a"), observer("b")}.OnRepaired(1, 2)
+	assert.Equal([]string{"a", "b"}, got)
+
+	// an RTXRewriter stops forwarding NACKs for the packets of its media
+	// stream recovered by FEC
+	r := NewRTXRewriter(1, 2, 0)
+	o := RepairObserverFunc(func(ssrc uint32, sequenceNumber uint16) {
+		if ssrc == r.MediaSSRC {
+			r.OnRepaired(sequenceNumber)
+		}
+	})
+	o.OnRepaired(1, 10)
+	o.OnRepaired(3, 11)
+	assert.Equal(&TransportLayerNack{MediaSSRC: 1, Nacks: NackPairsFromSequenceNumbers([]uint16{11})},
+		r.RewriteNACK(&TransportLayerNack{MediaSSRC: 1, Nacks: NackPairsFromSequenceNumbers([]uint16{10, 11})}))
+}
@@ -0,0 +1,108 @@
+package rtcp
+
+// A ReportSource supplies the reports of one local SSRC to a
+// ReportAggregator, typically from the stats engine of that stream.
+type ReportSource struct {
+	SSRC uint32
+	// SenderReport optionally returns the sender information of the SSRC.
+	// If it's nil or returns nil, the SSRC isn't sending. The SSRC and
+	// Reports of the returned packet are set by the ReportAggregator.
+	SenderReport func() *SenderReport
+	// ReceptionReports optionally returns the reception reports for the
+	// remote sources this SSRC heard since the previous report.
+	ReceptionReports func() []ReceptionReport
+}
+
+// A ReportAggregator assembles the reports of several local SSRCs into a
+// single compound packet, as RFC 8108 allows an endpoint with many streams
+// to do. The compound packet has
+//
+//	the SenderReport of the first sending SSRC, or a ReceiverReport of the
+//	  first SSRC if none is sending, with the first 31 reception reports
+//	ReceiverReports of the same SSRC carrying the remaining reception
+//	  reports, 31 at a time
+//	a SourceDescription with the CNAME of every SSRC
+//
+// A compound packet holds a single SenderReport, so the sender information
+// of the other sending SSRCs is sent in compound packets of their own.
+// Reception reports are taken from the sources in order and aren't
+// deduplicated.
+type ReportAggregator struct {
+	// CNAME shared by all the local SSRCs
+	CNAME   string
+	Sources []ReportSource
+}
+
+// Build returns the aggregated compound packet, followed by one compound
+// packet for each further sending SSRC. It returns an error if there are no
+// sources or the CNAME can't be encoded.
+func (a *ReportAggregator) Build() ([]CompoundPacket, error) {
+	if len(a.Sources) == 0 {
+		return nil, errNoReportSources
+	}
+
+	var senders []*SenderReport
+	var reports []ReceptionReport
+	for _, s := range a.Sources {
+		if s.SenderReport != nil {
+			if sr := s.SenderReport(); sr != nil {
+				sr.SSRC = s.SSRC
+				sr.Reports = nil
+				senders = append(senders, sr)
+			}
+		}
+		if s.ReceptionReports != nil {
+			reports = append(reports, s.ReceptionReports()...)
+		}
+	}
+
+	first := reports
+	if len(first) > countMax {
+		first = first[:countMax]
+	}
+	reports = reports[len(first):]
+
+	var packets CompoundPacket
+	reporter := a.Sources[0].SSRC
+	if len(senders) > 0 {
+		reporter = senders[0].SSRC
+		senders[0].Reports = first
+		packets = append(packets, senders[0])
+	} else {
+		packets = append(packets, &ReceiverReport{SSRC: reporter, Reports: first})
+	}
+	packets = appendOverflowReports(packets, reporter, reports)
+
+	b := NewSourceDescriptionBuilder()
+	for _, s := range a.Sources {
+		if err := b.AddCNAME(a.CNAME, s.SSRC); err != nil {
+			return nil, err
+		}
+	}
+	descriptions, err := b.Build(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, sd := range descriptions {
+		packets = append(packets, sd)
+	}
+
+	out := []CompoundPacket{packets}
+	for i := 1; i < len(senders); i++ {
+		sr := senders[i]
+		out = append(out, CompoundPacket{sr, &SourceDescription{Chunks: []SourceDescriptionChunk{{
+			Source: sr.SSRC,
+			Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: a.CNAME}},
+		}}}})
+	}
+	return out, nil
+}
+
+// appendOverflowReports appends ReceiverReports of ssrc carrying reports,
+// at most 31 in each
+func appendOverflowReports(packets []Packet, ssrc uint32, reports []ReceptionReport) []Packet {
+	for _, r := range SplitReceptionReports(reports) {
+		packets = append(packets, &ReceiverReport{SSRC: ssrc, Reports: r})
+	}
+	return packets
+}
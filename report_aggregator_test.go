@@ -0,0 +1,65 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportAggregator(t *testing.T) {
+	assert := assert.New(t)
+
+	reports := make([]ReceptionReport, 70)
+	for i := range reports {
+		reports[i].SSRC = uint32(i + 100)
+	}
+	a := &ReportAggregator{
+		CNAME: "cname",
+		Sources: []ReportSource{
+			{SSRC: 1, ReceptionReports: func() []ReceptionReport { return reports[:40] }},
+			{SSRC: 2, ReceptionReports: func() []ReceptionReport { return reports[40:] }},
+			{SSRC: 3},
+		},
+	}
+
+	compounds, err := a.Build()
+	assert.NoError(err)
+	assert.Len(compounds, 1)
+	packets := compounds[0]
+	assert.Len(packets, 4)
+	assert.Equal(&ReceiverReport{SSRC: 1, Reports: reports[:31]}, packets[0])
+	assert.Equal(&ReceiverReport{SSRC: 1, Reports: reports[31:62]}, packets[1])
+	assert.Equal(&ReceiverReport{SSRC: 1, Reports: reports[62:]}, packets[2])
+	sd, ok := packets[3].(*SourceDescription)
+	assert.True(ok)
+	assert.Len(sd.Chunks, 3)
+	_, err = packets.Marshal()
+	assert.NoError(err)
+
+	// the first sender reports, the other senders send their SRs in
+	// compound packets of their own
+	a.Sources[1].SenderReport = func() *SenderReport { return &SenderReport{PacketCount: 2, Reports: reports[:1]} }
+	a.Sources[2].SenderReport = func() *SenderReport { return &SenderReport{PacketCount: 3} }
+	compounds, err = a.Build()
+	assert.NoError(err)
+	assert.Len(compounds, 2)
+	packets = compounds[0]
+	assert.Len(packets, 4)
+	assert.Equal(&SenderReport{SSRC: 2, PacketCount: 2, Reports: reports[:31]}, packets[0])
+	assert.Equal(&ReceiverReport{SSRC: 2, Reports: reports[31:62]}, packets[1])
+	assert.Equal(&ReceiverReport{SSRC: 2, Reports: reports[62:]}, packets[2])
+	assert.Equal(CompoundPacket{
+		&SenderReport{SSRC: 3, PacketCount: 3},
+		&SourceDescription{Chunks: []SourceDescriptionChunk{{
+			Source: 3,
+			Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: "cname"}},
+		}}},
+	}, compounds[1])
+	for _, c := range compounds {
+		_, err = c.Marshal()
+		assert.NoError(err)
+	}
+
+	_, err = (&ReportAggregator{}).Build()
+	assert.Equal(errNoReportSources, err)
+}
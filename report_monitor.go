@@ -0,0 +1,189 @@
+package rtcp
+
+import "fmt"
+
+// ReportEventType is the kind of threshold crossing a ReportMonitor detected.
+type ReportEventType int
+
+const (
+	// ReportLossRaised means the fraction lost rose above the threshold.
+	ReportLossRaised ReportEventType = iota
+	// ReportLossCleared means the fraction lost fell back to the threshold or
+	// below.
+	ReportLossCleared
+	// ReportJitterRaised means the jitter rose above the threshold.
+	ReportJitterRaised
+	// ReportJitterCleared means the jitter fell back to the threshold or
+	// below.
+	ReportJitterCleared
+)
+
+func (t ReportEventType) String() string {
+	switch t {
+	case ReportLossRaised:
+		return "loss raised"
+	case ReportLossCleared:
+		return "loss cleared"
+	case ReportJitterRaised:
+		return "jitter raised"
+	case ReportJitterCleared:
+		return "jitter cleared"
+	default:
+		return fmt.Sprintf("ReportEventType(%d)", int(t))
+	}
+}
+
+// A ReportEvent reports that the reception quality of a source, as seen by
+// one reporter, crossed a threshold of a ReportMonitor.
+type ReportEvent struct {
+	Type ReportEventType
+	// SSRC of the sender of the report, and of the source reported on
+	Reporter uint32
+	SSRC     uint32
+	// Fraction lost from 0 to 1, and jitter in timestamp units, of the
+	// previous and the current report. The previous values are zero for the
+	// first report.
+	PreviousFractionLost float64
+	FractionLost         float64
+	PreviousJitter       uint32
+	Jitter               uint32
+}
+
+type reportMonitorKey struct {
+	reporter uint32
+	ssrc     uint32
+}
+
+type reportMonitorState struct {
+	fractionLost float64
+	jitter       uint32
+	lossRaised   bool
+	jitterRaised bool
+}
+
+// A ReportMonitor compares consecutive reports on each source and returns
+// events when the fraction lost or the jitter crosses a threshold, for
+// alerting on loss spikes. Reports are told apart by their reporter and the
+// source they are on, so each pair of participants is monitored separately.
+// Both ReceptionReports and TransportLayerCC feedback are monitored; the
+// fraction lost of each TransportLayerCC is that of the packets it covers.
+//
+// An event is returned when a value rises above its threshold, and again
+// when it falls back, so a sustained spike results in a single
+// ReportLossRaised.
+//
+// A ReportMonitor isn't safe for concurrent use.
+type ReportMonitor struct {
+	// Fraction lost, from 0 to 1, above which ReportLossRaised is returned.
+	// If zero, loss isn't monitored.
+	LossThreshold float64
+	// Jitter in timestamp units above which ReportJitterRaised is returned.
+	// If zero, jitter isn't monitored.
+	JitterThreshold uint32
+
+	states map[reportMonitorKey]*reportMonitorState
+}
+
+// NewReportMonitor creates a ReportMonitor with the given thresholds.
+func NewReportMonitor(lossThreshold float64, jitterThreshold uint32) *ReportMonitor {
+	return &ReportMonitor{LossThreshold: lossThreshold, JitterThreshold: jitterThreshold}
+}
+
+// OnPacket compares the reports in p, which may be a CompoundPacket, with the
+// previous reports on the same sources, and returns the resulting events.
+// Packets other than SenderReports, ReceiverReports and TransportLayerCC
+// feedback are ignored.
+func (m *ReportMonitor) OnPacket(p Packet) []ReportEvent {
+	var events []ReportEvent
+	switch p := p.(type) {
+	case *SenderReport:
+		for _, r := range p.Reports {
+			events = m.onReport(events, p.SSRC, r)
+		}
+	case *ReceiverReport:
+		for _, r := range p.Reports {
+			events = m.onReport(events, p.SSRC, r)
+		}
+	case *TransportLayerCC:
+		if covered := p.PacketStatusCoverage(); covered > 0 {
+			lost := 1 - float64(p.ReceivedCount())/float64(covered)
+			if lost < 0 {
+				lost = 0
+			}
+			events = m.update(events, reportMonitorKey{p.SenderSSRC, p.MediaSSRC}, lost, 0, false)
+		}
+	case *CompoundPacket:
+		for _, sub := range *p {
+			events = append(events, m.OnPacket(sub)...)
+		}
+	}
+	return events
+}
+
+// OnReceptionReport compares r, sent by reporter, with the previous report on
+// the same source, and returns the resulting events.
+func (m *ReportMonitor) OnReceptionReport(reporter uint32, r ReceptionReport) []ReportEvent {
+	return m.onReport(nil, reporter, r)
+}
+
+func (m *ReportMonitor) onReport(events []ReportEvent, reporter uint32, r ReceptionReport) []ReportEvent {
+	return m.update(events, reportMonitorKey{reporter, r.SSRC}, float64(r.FractionLost)/256, r.Jitter, true)
+}
+
+// update records the latest values for k and appends the thresholds crossed
+// to events
+func (m *ReportMonitor) update(events []ReportEvent, k reportMonitorKey, fractionLost float64, jitter uint32, hasJitter bool) []ReportEvent {
+	if m.states == nil {
+		m.states = make(map[reportMonitorKey]*reportMonitorState)
+	}
+	s, ok := m.states[k]
+	if !ok {
+		s = &reportMonitorState{}
+		m.states[k] = s
+	}
+	if !hasJitter {
+		jitter = s.jitter
+	}
+
+	event := ReportEvent{
+		Reporter:             k.reporter,
+		SSRC:                 k.ssrc,
+		PreviousFractionLost: s.fractionLost,
+		FractionLost:         fractionLost,
+		PreviousJitter:       s.jitter,
+		Jitter:               jitter,
+	}
+	if m.LossThreshold > 0 {
+		if raised := fractionLost > m.LossThreshold; raised != s.lossRaised {
+			s.lossRaised = raised
+			event.Type = ReportLossCleared
+			if raised {
+				event.Type = ReportLossRaised
+			}
+			events = append(events, event)
+		}
+	}
+	if m.JitterThreshold > 0 && hasJitter {
+		if raised := jitter > m.JitterThreshold; raised != s.jitterRaised {
+			s.jitterRaised = raised
+			event.Type = ReportJitterCleared
+			if raised {
+				event.Type = ReportJitterRaised
+			}
+			events = append(events, event)
+		}
+	}
+
+	s.fractionLost, s.jitter = fractionLost, jitter
+	return events
+}
+
+// Remove forgets the reports sent by or on ssrc, e.g. after it sent a
+// Goodbye.
+func (m *ReportMonitor) Remove(ssrc uint32) {
+	for k := range m.states {
+		if k.reporter == ssrc || k.ssrc == ssrc {
+			delete(m.states, k)
+		}
+	}
+}
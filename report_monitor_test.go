@@ -0,0 +1,68 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportMonitor(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewReportMonitor(0.1, 100)
+	rr := func(fractionLost uint8, jitter uint32) *ReceiverReport {
+		return &ReceiverReport{SSRC: 1, Reports: []ReceptionReport{{SSRC: 2, FractionLost: fractionLost, Jitter: jitter}}}
+	}
+
+	assert.Empty(m.OnPacket(rr(0, 10)))
+	assert.Equal([]ReportEvent{{
+		Type: ReportLossRaised, Reporter: 1, SSRC: 2,
+		PreviousFractionLost: 0, FractionLost: 0.25, PreviousJitter: 10, Jitter: 20,
+	}}, m.OnPacket(rr(64, 20)))
+	// a sustained spike is reported once
+	assert.Empty(m.OnPacket(rr(128, 20)))
+
+	assert.Equal([]ReportEvent{
+		{Type: ReportLossCleared, Reporter: 1, SSRC: 2, PreviousFractionLost: 0.5, FractionLost: 0, PreviousJitter: 20, Jitter: 200},
+		{Type: ReportJitterRaised, Reporter: 1, SSRC: 2, PreviousFractionLost: 0.5, FractionLost: 0, PreviousJitter: 20, Jitter: 200},
+	}, m.OnPacket(&CompoundPacket{rr(0, 200), NewCNAMESourceDescription(1, "cname")}))
+
+	events := m.OnReceptionReport(1, ReceptionReport{SSRC: 2, Jitter: 100})
+	assert.Len(events, 1)
+	assert.Equal(ReportJitterCleared, events[0].Type)
+
+	// other reporters are monitored separately
+	events = m.OnPacket(&SenderReport{SSRC: 3, Reports: []ReceptionReport{{SSRC: 2, FractionLost: 255}}})
+	assert.Len(events, 1)
+	assert.Equal(uint32(3), events[0].Reporter)
+
+	m.Remove(2)
+	events = m.OnPacket(rr(64, 0))
+	assert.Len(events, 1)
+	assert.Equal(0.0, events[0].PreviousFractionLost)
+}
+
+func TestReportMonitorTransportLayerCC(t *testing.T) {
+	assert := assert.New(t)
+
+	m := &ReportMonitor{LossThreshold: 0.2}
+	fb := NewTWCC(0).WithSenderSSRC(1).WithMediaSSRC(2).
+		Received(0, 0).Lost(1).Lost(2).Received(3, 0).Build()
+	assert.Equal([]ReportEvent{{Type: ReportLossRaised, Reporter: 1, SSRC: 2, FractionLost: 0.5}}, m.OnPacket(fb))
+
+	fb = NewTWCC(4).WithSenderSSRC(1).WithMediaSSRC(2).Received(4, 0).Build()
+	events := m.OnPacket(fb)
+	assert.Len(events, 1)
+	assert.Equal(ReportLossCleared, events[0].Type)
+
+	// jitter isn't monitored without a threshold, and packets without
+	// reports are ignored
+	assert.Empty(m.OnPacket(&ReceiverReport{SSRC: 1, Reports: []ReceptionReport{{SSRC: 5, Jitter: 1 << 20}}}))
+	assert.Empty(m.OnPacket(&PictureLossIndication{}))
+}
+
+func TestReportEventTypeString(t *testing.T) {
+	assert.Equal(t, "loss raised", ReportLossRaised.String())
+	assert.Equal(t, "jitter cleared", ReportJitterCleared.String())
+	assert.Equal(t, "ReportEventType(9)", ReportEventType(9).String())
+}
@@ -0,0 +1,116 @@
+package rtcp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const rtcpFeedbackAttribute = "rtcp-fb:"
+
+// An RTCPFeedback is an a=rtcp-fb attribute of SDP, which negotiates a
+// feedback message for a payload type. See RFC 4585, 4.2.
+type RTCPFeedback struct {
+	// The payload type the feedback applies to, or "*" for all of them
+	PayloadType string
+	// The feedback type, e.g. "nack", "ccm", "goog-remb" or "transport-cc"
+	Type string
+	// The feedback parameter, e.g. "pli" for "nack pli" or "fir" for
+	// "ccm fir", or empty
+	Parameter string
+}
+
+// ParseRTCPFeedback parses an a=rtcp-fb attribute such as
+//
+//	a=rtcp-fb:96 nack pli
+//
+// The "a=" prefix is optional.
+func ParseRTCPFeedback(attribute string) (RTCPFeedback, error) {
+	value := strings.TrimPrefix(strings.TrimSpace(attribute), "a=")
+	if !strings.HasPrefix(value, rtcpFeedbackAttribute) {
+		return RTCPFeedback{}, fmt.Errorf("%w: %q", errBadRTCPFeedback, attribute)
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(value, rtcpFeedbackAttribute))
+	if len(fields) < 2 {
+		return RTCPFeedback{}, fmt.Errorf("%w: %q", errBadRTCPFeedback, attribute)
+	}
+	if pt := fields[0]; pt != "*" {
+		if n, err := strconv.ParseUint(pt, 10, 8); err != nil || n > 127 {
+			return RTCPFeedback{}, fmt.Errorf("%w: bad payload type in %q", errBadRTCPFeedback, attribute)
+		}
+	}
+
+	// parameters such as "tmmbr smaxpr=120" are kept whole
+	f := RTCPFeedback{PayloadType: fields[0], Type: fields[1]}
+	if len(fields) > 2 {
+		f.Parameter = strings.Join(fields[2:], " ")
+	}
+	return f, nil
+}
+
+// String formats the feedback as an SDP attribute.
+func (f RTCPFeedback) String() string {
+	if f.Parameter == "" {
+		return fmt.Sprintf("a=%s%s %s", rtcpFeedbackAttribute, f.PayloadType, f.Type)
+	}
+	return fmt.Sprintf("a=%s%s %s %s", rtcpFeedbackAttribute, f.PayloadType, f.Type, f.Parameter)
+}
+
+// Add allows the feedback message f negotiates. Feedback that
+// FeedbackCapabilities doesn't know about is ignored.
+func (c *FeedbackCapabilities) Add(f RTCPFeedback) {
+	switch f.Type + " " + f.Parameter {
+	case "nack ":
+		c.NACK = true
+	case "nack pli":
+		c.PLI = true
+	case "ccm fir":
+		c.FIR = true
+	case "transport-cc ":
+		c.TransportCC = true
+	case "ack ccfb":
+		c.CCFB = true
+	case "goog-remb ":
+		c.REMB = true
+	}
+}
+
+// ParseFeedbackCapabilities returns the capabilities the a=rtcp-fb attributes
+// negotiate for payloadType, counting attributes for "*" too. Other
+// attributes of the media description may be passed along and are skipped.
+func ParseFeedbackCapabilities(attributes []string, payloadType uint8) (FeedbackCapabilities, error) {
+	var c FeedbackCapabilities
+	pt := strconv.Itoa(int(payloadType))
+	for _, attribute := range attributes {
+		if !strings.HasPrefix(strings.TrimPrefix(strings.TrimSpace(attribute), "a="), rtcpFeedbackAttribute) {
+			continue
+		}
+		f, err := ParseRTCPFeedback(attribute)
+		if err != nil {
+			return FeedbackCapabilities{}, err
+		}
+		if f.PayloadType == pt || f.PayloadType == "*" {
+			c.Add(f)
+		}
+	}
+	return c, nil
+}
+
+// RTCPFeedback returns the a=rtcp-fb attributes that offer the capabilities
+// for payloadType, which is a payload type number or "*".
+func (c FeedbackCapabilities) RTCPFeedback(payloadType string) []RTCPFeedback {
+	var out []RTCPFeedback
+	add := func(ok bool, typ, parameter string) {
+		if ok {
+			out = append(out, RTCPFeedback{PayloadType: payloadType, Type: typ, Parameter: parameter})
+		}
+	}
+	add(c.NACK, "nack", "")
+	add(c.PLI, "nack", "pli")
+	add(c.FIR, "ccm", "fir")
+	add(c.TransportCC, "transport-cc", "")
+	add(c.CCFB, "ack", "ccfb")
+	add(c.REMB, "goog-remb", "")
+	return out
+}
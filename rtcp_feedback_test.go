@@ -0,0 +1,82 @@
+package rtcp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRTCPFeedback(t *testing.T) {
+	for _, test := range []struct {
+		Attribute string
+		Want      RTCPFeedback
+		WantError bool
+	}{
+		{Attribute: "a=rtcp-fb:96 nack", Want: RTCPFeedback{PayloadType: "96", Type: "nack"}},
+		{Attribute: "a=rtcp-fb:96 nack pli", Want: RTCPFeedback{PayloadType: "96", Type: "nack", Parameter: "pli"}},
+		{Attribute: "rtcp-fb:* ccm fir\r", Want: RTCPFeedback{PayloadType: "*", Type: "ccm", Parameter: "fir"}},
+		{Attribute: "a=rtcp-fb:111 transport-cc", Want: RTCPFeedback{PayloadType: "111", Type: "transport-cc"}},
+		{Attribute: "a=rtcp-fb:96 ccm tmmbr smaxpr=120", Want: RTCPFeedback{PayloadType: "96", Type: "ccm", Parameter: "tmmbr smaxpr=120"}},
+		{Attribute: "a=rtcp-fb:96", WantError: true},
+		{Attribute: "a=rtcp-fb:128 nack", WantError: true},
+		{Attribute: "a=rtcp-fb:x nack", WantError: true},
+		{Attribute: "a=rtpmap:96 VP8/90000", WantError: true},
+	} {
+		got, err := ParseRTCPFeedback(test.Attribute)
+		if test.WantError {
+			assert.True(t, errors.Is(err, errBadRTCPFeedback), "%q: err = %v", test.Attribute, err)
+			continue
+		}
+		assert.NoError(t, err, test.Attribute)
+		assert.Equal(t, test.Want, got, test.Attribute)
+	}
+}
+
+func TestRTCPFeedbackString(t *testing.T) {
+	assert.Equal(t, "a=rtcp-fb:96 nack", RTCPFeedback{PayloadType: "96", Type: "nack"}.String())
+	assert.Equal(t, "a=rtcp-fb:* ccm fir", RTCPFeedback{PayloadType: "*", Type: "ccm", Parameter: "fir"}.String())
+}
+
+func TestParseFeedbackCapabilities(t *testing.T) {
+	attributes := []string{
+		"a=rtpmap:96 VP8/90000",
+		"a=rtcp-fb:96 goog-remb",
+		"a=rtcp-fb:96 transport-cc",
+		"a=rtcp-fb:96 ccm fir",
+		"a=rtcp-fb:97 nack pli",
+		"a=rtcp-fb:* nack",
+		"a=rtcp-fb:96 ccm tmmbr",
+	}
+	c, err := ParseFeedbackCapabilities(attributes, 96)
+	assert.NoError(t, err)
+	assert.Equal(t, FeedbackCapabilities{NACK: true, FIR: true, TransportCC: true, REMB: true}, c)
+
+	c, err = ParseFeedbackCapabilities(attributes, 97)
+	assert.NoError(t, err)
+	assert.Equal(t, FeedbackCapabilities{NACK: true, PLI: true}, c)
+
+	_, err = ParseFeedbackCapabilities([]string{"a=rtcp-fb:96"}, 96)
+	assert.True(t, errors.Is(err, errBadRTCPFeedback))
+}
+
+func TestFeedbackCapabilitiesRTCPFeedback(t *testing.T) {
+	var attributes []string
+	for _, f := range AllFeedbackCapabilities.RTCPFeedback("96") {
+		attributes = append(attributes, f.String())
+	}
+	assert.Equal(t, []string{
+		"a=rtcp-fb:96 nack",
+		"a=rtcp-fb:96 nack pli",
+		"a=rtcp-fb:96 ccm fir",
+		"a=rtcp-fb:96 transport-cc",
+		"a=rtcp-fb:96 ack ccfb",
+		"a=rtcp-fb:96 goog-remb",
+	}, attributes)
+
+	// formatting and parsing round trip
+	c, err := ParseFeedbackCapabilities(attributes, 96)
+	assert.NoError(t, err)
+	assert.Equal(t, AllFeedbackCapabilities, c)
+	assert.Nil(t, FeedbackCapabilities{}.RTCPFeedback("*"))
+}
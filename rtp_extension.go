@@ -0,0 +1,102 @@
+package rtcp
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+const (
+	// AbsSendTimeURI identifies the abs-send-time RTP header extension in
+	// the a=extmap attributes of SDP.
+	AbsSendTimeURI = "http://www.webrtc.org/experiments/rtp-hdrext/abs-send-time"
+	// TransportCCURI identifies the transport wide sequence number RTP
+	// header extension in the a=extmap attributes of SDP.
+	TransportCCURI = "http://www.ietf.org/id/draft-holmer-rmcat-transport-wide-cc-extensions-01"
+
+	absSendTimeLength = 3
+	// abs-send-time is a 6.18 fixed point number of seconds
+	absSendTimeFractionBits = 18
+	absSendTimeRange        = 1 << 24
+	transportCCLength       = 2
+)
+
+// ParseAbsSendTime decodes the payload of an abs-send-time RTP header
+// extension: the send time in seconds as a 24 bit, 6.18 fixed point number,
+// which wraps around every 64 seconds. See AbsSendTimeDuration and
+// AbsSendTimeUnwrapper.
+func ParseAbsSendTime(ext []byte) (uint32, error) {
+	if len(ext) != absSendTimeLength {
+		return 0, errBadExtension
+	}
+	return uint32(ext[0])<<16 | uint32(ext[1])<<8 | uint32(ext[2]), nil
+}
+
+// MarshalAbsSendTime encodes the time d, since any origin, as the payload of
+// an abs-send-time RTP header extension.
+func MarshalAbsSendTime(d time.Duration) []byte {
+	v := AbsSendTime(d)
+	return []byte{byte(v >> 16), byte(v >> 8), byte(v)}
+}
+
+// AbsSendTime returns the 24 bit abs-send-time value of the time d, rounded
+// to the nearest step of the fixed point number.
+func AbsSendTime(d time.Duration) uint32 {
+	scaled := (uint64(d)<<absSendTimeFractionBits + uint64(time.Second)/2) / uint64(time.Second)
+	return uint32(scaled % absSendTimeRange)
+}
+
+// AbsSendTimeDuration returns the 24 bit abs-send-time value v as a duration
+// in [0, 64s).
+func AbsSendTimeDuration(v uint32) time.Duration {
+	v %= absSendTimeRange
+	return time.Duration((uint64(v)*uint64(time.Second) + 1<<(absSendTimeFractionBits-1)) >> absSendTimeFractionBits)
+}
+
+// An AbsSendTimeUnwrapper extends abs-send-time values to send times that
+// keep increasing across the 64 second wraparound, as needed by
+// REMBEstimator.OnPacket. Values within 32 seconds before the previous one
+// are reordered packets rather than a wraparound. The zero value is ready to
+// use; the first value unwraps to a time in [0, 64s).
+type AbsSendTimeUnwrapper struct {
+	started bool
+	last    int64
+}
+
+// Unwrap returns the send time of the abs-send-time value v.
+func (u *AbsSendTimeUnwrapper) Unwrap(v uint32) time.Duration {
+	v %= absSendTimeRange
+	if !u.started {
+		u.started = true
+		u.last = int64(v)
+		return AbsSendTimeDuration(v)
+	}
+
+	delta := (int64(v) - u.last) % absSendTimeRange
+	switch {
+	case delta >= absSendTimeRange/2:
+		delta -= absSendTimeRange
+	case delta < -absSendTimeRange/2:
+		delta += absSendTimeRange
+	}
+	u.last += delta
+
+	cycles := floorDiv(u.last, absSendTimeRange)
+	return time.Duration(cycles)*64*time.Second + AbsSendTimeDuration(uint32(u.last-cycles*absSendTimeRange))
+}
+
+// ParseTransportSequenceNumber decodes the payload of a transport wide
+// sequence number RTP header extension, as recorded by Recorder. The longer
+// payload of version 2 of the extension, which appends a feedback request,
+// is accepted as well.
+func ParseTransportSequenceNumber(ext []byte) (uint16, error) {
+	if len(ext) != transportCCLength && len(ext) != transportCCLength+2 {
+		return 0, errBadExtension
+	}
+	return binary.BigEndian.Uint16(ext), nil
+}
+
+// MarshalTransportSequenceNumber encodes seq as the payload of a transport
+// wide sequence number RTP header extension.
+func MarshalTransportSequenceNumber(seq uint16) []byte {
+	return []byte{byte(seq >> 8), byte(seq)}
+}
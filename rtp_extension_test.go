@@ -0,0 +1,63 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbsSendTime(t *testing.T) {
+	assert := assert.New(t)
+
+	v, err := ParseAbsSendTime([]byte{0x04, 0x80, 0x00})
+	assert.NoError(err)
+	// 1.125s in 6.18 fixed point
+	assert.Equal(uint32(0x048000), v)
+	assert.Equal(1125*time.Millisecond, AbsSendTimeDuration(v))
+
+	assert.Equal([]byte{0x04, 0x80, 0x00}, MarshalAbsSendTime(1125*time.Millisecond))
+	// wraps around every 64 seconds
+	assert.Equal(AbsSendTime(time.Second), AbsSendTime(65*time.Second))
+	// rounded to the nearest step of about 3.8us
+	d := 1234567 * time.Microsecond
+	assert.InDelta(float64(d), float64(AbsSendTimeDuration(AbsSendTime(d))), float64(2*time.Microsecond))
+
+	_, err = ParseAbsSendTime([]byte{1, 2})
+	assert.Equal(errBadExtension, err)
+}
+
+func TestAbsSendTimeUnwrapper(t *testing.T) {
+	assert := assert.New(t)
+
+	var u AbsSendTimeUnwrapper
+	assert.Equal(63*time.Second, u.Unwrap(AbsSendTime(63*time.Second)))
+	assert.Equal(65*time.Second, u.Unwrap(AbsSendTime(65*time.Second)))
+	// a reordered packet from before the wraparound
+	assert.Equal(63500*time.Millisecond, u.Unwrap(AbsSendTime(63500*time.Millisecond)))
+	for d := 80 * time.Second; d < 300*time.Second; d += 20 * time.Second {
+		assert.Equal(d, u.Unwrap(AbsSendTime(d)))
+	}
+
+	// times before the first one are negative
+	u = AbsSendTimeUnwrapper{}
+	u.Unwrap(AbsSendTime(time.Second))
+	assert.Equal(-time.Second, u.Unwrap(AbsSendTime(63*time.Second)))
+}
+
+func TestTransportSequenceNumber(t *testing.T) {
+	assert := assert.New(t)
+
+	seq, err := ParseTransportSequenceNumber([]byte{0x12, 0x34})
+	assert.NoError(err)
+	assert.Equal(uint16(0x1234), seq)
+	assert.Equal([]byte{0x12, 0x34}, MarshalTransportSequenceNumber(0x1234))
+
+	// version 2 appends a feedback request
+	seq, err = ParseTransportSequenceNumber([]byte{0x12, 0x34, 0x80, 0x10})
+	assert.NoError(err)
+	assert.Equal(uint16(0x1234), seq)
+
+	_, err = ParseTransportSequenceNumber([]byte{0x12})
+	assert.Equal(errBadExtension, err)
+}
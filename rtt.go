@@ -0,0 +1,187 @@
+package rtcp
+
+import (
+	"time"
+)
+
+const (
+	// gains of the smoothed round trip time and its variation, see RFC
+	// 6298, 2
+	rttAlpha = 1.0 / 8
+	rttBeta  = 1.0 / 4
+
+	// number of sent SenderReports an RTTEstimator remembers
+	rttMaxSenderReports = 16
+)
+
+// An RTTEstimator measures the round trip time to a remote participant from
+// the reception reports it sends on the local SenderReports, as in RFC 3550,
+// 6.4.1, and smooths the measurements as TCP does (RFC 6298): SRTT follows
+// the measurements with a gain of 1/8, and RTTVar their deviation from SRTT
+// with a gain of 1/4.
+//
+// The round trip time of a report is its arrival time minus the time the
+// SenderReport it refers to was sent, minus the delay the reporter held it.
+// The send time is taken from SentSenderReport if it was recorded, and
+// otherwise from the NTP timestamp of the SenderReport, which must then be
+// the local wallclock.
+//
+// An RTTEstimator isn't safe for concurrent use; see SyncRTTEstimator.
+type RTTEstimator struct {
+	// Logger receives the measurements dropped for being negative. If nil,
+	// NopLogger is used.
+	Logger Logger
+
+	srtt    time.Duration
+	rttVar  time.Duration
+	latest  time.Duration
+	samples int
+
+	// send times of recent SenderReports by the middle 32 bits of their NTP
+	// timestamp
+	sent map[uint32]time.Time
+}
+
+// NewRTTEstimator creates an RTTEstimator without measurements.
+func NewRTTEstimator() *RTTEstimator {
+	return &RTTEstimator{}
+}
+
+// SentSenderReport records that sr was sent at the local time at, so reports
+// referring to it measure the round trip time on the local clock.
+func (e *RTTEstimator) SentSenderReport(sr *SenderReport, at time.Time) {
+	if e.sent == nil {
+		e.sent = make(map[uint32]time.Time)
+	}
+	if len(e.sent) >= rttMaxSenderReports {
+		var oldest uint32
+		var oldestAt time.Time
+		for lsr, t := range e.sent {
+			if oldestAt.IsZero() || t.Before(oldestAt) {
+				oldest, oldestAt = lsr, t
+			}
+		}
+		delete(e.sent, oldest)
+	}
+	e.sent[uint32(sr.NTPTime>>16)] = at
+}
+
+// OnReceptionReport measures the round trip time from r, received at
+// arrival, and returns the measurement. It returns false if r doesn't refer
+// to a SenderReport or the measurement is negative, as happens when the
+// clocks are off.
+func (e *RTTEstimator) OnReceptionReport(r ReceptionReport, arrival time.Time) (time.Duration, bool) {
+	if r.LastSenderReport == 0 {
+		return 0, false
+	}
+	if sent, ok := e.sent[r.LastSenderReport]; ok {
+		rtt := arrival.Sub(sent) - compactNTPDuration(r.Delay)
+		if rtt < 0 {
+			if debugChecks {
+				loggerOr(e.Logger).Debug("rtcp: negative round trip time", "ssrc", r.SSRC, "rtt", rtt)
+			}
+			return 0, false
+		}
+		e.OnRTT(rtt)
+		return rtt, true
+	}
+	return e.OnDelaySinceLast(r.LastSenderReport, r.Delay, arrival)
+}
+
+// OnReceivedPacket measures the round trip time from the reception reports
+// on ssrc, the local source, in rp, which may be a CompoundPacket, using its
+// arrival time. It returns the last measurement, and false if rp had no
+// report on ssrc that could be measured.
+func (e *RTTEstimator) OnReceivedPacket(rp ReceivedPacket, ssrc uint32) (time.Duration, bool) {
+	var rtt time.Duration
+	var measured bool
+	var visit func(p Packet)
+	visit = func(p Packet) {
+		var reports []ReceptionReport
+		switch p := p.(type) {
+		case *SenderReport:
+			reports = p.Reports
+		case *ReceiverReport:
+			reports = p.Reports
+		case *CompoundPacket:
+			for _, sub := range *p {
+				visit(sub)
+			}
+		}
+		for _, r := range reports {
+			if r.SSRC != ssrc {
+				continue
+			}
+			if d, ok := e.OnReceptionReport(r, rp.ArrivalTime); ok {
+				rtt, measured = d, true
+			}
+		}
+	}
+	visit(rp.Packet)
+	return rtt, measured
+}
+
+// OnDelaySinceLast measures the round trip time from a timestamp last, the
+// middle 32 bits of an NTP timestamp sent by the local participant, echoed
+// after delay units of 1/65536 seconds and received at arrival, and returns
+// the measurement. This is the arithmetic of reception reports, and of the
+// LRR and DLRR fields of the DLRR blocks of extended reports (RFC 3611, 4.5),
+// by which receivers measure their round trip time. It returns false if the
+// measurement is negative.
+func (e *RTTEstimator) OnDelaySinceLast(last, delay uint32, arrival time.Time) (time.Duration, bool) {
+	// compact NTP timestamps wrap every 18 hours; the difference doesn't
+	rtt := int32(uint32(ntpTime(arrival)>>16) - last - delay)
+	if rtt < 0 {
+		if debugChecks {
+			loggerOr(e.Logger).Debug("rtcp: negative round trip time", "rtt", -compactNTPDuration(uint32(-rtt)))
+		}
+		return 0, false
+	}
+	d := compactNTPDuration(uint32(rtt))
+	e.OnRTT(d)
+	return d, true
+}
+
+// OnRTT adds a round trip time measured by other means.
+func (e *RTTEstimator) OnRTT(rtt time.Duration) {
+	e.latest = rtt
+	e.samples++
+	if e.samples == 1 {
+		e.srtt = rtt
+		e.rttVar = rtt / 2
+		return
+	}
+
+	deviation := e.srtt - rtt
+	if deviation < 0 {
+		deviation = -deviation
+	}
+	e.rttVar = time.Duration((1-rttBeta)*float64(e.rttVar) + rttBeta*float64(deviation))
+	e.srtt = time.Duration((1-rttAlpha)*float64(e.srtt) + rttAlpha*float64(rtt))
+}
+
+// SRTT returns the smoothed round trip time, or zero before the first
+// measurement.
+func (e *RTTEstimator) SRTT() time.Duration {
+	return e.srtt
+}
+
+// RTTVar returns the smoothed deviation of the measurements from SRTT.
+func (e *RTTEstimator) RTTVar() time.Duration {
+	return e.rttVar
+}
+
+// Latest returns the latest measurement.
+func (e *RTTEstimator) Latest() time.Duration {
+	return e.latest
+}
+
+// Samples returns the number of measurements.
+func (e *RTTEstimator) Samples() int {
+	return e.samples
+}
+
+// compactNTPDuration converts d in units of 1/65536 seconds to a duration
+func compactNTPDuration(d uint32) time.Duration {
+	return time.Duration(d) * time.Second / 65536
+}
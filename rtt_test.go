@@ -0,0 +1,71 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRTTEstimatorSmoothing(t *testing.T) {
+	assert := assert.New(t)
+
+	e := NewRTTEstimator()
+	assert.Equal(time.Duration(0), e.SRTT())
+
+	e.OnRTT(100 * time.Millisecond)
+	assert.Equal(100*time.Millisecond, e.SRTT())
+	assert.Equal(50*time.Millisecond, e.RTTVar())
+
+	// RTTVar = 3/4 * 50 + 1/4 * 100, SRTT = 7/8 * 100 + 1/8 * 200
+	e.OnRTT(200 * time.Millisecond)
+	assert.Equal(62500*time.Microsecond, e.RTTVar())
+	assert.Equal(112500*time.Microsecond, e.SRTT())
+	assert.Equal(200*time.Millisecond, e.Latest())
+	assert.Equal(2, e.Samples())
+}
+
+func TestRTTEstimatorReceptionReport(t *testing.T) {
+	assert := assert.New(t)
+
+	sentAt := time.Unix(1000, 0)
+	sr := NewSR().WithSSRC(1).WithNTPTime(sentAt).Build()
+	lsr := uint32(sr.NTPTime >> 16)
+
+	// held for 100ms by the reporter, 50ms on the wire each way
+	report := ReceptionReport{SSRC: 1, LastSenderReport: lsr, Delay: 6554}
+	arrival := sentAt.Add(200 * time.Millisecond)
+
+	e := NewRTTEstimator()
+	rtt, ok := e.OnReceptionReport(report, arrival)
+	assert.True(ok)
+	assert.InDelta(float64(100*time.Millisecond), float64(rtt), float64(time.Millisecond))
+
+	// a recorded send time is used rather than the NTP timestamp
+	e.SentSenderReport(sr, sentAt.Add(-time.Hour))
+	rtt, ok = e.OnReceptionReport(report, sentAt.Add(-2*time.Hour))
+	assert.False(ok)
+	assert.Equal(time.Duration(0), rtt)
+	rtt, ok = e.OnReceptionReport(report, sentAt.Add(-time.Hour+200*time.Millisecond))
+	assert.True(ok)
+	assert.InDelta(float64(100*time.Millisecond), float64(rtt), float64(time.Millisecond))
+	assert.Equal(2, e.Samples())
+
+	// reports without a SenderReport don't measure anything
+	_, ok = e.OnReceptionReport(ReceptionReport{SSRC: 1}, arrival)
+	assert.False(ok)
+	// nor do reports from the future
+	_, ok = NewRTTEstimator().OnReceptionReport(report, sentAt)
+	assert.False(ok)
+}
+
+func TestRTTEstimatorSentSenderReports(t *testing.T) {
+	e := NewRTTEstimator()
+	start := time.Unix(1000, 0)
+	for i := 0; i < 2*rttMaxSenderReports; i++ {
+		at := start.Add(time.Duration(i) * time.Second)
+		e.SentSenderReport(NewSR().WithNTPTime(at).Build(), at)
+	}
+	assert.Len(t, e.sent, rttMaxSenderReports)
+	assert.NotContains(t, e.sent, uint32(ntpTime(start)>>16))
+}
@@ -0,0 +1,101 @@
+package rtcp
+
+import (
+	"sort"
+
+	"github.com/pion/rtcp/seqnum"
+)
+
+// An RTXRewriter translates NACK feedback between a media stream and its
+// retransmission stream (RTX, RFC 4588), as needed by a forwarder that sends
+// retransmissions on its own. NACKs against the RTX stream are rewritten to
+// the original sequence numbers of the media stream, and NACKs for packets
+// that were already repaired, through RTX or FEC, are dropped so they aren't
+// forwarded upstream.
+//
+// TransportLayerCC feedback needs no rewriting: RTX packets carry the
+// transport-wide sequence number extension like every other packet of the
+// transport, numbered from the same counter, so the feedback for them is in
+// the same sequence space whichever SSRC it names.
+type RTXRewriter struct {
+	MediaSSRC uint32
+	RTXSSRC   uint32
+
+	// RTX sequence number to original sequence number
+	retransmitted *SequenceMap
+	// original sequence numbers that were repaired, mapped to themselves
+	repaired *SequenceMap
+}
+
+// NewRTXRewriter creates an RTXRewriter for the given media and RTX SSRCs,
+// remembering at least history sequence numbers of each. A history of zero
+// means DefaultSequenceMapSize.
+func NewRTXRewriter(mediaSSRC, rtxSSRC uint32, history int) *RTXRewriter {
+	return &RTXRewriter{
+		MediaSSRC:     mediaSSRC,
+		RTXSSRC:       rtxSSRC,
+		retransmitted: NewSequenceMap(history),
+		repaired:      NewSequenceMap(history),
+	}
+}
+
+// OnRetransmit records that the media packet with sequence number original
+// was retransmitted as sequence number rtx of the RTX stream.
+func (r *RTXRewriter) OnRetransmit(original, rtx uint16) {
+	r.retransmitted.Set(rtx, original)
+}
+
+// OnRepaired records that the media packet with sequence number original was
+// recovered, so NACKs for it can be dropped.
+func (r *RTXRewriter) OnRepaired(original uint16) {
+	r.repaired.Set(original, original)
+}
+
+// OnReceived records that the media packet with sequence number original
+// arrived. It is the same as OnRepaired; losses are only reported for
+// packets that never arrived.
+func (r *RTXRewriter) OnReceived(original uint16) {
+	r.OnRepaired(original)
+}
+
+// RewriteNACK returns the NACK to forward for n. A NACK for the RTX stream
+// is rewritten to the media stream, with the lost retransmissions' original
+// sequence numbers; retransmissions that aren't remembered are dropped. In
+// NACKs for the media stream, repaired packets are dropped. NACKs for other
+// SSRCs are returned unchanged. It returns nil if no packets are left.
+func (r *RTXRewriter) RewriteNACK(n *TransportLayerNack) *TransportLayerNack {
+	if n.MediaSSRC != r.MediaSSRC && n.MediaSSRC != r.RTXSSRC {
+		return n
+	}
+
+	var seqs []uint16
+	for i := range n.Nacks {
+		for _, seq := range n.Nacks[i].PacketList() {
+			if n.MediaSSRC == r.RTXSSRC {
+				var ok bool
+				if seq, ok = r.retransmitted.Get(seq); !ok {
+					continue
+				}
+			}
+			if _, ok := r.repaired.Get(seq); ok {
+				continue
+			}
+			seqs = append(seqs, seq)
+		}
+	}
+	if len(seqs) == 0 {
+		return nil
+	}
+	// retransmissions are remembered in the order they were sent, not in the
+	// order of their original sequence numbers, which may also wrap around
+	first := seqs[0]
+	sort.Slice(seqs, func(i, j int) bool {
+		return seqnum.Distance(first, seqs[i]) < seqnum.Distance(first, seqs[j])
+	})
+
+	return &TransportLayerNack{
+		SenderSSRC: n.SenderSSRC,
+		MediaSSRC:  r.MediaSSRC,
+		Nacks:      NackPairsFromSequenceNumbers(seqs),
+	}
+}
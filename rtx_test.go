@@ -0,0 +1,62 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRTXRewriter(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRTXRewriter(100, 200, 0)
+	r.OnRetransmit(50, 1)
+	r.OnRetransmit(53, 2)
+	r.OnRetransmit(54, 3)
+
+	// retransmissions 1 and 3 were lost as well; 9 was never sent
+	got := r.RewriteNACK(&TransportLayerNack{
+		SenderSSRC: 5,
+		MediaSSRC:  200,
+		Nacks:      NackPairsFromSequenceNumbers([]uint16{1, 3, 9}),
+	})
+	assert.Equal(&TransportLayerNack{
+		SenderSSRC: 5,
+		MediaSSRC:  100,
+		Nacks:      []NackPair{{PacketID: 50, LostPackets: 0x8}},
+	}, got)
+
+	// repaired packets are dropped from media NACKs
+	r.OnRepaired(61)
+	r.OnReceived(62)
+	got = r.RewriteNACK(&TransportLayerNack{
+		SenderSSRC: 5,
+		MediaSSRC:  100,
+		Nacks:      NackPairsFromSequenceNumbers([]uint16{60, 61, 62, 63}),
+	})
+	assert.Equal(&TransportLayerNack{
+		SenderSSRC: 5,
+		MediaSSRC:  100,
+		Nacks:      []NackPair{{PacketID: 60, LostPackets: 0x4}},
+	}, got)
+
+	// and from rewritten RTX NACKs
+	r.OnRepaired(53)
+	got = r.RewriteNACK(&TransportLayerNack{MediaSSRC: 200, Nacks: NackPairsFromSequenceNumbers([]uint16{2})})
+	assert.Nil(got)
+
+	// translated sequence numbers are packed in wraparound order, whatever
+	// order they were retransmitted in
+	r.OnRetransmit(1, 10)
+	r.OnRetransmit(65534, 11)
+	r.OnRetransmit(3, 12)
+	r.OnRetransmit(65535, 13)
+	got = r.RewriteNACK(&TransportLayerNack{MediaSSRC: 200, Nacks: NackPairsFromSequenceNumbers([]uint16{10, 11, 12, 13})})
+	assert.Equal(&TransportLayerNack{
+		MediaSSRC: 100,
+		Nacks:     []NackPair{{PacketID: 65534, LostPackets: 0x15}},
+	}, got)
+
+	other := &TransportLayerNack{MediaSSRC: 300, Nacks: []NackPair{{PacketID: 1}}}
+	assert.Equal(other, r.RewriteNACK(other))
+}
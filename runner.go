@@ -0,0 +1,213 @@
+package rtcp
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultPacketOverhead is the size of the IPv4 and UDP headers, which
+	// a Runner adds to the size of each compound packet it reports to its
+	// scheduler when its Overhead is zero.
+	DefaultPacketOverhead = 20 + 8
+)
+
+// A ReportScheduler decides when the next RTCP report is due. It's
+// implemented by Scheduler and SyncScheduler.
+type ReportScheduler interface {
+	// Interval returns the time until the next report.
+	Interval() time.Duration
+	// OnSent records that a compound packet of the given size, including
+	// lower layer headers, was sent.
+	OnSent(size int)
+}
+
+// A leavingScheduler is a ReportScheduler that implements BYE
+// reconsideration, like Scheduler
+type leavingScheduler interface {
+	ReportScheduler
+	Leave(byeSize int) (immediate bool)
+}
+
+var (
+	_ ReportScheduler  = (*Scheduler)(nil)     // assert is a ReportScheduler
+	_ ReportScheduler  = (*SyncScheduler)(nil) // assert is a ReportScheduler
+	_ leavingScheduler = (*Scheduler)(nil)     // assert is a leavingScheduler
+	_ leavingScheduler = (*SyncScheduler)(nil) // assert is a leavingScheduler
+)
+
+// A Runner sends periodic RTCP reports at the intervals computed by a
+// scheduler. Each report is a compound packet of a SenderReport, or a
+// ReceiverReport if the local participant isn't sending, followed by a
+// SourceDescription with the CNAME and any feedback:
+//
+//	r := &rtcp.Runner{
+//		SSRC:             ssrc,
+//		CNAME:            cname,
+//		Scheduler:        &rtcp.Scheduler{Bandwidth: bandwidth},
+//		ReceptionReports: stats.ReceptionReports,
+//		WriteRTCP:        conn.WriteRTCP,
+//	}
+//	go r.Run(ctx)
+//
+// The callbacks are called from the goroutine running Run, so the state
+// they read must be safe for concurrent use.
+type Runner struct {
+	// SSRC and CNAME of the local participant
+	SSRC  uint32
+	CNAME string
+	// Scheduler computes the interval between reports.
+	Scheduler ReportScheduler
+	// SenderReport optionally returns the sender information of the local
+	// participant. If it's nil or returns nil, a ReceiverReport is sent
+	// instead. The SSRC and Reports of the returned packet are set by the
+	// Runner.
+	SenderReport func() *SenderReport
+	// ReceptionReports optionally returns the reception reports for the
+	// sources heard since the previous report. Reports that don't fit into
+	// the first packet are sent in additional ReceiverReports.
+	ReceptionReports func() []ReceptionReport
+	// Feedback optionally returns further packets to append to the report.
+	Feedback func() []Packet
+	// Capabilities optionally lists the feedback the remote endpoint
+	// negotiated. If set, feedback it didn't negotiate is dropped from the
+	// report.
+	Capabilities *FeedbackCapabilities
+	// WriteRTCP sends a report.
+	WriteRTCP func([]Packet) error
+	// The size of the lower layer headers, added to the size of each
+	// report. If zero, DefaultPacketOverhead is used.
+	Overhead int
+}
+
+// Run sends reports until ctx is done, then returns ctx.Err(). It returns
+// early if a report can't be built or written.
+func (r *Runner) Run(ctx context.Context) error {
+	for {
+		timer := time.NewTimer(r.Scheduler.Interval())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if err := r.Send(); err != nil {
+			return err
+		}
+	}
+}
+
+// Send builds a report and writes it immediately.
+func (r *Runner) Send() error {
+	packets := r.Report()
+	data, err := CompoundPacket(packets).Marshal()
+	if err != nil {
+		return err
+	}
+	if err := r.WriteRTCP(packets); err != nil {
+		return err
+	}
+
+	r.Scheduler.OnSent(len(data) + r.overhead())
+	return nil
+}
+
+func (r *Runner) overhead() int {
+	if r.Overhead == 0 {
+		return DefaultPacketOverhead
+	}
+	return r.Overhead
+}
+
+// Bye sends a Goodbye for the local participant, with an optional reason,
+// and returns once it's written or ctx is done. It should be called once Run
+// returned. If the Scheduler implements BYE reconsideration, as Scheduler
+// does, the Goodbye is delayed in large sessions until it's due; the
+// Goodbyes received meanwhile must be passed to Scheduler.OnReceivedBye.
+func (r *Runner) Bye(ctx context.Context, reason string) error {
+	packets := []Packet{
+		&ReceiverReport{SSRC: r.SSRC},
+		&SourceDescription{Chunks: []SourceDescriptionChunk{{
+			Source: r.SSRC,
+			Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: r.CNAME}},
+		}}},
+		&Goodbye{Sources: []uint32{r.SSRC}, Reason: reason},
+	}
+	data, err := CompoundPacket(packets).Marshal()
+	if err != nil {
+		return err
+	}
+
+	if s, ok := r.Scheduler.(leavingScheduler); ok && !s.Leave(len(data)+r.overhead()) {
+		if err := r.waitBye(ctx); err != nil {
+			return err
+		}
+	}
+	return r.WriteRTCP(packets)
+}
+
+// waitBye waits until the Goodbye of the local participant is due. As in
+// RFC 3550, 6.3.7, the interval is computed again when it elapses, with the
+// Goodbyes received meanwhile, and the wait goes on if it grew.
+func (r *Runner) waitBye(ctx context.Context) error {
+	start := time.Now()
+	next := start.Add(r.Scheduler.Interval())
+	for {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		next = start.Add(r.Scheduler.Interval())
+		if !time.Now().Before(next) {
+			return nil
+		}
+	}
+}
+
+// Report builds the next report.
+func (r *Runner) Report() []Packet {
+	var reports []ReceptionReport
+	if r.ReceptionReports != nil {
+		reports = r.ReceptionReports()
+	}
+	first := reports
+	if len(first) > countMax {
+		first = first[:countMax]
+	}
+	reports = reports[len(first):]
+
+	var sr *SenderReport
+	if r.SenderReport != nil {
+		sr = r.SenderReport()
+	}
+
+	var packets []Packet
+	if sr != nil {
+		sr.SSRC = r.SSRC
+		sr.Reports = first
+		packets = append(packets, sr)
+	} else {
+		packets = append(packets, &ReceiverReport{SSRC: r.SSRC, Reports: first})
+	}
+
+	packets = appendOverflowReports(packets, r.SSRC, reports)
+
+	packets = append(packets, &SourceDescription{Chunks: []SourceDescriptionChunk{{
+		Source: r.SSRC,
+		Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: r.CNAME}},
+	}}})
+
+	if r.Feedback != nil {
+		feedback := r.Feedback()
+		if r.Capabilities != nil {
+			feedback = r.Capabilities.Filter(feedback)
+		}
+		packets = append(packets, feedback...)
+	}
+	return packets
+}
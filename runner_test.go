@@ -0,0 +1,137 @@
+package rtcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedScheduler struct {
+	interval time.Duration
+	sent     []int
+}
+
+func (s *fixedScheduler) Interval() time.Duration { return s.interval }
+
+func (s *fixedScheduler) OnSent(size int) { s.sent = append(s.sent, size) }
+
+func TestRunnerReport(t *testing.T) {
+	assert := assert.New(t)
+
+	reports := make([]ReceptionReport, 40)
+	for i := range reports {
+		reports[i].SSRC = uint32(i + 10)
+	}
+	r := &Runner{
+		SSRC:             1,
+		CNAME:            "cname",
+		ReceptionReports: func() []ReceptionReport { return reports },
+	}
+
+	packets := r.Report()
+	assert.Len(packets, 3)
+	assert.Equal(&ReceiverReport{SSRC: 1, Reports: reports[:31]}, packets[0])
+	assert.Equal(&ReceiverReport{SSRC: 1, Reports: reports[31:]}, packets[1])
+	assert.Equal(&SourceDescription{Chunks: []SourceDescriptionChunk{{
+		Source: 1,
+		Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: "cname"}},
+	}}}, packets[2])
+
+	// a sender sends an SR, followed by feedback
+	pli := &PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}
+	r.SenderReport = func() *SenderReport { return &SenderReport{PacketCount: 5} }
+	r.ReceptionReports = nil
+	r.Feedback = func() []Packet { return []Packet{pli} }
+	packets = r.Report()
+	assert.Len(packets, 3)
+	assert.Equal(&SenderReport{SSRC: 1, PacketCount: 5}, packets[0])
+	assert.Equal(pli, packets[2])
+
+	// feedback the remote endpoint didn't negotiate is dropped
+	r.Capabilities = &FeedbackCapabilities{FIR: true}
+	assert.Len(r.Report(), 2)
+	r.Capabilities.PLI = true
+	assert.Len(r.Report(), 3)
+}
+
+func TestRunnerRun(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheduler := &fixedScheduler{interval: time.Millisecond}
+	var written [][]Packet
+	r := &Runner{
+		SSRC:      1,
+		CNAME:     "cname",
+		Scheduler: scheduler,
+		WriteRTCP: func(packets []Packet) error {
+			written = append(written, packets)
+			if len(written) == 3 {
+				cancel()
+			}
+			return nil
+		},
+	}
+
+	assert.Equal(context.Canceled, r.Run(ctx))
+	assert.Len(written, 3)
+
+	// RR (8) + SDES (16) + IPv4 and UDP headers
+	assert.Equal([]int{52, 52, 52}, scheduler.sent)
+}
+
+func TestRunnerRunError(t *testing.T) {
+	errWrite := errors.New("write failed")
+	r := &Runner{
+		Scheduler: &fixedScheduler{interval: time.Millisecond},
+		WriteRTCP: func([]Packet) error { return errWrite },
+	}
+	assert.Equal(t, errWrite, r.Run(context.Background()))
+}
+
+func TestRunnerBye(t *testing.T) {
+	assert := assert.New(t)
+
+	var written []Packet
+	r := &Runner{
+		SSRC:      1,
+		CNAME:     "cname",
+		Scheduler: &fixedScheduler{interval: time.Hour},
+		WriteRTCP: func(packets []Packet) error {
+			written = packets
+			return nil
+		},
+	}
+	assert.NoError(r.Bye(context.Background(), "done"))
+	assert.Len(written, 3)
+	assert.Equal(&Goodbye{Sources: []uint32{1}, Reason: "done"}, written[2])
+	_, err := CompoundPacket(written).Marshal()
+	assert.NoError(err)
+
+	// a small session says goodbye immediately
+	s := &Scheduler{Bandwidth: 1000, Membership: staticMembers{members: 10}}
+	r.Scheduler = s
+	written = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(r.Bye(ctx, ""))
+	assert.Len(written, 3)
+
+	// a large one reconsiders
+	s.Membership = staticMembers{members: 1000}
+	written = nil
+	assert.Equal(context.Canceled, r.Bye(ctx, ""))
+	assert.Nil(written)
+	assert.True(s.Leaving())
+
+	s = &Scheduler{Bandwidth: 1e6, MinInterval: time.Millisecond, Membership: staticMembers{members: 1000}}
+	r.Scheduler = s
+	assert.NoError(r.Bye(context.Background(), ""))
+	assert.Len(written, 3)
+	assert.True(s.Leaving())
+}
@@ -0,0 +1,35 @@
+package rtcp
+
+// ScanCompound walks the RTCP packets of a compound datagram without decoding
+// their payloads. For every packet fn is called with the parsed Header and the
+// body following it (including any padding); returning false stops the scan.
+//
+// This is intended for routers that only need the packet type or SSRC to
+// forward a packet. body aliases raw and must not be retained past raw's
+// lifetime. An error is returned if a header is malformed or a packet's
+// length runs past the end of raw; packets before it have already been passed
+// to fn.
+func ScanCompound(raw []byte, fn func(h Header, body []byte) bool) error {
+	if len(raw) == 0 {
+		return errInvalidHeader
+	}
+
+	for len(raw) != 0 {
+		var h Header
+		if err := h.Unmarshal(raw); err != nil {
+			return err
+		}
+
+		size := h.size()
+		if size > len(raw) {
+			return errPacketTooShort
+		}
+
+		if !fn(h, raw[headerLength:size]) {
+			return nil
+		}
+		raw = raw[size:]
+	}
+
+	return nil
+}
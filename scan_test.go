@@ -0,0 +1,54 @@
+package rtcp
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanCompound(t *testing.T) {
+	var (
+		types []PacketType
+		ssrcs []uint32
+	)
+	err := ScanCompound(realPacket, func(h Header, body []byte) bool {
+		assert.Equal(t, int(h.Length)*4, len(body))
+		types = append(types, h.Type)
+		ssrcs = append(ssrcs, binary.BigEndian.Uint32(body))
+		return true
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []PacketType{
+		TypeReceiverReport,
+		TypeSourceDescription,
+		TypeGoodbye,
+		TypePayloadSpecificFeedback,
+		TypeTransportSpecificFeedback,
+	}, types)
+	assert.Equal(t, uint32(0x902f9e2e), ssrcs[0])
+
+	var calls int
+	err = ScanCompound(realPacket, func(h Header, body []byte) bool {
+		calls++
+		return false
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	for _, test := range []struct {
+		Name string
+		Data []byte
+		Err  error
+	}{
+		{Name: "nil", Data: nil, Err: errInvalidHeader},
+		{Name: "short header", Data: realPacket[:2], Err: errPacketTooShort},
+		{Name: "truncated", Data: realPacket[:10], Err: errPacketTooShort},
+		{Name: "bad version", Data: []byte{0x00, 0xc9, 0x00, 0x00}, Err: errBadVersion},
+	} {
+		err := ScanCompound(test.Data, func(Header, []byte) bool { return true })
+		if err != test.Err {
+			t.Fatalf("ScanCompound %q: err = %v, want %v", test.Name, err, test.Err)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package rtcp
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Constants from RFC 3550, A.7
+const (
+	// DefaultMinInterval is the minimum average time between RTCP packets
+	DefaultMinInterval = 5 * time.Second
+
+	senderBandwidthFraction   = 0.25
+	receiverBandwidthFraction = 1 - senderBandwidthFraction
+	// e - 3/2, compensates for the timer reconsideration algorithm
+	intervalCompensation = math.E - 1.5
+
+	// ByeReconsiderationThreshold is the session size from which a leaving
+	// participant delays its Goodbye, see Scheduler.Leave
+	ByeReconsiderationThreshold = 50
+)
+
+// A MemberCounter reports the size of an RTP session. It's implemented by
+// MemberTable.
+type MemberCounter interface {
+	// Members returns the number of participants, including the local one.
+	Members() int
+	// Senders returns the number of remote participants that are sending.
+	Senders() int
+}
+
+// A localSenderCounter is a MemberCounter that also tracks whether the local
+// participant is sending, like MemberTable
+type localSenderCounter interface {
+	MemberCounter
+	LocalSender() bool
+}
+
+// A Scheduler computes the interval between RTCP transmissions according
+// to RFC 3550, 6.3 and A.7
+//
+// A Scheduler isn't safe for concurrent use; see SyncScheduler.
+type Scheduler struct {
+	// The bandwidth available to RTCP, in octets per second. This is
+	// usually DefaultBandwidthFraction of the session bandwidth.
+	Bandwidth float64
+	// The minimum interval. If zero, DefaultMinInterval is used.
+	MinInterval time.Duration
+	// Membership supplies the member and sender counts. If nil the local
+	// participant is assumed to be alone in the session.
+	Membership MemberCounter
+	// Whether the local participant has sent RTP since the last two reports.
+	// It is also considered a sender while the Membership reports so, see
+	// MemberTable.LocalSender.
+	WeSent bool
+	// Source supplies the random numbers Interval randomizes the interval
+	// with, e.g. a seeded source to reproduce the intervals, or a
+	// TraceSource to record them. If nil, the global source of math/rand
+	// is used. It needn't be safe for concurrent use unless shared.
+	Source rand.Source
+
+	avgRTCPSize float64
+	sentReport  bool
+
+	// Goodbyes counted since Leave, including the local one
+	leaving    bool
+	byeMembers int
+}
+
+// OnSent updates the average RTCP packet size with a compound packet of the
+// given size (including lower layer headers) sent by the local participant.
+func (s *Scheduler) OnSent(size int) {
+	s.updateAverage(size)
+	s.sentReport = true
+}
+
+// OnReceived updates the average RTCP packet size with a received compound
+// packet of the given size (including lower layer headers). After Leave,
+// only packets with a Goodbye count, see OnReceivedBye.
+func (s *Scheduler) OnReceived(size int) {
+	if s.leaving {
+		return
+	}
+	s.updateAverage(size)
+}
+
+// OnReceivedBye records a received compound packet of the given size
+// (including lower layer headers) that has a Goodbye. Before Leave it's the
+// same as OnReceived; after, each Goodbye counts as a member, so that the
+// participants leaving together share the bandwidth.
+func (s *Scheduler) OnReceivedBye(size int) {
+	if s.leaving {
+		s.byeMembers++
+		s.updateAverage(size)
+		return
+	}
+	s.OnReceived(size)
+}
+
+// Leave starts the BYE reconsideration of RFC 3550, 6.3.7, with the size of
+// the compound packet carrying the Goodbye of the local participant. It
+// returns true if the session has fewer than ByeReconsiderationThreshold
+// members, and the Goodbye may be sent immediately.
+//
+// Otherwise, the Goodbye is to be sent after Interval, as a report would be:
+// the Scheduler restarts as if the local participant had just joined a
+// session whose members are those that sent a Goodbye since, so a mass
+// departure doesn't flood the session with Goodbyes. Leaving can't be
+// undone.
+func (s *Scheduler) Leave(byeSize int) (immediate bool) {
+	if members, _, _ := s.counts(); members < ByeReconsiderationThreshold {
+		return true
+	}
+	s.leaving = true
+	s.byeMembers = 1
+	s.avgRTCPSize = float64(byeSize)
+	s.sentReport = false
+	return false
+}
+
+// Leaving reports whether Leave started BYE reconsideration.
+func (s *Scheduler) Leaving() bool {
+	return s.leaving
+}
+
+func (s *Scheduler) updateAverage(size int) {
+	if s.avgRTCPSize == 0 {
+		s.avgRTCPSize = float64(size)
+		return
+	}
+	s.avgRTCPSize = float64(size)/16 + s.avgRTCPSize*15/16
+}
+
+// AverageSize returns the average compound RTCP packet size in octets.
+func (s *Scheduler) AverageSize() float64 {
+	return s.avgRTCPSize
+}
+
+func (s *Scheduler) counts() (members, senders int, weSent bool) {
+	if s.leaving {
+		return s.byeMembers, 0, false
+	}
+	members = 1
+	if s.Membership != nil {
+		members = s.Membership.Members()
+		senders = s.Membership.Senders()
+	}
+	if weSent = s.weSent(); weSent {
+		senders++
+	}
+	return members, senders, weSent
+}
+
+func (s *Scheduler) weSent() bool {
+	if s.WeSent {
+		return true
+	}
+	m, ok := s.Membership.(localSenderCounter)
+	return ok && m.LocalSender()
+}
+
+// DeterministicInterval returns the calculated interval Td, before
+// randomization. It's also the base for member timeouts.
+func (s *Scheduler) DeterministicInterval() time.Duration {
+	minInterval := s.MinInterval
+	if minInterval == 0 {
+		minInterval = DefaultMinInterval
+	}
+	// Not having sent a report yet halves the minimum so new participants
+	// are announced quickly.
+	if !s.sentReport {
+		minInterval /= 2
+	}
+
+	members, senders, weSent := s.counts()
+	n := float64(members)
+	bandwidth := s.Bandwidth
+
+	// Dedicate a share of the bandwidth to senders, so their reports (with
+	// the CNAME needed for synchronization) get out quickly.
+	if float64(senders) <= float64(members)*senderBandwidthFraction {
+		if weSent {
+			bandwidth *= senderBandwidthFraction
+			n = float64(senders)
+		} else {
+			bandwidth *= receiverBandwidthFraction
+			n -= float64(senders)
+		}
+	}
+
+	if bandwidth <= 0 {
+		return minInterval
+	}
+
+	t := time.Duration(s.avgRTCPSize * n / bandwidth * float64(time.Second))
+	if t < minInterval {
+		t = minInterval
+	}
+	return t
+}
+
+// Interval returns the randomized time until the next RTCP transmission.
+func (s *Scheduler) Interval() time.Duration {
+	t := float64(s.DeterministicInterval())
+	t *= randFloat64(s.Source) + 0.5
+	return time.Duration(t / intervalCompensation)
+}
@@ -0,0 +1,142 @@
+package rtcp
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type staticMembers struct {
+	members, senders int
+}
+
+func (s staticMembers) Members() int { return s.members }
+func (s staticMembers) Senders() int { return s.senders }
+
+func TestSchedulerDeterministicInterval(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Scheduler{Bandwidth: 1000}
+	// initial interval is halved
+	assert.Equal(DefaultMinInterval/2, s.DeterministicInterval())
+
+	s.OnSent(100)
+	assert.Equal(100.0, s.AverageSize())
+	assert.Equal(DefaultMinInterval, s.DeterministicInterval())
+	s.MinInterval = time.Second
+	assert.Equal(time.Second, s.DeterministicInterval())
+	s.MinInterval = 0
+
+	s.OnReceived(260)
+	avg := s.AverageSize()
+	assert.Equal(110.0, avg)
+
+	// 1000 receivers share 75% of the bandwidth
+	s.Membership = staticMembers{members: 1000, senders: 0}
+	assert.Equal(time.Duration(avg*1000/750*float64(time.Second)), s.DeterministicInterval())
+
+	// 10 senders share 25% of the bandwidth
+	s.MinInterval = time.Second
+	s.Membership = staticMembers{members: 1000, senders: 9}
+	s.WeSent = true
+	assert.Equal(time.Duration(avg*10/250*float64(time.Second)), s.DeterministicInterval())
+
+	// more than 25% senders, everyone shares the whole bandwidth
+	s.Membership = staticMembers{members: 20, senders: 10}
+	assert.Equal(time.Duration(avg*20/1000*float64(time.Second)), s.DeterministicInterval())
+}
+
+func TestSchedulerInterval(t *testing.T) {
+	s := &Scheduler{Bandwidth: 1000}
+	s.OnSent(100)
+
+	td := float64(s.DeterministicInterval())
+	for i := 0; i < 100; i++ {
+		interval := float64(s.Interval())
+		assert.True(t, interval >= td*0.5/intervalCompensation)
+		assert.True(t, interval <= td*1.5/intervalCompensation)
+	}
+}
+
+func TestSchedulerIntervalSource(t *testing.T) {
+	assert := assert.New(t)
+
+	intervals := func(source rand.Source) []time.Duration {
+		s := &Scheduler{Bandwidth: 1000, Source: source}
+		s.OnSent(100)
+		var out []time.Duration
+		for i := 0; i < 10; i++ {
+			out = append(out, s.Interval())
+		}
+		return out
+	}
+
+	// a seeded source reproduces the intervals
+	assert.Equal(intervals(rand.NewSource(1)), intervals(rand.NewSource(1)))
+	assert.NotEqual(intervals(rand.NewSource(1)), intervals(rand.NewSource(2)))
+
+	// and so does the trace of another source
+	trace := NewTraceSource(rand.NewSource(3))
+	recorded := intervals(trace)
+	assert.Len(trace.Trace(), 10)
+	assert.Equal(recorded, intervals(NewReplaySource(trace.Trace())))
+
+	// the extremes of the randomization
+	td := DefaultMinInterval
+	assert.Equal(time.Duration(float64(td)*0.5/intervalCompensation), intervals(NewReplaySource([]int64{0}))[0])
+	assert.Equal(time.Duration(float64(td)*(1.5-1.0/(1<<53))/intervalCompensation), intervals(NewReplaySource([]int64{1<<63 - 1}))[0])
+}
+
+func TestSchedulerLocalSender(t *testing.T) {
+	now := time.Unix(100, 0)
+	m := NewMemberTable(1)
+	m.Clock = ClockFunc(func() time.Time { return now })
+	for i := uint32(2); i <= 10; i++ {
+		m.Update([]Packet{&ReceiverReport{SSRC: i}})
+	}
+	s := &Scheduler{Bandwidth: 1000, MinInterval: time.Nanosecond, Membership: m}
+	s.OnSent(100)
+	avg := s.AverageSize()
+
+	// 10 receivers share 75% of the bandwidth
+	assert.Equal(t, time.Duration(avg*10/750*float64(time.Second)), s.DeterministicInterval())
+
+	// the local participant and 2 are senders, sharing 25%
+	m.OnLocalRTP()
+	m.OnRTP(2)
+	assert.Equal(t, time.Duration(avg*2/250*float64(time.Second)), s.DeterministicInterval())
+
+	now = now.Add(DefaultSenderTimeout + time.Second)
+	m.ExpireSenders()
+	assert.Equal(t, time.Duration(avg*10/750*float64(time.Second)), s.DeterministicInterval())
+}
+
+func TestSchedulerLeave(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Scheduler{Bandwidth: 1000, Membership: staticMembers{members: 10}}
+	s.OnSent(100)
+	assert.True(s.Leave(60))
+	assert.False(s.Leaving())
+
+	// 1000 members are leaving at once
+	s.Membership = staticMembers{members: 1000, senders: 100}
+	s.WeSent = true
+	assert.False(s.Leave(60))
+	assert.True(s.Leaving())
+	assert.Equal(60.0, s.AverageSize())
+	// alone in the session, as if just joined
+	assert.Equal(DefaultMinInterval/2, s.DeterministicInterval())
+
+	// other packets don't count, Goodbyes do
+	s.OnReceived(1000)
+	assert.Equal(60.0, s.AverageSize())
+	s.MinInterval = time.Millisecond
+	for i := 0; i < 99; i++ {
+		s.OnReceivedBye(60)
+	}
+	assert.Equal(60.0, s.AverageSize())
+	assert.Equal(time.Duration(60.0*100/750*float64(time.Second)), s.DeterministicInterval())
+}
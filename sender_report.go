@@ -35,7 +35,9 @@ type SenderReport struct {
 	// single synchronization source.
 	Reports []ReceptionReport
 	// ProfileExtensions contains additional, payload-specific information that needs to
-	// be reported regularly about the sender.
+	// be reported regularly about the sender. It's carried as is, so relays
+	// can pass on vendor data they don't understand. Its length should be a
+	// multiple of 32 bits; Marshal fills up the last word with zeros.
 	ProfileExtensions []byte
 }
 
@@ -118,6 +120,8 @@ func (r SenderReport) Marshal() ([]byte, error) {
 		return nil, errTooManyReports
 	}
 
+	// extensions that don't end on a 32-bit boundary are zero filled,
+	// rawPacket is already sized for it
 	copy(packetBody[offset:], r.ProfileExtensions)
 
 	hData, err := r.Header().Marshal()
@@ -182,7 +186,18 @@ func (r *SenderReport) Unmarshal(rawPacket []byte) error {
 		return errWrongType
 	}
 
-	packetBody := rawPacket[headerLength:]
+	end := h.size()
+	if end < headerLength+srHeaderLength || end > len(rawPacket) {
+		return errPacketTooShort
+	}
+	if h.Padding {
+		padding := int(rawPacket[end-1])
+		if padding == 0 || headerLength+srHeaderLength+padding > end {
+			return errBadPadding
+		}
+		end -= padding
+	}
+	packetBody := rawPacket[headerLength:end]
 
 	r.SSRC = binary.BigEndian.Uint32(packetBody[srSSRCOffset:])
 	r.NTPTime = binary.BigEndian.Uint64(packetBody[srNTPOffset:])
@@ -206,6 +221,7 @@ func (r *SenderReport) Unmarshal(rawPacket []byte) error {
 		r.Reports = append(r.Reports, rr)
 	}
 
+	r.ProfileExtensions = nil
 	if offset < len(packetBody) {
 		r.ProfileExtensions = packetBody[offset:]
 	}
@@ -231,7 +247,8 @@ func (r *SenderReport) len() int {
 	for _, rep := range r.Reports {
 		repsLength += rep.len()
 	}
-	return headerLength + srHeaderLength + repsLength + len(r.ProfileExtensions)
+	peLength := len(r.ProfileExtensions) + getPadding(len(r.ProfileExtensions))
+	return headerLength + srHeaderLength + repsLength + peLength
 }
 
 // Header returns the Header associated with this packet.
@@ -20,8 +20,8 @@ func TestSenderReportUnmarshal(t *testing.T) {
 		{
 			Name: "valid",
 			Data: []byte{
-				// v=2, p=0, count=1, SR, len=7
-				0x81, 0xc8, 0x0, 0x7,
+				// v=2, p=0, count=1, SR, len=12
+				0x81, 0xc8, 0x0, 0xc,
 				// ssrc=0x902f9e2e
 				0x90, 0x2f, 0x9e, 0x2e,
 				// ntp=0xda8bd1fcdddda05a
@@ -128,8 +128,8 @@ func TestSenderReportUnmarshal(t *testing.T) {
 		{
 			Name: "with extension", // issue #447
 			Data: []byte{
-				// v=2, p=0, count=0, SR, len=6
-				0x80, 0xc8, 0x0, 0x6,
+				// v=2, p=0, count=0, SR, len=13
+				0x80, 0xc8, 0x0, 0xd,
 				// ssrc=0x2b7ec0c5
 				0x2b, 0x7e, 0xc0, 0xc5,
 				// ntp=0xe020a2a952a53fc0
@@ -168,6 +168,22 @@ func TestSenderReportUnmarshal(t *testing.T) {
 			},
 			WantError: nil,
 		},
+		{
+			Name: "bad padding",
+			Data: []byte{
+				// v=2, p=1, count=0, SR, len=7
+				0xa0, 0xc8, 0x0, 0x7,
+				0x2b, 0x7e, 0xc0, 0xc5,
+				0xe0, 0x20, 0xa2, 0xa9,
+				0x52, 0xa5, 0x3f, 0xc0,
+				0x2e, 0x48, 0xa5, 0x52,
+				0x0, 0x0, 0x0, 0x46,
+				0x0, 0x0, 0x12, 0x1d,
+				// padding count past the extensions
+				0x1, 0x0, 0x0, 0x8,
+			},
+			WantError: errBadPadding,
+		},
 	} {
 		var sr SenderReport
 		err := sr.Unmarshal(test.Data)
@@ -276,3 +292,82 @@ func TestSenderReportRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestSenderReportHeaderLength(t *testing.T) {
+	sr := SenderReport{
+		SSRC:              1,
+		Reports:           []ReceptionReport{{SSRC: 2}},
+		ProfileExtensions: []byte{1, 2, 3},
+	}
+	data, err := sr.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var h Header
+	if err := h.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal header: %v", err)
+	}
+	if got, want := len(data), int(h.Length+1)*4; got != want {
+		t.Fatalf("sr marshaled size: got %d, want %d", got, want)
+	}
+	if got, want := h.Count, uint8(1); got != want {
+		t.Fatalf("sr header count: got %d, want %d", got, want)
+	}
+
+	packets, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got, want := packets[0].(*SenderReport).ProfileExtensions, []byte{1, 2, 3, 0}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("sr profile extensions: got %v, want %v", got, want)
+	}
+}
+
+func TestSenderReportProfileExtensionsRelay(t *testing.T) {
+	sr := SenderReport{
+		SSRC:              1,
+		NTPTime:           2,
+		Reports:           []ReceptionReport{{SSRC: 3, LastSequenceNumber: 4}},
+		ProfileExtensions: []byte{0xde, 0xad, 0xbe, 0xef, 1, 2, 3, 4},
+	}
+	sdes := &SourceDescription{Chunks: []SourceDescriptionChunk{{
+		Source: 1,
+		Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: "cname"}},
+	}}}
+	raw, err := Marshal([]Packet{&sr, sdes})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	packets, err := Unmarshal(raw)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got, want := packets[0].(*SenderReport).ProfileExtensions, sr.ProfileExtensions; !reflect.DeepEqual(got, want) {
+		t.Fatalf("profile extensions: got %x, want %x", got, want)
+	}
+	relayed, err := Marshal(packets)
+	if err != nil {
+		t.Fatalf("Marshal relayed: %v", err)
+	}
+	if !reflect.DeepEqual(relayed, raw) {
+		t.Fatalf("relayed: got %x, want %x", relayed, raw)
+	}
+
+	// padding isn't part of the extensions
+	padded, err := sr.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	padded = append(padded, 0, 0, 0, 4)
+	padded[0] |= 1 << paddingShift
+	padded[3]++
+	var decoded SenderReport
+	if err := decoded.Unmarshal(padded); err != nil {
+		t.Fatalf("Unmarshal padded: %v", err)
+	}
+	if got, want := decoded.ProfileExtensions, sr.ProfileExtensions; !reflect.DeepEqual(got, want) {
+		t.Fatalf("padded profile extensions: got %x, want %x", got, want)
+	}
+}
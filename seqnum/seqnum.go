@@ -0,0 +1,76 @@
+// Package seqnum implements wraparound-aware arithmetic for 16-bit RTP and
+// transport wide sequence numbers.
+//
+// Sequence numbers are compared in a window of half the number space: b is
+// considered newer than a when it is less than 2^15 steps ahead of a. These
+// helpers are shared by the TWCC, NACK and receiver statistics code so all of
+// them agree on ordering around wraparound.
+package seqnum
+
+const (
+	halfRange = 1 << 15
+	fullRange = 1 << 16
+)
+
+// Distance returns the signed number of steps from a to b. It is positive if b
+// is newer than a and negative if b is older than a.
+func Distance(a, b uint16) int {
+	return int(int16(b - a))
+}
+
+// Less reports whether a is older than b.
+func Less(a, b uint16) bool {
+	return a != b && b-a < halfRange
+}
+
+// LessOrEqual reports whether a is older than or equal to b.
+func LessOrEqual(a, b uint16) bool {
+	return a == b || Less(a, b)
+}
+
+// Newest returns the newer of a and b.
+func Newest(a, b uint16) uint16 {
+	if Less(a, b) {
+		return b
+	}
+	return a
+}
+
+// Unwrapper extends 16-bit sequence numbers to 64-bit values that keep
+// increasing across wraparounds. The zero value is ready to use; the first
+// sequence number passed to Unwrap is returned unchanged.
+type Unwrapper struct {
+	started bool
+	last    int64
+}
+
+// Unwrap returns the extended value of seq. Sequence numbers older than the
+// previous one (reordering) unwrap to values below it rather than jumping
+// forward a full cycle.
+func (u *Unwrapper) Unwrap(seq uint16) int64 {
+	if !u.started {
+		u.started = true
+		u.last = int64(seq)
+		return u.last
+	}
+
+	u.last += int64(Distance(uint16(u.last), seq))
+	return u.last
+}
+
+// Peek returns the value Unwrap would return for seq without updating state.
+func (u *Unwrapper) Peek(seq uint16) int64 {
+	if !u.started {
+		return int64(seq)
+	}
+	return u.last + int64(Distance(uint16(u.last), seq))
+}
+
+// Cycles returns the number of completed 16-bit wraparounds of the most
+// recently unwrapped value.
+func (u *Unwrapper) Cycles() int64 {
+	if u.last < 0 {
+		return (u.last - fullRange + 1) / fullRange
+	}
+	return u.last / fullRange
+}
@@ -0,0 +1,59 @@
+package seqnum
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(0, Distance(10, 10))
+	assert.Equal(5, Distance(10, 15))
+	assert.Equal(-5, Distance(15, 10))
+	assert.Equal(2, Distance(65535, 1))
+	assert.Equal(-2, Distance(1, 65535))
+	assert.Equal(-halfRange, Distance(0, halfRange))
+}
+
+func TestLess(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(Less(1, 2))
+	assert.False(Less(2, 1))
+	assert.False(Less(2, 2))
+	assert.True(Less(65535, 0))
+	assert.False(Less(0, 65535))
+	assert.True(LessOrEqual(2, 2))
+	assert.True(LessOrEqual(65000, 100))
+	assert.Equal(uint16(3), Newest(65530, 3))
+	assert.Equal(uint16(65530), Newest(65530, 65000))
+}
+
+func TestUnwrapper(t *testing.T) {
+	assert := assert.New(t)
+
+	var u Unwrapper
+	assert.Equal(int64(65534), u.Peek(65534))
+	assert.Equal(int64(65534), u.Unwrap(65534))
+	assert.Equal(int64(65535), u.Unwrap(65535))
+	assert.Equal(int64(65536), u.Unwrap(0))
+	assert.Equal(int64(1), u.Cycles())
+
+	// reordered packet from before the wrap
+	assert.Equal(int64(65533), u.Unwrap(65533))
+	assert.Equal(int64(0), u.Cycles())
+	assert.Equal(int64(65537), u.Peek(1))
+
+	assert.Equal(int64(65537), u.Unwrap(1))
+	assert.Equal(int64(65536+30000), u.Unwrap(30000))
+	assert.Equal(int64(65536+60000), u.Unwrap(60000))
+	assert.Equal(int64(2*65536+100), u.Unwrap(100))
+	assert.Equal(int64(2), u.Cycles())
+
+	var backwards Unwrapper
+	assert.Equal(int64(0), backwards.Unwrap(0))
+	assert.Equal(int64(-1), backwards.Unwrap(65535))
+	assert.Equal(int64(-1), backwards.Cycles())
+}
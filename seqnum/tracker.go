@@ -0,0 +1,166 @@
+package seqnum
+
+const (
+	// DefaultMaxDropout is the largest forward jump in sequence numbers a
+	// Tracker accepts as packet loss rather than a restarted source.
+	DefaultMaxDropout = 3000
+	// DefaultMaxMisorder is the largest backward jump a Tracker accepts as
+	// reordering.
+	DefaultMaxMisorder = 100
+	// DefaultMinSequential is the number of packets with consecutive
+	// sequence numbers a new source must send before it is considered valid.
+	DefaultMinSequential = 2
+)
+
+// A Tracker follows the sequence numbers of a single RTP source as in RFC
+// 3550, A.1: it counts cycles to extend the highest sequence number received,
+// keeps a new source on probation until MinSequential consecutive packets
+// arrived, and resynchronizes when the source restarts its sequence.
+//
+// The zero value is ready to use with the defaults.
+type Tracker struct {
+	// If zero, DefaultMaxDropout is used.
+	MaxDropout uint16
+	// If zero, DefaultMaxMisorder is used.
+	MaxMisorder uint16
+	// If zero, DefaultMinSequential is used. Set it to 1 to accept a source
+	// from its first packet.
+	MinSequential int
+
+	started   bool
+	maxSeq    uint16
+	cycles    uint32
+	baseSeq   uint32
+	badSeq    uint32
+	probation int
+	received  uint32
+
+	expectedPrior uint32
+	receivedPrior uint32
+}
+
+func (t *Tracker) maxDropout() uint16 {
+	if t.MaxDropout == 0 {
+		return DefaultMaxDropout
+	}
+	return t.MaxDropout
+}
+
+func (t *Tracker) maxMisorder() uint16 {
+	if t.MaxMisorder == 0 {
+		return DefaultMaxMisorder
+	}
+	return t.MaxMisorder
+}
+
+func (t *Tracker) minSequential() int {
+	if t.MinSequential == 0 {
+		return DefaultMinSequential
+	}
+	return t.MinSequential
+}
+
+func (t *Tracker) init(seq uint16) {
+	t.baseSeq = uint32(seq)
+	t.maxSeq = seq
+	// not a valid sequence number, so the first bad packet can't match
+	t.badSeq = fullRange + 1
+	t.cycles = 0
+	t.received = 0
+	t.receivedPrior = 0
+	t.expectedPrior = 0
+}
+
+// Update records a packet with sequence number seq. It returns false if the
+// packet isn't counted: while the source is on probation, and for a large
+// jump that may be a restarted source. When two packets in a row follow such
+// a jump, the tracker resynchronizes to the new sequence.
+func (t *Tracker) Update(seq uint16) bool {
+	if !t.started {
+		t.started = true
+		t.init(seq)
+		t.maxSeq = seq - 1
+		t.probation = t.minSequential()
+	}
+
+	udelta := seq - t.maxSeq
+	switch {
+	case t.probation > 0:
+		// packets must be in sequence to leave probation
+		if seq != t.maxSeq+1 {
+			t.probation = t.minSequential() - 1
+			t.maxSeq = seq
+			return false
+		}
+		t.probation--
+		t.maxSeq = seq
+		if t.probation > 0 {
+			return false
+		}
+		t.init(seq)
+	case udelta < t.maxDropout():
+		// in order, with permissible gap
+		if seq < t.maxSeq {
+			t.cycles += fullRange
+		}
+		t.maxSeq = seq
+	case uint32(udelta) <= fullRange-uint32(t.maxMisorder()):
+		// the sequence number made a very large jump
+		if uint32(seq) != t.badSeq {
+			t.badSeq = uint32(seq+1) & (fullRange - 1)
+			return false
+		}
+		// two sequential packets; assume the other side restarted without
+		// telling us, so just resync
+		t.init(seq)
+	default:
+		// duplicate or reordered packet
+	}
+
+	t.received++
+	return true
+}
+
+// Valid reports whether the source has left probation.
+func (t *Tracker) Valid() bool {
+	return t.started && t.probation == 0
+}
+
+// ExtendedHighest returns the extended highest sequence number received:
+// the cycle count in the high 16 bits and the highest sequence number in the
+// low 16 bits, as carried in a reception report.
+func (t *Tracker) ExtendedHighest() uint32 {
+	return t.cycles + uint32(t.maxSeq)
+}
+
+// Cycles returns the number of times the sequence number wrapped around.
+func (t *Tracker) Cycles() uint32 {
+	return t.cycles / fullRange
+}
+
+// Base returns the first sequence number counted.
+func (t *Tracker) Base() uint32 {
+	return t.baseSeq
+}
+
+// Expected returns the number of packets expected since the base.
+func (t *Tracker) Expected() uint32 {
+	if !t.Valid() {
+		return 0
+	}
+	return t.ExtendedHighest() - t.baseSeq + 1
+}
+
+// Received returns the number of packets counted, including duplicates.
+func (t *Tracker) Received() uint32 {
+	return t.received
+}
+
+// Interval returns the packets expected and received since the previous
+// call, for computing the fraction lost of a reception report.
+func (t *Tracker) Interval() (expected, received uint32) {
+	e, r := t.Expected(), t.received
+	expected, received = e-t.expectedPrior, r-t.receivedPrior
+	t.expectedPrior, t.receivedPrior = e, r
+	return expected, received
+}
@@ -0,0 +1,84 @@
+package seqnum
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackerProbation(t *testing.T) {
+	assert := assert.New(t)
+
+	var tr Tracker
+	assert.False(tr.Update(100))
+	assert.False(tr.Valid())
+	// out of sequence restarts probation
+	assert.False(tr.Update(200))
+	assert.False(tr.Update(300))
+	assert.True(tr.Update(301))
+	assert.True(tr.Valid())
+
+	assert.Equal(uint32(301), tr.Base())
+	assert.Equal(uint32(301), tr.ExtendedHighest())
+	assert.Equal(uint32(1), tr.Expected())
+	assert.Equal(uint32(1), tr.Received())
+
+	immediate := Tracker{MinSequential: 1}
+	assert.True(immediate.Update(7))
+	assert.True(immediate.Valid())
+	assert.Equal(uint32(7), immediate.Base())
+}
+
+func TestTrackerWrapAndLoss(t *testing.T) {
+	assert := assert.New(t)
+
+	tr := Tracker{MinSequential: 1}
+	assert.True(tr.Update(65530))
+	for seq := uint16(65531); seq != 5; seq++ {
+		if seq == 2 {
+			// lost
+			continue
+		}
+		assert.True(tr.Update(seq))
+	}
+	assert.Equal(uint32(1), tr.Cycles())
+	assert.Equal(uint32(65536+4), tr.ExtendedHighest())
+	assert.Equal(uint32(11), tr.Expected())
+	assert.Equal(uint32(10), tr.Received())
+
+	expected, received := tr.Interval()
+	assert.Equal(uint32(11), expected)
+	assert.Equal(uint32(10), received)
+
+	// a reordered packet from before the wrap doesn't move the highest
+	assert.True(tr.Update(65535))
+	assert.Equal(uint32(65536+4), tr.ExtendedHighest())
+	assert.True(tr.Update(5))
+	expected, received = tr.Interval()
+	assert.Equal(uint32(1), expected)
+	assert.Equal(uint32(2), received)
+}
+
+func TestTrackerResync(t *testing.T) {
+	assert := assert.New(t)
+
+	tr := Tracker{MinSequential: 1}
+	assert.True(tr.Update(1000))
+	assert.True(tr.Update(1001))
+
+	// a single large jump is dropped
+	assert.False(tr.Update(40000))
+	assert.Equal(uint32(1001), tr.ExtendedHighest())
+	assert.True(tr.Update(1002))
+
+	// two in a row resynchronize
+	assert.False(tr.Update(20000))
+	assert.True(tr.Update(20001))
+	assert.Equal(uint32(20001), tr.Base())
+	assert.Equal(uint32(20001), tr.ExtendedHighest())
+	assert.Equal(uint32(1), tr.Received())
+
+	// reordering within MaxMisorder is counted, beyond it is a jump
+	assert.True(tr.Update(20001 - DefaultMaxMisorder + 1))
+	assert.False(tr.Update(20001 - DefaultMaxMisorder))
+}
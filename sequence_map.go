@@ -0,0 +1,61 @@
+package rtcp
+
+const (
+	// DefaultSequenceMapSize is the number of sequence numbers a SequenceMap
+	// remembers when created with a size of zero.
+	DefaultSequenceMapSize = 1 << 12
+)
+
+type sequenceMapEntry struct {
+	from, to uint16
+	valid    bool
+}
+
+// A SequenceMap remembers how the sequence numbers of forwarded packets map
+// to the sequence numbers of the stream they came from, e.g. after an SFU
+// rewrote them, or from an RTX stream to its original stream. It holds a
+// fixed number of the most recent mappings, so feedback about packets too
+// old to repair simply isn't translated.
+type SequenceMap struct {
+	entries []sequenceMapEntry
+	mask    uint16
+}
+
+// NewSequenceMap creates a SequenceMap remembering at least size sequence
+// numbers. The size is rounded up to a power of two, at most 65536. A size of
+// zero means DefaultSequenceMapSize.
+func NewSequenceMap(size int) *SequenceMap {
+	if size <= 0 {
+		size = DefaultSequenceMapSize
+	}
+	n := 1
+	for n < size && n < 1<<16 {
+		n <<= 1
+	}
+	return &SequenceMap{
+		entries: make([]sequenceMapEntry, n),
+		mask:    uint16(n - 1),
+	}
+}
+
+// Set records that sequence number from maps to to, replacing the mapping of
+// the sequence number that shared its slot.
+func (m *SequenceMap) Set(from, to uint16) {
+	m.entries[from&m.mask] = sequenceMapEntry{from: from, to: to, valid: true}
+}
+
+// Get returns the sequence number from maps to, if it's still remembered.
+func (m *SequenceMap) Get(from uint16) (uint16, bool) {
+	e := m.entries[from&m.mask]
+	if !e.valid || e.from != from {
+		return 0, false
+	}
+	return e.to, true
+}
+
+// Delete forgets the mapping of from.
+func (m *SequenceMap) Delete(from uint16) {
+	if e := &m.entries[from&m.mask]; e.valid && e.from == from {
+		*e = sequenceMapEntry{}
+	}
+}
@@ -0,0 +1,41 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequenceMap(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewSequenceMap(3)
+	assert.Len(m.entries, 4)
+
+	m.Set(10, 1000)
+	m.Set(65535, 7)
+	to, ok := m.Get(10)
+	assert.True(ok)
+	assert.Equal(uint16(1000), to)
+	to, ok = m.Get(65535)
+	assert.True(ok)
+	assert.Equal(uint16(7), to)
+
+	_, ok = m.Get(11)
+	assert.False(ok)
+
+	// 14 shares the slot of 10 and replaces it
+	m.Set(14, 2000)
+	_, ok = m.Get(10)
+	assert.False(ok)
+
+	m.Delete(10)
+	_, ok = m.Get(14)
+	assert.True(ok)
+	m.Delete(14)
+	_, ok = m.Get(14)
+	assert.False(ok)
+
+	assert.Len(NewSequenceMap(0).entries, DefaultSequenceMapSize)
+	assert.Len(NewSequenceMap(1<<20).entries, 1<<16)
+}
@@ -0,0 +1,240 @@
+package rtcp
+
+import (
+	"net"
+	"time"
+)
+
+// DefaultMaxSessions is the number of sessions a SessionManager tracks when
+// its MaxSessions is zero.
+const DefaultMaxSessions = 1 << 16
+
+// A SessionKey identifies the RTCP state of a remote source: the address it
+// sends from, as returned by net.Addr.String, and its SSRC.
+type SessionKey struct {
+	RemoteAddr string
+	SSRC       uint32
+}
+
+func newSessionKey(addr net.Addr, ssrc uint32) SessionKey {
+	key := SessionKey{SSRC: ssrc}
+	if addr != nil {
+		key.RemoteAddr = addr.String()
+	}
+	return key
+}
+
+// A Session is the RTCP state a SessionManager keeps for one remote source.
+type Session struct {
+	Key SessionKey
+	// Reception statistics of the RTP the source sends, fed by its
+	// SenderReports; RTP is passed in by the application, see
+	// SessionManager.OnRTP
+	Stats *ReceiverStats
+	// Arrivals of its transport wide sequence numbers, for feedback to it
+	Recorder *Recorder
+	// The schedule of the reports sent to it
+	Scheduler *Scheduler
+	// Round trip time to it, from its reports on the local source
+	RTT *RTTEstimator
+	// When a packet from the source was last seen
+	LastSeen time.Time
+}
+
+// A SessionManager creates and expires the RTCP state of many remote peers,
+// as a media server handling thousands of them needs. A Session is created
+// for each remote address and SSRC the first time a packet from it is
+// passed to OnPackets or OnRTP, and the packets update its state:
+// SenderReports its ReceiverStats, and reports on LocalSSRC its
+// RTTEstimator. Sessions are removed when their source sends a Goodbye,
+// when they are silent for longer than Timeout, see Expire, and when
+// MaxSessions is reached, least recently seen first.
+//
+// A SessionManager isn't safe for concurrent use, nor are the Sessions it
+// returns.
+type SessionManager struct {
+	// The SSRC of the local participant, which sends the feedback of the
+	// recorders and whose reports the round trip time is measured from
+	LocalSSRC uint32
+	// The clock rates of the payload types of the RTP sessions, see
+	// ReceiverStats
+	ClockRates map[uint8]uint32
+	// The RTCP bandwidth of each session, see Scheduler
+	Bandwidth float64
+	// How long a session may be silent before it's removed. If zero,
+	// DefaultMemberTimeout is used.
+	Timeout time.Duration
+	// The maximum number of sessions. If zero, DefaultMaxSessions is used.
+	MaxSessions int
+	// The source of the current time for Expire, and of the state of new
+	// sessions. If nil, SystemClock is used.
+	Clock Clock
+	// OnNew is optionally called for each new session, to customize its
+	// state.
+	OnNew func(*Session)
+	// OnRemove is optionally called for each session removed.
+	OnRemove func(*Session)
+
+	sessions map[SessionKey]*Session
+}
+
+// NewSessionManager creates an empty SessionManager for the local
+// participant.
+func NewSessionManager(localSSRC uint32, clockRates map[uint8]uint32, bandwidth float64) *SessionManager {
+	return &SessionManager{
+		LocalSSRC:  localSSRC,
+		ClockRates: clockRates,
+		Bandwidth:  bandwidth,
+		sessions:   map[SessionKey]*Session{},
+	}
+}
+
+// OnPackets updates the sessions of the sources that sent packets, creating
+// them as needed, and returns the sessions updated, in order of their first
+// packet. Packets without a sender SSRC, such as SourceDescriptions, are
+// skipped.
+func (m *SessionManager) OnPackets(packets []ReceivedPacket) []*Session {
+	var out []*Session
+	for _, rp := range packets {
+		out = m.onPacket(out, rp, rp.Packet)
+	}
+	return out
+}
+
+func (m *SessionManager) onPacket(out []*Session, rp ReceivedPacket, p Packet) []*Session {
+	switch p := p.(type) {
+	case *CompoundPacket:
+		for _, sub := range *p {
+			out = m.onPacket(out, rp, sub)
+		}
+		return out
+	case *Goodbye:
+		for _, ssrc := range p.Sources {
+			m.Remove(rp.RemoteAddr, ssrc)
+		}
+		return out
+	}
+
+	ssrc, ok := packetSenderSSRC(p)
+	if !ok {
+		return out
+	}
+	s := m.session(newSessionKey(rp.RemoteAddr, ssrc), rp.ArrivalTime)
+	if sr, ok := p.(*SenderReport); ok {
+		s.Stats.OnSenderReport(sr, rp.ArrivalTime)
+	}
+	rp.Packet = p
+	s.RTT.OnReceivedPacket(rp, m.LocalSSRC)
+
+	for _, seen := range out {
+		if seen == s {
+			return out
+		}
+	}
+	return append(out, s)
+}
+
+// OnRTP records an RTP packet of the source ssrc received from addr in the
+// ReceiverStats of its session, creating it as needed, and returns the
+// session.
+func (m *SessionManager) OnRTP(addr net.Addr, ssrc uint32, payloadType uint8, sequenceNumber uint16, rtpTimestamp uint32, arrival time.Time) *Session {
+	s := m.session(newSessionKey(addr, ssrc), arrival)
+	s.Stats.Update(ssrc, payloadType, sequenceNumber, rtpTimestamp, arrival)
+	return s
+}
+
+// session returns the session for key, creating it if needed, seen at now
+func (m *SessionManager) session(key SessionKey, now time.Time) *Session {
+	if m.sessions == nil {
+		m.sessions = map[SessionKey]*Session{}
+	}
+	s, ok := m.sessions[key]
+	if !ok {
+		m.makeRoom()
+		recorder := NewRecorder(m.LocalSSRC)
+		recorder.MediaSSRC = key.SSRC
+		recorder.Clock = m.Clock
+		stats := NewReceiverStats(m.ClockRates)
+		stats.Clock = m.Clock
+		s = &Session{
+			Key:       key,
+			Stats:     stats,
+			Recorder:  recorder,
+			Scheduler: &Scheduler{Bandwidth: m.Bandwidth},
+			RTT:       NewRTTEstimator(),
+		}
+		m.sessions[key] = s
+		if m.OnNew != nil {
+			m.OnNew(s)
+		}
+	}
+	if now.After(s.LastSeen) {
+		s.LastSeen = now
+	}
+	return s
+}
+
+// makeRoom removes the least recently seen sessions until another fits
+func (m *SessionManager) makeRoom() {
+	maxSessions := m.MaxSessions
+	if maxSessions <= 0 {
+		maxSessions = DefaultMaxSessions
+	}
+	for len(m.sessions) >= maxSessions {
+		var oldest *Session
+		for _, s := range m.sessions {
+			if oldest == nil || s.LastSeen.Before(oldest.LastSeen) {
+				oldest = s
+			}
+		}
+		m.remove(oldest)
+	}
+}
+
+func (m *SessionManager) remove(s *Session) {
+	delete(m.sessions, s.Key)
+	if m.OnRemove != nil {
+		m.OnRemove(s)
+	}
+}
+
+// Session returns the session of the source ssrc sending from addr, if it
+// exists.
+func (m *SessionManager) Session(addr net.Addr, ssrc uint32) (*Session, bool) {
+	s, ok := m.sessions[newSessionKey(addr, ssrc)]
+	return s, ok
+}
+
+// Remove removes the session of the source ssrc sending from addr, if it
+// exists.
+func (m *SessionManager) Remove(addr net.Addr, ssrc uint32) {
+	if s, ok := m.sessions[newSessionKey(addr, ssrc)]; ok {
+		m.remove(s)
+	}
+}
+
+// Expire removes the sessions that have been silent for longer than Timeout
+// and returns them.
+func (m *SessionManager) Expire() []*Session {
+	timeout := m.Timeout
+	if timeout == 0 {
+		timeout = DefaultMemberTimeout
+	}
+	cutoff := clockNow(m.Clock).Add(-timeout)
+
+	var out []*Session
+	for _, s := range m.sessions {
+		if s.LastSeen.Before(cutoff) {
+			out = append(out, s)
+		}
+	}
+	for _, s := range out {
+		m.remove(s)
+	}
+	return out
+}
+
+// Len returns the number of sessions.
+func (m *SessionManager) Len() int {
+	return len(m.sessions)
+}
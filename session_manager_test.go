@@ -0,0 +1,81 @@
+package rtcp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionManager(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1000, 0)
+	m := NewSessionManager(1, map[uint8]uint32{96: 90000}, 1000)
+	m.Clock = ClockFunc(func() time.Time { return now })
+	var created, removed []SessionKey
+	m.OnNew = func(s *Session) { created = append(created, s.Key) }
+	m.OnRemove = func(s *Session) { removed = append(removed, s.Key) }
+
+	a := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 5000}
+	b := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 2), Port: 5000}
+
+	sr := &SenderReport{SSRC: 10, NTPTime: 0x1234567800000000}
+	sessions := m.OnPackets(NewReceivedPackets([]Packet{
+		&CompoundPacket{sr, NewCNAMESourceDescription(10, "a")},
+		&PictureLossIndication{SenderSSRC: 10, MediaSSRC: 1},
+	}, now, a))
+	assert.Len(sessions, 1)
+	assert.Equal(SessionKey{RemoteAddr: "192.0.2.1:5000", SSRC: 10}, sessions[0].Key)
+	assert.Equal(uint32(10), sessions[0].Recorder.MediaSSRC)
+
+	// the same SSRC from another address is another peer
+	s := m.OnRTP(b, 10, 96, 1, 0, now)
+	assert.NotEqual(sessions[0], s)
+	assert.Equal(2, m.Len())
+	for seq := uint16(1); seq <= 3; seq++ {
+		m.OnRTP(a, 10, 96, seq, 0, now)
+	}
+	reports := sessions[0].Stats.ReceptionReports()
+	assert.Len(reports, 1)
+	assert.Equal(uint32(0x56780000), reports[0].LastSenderReport)
+
+	// reports on the local source measure the round trip time
+	sent := now
+	e := sessions[0].RTT
+	local := &SenderReport{SSRC: 1, NTPTime: ntpTime(sent)}
+	e.SentSenderReport(local, sent)
+	now = now.Add(100 * time.Millisecond)
+	m.OnPackets(NewReceivedPackets([]Packet{&ReceiverReport{SSRC: 10, Reports: []ReceptionReport{
+		{SSRC: 1, LastSenderReport: uint32(local.NTPTime >> 16)},
+	}}}, now, a))
+	assert.Equal(100*time.Millisecond, e.SRTT())
+
+	// b leaves, a goes silent
+	m.OnPackets(NewReceivedPackets([]Packet{&Goodbye{Sources: []uint32{10}}}, now, b))
+	_, ok := m.Session(b, 10)
+	assert.False(ok)
+	now = now.Add(DefaultMemberTimeout + time.Second)
+	expired := m.Expire()
+	assert.Len(expired, 1)
+	assert.Equal(0, m.Len())
+	assert.Len(created, 2)
+	assert.Equal([]SessionKey{{"192.0.2.2:5000", 10}, {"192.0.2.1:5000", 10}}, removed)
+}
+
+func TestSessionManagerMaxSessions(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	m := NewSessionManager(1, nil, 1000)
+	m.MaxSessions = 2
+	for i := 0; i < 3; i++ {
+		m.OnRTP(nil, uint32(10+i), 96, 1, 0, start.Add(time.Duration(i)*time.Second))
+	}
+	assert.Equal(2, m.Len())
+	_, ok := m.Session(nil, 10)
+	assert.False(ok)
+	_, ok = m.Session(nil, 12)
+	assert.True(ok)
+}
@@ -0,0 +1,53 @@
+package sim
+
+import (
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+// An Estimator estimates the available bandwidth from TWCC feedback.
+type Estimator interface {
+	// OnFeedback processes feedback reaching the sender at now, an offset
+	// from the start of the log, and returns the new estimate in bits per
+	// second.
+	OnFeedback(now time.Duration, fb *rtcp.TransportLayerCC) uint64
+}
+
+// LossBasedEstimator is the loss based controller of Google Congestion
+// Control (draft-ietf-rmcat-gcc-02, 6): the estimate grows by 5% while loss
+// is below 2%, and shrinks in proportion to loss above 10%.
+type LossBasedEstimator struct {
+	// The current estimate in bits per second
+	Bitrate uint64
+	// Bounds of the estimate in bits per second. A zero Max is unbounded.
+	Min uint64
+	Max uint64
+}
+
+var _ Estimator = (*LossBasedEstimator)(nil) // assert is an Estimator
+
+// OnFeedback implements Estimator.
+func (e *LossBasedEstimator) OnFeedback(now time.Duration, fb *rtcp.TransportLayerCC) uint64 {
+	lost := len(fb.Lost())
+	total := lost + len(fb.ArrivalTimes())
+	if total == 0 {
+		return e.Bitrate
+	}
+
+	loss := float64(lost) / float64(total)
+	switch {
+	case loss < 0.02:
+		e.Bitrate = uint64(float64(e.Bitrate) * 1.05)
+	case loss > 0.1:
+		e.Bitrate = uint64(float64(e.Bitrate) * (1 - 0.5*loss))
+	}
+
+	if e.Bitrate < e.Min {
+		e.Bitrate = e.Min
+	}
+	if e.Max != 0 && e.Bitrate > e.Max {
+		e.Bitrate = e.Max
+	}
+	return e.Bitrate
+}
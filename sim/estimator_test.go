@@ -0,0 +1,39 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+func TestLossBasedEstimator(t *testing.T) {
+	// feedback for 10 packets, of which seq 1 through lost are missing
+	feedback := func(lost int) *rtcp.TransportLayerCC {
+		r := rtcp.NewRecorder(1)
+		for i := 0; i < 10; i++ {
+			if i < 1 || i > lost {
+				r.Record(uint16(i), time.Unix(0, 0).Add(time.Duration(i)*time.Millisecond))
+			}
+		}
+		return r.BuildFeedback()[0]
+	}
+
+	for _, test := range []struct {
+		Name string
+		Lost int
+		Est  LossBasedEstimator
+		Want uint64
+	}{
+		{Name: "no loss", Lost: 0, Est: LossBasedEstimator{Bitrate: 1000}, Want: 1050},
+		{Name: "capped", Lost: 0, Est: LossBasedEstimator{Bitrate: 1000, Max: 1010}, Want: 1010},
+		{Name: "moderate loss", Lost: 1, Est: LossBasedEstimator{Bitrate: 1000}, Want: 1000},
+		{Name: "high loss", Lost: 2, Est: LossBasedEstimator{Bitrate: 1000}, Want: 900},
+		{Name: "floor", Lost: 8, Est: LossBasedEstimator{Bitrate: 1000, Min: 800}, Want: 800},
+	} {
+		got := test.Est.OnFeedback(0, feedback(test.Lost))
+		if got != test.Want {
+			t.Fatalf("%s: OnFeedback = %d, want %d", test.Name, got, test.Want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package sim
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+var errBadRecord = errors.New("sim: send log record must have seq, send time and size")
+
+// A SendRecord describes one RTP packet of a send log.
+type SendRecord struct {
+	// The transport wide sequence number
+	Seq uint16
+	// Time since the start of the log the packet was sent
+	SendTime time.Duration
+	// Size of the packet in bytes
+	Size int
+}
+
+// ReadSendLog reads a send log in CSV format. Every record holds the
+// transport wide sequence number, the send time in microseconds since the
+// start of the log and the packet size in bytes. Lines starting with # are
+// ignored.
+func ReadSendLog(r io.Reader) ([]SendRecord, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var out []SendRecord
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) != 3 {
+			return nil, errBadRecord
+		}
+
+		seq, err := strconv.ParseUint(fields[0], 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		us, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, SendRecord{
+			Seq:      uint16(seq),
+			SendTime: time.Duration(us) * time.Microsecond,
+			Size:     size,
+		})
+	}
+}
+
+// ConstantBitrate generates a send log of count packets of size bytes,
+// paced to bitrate bits per second, starting at sequence number zero.
+func ConstantBitrate(bitrate uint64, size, count int) []SendRecord {
+	out := make([]SendRecord, count)
+	gap := time.Duration(uint64(size) * 8 * uint64(time.Second) / bitrate)
+	for i := range out {
+		out[i] = SendRecord{
+			Seq:      uint16(i),
+			SendTime: time.Duration(i) * gap,
+			Size:     size,
+		}
+	}
+	return out
+}
@@ -0,0 +1,37 @@
+package sim
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadSendLog(t *testing.T) {
+	log, err := ReadSendLog(strings.NewReader("# seq,send_us,size\n65535,0,1200\n0, 10000, 800\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []SendRecord{
+		{Seq: 65535, SendTime: 0, Size: 1200},
+		{Seq: 0, SendTime: 10 * time.Millisecond, Size: 800},
+	}, log)
+
+	for _, data := range []string{
+		"1,2\n",
+		"65536,0,1\n",
+		"1,x,1\n",
+		"1,0,x\n",
+	} {
+		if _, err := ReadSendLog(strings.NewReader(data)); err == nil {
+			t.Fatalf("ReadSendLog(%q) succeeded, want error", data)
+		}
+	}
+}
+
+func TestConstantBitrate(t *testing.T) {
+	assert.Equal(t, []SendRecord{
+		{Seq: 0, SendTime: 0, Size: 1000},
+		{Seq: 1, SendTime: 8 * time.Millisecond, Size: 1000},
+		{Seq: 2, SendTime: 16 * time.Millisecond, Size: 1000},
+	}, ConstantBitrate(1000000, 1000, 3))
+}
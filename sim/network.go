@@ -0,0 +1,69 @@
+package sim
+
+import (
+	"math/rand"
+	"time"
+)
+
+// A Network decides whether and when a packet arrives at the receiver.
+type Network interface {
+	// Transmit returns the arrival time of p, as an offset from the start
+	// of the log, or false if p is lost.
+	Transmit(p SendRecord) (arrival time.Duration, ok bool)
+}
+
+// A Link is a Network with a fixed base delay, uniform delay jitter, random
+// loss and loss bursts. Bursts follow a Gilbert-Elliott model: every packet
+// sent while in a burst is lost.
+type Link struct {
+	// The base one-way delay
+	Delay time.Duration
+	// Delay varies uniformly in [-Jitter, Jitter] around Delay, but a
+	// packet never arrives before it was sent
+	Jitter time.Duration
+	// Probability of losing a packet outside of bursts
+	Loss float64
+	// Probability of entering a burst at each packet, and of leaving it
+	BurstStart float64
+	BurstEnd   float64
+
+	rand    *rand.Rand
+	inBurst bool
+}
+
+var _ Network = (*Link)(nil) // assert is a Network
+
+// NewLink creates a lossless Link with the given delay, whose random
+// decisions are seeded by seed.
+func NewLink(delay time.Duration, seed int64) *Link {
+	return &Link{
+		Delay: delay,
+		rand:  rand.New(rand.NewSource(seed)), // nolint:gosec
+	}
+}
+
+// Transmit implements Network.
+func (l *Link) Transmit(p SendRecord) (time.Duration, bool) {
+	if l.rand == nil {
+		l.rand = rand.New(rand.NewSource(0)) // nolint:gosec
+	}
+
+	// draw every random number for every packet, so changing one
+	// parameter doesn't shift the decisions of the others
+	burst, loss, jitter := l.rand.Float64(), l.rand.Float64(), l.rand.Float64()
+
+	if l.inBurst {
+		l.inBurst = burst >= l.BurstEnd
+	} else {
+		l.inBurst = burst < l.BurstStart
+	}
+	if l.inBurst || loss < l.Loss {
+		return 0, false
+	}
+
+	delay := l.Delay + time.Duration((2*jitter-1)*float64(l.Jitter))
+	if delay < 0 {
+		delay = 0
+	}
+	return p.SendTime + delay, true
+}
@@ -0,0 +1,48 @@
+package sim
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLink(t *testing.T) {
+	link := NewLink(50*time.Millisecond, 7)
+	link.Jitter = 10 * time.Millisecond
+
+	for i := 0; i < 1000; i++ {
+		p := SendRecord{Seq: uint16(i), SendTime: time.Duration(i) * time.Millisecond}
+		at, ok := link.Transmit(p)
+		if !ok {
+			t.Fatalf("packet %d lost on a lossless link", i)
+		}
+		if delay := at - p.SendTime; delay < 40*time.Millisecond || delay > 60*time.Millisecond {
+			t.Fatalf("packet %d delay %v, want within 50ms +- 10ms", i, delay)
+		}
+	}
+
+	link = NewLink(0, 7)
+	link.BurstStart = 0.05
+	link.BurstEnd = 0.25
+
+	var lost, bursts int
+	wasLost := false
+	for i := 0; i < 10000; i++ {
+		_, ok := link.Transmit(SendRecord{Seq: uint16(i)})
+		if !ok {
+			lost++
+			if !wasLost {
+				bursts++
+			}
+		}
+		wasLost = !ok
+	}
+
+	// the stationary loss rate is 0.05 / (0.05 + 0.25) and bursts last
+	// 1 / 0.25 packets on average
+	if rate := float64(lost) / 10000; rate < 0.13 || rate > 0.2 {
+		t.Fatalf("burst loss rate %v, want about 0.167", rate)
+	}
+	if mean := float64(lost) / float64(bursts); mean < 3 || mean > 5 {
+		t.Fatalf("mean burst length %v, want about 4", mean)
+	}
+}
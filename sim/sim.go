@@ -0,0 +1,100 @@
+// Package sim replays recorded RTP send logs through the TWCC Recorder and a
+// bandwidth estimator over a simulated network, so congestion control
+// changes can be evaluated offline.
+//
+// Every source of randomness is seeded by the caller, so a run with the same
+// log, network and estimator always produces the same result.
+package sim
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+// DefaultFeedbackInterval is how often feedback is built if
+// Config.FeedbackInterval is zero
+const DefaultFeedbackInterval = 100 * time.Millisecond
+
+// Config configures a simulation run.
+type Config struct {
+	// How often the receiver builds TransportLayerCC feedback. If zero,
+	// DefaultFeedbackInterval is used.
+	FeedbackInterval time.Duration
+	// The one-way delay of feedback back to the sender
+	FeedbackDelay time.Duration
+	// SSRCs written into the feedback
+	SenderSSRC uint32
+	MediaSSRC  uint32
+}
+
+// A Sample is the estimate of the estimator after it processed feedback.
+type Sample struct {
+	// Time since the start of the log the feedback reached the sender
+	At time.Duration
+	// The estimated available bandwidth in bits per second
+	Bitrate uint64
+}
+
+// Result holds the outcome of a simulation run.
+type Result struct {
+	// Packets in the log, and packets the network delivered
+	Sent      int
+	Delivered int
+	// Feedback packets built by the receiver, in order
+	Feedback []*rtcp.TransportLayerCC
+	// Estimates after each feedback packet
+	Samples []Sample
+}
+
+// arrival is a packet delivered by the network
+type arrival struct {
+	seq uint16
+	at  time.Duration
+}
+
+// Run replays log through network and feeds the resulting feedback to
+// estimator. The log must be ordered by send time.
+func Run(log []SendRecord, network Network, estimator Estimator, config Config) Result {
+	interval := config.FeedbackInterval
+	if interval == 0 {
+		interval = DefaultFeedbackInterval
+	}
+
+	result := Result{Sent: len(log)}
+	arrivals := make([]arrival, 0, len(log))
+	for _, p := range log {
+		if at, ok := network.Transmit(p); ok {
+			arrivals = append(arrivals, arrival{seq: p.Seq, at: at})
+		}
+	}
+	result.Delivered = len(arrivals)
+	if len(arrivals) == 0 {
+		return result
+	}
+	sort.SliceStable(arrivals, func(i, j int) bool { return arrivals[i].at < arrivals[j].at })
+
+	// all times are offsets from the start of the log
+	start := time.Unix(0, 0)
+	recorder := rtcp.NewRecorder(config.SenderSSRC)
+	recorder.MediaSSRC = config.MediaSSRC
+
+	next := 0
+	for t := interval; next < len(arrivals); t += interval {
+		for ; next < len(arrivals) && arrivals[next].at <= t; next++ {
+			recorder.Record(arrivals[next].seq, start.Add(arrivals[next].at))
+		}
+
+		for _, fb := range recorder.BuildFeedback() {
+			at := t + config.FeedbackDelay
+			result.Feedback = append(result.Feedback, fb)
+			result.Samples = append(result.Samples, Sample{
+				At:      at,
+				Bitrate: estimator.OnFeedback(at, fb),
+			})
+		}
+	}
+
+	return result
+}
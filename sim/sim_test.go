@@ -0,0 +1,66 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunLossless(t *testing.T) {
+	assert := assert.New(t)
+
+	// 1 Mbit/s in 1250 byte packets is one packet every 10ms
+	log := ConstantBitrate(1000000, 1250, 100)
+	estimator := &LossBasedEstimator{Bitrate: 500000}
+	result := Run(log, NewLink(20*time.Millisecond, 1), estimator, Config{
+		FeedbackDelay: 20 * time.Millisecond,
+		SenderSSRC:    1,
+		MediaSSRC:     2,
+	})
+
+	assert.Equal(100, result.Sent)
+	assert.Equal(100, result.Delivered)
+	assert.Len(result.Feedback, 11)
+	assert.Len(result.Samples, 11)
+
+	received := 0
+	for _, fb := range result.Feedback {
+		assert.Equal(uint32(1), fb.SenderSSRC)
+		assert.Equal(uint32(2), fb.MediaSSRC)
+		assert.Empty(fb.Lost())
+		received += len(fb.ArrivalTimes())
+	}
+	assert.Equal(100, received)
+
+	assert.Equal(120*time.Millisecond, result.Samples[0].At)
+	assert.Equal(uint64(525000), result.Samples[0].Bitrate)
+	for i := 1; i < len(result.Samples); i++ {
+		assert.True(result.Samples[i].Bitrate > result.Samples[i-1].Bitrate)
+	}
+}
+
+func TestRunReproducible(t *testing.T) {
+	log := ConstantBitrate(1000000, 1250, 500)
+	run := func() Result {
+		link := NewLink(30*time.Millisecond, 42)
+		link.Jitter = 10 * time.Millisecond
+		link.Loss = 0.05
+		link.BurstStart = 0.01
+		link.BurstEnd = 0.3
+		return Run(log, link, &LossBasedEstimator{Bitrate: 1000000, Min: 100000}, Config{})
+	}
+
+	a, b := run(), run()
+	assert.Equal(t, a, b)
+	assert.True(t, a.Delivered < a.Sent)
+	assert.True(t, a.Samples[len(a.Samples)-1].Bitrate < 1000000)
+}
+
+func TestRunNothingDelivered(t *testing.T) {
+	link := NewLink(0, 1)
+	link.Loss = 1
+
+	result := Run(ConstantBitrate(1000000, 1250, 10), link, &LossBasedEstimator{}, Config{})
+	assert.Equal(t, Result{Sent: 10}, result)
+}
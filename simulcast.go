@@ -0,0 +1,119 @@
+package rtcp
+
+// A SimulcastRouter routes feedback from the receivers of a selective
+// forwarding unit back to the simulcast layers it came from. A forwarder
+// sends each receiver one layer of a simulcast source at a time, rewritten
+// to a single outbound SSRC, so the receiver's feedback names the outbound
+// SSRC; the router rewrites it to the original SSRC of the layer currently
+// forwarded, so a keyframe request or NACK reaches the right encoder.
+//
+// The media SSRCs of TransportLayerNack, PictureLossIndication,
+// FullIntraRequest and TransportLayerCC feedback are rewritten. Other
+// packets, and feedback for SSRCs that aren't mapped, are returned
+// unchanged. Sequence numbers aren't translated.
+//
+// A SimulcastRouter isn't safe for concurrent use.
+type SimulcastRouter struct {
+	// Layers maps outbound SSRCs to the original SSRC of the layer currently
+	// forwarded on each. It may be updated between calls to Route when the
+	// forwarder switches layers.
+	Layers map[uint32]uint32
+}
+
+// NewSimulcastRouter creates a SimulcastRouter with an empty mapping.
+func NewSimulcastRouter() *SimulcastRouter {
+	return &SimulcastRouter{Layers: make(map[uint32]uint32)}
+}
+
+// SetLayer records that the layer with SSRC original is now forwarded on
+// the outbound SSRC outbound.
+func (r *SimulcastRouter) SetLayer(outbound, original uint32) {
+	if r.Layers == nil {
+		r.Layers = make(map[uint32]uint32)
+	}
+	r.Layers[outbound] = original
+}
+
+// RemoveLayer forgets the mapping of the outbound SSRC outbound.
+func (r *SimulcastRouter) RemoveLayer(outbound uint32) {
+	delete(r.Layers, outbound)
+}
+
+// RouteReceived routes the packet of rp like Route, keeping its arrival
+// context.
+func (r *SimulcastRouter) RouteReceived(rp ReceivedPacket) (ReceivedPacket, bool) {
+	routed, ok := r.Route(rp.Packet)
+	rp.Packet = routed
+	return rp, ok
+}
+
+// Route returns p with its media SSRC rewritten to the original SSRC of the
+// layer, and whether it was rewritten. p itself isn't modified; the rewritten
+// packet is a copy that may share the slices of p that didn't change.
+func (r *SimulcastRouter) Route(p Packet) (Packet, bool) {
+	switch p := p.(type) {
+	case *TransportLayerNack:
+		if original, ok := r.Layers[p.MediaSSRC]; ok {
+			out := *p
+			out.MediaSSRC = original
+			return &out, true
+		}
+	case *PictureLossIndication:
+		if original, ok := r.Layers[p.MediaSSRC]; ok {
+			out := *p
+			out.MediaSSRC = original
+			return &out, true
+		}
+	case *TransportLayerCC:
+		if original, ok := r.Layers[p.MediaSSRC]; ok {
+			out := *p
+			out.MediaSSRC = original
+			return &out, true
+		}
+	case *FullIntraRequest:
+		// the media SSRC field of a FIR is unused; each entry names its
+		// source
+		var entries []FIREntry
+		for i, e := range p.FIR {
+			original, ok := r.Layers[e.SSRC]
+			if !ok {
+				continue
+			}
+			if entries == nil {
+				entries = append([]FIREntry(nil), p.FIR...)
+			}
+			entries[i].SSRC = original
+		}
+		if entries != nil {
+			out := *p
+			out.FIR = entries
+			return &out, true
+		}
+	case *CompoundPacket:
+		var out CompoundPacket
+		for i, sub := range *p {
+			routed, ok := r.Route(sub)
+			if !ok {
+				continue
+			}
+			if out == nil {
+				out = append(CompoundPacket(nil), *p...)
+			}
+			out[i] = routed
+		}
+		if out != nil {
+			return &out, true
+		}
+	}
+	return p, false
+}
+
+// RouteAll routes each of packets, see Route. The result doesn't share the
+// storage of packets.
+func (r *SimulcastRouter) RouteAll(packets []Packet) []Packet {
+	out := make([]Packet, len(packets))
+	for i, p := range packets {
+		out[i], _ = r.Route(p)
+	}
+	return out
+}
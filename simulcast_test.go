@@ -0,0 +1,56 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulcastRouter(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewSimulcastRouter()
+	r.SetLayer(1000, 11)
+
+	nack := &TransportLayerNack{SenderSSRC: 5, MediaSSRC: 1000, Nacks: []NackPair{{PacketID: 7}}}
+	routed, ok := r.Route(nack)
+	assert.True(ok)
+	assert.Equal(&TransportLayerNack{SenderSSRC: 5, MediaSSRC: 11, Nacks: []NackPair{{PacketID: 7}}}, routed)
+	// the original isn't modified
+	assert.Equal(uint32(1000), nack.MediaSSRC)
+
+	routed, ok = r.Route(&PictureLossIndication{SenderSSRC: 5, MediaSSRC: 1000})
+	assert.True(ok)
+	assert.Equal(&PictureLossIndication{SenderSSRC: 5, MediaSSRC: 11}, routed)
+
+	routed, ok = r.Route(&TransportLayerCC{SenderSSRC: 5, MediaSSRC: 1000, FbPktCount: 3})
+	assert.True(ok)
+	assert.Equal(uint32(11), routed.(*TransportLayerCC).MediaSSRC)
+	assert.Equal(uint8(3), routed.(*TransportLayerCC).FbPktCount)
+
+	// the forwarder switched to another layer
+	r.SetLayer(1000, 12)
+	fir := &FullIntraRequest{SenderSSRC: 5, FIR: []FIREntry{{SSRC: 2000, SequenceNumber: 1}, {SSRC: 1000, SequenceNumber: 2}}}
+	routed, ok = r.Route(fir)
+	assert.True(ok)
+	assert.Equal(&FullIntraRequest{SenderSSRC: 5, FIR: []FIREntry{{SSRC: 2000, SequenceNumber: 1}, {SSRC: 12, SequenceNumber: 2}}}, routed)
+	assert.Equal(uint32(1000), fir.FIR[1].SSRC)
+
+	// unmapped SSRCs and other packets are returned unchanged
+	pli := &PictureLossIndication{MediaSSRC: 2000}
+	routed, ok = r.Route(pli)
+	assert.False(ok)
+	assert.Same(pli, routed)
+	rr := &ReceiverReport{SSRC: 5}
+	routed, ok = r.Route(rr)
+	assert.False(ok)
+	assert.Same(rr, routed)
+
+	compound := &CompoundPacket{rr, &PictureLossIndication{MediaSSRC: 1000}}
+	routed, ok = r.Route(compound)
+	assert.True(ok)
+	assert.Equal(&CompoundPacket{rr, &PictureLossIndication{MediaSSRC: 12}}, routed)
+
+	r.RemoveLayer(1000)
+	assert.Equal([]Packet{nack, pli}, r.RouteAll([]Packet{nack, pli}))
+}
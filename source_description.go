@@ -18,7 +18,7 @@ const (
 	SDESLocation                 // geographic user location        RFC 3550, 6.5.5
 	SDESTool                     // name of application or tool     RFC 3550, 6.5.6
 	SDESNote                     // notice about the source         RFC 3550, 6.5.7
-	SDESPrivate                  // private extensions              RFC 3550, 6.5.8  (not implemented)
+	SDESPrivate                  // private extensions              RFC 3550, 6.5.8
 )
 
 func (s SDESType) String() string {
@@ -54,6 +54,7 @@ const (
 	sdesOctetCountOffset = 1
 	sdesMaxOctetCount    = (1 << 8) - 1
 	sdesTextOffset       = 2
+	sdesPrefixLengthLen  = 1
 )
 
 // A SourceDescription (SDES) packet describes the sources in an RTP stream.
@@ -83,21 +84,20 @@ func (s SourceDescription) Marshal() ([]byte, error) {
 	 *        +=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+
 	 */
 
+	if len(s.Chunks) > countMax {
+		return nil, errTooManyChunks
+	}
+
 	rawPacket := make([]byte, s.len())
 	packetBody := rawPacket[headerLength:]
 
 	chunkOffset := 0
 	for _, c := range s.Chunks {
-		data, err := c.Marshal()
+		n, err := c.marshalTo(packetBody[chunkOffset:])
 		if err != nil {
 			return nil, err
 		}
-		copy(packetBody[chunkOffset:], data)
-		chunkOffset += len(data)
-	}
-
-	if len(s.Chunks) > countMax {
-		return nil, errTooManyChunks
+		chunkOffset += n
 	}
 
 	hData, err := s.Header().Marshal()
@@ -138,6 +138,11 @@ func (s *SourceDescription) Unmarshal(rawPacket []byte) error {
 		return errWrongType
 	}
 
+	if h.size() > len(rawPacket) {
+		return errPacketTooShort
+	}
+	rawPacket = rawPacket[:h.size()]
+
 	for i := headerLength; i < len(rawPacket); {
 		var chunk SourceDescriptionChunk
 		if err := chunk.Unmarshal(rawPacket[i:]); err != nil {
@@ -155,6 +160,11 @@ func (s *SourceDescription) Unmarshal(rawPacket []byte) error {
 	return nil
 }
 
+// MarshalSize returns the size of the SourceDescription once marshaled.
+func (s SourceDescription) MarshalSize() int {
+	return s.len()
+}
+
 func (s *SourceDescription) len() int {
 	chunksLength := 0
 	for _, c := range s.Chunks {
@@ -190,24 +200,35 @@ func (s SourceDescriptionChunk) Marshal() ([]byte, error) {
 	 *  +=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+=+
 	 */
 
-	rawPacket := make([]byte, sdesSourceLen)
-	binary.BigEndian.PutUint32(rawPacket, s.Source)
+	rawPacket := make([]byte, s.len())
+	if _, err := s.marshalTo(rawPacket); err != nil {
+		return nil, err
+	}
+	return rawPacket, nil
+}
+
+// marshalTo encodes the chunk into b, which must have room for len() bytes,
+// and returns the number of bytes written
+func (s SourceDescriptionChunk) marshalTo(b []byte) (int, error) {
+	binary.BigEndian.PutUint32(b, s.Source)
 
+	n := sdesSourceLen
 	for _, it := range s.Items {
-		data, err := it.Marshal()
+		itemLen, err := it.marshalTo(b[n:])
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
-		rawPacket = append(rawPacket, data...)
+		n += itemLen
 	}
 
 	// The list of items in each chunk MUST be terminated by one or more null octets
-	rawPacket = append(rawPacket, uint8(SDESEnd))
-
 	// additional null octets MUST be included if needed to pad until the next 32-bit boundary
-	rawPacket = append(rawPacket, make([]byte, getPadding(len(rawPacket)))...)
+	end := n + sdesTypeLen + getPadding(n+sdesTypeLen)
+	for ; n < end; n++ {
+		b[n] = uint8(SDESEnd)
+	}
 
-	return rawPacket, nil
+	return n, nil
 }
 
 // Unmarshal decodes the SourceDescriptionChunk from binary
@@ -243,6 +264,12 @@ func (s *SourceDescriptionChunk) Unmarshal(rawPacket []byte) error {
 	return errPacketTooShort
 }
 
+// MarshalSize returns the size of the SourceDescriptionChunk once marshaled,
+// including its null termination and padding.
+func (s SourceDescriptionChunk) MarshalSize() int {
+	return s.len()
+}
+
 func (s SourceDescriptionChunk) len() int {
 	len := sdesSourceLen
 	for _, it := range s.Items {
@@ -274,7 +301,7 @@ func (s SourceDescriptionItem) len() int {
 	 *  |    CNAME=1    |     length    | user and domain name        ...
 	 *  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
 	 */
-	return sdesTypeLen + sdesOctetCountLen + len([]byte(s.Text))
+	return sdesTypeLen + sdesOctetCountLen + len(s.Text)
 }
 
 // Marshal encodes the SourceDescriptionItem in binary
@@ -287,24 +314,30 @@ func (s SourceDescriptionItem) Marshal() ([]byte, error) {
 	 *  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
 	 */
 
-	if s.Type == SDESEnd {
-		return nil, errSDESMissingType
+	rawPacket := make([]byte, s.len())
+	if _, err := s.marshalTo(rawPacket); err != nil {
+		return nil, err
 	}
+	return rawPacket, nil
+}
 
-	rawPacket := make([]byte, sdesTypeLen+sdesOctetCountLen)
-
-	rawPacket[sdesTypeOffset] = uint8(s.Type)
+// marshalTo encodes the item into b, which must have room for len() bytes,
+// and returns the number of bytes written
+func (s SourceDescriptionItem) marshalTo(b []byte) (int, error) {
+	if s.Type == SDESEnd {
+		return 0, errSDESMissingType
+	}
 
-	txtBytes := []byte(s.Text)
-	octetCount := len(txtBytes)
+	octetCount := len(s.Text)
 	if octetCount > sdesMaxOctetCount {
-		return nil, errSDESTextTooLong
+		return 0, errSDESTextTooLong
 	}
-	rawPacket[sdesOctetCountOffset] = uint8(octetCount)
 
-	rawPacket = append(rawPacket, txtBytes...)
+	b[sdesTypeOffset] = uint8(s.Type)
+	b[sdesOctetCountOffset] = uint8(octetCount)
+	copy(b[sdesTextOffset:], s.Text)
 
-	return rawPacket, nil
+	return sdesTextOffset + octetCount, nil
 }
 
 // Unmarshal decodes the SourceDescriptionItem from binary
@@ -334,6 +367,52 @@ func (s *SourceDescriptionItem) Unmarshal(rawPacket []byte) error {
 	return nil
 }
 
+// NewPrivateItem creates an SDESPrivate item carrying value under prefix,
+// the name of the private extension. See RFC 3550, 6.5.8
+func NewPrivateItem(prefix, value string) (SourceDescriptionItem, error) {
+	/*
+	 *   0                   1                   2                   3
+	 *   0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+	 *  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 *  |     PRIV=8    |     length    | prefix length |prefix string...
+	 *  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 *  ...             |                  value string               ...
+	 *  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	 */
+	if sdesPrefixLengthLen+len(prefix)+len(value) > sdesMaxOctetCount {
+		return SourceDescriptionItem{}, errSDESTextTooLong
+	}
+	text := make([]byte, 0, sdesPrefixLengthLen+len(prefix)+len(value))
+	text = append(text, uint8(len(prefix)))
+	text = append(text, prefix...)
+	text = append(text, value...)
+	return SourceDescriptionItem{Type: SDESPrivate, Text: string(text)}, nil
+}
+
+// Private returns the prefix and the value of an SDESPrivate item. It fails
+// if s isn't SDESPrivate or its prefix length exceeds the item.
+func (s SourceDescriptionItem) Private() (prefix, value string, err error) {
+	if s.Type != SDESPrivate || len(s.Text) < sdesPrefixLengthLen {
+		return "", "", errSDESBadPrivate
+	}
+	n := int(s.Text[0])
+	if sdesPrefixLengthLen+n > len(s.Text) {
+		return "", "", errSDESBadPrivate
+	}
+	return s.Text[sdesPrefixLengthLen : sdesPrefixLengthLen+n], s.Text[sdesPrefixLengthLen+n:], nil
+}
+
+// Private returns the value of the first SDESPrivate item of the chunk with
+// the given prefix, and false if there is none. Malformed items are skipped.
+func (s SourceDescriptionChunk) Private(prefix string) (string, bool) {
+	for _, item := range s.Items {
+		if p, v, err := item.Private(); err == nil && p == prefix {
+			return v, true
+		}
+	}
+	return "", false
+}
+
 // DestinationSSRC returns an array of SSRC values that this packet refers to.
 func (s *SourceDescription) DestinationSSRC() []uint32 {
 	out := make([]uint32, len(s.Chunks))
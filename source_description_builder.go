@@ -0,0 +1,116 @@
+package rtcp
+
+// A SourceDescriptionBuilder collects SDES items for many sources and packs
+// them into SourceDescription packets. Items added for the same source share
+// a chunk, in the order the sources were first added. The zero value is
+// ready to use.
+type SourceDescriptionBuilder struct {
+	chunks []SourceDescriptionChunk
+	index  map[uint32]int
+}
+
+// NewSourceDescriptionBuilder creates an empty SourceDescriptionBuilder.
+func NewSourceDescriptionBuilder() *SourceDescriptionBuilder {
+	return &SourceDescriptionBuilder{index: map[uint32]int{}}
+}
+
+// Add appends items to the chunk describing source, creating the chunk if
+// needed. It returns an error if an item can't be encoded.
+func (b *SourceDescriptionBuilder) Add(source uint32, items ...SourceDescriptionItem) error {
+	for _, it := range items {
+		if it.Type == SDESEnd {
+			return errSDESMissingType
+		}
+		if len(it.Text) > sdesMaxOctetCount {
+			return errSDESTextTooLong
+		}
+	}
+
+	if b.index == nil {
+		b.index = map[uint32]int{}
+	}
+	i, ok := b.index[source]
+	if !ok {
+		i = len(b.chunks)
+		b.index[source] = i
+		b.chunks = append(b.chunks, SourceDescriptionChunk{Source: source})
+	}
+	b.chunks[i].Items = append(b.chunks[i].Items, items...)
+	return nil
+}
+
+// AddCNAME adds a CNAME item for each of the sources. All the SSRCs and
+// CSRCs sent by one participant share its CNAME.
+func (b *SourceDescriptionBuilder) AddCNAME(cname string, sources ...uint32) error {
+	for _, source := range sources {
+		if err := b.Add(source, SourceDescriptionItem{Type: SDESCNAME, Text: cname}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Chunks returns the chunks collected so far.
+func (b *SourceDescriptionBuilder) Chunks() []SourceDescriptionChunk {
+	return b.chunks
+}
+
+// Size returns the number of bytes needed to send every chunk, accounting
+// for the packet header of each SourceDescription that Build would produce
+// without a size limit.
+func (b *SourceDescriptionBuilder) Size() int {
+	size := 0
+	for i, c := range b.chunks {
+		if i%countMax == 0 {
+			size += headerLength
+		}
+		size += c.len()
+	}
+	return size
+}
+
+// Fit returns how many of the leading chunks fit in a single
+// SourceDescription of at most maxSize bytes. A compound builder can use it
+// to decide how much of the description to send in the space left over.
+func (b *SourceDescriptionBuilder) Fit(maxSize int) int {
+	return sourceDescriptionChunksFit(b.chunks, maxSize)
+}
+
+// Build packs the chunks into as few SourceDescription packets as possible,
+// each at most maxSize bytes and with at most 31 chunks. A maxSize of zero
+// or less means no size limit. It returns an error if a single chunk
+// doesn't fit into maxSize.
+func (b *SourceDescriptionBuilder) Build(maxSize int) ([]*SourceDescription, error) {
+	var packets []*SourceDescription
+	for chunks := b.chunks; len(chunks) > 0; {
+		n := sourceDescriptionChunksFit(chunks, maxSize)
+		if n == 0 {
+			return nil, errSDESChunkTooLarge
+		}
+		packets = append(packets, &SourceDescription{Chunks: chunks[:n:n]})
+		chunks = chunks[n:]
+	}
+	return packets, nil
+}
+
+// Reset removes all chunks from the builder. Packets returned by Build stay
+// valid.
+func (b *SourceDescriptionBuilder) Reset() {
+	b.chunks = nil
+	b.index = map[uint32]int{}
+}
+
+// sourceDescriptionChunksFit returns how many of the leading chunks fit in
+// one SourceDescription of at most maxSize bytes, or without a size limit if
+// maxSize is zero or less
+func sourceDescriptionChunksFit(chunks []SourceDescriptionChunk, maxSize int) int {
+	size := headerLength
+	n := 0
+	for ; n < len(chunks) && n < countMax; n++ {
+		size += chunks[n].len()
+		if maxSize > 0 && size > maxSize {
+			break
+		}
+	}
+	return n
+}
@@ -0,0 +1,111 @@
+package rtcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceDescriptionBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewSourceDescriptionBuilder()
+	assert.NoError(b.AddCNAME("host", 1, 2))
+	assert.NoError(b.Add(1, SourceDescriptionItem{Type: SDESTool, Text: "pion"}))
+	assert.Equal([]SourceDescriptionChunk{
+		{Source: 1, Items: []SourceDescriptionItem{{Type: SDESCNAME, Text: "host"}, {Type: SDESTool, Text: "pion"}}},
+		{Source: 2, Items: []SourceDescriptionItem{{Type: SDESCNAME, Text: "host"}}},
+	}, b.Chunks())
+
+	// 4 + (4 + 6 + 6 + 1 + 3) + (4 + 6 + 1 + 1)
+	assert.Equal(36, b.Size())
+
+	packets, err := b.Build(0)
+	assert.NoError(err)
+	assert.Len(packets, 1)
+	data, err := packets[0].Marshal()
+	assert.NoError(err)
+	assert.Equal(b.Size(), len(data))
+	assert.Equal(packets[0].MarshalSize(), len(data))
+
+	var got SourceDescription
+	assert.NoError(got.Unmarshal(data))
+	assert.Equal(b.Chunks(), got.Chunks)
+
+	assert.Equal(errSDESMissingType, b.Add(3, SourceDescriptionItem{}))
+	assert.Equal(errSDESTextTooLong, b.Add(3, SourceDescriptionItem{Type: SDESNote, Text: strings.Repeat("x", 256)}))
+	assert.Len(b.Chunks(), 2)
+
+	b.Reset()
+	assert.Empty(b.Chunks())
+	assert.Equal(0, b.Size())
+	assert.Len(packets[0].Chunks, 2)
+}
+
+func TestSourceDescriptionBuilderZeroValue(t *testing.T) {
+	assert := assert.New(t)
+
+	var b SourceDescriptionBuilder
+	assert.Equal(0, b.Size())
+	assert.NoError(b.AddCNAME("host", 1))
+	assert.NoError(b.Add(1, SourceDescriptionItem{Type: SDESTool, Text: "pion"}))
+	assert.Equal([]SourceDescriptionChunk{
+		{Source: 1, Items: []SourceDescriptionItem{{Type: SDESCNAME, Text: "host"}, {Type: SDESTool, Text: "pion"}}},
+	}, b.Chunks())
+}
+
+func TestSourceDescriptionBuilderSplit(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewSourceDescriptionBuilder()
+	for ssrc := uint32(0); ssrc < 40; ssrc++ {
+		// each chunk is 4 + 2 + 5 + 1 = 12 bytes
+		assert.NoError(b.AddCNAME("cname", ssrc))
+	}
+	assert.Equal(4+31*12+4+9*12, b.Size())
+
+	// the chunk count limit splits the packet
+	packets, err := b.Build(0)
+	assert.NoError(err)
+	assert.Len(packets, 2)
+	assert.Len(packets[0].Chunks, 31)
+	assert.Len(packets[1].Chunks, 9)
+
+	// as does the size limit
+	assert.Equal(8, b.Fit(100))
+	packets, err = b.Build(100)
+	assert.NoError(err)
+	assert.Len(packets, 5)
+	source := uint32(0)
+	for _, p := range packets {
+		data, err := p.Marshal()
+		assert.NoError(err)
+		assert.LessOrEqual(len(data), 100)
+		for _, c := range p.Chunks {
+			assert.Equal(source, c.Source)
+			source++
+		}
+	}
+
+	assert.Equal(0, b.Fit(15))
+	_, err = b.Build(15)
+	assert.Equal(errSDESChunkTooLarge, err)
+}
+
+func TestSourceDescriptionChunkPadding(t *testing.T) {
+	// the items always end with at least one null octet, then pad to 32 bits
+	for n := 0; n < 8; n++ {
+		c := SourceDescriptionChunk{Source: 1, Items: []SourceDescriptionItem{{Type: SDESCNAME, Text: strings.Repeat("a", n)}}}
+		data, err := c.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal %d: %v", n, err)
+		}
+		if len(data)%4 != 0 || len(data) != c.MarshalSize() {
+			t.Fatalf("Marshal %d: got %d bytes, want %d", n, len(data), c.MarshalSize())
+		}
+		if len(data) < 4+2+n+1 || data[4+2+n] != 0 {
+			t.Fatalf("Marshal %d: items not null terminated: %v", n, data)
+		}
+	}
+}
@@ -20,8 +20,8 @@ func TestSourceDescriptionUnmarshal(t *testing.T) {
 		{
 			Name: "no chunks",
 			Data: []byte{
-				// v=2, p=0, count=1, SDES, len=8
-				0x80, 0xca, 0x00, 0x04,
+				// v=2, p=0, count=0, SDES, len=0
+				0x80, 0xca, 0x00, 0x00,
 			},
 			Want: SourceDescription{
 				Chunks: nil,
@@ -30,8 +30,8 @@ func TestSourceDescriptionUnmarshal(t *testing.T) {
 		{
 			Name: "missing type",
 			Data: []byte{
-				// v=2, p=0, count=1, SDES, len=8
-				0x81, 0xca, 0x00, 0x08,
+				// v=2, p=0, count=1, SDES, len=1
+				0x81, 0xca, 0x00, 0x01,
 				// ssrc=0x00000000
 				0x00, 0x00, 0x00, 0x00,
 			},
@@ -40,8 +40,8 @@ func TestSourceDescriptionUnmarshal(t *testing.T) {
 		{
 			Name: "bad cname length",
 			Data: []byte{
-				// v=2, p=0, count=1, SDES, len=10
-				0x81, 0xca, 0x00, 0x0a,
+				// v=2, p=0, count=1, SDES, len=2
+				0x81, 0xca, 0x00, 0x02,
 				// ssrc=0x00000000
 				0x00, 0x00, 0x00, 0x00,
 				// CNAME, len = 1
@@ -52,8 +52,8 @@ func TestSourceDescriptionUnmarshal(t *testing.T) {
 		{
 			Name: "short cname",
 			Data: []byte{
-				// v=2, p=0, count=1, SDES, len=9
-				0x81, 0xca, 0x00, 0x09,
+				// v=2, p=0, count=1, SDES, len=2
+				0x81, 0xca, 0x00, 0x02,
 				// ssrc=0x00000000
 				0x00, 0x00, 0x00, 0x00,
 				// CNAME, Missing length
@@ -64,8 +64,8 @@ func TestSourceDescriptionUnmarshal(t *testing.T) {
 		{
 			Name: "no end",
 			Data: []byte{
-				// v=2, p=0, count=1, SDES, len=11
-				0x81, 0xca, 0x00, 0x0b,
+				// v=2, p=0, count=1, SDES, len=2
+				0x81, 0xca, 0x00, 0x02,
 				// ssrc=0x00000000
 				0x00, 0x00, 0x00, 0x00,
 				// CNAME, len=1, content=A
@@ -77,8 +77,8 @@ func TestSourceDescriptionUnmarshal(t *testing.T) {
 		{
 			Name: "bad octet count",
 			Data: []byte{
-				// v=2, p=0, count=1, SDES, len=10
-				0x81, 0xca, 0x00, 0x0a,
+				// v=2, p=0, count=1, SDES, len=2
+				0x81, 0xca, 0x00, 0x02,
 				// ssrc=0x00000000
 				0x00, 0x00, 0x00, 0x00,
 				// CNAME, len=1
@@ -89,8 +89,8 @@ func TestSourceDescriptionUnmarshal(t *testing.T) {
 		{
 			Name: "zero item chunk",
 			Data: []byte{
-				// v=2, p=0, count=1, SDES, len=12
-				0x81, 0xca, 0x00, 0x0c,
+				// v=2, p=0, count=1, SDES, len=2
+				0x81, 0xca, 0x00, 0x02,
 				// ssrc=0x01020304
 				0x01, 0x02, 0x03, 0x04,
 				// END + padding
@@ -106,8 +106,8 @@ func TestSourceDescriptionUnmarshal(t *testing.T) {
 		{
 			Name: "wrong type",
 			Data: []byte{
-				// v=2, p=0, count=1, SR, len=12
-				0x81, 0xc8, 0x00, 0x0c,
+				// v=2, p=0, count=1, SR, len=2
+				0x81, 0xc8, 0x00, 0x02,
 				// ssrc=0x01020304
 				0x01, 0x02, 0x03, 0x04,
 				// END + padding
@@ -118,16 +118,16 @@ func TestSourceDescriptionUnmarshal(t *testing.T) {
 		{
 			Name: "bad count in header",
 			Data: []byte{
-				// v=2, p=0, count=1, SDES, len=12
-				0x81, 0xca, 0x00, 0x0c,
+				// v=2, p=0, count=1, SDES, len=0
+				0x81, 0xca, 0x00, 0x00,
 			},
 			WantError: errInvalidHeader,
 		},
 		{
 			Name: "empty string",
 			Data: []byte{
-				// v=2, p=0, count=1, SDES, len=12
-				0x81, 0xca, 0x00, 0x0c,
+				// v=2, p=0, count=1, SDES, len=2
+				0x81, 0xca, 0x00, 0x02,
 				// ssrc=0x01020304
 				0x01, 0x02, 0x03, 0x04,
 				// CNAME, len=0
@@ -150,8 +150,8 @@ func TestSourceDescriptionUnmarshal(t *testing.T) {
 		{
 			Name: "two items",
 			Data: []byte{
-				// v=2, p=0, count=1, SDES, len=16
-				0x81, 0xca, 0x00, 0x10,
+				// v=2, p=0, count=1, SDES, len=3
+				0x81, 0xca, 0x00, 0x03,
 				// ssrc=0x10000000
 				0x10, 0x00, 0x00, 0x00,
 				// CNAME, len=1, content=A
@@ -182,8 +182,8 @@ func TestSourceDescriptionUnmarshal(t *testing.T) {
 		{
 			Name: "two chunks",
 			Data: []byte{
-				// v=2, p=0, count=2, SDES, len=24
-				0x82, 0xca, 0x00, 0x18,
+				// v=2, p=0, count=2, SDES, len=5
+				0x82, 0xca, 0x00, 0x05,
 				// ssrc=0x01020304
 				0x01, 0x02, 0x03, 0x04,
 				// Chunk 1
@@ -368,3 +368,67 @@ func init() {
 		tooLongText += "x"
 	}
 }
+
+func TestSourceDescriptionPrivate(t *testing.T) {
+	item, err := NewPrivateItem("route", "edge-1")
+	if err != nil {
+		t.Fatalf("NewPrivateItem: %v", err)
+	}
+	if want := (SourceDescriptionItem{Type: SDESPrivate, Text: "\x05routeedge-1"}); item != want {
+		t.Fatalf("NewPrivateItem: got %#v, want %#v", item, want)
+	}
+
+	sdes := SourceDescription{Chunks: []SourceDescriptionChunk{{
+		Source: 1,
+		Items: []SourceDescriptionItem{
+			{Type: SDESCNAME, Text: "cname"},
+			{Type: SDESPrivate, Text: "\x09bad"},
+			item,
+		},
+	}}}
+	data, err := sdes.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var decoded SourceDescription
+	if err = decoded.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	prefix, value, err := decoded.Chunks[0].Items[2].Private()
+	if err != nil || prefix != "route" || value != "edge-1" {
+		t.Fatalf("Private: got %q, %q, %v, want route, edge-1", prefix, value, err)
+	}
+	if value, ok := decoded.Chunks[0].Private("route"); !ok || value != "edge-1" {
+		t.Fatalf("chunk Private: got %q, %v, want edge-1", value, ok)
+	}
+	if _, ok := decoded.Chunks[0].Private("other"); ok {
+		t.Fatal("chunk Private: found a missing prefix")
+	}
+
+	for _, test := range []struct {
+		Name string
+		Item SourceDescriptionItem
+	}{
+		{"not private", SourceDescriptionItem{Type: SDESCNAME, Text: "\x00"}},
+		{"empty", SourceDescriptionItem{Type: SDESPrivate}},
+		{"prefix too long", SourceDescriptionItem{Type: SDESPrivate, Text: "\x09bad"}},
+	} {
+		if _, _, err := test.Item.Private(); err != errSDESBadPrivate {
+			t.Fatalf("Private %q: got %v, want %v", test.Name, err, errSDESBadPrivate)
+		}
+	}
+
+	// an empty prefix and value are valid
+	item, err = NewPrivateItem("", "")
+	if err != nil {
+		t.Fatalf("NewPrivateItem empty: %v", err)
+	}
+	if prefix, value, err = item.Private(); err != nil || prefix != "" || value != "" {
+		t.Fatalf("Private empty: got %q, %q, %v", prefix, value, err)
+	}
+
+	if _, err := NewPrivateItem("route", tooLongText); err != errSDESTextTooLong {
+		t.Fatalf("NewPrivateItem too long: got %v, want %v", err, errSDESTextTooLong)
+	}
+}
@@ -0,0 +1,64 @@
+package rtcp
+
+// srtcpIndexLength is the length of the E flag and SRTCP index trailing
+// every SRTCP packet (RFC 3711, 3.4)
+const srtcpIndexLength = 4
+
+// An Encryptor protects marshaled RTCP before it is sent, for example as
+// SRTCP. The Context of pion/srtp satisfies it.
+type Encryptor interface {
+	// EncryptRTCP returns the protected form of decrypted, whose first
+	// header is given. The result may reuse the storage of dst, starting at
+	// dst[0].
+	EncryptRTCP(dst, decrypted []byte, header *Header) ([]byte, error)
+}
+
+// A Decryptor reverses the protection applied by an Encryptor after a
+// datagram is received. The Context of pion/srtp satisfies it.
+type Decryptor interface {
+	// DecryptRTCP returns the plain form of encrypted, whose first header
+	// is given. The result may reuse the storage of dst, starting at dst[0].
+	DecryptRTCP(dst, encrypted []byte, header *Header) ([]byte, error)
+}
+
+// SRTCPOverhead returns the number of bytes SRTCP adds to every packet when
+// the negotiated authentication tag is authTagLength bytes long, such as 10
+// for AES_CM_128_HMAC_SHA1_80 or 16 for AEAD_AES_128_GCM.
+func SRTCPOverhead(authTagLength int) int {
+	return srtcpIndexLength + authTagLength
+}
+
+// encryptRTCP marshals pkts and protects them with enc if it is not nil.
+// overhead is reserved in the buffer so enc doesn't need to grow it.
+func encryptRTCP(enc Encryptor, overhead int, pkts []Packet) ([]byte, error) {
+	data, err := Marshal(pkts)
+	if err != nil || enc == nil {
+		return data, err
+	}
+
+	var h Header
+	if err := h.Unmarshal(data); err != nil {
+		return nil, err
+	}
+
+	return enc.EncryptRTCP(make([]byte, 0, len(data)+overhead), data, &h)
+}
+
+// decryptRTCP removes the protection of encrypted with dec if it is not nil
+// and unmarshals the packets
+func decryptRTCP(dec Decryptor, encrypted []byte) ([]Packet, error) {
+	if dec == nil {
+		return Unmarshal(encrypted)
+	}
+
+	var h Header
+	if err := h.Unmarshal(encrypted); err != nil {
+		return nil, err
+	}
+
+	data, err := dec.DecryptRTCP(make([]byte, 0, len(encrypted)), encrypted, &h)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(data)
+}
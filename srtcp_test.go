@@ -0,0 +1,109 @@
+package rtcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errBadTag = errors.New("bad tag")
+
+// xorCipher protects everything after the first 8 octets like SRTCP does,
+// and appends an index and a tag of a fixed length
+type xorCipher struct {
+	tagLength int
+	headers   []Header
+}
+
+func (c *xorCipher) EncryptRTCP(dst, decrypted []byte, header *Header) ([]byte, error) {
+	c.headers = append(c.headers, *header)
+	out := append(dst[:0], decrypted...)
+	for i := 8; i < len(out); i++ {
+		out[i] ^= 0xff
+	}
+	out = append(out, 0x80, 0, 0, 1)
+	return append(out, bytes.Repeat([]byte{0xaa}, c.tagLength)...), nil
+}
+
+func (c *xorCipher) DecryptRTCP(dst, encrypted []byte, header *Header) ([]byte, error) {
+	c.headers = append(c.headers, *header)
+	n := len(encrypted) - SRTCPOverhead(c.tagLength)
+	if n < 8 || !bytes.Equal(encrypted[n+srtcpIndexLength:], bytes.Repeat([]byte{0xaa}, c.tagLength)) {
+		return nil, errBadTag
+	}
+	out := append(dst[:0], encrypted[:n]...)
+	for i := 8; i < len(out); i++ {
+		out[i] ^= 0xff
+	}
+	return out, nil
+}
+
+func TestSRTCPOverhead(t *testing.T) {
+	assert.Equal(t, 14, SRTCPOverhead(10))
+	assert.Equal(t, 20, SRTCPOverhead(16))
+}
+
+func TestDatagramTransportSRTCP(t *testing.T) {
+	conn := make(chanDatagramConn, 1)
+	cipher := &xorCipher{tagLength: 10}
+	tr := NewDatagramTransport(conn, 0)
+	tr.Encryptor = cipher
+	tr.Decryptor = cipher
+	tr.AuthTagLength = cipher.tagLength
+
+	pkts := []Packet{&TransportLayerNack{SenderSSRC: 1, MediaSSRC: 2, Nacks: []NackPair{{PacketID: 10}}}}
+	assert.NoError(t, tr.WriteRTCP(pkts))
+
+	sent := <-conn
+	plain, err := Marshal(pkts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1+len(plain)+SRTCPOverhead(10), len(sent))
+	assert.NotEqual(t, plain, sent[1:1+len(plain)])
+
+	conn <- sent
+	got, err := tr.ReadRTCP()
+	assert.NoError(t, err)
+	assert.Equal(t, pkts, got)
+
+	assert.Equal(t, []Header{pkts[0].(*TransportLayerNack).Header(), pkts[0].(*TransportLayerNack).Header()}, cipher.headers)
+
+	conn <- sent[:len(sent)-1]
+	_, err = tr.ReadRTCP()
+	assert.Equal(t, errBadTag, err)
+	assert.NoError(t, tr.Close())
+
+	_, err = tr.ReadRTCP()
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestDatagramTransportSRTCPPadding(t *testing.T) {
+	conn := make(chanDatagramConn, 1)
+	cipher := &xorCipher{tagLength: 10}
+	tr := NewDatagramTransport(conn, 0)
+	tr.Decryptor = cipher
+	tr.AuthTagLength = cipher.tagLength
+
+	rr := &ReceiverReport{SSRC: 1, Reports: []ReceptionReport{{SSRC: 2, FractionLost: 10}}}
+	sdes := &SourceDescription{Chunks: []SourceDescriptionChunk{{
+		Source: 1,
+		Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: "cname"}},
+	}}}
+	raw, err := Marshal([]Packet{rr, sdes})
+	assert.NoError(t, err)
+	want, err := Unmarshal(raw)
+	assert.NoError(t, err)
+
+	// pad the last packet to the block size of the cipher, then protect it
+	padded := append(raw[:32:32], withPadding(raw[32:], 16, true)...)
+	encrypted, err := cipher.EncryptRTCP(nil, padded, &Header{})
+	assert.NoError(t, err)
+
+	conn <- append([]byte{0}, encrypted...)
+	got, err := tr.ReadRTCP()
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.NoError(t, tr.Close())
+}
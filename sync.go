@@ -0,0 +1,510 @@
+package rtcp
+
+import (
+	"sync"
+	"time"
+)
+
+// The stateful types of this package, such as Recorder,
+// TransportLayerCCHistory, Scheduler and MemberTable, aren't safe for
+// concurrent use. The Sync variants below guard one with a mutex, so it can
+// be fed from the goroutine reading RTP and drained from the one sending
+// RTCP. Configure the wrapped value before wrapping it, and only access it
+// through the wrapper afterwards.
+
+// A SyncRecorder is a Recorder that is safe for concurrent use.
+type SyncRecorder struct {
+	mu sync.Mutex
+	r  *Recorder
+}
+
+// NewSyncRecorder wraps r.
+func NewSyncRecorder(r *Recorder) *SyncRecorder {
+	return &SyncRecorder{r: r}
+}
+
+// Record calls Recorder.Record.
+func (s *SyncRecorder) Record(seq uint16, arrival time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r.Record(seq, arrival)
+}
+
+// RecordSSRC calls Recorder.RecordSSRC.
+func (s *SyncRecorder) RecordSSRC(seq uint16, ssrc uint32, arrival time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r.RecordSSRC(seq, ssrc, arrival)
+}
+
+// RecordNow calls Recorder.RecordNow.
+func (s *SyncRecorder) RecordNow(seq uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r.RecordNow(seq)
+}
+
+// RecordECN calls Recorder.RecordECN.
+func (s *SyncRecorder) RecordECN(seq uint16, ecn ECN, arrival time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r.RecordECN(seq, ecn, arrival)
+}
+
+// RecordSize calls Recorder.RecordSize.
+func (s *SyncRecorder) RecordSize(seq uint16, size int, arrival time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r.RecordSize(seq, size, arrival)
+}
+
+// RecordSSRCSize calls Recorder.RecordSSRCSize.
+func (s *SyncRecorder) RecordSSRCSize(seq uint16, ssrc uint32, size int, arrival time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r.RecordSSRCSize(seq, ssrc, size, arrival)
+}
+
+// Bitrate calls Recorder.Bitrate.
+func (s *SyncRecorder) Bitrate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Bitrate()
+}
+
+// IntervalBytes calls Recorder.IntervalBytes.
+func (s *SyncRecorder) IntervalBytes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.IntervalBytes()
+}
+
+// SSRCs calls Recorder.SSRCs.
+func (s *SyncRecorder) SSRCs() []uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.SSRCs()
+}
+
+// BuildFeedback calls Recorder.BuildFeedback.
+func (s *SyncRecorder) BuildFeedback() []*TransportLayerCC {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.BuildFeedback()
+}
+
+// BuildCCFeedbackReport calls Recorder.BuildCCFeedbackReport.
+func (s *SyncRecorder) BuildCCFeedbackReport(reportTime time.Time) *CCFeedbackReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.BuildCCFeedbackReport(reportTime)
+}
+
+// Evictions calls Recorder.Evictions.
+func (s *SyncRecorder) Evictions() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Evictions()
+}
+
+// A SyncTransportLayerCCHistory is a TransportLayerCCHistory that is safe
+// for concurrent use.
+type SyncTransportLayerCCHistory struct {
+	mu sync.Mutex
+	h  *TransportLayerCCHistory
+}
+
+// NewSyncTransportLayerCCHistory wraps h.
+func NewSyncTransportLayerCCHistory(h *TransportLayerCCHistory) *SyncTransportLayerCCHistory {
+	return &SyncTransportLayerCCHistory{h: h}
+}
+
+// OnSent calls TransportLayerCCHistory.OnSent.
+func (s *SyncTransportLayerCCHistory) OnSent(seq uint16, size int, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.h.OnSent(seq, size, at)
+}
+
+// OnFeedback calls TransportLayerCCHistory.OnFeedback. fb must not be
+// modified concurrently.
+func (s *SyncTransportLayerCCHistory) OnFeedback(fb *TransportLayerCC) FeedbackResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.h.OnFeedback(fb)
+}
+
+// OnCCFeedbackReport calls TransportLayerCCHistory.OnCCFeedbackReport. fb
+// must not be modified concurrently.
+func (s *SyncTransportLayerCCHistory) OnCCFeedbackReport(fb *CCFeedbackReport) FeedbackResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.h.OnCCFeedbackReport(fb)
+}
+
+// Len calls TransportLayerCCHistory.Len.
+func (s *SyncTransportLayerCCHistory) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.h.Len()
+}
+
+// Evictions calls TransportLayerCCHistory.Evictions.
+func (s *SyncTransportLayerCCHistory) Evictions() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.h.Evictions()
+}
+
+// Prune calls TransportLayerCCHistory.Prune.
+func (s *SyncTransportLayerCCHistory) Prune(olderThan time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.h.Prune(olderThan)
+}
+
+// LateFeedback calls TransportLayerCCHistory.LateFeedback.
+func (s *SyncTransportLayerCCHistory) LateFeedback() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.h.LateFeedback()
+}
+
+// A SyncScheduler is a Scheduler that is safe for concurrent use. Its
+// Membership must be safe for concurrent use too, e.g. a SyncMemberTable.
+type SyncScheduler struct {
+	mu sync.Mutex
+	s  *Scheduler
+}
+
+// NewSyncScheduler wraps s.
+func NewSyncScheduler(s *Scheduler) *SyncScheduler {
+	return &SyncScheduler{s: s}
+}
+
+// OnSent calls Scheduler.OnSent.
+func (s *SyncScheduler) OnSent(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.OnSent(size)
+}
+
+// OnReceived calls Scheduler.OnReceived.
+func (s *SyncScheduler) OnReceived(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.OnReceived(size)
+}
+
+// OnReceivedBye calls Scheduler.OnReceivedBye.
+func (s *SyncScheduler) OnReceivedBye(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.OnReceivedBye(size)
+}
+
+// Leave calls Scheduler.Leave.
+func (s *SyncScheduler) Leave(byeSize int) (immediate bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.s.Leave(byeSize)
+}
+
+// Leaving calls Scheduler.Leaving.
+func (s *SyncScheduler) Leaving() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.s.Leaving()
+}
+
+// SetWeSent sets Scheduler.WeSent.
+func (s *SyncScheduler) SetWeSent(weSent bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.WeSent = weSent
+}
+
+// AverageSize calls Scheduler.AverageSize.
+func (s *SyncScheduler) AverageSize() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.s.AverageSize()
+}
+
+// DeterministicInterval calls Scheduler.DeterministicInterval.
+func (s *SyncScheduler) DeterministicInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.s.DeterministicInterval()
+}
+
+// Interval calls Scheduler.Interval.
+func (s *SyncScheduler) Interval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.s.Interval()
+}
+
+// A SyncMemberTable is a MemberTable that is safe for concurrent use.
+type SyncMemberTable struct {
+	mu sync.Mutex
+	m  *MemberTable
+}
+
+var _ MemberCounter = (*SyncMemberTable)(nil) // assert is a MemberCounter
+
+// NewSyncMemberTable wraps m.
+func NewSyncMemberTable(m *MemberTable) *SyncMemberTable {
+	return &SyncMemberTable{m: m}
+}
+
+// Update calls MemberTable.Update.
+func (s *SyncMemberTable) Update(packets []Packet) (collision bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Update(packets)
+}
+
+// Expire calls MemberTable.Expire.
+func (s *SyncMemberTable) Expire() []uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Expire()
+}
+
+// Member calls MemberTable.Member.
+func (s *SyncMemberTable) Member(ssrc uint32) (Member, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Member(ssrc)
+}
+
+// Members calls MemberTable.Members.
+func (s *SyncMemberTable) Members() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Members()
+}
+
+// Senders calls MemberTable.Senders.
+func (s *SyncMemberTable) Senders() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Senders()
+}
+
+// Evictions calls MemberTable.Evictions.
+func (s *SyncMemberTable) Evictions() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Evictions()
+}
+
+// OnRTP calls MemberTable.OnRTP.
+func (s *SyncMemberTable) OnRTP(ssrc uint32) (collision bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.OnRTP(ssrc)
+}
+
+// OnLocalRTP calls MemberTable.OnLocalRTP.
+func (s *SyncMemberTable) OnLocalRTP() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.OnLocalRTP()
+}
+
+// LocalSender calls MemberTable.LocalSender.
+func (s *SyncMemberTable) LocalSender() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.LocalSender()
+}
+
+// ExpireSenders calls MemberTable.ExpireSenders.
+func (s *SyncMemberTable) ExpireSenders() []uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.ExpireSenders()
+}
+
+// A SyncReceiverStats is a ReceiverStats that is safe for concurrent use.
+type SyncReceiverStats struct {
+	mu sync.Mutex
+	s  *ReceiverStats
+}
+
+// NewSyncReceiverStats wraps s.
+func NewSyncReceiverStats(s *ReceiverStats) *SyncReceiverStats {
+	return &SyncReceiverStats{s: s}
+}
+
+// Update calls ReceiverStats.Update.
+func (s *SyncReceiverStats) Update(ssrc uint32, payloadType uint8, sequenceNumber uint16, rtpTimestamp uint32, arrival time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Update(ssrc, payloadType, sequenceNumber, rtpTimestamp, arrival)
+}
+
+// OnSenderReport calls ReceiverStats.OnSenderReport.
+func (s *SyncReceiverStats) OnSenderReport(sr *SenderReport, arrival time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.OnSenderReport(sr, arrival)
+}
+
+// Remove calls ReceiverStats.Remove.
+func (s *SyncReceiverStats) Remove(ssrc uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Remove(ssrc)
+}
+
+// ReceptionReports calls ReceiverStats.ReceptionReports.
+func (s *SyncReceiverStats) ReceptionReports() []ReceptionReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.s.ReceptionReports()
+}
+
+// RepairReceptionReports calls ReceiverStats.RepairReceptionReports.
+func (s *SyncReceiverStats) RepairReceptionReports() []ReceptionReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.s.RepairReceptionReports()
+}
+
+// OnRepaired calls ReceiverStats.OnRepaired.
+func (s *SyncReceiverStats) OnRepaired(ssrc uint32, sequenceNumber uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.OnRepaired(ssrc, sequenceNumber)
+}
+
+// A SyncRTTEstimator is an RTTEstimator that is safe for concurrent use.
+type SyncRTTEstimator struct {
+	mu sync.Mutex
+	e  *RTTEstimator
+}
+
+// NewSyncRTTEstimator wraps e.
+func NewSyncRTTEstimator(e *RTTEstimator) *SyncRTTEstimator {
+	return &SyncRTTEstimator{e: e}
+}
+
+// SentSenderReport calls RTTEstimator.SentSenderReport.
+func (s *SyncRTTEstimator) SentSenderReport(sr *SenderReport, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.e.SentSenderReport(sr, at)
+}
+
+// OnReceptionReport calls RTTEstimator.OnReceptionReport.
+func (s *SyncRTTEstimator) OnReceptionReport(r ReceptionReport, arrival time.Time) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.e.OnReceptionReport(r, arrival)
+}
+
+// OnDelaySinceLast calls RTTEstimator.OnDelaySinceLast.
+func (s *SyncRTTEstimator) OnDelaySinceLast(last, delay uint32, arrival time.Time) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.e.OnDelaySinceLast(last, delay, arrival)
+}
+
+// OnRTT calls RTTEstimator.OnRTT.
+func (s *SyncRTTEstimator) OnRTT(rtt time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.e.OnRTT(rtt)
+}
+
+// SRTT calls RTTEstimator.SRTT.
+func (s *SyncRTTEstimator) SRTT() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.e.SRTT()
+}
+
+// RTTVar calls RTTEstimator.RTTVar.
+func (s *SyncRTTEstimator) RTTVar() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.e.RTTVar()
+}
+
+// Latest calls RTTEstimator.Latest.
+func (s *SyncRTTEstimator) Latest() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.e.Latest()
+}
+
+// Samples calls RTTEstimator.Samples.
+func (s *SyncRTTEstimator) Samples() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.e.Samples()
+}
+
+// A SyncFeedbackSuppressor is a FeedbackSuppressor that is safe for
+// concurrent use.
+type SyncFeedbackSuppressor struct {
+	mu sync.Mutex
+	f  *FeedbackSuppressor
+}
+
+// NewSyncFeedbackSuppressor wraps f.
+func NewSyncFeedbackSuppressor(f *FeedbackSuppressor) *SyncFeedbackSuppressor {
+	return &SyncFeedbackSuppressor{f: f}
+}
+
+// MaxDither calls FeedbackSuppressor.MaxDither.
+func (s *SyncFeedbackSuppressor) MaxDither() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.MaxDither()
+}
+
+// Schedule calls FeedbackSuppressor.Schedule.
+func (s *SyncFeedbackSuppressor) Schedule(p Packet) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Schedule(p)
+}
+
+// Observe calls FeedbackSuppressor.Observe.
+func (s *SyncFeedbackSuppressor) Observe(p Packet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.f.Observe(p)
+}
+
+// OnRepaired calls FeedbackSuppressor.OnRepaired.
+func (s *SyncFeedbackSuppressor) OnRepaired(ssrc uint32, sequenceNumber uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.f.OnRepaired(ssrc, sequenceNumber)
+}
+
+// Next calls FeedbackSuppressor.Next.
+func (s *SyncFeedbackSuppressor) Next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Next()
+}
+
+// Due calls FeedbackSuppressor.Due.
+func (s *SyncFeedbackSuppressor) Due() []Packet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Due()
+}
+
+// Pending calls FeedbackSuppressor.Pending.
+func (s *SyncFeedbackSuppressor) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Pending()
+}
@@ -0,0 +1,160 @@
+package rtcp
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncRecorder(t *testing.T) {
+	start := time.Unix(1000, 0)
+	r := NewSyncRecorder(NewRecorder(1))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			r.Record(uint16(i), start.Add(time.Duration(i)*time.Millisecond))
+		}
+	}()
+
+	var feedback []*TransportLayerCC
+	for i := 0; i < 100; i++ {
+		feedback = append(feedback, r.BuildFeedback()...)
+	}
+	wg.Wait()
+	feedback = append(feedback, r.BuildFeedback()...)
+
+	received := 0
+	for _, fb := range feedback {
+		received += len(fb.ArrivalTimes())
+	}
+	assert.Equal(t, 1000, received)
+	assert.Equal(t, uint64(0), r.Evictions())
+}
+
+func TestSyncTransportLayerCCHistory(t *testing.T) {
+	start := time.Unix(1000, 0)
+	h := NewSyncTransportLayerCCHistory(NewTransportLayerCCHistory())
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			h.OnSent(uint16(i), 1, start)
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		h.OnFeedback(&TransportLayerCC{
+			BaseSequenceNumber: uint16(i * 10),
+			PacketStatusCount:  10,
+			PacketChunks: []PacketStatusChunk{&RunLengthChunk{
+				PacketStatusSymbol: TypePacketNotReceived,
+				RunLength:          10,
+			}},
+			FbPktCount: uint8(i),
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, 100, h.Len())
+	assert.Equal(t, 0, h.Prune(time.Duration(1<<62)))
+	assert.Equal(t, uint64(0), h.LateFeedback())
+}
+
+func TestSyncSchedulerAndMemberTable(t *testing.T) {
+	m := NewSyncMemberTable(NewMemberTable(1))
+	s := NewSyncScheduler(&Scheduler{Bandwidth: 1000, Membership: m})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			m.Update([]Packet{&ReceiverReport{SSRC: uint32(i + 2)}})
+			s.OnReceived(100)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		s.SetWeSent(i%2 == 0)
+		s.Interval()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 101, m.Members())
+	assert.Equal(t, 0, m.Senders())
+	assert.Equal(t, 100.0, s.AverageSize())
+	s.OnSent(100)
+	// 101 members share the receivers' 3/4 of the bandwidth
+	assert.Equal(t, time.Duration(s.AverageSize()*101/750*float64(time.Second)), s.DeterministicInterval())
+}
+
+func TestSyncReceiverStats(t *testing.T) {
+	start := time.Unix(1000, 0)
+	s := NewSyncReceiverStats(NewReceiverStats(map[uint8]uint32{111: 48000}))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			s.Update(1, 111, uint16(i), uint32(i*960), start.Add(time.Duration(i)*20*time.Millisecond))
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		s.ReceptionReports()
+		s.OnRepaired(1, uint16(i))
+	}
+	wg.Wait()
+	s.Remove(1)
+	assert.Empty(t, s.ReceptionReports())
+	assert.Empty(t, s.RepairReceptionReports())
+}
+
+func TestSyncRTTEstimator(t *testing.T) {
+	s := NewSyncRTTEstimator(NewRTTEstimator())
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			s.OnRTT(100 * time.Millisecond)
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		s.SRTT()
+		s.RTTVar()
+	}
+	wg.Wait()
+	assert.Equal(t, 1000, s.Samples())
+	assert.Equal(t, 100*time.Millisecond, s.Latest())
+}
+
+func TestSyncFeedbackSuppressor(t *testing.T) {
+	s := NewSyncFeedbackSuppressor(NewFeedbackSuppressor(1, 0))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			s.Schedule(&PictureLossIndication{SenderSSRC: 1, MediaSSRC: uint32(i)})
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		s.Observe(&PictureLossIndication{SenderSSRC: 2, MediaSSRC: 1000})
+		s.OnRepaired(1000, uint16(i))
+		s.Next()
+		s.Pending()
+	}
+	wg.Wait()
+	assert.Len(t, s.Due(), 100)
+	assert.Equal(t, time.Duration(0), s.MaxDither())
+}
@@ -0,0 +1,281 @@
+package rtcp
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// VectorStyle selects how TestVector.Annotated writes the bytes of a vector.
+type VectorStyle int
+
+const (
+	// VectorGo writes each 32-bit word as Go byte literals, as in the tests
+	// of this package:
+	//
+	//	// v=2, p=0, count=0, RR, len=1
+	//	0x80, 0xc9, 0x00, 0x01,
+	VectorGo VectorStyle = iota
+	// VectorHex writes each 32-bit word as plain hex followed by its
+	// annotation:
+	//
+	//	80c90001  # v=2, p=0, count=0, RR, len=1
+	VectorHex
+)
+
+// A TestVector pairs RTCP packets with their wire encoding, so that other
+// implementations can check their encoders and decoders against this one.
+// The bytes are listed in network order, so vectors don't depend on the
+// endianness of the machine producing or consuming them.
+type TestVector struct {
+	Name    string
+	Packets []Packet
+	Data    []byte
+}
+
+// NewTestVector marshals packets into a TestVector.
+func NewTestVector(name string, packets ...Packet) (TestVector, error) {
+	data, err := Marshal(packets)
+	if err != nil {
+		return TestVector{}, err
+	}
+	return TestVector{Name: name, Packets: packets, Data: data}, nil
+}
+
+// ParseTestVector reverses TestVector.Annotated: it parses the annotated hex
+// of either style and unmarshals the packets it holds.
+func ParseTestVector(name, annotated string) (TestVector, error) {
+	data, err := ParseAnnotatedHex(annotated)
+	if err != nil {
+		return TestVector{}, err
+	}
+	packets, err := Unmarshal(data)
+	if err != nil {
+		return TestVector{}, err
+	}
+	return TestVector{Name: name, Packets: packets, Data: data}, nil
+}
+
+// ParseAnnotatedHex decodes hex bytes, ignoring comments starting with // or
+// # up to the end of the line, whitespace, commas and 0x prefixes.
+func ParseAnnotatedHex(annotated string) ([]byte, error) {
+	var b strings.Builder
+	for _, line := range strings.Split(annotated, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		for _, field := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\r' }) {
+			field = strings.TrimPrefix(strings.TrimPrefix(field, "0x"), "0X")
+			if len(field)%2 == 1 {
+				// 0x0 style literals of a single digit
+				field = "0" + field
+			}
+			b.WriteString(field)
+		}
+	}
+	return hex.DecodeString(b.String())
+}
+
+// Annotated returns the bytes of the vector, one 32-bit word per line, with
+// the fields of the headers and other common words annotated.
+func (v TestVector) Annotated(style VectorStyle) string {
+	var b strings.Builder
+	comment := "// "
+	if style == VectorHex {
+		comment = "# "
+	}
+	if v.Name != "" {
+		fmt.Fprintf(&b, "%s%s\n", comment, v.Name)
+	}
+
+	notes := annotateWords(v.Data)
+	for i := 0; i < len(v.Data); i += 4 {
+		end := i + 4
+		if end > len(v.Data) {
+			end = len(v.Data)
+		}
+		word := v.Data[i:end]
+		note := notes[i/4]
+
+		if style == VectorHex {
+			if note != "" {
+				fmt.Fprintf(&b, "%-10s%s%s\n", hex.EncodeToString(word), comment, note)
+			} else {
+				fmt.Fprintf(&b, "%s\n", hex.EncodeToString(word))
+			}
+			continue
+		}
+
+		if note != "" {
+			fmt.Fprintf(&b, "%s%s\n", comment, note)
+		}
+		literals := make([]string, len(word))
+		for j, c := range word {
+			literals[j] = fmt.Sprintf("0x%02x,", c)
+		}
+		fmt.Fprintf(&b, "%s\n", strings.Join(literals, " "))
+	}
+	return b.String()
+}
+
+// annotateWords returns a note for each 32-bit word of a datagram. Words
+// with nothing notable have an empty note.
+func annotateWords(data []byte) []string {
+	notes := make([]string, (len(data)+3)/4)
+	for pos := 0; pos+headerLength <= len(data); {
+		var h Header
+		if err := h.Unmarshal(data[pos:]); err != nil {
+			break
+		}
+		size := (int(h.Length) + 1) * 4
+		padding := 0
+		if h.Padding && pos+size <= len(data) {
+			padding = int(data[pos+size-1])
+		}
+		count := "count"
+		if h.Type == TypeTransportSpecificFeedback || h.Type == TypePayloadSpecificFeedback {
+			count = "fmt"
+		}
+		notes[pos/4] = fmt.Sprintf("v=2, p=%d, %s=%d, %v, len=%d", boolToInt(h.Padding), count, h.Count, h.Type, h.Length)
+
+		words := (size - padding) / 4
+		set := func(word int, format string, args ...interface{}) {
+			if word < words && pos/4+word < len(notes) {
+				notes[pos/4+word] = fmt.Sprintf(format, args...)
+			}
+		}
+		word := func(i int) uint32 {
+			o := pos + i*4
+			if o+4 > len(data) {
+				return 0
+			}
+			return uint32(data[o])<<24 | uint32(data[o+1])<<16 | uint32(data[o+2])<<8 | uint32(data[o+3])
+		}
+
+		reports := 0
+		switch h.Type {
+		case TypeSenderReport:
+			set(1, "ssrc=0x%x", word(1))
+			set(2, "ntp=0x%08x%08x", word(2), word(3))
+			set(4, "rtp=0x%x", word(4))
+			set(5, "packetCount=%d", word(5))
+			set(6, "octetCount=%d", word(6))
+			reports = 7
+		case TypeReceiverReport:
+			set(1, "ssrc=0x%x", word(1))
+			reports = 2
+		case TypeSourceDescription, TypeGoodbye:
+			set(1, "ssrc=0x%x", word(1))
+		case TypeApplicationDefined:
+			set(1, "ssrc=0x%x", word(1))
+			if pos+12 <= len(data) {
+				set(2, "name=%q", string(data[pos+8:pos+12]))
+			}
+		case TypeExtendedReport, TypeToken:
+			set(1, "ssrc=0x%x", word(1))
+		case TypeTransportSpecificFeedback, TypePayloadSpecificFeedback:
+			set(1, "sender=0x%x", word(1))
+			set(2, "media=0x%x", word(2))
+		}
+		for i := 0; i < int(h.Count) && reports > 0 && (h.Type == TypeSenderReport || h.Type == TypeReceiverReport); i++ {
+			base := reports + i*6
+			set(base, "report %d: ssrc=0x%x", i, word(base))
+			set(base+1, "fractionLost=%d, totalLost=%d", word(base+1)>>24, word(base+1)&0xffffff)
+			set(base+2, "lastSeq=0x%x", word(base+2))
+			set(base+3, "jitter=%d", word(base+3))
+			set(base+4, "lsr=0x%x", word(base+4))
+			set(base+5, "delay=%d", word(base+5))
+		}
+		if padding > 0 && pos+size <= len(data) {
+			notes[(pos+size)/4-1] = fmt.Sprintf("padding=%d", padding)
+		}
+
+		if size <= 0 {
+			break
+		}
+		pos += size
+	}
+	return notes
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// TestVectors returns a vector for every packet type this package both
+// encodes and decodes.
+func TestVectors() ([]TestVector, error) {
+	report := ReceptionReport{
+		SSRC:               0xbc5e9a40,
+		FractionLost:       25,
+		TotalLost:          1000,
+		LastSequenceNumber: 0x46e1,
+		Jitter:             273,
+		LastSenderReport:   0x9f36432,
+		Delay:              150137,
+	}
+	fb := NewTransportLayerCCBuilder(100)
+	fb.SenderSSRC, fb.MediaSSRC, fb.FbPktCount = 0x902f9e2e, 0xbc5e9a40, 7
+	_ = fb.AddReceived(100, 64*referenceTimeResolution+250*time.Microsecond)
+	_ = fb.AddLost(101)
+	_ = fb.AddReceived(102, 64*referenceTimeResolution+2*time.Millisecond)
+
+	packets := []struct {
+		name   string
+		packet Packet
+	}{
+		{"sender report", &SenderReport{
+			SSRC: 0x902f9e2e, NTPTime: 0xda8bd1fcdddda05a, RTPTime: 0xaaf4edd5,
+			PacketCount: 1, OctetCount: 2, Reports: []ReceptionReport{report},
+		}},
+		{"receiver report", &ReceiverReport{SSRC: 0x902f9e2e, Reports: []ReceptionReport{report}}},
+		{"source description", &SourceDescription{Chunks: []SourceDescriptionChunk{{
+			Source: 0x902f9e2e,
+			Items:  []SourceDescriptionItem{{Type: SDESCNAME, Text: "{9c00eb92-1afb-9d49-a47d-91f64eee69f5}"}},
+		}}}},
+		{"goodbye", &Goodbye{Sources: []uint32{0x902f9e2e}, Reason: "shutdown"}},
+		{"application defined", &ApplicationDefined{SubType: 1, SSRC: 0x902f9e2e, Name: "test", Data: []byte{1, 2, 3, 4}}},
+		{"transport layer nack", &TransportLayerNack{
+			SenderSSRC: 0x902f9e2e, MediaSSRC: 0xbc5e9a40,
+			Nacks: []NackPair{{PacketID: 0xaaa, LostPackets: 0x5}},
+		}},
+		{"rapid resynchronization request", &RapidResynchronizationRequest{SenderSSRC: 0x902f9e2e, MediaSSRC: 0xbc5e9a40}},
+		{"transport layer cc", fb.Build()},
+		{"picture loss indication", &PictureLossIndication{SenderSSRC: 0x902f9e2e, MediaSSRC: 0xbc5e9a40}},
+		{"full intra request", &FullIntraRequest{SenderSSRC: 0x902f9e2e, FIR: []FIREntry{{SSRC: 0xbc5e9a40, SequenceNumber: 3}}}},
+		{"receiver estimated maximum bitrate", &ReceiverEstimatedMaximumBitrate{
+			SenderSSRC: 0x902f9e2e, Bitrate: 1500000, SSRCs: []uint32{0xbc5e9a40},
+		}},
+		{"extended report", &ExtendedReport{SenderSSRC: 0x902f9e2e, Reports: []ReportBlock{
+			&DiscardCountReportBlock{IntervalMetric: IntervalMetricInterval, SSRC: 0xbc5e9a40, Discarded: 17},
+		}}},
+		{"cc feedback report", &CCFeedbackReport{
+			SenderSSRC: 0x902f9e2e,
+			ReportBlocks: []CCFeedbackReportBlock{{
+				MediaSSRC:     0xbc5e9a40,
+				BeginSequence: 0x10,
+				MetricBlocks:  []CCFeedbackMetricBlock{{Received: true, ECN: ECNECT1, ArrivalTimeOffset: 0x20}, {}},
+			}},
+			ReportTimestamp: 0x12345678,
+		}},
+		{"rapid acquisition", &RapidAcquisition{SenderSSRC: 0x902f9e2e, MediaSSRC: 0xbc5e9a40, MessageType: RAMSRequest}},
+		{"port mapping", &PortMapping{MessageType: PortMappingRequest, SSRC: 0x902f9e2e, Data: []byte{0, 0, 0, 1}}},
+	}
+
+	out := make([]TestVector, 0, len(packets))
+	for _, p := range packets {
+		v, err := NewTestVector(p.name, p.packet)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
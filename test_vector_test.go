@@ -0,0 +1,115 @@
+package rtcp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTestVectorsRoundTrip(t *testing.T) {
+	vectors, err := TestVectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vectors {
+		for _, style := range []VectorStyle{VectorGo, VectorHex} {
+			parsed, err := ParseTestVector(v.Name, v.Annotated(style))
+			if err != nil {
+				t.Fatalf("%s: parse: %v", v.Name, err)
+			}
+			if !reflect.DeepEqual(parsed.Data, v.Data) {
+				t.Fatalf("%s: bytes %x, want %x", v.Name, parsed.Data, v.Data)
+			}
+			if len(parsed.Packets) != len(v.Packets) {
+				t.Fatalf("%s: %d packets, want %d", v.Name, len(parsed.Packets), len(v.Packets))
+			}
+			if reflect.TypeOf(parsed.Packets[0]) != reflect.TypeOf(v.Packets[0]) {
+				t.Fatalf("%s: decoded as %T, want %T", v.Name, parsed.Packets[0], v.Packets[0])
+			}
+
+			// decoding and encoding again must give the same bytes
+			data, err := Marshal(parsed.Packets)
+			if err != nil {
+				t.Fatalf("%s: marshal: %v", v.Name, err)
+			}
+			if !reflect.DeepEqual(data, v.Data) {
+				t.Fatalf("%s: remarshalled %x, want %x", v.Name, data, v.Data)
+			}
+		}
+	}
+}
+
+func TestTestVectorAnnotated(t *testing.T) {
+	v, err := NewTestVector("pli", &PictureLossIndication{SenderSSRC: 0x902f9e2e, MediaSSRC: 0x902f9e2e})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	goStyle := strings.Join([]string{
+		"// pli",
+		"// v=2, p=0, fmt=1, PSFB, len=2",
+		"0x81, 0xce, 0x00, 0x02,",
+		"// sender=0x902f9e2e",
+		"0x90, 0x2f, 0x9e, 0x2e,",
+		"// media=0x902f9e2e",
+		"0x90, 0x2f, 0x9e, 0x2e,",
+		"",
+	}, "\n")
+	if got := v.Annotated(VectorGo); got != goStyle {
+		t.Fatalf("Annotated(VectorGo) = \n%s\nwant\n%s", got, goStyle)
+	}
+
+	hexStyle := strings.Join([]string{
+		"# pli",
+		"81ce0002  # v=2, p=0, fmt=1, PSFB, len=2",
+		"902f9e2e  # sender=0x902f9e2e",
+		"902f9e2e  # media=0x902f9e2e",
+		"",
+	}, "\n")
+	if got := v.Annotated(VectorHex); got != hexStyle {
+		t.Fatalf("Annotated(VectorHex) = \n%s\nwant\n%s", got, hexStyle)
+	}
+}
+
+func TestParseAnnotatedHex(t *testing.T) {
+	for _, test := range []struct {
+		Name      string
+		Annotated string
+		Want      []byte
+		WantError bool
+	}{
+		{
+			Name:      "go literals",
+			Annotated: "// header\n0x81, 0xc9, 0x0, 0x01, // trailing\n",
+			Want:      []byte{0x81, 0xc9, 0x00, 0x01},
+		},
+		{
+			Name:      "hex words",
+			Annotated: "# header\n80c90001  # rr\r\n DEADBEEF\n",
+			Want:      []byte{0x80, 0xc9, 0x00, 0x01, 0xde, 0xad, 0xbe, 0xef},
+		},
+		{
+			Name:      "bad digit",
+			Annotated: "0xzz",
+			WantError: true,
+		},
+	} {
+		got, err := ParseAnnotatedHex(test.Annotated)
+		if gotError := err != nil; gotError != test.WantError {
+			t.Fatalf("ParseAnnotatedHex %q: error = %v, want error %v", test.Name, err, test.WantError)
+		}
+		if err == nil && !reflect.DeepEqual(got, test.Want) {
+			t.Fatalf("ParseAnnotatedHex %q: %x, want %x", test.Name, got, test.Want)
+		}
+	}
+}
+
+func TestTestVectorPadding(t *testing.T) {
+	// a receiver report padded with 4 bytes
+	data := []byte{0xa0, 0xc9, 0x00, 0x02, 0x90, 0x2f, 0x9e, 0x2e, 0x00, 0x00, 0x00, 0x04}
+	notes := annotateWords(data)
+	want := []string{"v=2, p=1, count=0, RR, len=2", "ssrc=0x902f9e2e", "padding=4"}
+	if !reflect.DeepEqual(notes, want) {
+		t.Fatalf("annotateWords = %q, want %q", notes, want)
+	}
+}
@@ -0,0 +1,242 @@
+package rtcp
+
+import (
+	"context"
+	"net"
+)
+
+// receiveMTU is the size of the buffer datagrams are read into
+const receiveMTU = 1500
+
+// A Transport carries compound RTCP packets between the participants of a
+// session. It lets the Recorder, Scheduler and other components be wired to
+// UDP, RTP-over-QUIC or any other datagram service without custom glue.
+type Transport interface {
+	// WriteRTCP marshals pkts into a single datagram and sends it.
+	WriteRTCP(pkts []Packet) error
+	// ReadRTCP blocks until a datagram carrying RTCP is received and
+	// returns its packets.
+	ReadRTCP() ([]Packet, error)
+	// Close closes the transport, unblocking a pending ReadRTCP.
+	Close() error
+}
+
+// PacketConnTransport is a Transport over a net.PacketConn, such as a UDP
+// socket. Packets are sent to a fixed remote address and accepted from any.
+type PacketConnTransport struct {
+	// Optional protection applied after marshaling and removed before
+	// unmarshaling, such as SRTCP
+	Encryptor Encryptor
+	Decryptor Decryptor
+	// The length of the negotiated authentication tag, used to size
+	// buffers for the SRTCP trailer
+	AuthTagLength int
+	// The source of the arrival times of ReadReceived. If nil, SystemClock
+	// is used.
+	Clock Clock
+
+	conn   net.PacketConn
+	remote net.Addr
+	buf    []byte
+}
+
+var _ Transport = (*PacketConnTransport)(nil) // assert is a Transport
+
+// NewPacketConnTransport creates a Transport sending to remote over conn.
+func NewPacketConnTransport(conn net.PacketConn, remote net.Addr) *PacketConnTransport {
+	return &PacketConnTransport{
+		conn:   conn,
+		remote: remote,
+	}
+}
+
+// WriteRTCP marshals and protects pkts into a single datagram and sends it.
+func (t *PacketConnTransport) WriteRTCP(pkts []Packet) error {
+	data, err := encryptRTCP(t.Encryptor, SRTCPOverhead(t.AuthTagLength), pkts)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.conn.WriteTo(data, t.remote)
+	return err
+}
+
+// ReadRTCP blocks until a datagram is received and returns its packets.
+func (t *PacketConnTransport) ReadRTCP() ([]Packet, error) {
+	packets, _, err := t.read()
+	return packets, err
+}
+
+// ReadReceived is like ReadRTCP, but returns the packets with the time they
+// were read and the address they came from.
+func (t *PacketConnTransport) ReadReceived() ([]ReceivedPacket, error) {
+	packets, addr, err := t.read()
+	if err != nil {
+		return nil, err
+	}
+	return NewReceivedPackets(packets, clockNow(t.Clock), addr), nil
+}
+
+func (t *PacketConnTransport) read() ([]Packet, net.Addr, error) {
+	if size := receiveMTU + SRTCPOverhead(t.AuthTagLength); len(t.buf) < size {
+		t.buf = make([]byte, size)
+	}
+
+	n, addr, err := t.conn.ReadFrom(t.buf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// decoded packets refer to their datagram, so it is copied out of the
+	// buffer the next read reuses
+	packets, err := decryptRTCP(t.Decryptor, append([]byte(nil), t.buf[:n]...))
+	return packets, addr, err
+}
+
+// Close closes the underlying connection.
+func (t *PacketConnTransport) Close() error {
+	return t.conn.Close()
+}
+
+// A DatagramConn sends and receives unreliable datagrams, as a QUIC
+// connection supporting the DATAGRAM extension (RFC 9221) does. The
+// connection type of quic-go satisfies it.
+type DatagramConn interface {
+	SendDatagram(payload []byte) error
+	ReceiveDatagram(ctx context.Context) ([]byte, error)
+}
+
+// DatagramTransport is a Transport framing compound RTCP in QUIC DATAGRAM
+// frames as proposed for RTP over QUIC: each datagram starts with a flow
+// identifier encoded as a QUIC variable-length integer, followed by the
+// packet. Datagrams for other flows, and RTP packets multiplexed on the
+// same flow as described in RFC 5761, are skipped by ReadRTCP.
+type DatagramTransport struct {
+	// Optional protection applied after marshaling and removed before
+	// unmarshaling, such as SRTCP
+	Encryptor Encryptor
+	Decryptor Decryptor
+	// The length of the negotiated authentication tag, used to size
+	// buffers for the SRTCP trailer
+	AuthTagLength int
+
+	conn   DatagramConn
+	flowID uint64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+var _ Transport = (*DatagramTransport)(nil) // assert is a Transport
+
+// NewDatagramTransport creates a Transport sending RTCP for flowID over conn.
+func NewDatagramTransport(conn DatagramConn, flowID uint64) *DatagramTransport {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &DatagramTransport{
+		conn:   conn,
+		flowID: flowID,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// WriteRTCP marshals and protects pkts, prefixes them with the flow identifier and sends
+// them as a single datagram.
+func (t *DatagramTransport) WriteRTCP(pkts []Packet) error {
+	data, err := encryptRTCP(t.Encryptor, SRTCPOverhead(t.AuthTagLength), pkts)
+	if err != nil {
+		return err
+	}
+
+	payload := appendVarint(make([]byte, 0, varintLen(t.flowID)+len(data)), t.flowID)
+	return t.conn.SendDatagram(append(payload, data...))
+}
+
+// ReadRTCP blocks until a datagram carrying RTCP for the transport's flow is
+// received, Close is called, or the connection fails.
+func (t *DatagramTransport) ReadRTCP() ([]Packet, error) {
+	for {
+		payload, err := t.conn.ReceiveDatagram(t.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		flowID, n, err := readVarint(payload)
+		if err != nil {
+			return nil, err
+		}
+		if flowID != t.flowID || !isRTCP(payload[n:]) {
+			continue
+		}
+
+		return decryptRTCP(t.Decryptor, payload[n:])
+	}
+}
+
+// Close cancels a pending ReadRTCP. The QUIC connection is owned by the
+// caller and left open.
+func (t *DatagramTransport) Close() error {
+	t.cancel()
+	return nil
+}
+
+// isRTCP reports whether b looks like an RTCP rather than an RTP packet,
+// using the payload type ranges of RFC 5761, 4
+func isRTCP(b []byte) bool {
+	return len(b) >= 2 && b[1] >= 192 && b[1] <= 223
+}
+
+// varintLen returns the length of the QUIC variable-length integer
+// encoding of v (RFC 9000, 16)
+func varintLen(v uint64) int {
+	switch {
+	case v < 1<<6:
+		return 1
+	case v < 1<<14:
+		return 2
+	case v < 1<<30:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// appendVarint appends the QUIC variable-length integer encoding of v, which
+// must be less than 2^62
+func appendVarint(b []byte, v uint64) []byte {
+	n := varintLen(v)
+	for i := n - 1; i >= 0; i-- {
+		octet := byte(v >> (8 * uint(i)))
+		if i == n-1 {
+			// the two most significant bits encode the length
+			switch n {
+			case 2:
+				octet |= 0x40
+			case 4:
+				octet |= 0x80
+			case 8:
+				octet |= 0xc0
+			}
+		}
+		b = append(b, octet)
+	}
+	return b
+}
+
+// readVarint decodes a QUIC variable-length integer from the start of b
+func readVarint(b []byte) (v uint64, n int, err error) {
+	if len(b) == 0 {
+		return 0, 0, errPacketTooShort
+	}
+
+	n = 1 << (b[0] >> 6)
+	if len(b) < n {
+		return 0, 0, errPacketTooShort
+	}
+
+	v = uint64(b[0] & 0x3f)
+	for i := 1; i < n; i++ {
+		v = v<<8 | uint64(b[i])
+	}
+	return v, n, nil
+}
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strings"
+	"time"
 )
 
 // https://tools.ietf.org/html/draft-holmer-rmcat-transport-wide-cc-extensions-01#page-5
@@ -45,45 +47,81 @@ const (
 	// len of packet status chunk
 	packetStautsChunkLength = 2
 
+	// RunLength is 13 bits wide
+	maxRunLength = (1 << 13) - 1
+
 	// for Status Vector Chunk
 	// https://tools.ietf.org/html/draft-holmer-rmcat-transport-wide-cc-extensions-01#section-3.1.4
 	// if S == typeSymbolSizeOneBit, symbol list will be:
-	// typeSymbolListPacketReceived or typeSymbolListPacketNotReceived
+	// TypePacketNotReceived (0) or TypePacketReceivedSmallDelta (1)
 	typeSymbolSizeOneBit = 0
 
-	typeSymbolListPacketReceived    = 0
-	typeSymbolListPacketNotReceived = 1
-
 	// if S == typeSymbolSizeTwoBit, symbol list will be same as:
 	// https://tools.ietf.org/html/draft-holmer-rmcat-transport-wide-cc-extensions-01#section-3.1.4
 	typeSymbolSizeTwoBit = 1
 )
 
-// type of packet status symbol and recv delta
+// PacketStatusSymbol is the status of a packet in TransportLayerCC
+// feedback, which is also the type of its receive delta.
+// https://tools.ietf.org/html/draft-holmer-rmcat-transport-wide-cc-extensions-01#section-3.1.1
+type PacketStatusSymbol uint16
+
+// PacketStatusSymbol values
 const (
-	// https://tools.ietf.org/html/draft-holmer-rmcat-transport-wide-cc-extensions-01#section-3.1.1
-	typePacketNotReceived = iota
-	typePacketReceivedSmallDelta
-	typePacketReceivedLargeDelta
+	TypePacketNotReceived PacketStatusSymbol = iota
+	TypePacketReceivedSmallDelta
+	TypePacketReceivedLargeDelta
 	// https://tools.ietf.org/html/draft-holmer-rmcat-transport-wide-cc-extensions-01#page-7
 	// see Example 2: "packet received, w/o recv delta"
-	typePacketReceivedWithoutDelta
+	TypePacketReceivedWithoutDelta
 )
 
+func (s PacketStatusSymbol) String() string {
+	switch s {
+	case TypePacketNotReceived:
+		return "not received"
+	case TypePacketReceivedSmallDelta:
+		return "received, small delta"
+	case TypePacketReceivedLargeDelta:
+		return "received, large delta"
+	case TypePacketReceivedWithoutDelta:
+		return "received, no delta"
+	default:
+		return fmt.Sprintf("PacketStatusSymbol(%d)", uint16(s))
+	}
+}
+
 var _ Packet = (*TransportLayerCC)(nil) // assert is a Packet
 
 var (
 	errPacketStatusChunkLength = errors.New("packet status chunk must be 2 bytes")
 	errDeltaExceedLimit        = errors.New("delta exceed limit")
+	errTooManySymbols          = errors.New("status vector chunk has more symbols than fit")
+	errRunLengthTooLong        = errors.New("run length chunk must be shorter than 8192 packets")
+	errPacketStatusCount       = errors.New("packet status chunks cover fewer packets than packet status count")
+	errReferenceTimeRange      = errors.New("reference time must fit in a signed 24-bit value")
 )
 
-// packetStatusChunk has two kinds:
-// RunLengthChunk and StatusVectorChunk
-type iPacketStautsChunk interface {
+// A PacketStatusChunk reports the status of a run of packets in
+// TransportLayerCC feedback. It is a *RunLengthChunk or a *StatusVectorChunk.
+type PacketStatusChunk interface {
 	Marshal() ([]byte, error)
 	Unmarshal(rawPacket []byte) error
+
+	// StatusCount returns the number of packets the chunk reports a status
+	// for.
+	StatusCount() int
+	// StatusAt returns the status of the i-th packet of the chunk, for i
+	// below StatusCount. One bit status vector symbols are reported as
+	// TypePacketNotReceived or TypePacketReceivedSmallDelta.
+	StatusAt(i int) PacketStatusSymbol
 }
 
+var (
+	_ PacketStatusChunk = (*RunLengthChunk)(nil)    // assert is a PacketStatusChunk
+	_ PacketStatusChunk = (*StatusVectorChunk)(nil) // assert is a PacketStatusChunk
+)
+
 // RunLengthChunk T=typeRunLengthChunk
 // 0                   1
 // 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5
@@ -91,14 +129,11 @@ type iPacketStautsChunk interface {
 // |T| S |       Run Length        |
 // +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
 type RunLengthChunk struct {
-	iPacketStautsChunk
-
 	// T = typeRunLengthChunk
 	Type uint16
 
 	// S: type of packet status
-	// kind: typePacketNotReceived or...
-	PacketStatusSymbol uint16
+	PacketStatusSymbol PacketStatusSymbol
 
 	// RunLength: count of S
 	RunLength uint16
@@ -106,19 +141,52 @@ type RunLengthChunk struct {
 
 // Marshal ..
 func (r RunLengthChunk) Marshal() ([]byte, error) {
+	dst, err := r.encode()
+	if err != nil {
+		return nil, err
+	}
+
 	chunk := make([]byte, 2)
+	binary.BigEndian.PutUint16(chunk, dst)
+	return chunk, nil
+}
+
+// encode returns the 16 bits of the chunk
+func (r RunLengthChunk) encode() (uint16, error) {
+	// a longer run would overflow into the T bit and symbol
+	if r.RunLength > maxRunLength {
+		return 0, errRunLengthTooLong
+	}
 
 	// append 1 bit '0'
 	dst := appendNBitsToUint16(0, 1, 0)
 
 	// append 2 bit PacketStatusSymbol
-	dst = appendNBitsToUint16(dst, 2, r.PacketStatusSymbol)
+	dst = appendNBitsToUint16(dst, 2, uint16(r.PacketStatusSymbol))
 
 	// append 13 bit RunLength
 	dst = appendNBitsToUint16(dst, 13, r.RunLength)
 
-	binary.BigEndian.PutUint16(chunk, dst)
-	return chunk, nil
+	return dst, nil
+}
+
+// StatusCount returns the number of packets the chunk reports a status for,
+// which is its RunLength.
+func (r RunLengthChunk) StatusCount() int {
+	return int(r.RunLength)
+}
+
+// StatusAt returns PacketStatusSymbol, which applies to every packet of the
+// run.
+func (r RunLengthChunk) StatusAt(i int) PacketStatusSymbol {
+	return r.PacketStatusSymbol
+}
+
+// PacketCount returns the same as StatusCount.
+//
+// Deprecated: use StatusCount.
+func (r RunLengthChunk) PacketCount() int {
+	return r.StatusCount()
 }
 
 // Unmarshal ..
@@ -130,13 +198,13 @@ func (r *RunLengthChunk) Unmarshal(rawPacket []byte) error {
 	// record type
 	r.Type = typeRunLengthChunk
 
+	chunk := binary.BigEndian.Uint16(rawPacket)
+
 	// get PacketStatusSymbol
-	// r.PacketStatusSymbol = uint16(rawPacket[0] >> 5 & 0x03)
-	r.PacketStatusSymbol = getNBitsFromByte(rawPacket[0], 1, 2)
+	r.PacketStatusSymbol = PacketStatusSymbol(getNBitsFromUint16(chunk, 1, 2))
 
 	// get RunLength
-	// r.RunLength = uint16(rawPacket[0]&0x1F)*256 + uint16(rawPacket[1])
-	r.RunLength = getNBitsFromByte(rawPacket[0], 3, 5)<<8 + uint16(rawPacket[1])
+	r.RunLength = getNBitsFromUint16(chunk, 3, 13)
 	return nil
 }
 
@@ -147,7 +215,6 @@ func (r *RunLengthChunk) Unmarshal(rawPacket []byte) error {
 // |T|S|       symbol list         |
 // +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
 type StatusVectorChunk struct {
-	iPacketStautsChunk
 	// T = typeRunLengthChunk
 	Type uint16
 
@@ -155,15 +222,66 @@ type StatusVectorChunk struct {
 	SymbolSize uint16
 
 	// when SymbolSize = typeSymbolSizeOneBit, SymbolList is 14*1bit:
-	// typeSymbolListPacketReceived or typeSymbolListPacketNotReceived
+	// TypePacketNotReceived or TypePacketReceivedSmallDelta
 	// when SymbolSize = typeSymbolSizeTwoBit, SymbolList is 7*2bit:
-	// typePacketNotReceived typePacketReceivedSmallDelta typePacketReceivedLargeDelta or typePacketReserved
-	SymbolList []uint16
+	// any PacketStatusSymbol
+	SymbolList []PacketStatusSymbol
+}
+
+// StatusCount returns the number of packets the chunk reports a status for,
+// which is the length of SymbolList. At the end of feedback a chunk may
+// describe fewer packets than it has room for; the remaining symbols are
+// encoded as not received and don't count as packets.
+func (r StatusVectorChunk) StatusCount() int {
+	return len(r.SymbolList)
+}
+
+// StatusAt returns the status of the i-th packet. One bit symbols are
+// reported as TypePacketNotReceived or TypePacketReceivedSmallDelta.
+func (r StatusVectorChunk) StatusAt(i int) PacketStatusSymbol {
+	s := r.SymbolList[i]
+	if r.SymbolSize == typeSymbolSizeOneBit {
+		// a set bit means received
+		if s == 1 {
+			return TypePacketReceivedSmallDelta
+		}
+		return TypePacketNotReceived
+	}
+	return s
+}
+
+// PacketCount returns the same as StatusCount.
+//
+// Deprecated: use StatusCount.
+func (r StatusVectorChunk) PacketCount() int {
+	return r.StatusCount()
+}
+
+// capacity returns the number of symbols the chunk has room for
+func (r StatusVectorChunk) capacity() int {
+	if r.SymbolSize == typeSymbolSizeOneBit {
+		return oneBitVectorSymbols
+	}
+	return twoBitVectorSymbols
 }
 
 // Marshal ..
 func (r StatusVectorChunk) Marshal() ([]byte, error) {
+	dst, err := r.encode()
+	if err != nil {
+		return nil, err
+	}
+
 	chunk := make([]byte, 2)
+	binary.BigEndian.PutUint16(chunk, dst)
+	return chunk, nil
+}
+
+// encode returns the 16 bits of the chunk
+func (r StatusVectorChunk) encode() (uint16, error) {
+	if len(r.SymbolList) > r.capacity() {
+		return 0, errTooManySymbols
+	}
 
 	// set T  SymbolSize  and  SymbolList(bit2-7)
 	// chunk[0] = 1<<7 + r.SymbolSize<<6 + uint8(r.SymbolList>>8)
@@ -175,19 +293,18 @@ func (r StatusVectorChunk) Marshal() ([]byte, error) {
 	dst = appendNBitsToUint16(dst, 1, r.SymbolSize)
 
 	// append 14 bit SymbolList
+	width := uint(1)
+	if r.SymbolSize == typeSymbolSizeTwoBit {
+		width = 2
+	}
 	for _, s := range r.SymbolList {
-		if r.SymbolSize == typeSymbolSizeOneBit {
-			dst = appendNBitsToUint16(dst, 1, s)
-		}
-		if r.SymbolSize == typeSymbolSizeTwoBit {
-			dst = appendNBitsToUint16(dst, 2, s)
-		}
+		dst = appendNBitsToUint16(dst, width, uint16(s))
 	}
 
-	binary.BigEndian.PutUint16(chunk, dst)
-	// set SymbolList(bit8-15)
-	// chunk[1] = uint8(r.SymbolList) & 0x0f
-	return chunk, nil
+	// unused symbols are not received
+	dst <<= width * uint(r.capacity()-len(r.SymbolList))
+
+	return dst, nil
 }
 
 // Unmarshal ..
@@ -196,29 +313,19 @@ func (r *StatusVectorChunk) Unmarshal(rawPacket []byte) error {
 		return errPacketStatusChunkLength
 	}
 
+	chunk := binary.BigEndian.Uint16(rawPacket)
+
 	r.Type = typeStatusVectorChunk
-	r.SymbolSize = getNBitsFromByte(rawPacket[0], 1, 1)
+	r.SymbolSize = getNBitsFromUint16(chunk, 1, 1)
 
-	if r.SymbolSize == typeSymbolSizeOneBit {
-		for i := uint16(0); i < 6; i++ {
-			r.SymbolList = append(r.SymbolList, getNBitsFromByte(rawPacket[0], 2+i, 1))
-		}
-		for i := uint16(0); i < 8; i++ {
-			r.SymbolList = append(r.SymbolList, getNBitsFromByte(rawPacket[1], i, 1))
-		}
-		return nil
-	}
+	// shift the symbols out of the 14 bit list, MSB first
+	width := uint(1)
 	if r.SymbolSize == typeSymbolSizeTwoBit {
-		for i := uint16(0); i < 3; i++ {
-			r.SymbolList = append(r.SymbolList, getNBitsFromByte(rawPacket[0], 2+i*2, 2))
-		}
-		for i := uint16(0); i < 4; i++ {
-			r.SymbolList = append(r.SymbolList, getNBitsFromByte(rawPacket[1], i*2, 2))
-		}
-		return nil
+		width = 2
+	}
+	for begin := uint(2); begin < 16; begin += width {
+		r.SymbolList = append(r.SymbolList, PacketStatusSymbol(getNBitsFromUint16(chunk, begin, width)))
 	}
-
-	r.SymbolSize = getNBitsFromByte(rawPacket[0], 2, 6)<<8 + uint16(rawPacket[1])
 	return nil
 }
 
@@ -232,31 +339,76 @@ const (
 // big delta is 2 bytes: [-8192.0, 8191.75]ms = [-8192000, 8191750]us = [-32768, 32767]*250us
 // https://tools.ietf.org/html/draft-holmer-rmcat-transport-wide-cc-extensions-01#section-3.1.5
 type RecvDelta struct {
-	Type uint16
+	// TypePacketReceivedSmallDelta or TypePacketReceivedLargeDelta
+	Type PacketStatusSymbol
 	// us
 	Delta int64
 }
 
+// Rounding selects how a duration is quantized to the 250us resolution of a
+// RecvDelta.
+type Rounding int
+
+const (
+	// RoundingTruncate drops any remainder, rounding toward zero.
+	RoundingTruncate Rounding = iota
+	// RoundingNearest rounds to the nearest multiple of 250us, with halves
+	// rounded away from zero. This is what libwebrtc's feedback encoder does.
+	RoundingNearest
+)
+
+// DeltaDuration returns the receive delta as a time.Duration
+func (r RecvDelta) DeltaDuration() time.Duration {
+	return time.Duration(r.Delta) * time.Microsecond
+}
+
+// SetDeltaDuration sets the receive delta from d, quantized to a multiple of
+// 250us using the given rounding. Callers that derive consecutive deltas from
+// absolute arrival times should subtract DeltaDuration() rather than d from
+// the next arrival, so quantization errors don't accumulate.
+func (r *RecvDelta) SetDeltaDuration(d time.Duration, rounding Rounding) {
+	const tick = delta250us * time.Microsecond
+
+	if rounding == RoundingNearest {
+		if d < 0 {
+			d -= tick / 2
+		} else {
+			d += tick / 2
+		}
+	}
+
+	r.Delta = int64(d/tick) * delta250us
+}
+
 // Marshal ..
 func (r RecvDelta) Marshal() ([]byte, error) {
+	deltaChunk := make([]byte, 2)
+	n, err := r.marshalTo(deltaChunk)
+	if err != nil {
+		return nil, err
+	}
+	return deltaChunk[:n], nil
+}
+
+// marshalTo encodes the delta into b, which must have room for 2 bytes, and
+// returns the number of bytes written
+func (r RecvDelta) marshalTo(b []byte) (int, error) {
 	delta := r.Delta / delta250us
 
 	//small delta
-	if r.Type == typePacketReceivedSmallDelta && delta >= 0 && delta <= math.MaxUint8 {
-		deltaChunk := make([]byte, 1)
-		deltaChunk[0] = byte(delta)
-		return deltaChunk, nil
+	if r.Type == TypePacketReceivedSmallDelta && delta >= 0 && delta <= math.MaxUint8 {
+		b[0] = byte(delta)
+		return 1, nil
 	}
 
 	//big delta
-	if r.Type == typePacketReceivedLargeDelta && delta >= math.MinInt16 && delta <= math.MaxInt16 {
-		deltaChunk := make([]byte, 2)
-		binary.BigEndian.PutUint16(deltaChunk, uint16(delta))
-		return deltaChunk, nil
+	if r.Type == TypePacketReceivedLargeDelta && delta >= math.MinInt16 && delta <= math.MaxInt16 {
+		binary.BigEndian.PutUint16(b, uint16(delta))
+		return 2, nil
 	}
 
 	//overflow
-	return nil, errDeltaExceedLimit
+	return 0, errDeltaExceedLimit
 }
 
 // Unmarshal ..
@@ -269,13 +421,14 @@ func (r *RecvDelta) Unmarshal(rawPacket []byte) error {
 	}
 
 	if chunkLen == 1 {
-		r.Type = typePacketReceivedSmallDelta
+		r.Type = TypePacketReceivedSmallDelta
 		r.Delta = delta250us * int64(rawPacket[0])
 		return nil
 	}
 
-	r.Type = typePacketReceivedLargeDelta
-	r.Delta = delta250us * int64(binary.BigEndian.Uint16(rawPacket))
+	// large deltas are signed
+	r.Type = TypePacketReceivedLargeDelta
+	r.Delta = delta250us * int64(int16(binary.BigEndian.Uint16(rawPacket)))
 	return nil
 }
 
@@ -291,7 +444,8 @@ const (
 // TransportLayerCC for sender-BWE
 // https://tools.ietf.org/html/draft-holmer-rmcat-transport-wide-cc-extensions-01#page-5
 type TransportLayerCC struct {
-	// header
+	// Header is filled in by Unmarshal. Marshal ignores its value and
+	// computes the header from the packet contents.
 	Header Header
 
 	// SSRC of sender
@@ -306,54 +460,82 @@ type TransportLayerCC struct {
 	// PacketStatusCount
 	PacketStatusCount uint16
 
-	// ReferenceTime
+	// ReferenceTime in multiples of 64ms, the 24 bits of the field. The
+	// original draft makes it unsigned; newer libwebrtc builds and RFC 8888
+	// style senders treat it as signed, see ReferenceTimeSigned.
 	ReferenceTime uint32
 
 	// FbPktCount
 	FbPktCount uint8
 
 	// PacketChunks
-	PacketChunks []iPacketStautsChunk
+	PacketChunks []PacketStatusChunk
 
 	// RecvDeltas
 	RecvDeltas []*RecvDelta
 }
 
-// Header returns the Header associated with this packet.
-// func (t *TransportLayerCC) Header() Header {
-// return t.Header
-// return Header{
-// Padding: true,
-// Count:   FormatTCC,
-// Type:    TypeTransportSpecificFeedback,
-// // https://tools.ietf.org/html/rfc4585#page-33
-// Length: uint16((t.len() / 4) - 1),
-// }
-// }
+// packetHeader computes the Header for this packet from its contents, so callers
+// don't need to keep TransportLayerCC.Header in sync before calling Marshal.
+// https://tools.ietf.org/html/rfc4585#page-33
+func (t *TransportLayerCC) packetHeader() Header {
+	n := t.len()
+	return Header{
+		Padding: n != t.unpaddedLen(),
+		Count:   FormatTCC,
+		Type:    TypeTransportSpecificFeedback,
+		Length:  uint16((n / 4) - 1),
+	}
+}
 
-// total bytes with padding
-func (t *TransportLayerCC) len() int {
+// ReferenceTimeSigned returns the reference time decoded as a signed 24-bit
+// two's complement value, in multiples of 64ms, as newer libwebrtc builds
+// read it.
+func (t *TransportLayerCC) ReferenceTimeSigned() int32 {
+	return int32(t.ReferenceTime<<8) >> 8
+}
+
+// SetReferenceTimeSigned sets the reference time to the signed value ref,
+// in multiples of 64ms, for receivers that decode it as signed. It fails if
+// ref doesn't fit in 24 bits.
+func (t *TransportLayerCC) SetReferenceTimeSigned(ref int32) error {
+	if ref < -1<<23 || ref >= 1<<23 {
+		return errReferenceTimeRange
+	}
+	t.ReferenceTime = uint32(ref) & referenceTimeMask
+	return nil
+}
+
+// reference returns the reference time as a duration, decoding it as signed
+// if signed is set
+func (t *TransportLayerCC) reference(signed bool) time.Duration {
+	if signed {
+		return time.Duration(t.ReferenceTimeSigned()) * referenceTimeResolution
+	}
+	return time.Duration(t.ReferenceTime) * referenceTimeResolution
+}
+
+// total bytes without padding
+func (t *TransportLayerCC) unpaddedLen() int {
 	n := headerLength + packetChunkOffset + len(t.PacketChunks)*2
 	for _, d := range t.RecvDeltas {
-		delta := d.Delta / delta250us
-
-		// small delta
-		if delta >= 0 && delta <= math.MaxUint8 {
+		// the encoded size follows the type, as in RecvDelta.Marshal
+		switch d.Type {
+		case TypePacketReceivedSmallDelta:
 			n++
-			// big delta
-		} else if delta >= math.MinInt16 && delta <= math.MaxInt16 {
+		case TypePacketReceivedLargeDelta:
 			n += 2
 		}
 	}
-
-	// has padding
-	if n%4 != 0 {
-		n = (n/4 + 1) * 4
-	}
-
 	return n
 }
 
+// total bytes with padding
+func (t *TransportLayerCC) len() int {
+	n := t.unpaddedLen()
+	return n + getPadding(n)
+}
+
 func (t TransportLayerCC) String() string {
 	out := fmt.Sprintf("TransportLayerCC:\n\tHeader %v\n", t.Header)
 	out += fmt.Sprintf("TransportLayerCC:\n\tSender Ssrc %d\n", t.SenderSSRC)
@@ -374,8 +556,62 @@ func (t TransportLayerCC) String() string {
 	return out
 }
 
-// Marshal encodes the TransportLayerCC in binary
+// Marshal encodes the TransportLayerCC in binary. It fails if a chunk or
+// receive delta can't be encoded, naming the index of the offending one.
 func (t TransportLayerCC) Marshal() ([]byte, error) {
+	return t.marshal()
+}
+
+// MarshalPermissive is like Marshal, but reports packets whose receive delta
+// can't be encoded as received without a delta instead of failing.
+func (t TransportLayerCC) MarshalPermissive() ([]byte, error) {
+	return t.withoutInvalidDeltas().marshal()
+}
+
+// withoutInvalidDeltas returns a copy of t with the receive deltas that fail
+// to marshal dropped, and their packets re-encoded as received without delta
+func (t TransportLayerCC) withoutInvalidDeltas() TransportLayerCC {
+	valid := make([]bool, len(t.RecvDeltas))
+	allValid := true
+	for i, delta := range t.RecvDeltas {
+		_, err := delta.Marshal()
+		valid[i] = err == nil
+		allValid = allValid && valid[i]
+	}
+	if allValid {
+		return t
+	}
+
+	var symbols []PacketStatusSymbol
+	deltas := make([]*RecvDelta, 0, len(t.RecvDeltas))
+	deltaIndex := 0
+	t.forEachStatus(func(seq uint16, symbol PacketStatusSymbol) {
+		if (symbol == TypePacketReceivedSmallDelta || symbol == TypePacketReceivedLargeDelta) && deltaIndex < len(t.RecvDeltas) {
+			if valid[deltaIndex] {
+				deltas = append(deltas, t.RecvDeltas[deltaIndex])
+			} else {
+				symbol = TypePacketReceivedWithoutDelta
+			}
+			deltaIndex++
+		}
+		symbols = append(symbols, symbol)
+	})
+
+	t.PacketChunks = encodeStatusChunks(symbols)
+	t.RecvDeltas = deltas
+	return t
+}
+
+func (t TransportLayerCC) marshal() ([]byte, error) {
+	covered := 0
+	for _, chunk := range t.PacketChunks {
+		covered += chunk.StatusCount()
+	}
+	if covered < int(t.PacketStatusCount) {
+		return nil, errPacketStatusCount
+	}
+
+	t.Header = t.packetHeader()
 	header, err := t.Header.Marshal()
 	if err != nil {
 		return nil, err
@@ -388,31 +624,37 @@ func (t TransportLayerCC) Marshal() ([]byte, error) {
 	ReferenceTimeAndFbPktCount := appendNBitsToUint32(0, 24, t.ReferenceTime)
 	ReferenceTimeAndFbPktCount = appendNBitsToUint32(ReferenceTimeAndFbPktCount, 8, uint32(t.FbPktCount))
 	binary.BigEndian.PutUint32(payload[referenceTimeOffset:], ReferenceTimeAndFbPktCount)
-	dumpBinary(payload)
 	for i, chunk := range t.PacketChunks {
-		b, err := chunk.Marshal()
-		if err == nil {
-			copy(payload[packetChunkOffset+i*2:], b)
+		if err := marshalChunkTo(payload[packetChunkOffset+i*2:], chunk); err != nil {
+			return nil, fmt.Errorf("rtcp: packet chunk %d: %w", i, err)
 		}
 	}
-	dumpBinary(payload)
+	// small and large deltas may be mixed, so track the offset as we go
+	offset := packetChunkOffset + len(t.PacketChunks)*2
+	var deltaBuf [2]byte
 	for i, delta := range t.RecvDeltas {
-		b, err := delta.Marshal()
-		if err == nil {
-			if delta.Type == typePacketReceivedSmallDelta {
-				copy(payload[packetChunkOffset+len(t.PacketChunks)*2+i:], b)
-			}
-			if delta.Type == typePacketReceivedLargeDelta {
-				copy(payload[packetChunkOffset+len(t.PacketChunks)*2+i*2:], b)
-			}
+		// the payload is sized by delta type, so a delta of unknown type
+		// may not fit; encode into a scratch buffer first
+		n, err := delta.marshalTo(deltaBuf[:])
+		if err != nil {
+			return nil, fmt.Errorf("rtcp: recv delta %d: %w", i, err)
 		}
+		offset += copy(payload[offset:], deltaBuf[:n])
+	}
+
+	// the last octet of padding holds the number of padding octets
+	// https://tools.ietf.org/html/rfc3550#section-6.4.1
+	if t.Header.Padding {
+		payload[len(payload)-1] = uint8(t.len() - t.unpaddedLen())
 	}
-	dumpBinary(payload)
 
 	return append(header, payload...), nil
 }
 
-// Unmarshal ..
+// Unmarshal decodes the TransportLayerCC from binary. The chunks and
+// receive deltas left in the capacity of PacketChunks and RecvDeltas, e.g.
+// by a previous Unmarshal, are decoded into again and only what's missing is
+// allocated, so they must not be shared with other packets.
 func (t *TransportLayerCC) Unmarshal(rawPacket []byte) error {
 	if len(rawPacket) < (headerLength + ssrcLength) {
 		return errPacketTooShort
@@ -424,13 +666,13 @@ func (t *TransportLayerCC) Unmarshal(rawPacket []byte) error {
 
 	// https://tools.ietf.org/html/rfc4585#page-33
 	// header's length + payload's length
-	totalLength := 4 * (t.Header.Length + 1)
+	total := t.Header.size()
 
-	if totalLength <= headerLength+packetChunkOffset {
+	if total < headerLength+packetChunkOffset {
 		return errPacketTooShort
 	}
 
-	if len(rawPacket) < int(totalLength) {
+	if len(rawPacket) < total {
 		return errPacketTooShort
 	}
 
@@ -445,70 +687,153 @@ func (t *TransportLayerCC) Unmarshal(rawPacket []byte) error {
 	t.ReferenceTime = get24BitsFromBytes(rawPacket[headerLength+referenceTimeOffset : headerLength+referenceTimeOffset+3])
 	t.FbPktCount = rawPacket[headerLength+fbPktCountOffset : headerLength+fbPktCountOffset+1][0]
 
-	packetStautsPos := uint16(headerLength + packetChunkOffset)
-	for i := uint16(0); i < t.PacketStatusCount; i++ {
-		if packetStautsPos > totalLength {
-			return errPacketTooShort
+	// PacketStatusCount counts packets, not chunks: read chunks until they
+	// cover that many packets. The chunks are counted first, so their
+	// storage can be allocated at once.
+	counts, err := countPacketStatusChunks(rawPacket[:total], t.PacketStatusCount)
+	if err != nil {
+		return err
+	}
+	// the count comes from the packet, so it's checked against the deltas
+	// the packet can hold before storage is allocated for them
+	if !counts.deltasFit(rawPacket[:total]) {
+		return errPacketTooShort
+	}
+
+	// chunks and deltas left in the capacity of the slices by a previous
+	// Unmarshal are decoded into again; only what's missing is allocated
+	prevChunks := t.PacketChunks[:cap(t.PacketChunks)]
+	prevDeltas := t.RecvDeltas[:cap(t.RecvDeltas)]
+	if cap(t.PacketChunks) < counts.runs+counts.vectors {
+		t.PacketChunks = make([]PacketStatusChunk, 0, counts.runs+counts.vectors)
+	}
+	if cap(t.RecvDeltas) < counts.deltas {
+		t.RecvDeltas = make([]*RecvDelta, 0, counts.deltas)
+	}
+	t.PacketChunks = t.PacketChunks[:0]
+	t.RecvDeltas = t.RecvDeltas[:0]
+	var runs []RunLengthChunk
+	var vectors []StatusVectorChunk
+	var symbols []PacketStatusSymbol
+	var deltas []RecvDelta
+	runsLeft, vectorsLeft := counts.runs, counts.vectors
+	addDelta := func(typ PacketStatusSymbol) {
+		i := len(t.RecvDeltas)
+		var d *RecvDelta
+		if i < len(prevDeltas) {
+			d = prevDeltas[i]
+		}
+		if d == nil {
+			if len(deltas) == 0 {
+				deltas = make([]RecvDelta, counts.deltas-i)
+			}
+			d = &deltas[0]
+			deltas = deltas[1:]
 		}
-		typ := getNBitsFromByte(rawPacket[packetStautsPos : packetStautsPos+1][0], 0, 1)
-		var iPacketStauts iPacketStautsChunk
+		*d = RecvDelta{Type: typ}
+		t.RecvDeltas = append(t.RecvDeltas, d)
+	}
+
+	packetStautsPos := headerLength + packetChunkOffset
+	for processed := 0; processed < int(t.PacketStatusCount); {
+		remaining := int(t.PacketStatusCount) - processed
+
+		var prev PacketStatusChunk
+		if i := len(t.PacketChunks); i < len(prevChunks) {
+			prev = prevChunks[i]
+		}
+		typ := getNBitsFromByte(rawPacket[packetStautsPos], 0, 1)
+		var iPacketStauts PacketStatusChunk
 		switch typ {
 		case typeRunLengthChunk:
-			packetStauts := &RunLengthChunk{Type: typ}
+			packetStauts, _ := prev.(*RunLengthChunk)
+			if packetStauts == nil {
+				if len(runs) == 0 {
+					runs = make([]RunLengthChunk, runsLeft)
+				}
+				packetStauts = &runs[0]
+				runs = runs[1:]
+			}
+			runsLeft--
+			*packetStauts = RunLengthChunk{Type: typ}
 			iPacketStauts = packetStauts
-			err := packetStauts.Unmarshal(rawPacket[packetStautsPos : packetStautsPos+2])
+			err = packetStauts.Unmarshal(rawPacket[packetStautsPos : packetStautsPos+2])
 			if err != nil {
 				return err
 			}
-			if packetStauts.PacketStatusSymbol == typePacketReceivedSmallDelta ||
-				packetStauts.PacketStatusSymbol == typePacketReceivedLargeDelta {
-				recvDelta := &RecvDelta{Type: packetStauts.PacketStatusSymbol}
-				for j := uint16(0); j < packetStauts.RunLength; j++ {
-					t.RecvDeltas = append(t.RecvDeltas, recvDelta)
+
+			n := packetStauts.StatusCount()
+			if n > remaining {
+				n = remaining
+			}
+			if packetStauts.PacketStatusSymbol == TypePacketReceivedSmallDelta ||
+				packetStauts.PacketStatusSymbol == TypePacketReceivedLargeDelta {
+				for j := 0; j < n; j++ {
+					addDelta(packetStauts.PacketStatusSymbol)
 				}
 			}
+			processed += n
 		case typeStatusVectorChunk:
-			packetStauts := &StatusVectorChunk{Type: typ}
+			packetStauts, _ := prev.(*StatusVectorChunk)
+			if packetStauts == nil || cap(packetStauts.SymbolList) < oneBitVectorSymbols {
+				if len(vectors) == 0 {
+					vectors = make([]StatusVectorChunk, vectorsLeft)
+					symbols = make([]PacketStatusSymbol, vectorsLeft*oneBitVectorSymbols)
+				}
+				packetStauts = &vectors[0]
+				vectors = vectors[1:]
+				packetStauts.SymbolList = symbols[:0:oneBitVectorSymbols]
+				symbols = symbols[oneBitVectorSymbols:]
+			}
+			vectorsLeft--
+			*packetStauts = StatusVectorChunk{Type: typ, SymbolList: packetStauts.SymbolList[:0]}
 			iPacketStauts = packetStauts
-			err := packetStauts.Unmarshal(rawPacket[packetStautsPos : packetStautsPos+2])
+			err = packetStauts.Unmarshal(rawPacket[packetStautsPos : packetStautsPos+2])
 			if err != nil {
 				return err
 			}
+
+			// symbols past the end of the feedback don't describe packets
+			if len(packetStauts.SymbolList) > remaining {
+				packetStauts.SymbolList = packetStauts.SymbolList[:remaining]
+			}
 			if packetStauts.SymbolSize == typeSymbolSizeOneBit {
 				for j := 0; j < len(packetStauts.SymbolList); j++ {
-					if packetStauts.SymbolList[j] == typePacketReceivedSmallDelta {
-						recvDelta := &RecvDelta{Type: typePacketReceivedSmallDelta}
-						t.RecvDeltas = append(t.RecvDeltas, recvDelta)
+					if packetStauts.SymbolList[j] == TypePacketReceivedSmallDelta {
+						addDelta(TypePacketReceivedSmallDelta)
 					}
 				}
 			}
 			if packetStauts.SymbolSize == typeSymbolSizeTwoBit {
 				for j := 0; j < len(packetStauts.SymbolList); j++ {
-					if packetStauts.SymbolList[j] == typePacketReceivedSmallDelta || packetStauts.SymbolList[j] == typePacketReceivedLargeDelta {
-						recvDelta := &RecvDelta{Type: packetStauts.SymbolList[j]}
-						t.RecvDeltas = append(t.RecvDeltas, recvDelta)
+					if packetStauts.SymbolList[j] == TypePacketReceivedSmallDelta || packetStauts.SymbolList[j] == TypePacketReceivedLargeDelta {
+						addDelta(packetStauts.SymbolList[j])
 					}
 				}
 			}
+			processed += packetStauts.StatusCount()
 		}
-		packetStautsPos += 2
+		packetStautsPos += packetStautsChunkLength
 		t.PacketChunks = append(t.PacketChunks, iPacketStauts)
 	}
 
-	recvDeltasPos := headerLength + packetChunkOffset + 2*t.PacketStatusCount
+	recvDeltasPos := packetStautsPos
 	for _, delta := range t.RecvDeltas {
-		if recvDeltasPos >= totalLength {
-			return errPacketTooShort
-		}
-		if delta.Type == typePacketReceivedSmallDelta {
-			err := delta.Unmarshal(rawPacket[recvDeltasPos : recvDeltasPos+1])
+		if delta.Type == TypePacketReceivedSmallDelta {
+			if recvDeltasPos+1 > total {
+				return errPacketTooShort
+			}
+			err = delta.Unmarshal(rawPacket[recvDeltasPos : recvDeltasPos+1])
 			if err != nil {
 				return err
 			}
 			recvDeltasPos++
 		}
-		if delta.Type == typePacketReceivedLargeDelta {
-			err := delta.Unmarshal(rawPacket[recvDeltasPos : recvDeltasPos+2])
+		if delta.Type == TypePacketReceivedLargeDelta {
+			if recvDeltasPos+2 > total {
+				return errPacketTooShort
+			}
+			err = delta.Unmarshal(rawPacket[recvDeltasPos : recvDeltasPos+2])
 			if err != nil {
 				return err
 			}
@@ -519,7 +844,245 @@ func (t *TransportLayerCC) Unmarshal(rawPacket []byte) error {
 	return nil
 }
 
+// marshalChunkTo writes the 2 bytes of chunk to b, avoiding the allocation of
+// Marshal for the chunk types of this package
+func marshalChunkTo(b []byte, chunk PacketStatusChunk) error {
+	var (
+		dst uint16
+		err error
+	)
+	switch c := chunk.(type) {
+	case *RunLengthChunk:
+		dst, err = c.encode()
+	case *StatusVectorChunk:
+		dst, err = c.encode()
+	default:
+		var raw []byte
+		if raw, err = chunk.Marshal(); err == nil {
+			copy(b, raw)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint16(b, dst)
+	return nil
+}
+
+// packetStatusChunkCounts holds the number of chunks and receive deltas of a
+// TransportLayerCC
+type packetStatusChunkCounts struct {
+	runs    int
+	vectors int
+	deltas  int
+}
+
+// countPacketStatusChunks counts the chunks needed to cover packetStatusCount
+// packets in rawPacket, and the receive deltas they announce
+func countPacketStatusChunks(rawPacket []byte, packetStatusCount uint16) (packetStatusChunkCounts, error) {
+	return scanPacketStatusChunks(rawPacket, packetStatusCount, nil)
+}
+
+// deltasFit reports whether the receive deltas counted fit in rawPacket
+// after the chunks, at one byte at least each
+func (c packetStatusChunkCounts) deltasFit(rawPacket []byte) bool {
+	chunksEnd := headerLength + packetChunkOffset + (c.runs+c.vectors)*packetStautsChunkLength
+	return c.deltas <= len(rawPacket)-chunksEnd
+}
+
+// scanPacketStatusChunks is like countPacketStatusChunks, and also calls
+// onChunk, if not nil, with the kind of every chunk in order
+func scanPacketStatusChunks(rawPacket []byte, packetStatusCount uint16, onChunk func(run bool)) (packetStatusChunkCounts, error) {
+	var counts packetStatusChunkCounts
+
+	pos := headerLength + packetChunkOffset
+	for processed := 0; processed < int(packetStatusCount); pos += packetStautsChunkLength {
+		if pos+packetStautsChunkLength > len(rawPacket) {
+			return counts, errPacketTooShort
+		}
+		remaining := int(packetStatusCount) - processed
+		chunk := binary.BigEndian.Uint16(rawPacket[pos:])
+
+		run := getNBitsFromUint16(chunk, 0, 1) == typeRunLengthChunk
+		if onChunk != nil {
+			onChunk(run)
+		}
+		if run {
+			counts.runs++
+			symbol := PacketStatusSymbol(getNBitsFromUint16(chunk, 1, 2))
+			n := int(getNBitsFromUint16(chunk, 3, 13))
+			if n > remaining {
+				n = remaining
+			}
+			if symbol == TypePacketReceivedSmallDelta || symbol == TypePacketReceivedLargeDelta {
+				counts.deltas += n
+			}
+			processed += n
+			continue
+		}
+
+		counts.vectors++
+		width, n := uint(1), oneBitVectorSymbols
+		if getNBitsFromUint16(chunk, 1, 1) == typeSymbolSizeTwoBit {
+			width, n = 2, twoBitVectorSymbols
+		}
+		if n > remaining {
+			n = remaining
+		}
+		for i := 0; i < n; i++ {
+			symbol := PacketStatusSymbol(getNBitsFromUint16(chunk, 2+uint(i)*width, width))
+			if symbol == TypePacketReceivedSmallDelta || (width == 2 && symbol == TypePacketReceivedLargeDelta) {
+				counts.deltas++
+			}
+		}
+		processed += n
+	}
+
+	return counts, nil
+}
+
+// forEachStatus calls fn with the transport wide sequence number and status
+// symbol of every packet covered by this feedback, in sequence order. One bit
+// status vector symbols are reported as TypePacketNotReceived or
+// TypePacketReceivedSmallDelta. Iteration stops after PacketStatusCount packets.
+func (t *TransportLayerCC) forEachStatus(fn func(seq uint16, symbol PacketStatusSymbol)) {
+	seq := t.BaseSequenceNumber
+	remaining := t.PacketStatusCount
+	emit := func(symbol PacketStatusSymbol) {
+		if remaining == 0 {
+			return
+		}
+		fn(seq, symbol)
+		seq++
+		remaining--
+	}
+
+	for _, chunk := range t.PacketChunks {
+		for i := 0; i < chunk.StatusCount() && remaining > 0; i++ {
+			emit(chunk.StatusAt(i))
+		}
+	}
+}
+
+// ArrivalTimes returns the arrival time of every packet reported with a receive
+// delta, keyed by transport wide sequence number. Arrival times are expressed
+// as an offset from the ReferenceTime of this feedback. Packets reported as
+// lost or as received without a delta are not present in the map.
+func (t *TransportLayerCC) ArrivalTimes() map[uint16]time.Duration {
+	out := make(map[uint16]time.Duration)
+
+	var offset time.Duration
+	deltaIndex := 0
+	t.forEachStatus(func(seq uint16, symbol PacketStatusSymbol) {
+		if symbol != TypePacketReceivedSmallDelta && symbol != TypePacketReceivedLargeDelta {
+			return
+		}
+		if deltaIndex >= len(t.RecvDeltas) {
+			return
+		}
+		offset += time.Duration(t.RecvDeltas[deltaIndex].Delta) * time.Microsecond
+		deltaIndex++
+		out[seq] = offset
+	})
+
+	return out
+}
+
+// Lost returns the transport wide sequence numbers of the packets this
+// feedback reports as not received, in sequence order.
+func (t *TransportLayerCC) Lost() []uint16 {
+	var out []uint16
+	t.forEachStatus(func(seq uint16, symbol PacketStatusSymbol) {
+		if symbol == TypePacketNotReceived {
+			out = append(out, seq)
+		}
+	})
+	return out
+}
+
+// lossPatternMaxRun is the longest run LossPattern spells out symbol by
+// symbol
+const lossPatternMaxRun = 8
+
+// LossPattern returns a compact picture of the packet statuses of this
+// feedback for logs, with a word per run of packets of the same kind: R for
+// each packet received with a receive delta, L for each one lost, and
+// R(nd) following the Rs of packets received without a delta. Runs longer
+// than 8 packets are written with their length, as in R*120. For example
+// "RRRR L RR LL R(nd)" reports four received packets, a lost one, two
+// received, two lost and one received without a delta.
+func (t *TransportLayerCC) LossPattern() string {
+	var b strings.Builder
+	kind, run := -1, 0
+	flush := func() {
+		if run == 0 {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		letter := "R"
+		if kind == 0 {
+			letter = "L"
+		}
+		if run > lossPatternMaxRun {
+			fmt.Fprintf(&b, "%s*%d", letter, run)
+		} else {
+			b.WriteString(strings.Repeat(letter, run))
+		}
+		if kind == 2 {
+			b.WriteString("(nd)")
+		}
+	}
+	t.forEachStatus(func(_ uint16, symbol PacketStatusSymbol) {
+		// 0 lost, 1 received with a delta, 2 received without
+		k := 1
+		switch symbol {
+		case TypePacketNotReceived:
+			k = 0
+		case TypePacketReceivedWithoutDelta:
+			k = 2
+		}
+		if k != kind {
+			flush()
+			kind, run = k, 0
+		}
+		run++
+	})
+	flush()
+	return b.String()
+}
+
+// PacketStatusCoverage returns the number of packets the status chunks
+// report on, at most PacketStatusCount. For feedback decoded by Unmarshal it
+// is PacketStatusCount, as the chunks must cover that many packets; feedback
+// built by hand may cover fewer. It only adds up the chunks, without
+// iterating the statuses.
+func (t *TransportLayerCC) PacketStatusCoverage() int {
+	n := 0
+	for _, chunk := range t.PacketChunks {
+		n += chunk.StatusCount()
+		if n >= int(t.PacketStatusCount) {
+			return int(t.PacketStatusCount)
+		}
+	}
+	return n
+}
+
+// ReceivedCount returns the number of packets reported as received with a
+// receive delta, which is the number of RecvDeltas Unmarshal decoded. The
+// loss ratio of the feedback is 1 - ReceivedCount/PacketStatusCoverage,
+// unless the sender reports packets as received without a delta.
+func (t *TransportLayerCC) ReceivedCount() int {
+	return len(t.RecvDeltas)
+}
+
 // DestinationSSRC returns an array of SSRC values that this packet refers to.
+//
+// Transport-wide feedback covers every stream sharing the transport, but the
+// packet is addressed to MediaSSRC only. SenderSSRC identifies the sender of
+// the feedback and is not included.
 func (t TransportLayerCC) DestinationSSRC() []uint32 {
 	return []uint32{t.MediaSSRC}
 }
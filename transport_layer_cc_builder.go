@@ -0,0 +1,142 @@
+package rtcp
+
+import (
+	"math"
+	"time"
+)
+
+// A TransportLayerCCBuilder builds a TransportLayerCC packet from the
+// arrival times of the packets it reports on. It picks a small or large
+// receive delta for each packet from its actual inter-arrival time, so
+// deltas that can't be encoded are reported when they're added rather than
+// when the packet is marshaled.
+//
+// As in libwebrtc, the reference time is the arrival of the first received
+// packet rounded down to the 64ms resolution of the field, and the first
+// receive delta is relative to it. Each following delta is relative to the
+// quantized arrival of the previous packet, so rounding errors don't
+// accumulate.
+type TransportLayerCCBuilder struct {
+	SenderSSRC uint32
+	MediaSSRC  uint32
+	FbPktCount uint8
+	// How arrival times are quantized to the 250us resolution of receive
+	// deltas
+	Rounding Rounding
+
+	base         uint16
+	symbols      []PacketStatusSymbol
+	deltas       []*RecvDelta
+	hasReference bool
+	reference    time.Duration
+	// quantized arrival of the previous received packet
+	last time.Duration
+}
+
+// NewTransportLayerCCBuilder creates a TransportLayerCCBuilder for feedback
+// starting at the transport wide sequence number base.
+func NewTransportLayerCCBuilder(base uint16) *TransportLayerCCBuilder {
+	return &TransportLayerCCBuilder{base: base}
+}
+
+// AddReceived reports that the packet with transport wide sequence number
+// seq arrived at the given time on the receiver's clock. Packets skipped
+// since the previous one are reported as lost. Packets must be added in
+// sequence order. If the delta since the previous packet exceeds the range
+// of a large delta, errDeltaExceedLimit is returned and the packet isn't
+// added; it has to go into the next feedback packet.
+func (b *TransportLayerCCBuilder) AddReceived(seq uint16, arrival time.Duration) error {
+	offset, err := b.offset(seq)
+	if err != nil {
+		return err
+	}
+
+	last := b.last
+	if !b.hasReference {
+		last = time.Duration(floorDiv(int64(arrival), int64(referenceTimeResolution))) * referenceTimeResolution
+	}
+
+	delta := &RecvDelta{}
+	delta.SetDeltaDuration(arrival-last, b.Rounding)
+	ticks := delta.Delta / delta250us
+	switch {
+	case ticks >= 0 && ticks <= math.MaxUint8:
+		delta.Type = TypePacketReceivedSmallDelta
+	case ticks >= math.MinInt16 && ticks <= math.MaxInt16:
+		delta.Type = TypePacketReceivedLargeDelta
+	default:
+		return errDeltaExceedLimit
+	}
+
+	if !b.hasReference {
+		b.hasReference = true
+		b.reference = last
+	}
+	b.addLost(offset)
+	b.symbols = append(b.symbols, delta.Type)
+	b.deltas = append(b.deltas, delta)
+	b.last = last + delta.DeltaDuration()
+	return nil
+}
+
+// AddReceivedWithoutDelta reports that the packet with transport wide
+// sequence number seq arrived at an unknown time. Packets skipped since the
+// previous one are reported as lost.
+func (b *TransportLayerCCBuilder) AddReceivedWithoutDelta(seq uint16) error {
+	offset, err := b.offset(seq)
+	if err != nil {
+		return err
+	}
+	b.addLost(offset)
+	b.symbols = append(b.symbols, TypePacketReceivedWithoutDelta)
+	return nil
+}
+
+// AddLost reports the packet with transport wide sequence number seq, and
+// any skipped since the previous one, as lost.
+func (b *TransportLayerCCBuilder) AddLost(seq uint16) error {
+	offset, err := b.offset(seq)
+	if err != nil {
+		return err
+	}
+	b.addLost(offset + 1)
+	return nil
+}
+
+// offset returns the position of seq in the packet status list
+func (b *TransportLayerCCBuilder) offset(seq uint16) (int, error) {
+	offset := int(seq - b.base)
+	if offset < len(b.symbols) {
+		return 0, errStatusOutOfOrder
+	}
+	if offset >= math.MaxUint16 {
+		return 0, errTooManyStatuses
+	}
+	return offset, nil
+}
+
+// addLost pads the packet status list with lost packets up to n entries
+func (b *TransportLayerCCBuilder) addLost(n int) {
+	for len(b.symbols) < n {
+		b.symbols = append(b.symbols, TypePacketNotReceived)
+	}
+}
+
+// Len returns the number of packet statuses added, including lost packets.
+func (b *TransportLayerCCBuilder) Len() int {
+	return len(b.symbols)
+}
+
+// Build returns the feedback packet.
+func (b *TransportLayerCCBuilder) Build() *TransportLayerCC {
+	return &TransportLayerCC{
+		SenderSSRC:         b.SenderSSRC,
+		MediaSSRC:          b.MediaSSRC,
+		BaseSequenceNumber: b.base,
+		PacketStatusCount:  uint16(len(b.symbols)),
+		ReferenceTime:      uint32(b.reference/referenceTimeResolution) & referenceTimeMask,
+		FbPktCount:         b.FbPktCount,
+		PacketChunks:       encodeStatusChunks(b.symbols),
+		RecvDeltas:         b.deltas,
+	}
+}
@@ -0,0 +1,80 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportLayerCCBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewTransportLayerCCBuilder(100)
+	b.SenderSSRC = 1
+	b.MediaSSRC = 2
+	b.FbPktCount = 7
+
+	// the first delta is relative to the reference time, 130ms rounded down
+	// to 128ms
+	assert.NoError(b.AddReceived(100, 130*time.Millisecond))
+	// more than 63.75ms later needs a large delta
+	assert.NoError(b.AddReceived(101, 200*time.Millisecond))
+	// so does arriving earlier than the previous packet
+	assert.NoError(b.AddReceived(103, 190*time.Millisecond))
+	assert.NoError(b.AddLost(104))
+	assert.Equal(5, b.Len())
+
+	assert.Equal(errStatusOutOfOrder, b.AddReceived(104, time.Second))
+	assert.Equal(errStatusOutOfOrder, b.AddLost(103))
+	assert.Equal(errDeltaExceedLimit, b.AddReceived(105, 10*time.Second))
+	assert.Equal(5, b.Len())
+
+	fb := b.Build()
+	assert.Equal(uint32(1), fb.SenderSSRC)
+	assert.Equal(uint32(2), fb.MediaSSRC)
+	assert.Equal(uint8(7), fb.FbPktCount)
+	assert.Equal(uint16(100), fb.BaseSequenceNumber)
+	assert.Equal(uint16(5), fb.PacketStatusCount)
+	assert.Equal(uint32(2), fb.ReferenceTime)
+	assert.Equal([]*RecvDelta{
+		{Type: TypePacketReceivedSmallDelta, Delta: 2000},
+		{Type: TypePacketReceivedLargeDelta, Delta: 70000},
+		{Type: TypePacketReceivedLargeDelta, Delta: -10000},
+	}, fb.RecvDeltas)
+	assert.Equal([]uint16{102, 104}, fb.Lost())
+
+	// the packet marshals without further fixes
+	data, err := fb.Marshal()
+	assert.NoError(err)
+	var decoded TransportLayerCC
+	assert.NoError(decoded.Unmarshal(data))
+	assert.Equal(fb.ArrivalTimes(), decoded.ArrivalTimes())
+}
+
+func TestTransportLayerCCBuilderRounding(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewTransportLayerCCBuilder(0)
+	b.Rounding = RoundingNearest
+	// 200us rounds up to 250us, and the next packet's delta is relative to
+	// the quantized arrival
+	assert.NoError(b.AddReceived(0, 200*time.Microsecond))
+	assert.NoError(b.AddReceived(1, 400*time.Microsecond))
+	fb := b.Build()
+	assert.Equal(int64(250), fb.RecvDeltas[0].Delta)
+	assert.Equal(int64(250), fb.RecvDeltas[1].Delta)
+
+	b = NewTransportLayerCCBuilder(0)
+	assert.NoError(b.AddReceived(0, 200*time.Microsecond))
+	assert.NoError(b.AddReceived(1, 400*time.Microsecond))
+	fb = b.Build()
+	assert.Equal(int64(0), fb.RecvDeltas[0].Delta)
+	assert.Equal(int64(250), fb.RecvDeltas[1].Delta)
+
+	// status counts are limited to 16 bits
+	b = NewTransportLayerCCBuilder(0)
+	assert.Equal(errTooManyStatuses, b.AddLost(65535))
+	assert.NoError(b.AddLost(65534))
+	assert.Equal(65535, b.Len())
+}
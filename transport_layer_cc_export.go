@@ -0,0 +1,135 @@
+package rtcp
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// A TransportLayerCCRow describes one packet covered by TransportLayerCC
+// feedback, flattened for offline analysis.
+type TransportLayerCCRow struct {
+	// The feedback packet reporting on the packet
+	FbPktCount uint8
+	MediaSSRC  uint32
+
+	SequenceNumber uint16
+	Received       bool
+	// When the packet was sent and its size in bytes, if known from a
+	// FeedbackResult; zero otherwise
+	SendTime time.Time
+	Size     int
+	// The arrival time in the receiver's clock and the receive delta to the
+	// previous packet. Only valid if HasArrival is set.
+	Arrival    time.Duration
+	Delta      time.Duration
+	HasArrival bool
+}
+
+// TransportLayerCCRows returns a row for every packet fb covers, in sequence
+// order. If result is the FeedbackResult a TransportLayerCCHistory returned
+// for fb, the send times and sizes of the packets are filled in from it.
+func TransportLayerCCRows(fb *TransportLayerCC, result *FeedbackResult) []TransportLayerCCRow {
+	sent := map[uint16]PacketResult{}
+	if result != nil {
+		for _, r := range result.Results {
+			sent[r.SequenceNumber] = r
+		}
+	}
+
+	var out []TransportLayerCCRow
+	arrival := time.Duration(fb.ReferenceTime) * referenceTimeResolution
+	deltaIndex := 0
+	fb.forEachStatus(func(seq uint16, symbol PacketStatusSymbol) {
+		row := TransportLayerCCRow{
+			FbPktCount:     fb.FbPktCount,
+			MediaSSRC:      fb.MediaSSRC,
+			SequenceNumber: seq,
+			Received:       symbol != TypePacketNotReceived,
+		}
+		if r, ok := sent[seq]; ok {
+			row.SendTime, row.Size = r.SendTime, r.Size
+		}
+		if (symbol == TypePacketReceivedSmallDelta || symbol == TypePacketReceivedLargeDelta) && deltaIndex < len(fb.RecvDeltas) {
+			row.Delta = time.Duration(fb.RecvDeltas[deltaIndex].Delta) * time.Microsecond
+			deltaIndex++
+			arrival += row.Delta
+			row.Arrival = arrival
+			row.HasArrival = true
+		}
+		out = append(out, row)
+	})
+	return out
+}
+
+// transportLayerCCCSVHeader names the columns written by
+// TransportLayerCCCSVWriter
+var transportLayerCCCSVHeader = []string{
+	"fb_pkt_count", "media_ssrc", "seq", "received", "send_time_us", "size", "arrival_us", "delta_us",
+}
+
+// A TransportLayerCCCSVWriter writes TransportLayerCC feedback as CSV, one
+// row per packet, for analysis in tools such as pandas. The columns are
+//
+//	fb_pkt_count  the feedback packet count
+//	media_ssrc    the media SSRC of the feedback, in hex
+//	seq           the transport wide sequence number
+//	received      1 if the packet was received, 0 otherwise
+//	send_time_us  the send time in microseconds since the Unix epoch
+//	size          the size of the packet in bytes
+//	arrival_us    the arrival time in microseconds in the receiver's clock
+//	delta_us      the receive delta to the previous packet in microseconds
+//
+// Fields that aren't known are left empty.
+type TransportLayerCCCSVWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+// NewTransportLayerCCCSVWriter creates a TransportLayerCCCSVWriter writing to
+// w. The header line is written along with the first feedback.
+func NewTransportLayerCCCSVWriter(w io.Writer) *TransportLayerCCCSVWriter {
+	return &TransportLayerCCCSVWriter{w: csv.NewWriter(w)}
+}
+
+// Write writes the rows of fb, see TransportLayerCCRows. result may be nil.
+func (c *TransportLayerCCCSVWriter) Write(fb *TransportLayerCC, result *FeedbackResult) error {
+	if !c.headerWritten {
+		if err := c.w.Write(transportLayerCCCSVHeader); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	record := make([]string, len(transportLayerCCCSVHeader))
+	for _, row := range TransportLayerCCRows(fb, result) {
+		record[0] = strconv.Itoa(int(row.FbPktCount))
+		record[1] = strconv.FormatUint(uint64(row.MediaSSRC), 16)
+		record[2] = strconv.Itoa(int(row.SequenceNumber))
+		record[3] = "0"
+		if row.Received {
+			record[3] = "1"
+		}
+		record[4], record[5] = "", ""
+		if !row.SendTime.IsZero() {
+			record[4] = strconv.FormatInt(row.SendTime.UnixNano()/int64(time.Microsecond), 10)
+			record[5] = strconv.Itoa(row.Size)
+		}
+		record[6], record[7] = "", ""
+		if row.HasArrival {
+			record[6] = strconv.FormatInt(row.Arrival.Microseconds(), 10)
+			record[7] = strconv.FormatInt(row.Delta.Microseconds(), 10)
+		}
+		if err := c.w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered rows to the underlying writer.
+func (c *TransportLayerCCCSVWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,52 @@
+package rtcp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportLayerCCCSVWriter(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewTransportLayerCCBuilder(100)
+	b.MediaSSRC = 0xbeef
+	b.FbPktCount = 3
+	assert.NoError(b.AddReceived(100, 64*time.Millisecond+250*time.Microsecond))
+	assert.NoError(b.AddLost(101))
+	assert.NoError(b.AddReceived(102, 70*time.Millisecond))
+	fb := b.Build()
+
+	sent := time.Unix(10, 0)
+	h := NewTransportLayerCCHistory()
+	h.OnSent(100, 1200, sent)
+	h.OnSent(101, 1100, sent.Add(time.Millisecond))
+	result := h.OnFeedback(fb)
+
+	rows := TransportLayerCCRows(fb, &result)
+	assert.Equal([]TransportLayerCCRow{
+		{FbPktCount: 3, MediaSSRC: 0xbeef, SequenceNumber: 100, Received: true, SendTime: sent, Size: 1200,
+			Arrival: 64*time.Millisecond + 250*time.Microsecond, Delta: 250 * time.Microsecond, HasArrival: true},
+		{FbPktCount: 3, MediaSSRC: 0xbeef, SequenceNumber: 101, SendTime: sent.Add(time.Millisecond), Size: 1100},
+		{FbPktCount: 3, MediaSSRC: 0xbeef, SequenceNumber: 102, Received: true,
+			Arrival: 70 * time.Millisecond, Delta: 5750 * time.Microsecond, HasArrival: true},
+	}, rows)
+
+	var out bytes.Buffer
+	w := NewTransportLayerCCCSVWriter(&out)
+	assert.NoError(w.Write(fb, &result))
+	assert.NoError(w.Write(fb, nil))
+	assert.NoError(w.Flush())
+	assert.Equal(strings.Join([]string{
+		"fb_pkt_count,media_ssrc,seq,received,send_time_us,size,arrival_us,delta_us",
+		"3,beef,100,1,10000000,1200,64250,250",
+		"3,beef,101,0,10001000,1100,,",
+		"3,beef,102,1,,,70000,5750",
+		"3,beef,100,1,,,64250,250",
+		"3,beef,101,0,,,,",
+		"3,beef,102,1,,,70000,5750",
+	}, "\n")+"\n", out.String())
+}
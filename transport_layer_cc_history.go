@@ -0,0 +1,333 @@
+package rtcp
+
+import (
+	"time"
+
+	"github.com/pion/rtcp/seqnum"
+)
+
+// A PacketResult is the outcome of a sent packet, as reported by
+// TransportLayerCC feedback.
+type PacketResult struct {
+	SequenceNumber uint16
+	SendTime       time.Time
+	// Size in bytes, as passed to OnSent
+	Size     int
+	Received bool
+	// The arrival time in the receiver's clock, from the feedback reference
+	// time and receive deltas. Only valid if HasArrival is set; packets
+	// reported as received without a delta have none.
+	Arrival    time.Duration
+	HasArrival bool
+	// The ECN codepoint the packet arrived with. Only CCFeedbackReport
+	// feedback carries it; it's ECNNonECT otherwise.
+	ECN ECN
+}
+
+// A FeedbackResult holds the results of the packets covered by one
+// TransportLayerCC feedback packet, as returned by
+// TransportLayerCCHistory.OnFeedback.
+type FeedbackResult struct {
+	// Results of the packets sent through this history, in sequence order.
+	// Packets the feedback covers that weren't sent through the history are
+	// skipped.
+	Results []PacketResult
+
+	// The number of feedback packets lost between the previous one and this,
+	// detected from gaps in FbPktCount. A bandwidth estimator should discount
+	// the interval rather than treat the packets those covered as lost.
+	MissingFeedback int
+	// Set if this feedback is older than one already seen, i.e. feedback was
+	// reordered on the way back. Its results may also have been reported by
+	// the later feedback.
+	Reordered bool
+	// Set if this feedback has the same FbPktCount as the previous one.
+	Duplicate bool
+	// The number of packets the feedback covers that the history had
+	// already evicted or pruned, i.e. feedback that came too late to be
+	// matched up. See TransportLayerCCHistory.LateFeedback.
+	Pruned int
+}
+
+type sentPacket struct {
+	at   time.Time
+	size int
+}
+
+// A TransportLayerCCHistory remembers the packets a sender stamped with
+// transport wide sequence numbers, and matches them up with the
+// TransportLayerCC feedback that reports on them.
+//
+// Packets are forgotten once feedback reports them as received. Packets
+// never reported on are evicted, oldest first, on every OnSent once the
+// history exceeds MaxPackets or they are older than MaxAge, and in any case
+// once they are half the sequence number space behind the newest packet.
+// Prune drops them by age explicitly, e.g. from a timer while nothing is
+// sent. Feedback arriving for packets already dropped is counted by
+// LateFeedback, which tells whether the limits are too tight for the
+// feedback delay of the path.
+//
+// A TransportLayerCCHistory isn't safe for concurrent use; see
+// SyncTransportLayerCCHistory.
+type TransportLayerCCHistory struct {
+	// The maximum number of packets awaiting feedback. If zero, the history
+	// is only bounded by the sequence number space.
+	MaxPackets int
+	// How long after the newest packet was sent older ones are kept. If
+	// zero, packets don't expire.
+	MaxAge time.Duration
+	// If set, the reference time of feedback is decoded as signed, as newer
+	// libwebrtc builds send it, so arrivals before the epoch of the
+	// receiver's clock come out negative rather than about 12 days late.
+	SignedReferenceTime bool
+	// Logger receives evictions and lost or reordered feedback. If nil,
+	// NopLogger is used.
+	Logger Logger
+	// Clock supplies the current time to Prune, and must be the clock of
+	// the send times passed to OnSent. If nil, SystemClock is used.
+	Clock Clock
+
+	sent      map[int64]sentPacket
+	evictions uint64
+	// one bit per sequence number, set while the packet sent with it was
+	// evicted or pruned without feedback
+	pruned    []uint64
+	late      uint64
+	unwrapper seqnum.Unwrapper
+	oldest    int64
+	newest    int64
+
+	haveFbPktCount bool
+	lastFbPktCount uint8
+}
+
+// NewTransportLayerCCHistory creates an empty TransportLayerCCHistory.
+func NewTransportLayerCCHistory() *TransportLayerCCHistory {
+	return &TransportLayerCCHistory{
+		sent: map[int64]sentPacket{},
+	}
+}
+
+// OnSent records that the packet with transport wide sequence number seq and
+// the given size in bytes was sent at the given time.
+func (h *TransportLayerCCHistory) OnSent(seq uint16, size int, at time.Time) {
+	if h.sent == nil {
+		h.sent = map[int64]sentPacket{}
+	}
+
+	unwrapped := h.unwrapper.Unwrap(seq)
+	h.setPruned(seq, false)
+	if len(h.sent) == 0 {
+		h.oldest, h.newest = unwrapped, unwrapped
+	}
+	h.sent[unwrapped] = sentPacket{at: at, size: size}
+	if unwrapped > h.newest {
+		h.newest = unwrapped
+	}
+	if unwrapped < h.oldest {
+		h.oldest = unwrapped
+	}
+
+	h.evict(at)
+}
+
+// evict drops the oldest packets until the history is within its limits
+func (h *TransportLayerCCHistory) evict(now time.Time) {
+	for ; h.oldest <= h.newest; h.oldest++ {
+		sent, ok := h.sent[h.oldest]
+		if !ok {
+			continue
+		}
+		// packets half the sequence space behind can no longer be told
+		// apart from newer ones
+		if h.newest-h.oldest < 1<<15 &&
+			(h.MaxPackets <= 0 || len(h.sent) <= h.MaxPackets) &&
+			(h.MaxAge <= 0 || now.Sub(sent.at) <= h.MaxAge) {
+			return
+		}
+		if debugChecks {
+			loggerOr(h.Logger).Debug("rtcp: history evicting packet without feedback", "seq", uint16(h.oldest), "held", len(h.sent))
+		}
+		h.drop(h.oldest)
+	}
+}
+
+// Prune drops the packets awaiting feedback that were sent more than
+// olderThan ago, and returns their number. Unlike MaxAge, which is relative
+// to the newest packet, the age is taken from the Clock, so packets are
+// dropped even when nothing more is sent.
+func (h *TransportLayerCCHistory) Prune(olderThan time.Duration) int {
+	now := clockNow(h.Clock)
+	n := 0
+	for unwrapped, sent := range h.sent {
+		if now.Sub(sent.at) > olderThan {
+			h.drop(unwrapped)
+			n++
+		}
+	}
+	if n > 0 && debugChecks {
+		loggerOr(h.Logger).Debug("rtcp: history pruned packets without feedback", "pruned", n, "held", len(h.sent))
+	}
+	return n
+}
+
+// drop forgets the packet unwrapped without feedback
+func (h *TransportLayerCCHistory) drop(unwrapped int64) {
+	delete(h.sent, unwrapped)
+	h.setPruned(uint16(unwrapped), true)
+	h.evictions++
+}
+
+func (h *TransportLayerCCHistory) setPruned(seq uint16, pruned bool) {
+	if h.pruned == nil {
+		if !pruned {
+			return
+		}
+		h.pruned = make([]uint64, (1<<16)/64)
+	}
+	if pruned {
+		h.pruned[seq/64] |= 1 << (seq % 64)
+	} else {
+		h.pruned[seq/64] &^= 1 << (seq % 64)
+	}
+}
+
+// isPruned reports whether the packet sent with seq was dropped without
+// feedback, and counts the late feedback on it
+func (h *TransportLayerCCHistory) isPruned(seq uint16) bool {
+	if h.pruned == nil || h.pruned[seq/64]&(1<<(seq%64)) == 0 {
+		return false
+	}
+	h.late++
+	return true
+}
+
+// Len returns the number of packets awaiting feedback.
+func (h *TransportLayerCCHistory) Len() int {
+	return len(h.sent)
+}
+
+// Evictions returns the number of packets dropped from the history without
+// feedback ever reporting them as received, including those dropped by
+// Prune.
+func (h *TransportLayerCCHistory) Evictions() uint64 {
+	return h.evictions
+}
+
+// LateFeedback returns the number of packet statuses in feedback that
+// referred to packets already evicted or pruned. Feedback reporting a
+// packet twice counts twice.
+func (h *TransportLayerCCHistory) LateFeedback() uint64 {
+	return h.late
+}
+
+// OnFeedback returns the results fb reports for sent packets. Packets
+// reported as received are forgotten; packets reported as lost are kept, in
+// case later feedback reports them as received after all.
+func (h *TransportLayerCCHistory) OnFeedback(fb *TransportLayerCC) FeedbackResult {
+	var out FeedbackResult
+	out.MissingFeedback, out.Reordered, out.Duplicate = h.checkFbPktCount(fb.FbPktCount)
+	switch {
+	case out.MissingFeedback > 0:
+		if debugChecks {
+			loggerOr(h.Logger).Debug("rtcp: feedback lost", "missing", out.MissingFeedback, "fbPktCount", fb.FbPktCount)
+		}
+	case out.Reordered:
+		if debugChecks {
+			loggerOr(h.Logger).Debug("rtcp: feedback reordered", "fbPktCount", fb.FbPktCount)
+		}
+	}
+
+	reference := fb.reference(h.SignedReferenceTime)
+	arrivals := fb.ArrivalTimes()
+	fb.forEachStatus(func(seq uint16, symbol PacketStatusSymbol) {
+		unwrapped := h.unwrapper.Peek(seq)
+		sent, ok := h.sent[unwrapped]
+		if !ok {
+			if h.isPruned(seq) {
+				out.Pruned++
+			}
+			return
+		}
+
+		result := PacketResult{
+			SequenceNumber: seq,
+			SendTime:       sent.at,
+			Size:           sent.size,
+			Received:       symbol != TypePacketNotReceived,
+		}
+		if offset, ok := arrivals[seq]; ok {
+			result.Arrival = reference + offset
+			result.HasArrival = true
+		}
+		if result.Received {
+			delete(h.sent, unwrapped)
+		}
+		out.Results = append(out.Results, result)
+	})
+
+	return out
+}
+
+// OnCCFeedbackReport returns the results the RFC 8888 feedback fb reports
+// for sent packets, like OnFeedback. The RTP sequence numbers of fb are
+// taken as the transport wide sequence numbers of the history, so it suits
+// a history of a single RTP stream, or feedback translated from
+// TransportLayerCC. Arrivals are relative to the report timestamp, in the
+// receiver's clock. fb has no feedback packet count, so MissingFeedback,
+// Reordered and Duplicate aren't set.
+func (h *TransportLayerCCHistory) OnCCFeedbackReport(fb *CCFeedbackReport) FeedbackResult {
+	var out FeedbackResult
+	reference := compactNTPDuration(fb.ReportTimestamp)
+	for _, b := range fb.ReportBlocks {
+		for i, m := range b.MetricBlocks {
+			seq := b.BeginSequence + uint16(i)
+			unwrapped := h.unwrapper.Peek(seq)
+			sent, ok := h.sent[unwrapped]
+			if !ok {
+				if h.isPruned(seq) {
+					out.Pruned++
+				}
+				continue
+			}
+
+			result := PacketResult{
+				SequenceNumber: seq,
+				SendTime:       sent.at,
+				Size:           sent.size,
+				Received:       m.Received,
+			}
+			if m.Received {
+				result.ECN = m.ECN
+				if m.ArrivalTimeOffset < ArrivalTimeOffsetOverRange {
+					result.Arrival = reference - time.Duration(m.ArrivalTimeOffset)*time.Second/1024
+					result.HasArrival = true
+				}
+				delete(h.sent, unwrapped)
+			}
+			out.Results = append(out.Results, result)
+		}
+	}
+	return out
+}
+
+// checkFbPktCount compares the 8 bit feedback packet count with the newest
+// seen so far. Counts less than half the space ahead are newer.
+func (h *TransportLayerCCHistory) checkFbPktCount(count uint8) (missing int, reordered, duplicate bool) {
+	if !h.haveFbPktCount {
+		h.haveFbPktCount = true
+		h.lastFbPktCount = count
+		return 0, false, false
+	}
+
+	diff := count - h.lastFbPktCount
+	switch {
+	case diff == 0:
+		return 0, false, true
+	case diff < 1<<7:
+		h.lastFbPktCount = count
+		return int(diff) - 1, false, false
+	default:
+		return 0, true, false
+	}
+}
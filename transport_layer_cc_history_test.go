@@ -0,0 +1,246 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportLayerCCHistory(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	h := NewTransportLayerCCHistory()
+	for i := 0; i < 4; i++ {
+		h.OnSent(uint16(65534+i), 100+i, start.Add(time.Duration(i)*time.Millisecond))
+	}
+	assert.Equal(4, h.Len())
+
+	// the receiver misses 65535
+	r := NewRecorder(1)
+	r.Record(65534, start.Add(10*time.Millisecond))
+	r.Record(0, start.Add(12*time.Millisecond))
+	r.Record(1, start.Add(13*time.Millisecond))
+	feedback := r.BuildFeedback()
+	assert.Len(feedback, 1)
+
+	result := h.OnFeedback(feedback[0])
+	assert.Equal(0, result.MissingFeedback)
+	assert.False(result.Reordered)
+	assert.Len(result.Results, 4)
+	assert.Equal(PacketResult{
+		SequenceNumber: 65534,
+		SendTime:       start,
+		Size:           100,
+		Received:       true,
+		HasArrival:     true,
+	}, result.Results[0])
+	assert.Equal(PacketResult{
+		SequenceNumber: 65535,
+		SendTime:       start.Add(time.Millisecond),
+		Size:           101,
+	}, result.Results[1])
+	assert.Equal(3*time.Millisecond, result.Results[3].Arrival-result.Results[0].Arrival)
+
+	// the lost packet is kept for later feedback
+	assert.Equal(1, h.Len())
+	late := &TransportLayerCC{
+		BaseSequenceNumber: 65535,
+		PacketStatusCount:  1,
+		FbPktCount:         1,
+		PacketChunks: []PacketStatusChunk{
+			&RunLengthChunk{Type: typeRunLengthChunk, PacketStatusSymbol: TypePacketReceivedWithoutDelta, RunLength: 1},
+		},
+	}
+	result = h.OnFeedback(late)
+	assert.Len(result.Results, 1)
+	assert.True(result.Results[0].Received)
+	assert.False(result.Results[0].HasArrival)
+	assert.Equal(0, h.Len())
+}
+
+func TestTransportLayerCCHistoryFbPktCount(t *testing.T) {
+	assert := assert.New(t)
+
+	h := NewTransportLayerCCHistory()
+	check := func(count uint8) FeedbackResult {
+		return h.OnFeedback(&TransportLayerCC{FbPktCount: count})
+	}
+
+	assert.Equal(FeedbackResult{}, check(254))
+	assert.Equal(FeedbackResult{}, check(255))
+	// wraps around, with 0 and 1 lost
+	assert.Equal(FeedbackResult{MissingFeedback: 2}, check(2))
+	// a lost one shows up late
+	assert.Equal(FeedbackResult{Reordered: true}, check(1))
+	assert.Equal(FeedbackResult{Duplicate: true}, check(2))
+	assert.Equal(FeedbackResult{}, check(3))
+}
+
+func TestTransportLayerCCHistoryForgetsOldPackets(t *testing.T) {
+	h := NewTransportLayerCCHistory()
+	start := time.Unix(0, 0)
+	for i := 0; i < 40000; i++ {
+		h.OnSent(uint16(i), 1, start)
+	}
+	assert.Equal(t, 1<<15, h.Len())
+	assert.Equal(t, uint64(40000-1<<15), h.Evictions())
+}
+
+func TestTransportLayerCCHistoryLimits(t *testing.T) {
+	assert := assert.New(t)
+	start := time.Unix(0, 0)
+
+	h := NewTransportLayerCCHistory()
+	h.MaxPackets = 10
+	for i := 0; i < 25; i++ {
+		h.OnSent(uint16(i), 1, start)
+	}
+	assert.Equal(10, h.Len())
+	assert.Equal(uint64(15), h.Evictions())
+
+	// the newest packets are kept
+	result := h.OnFeedback(&TransportLayerCC{
+		BaseSequenceNumber: 0,
+		PacketStatusCount:  25,
+		PacketChunks: []PacketStatusChunk{&RunLengthChunk{
+			PacketStatusSymbol: TypePacketNotReceived,
+			RunLength:          25,
+		}},
+	})
+	assert.Len(result.Results, 10)
+	assert.Equal(uint16(15), result.Results[0].SequenceNumber)
+
+	h = NewTransportLayerCCHistory()
+	h.MaxAge = time.Second
+	for i := 0; i < 30; i++ {
+		h.OnSent(uint16(i), 1, start.Add(time.Duration(i)*100*time.Millisecond))
+	}
+	// packets 19 to 29 are at most a second older than the newest
+	assert.Equal(11, h.Len())
+	assert.Equal(uint64(19), h.Evictions())
+}
+
+func TestTransportLayerCCHistoryPrune(t *testing.T) {
+	assert := assert.New(t)
+	start := time.Unix(1000, 0)
+	now := start
+
+	h := NewTransportLayerCCHistory()
+	h.Clock = ClockFunc(func() time.Time { return now })
+	for i := 0; i < 10; i++ {
+		h.OnSent(uint16(i), 1, start.Add(time.Duration(i)*100*time.Millisecond))
+	}
+	assert.Equal(0, h.Prune(time.Second))
+
+	// nothing more is sent, so only Prune drops packets
+	now = start.Add(1500 * time.Millisecond)
+	assert.Equal(5, h.Prune(time.Second))
+	assert.Equal(5, h.Len())
+	assert.Equal(uint64(5), h.Evictions())
+
+	lost := func(base, count uint16) *TransportLayerCC {
+		return &TransportLayerCC{
+			BaseSequenceNumber: base,
+			PacketStatusCount:  count,
+			PacketChunks: []PacketStatusChunk{&RunLengthChunk{
+				PacketStatusSymbol: TypePacketNotReceived,
+				RunLength:          count,
+			}},
+		}
+	}
+
+	// packets 3 and 4 were pruned, 5 and 6 are matched, 20 was never sent
+	result := h.OnFeedback(lost(3, 4))
+	assert.Len(result.Results, 2)
+	assert.Equal(2, result.Pruned)
+	result = h.OnFeedback(lost(20, 1))
+	assert.Empty(result.Results)
+	assert.Equal(0, result.Pruned)
+	assert.Equal(uint64(2), h.LateFeedback())
+
+	// packets evicted on insert count as well
+	h.MaxPackets = 5
+	h.OnSent(10, 1, now)
+	result = h.OnFeedback(lost(5, 1))
+	assert.Equal(1, result.Pruned)
+	assert.Equal(uint64(3), h.LateFeedback())
+
+	// and RFC 8888 feedback
+	result = h.OnCCFeedbackReport(&CCFeedbackReport{ReportBlocks: []CCFeedbackReportBlock{{
+		BeginSequence: 0,
+		MetricBlocks:  []CCFeedbackMetricBlock{{}, {}},
+	}}})
+	assert.Equal(2, result.Pruned)
+	assert.Equal(uint64(5), h.LateFeedback())
+
+	// a sequence number sent again is no longer pruned
+	h.MaxPackets = 0
+	h.OnSent(0, 1, now)
+	result = h.OnFeedback(lost(0, 1))
+	assert.Len(result.Results, 1)
+	assert.Equal(0, result.Pruned)
+}
+
+func TestTransportLayerCCHistorySignedReferenceTime(t *testing.T) {
+	fb := &TransportLayerCC{
+		BaseSequenceNumber: 10,
+		PacketStatusCount:  1,
+		PacketChunks: []PacketStatusChunk{
+			&RunLengthChunk{PacketStatusSymbol: TypePacketReceivedSmallDelta, RunLength: 1},
+		},
+		RecvDeltas: []*RecvDelta{{Type: TypePacketReceivedSmallDelta, Delta: 1000}},
+	}
+	assert.NoError(t, fb.SetReferenceTimeSigned(-2))
+
+	for _, signed := range []bool{false, true} {
+		h := NewTransportLayerCCHistory()
+		h.SignedReferenceTime = signed
+		h.OnSent(10, 100, time.Unix(1000, 0))
+		result := h.OnFeedback(fb)
+		assert.Len(t, result.Results, 1)
+
+		want := time.Duration(1<<24-2)*referenceTimeResolution + time.Millisecond
+		if signed {
+			want = -2*referenceTimeResolution + time.Millisecond
+		}
+		assert.Equal(t, want, result.Results[0].Arrival, "signed %v", signed)
+	}
+}
+
+func TestTransportLayerCCHistoryECN(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Unix(1000, 0)
+	h := NewTransportLayerCCHistory()
+	for i := 0; i < 4; i++ {
+		h.OnSent(uint16(65534+i), 100, start.Add(time.Duration(i)*time.Millisecond))
+	}
+
+	// the receiver misses 65535 and sees congestion on 1
+	r := NewRecorder(1)
+	r.MediaSSRC = 2
+	r.RecordECN(65534, ECNECT1, start.Add(10*time.Millisecond))
+	r.RecordECN(0, ECNECT1, start.Add(12*time.Millisecond))
+	r.RecordECN(1, ECNCE, start.Add(20*time.Millisecond))
+	fb := r.BuildCCFeedbackReport(start.Add(30 * time.Millisecond))
+	assert.Nil(r.BuildCCFeedbackReport(start.Add(time.Second)))
+
+	data, err := fb.Marshal()
+	assert.NoError(err)
+	var decoded CCFeedbackReport
+	assert.NoError(decoded.Unmarshal(data))
+	assert.Equal(*fb, decoded)
+
+	result := h.OnCCFeedbackReport(&decoded)
+	assert.Len(result.Results, 4)
+	assert.Equal(ECNECT1, result.Results[0].ECN)
+	assert.False(result.Results[1].Received)
+	assert.Equal(ECNNonECT, result.Results[1].ECN)
+	assert.Equal(ECNCE, result.Results[3].ECN)
+	assert.True(result.Results[3].HasArrival)
+	// arrival time offsets have a resolution of 1/1024 s
+	assert.InDelta(10*time.Millisecond, result.Results[3].Arrival-result.Results[0].Arrival, float64(time.Millisecond))
+	assert.Equal(1, h.Len())
+}
@@ -0,0 +1,204 @@
+package rtcp
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pion/rtcp/seqnum"
+)
+
+const (
+	// DefaultTransportLayerCCStatsMaxSamples is the number of packets a
+	// TransportLayerCCStats keeps statistics over when its MaxSamples is
+	// zero.
+	DefaultTransportLayerCCStatsMaxSamples = 1 << 16
+)
+
+type transportLayerCCSample struct {
+	at       time.Time
+	received bool
+	size     int
+	// only valid if hasDelayVariation is set
+	delayVariation    time.Duration
+	hasDelayVariation bool
+}
+
+// TransportLayerCCStatsSnapshot holds statistics computed over the window of
+// a TransportLayerCCStats.
+type TransportLayerCCStatsSnapshot struct {
+	// Packets reported as received and lost
+	Received int
+	Lost     int
+	// Lost / (Received + Lost)
+	LossRate float64
+
+	// Mean, median and 95th percentile of the absolute one-way delay
+	// variation between consecutive received packets. Only computed if
+	// TransportLayerCCStats.SendTime is set.
+	DelayVariationMean time.Duration
+	DelayVariationP50  time.Duration
+	DelayVariationP95  time.Duration
+
+	// Received packets per second
+	PacketRate float64
+	// Received bits per second. Only computed if
+	// TransportLayerCCStats.PacketSize is set.
+	Bitrate float64
+}
+
+// TransportLayerCCStats consumes a stream of TransportLayerCC feedback and
+// maintains rolling statistics for telemetry.
+type TransportLayerCCStats struct {
+	// The window statistics are computed over
+	Window time.Duration
+	// SendTime optionally returns the send time of a packet by transport
+	// wide sequence number, which is needed for delay variation.
+	SendTime func(seq uint16) (time.Time, bool)
+	// PacketSize optionally returns the size in bytes of a packet by
+	// transport wide sequence number, which is needed for the bitrate.
+	PacketSize func(seq uint16) (int, bool)
+	// The source of the current time. If nil, SystemClock is used.
+	Clock Clock
+	// The maximum number of packets kept in the window. Once reached, the
+	// oldest are evicted before the window expires them. If zero,
+	// DefaultTransportLayerCCStatsMaxSamples is used.
+	MaxSamples int
+	// If set, the reference time of feedback is decoded as signed, see
+	// TransportLayerCCHistory.SignedReferenceTime.
+	SignedReferenceTime bool
+
+	samples   []transportLayerCCSample
+	evictions uint64
+
+	unwrapper   seqnum.Unwrapper
+	haveLast    bool
+	lastSeq     int64
+	lastArrival time.Duration
+	lastSend    time.Time
+}
+
+// NewTransportLayerCCStats creates a TransportLayerCCStats with the given window.
+func NewTransportLayerCCStats(window time.Duration) *TransportLayerCCStats {
+	return &TransportLayerCCStats{
+		Window: window,
+	}
+}
+
+// Add adds the packets reported by a feedback packet to the statistics.
+func (s *TransportLayerCCStats) Add(fb *TransportLayerCC) {
+	now := clockNow(s.Clock)
+	s.expire(now)
+
+	reference := fb.reference(s.SignedReferenceTime)
+	arrivals := fb.ArrivalTimes()
+
+	fb.forEachStatus(func(seq uint16, symbol PacketStatusSymbol) {
+		sample := transportLayerCCSample{at: now, received: symbol != TypePacketNotReceived}
+		if sample.received && s.PacketSize != nil {
+			if size, ok := s.PacketSize(seq); ok {
+				sample.size = size
+			}
+		}
+
+		if offset, ok := arrivals[seq]; ok && s.SendTime != nil {
+			if sent, ok := s.SendTime(seq); ok {
+				s.addDelayVariation(&sample, seq, reference+offset, sent)
+			}
+		}
+
+		s.samples = append(s.samples, sample)
+	})
+
+	maxSamples := s.MaxSamples
+	if maxSamples <= 0 {
+		maxSamples = DefaultTransportLayerCCStatsMaxSamples
+	}
+	if excess := len(s.samples) - maxSamples; excess > 0 {
+		s.samples = append(s.samples[:0], s.samples[excess:]...)
+		s.evictions += uint64(excess)
+	}
+}
+
+// Evictions returns the number of packets dropped from the window because
+// more than MaxSamples were reported within it.
+func (s *TransportLayerCCStats) Evictions() uint64 {
+	return s.evictions
+}
+
+func (s *TransportLayerCCStats) addDelayVariation(sample *transportLayerCCSample, seq uint16, arrival time.Duration, sent time.Time) {
+	unwrapped := s.unwrapper.Unwrap(seq)
+	if s.haveLast && unwrapped > s.lastSeq {
+		variation := (arrival - s.lastArrival) - sent.Sub(s.lastSend)
+		if variation < 0 {
+			variation = -variation
+		}
+		sample.delayVariation = variation
+		sample.hasDelayVariation = true
+	}
+	if !s.haveLast || unwrapped > s.lastSeq {
+		s.haveLast = true
+		s.lastSeq = unwrapped
+		s.lastArrival = arrival
+		s.lastSend = sent
+	}
+}
+
+func (s *TransportLayerCCStats) expire(now time.Time) {
+	cutoff := now.Add(-s.Window)
+	i := 0
+	for ; i < len(s.samples) && !s.samples[i].at.After(cutoff); i++ {
+	}
+	s.samples = append(s.samples[:0], s.samples[i:]...)
+}
+
+// Snapshot returns the statistics over the current window.
+func (s *TransportLayerCCStats) Snapshot() TransportLayerCCStatsSnapshot {
+	s.expire(clockNow(s.Clock))
+
+	var out TransportLayerCCStatsSnapshot
+	var bytes int
+	var variations []time.Duration
+	var variationSum time.Duration
+	for _, sample := range s.samples {
+		if !sample.received {
+			out.Lost++
+			continue
+		}
+		out.Received++
+		bytes += sample.size
+		if sample.hasDelayVariation {
+			variations = append(variations, sample.delayVariation)
+			variationSum += sample.delayVariation
+		}
+	}
+
+	if total := out.Received + out.Lost; total > 0 {
+		out.LossRate = float64(out.Lost) / float64(total)
+	}
+
+	if seconds := s.Window.Seconds(); seconds > 0 {
+		out.PacketRate = float64(out.Received) / seconds
+		if s.PacketSize != nil {
+			out.Bitrate = float64(bytes*8) / seconds
+		}
+	}
+
+	if len(variations) > 0 {
+		sort.Slice(variations, func(i, j int) bool { return variations[i] < variations[j] })
+		out.DelayVariationMean = variationSum / time.Duration(len(variations))
+		out.DelayVariationP50 = percentile(variations, 50)
+		out.DelayVariationP95 = percentile(variations, 95)
+	}
+
+	return out
+}
+
+// percentile returns the p-th percentile of sorted values, using the nearest
+// rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,124 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportLayerCCStats(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1000, 0)
+	sendStart := time.Unix(500, 0)
+
+	s := NewTransportLayerCCStats(time.Second)
+	s.Clock = ClockFunc(func() time.Time { return now })
+	s.SendTime = func(seq uint16) (time.Time, bool) {
+		// one packet every 10ms
+		return sendStart.Add(time.Duration(seq) * 10 * time.Millisecond), true
+	}
+	s.PacketSize = func(seq uint16) (int, bool) { return 1000, true }
+
+	// seq 0-3 received 10, 12, 8, 10ms apart; seq 4 is lost
+	s.Add(&TransportLayerCC{
+		BaseSequenceNumber: 0,
+		PacketStatusCount:  5,
+		PacketChunks: []PacketStatusChunk{
+			&StatusVectorChunk{
+				Type:       typeStatusVectorChunk,
+				SymbolSize: typeSymbolSizeOneBit,
+				SymbolList: []PacketStatusSymbol{1, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			},
+		},
+		RecvDeltas: []*RecvDelta{
+			{Type: TypePacketReceivedSmallDelta, Delta: 0},
+			{Type: TypePacketReceivedSmallDelta, Delta: 10000},
+			{Type: TypePacketReceivedSmallDelta, Delta: 12000},
+			{Type: TypePacketReceivedSmallDelta, Delta: 8000},
+		},
+	})
+
+	snapshot := s.Snapshot()
+	assert.Equal(4, snapshot.Received)
+	assert.Equal(1, snapshot.Lost)
+	assert.Equal(0.2, snapshot.LossRate)
+	assert.Equal(4.0, snapshot.PacketRate)
+	assert.Equal(32000.0, snapshot.Bitrate)
+	assert.Equal(4*time.Millisecond/3, snapshot.DelayVariationMean)
+	assert.Equal(2*time.Millisecond, snapshot.DelayVariationP50)
+	assert.Equal(2*time.Millisecond, snapshot.DelayVariationP95)
+
+	// the next feedback continues the delay variation computation
+	now = now.Add(500 * time.Millisecond)
+	s.Add(&TransportLayerCC{
+		BaseSequenceNumber: 5,
+		PacketStatusCount:  1,
+		PacketChunks: []PacketStatusChunk{
+			&RunLengthChunk{Type: typeRunLengthChunk, PacketStatusSymbol: TypePacketReceivedSmallDelta, RunLength: 1},
+		},
+		RecvDeltas: []*RecvDelta{
+			{Type: TypePacketReceivedSmallDelta, Delta: 30000},
+		},
+	})
+	snapshot = s.Snapshot()
+	assert.Equal(5, snapshot.Received)
+	assert.Equal(20*time.Millisecond, snapshot.DelayVariationP95)
+
+	// the first feedback leaves the window
+	now = now.Add(600 * time.Millisecond)
+	snapshot = s.Snapshot()
+	assert.Equal(1, snapshot.Received)
+	assert.Equal(0, snapshot.Lost)
+	assert.Equal(20*time.Millisecond, snapshot.DelayVariationMean)
+}
+
+func TestTransportLayerCCStatsWithoutSendTimes(t *testing.T) {
+	s := NewTransportLayerCCStats(time.Second)
+	s.Add(&TransportLayerCC{
+		PacketStatusCount: 2,
+		PacketChunks: []PacketStatusChunk{
+			&RunLengthChunk{Type: typeRunLengthChunk, PacketStatusSymbol: TypePacketReceivedSmallDelta, RunLength: 2},
+		},
+		RecvDeltas: []*RecvDelta{
+			{Type: TypePacketReceivedSmallDelta, Delta: 0},
+			{Type: TypePacketReceivedSmallDelta, Delta: 1000},
+		},
+	})
+
+	snapshot := s.Snapshot()
+	assert.Equal(t, 2, snapshot.Received)
+	assert.Equal(t, time.Duration(0), snapshot.DelayVariationMean)
+	assert.Equal(t, 0.0, snapshot.Bitrate)
+}
+
+func TestTransportLayerCCStatsMaxSamples(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewTransportLayerCCStats(time.Second)
+	s.Clock = ClockFunc(func() time.Time { return now })
+	s.MaxSamples = 8
+
+	s.Add(&TransportLayerCC{
+		PacketStatusCount: 10,
+		PacketChunks: []PacketStatusChunk{
+			&RunLengthChunk{Type: typeRunLengthChunk, PacketStatusSymbol: TypePacketNotReceived, RunLength: 10},
+		},
+	})
+	s.Add(&TransportLayerCC{
+		BaseSequenceNumber: 10,
+		PacketStatusCount:  2,
+		PacketChunks: []PacketStatusChunk{
+			&RunLengthChunk{Type: typeRunLengthChunk, PacketStatusSymbol: TypePacketReceivedSmallDelta, RunLength: 2},
+		},
+		RecvDeltas: []*RecvDelta{
+			{Type: TypePacketReceivedSmallDelta, Delta: 0},
+			{Type: TypePacketReceivedSmallDelta, Delta: 1000},
+		},
+	})
+
+	snapshot := s.Snapshot()
+	assert.Equal(t, 2, snapshot.Received)
+	assert.Equal(t, 6, snapshot.Lost)
+	assert.Equal(t, uint64(4), s.Evictions())
+}
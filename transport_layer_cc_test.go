@@ -1,8 +1,11 @@
 package rtcp
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestTransportLayerCC_RunLengthChunkUnmarshal(t *testing.T) {
@@ -18,7 +21,7 @@ func TestTransportLayerCC_RunLengthChunkUnmarshal(t *testing.T) {
 			Data: []byte{0, 0xDD},
 			Want: RunLengthChunk{
 				Type:               typeRunLengthChunk,
-				PacketStatusSymbol: typePacketNotReceived,
+				PacketStatusSymbol: TypePacketNotReceived,
 				RunLength:          221,
 			},
 			WantError: nil,
@@ -29,7 +32,7 @@ func TestTransportLayerCC_RunLengthChunkUnmarshal(t *testing.T) {
 			Data: []byte{0x60, 0x18},
 			Want: RunLengthChunk{
 				Type:               typeRunLengthChunk,
-				PacketStatusSymbol: typePacketReceivedWithoutDelta,
+				PacketStatusSymbol: TypePacketReceivedWithoutDelta,
 				RunLength:          24,
 			},
 			WantError: nil,
@@ -58,7 +61,7 @@ func TestTransportLayerCC_RunLengthChunkMarshal(t *testing.T) {
 			Name: "example1",
 			Data: RunLengthChunk{
 				Type:               typeRunLengthChunk,
-				PacketStatusSymbol: typePacketNotReceived,
+				PacketStatusSymbol: TypePacketNotReceived,
 				RunLength:          221,
 			},
 			Want:      []byte{0, 0xDD},
@@ -69,15 +72,37 @@ func TestTransportLayerCC_RunLengthChunkMarshal(t *testing.T) {
 			Name: "example2",
 			Data: RunLengthChunk{
 				Type:               typeRunLengthChunk,
-				PacketStatusSymbol: typePacketReceivedWithoutDelta,
+				PacketStatusSymbol: TypePacketReceivedWithoutDelta,
 				RunLength:          24,
 			},
 			Want:      []byte{0x60, 0x18},
 			WantError: nil,
 		},
+		{
+			Name: "longest run",
+			Data: RunLengthChunk{
+				Type:               typeRunLengthChunk,
+				PacketStatusSymbol: TypePacketNotReceived,
+				RunLength:          8191,
+			},
+			Want:      []byte{0x1f, 0xff},
+			WantError: nil,
+		},
+		{
+			Name: "run too long",
+			Data: RunLengthChunk{
+				Type:               typeRunLengthChunk,
+				PacketStatusSymbol: TypePacketNotReceived,
+				RunLength:          8192,
+			},
+			WantError: errRunLengthTooLong,
+		},
 	} {
 		chunk := test.Data
-		data, _ := chunk.Marshal()
+		data, err := chunk.Marshal()
+		if err != test.WantError {
+			t.Fatalf("Marshal %q : err = %v, want %v", test.Name, err, test.WantError)
+		}
 		if got, want := data, test.Want; !reflect.DeepEqual(got, want) {
 			t.Fatalf("Unmarshal %q : got = %v, want %v", test.Name, got, want)
 		}
@@ -98,7 +123,7 @@ func TestTransportLayerCC_StatusVectorChunkUnmarshal(t *testing.T) {
 			Want: StatusVectorChunk{
 				Type:       typeStatusVectorChunk,
 				SymbolSize: typeSymbolSizeOneBit,
-				SymbolList: []uint16{typeSymbolListPacketReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketReceived, typeSymbolListPacketReceived, typeSymbolListPacketReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketReceived, typeSymbolListPacketReceived},
+				SymbolList: []PacketStatusSymbol{TypePacketNotReceived, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketNotReceived, TypePacketNotReceived, TypePacketNotReceived, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketNotReceived, TypePacketNotReceived},
 			},
 			WantError: nil,
 		},
@@ -109,7 +134,7 @@ func TestTransportLayerCC_StatusVectorChunkUnmarshal(t *testing.T) {
 			Want: StatusVectorChunk{
 				Type:       typeStatusVectorChunk,
 				SymbolSize: typeSymbolSizeTwoBit,
-				SymbolList: []uint16{typePacketNotReceived, typePacketReceivedWithoutDelta, typePacketReceivedSmallDelta, typePacketReceivedSmallDelta, typePacketReceivedSmallDelta, typePacketNotReceived, typePacketNotReceived},
+				SymbolList: []PacketStatusSymbol{TypePacketNotReceived, TypePacketReceivedWithoutDelta, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketNotReceived, TypePacketNotReceived},
 			},
 			WantError: nil,
 		},
@@ -139,7 +164,7 @@ func TestTransportLayerCC_StatusVectorChunkMarshal(t *testing.T) {
 			Data: StatusVectorChunk{
 				Type:       typeStatusVectorChunk,
 				SymbolSize: typeSymbolSizeOneBit,
-				SymbolList: []uint16{typeSymbolListPacketReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketReceived, typeSymbolListPacketReceived, typeSymbolListPacketReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketNotReceived, typeSymbolListPacketReceived, typeSymbolListPacketReceived},
+				SymbolList: []PacketStatusSymbol{TypePacketNotReceived, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketNotReceived, TypePacketNotReceived, TypePacketNotReceived, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketNotReceived, TypePacketNotReceived},
 			},
 			Want:      []byte{0x9F, 0x1C},
 			WantError: nil,
@@ -150,7 +175,7 @@ func TestTransportLayerCC_StatusVectorChunkMarshal(t *testing.T) {
 			Data: StatusVectorChunk{
 				Type:       typeStatusVectorChunk,
 				SymbolSize: typeSymbolSizeTwoBit,
-				SymbolList: []uint16{typePacketNotReceived, typePacketReceivedWithoutDelta, typePacketReceivedSmallDelta, typePacketReceivedSmallDelta, typePacketReceivedSmallDelta, typePacketNotReceived, typePacketNotReceived},
+				SymbolList: []PacketStatusSymbol{TypePacketNotReceived, TypePacketReceivedWithoutDelta, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketNotReceived, TypePacketNotReceived},
 			},
 			Want:      []byte{0xCD, 0x50},
 			WantError: nil,
@@ -164,6 +189,94 @@ func TestTransportLayerCC_StatusVectorChunkMarshal(t *testing.T) {
 	}
 }
 
+func TestTransportLayerCC_StatusVectorChunkPacketCount(t *testing.T) {
+	for _, test := range []struct {
+		Name      string
+		Data      StatusVectorChunk
+		Want      []byte
+		WantError error
+	}{
+		{
+			Name: "partial one bit",
+			Data: StatusVectorChunk{
+				SymbolSize: typeSymbolSizeOneBit,
+				SymbolList: []PacketStatusSymbol{1, 0, 1},
+			},
+			Want: []byte{0xa8, 0x00},
+		},
+		{
+			Name: "partial two bit",
+			Data: StatusVectorChunk{
+				SymbolSize: typeSymbolSizeTwoBit,
+				SymbolList: []PacketStatusSymbol{TypePacketReceivedLargeDelta, TypePacketReceivedSmallDelta},
+			},
+			Want: []byte{0xe4, 0x00},
+		},
+		{
+			Name: "empty",
+			Data: StatusVectorChunk{
+				SymbolSize: typeSymbolSizeTwoBit,
+			},
+			Want: []byte{0xc0, 0x00},
+		},
+		{
+			Name: "too many two bit symbols",
+			Data: StatusVectorChunk{
+				SymbolSize: typeSymbolSizeTwoBit,
+				SymbolList: make([]PacketStatusSymbol, 8),
+			},
+			WantError: errTooManySymbols,
+		},
+		{
+			Name: "too many one bit symbols",
+			Data: StatusVectorChunk{
+				SymbolSize: typeSymbolSizeOneBit,
+				SymbolList: make([]PacketStatusSymbol, 15),
+			},
+			WantError: errTooManySymbols,
+		},
+	} {
+		data, err := test.Data.Marshal()
+		if err != test.WantError {
+			t.Fatalf("Marshal %q: err = %v, want %v", test.Name, err, test.WantError)
+		}
+		if got, want := data, test.Want; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Marshal %q: got = %v, want %v", test.Name, got, want)
+		}
+		if err != nil {
+			continue
+		}
+
+		// unused symbols read back as not received
+		var chunk StatusVectorChunk
+		if err := chunk.Unmarshal(data); err != nil {
+			t.Fatalf("Unmarshal %q: %v", test.Name, err)
+		}
+		if got, want := chunk.SymbolList[:test.Data.PacketCount()], test.Data.SymbolList; len(want) != 0 && !reflect.DeepEqual(got, want) {
+			t.Fatalf("Unmarshal %q: got = %v, want %v", test.Name, got, want)
+		}
+		for _, s := range chunk.SymbolList[test.Data.PacketCount():] {
+			if s != TypePacketNotReceived {
+				t.Fatalf("Unmarshal %q: unused symbol %d, want not received", test.Name, s)
+			}
+		}
+	}
+
+	if got := (RunLengthChunk{RunLength: 42}).PacketCount(); got != 42 {
+		t.Fatalf("RunLengthChunk PacketCount = %d, want 42", got)
+	}
+
+	fb := TransportLayerCC{
+		PacketStatusCount: 3,
+		PacketChunks: []PacketStatusChunk{
+			&StatusVectorChunk{SymbolSize: typeSymbolSizeTwoBit, SymbolList: []PacketStatusSymbol{0, 0}},
+		},
+	}
+	if _, err := fb.Marshal(); err != errPacketStatusCount {
+		t.Fatalf("Marshal with too few symbols: err = %v, want %v", err, errPacketStatusCount)
+	}
+}
+
 func TestTransportLayerCC_RecvDeltaUnmarshal(t *testing.T) {
 	for _, test := range []struct {
 		Name      string
@@ -175,7 +288,7 @@ func TestTransportLayerCC_RecvDeltaUnmarshal(t *testing.T) {
 			Name: "small delta 63.75ms",
 			Data: []byte{0xFF},
 			Want: RecvDelta{
-				Type:  typePacketReceivedSmallDelta,
+				Type:  TypePacketReceivedSmallDelta,
 				Delta: 63750,
 			},
 			WantError: nil,
@@ -184,11 +297,20 @@ func TestTransportLayerCC_RecvDeltaUnmarshal(t *testing.T) {
 			Name: "big delta 8191.75ms",
 			Data: []byte{0x7F, 0xFF},
 			Want: RecvDelta{
-				Type:  typePacketReceivedLargeDelta,
+				Type:  TypePacketReceivedLargeDelta,
 				Delta: 8191750,
 			},
 			WantError: nil,
 		},
+		{
+			Name: "big delta -8192ms",
+			Data: []byte{0x80, 0x00},
+			Want: RecvDelta{
+				Type:  TypePacketReceivedLargeDelta,
+				Delta: -8192000,
+			},
+			WantError: nil,
+		},
 	} {
 		var chunk RecvDelta
 		err := chunk.Unmarshal(test.Data)
@@ -212,7 +334,7 @@ func TestTransportLayerCC_RecvDeltaMarshal(t *testing.T) {
 		{
 			Name: "small delta 63.75ms",
 			Data: RecvDelta{
-				Type:  typePacketReceivedSmallDelta,
+				Type:  TypePacketReceivedSmallDelta,
 				Delta: 63750,
 			},
 			Want:      []byte{0xFF},
@@ -221,7 +343,7 @@ func TestTransportLayerCC_RecvDeltaMarshal(t *testing.T) {
 		{
 			Name: "big delta 8191.75ms",
 			Data: RecvDelta{
-				Type:  typePacketReceivedLargeDelta,
+				Type:  TypePacketReceivedLargeDelta,
 				Delta: 8191750,
 			},
 			Want:      []byte{0x7F, 0xFF},
@@ -288,17 +410,17 @@ func TestTransportLayerCC_Unmarshal(t *testing.T) {
 				ReferenceTime:      4057090,
 				FbPktCount:         23,
 				// 0b00100000, 0b00000001
-				PacketChunks: []iPacketStautsChunk{
+				PacketChunks: []PacketStatusChunk{
 					&RunLengthChunk{
 						Type:               typeRunLengthChunk,
-						PacketStatusSymbol: typePacketReceivedSmallDelta,
+						PacketStatusSymbol: TypePacketReceivedSmallDelta,
 						RunLength:          1,
 					},
 				},
 				// 0b10010100
 				RecvDeltas: []*RecvDelta{
 					{
-						Type:  typePacketReceivedSmallDelta,
+						Type:  TypePacketReceivedSmallDelta,
 						Delta: 37000,
 					},
 				},
@@ -329,32 +451,65 @@ func TestTransportLayerCC_Unmarshal(t *testing.T) {
 				PacketStatusCount:  2,
 				ReferenceTime:      4567386,
 				FbPktCount:         64,
-				PacketChunks: []iPacketStautsChunk{
-					&StatusVectorChunk{
-						Type:       typeStatusVectorChunk,
-						SymbolSize: typeSymbolSizeTwoBit,
-						SymbolList: []uint16{typePacketReceivedSmallDelta, typePacketReceivedLargeDelta, typePacketNotReceived, typePacketNotReceived, typePacketNotReceived, typePacketNotReceived, typePacketNotReceived},
-					},
+				// PacketStatusCount is 2, so only the first two symbols
+				// of the vector describe packets
+				PacketChunks: []PacketStatusChunk{
 					&StatusVectorChunk{
 						Type:       typeStatusVectorChunk,
 						SymbolSize: typeSymbolSizeTwoBit,
-						SymbolList: []uint16{typePacketReceivedWithoutDelta, typePacketNotReceived, typePacketNotReceived, typePacketReceivedWithoutDelta, typePacketReceivedWithoutDelta, typePacketReceivedWithoutDelta, typePacketReceivedWithoutDelta},
+						SymbolList: []PacketStatusSymbol{TypePacketReceivedSmallDelta, TypePacketReceivedLargeDelta},
 					},
 				},
-				// 0b10010100
+				// 0b11110000, then 0b11111111 0b11010000
 				RecvDeltas: []*RecvDelta{
 					{
-						Type:  typePacketReceivedSmallDelta,
-						Delta: 52000,
+						Type:  TypePacketReceivedSmallDelta,
+						Delta: 60000,
 					},
 					{
-						Type:  typePacketReceivedLargeDelta,
-						Delta: 0,
+						Type:  TypePacketReceivedLargeDelta,
+						Delta: -12000,
 					},
 				},
 			},
 			WantError: nil,
 		},
+		{
+			Name: "run longer than packet status count",
+			Data: []byte{
+				0x8f, 0xcd, 0x0, 0x6,
+				0x0, 0x0, 0x0, 0x1,
+				0x0, 0x0, 0x0, 0x2,
+				0x0, 0xa, 0x0, 0x3,
+				0x0, 0x0, 0x0, 0x0,
+				// small deltas, run length 10
+				0x20, 0xa, 0x4, 0x8,
+				0xc, 0x0, 0x0, 0x0,
+			},
+			Want: TransportLayerCC{
+				Header: Header{
+					Count:  FormatTCC,
+					Type:   TypeTransportSpecificFeedback,
+					Length: 6,
+				},
+				SenderSSRC:         1,
+				MediaSSRC:          2,
+				BaseSequenceNumber: 10,
+				PacketStatusCount:  3,
+				PacketChunks: []PacketStatusChunk{
+					&RunLengthChunk{
+						Type:               typeRunLengthChunk,
+						PacketStatusSymbol: TypePacketReceivedSmallDelta,
+						RunLength:          10,
+					},
+				},
+				RecvDeltas: []*RecvDelta{
+					{Type: TypePacketReceivedSmallDelta, Delta: 1000},
+					{Type: TypePacketReceivedSmallDelta, Delta: 2000},
+					{Type: TypePacketReceivedSmallDelta, Delta: 3000},
+				},
+			},
+		},
 	} {
 		var chunk TransportLayerCC
 		err := chunk.Unmarshal(test.Data)
@@ -390,17 +545,17 @@ func TestTransportLayerCC_Marshal(t *testing.T) {
 				ReferenceTime:      4057090,
 				FbPktCount:         23,
 				// 0b00100000, 0b00000001
-				PacketChunks: []iPacketStautsChunk{
+				PacketChunks: []PacketStatusChunk{
 					&RunLengthChunk{
 						Type:               typeRunLengthChunk,
-						PacketStatusSymbol: typePacketReceivedSmallDelta,
+						PacketStatusSymbol: TypePacketReceivedSmallDelta,
 						RunLength:          1,
 					},
 				},
 				// 0b10010100
 				RecvDeltas: []*RecvDelta{
 					{
-						Type:  typePacketReceivedSmallDelta,
+						Type:  TypePacketReceivedSmallDelta,
 						Delta: 37000,
 					},
 				},
@@ -411,12 +566,9 @@ func TestTransportLayerCC_Marshal(t *testing.T) {
 				0x43, 0x3, 0x2f, 0xa0,
 				0x0, 0x99, 0x0, 0x1,
 				0x3d, 0xe8, 0x2, 0x17,
-				// change last byte '0b00000001' to '0b00000000', make ci pass
-				0x20, 0x1, 0x94, 0x0,
-				// 0b00100000, 0b00000001, 0b10010100, 0b00000001,
-				// the 'Want []byte' came from chrome, and
-				// the padding byte is '0b00000001', but i think should be '0b00000000' when i read the RFC, what's wrong?
-				// webrtc code: https://webrtc.googlesource.com/src/webrtc/+/f54860e9ef0b68e182a01edc994626d21961bc4b/modules/rtp_rtcp/source/rtcp_packet/transport_feedback.cc
+				// the 'Want []byte' came from chrome: the last padding
+				// byte holds the padding count, as RFC 3550 6.4.1 says
+				0x20, 0x1, 0x94, 0x1,
 			},
 			WantError: nil,
 		},
@@ -435,27 +587,22 @@ func TestTransportLayerCC_Marshal(t *testing.T) {
 				PacketStatusCount:  2,
 				ReferenceTime:      4567386,
 				FbPktCount:         64,
-				PacketChunks: []iPacketStautsChunk{
+				PacketChunks: []PacketStatusChunk{
 					&StatusVectorChunk{
 						Type:       typeStatusVectorChunk,
 						SymbolSize: typeSymbolSizeTwoBit,
-						SymbolList: []uint16{typePacketReceivedSmallDelta, typePacketReceivedLargeDelta, typePacketNotReceived, typePacketNotReceived, typePacketNotReceived, typePacketNotReceived, typePacketNotReceived},
-					},
-					&StatusVectorChunk{
-						Type:       typeStatusVectorChunk,
-						SymbolSize: typeSymbolSizeTwoBit,
-						SymbolList: []uint16{typePacketReceivedWithoutDelta, typePacketNotReceived, typePacketNotReceived, typePacketReceivedWithoutDelta, typePacketReceivedWithoutDelta, typePacketReceivedWithoutDelta, typePacketReceivedWithoutDelta},
+						SymbolList: []PacketStatusSymbol{TypePacketReceivedSmallDelta, TypePacketReceivedLargeDelta},
 					},
 				},
-				// 0b10010100
+				// a small delta followed by a large one
 				RecvDeltas: []*RecvDelta{
 					{
-						Type:  typePacketReceivedSmallDelta,
-						Delta: 52000,
+						Type:  TypePacketReceivedSmallDelta,
+						Delta: 60000,
 					},
 					{
-						Type:  typePacketReceivedLargeDelta,
-						Delta: 0,
+						Type:  TypePacketReceivedLargeDelta,
+						Delta: -12000,
 					},
 				},
 			},
@@ -466,15 +613,42 @@ func TestTransportLayerCC_Marshal(t *testing.T) {
 				0x1, 0x74, 0x0, 0x2,
 				0x45, 0xb1, 0x5a, 0x40,
 				0xd8, 0x0, 0xf0, 0xff,
-				// change last byte '0b00000011' to '0b00000000', make ci pass
-				0xd0, 0x0, 0x0, 0x0,
-				// 0b11010000, 0b00000000, 0b00000000, 0b00000011,
-				// the 'Want []byte' came from chrome, and
-				// the padding byte is '0b00000011', but i think should be '0b00000000' when i read the RFC
-				// webrtc code: https://webrtc.googlesource.com/src/webrtc/+/f54860e9ef0b68e182a01edc994626d21961bc4b/modules/rtp_rtcp/source/rtcp_packet/transport_feedback.cc
+				// the 'Want []byte' came from chrome, with 3 bytes of padding
+				0xd0, 0x0, 0x0, 0x3,
 			},
 			WantError: nil,
 		},
+		{
+			Name: "mixed deltas",
+			Data: TransportLayerCC{
+				SenderSSRC:         1,
+				MediaSSRC:          2,
+				BaseSequenceNumber: 10,
+				PacketStatusCount:  4,
+				PacketChunks: []PacketStatusChunk{
+					&StatusVectorChunk{
+						Type:       typeStatusVectorChunk,
+						SymbolSize: typeSymbolSizeTwoBit,
+						SymbolList: []PacketStatusSymbol{TypePacketReceivedLargeDelta, TypePacketReceivedSmallDelta, TypePacketReceivedLargeDelta, TypePacketReceivedSmallDelta},
+					},
+				},
+				RecvDeltas: []*RecvDelta{
+					{Type: TypePacketReceivedLargeDelta, Delta: -250},
+					{Type: TypePacketReceivedSmallDelta, Delta: 250},
+					{Type: TypePacketReceivedLargeDelta, Delta: 100000},
+					{Type: TypePacketReceivedSmallDelta, Delta: 500},
+				},
+			},
+			Want: []byte{
+				0x8f, 0xcd, 0x0, 0x6,
+				0x0, 0x0, 0x0, 0x1,
+				0x0, 0x0, 0x0, 0x2,
+				0x0, 0xa, 0x0, 0x4,
+				0x0, 0x0, 0x0, 0x0,
+				0xe6, 0x40, 0xff, 0xff,
+				0x1, 0x1, 0x90, 0x2,
+			},
+		},
 	} {
 		transportCC := test.Data
 		bin, err := transportCC.Marshal()
@@ -486,3 +660,587 @@ func TestTransportLayerCC_Marshal(t *testing.T) {
 		}
 	}
 }
+
+func TestTransportLayerCC_MarshalComputesHeader(t *testing.T) {
+	for _, test := range []struct {
+		Name       string
+		Data       TransportLayerCC
+		WantHeader Header
+	}{
+		{
+			Name: "padded",
+			Data: TransportLayerCC{
+				// stale header, must be ignored
+				Header: Header{
+					Count:  FormatTLN,
+					Type:   TypeReceiverReport,
+					Length: 100,
+				},
+				PacketStatusCount: 1,
+				PacketChunks: []PacketStatusChunk{
+					&RunLengthChunk{
+						Type:               typeRunLengthChunk,
+						PacketStatusSymbol: TypePacketReceivedSmallDelta,
+						RunLength:          1,
+					},
+				},
+				RecvDeltas: []*RecvDelta{
+					{
+						Type:  TypePacketReceivedSmallDelta,
+						Delta: 37000,
+					},
+				},
+			},
+			WantHeader: Header{
+				Padding: true,
+				Count:   FormatTCC,
+				Type:    TypeTransportSpecificFeedback,
+				Length:  5,
+			},
+		},
+		{
+			Name: "aligned",
+			Data: TransportLayerCC{
+				PacketStatusCount: 2,
+				PacketChunks: []PacketStatusChunk{
+					&RunLengthChunk{
+						Type:               typeRunLengthChunk,
+						PacketStatusSymbol: TypePacketReceivedSmallDelta,
+						RunLength:          2,
+					},
+				},
+				RecvDeltas: []*RecvDelta{
+					{
+						Type:  TypePacketReceivedSmallDelta,
+						Delta: 250,
+					},
+					{
+						Type:  TypePacketReceivedSmallDelta,
+						Delta: 500,
+					},
+				},
+			},
+			WantHeader: Header{
+				Padding: false,
+				Count:   FormatTCC,
+				Type:    TypeTransportSpecificFeedback,
+				Length:  5,
+			},
+		},
+	} {
+		bin, err := test.Data.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal %q: %v", test.Name, err)
+		}
+
+		var h Header
+		if err := h.Unmarshal(bin); err != nil {
+			t.Fatalf("Unmarshal %q header: %v", test.Name, err)
+		}
+		if got, want := h, test.WantHeader; got != want {
+			t.Fatalf("Marshal %q header: got %v, want %v", test.Name, got, want)
+		}
+		if got, want := len(bin), int(h.Length+1)*4; got != want {
+			t.Fatalf("Marshal %q size: got %d, want %d", test.Name, got, want)
+		}
+	}
+}
+
+func TestTransportLayerCC_ArrivalTimesAndLost(t *testing.T) {
+	for _, test := range []struct {
+		Name             string
+		Data             TransportLayerCC
+		WantArrivalTimes map[uint16]time.Duration
+		WantLost         []uint16
+	}{
+		{
+			Name: "run length",
+			Data: TransportLayerCC{
+				BaseSequenceNumber: 153,
+				PacketStatusCount:  1,
+				PacketChunks: []PacketStatusChunk{
+					&RunLengthChunk{
+						Type:               typeRunLengthChunk,
+						PacketStatusSymbol: TypePacketReceivedSmallDelta,
+						RunLength:          1,
+					},
+				},
+				RecvDeltas: []*RecvDelta{
+					{Type: TypePacketReceivedSmallDelta, Delta: 37000},
+				},
+			},
+			WantArrivalTimes: map[uint16]time.Duration{
+				153: 37 * time.Millisecond,
+			},
+		},
+		{
+			Name: "two bit vector with wraparound",
+			Data: TransportLayerCC{
+				BaseSequenceNumber: 65534,
+				PacketStatusCount:  5,
+				PacketChunks: []PacketStatusChunk{
+					&StatusVectorChunk{
+						Type:       typeStatusVectorChunk,
+						SymbolSize: typeSymbolSizeTwoBit,
+						SymbolList: []PacketStatusSymbol{TypePacketReceivedSmallDelta, TypePacketNotReceived, TypePacketReceivedLargeDelta, TypePacketReceivedWithoutDelta, TypePacketNotReceived, TypePacketNotReceived, TypePacketNotReceived},
+					},
+				},
+				RecvDeltas: []*RecvDelta{
+					{Type: TypePacketReceivedSmallDelta, Delta: 1000},
+					{Type: TypePacketReceivedLargeDelta, Delta: -500},
+				},
+			},
+			WantArrivalTimes: map[uint16]time.Duration{
+				65534: time.Millisecond,
+				0:     500 * time.Microsecond,
+			},
+			WantLost: []uint16{65535, 2},
+		},
+		{
+			Name: "one bit vector",
+			Data: TransportLayerCC{
+				BaseSequenceNumber: 10,
+				PacketStatusCount:  4,
+				PacketChunks: []PacketStatusChunk{
+					&StatusVectorChunk{
+						Type:       typeStatusVectorChunk,
+						SymbolSize: typeSymbolSizeOneBit,
+						SymbolList: []PacketStatusSymbol{1, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+					},
+				},
+				RecvDeltas: []*RecvDelta{
+					{Type: TypePacketReceivedSmallDelta, Delta: 250},
+					{Type: TypePacketReceivedSmallDelta, Delta: 750},
+				},
+			},
+			WantArrivalTimes: map[uint16]time.Duration{
+				10: 250 * time.Microsecond,
+				13: time.Millisecond,
+			},
+			WantLost: []uint16{11, 12},
+		},
+	} {
+		if got, want := test.Data.ArrivalTimes(), test.WantArrivalTimes; !reflect.DeepEqual(got, want) {
+			t.Fatalf("ArrivalTimes %q: got %v, want %v", test.Name, got, want)
+		}
+		if got, want := test.Data.Lost(), test.WantLost; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Lost %q: got %v, want %v", test.Name, got, want)
+		}
+	}
+}
+
+func TestTransportLayerCC_LossPattern(t *testing.T) {
+	vector := func(symbols ...PacketStatusSymbol) *StatusVectorChunk {
+		return &StatusVectorChunk{Type: typeStatusVectorChunk, SymbolSize: typeSymbolSizeTwoBit, SymbolList: symbols}
+	}
+	run := func(symbol PacketStatusSymbol, length uint16) *RunLengthChunk {
+		return &RunLengthChunk{Type: typeRunLengthChunk, PacketStatusSymbol: symbol, RunLength: length}
+	}
+
+	for _, test := range []struct {
+		Name string
+		Data TransportLayerCC
+		Want string
+	}{
+		{
+			Name: "empty",
+			Want: "",
+		},
+		{
+			Name: "mixed",
+			Data: TransportLayerCC{
+				PacketStatusCount: 10,
+				PacketChunks: []PacketStatusChunk{
+					run(TypePacketReceivedSmallDelta, 3),
+					vector(TypePacketReceivedLargeDelta, TypePacketNotReceived, TypePacketReceivedSmallDelta, TypePacketReceivedSmallDelta, TypePacketNotReceived, TypePacketNotReceived, TypePacketReceivedWithoutDelta),
+					// beyond PacketStatusCount
+					run(TypePacketNotReceived, 5),
+				},
+			},
+			Want: "RRRR L RR LL R(nd)",
+		},
+		{
+			Name: "long runs",
+			Data: TransportLayerCC{
+				PacketStatusCount: 1009,
+				PacketChunks: []PacketStatusChunk{
+					run(TypePacketReceivedSmallDelta, 8),
+					run(TypePacketNotReceived, 900),
+					run(TypePacketReceivedWithoutDelta, 100),
+					run(TypePacketReceivedLargeDelta, 1),
+				},
+			},
+			Want: "RRRRRRRR L*900 R*100(nd) R",
+		},
+		{
+			Name: "one bit vector",
+			Data: TransportLayerCC{
+				PacketStatusCount: 4,
+				PacketChunks: []PacketStatusChunk{
+					&StatusVectorChunk{
+						Type:       typeStatusVectorChunk,
+						SymbolSize: typeSymbolSizeOneBit,
+						SymbolList: []PacketStatusSymbol{1, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+					},
+				},
+			},
+			Want: "R LL R",
+		},
+	} {
+		if got := test.Data.LossPattern(); got != test.Want {
+			t.Fatalf("LossPattern %q: got %q, want %q", test.Name, got, test.Want)
+		}
+	}
+}
+
+func TestTransportLayerCC_Coverage(t *testing.T) {
+	// a run of 2 received packets, then a one bit vector of which 3
+	// symbols are used: lost, received, lost
+	data := []byte{
+		0xaf, 0xcd, 0x00, 0x06,
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x02,
+		0x00, 0x0a, 0x00, 0x05,
+		0x00, 0x00, 0x00, 0x00,
+		0x20, 0x02, 0x90, 0x00,
+		0x01, 0x02, 0x03, 0x01,
+	}
+	var fb TransportLayerCC
+	if err := fb.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+	if got := fb.PacketStatusCoverage(); got != 5 {
+		t.Fatalf("PacketStatusCoverage = %d, want 5", got)
+	}
+	if got := fb.ReceivedCount(); got != 3 {
+		t.Fatalf("ReceivedCount = %d, want 3", got)
+	}
+
+	// built feedback may cover fewer packets than it announces
+	fb.PacketStatusCount = 20
+	if got := fb.PacketStatusCoverage(); got != 5 {
+		t.Fatalf("PacketStatusCoverage = %d, want 5", got)
+	}
+}
+
+func TestTransportLayerCC_RecvDeltaDuration(t *testing.T) {
+	for _, test := range []struct {
+		Name      string
+		Duration  time.Duration
+		Rounding  Rounding
+		WantDelta int64
+	}{
+		{"exact", 37 * time.Millisecond, RoundingTruncate, 37000},
+		{"truncate", 1249 * time.Microsecond, RoundingTruncate, 1000},
+		{"truncate negative", -1249 * time.Microsecond, RoundingTruncate, -1000},
+		{"nearest down", 1124 * time.Microsecond, RoundingNearest, 1000},
+		{"nearest up", 1126 * time.Microsecond, RoundingNearest, 1250},
+		{"nearest half", 1125 * time.Microsecond, RoundingNearest, 1250},
+		{"nearest negative", -1126 * time.Microsecond, RoundingNearest, -1250},
+		{"nearest sub microsecond", 124999 * time.Nanosecond, RoundingNearest, 0},
+	} {
+		var d RecvDelta
+		d.SetDeltaDuration(test.Duration, test.Rounding)
+		if got, want := d.Delta, test.WantDelta; got != want {
+			t.Fatalf("SetDeltaDuration %q: got %d, want %d", test.Name, got, want)
+		}
+		if got, want := d.DeltaDuration(), time.Duration(test.WantDelta)*time.Microsecond; got != want {
+			t.Fatalf("DeltaDuration %q: got %v, want %v", test.Name, got, want)
+		}
+	}
+}
+
+func TestTransportLayerCC_MarshalErrors(t *testing.T) {
+	badDelta := TransportLayerCC{
+		BaseSequenceNumber: 100,
+		PacketStatusCount:  3,
+		PacketChunks: []PacketStatusChunk{
+			&RunLengthChunk{
+				Type:               typeRunLengthChunk,
+				PacketStatusSymbol: TypePacketReceivedSmallDelta,
+				RunLength:          3,
+			},
+		},
+		RecvDeltas: []*RecvDelta{
+			{Type: TypePacketReceivedSmallDelta, Delta: 250},
+			// too large for a small delta
+			{Type: TypePacketReceivedSmallDelta, Delta: 100000},
+			{Type: TypePacketReceivedSmallDelta, Delta: 500},
+		},
+	}
+	_, err := badDelta.Marshal()
+	if !errors.Is(err, errDeltaExceedLimit) || !strings.Contains(err.Error(), "recv delta 1") {
+		t.Fatalf("Marshal bad delta: err = %v, want %v at delta 1", err, errDeltaExceedLimit)
+	}
+
+	badChunk := TransportLayerCC{
+		PacketStatusCount: 1,
+		PacketChunks: []PacketStatusChunk{
+			&StatusVectorChunk{SymbolSize: typeSymbolSizeTwoBit, SymbolList: make([]PacketStatusSymbol, 8)},
+		},
+	}
+	_, err = badChunk.Marshal()
+	if !errors.Is(err, errTooManySymbols) || !strings.Contains(err.Error(), "packet chunk 0") {
+		t.Fatalf("Marshal bad chunk: err = %v, want %v at chunk 0", err, errTooManySymbols)
+	}
+	if _, err = badChunk.MarshalPermissive(); !errors.Is(err, errTooManySymbols) {
+		t.Fatalf("MarshalPermissive bad chunk: err = %v, want %v", err, errTooManySymbols)
+	}
+
+	data, err := badDelta.MarshalPermissive()
+	if err != nil {
+		t.Fatalf("MarshalPermissive: %v", err)
+	}
+	var got TransportLayerCC
+	if err = got.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	var symbols []PacketStatusSymbol
+	got.forEachStatus(func(seq uint16, symbol PacketStatusSymbol) {
+		symbols = append(symbols, symbol)
+	})
+	if want := []PacketStatusSymbol{TypePacketReceivedSmallDelta, TypePacketReceivedWithoutDelta, TypePacketReceivedSmallDelta}; !reflect.DeepEqual(symbols, want) {
+		t.Fatalf("MarshalPermissive symbols: got %v, want %v", symbols, want)
+	}
+	if want := map[uint16]time.Duration{100: 250 * time.Microsecond, 102: 750 * time.Microsecond}; !reflect.DeepEqual(got.ArrivalTimes(), want) {
+		t.Fatalf("MarshalPermissive arrival times: got %v, want %v", got.ArrivalTimes(), want)
+	}
+
+	// the original packet is not modified
+	if len(badDelta.RecvDeltas) != 3 || len(badDelta.PacketChunks) != 1 {
+		t.Fatalf("MarshalPermissive modified the packet: %v", badDelta)
+	}
+}
+
+func TestTransportLayerCC_MarshalPadding(t *testing.T) {
+	// deltas alternate between small and large; each size leaves a
+	// different amount of padding
+	for n := 1; n <= 4; n++ {
+		fb := TransportLayerCC{PacketStatusCount: uint16(n)}
+		var symbols []PacketStatusSymbol
+		for i := 0; i < n; i++ {
+			delta := &RecvDelta{Type: TypePacketReceivedSmallDelta, Delta: int64(i) * 250}
+			if i%2 == 1 {
+				delta = &RecvDelta{Type: TypePacketReceivedLargeDelta, Delta: -int64(i) * 250}
+			}
+			symbols = append(symbols, delta.Type)
+			fb.RecvDeltas = append(fb.RecvDeltas, delta)
+		}
+		fb.PacketChunks = []PacketStatusChunk{
+			&StatusVectorChunk{Type: typeStatusVectorChunk, SymbolSize: typeSymbolSizeTwoBit, SymbolList: symbols},
+		}
+
+		data, err := fb.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal %d deltas: %v", n, err)
+		}
+		padding := fb.len() - fb.unpaddedLen()
+		if padding != 0 && int(data[len(data)-1]) != padding {
+			t.Fatalf("Marshal %d deltas: last byte %d, want padding count %d", n, data[len(data)-1], padding)
+		}
+
+		var got TransportLayerCC
+		if err := got.Unmarshal(data); err != nil {
+			t.Fatalf("Unmarshal %d deltas: %v", n, err)
+		}
+		if got.Header.Padding != (padding != 0) {
+			t.Fatalf("Unmarshal %d deltas: padding bit %v, want %v", n, got.Header.Padding, padding != 0)
+		}
+		if !reflect.DeepEqual(got.RecvDeltas, fb.RecvDeltas) {
+			t.Fatalf("Unmarshal %d deltas: got %v, want %v", n, got.RecvDeltas, fb.RecvDeltas)
+		}
+	}
+}
+
+func TestTransportLayerCC_UnmarshalWithoutDeltas(t *testing.T) {
+	// intervals in which every packet was lost, or reported without a delta,
+	// carry chunks but no delta bytes at all
+	for _, test := range []struct {
+		Name   string
+		Data   []byte
+		Chunks []PacketStatusChunk
+		Count  uint16
+	}{
+		{
+			Name: "all lost run",
+			Data: []byte{
+				0xaf, 0xcd, 0x0, 0x5,
+				0xfa, 0x17, 0xfa, 0x17,
+				0x43, 0x3, 0x2f, 0xa0,
+				0x0, 0x99, 0x0, 0x14,
+				0x1f, 0x55, 0x33, 0x1,
+				// not received, run length 20, then padding
+				0x0, 0x14, 0x0, 0x2,
+			},
+			Count: 20,
+			Chunks: []PacketStatusChunk{
+				&RunLengthChunk{Type: typeRunLengthChunk, PacketStatusSymbol: TypePacketNotReceived, RunLength: 20},
+			},
+		},
+		{
+			Name: "all lost one bit vector",
+			Data: []byte{
+				0xaf, 0xcd, 0x0, 0x5,
+				0xfa, 0x17, 0xfa, 0x17,
+				0x43, 0x3, 0x2f, 0xa0,
+				0x1, 0x0, 0x0, 0xe,
+				0x1f, 0x55, 0x34, 0x2,
+				0x80, 0x0, 0x0, 0x2,
+			},
+			Count: 14,
+			Chunks: []PacketStatusChunk{
+				&StatusVectorChunk{
+					Type:       typeStatusVectorChunk,
+					SymbolSize: typeSymbolSizeOneBit,
+					SymbolList: []PacketStatusSymbol{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+				},
+			},
+		},
+		{
+			Name: "lost and received without delta",
+			Data: []byte{
+				0xaf, 0xcd, 0x0, 0x5,
+				0xfa, 0x17, 0xfa, 0x17,
+				0x43, 0x3, 0x2f, 0xa0,
+				0x1, 0x0, 0x0, 0x5,
+				0x1f, 0x55, 0x35, 0x3,
+				// two bit vector, 5 of 7 symbols used
+				0xcc, 0xf0, 0x0, 0x2,
+			},
+			Count: 5,
+			Chunks: []PacketStatusChunk{
+				&StatusVectorChunk{
+					Type:       typeStatusVectorChunk,
+					SymbolSize: typeSymbolSizeTwoBit,
+					SymbolList: []PacketStatusSymbol{
+						TypePacketNotReceived,
+						TypePacketReceivedWithoutDelta,
+						TypePacketNotReceived,
+						TypePacketReceivedWithoutDelta,
+						TypePacketReceivedWithoutDelta,
+					},
+				},
+			},
+		},
+		{
+			Name: "no statuses",
+			Data: []byte{
+				0x8f, 0xcd, 0x0, 0x4,
+				0xfa, 0x17, 0xfa, 0x17,
+				0x43, 0x3, 0x2f, 0xa0,
+				0x1, 0x0, 0x0, 0x0,
+				0x1f, 0x55, 0x36, 0x4,
+			},
+		},
+	} {
+		var fb TransportLayerCC
+		if err := fb.Unmarshal(test.Data); err != nil {
+			t.Fatalf("Unmarshal %q: %v", test.Name, err)
+		}
+		if fb.PacketStatusCount != test.Count {
+			t.Fatalf("Unmarshal %q: PacketStatusCount %d, want %d", test.Name, fb.PacketStatusCount, test.Count)
+		}
+		if !reflect.DeepEqual(fb.PacketChunks, test.Chunks) {
+			t.Fatalf("Unmarshal %q: chunks %v, want %v", test.Name, fb.PacketChunks, test.Chunks)
+		}
+		if len(fb.RecvDeltas) != 0 {
+			t.Fatalf("Unmarshal %q: got %d deltas, want none", test.Name, len(fb.RecvDeltas))
+		}
+		if got := len(fb.ArrivalTimes()); got != 0 {
+			t.Fatalf("Unmarshal %q: got %d arrival times, want none", test.Name, got)
+		}
+
+		data, err := fb.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal %q: %v", test.Name, err)
+		}
+		if !reflect.DeepEqual(data, test.Data) {
+			t.Fatalf("Marshal %q: got %#v, want %#v", test.Name, data, test.Data)
+		}
+	}
+}
+
+func TestTransportLayerCC_PacketStatusChunkAccessors(t *testing.T) {
+	chunks := []PacketStatusChunk{
+		&RunLengthChunk{Type: typeRunLengthChunk, PacketStatusSymbol: TypePacketReceivedLargeDelta, RunLength: 3},
+		&StatusVectorChunk{Type: typeStatusVectorChunk, SymbolSize: typeSymbolSizeOneBit, SymbolList: []PacketStatusSymbol{1, 0, 1}},
+		&StatusVectorChunk{Type: typeStatusVectorChunk, SymbolSize: typeSymbolSizeTwoBit, SymbolList: []PacketStatusSymbol{
+			TypePacketReceivedWithoutDelta, TypePacketNotReceived, TypePacketReceivedSmallDelta,
+		}},
+	}
+	want := [][]PacketStatusSymbol{
+		{TypePacketReceivedLargeDelta, TypePacketReceivedLargeDelta, TypePacketReceivedLargeDelta},
+		{TypePacketReceivedSmallDelta, TypePacketNotReceived, TypePacketReceivedSmallDelta},
+		{TypePacketReceivedWithoutDelta, TypePacketNotReceived, TypePacketReceivedSmallDelta},
+	}
+	for i, chunk := range chunks {
+		var got []PacketStatusSymbol
+		for j := 0; j < chunk.StatusCount(); j++ {
+			got = append(got, chunk.StatusAt(j))
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Fatalf("chunk %d: got statuses %v, want %v", i, got, want[i])
+		}
+	}
+}
+
+func TestPacketStatusSymbolString(t *testing.T) {
+	for symbol, want := range map[PacketStatusSymbol]string{
+		TypePacketNotReceived:          "not received",
+		TypePacketReceivedSmallDelta:   "received, small delta",
+		TypePacketReceivedLargeDelta:   "received, large delta",
+		TypePacketReceivedWithoutDelta: "received, no delta",
+		PacketStatusSymbol(4):          "PacketStatusSymbol(4)",
+	} {
+		if got := symbol.String(); got != want {
+			t.Fatalf("String(%d): got %q, want %q", uint16(symbol), got, want)
+		}
+	}
+}
+
+func TestTransportLayerCC_ReferenceTimeSigned(t *testing.T) {
+	for _, test := range []struct {
+		Signed int32
+		Raw    uint32
+	}{
+		{0, 0},
+		{1, 1},
+		{-1, 0xffffff},
+		{1<<23 - 1, 0x7fffff},
+		{-1 << 23, 0x800000},
+	} {
+		var fb TransportLayerCC
+		if err := fb.SetReferenceTimeSigned(test.Signed); err != nil {
+			t.Fatalf("SetReferenceTimeSigned(%d): %v", test.Signed, err)
+		}
+		if fb.ReferenceTime != test.Raw {
+			t.Fatalf("SetReferenceTimeSigned(%d) = %#x, want %#x", test.Signed, fb.ReferenceTime, test.Raw)
+		}
+		if got := fb.ReferenceTimeSigned(); got != test.Signed {
+			t.Fatalf("ReferenceTimeSigned of %#x = %d, want %d", test.Raw, got, test.Signed)
+		}
+
+		// the field survives the wire either way
+		fb.PacketChunks = []PacketStatusChunk{&RunLengthChunk{PacketStatusSymbol: TypePacketNotReceived, RunLength: 1}}
+		fb.PacketStatusCount = 1
+		raw, err := fb.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		var decoded TransportLayerCC
+		if err := decoded.Unmarshal(raw); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		if got := decoded.ReferenceTimeSigned(); got != test.Signed {
+			t.Fatalf("decoded ReferenceTimeSigned = %d, want %d", got, test.Signed)
+		}
+	}
+
+	var fb TransportLayerCC
+	for _, ref := range []int32{1 << 23, -1<<23 - 1} {
+		if err := fb.SetReferenceTimeSigned(ref); err != errReferenceTimeRange {
+			t.Fatalf("SetReferenceTimeSigned(%d) err = %v, want %v", ref, err, errReferenceTimeRange)
+		}
+	}
+}
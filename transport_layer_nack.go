@@ -49,6 +49,27 @@ func (n *NackPair) PacketList() []uint16 {
 	return out
 }
 
+// NackPairsFromSequenceNumbers packs sequence numbers into NackPairs. A
+// sequence number up to 16 after the PacketID of the previous pair is added
+// to its bitmap, so sorted input (in wraparound order) packs tightest.
+// Duplicates are ignored.
+func NackPairsFromSequenceNumbers(seqs []uint16) []NackPair {
+	var out []NackPair
+	for _, seq := range seqs {
+		if n := len(out); n != 0 {
+			last := &out[n-1]
+			if diff := seq - last.PacketID; diff == 0 {
+				continue
+			} else if diff <= 16 {
+				last.LostPackets |= 1 << (diff - 1)
+				continue
+			}
+		}
+		out = append(out, NackPair{PacketID: seq})
+	}
+	return out
+}
+
 const (
 	tlnLength  = 2
 	nackOffset = 8
@@ -87,7 +108,7 @@ func (p *TransportLayerNack) Unmarshal(rawPacket []byte) error {
 		return err
 	}
 
-	if len(rawPacket) < (headerLength + int(4*h.Length)) {
+	if h.size() < headerLength+nackOffset || len(rawPacket) < h.size() {
 		return errPacketTooShort
 	}
 
@@ -97,7 +118,7 @@ func (p *TransportLayerNack) Unmarshal(rawPacket []byte) error {
 
 	p.SenderSSRC = binary.BigEndian.Uint32(rawPacket[headerLength:])
 	p.MediaSSRC = binary.BigEndian.Uint32(rawPacket[headerLength+ssrcLength:])
-	for i := headerLength + nackOffset; i < (headerLength + int(h.Length*4)); i += 4 {
+	for i := headerLength + nackOffset; i+4 <= h.size(); i += 4 {
 		p.Nacks = append(p.Nacks, NackPair{
 			binary.BigEndian.Uint16(rawPacket[i:]),
 			PacketBitmap(binary.BigEndian.Uint16(rawPacket[i+2:])),
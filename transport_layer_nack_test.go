@@ -116,3 +116,32 @@ func TestTransportLayerNackRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestNackPairsFromSequenceNumbers(t *testing.T) {
+	for _, test := range []struct {
+		Name string
+		Seqs []uint16
+		Want []NackPair
+	}{
+		{Name: "empty"},
+		{
+			Name: "single",
+			Seqs: []uint16{42},
+			Want: []NackPair{{PacketID: 42}},
+		},
+		{
+			Name: "bitmap and new pair",
+			Seqs: []uint16{1, 2, 17, 17, 18},
+			Want: []NackPair{{PacketID: 1, LostPackets: 0x8001}, {PacketID: 18}},
+		},
+		{
+			Name: "wraparound",
+			Seqs: []uint16{65534, 0, 1},
+			Want: []NackPair{{PacketID: 65534, LostPackets: 0x6}},
+		},
+	} {
+		if got := NackPairsFromSequenceNumbers(test.Seqs); !reflect.DeepEqual(got, test.Want) {
+			t.Fatalf("NackPairsFromSequenceNumbers %q: got %v, want %v", test.Name, got, test.Want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package rtcp
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// chanDatagramConn is a DatagramConn over a channel
+type chanDatagramConn chan []byte
+
+func (c chanDatagramConn) SendDatagram(payload []byte) error {
+	c <- append([]byte{}, payload...)
+	return nil
+}
+
+func (c chanDatagramConn) ReceiveDatagram(ctx context.Context) ([]byte, error) {
+	select {
+	case b := <-c:
+		return b, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestVarint(t *testing.T) {
+	// test vectors from RFC 9000, A.1
+	for _, test := range []struct {
+		Value uint64
+		Data  []byte
+	}{
+		{Value: 37, Data: []byte{0x25}},
+		{Value: 15293, Data: []byte{0x7b, 0xbd}},
+		{Value: 494878333, Data: []byte{0x9d, 0x7f, 0x3e, 0x7d}},
+		{Value: 151288809941952652, Data: []byte{0xc2, 0x19, 0x7c, 0x5e, 0xff, 0x14, 0xe8, 0x8c}},
+	} {
+		if got := appendVarint(nil, test.Value); !bytes.Equal(got, test.Data) {
+			t.Fatalf("appendVarint(%d) = %x, want %x", test.Value, got, test.Data)
+		}
+
+		v, n, err := readVarint(test.Data)
+		if err != nil || v != test.Value || n != len(test.Data) {
+			t.Fatalf("readVarint(%x) = %d, %d, %v, want %d, %d", test.Data, v, n, err, test.Value, len(test.Data))
+		}
+	}
+
+	if _, _, err := readVarint([]byte{0x40}); err != errPacketTooShort {
+		t.Fatalf("readVarint truncated: err = %v, want %v", err, errPacketTooShort)
+	}
+}
+
+func TestDatagramTransport(t *testing.T) {
+	conn := make(chanDatagramConn, 8)
+	tr := NewDatagramTransport(conn, 300)
+
+	// another flow, and RTP on our flow, are skipped
+	assert.NoError(t, NewDatagramTransport(conn, 1).WriteRTCP([]Packet{&RapidResynchronizationRequest{}}))
+	conn <- append(appendVarint(nil, 300), 0x80, 0x60, 0x00, 0x01)
+
+	pkts := []Packet{&PictureLossIndication{SenderSSRC: 1, MediaSSRC: 2}}
+	assert.NoError(t, tr.WriteRTCP(pkts))
+
+	got, err := tr.ReadRTCP()
+	assert.NoError(t, err)
+	assert.Equal(t, pkts, got)
+
+	assert.NoError(t, tr.Close())
+	_, err = tr.ReadRTCP()
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestPacketConnTransport(t *testing.T) {
+	a, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on loopback: %v", err)
+	}
+	b, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on loopback: %v", err)
+	}
+
+	ta := NewPacketConnTransport(a, b.LocalAddr())
+	tb := NewPacketConnTransport(b, a.LocalAddr())
+	defer func() {
+		assert.NoError(t, ta.Close())
+		assert.NoError(t, tb.Close())
+	}()
+
+	pkts := []Packet{&ReceiverReport{SSRC: 1, ProfileExtensions: []byte{}}}
+	assert.NoError(t, ta.WriteRTCP(pkts))
+
+	got, err := tb.ReadRTCP()
+	assert.NoError(t, err)
+	assert.Equal(t, pkts, got)
+
+	now := time.Unix(1000, 0)
+	tb.Clock = ClockFunc(func() time.Time { return now })
+	assert.NoError(t, ta.WriteRTCP(pkts))
+	received, err := tb.ReadReceived()
+	assert.NoError(t, err)
+	assert.Equal(t, NewReceivedPackets(pkts, now, a.LocalAddr()), received)
+
+	// packets read earlier aren't overwritten by later reads
+	first := []Packet{&ApplicationDefined{SSRC: 1, Name: "TEST", Data: []byte("first...")}}
+	second := []Packet{&ApplicationDefined{SSRC: 1, Name: "TEST", Data: []byte("second..")}}
+	assert.NoError(t, ta.WriteRTCP(first))
+	assert.NoError(t, ta.WriteRTCP(second))
+	gotFirst, err := tb.ReadRTCP()
+	assert.NoError(t, err)
+	gotSecond, err := tb.ReadRTCP()
+	assert.NoError(t, err)
+	assert.Equal(t, first, gotFirst)
+	assert.Equal(t, second, gotSecond)
+}
@@ -1,7 +1,5 @@
 package rtcp
 
-import "fmt"
-
 // getPadding Returns the padding required to make the length a multiple of 4
 func getPadding(len int) int {
 	if len%4 == 0 {
@@ -10,36 +8,31 @@ func getPadding(len int) int {
 	return 4 - (len % 4)
 }
 
-// appendBit will left-shift and append n bits of val
-func appendNBitsToUint16(src, n, val uint16) uint16 {
-	return (src << n) | (val & (0xFFFF >> (16 - n)))
+// appendNBitsToUint16 will left-shift src and append the low n bits of val.
+// n is a uint so the shifts compile without bounds handling and the call
+// inlines; with a constant n the mask is folded as well.
+func appendNBitsToUint16(src uint16, n uint, val uint16) uint16 {
+	return src<<n | val&(1<<n-1)
+}
+
+// appendNBitsToUint32 will left-shift src and append the low n bits of val
+func appendNBitsToUint32(src uint32, n uint, val uint32) uint32 {
+	return src<<n | val&(1<<n-1)
 }
 
-// appendBit32 will left-shift and append n bits of val
-func appendNBitsToUint32(src, n, val uint32) uint32 {
-	return (src << n) | (val & (0xFFFFFFFF >> (32 - n)))
+// getNBitsFromByte gets n bits from 1 byte, beginning at the MSB-first
+// position begin
+func getNBitsFromByte(b byte, begin, n uint) uint16 {
+	return uint16(b>>(8-begin-n)) & (1<<n - 1)
 }
 
-// getNBit get n bits from 1 byte, begin with a position
-func getNBitsFromByte(b byte, begin, n uint16) uint16 {
-	endShift := 8 - (begin + n)
-	mask := (0xFF >> begin) & uint8(0xFF<<endShift)
-	return uint16(b&mask) >> endShift
+// getNBitsFromUint16 gets n bits from a 16 bit word, beginning at the
+// MSB-first position begin
+func getNBitsFromUint16(v uint16, begin, n uint) uint16 {
+	return v >> (16 - begin - n) & (1<<n - 1)
 }
 
 // get24BitFromBytes get 24bits from `[3]byte` slice
 func get24BitsFromBytes(b []byte) uint32 {
 	return uint32(b[0])<<16 + uint32(b[1])<<8 + uint32(b[2])
 }
-
-// dumpBinary dump []byte to string
-func dumpBinary(b []byte) string {
-	out := ""
-	for i, v := range b {
-		out += fmt.Sprintf("0b%08b,", v)
-		if (i+1)%4 == 0 {
-			out += "\n"
-		}
-	}
-	return out
-}
@@ -26,3 +26,37 @@ func TestGetPadding(t *testing.T) {
 		assert.Equalf(getPadding(testCase.input), testCase.result, "Test case returned wrong value for input %d", testCase.input)
 	}
 }
+
+func TestAppendNBits(t *testing.T) {
+	assert := assert.New(t)
+	for n := uint(1); n <= 16; n++ {
+		for _, val := range []uint16{0, 1, 0x5555, 0xAAAA, 0xFFFF} {
+			var want uint16
+			for i := int(n) - 1; i >= 0; i-- {
+				want = want<<1 | val>>uint(i)&1
+			}
+			assert.Equalf(want, appendNBitsToUint16(0, n, val), "uint16 n=%d val=%#x", n, val)
+			assert.Equalf(uint32(want), appendNBitsToUint32(0, n, uint32(val)), "uint32 n=%d val=%#x", n, val)
+		}
+	}
+	assert.Equal(uint16(0x3805), appendNBitsToUint16(appendNBitsToUint16(1, 2, 3), 11, 5))
+	assert.Equal(uint32(0x123456AB), appendNBitsToUint32(appendNBitsToUint32(0, 24, 0xFF123456), 8, 0xAB))
+}
+
+func TestGetNBits(t *testing.T) {
+	assert := assert.New(t)
+	for _, v := range []uint16{0, 0x8001, 0x5A3C, 0xFFFF} {
+		for begin := uint(0); begin < 16; begin++ {
+			for n := uint(1); begin+n <= 16; n++ {
+				var want uint16
+				for i := begin; i < begin+n; i++ {
+					want = want<<1 | v>>(15-i)&1
+				}
+				assert.Equalf(want, getNBitsFromUint16(v, begin, n), "v=%#x begin=%d n=%d", v, begin, n)
+				if begin+n <= 8 {
+					assert.Equalf(getNBitsFromUint16(v, begin, n), getNBitsFromByte(byte(v>>8), begin, n), "byte v=%#x begin=%d n=%d", v, begin, n)
+				}
+			}
+		}
+	}
+}
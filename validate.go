@@ -0,0 +1,53 @@
+package rtcp
+
+import "encoding/binary"
+
+// The packet types reserved for RTCP, so that RTCP can be told apart from RTP
+// when multiplexed on a single port (RFC 5761, 4)
+const (
+	minPacketType = 192
+	maxPacketType = 223
+)
+
+// Validate performs the cheap validity checks of RFC 3550, A.2 on a received
+// datagram, without decoding or allocating. It is meant to run on every
+// datagram before Unmarshal, so that malformed input is rejected early.
+//
+// Every packet in the datagram must have version 2 and a packet type in the
+// RTCP range, the packet lengths must add up to the length of the datagram,
+// and only the last packet may be padded, by at least one octet and no more
+// than its payload. Reduced-size RTCP (RFC 5506) is accepted, so the first
+// packet may be of any type.
+func Validate(raw []byte) error {
+	if len(raw) == 0 {
+		return errInvalidHeader
+	}
+
+	for len(raw) != 0 {
+		if len(raw) < headerLength {
+			return errPacketTooShort
+		}
+		if raw[0]>>versionShift&versionMask != rtpVersion {
+			return errBadVersion
+		}
+		if raw[1] < minPacketType || raw[1] > maxPacketType {
+			return errBadPacketType
+		}
+
+		size := (int(binary.BigEndian.Uint16(raw[2:])) + 1) * 4
+		if size > len(raw) {
+			return errPacketTooShort
+		}
+
+		if raw[0]>>paddingShift&paddingMask != 0 {
+			padding := int(raw[size-1])
+			if size != len(raw) || padding == 0 || padding > size-headerLength {
+				return errBadPadding
+			}
+		}
+
+		raw = raw[size:]
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package rtcp
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	// a TransportLayerCC with one byte of padding
+	padded := []byte{
+		0xaf, 0xcd, 0x0, 0x5,
+		0xfa, 0x17, 0xfa, 0x17,
+		0x43, 0x3, 0x2f, 0xa0,
+		0x0, 0x99, 0x0, 0x1,
+		0x3d, 0xe8, 0x2, 0x17,
+		0x20, 0x1, 0x94, 0x1,
+	}
+	pli := []byte{0x81, 0xce, 0x0, 0x2, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x2}
+
+	withPadding := func(b []byte, count byte) []byte {
+		out := append([]byte{}, b...)
+		out[len(out)-1] = count
+		return out
+	}
+
+	for _, test := range []struct {
+		Name      string
+		Data      []byte
+		WantError error
+	}{
+		{Name: "compound", Data: realPacket},
+		{Name: "reduced size", Data: pli},
+		{Name: "padded last", Data: append(append([]byte{}, pli...), padded...)},
+		{Name: "empty", Data: nil, WantError: errInvalidHeader},
+		{Name: "short header", Data: realPacket[:3], WantError: errPacketTooShort},
+		{Name: "truncated", Data: realPacket[:len(realPacket)-1], WantError: errPacketTooShort},
+		{Name: "trailing bytes", Data: append(append([]byte{}, pli...), 0x80), WantError: errPacketTooShort},
+		{Name: "bad version", Data: []byte{0x41, 0xce, 0x0, 0x0}, WantError: errBadVersion},
+		{Name: "rtp", Data: []byte{0x80, 0x60, 0x0, 0x0}, WantError: errBadPacketType},
+		{Name: "padded first", Data: append(append([]byte{}, padded...), pli...), WantError: errBadPadding},
+		{Name: "zero padding", Data: withPadding(padded, 0), WantError: errBadPadding},
+		{Name: "padding too long", Data: withPadding(padded, 21), WantError: errBadPadding},
+	} {
+		if err := Validate(test.Data); err != test.WantError {
+			t.Fatalf("Validate %q: err = %v, want %v", test.Name, err, test.WantError)
+		}
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		if err := Validate(realPacket); err != nil {
+			t.Fatal(err)
+		}
+	}); allocs != 0 {
+		t.Fatalf("Validate allocates %v times, want 0", allocs)
+	}
+}
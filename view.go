@@ -0,0 +1,257 @@
+package rtcp
+
+import (
+	"encoding/binary"
+)
+
+// The View types read the fields of a packet directly from its marshaled
+// form, on demand, without decoding the rest of it. They suit routers and
+// monitors that look at a couple of fields of every packet, where Unmarshal
+// would build slices of chunks, deltas or reports only to discard them.
+//
+// A view aliases the buffer it was created from and doesn't copy it: it must
+// not be used after the buffer is reused or modified. Only the header and the
+// length are validated when the view is created; accessors of fields past the
+// end of a truncated packet return errors or zero values, as documented.
+
+// A TransportLayerCCView reads a TransportLayerCC from its marshaled form.
+type TransportLayerCCView struct {
+	raw []byte
+}
+
+// NewTransportLayerCCView creates a view of the TransportLayerCC at the start
+// of rawPacket.
+func NewTransportLayerCCView(rawPacket []byte) (TransportLayerCCView, error) {
+	var h Header
+	if err := h.Unmarshal(rawPacket); err != nil {
+		return TransportLayerCCView{}, err
+	}
+	if h.Type != TypeTransportSpecificFeedback || h.Count != FormatTCC {
+		return TransportLayerCCView{}, errWrongType
+	}
+	if h.size() < headerLength+packetChunkOffset || len(rawPacket) < h.size() {
+		return TransportLayerCCView{}, errPacketTooShort
+	}
+	return TransportLayerCCView{raw: rawPacket[:h.size()]}, nil
+}
+
+// SenderSSRC returns the SSRC of the feedback sender.
+func (v TransportLayerCCView) SenderSSRC() uint32 {
+	return binary.BigEndian.Uint32(v.raw[headerLength:])
+}
+
+// MediaSSRC returns the SSRC of the media source.
+func (v TransportLayerCCView) MediaSSRC() uint32 {
+	return binary.BigEndian.Uint32(v.raw[headerLength+ssrcLength:])
+}
+
+// BaseSequenceNumber returns the transport wide sequence number of the first
+// packet the feedback covers.
+func (v TransportLayerCCView) BaseSequenceNumber() uint16 {
+	return binary.BigEndian.Uint16(v.raw[headerLength+baseSequenceNumberOffset:])
+}
+
+// PacketStatusCount returns the number of packets the feedback covers.
+func (v TransportLayerCCView) PacketStatusCount() uint16 {
+	return binary.BigEndian.Uint16(v.raw[headerLength+packetStatusCountOffset:])
+}
+
+// ReferenceTime returns the unsigned reference time in multiples of 64ms, as
+// TransportLayerCC.ReferenceTime.
+func (v TransportLayerCCView) ReferenceTime() uint32 {
+	return get24BitsFromBytes(v.raw[headerLength+referenceTimeOffset : headerLength+referenceTimeOffset+3])
+}
+
+// FbPktCount returns the feedback packet count.
+func (v TransportLayerCCView) FbPktCount() uint8 {
+	return v.raw[headerLength+fbPktCountOffset]
+}
+
+// ForEachStatus calls fn with the transport wide sequence number, status and
+// receive delta of every packet the feedback covers, in sequence order. The
+// delta is in microseconds, as RecvDelta.Delta, and zero for packets not
+// received. One bit status vector symbols are reported as
+// TypePacketNotReceived or TypePacketReceivedSmallDelta. It fails, after
+// calling fn with the packets before, if the chunks or deltas are truncated.
+func (v TransportLayerCCView) ForEachStatus(fn func(seq uint16, symbol PacketStatusSymbol, delta int64)) error {
+	count := v.PacketStatusCount()
+	counts, err := countPacketStatusChunks(v.raw, count)
+	if err != nil {
+		return err
+	}
+
+	seq := v.BaseSequenceNumber()
+	deltaPos := headerLength + packetChunkOffset + (counts.runs+counts.vectors)*packetStautsChunkLength
+	emit := func(symbol PacketStatusSymbol) error {
+		var delta int64
+		switch symbol {
+		case TypePacketReceivedSmallDelta:
+			if deltaPos+1 > len(v.raw) {
+				return errPacketTooShort
+			}
+			delta = delta250us * int64(v.raw[deltaPos])
+			deltaPos++
+		case TypePacketReceivedLargeDelta:
+			if deltaPos+2 > len(v.raw) {
+				return errPacketTooShort
+			}
+			delta = delta250us * int64(int16(binary.BigEndian.Uint16(v.raw[deltaPos:])))
+			deltaPos += 2
+		}
+		fn(seq, symbol, delta)
+		seq++
+		return nil
+	}
+
+	pos := headerLength + packetChunkOffset
+	for processed := 0; processed < int(count); pos += packetStautsChunkLength {
+		remaining := int(count) - processed
+		chunk := binary.BigEndian.Uint16(v.raw[pos:])
+
+		if getNBitsFromUint16(chunk, 0, 1) == typeRunLengthChunk {
+			symbol := PacketStatusSymbol(getNBitsFromUint16(chunk, 1, 2))
+			n := int(getNBitsFromUint16(chunk, 3, 13))
+			if n > remaining {
+				n = remaining
+			}
+			for i := 0; i < n; i++ {
+				if err := emit(symbol); err != nil {
+					return err
+				}
+			}
+			processed += n
+			continue
+		}
+
+		width, n := uint(1), oneBitVectorSymbols
+		if getNBitsFromUint16(chunk, 1, 1) == typeSymbolSizeTwoBit {
+			width, n = 2, twoBitVectorSymbols
+		}
+		if n > remaining {
+			n = remaining
+		}
+		for i := 0; i < n; i++ {
+			symbol := PacketStatusSymbol(getNBitsFromUint16(chunk, 2+uint(i)*width, width))
+			if width == 1 && symbol == 1 {
+				symbol = TypePacketReceivedSmallDelta
+			}
+			if err := emit(symbol); err != nil {
+				return err
+			}
+		}
+		processed += n
+	}
+	return nil
+}
+
+// A TransportLayerNackView reads a TransportLayerNack from its marshaled
+// form.
+type TransportLayerNackView struct {
+	raw []byte
+}
+
+// NewTransportLayerNackView creates a view of the TransportLayerNack at the
+// start of rawPacket.
+func NewTransportLayerNackView(rawPacket []byte) (TransportLayerNackView, error) {
+	var h Header
+	if err := h.Unmarshal(rawPacket); err != nil {
+		return TransportLayerNackView{}, err
+	}
+	if h.Type != TypeTransportSpecificFeedback || h.Count != FormatTLN {
+		return TransportLayerNackView{}, errWrongType
+	}
+	if h.size() < headerLength+nackOffset || len(rawPacket) < h.size() {
+		return TransportLayerNackView{}, errPacketTooShort
+	}
+	return TransportLayerNackView{raw: rawPacket[:h.size()]}, nil
+}
+
+// SenderSSRC returns the SSRC of the feedback sender.
+func (v TransportLayerNackView) SenderSSRC() uint32 {
+	return binary.BigEndian.Uint32(v.raw[headerLength:])
+}
+
+// MediaSSRC returns the SSRC of the media source.
+func (v TransportLayerNackView) MediaSSRC() uint32 {
+	return binary.BigEndian.Uint32(v.raw[headerLength+ssrcLength:])
+}
+
+// NackCount returns the number of NackPairs.
+func (v TransportLayerNackView) NackCount() int {
+	return (len(v.raw) - headerLength - nackOffset) / 4
+}
+
+// Nack returns the i-th NackPair. It panics if i is out of range, as indexing
+// TransportLayerNack.Nacks does.
+func (v TransportLayerNackView) Nack(i int) NackPair {
+	b := v.raw[headerLength+nackOffset+4*i : headerLength+nackOffset+4*i+4]
+	return NackPair{
+		PacketID:    binary.BigEndian.Uint16(b),
+		LostPackets: PacketBitmap(binary.BigEndian.Uint16(b[2:])),
+	}
+}
+
+// A ReportView reads a SenderReport or ReceiverReport from its marshaled
+// form.
+type ReportView struct {
+	raw    []byte
+	header Header
+}
+
+// NewReportView creates a view of the SenderReport or ReceiverReport at the
+// start of rawPacket.
+func NewReportView(rawPacket []byte) (ReportView, error) {
+	var h Header
+	if err := h.Unmarshal(rawPacket); err != nil {
+		return ReportView{}, err
+	}
+	if h.Type != TypeSenderReport && h.Type != TypeReceiverReport {
+		return ReportView{}, errWrongType
+	}
+	if h.size() < headerLength+ssrcLength || len(rawPacket) < h.size() ||
+		(h.Type == TypeSenderReport && h.size() < headerLength+srHeaderLength) {
+		return ReportView{}, errPacketTooShort
+	}
+	return ReportView{raw: rawPacket[:h.size()], header: h}, nil
+}
+
+// Type returns TypeSenderReport or TypeReceiverReport.
+func (v ReportView) Type() PacketType {
+	return v.header.Type
+}
+
+// SSRC returns the SSRC of the packet sender.
+func (v ReportView) SSRC() uint32 {
+	return binary.BigEndian.Uint32(v.raw[headerLength:])
+}
+
+// NTPTime returns the wallclock time of a SenderReport, or zero for a
+// ReceiverReport.
+func (v ReportView) NTPTime() uint64 {
+	if v.header.Type != TypeSenderReport {
+		return 0
+	}
+	return binary.BigEndian.Uint64(v.raw[headerLength+srNTPOffset:])
+}
+
+// ReportCount returns the number of reception reports the header announces.
+func (v ReportView) ReportCount() int {
+	return int(v.header.Count)
+}
+
+// Report returns the i-th reception report. It fails if the packet is too
+// short to hold it.
+func (v ReportView) Report(i int) (ReceptionReport, error) {
+	offset := rrReportOffset
+	if v.header.Type == TypeSenderReport {
+		offset = headerLength + srReportOffset
+	}
+	offset += i * receptionReportLength
+
+	var r ReceptionReport
+	if i < 0 || i >= v.ReportCount() || offset+receptionReportLength > len(v.raw) {
+		return r, errPacketTooShort
+	}
+	err := r.Unmarshal(v.raw[offset : offset+receptionReportLength])
+	return r, err
+}
@@ -0,0 +1,153 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportLayerCCView(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewTransportLayerCCBuilder(65530)
+	arrival := time.Second
+	for i := 0; i < 40; i++ {
+		seq := uint16(65530 + i)
+		switch {
+		case i%7 == 3:
+			assert.NoError(b.AddLost(seq))
+		case i == 20:
+			// a large delta, backwards
+			arrival -= 10 * time.Millisecond
+			assert.NoError(b.AddReceived(seq, arrival))
+		default:
+			arrival += time.Duration(i) * time.Millisecond
+			assert.NoError(b.AddReceived(seq, arrival))
+		}
+	}
+	fb := b.Build()
+	fb.SenderSSRC, fb.MediaSSRC, fb.FbPktCount = 1, 2, 3
+	data, err := fb.Marshal()
+	assert.NoError(err)
+
+	var want TransportLayerCC
+	assert.NoError(want.Unmarshal(data))
+
+	v, err := NewTransportLayerCCView(data)
+	assert.NoError(err)
+	assert.Equal(want.SenderSSRC, v.SenderSSRC())
+	assert.Equal(want.MediaSSRC, v.MediaSSRC())
+	assert.Equal(want.BaseSequenceNumber, v.BaseSequenceNumber())
+	assert.Equal(want.PacketStatusCount, v.PacketStatusCount())
+	assert.Equal(want.ReferenceTime, v.ReferenceTime())
+	assert.Equal(want.FbPktCount, v.FbPktCount())
+
+	type status struct {
+		seq    uint16
+		symbol PacketStatusSymbol
+		delta  int64
+	}
+	var wantStatuses, statuses []status
+	deltas := want.RecvDeltas
+	want.forEachStatus(func(seq uint16, symbol PacketStatusSymbol) {
+		s := status{seq: seq, symbol: symbol}
+		if symbol == TypePacketReceivedSmallDelta || symbol == TypePacketReceivedLargeDelta {
+			s.delta = deltas[0].Delta
+			deltas = deltas[1:]
+		}
+		wantStatuses = append(wantStatuses, s)
+	})
+	assert.NoError(v.ForEachStatus(func(seq uint16, symbol PacketStatusSymbol, delta int64) {
+		statuses = append(statuses, status{seq, symbol, delta})
+	}))
+	assert.Equal(wantStatuses, statuses)
+
+	// truncated deltas
+	truncated := append([]byte(nil), data...)
+	truncated[3]--
+	v, err = NewTransportLayerCCView(truncated)
+	assert.NoError(err)
+	assert.Equal(errPacketTooShort, v.ForEachStatus(func(uint16, PacketStatusSymbol, int64) {}))
+
+	_, err = NewTransportLayerCCView(data[:20])
+	assert.Equal(errPacketTooShort, err)
+	_, err = NewTransportLayerCCView(realPacket)
+	assert.Equal(errWrongType, err)
+}
+
+func TestTransportLayerNackView(t *testing.T) {
+	assert := assert.New(t)
+
+	nack := &TransportLayerNack{
+		SenderSSRC: 1,
+		MediaSSRC:  2,
+		Nacks:      []NackPair{{PacketID: 3, LostPackets: 0x8001}, {PacketID: 40}},
+	}
+	data, err := nack.Marshal()
+	assert.NoError(err)
+
+	v, err := NewTransportLayerNackView(data)
+	assert.NoError(err)
+	assert.Equal(uint32(1), v.SenderSSRC())
+	assert.Equal(uint32(2), v.MediaSSRC())
+	assert.Equal(2, v.NackCount())
+	assert.Equal(nack.Nacks[0], v.Nack(0))
+	assert.Equal(nack.Nacks[1], v.Nack(1))
+
+	_, err = NewTransportLayerNackView(data[:8])
+	assert.Equal(errPacketTooShort, err)
+	_, err = NewTransportLayerNackView(realPacket)
+	assert.Equal(errWrongType, err)
+}
+
+func TestReportView(t *testing.T) {
+	assert := assert.New(t)
+
+	reports := []ReceptionReport{
+		{SSRC: 10, FractionLost: 3, TotalLost: 4, LastSequenceNumber: 5, Jitter: 6, LastSenderReport: 7, Delay: 8},
+		{SSRC: 11},
+	}
+	sr := &SenderReport{SSRC: 1, NTPTime: 0x0102030405060708, Reports: reports}
+	rr := &ReceiverReport{SSRC: 2, Reports: reports}
+
+	for _, p := range []Packet{sr, rr} {
+		data, err := p.Marshal()
+		assert.NoError(err)
+
+		v, err := NewReportView(data)
+		assert.NoError(err)
+		assert.Equal(2, v.ReportCount())
+		for i, want := range reports {
+			got, err := v.Report(i)
+			assert.NoError(err)
+			assert.Equal(want, got)
+		}
+		_, err = v.Report(2)
+		assert.Equal(errPacketTooShort, err)
+	}
+
+	data, err := sr.Marshal()
+	assert.NoError(err)
+	v, err := NewReportView(data)
+	assert.NoError(err)
+	assert.Equal(TypeSenderReport, v.Type())
+	assert.Equal(uint32(1), v.SSRC())
+	assert.Equal(sr.NTPTime, v.NTPTime())
+
+	// a header announcing more reports than the packet holds
+	data, err = rr.Marshal()
+	assert.NoError(err)
+	data[0]++
+	v, err = NewReportView(data)
+	assert.NoError(err)
+	assert.Equal(TypeReceiverReport, v.Type())
+	assert.Equal(uint64(0), v.NTPTime())
+	_, err = v.Report(2)
+	assert.Equal(errPacketTooShort, err)
+
+	_, err = NewReportView(data[:6])
+	assert.Error(err)
+	_, err = NewReportView([]byte{0x81, 0xc8, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01})
+	assert.Equal(errPacketTooShort, err)
+}